@@ -1,23 +1,35 @@
 package main
 
 import (
+	"archive/zip"
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"log"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/command"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/api_mock"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabjwt"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
@@ -86,6 +98,7 @@ func TestMain(m *testing.M) {
 
 	app := SetupRouter(mongo, redis)
 	defer app.Shutdown()
+	app.StartWorkers()
 
 	testServer := &http.Server{
 		Addr: "127.0.0.1:8880",
@@ -145,6 +158,10 @@ func createJwt(
 }
 
 func request(method string, url string, jwt string, body io.Reader, t *testing.T) (*http.Response, func() error) {
+	return requestWithContentType(method, url, jwt, body, "application/json", t)
+}
+
+func requestWithContentType(method string, url string, jwt string, body io.Reader, contentType string, t *testing.T) (*http.Response, func() error) {
 	csrf := createCsrf()
 
 	client := &http.Client{}
@@ -152,7 +169,7 @@ func request(method string, url string, jwt string, body io.Reader, t *testing.T
 	fmt.Println("Request URL:", requestUrl)
 	req, err := http.NewRequest(method, requestUrl, body)
 	if method != "GET" && err == nil {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
 	}
 	assert.NoError(t, err)
 	if method != "GET" {
@@ -764,11 +781,1692 @@ func TestMessageDelete(t *testing.T) {
 	err = json.Unmarshal(bodyBytes, &result)
 	assert.NoError(t, err)
 
-	// メッセージが削除されていることを確認
-	exists, err := mongoHelper.ExistContents(model.MessageCollectionName, bson.M{"_id": func() primitive.ObjectID {
-		id, _ := primitive.ObjectIDFromHex(messageID)
-		return id
-	}()})
+	// ドキュメントは残したまま、削除の記録が付いていることを確認
+	exists, err := mongoHelper.ExistContents(model.MessageCollectionName, bson.M{
+		"_id": func() primitive.ObjectID {
+			id, _ := primitive.ObjectIDFromHex(messageID)
+			return id
+		}(),
+		"deletedAt": bson.M{"$exists": true},
+		"deletedBy": uuid,
+	})
 	assert.NoError(t, err)
-	assert.False(t, exists)
+	assert.True(t, exists)
+
+	// 一覧では本文を伏せて返す
+	senderJwt := createJwt("sender-test-uuid", "sender@example.com", time.Now().Add(1*time.Hour))
+	resp2, close2 := request("GET", "/message/"+roomID+"/list", senderJwt, nil, t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+	list := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp2.Body).Decode(&list))
+	assert.Len(t, list["messages"], 1)
+	assert.True(t, list["messages"][0].Deleted)
+	assert.Equal(t, "message deleted", list["messages"][0].Message)
+
+	// 削除したメッセージの閲覧・復元は管理者だけ
+	resp3, close3 := request("GET", "/message/"+roomID+"/deleted", senderJwt, nil, t)
+	defer close3()
+	assert.Equal(t, 403, resp3.StatusCode)
+
+	resp4, close4 := request("GET", "/message/"+roomID+"/deleted", jwt, nil, t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+	deleted := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp4.Body).Decode(&deleted))
+	assert.Len(t, deleted["messages"], 1)
+	assert.Equal(t, "This message will be deleted.", deleted["messages"][0].Message)
+
+	resp5, close5 := request("POST", "/message/"+roomID+"/"+messageID+"/restore", senderJwt, nil, t)
+	defer close5()
+	assert.Equal(t, 403, resp5.StatusCode)
+
+	resp6, close6 := request("POST", "/message/"+roomID+"/"+messageID+"/restore", jwt, nil, t)
+	defer close6()
+	assert.Equal(t, 200, resp6.StatusCode)
+
+	exists, err = mongoHelper.ExistContents(model.MessageCollectionName, bson.M{"message": "This message will be deleted.", "deletedAt": bson.M{"$exists": false}})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// 既に復元したメッセージは復元できない
+	resp7, close7 := request("POST", "/message/"+roomID+"/"+messageID+"/restore", jwt, nil, t)
+	defer close7()
+	assert.Equal(t, 404, resp7.StatusCode)
+}
+
+func TestMessageReport(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Message Report Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid", "sender-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	message := model.Message{
+		RoomID:    roomID,
+		Sender:    "sender-test-uuid",
+		Message:   "This message will be reported.",
+		CreatedAt: time.Now(),
+	}
+	messageID, err := mongoHelper.Insert(
+		model.MessageCollectionName,
+		message,
+	)
+	assert.NoError(t, err)
+
+	uuid := "test-uuid"
+	jwt := createJwt(
+		uuid,
+		"test@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+	requestBody := `{
+			"reason": "spam",
+			"comment": "advertisement"
+		}`
+	resp, close := request("POST", "/message/"+roomID+"/"+messageID+"/report", jwt, io.NopCloser(io.Reader(strings.NewReader(requestBody))), t)
+	defer close()
+
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// 通報が保存されていることを確認
+	exists, err := mongoHelper.ExistContents(model.ReportCollectionName, bson.M{
+		"messageid":    messageID,
+		"reporter":     uuid,
+		"reportedUser": "sender-test-uuid",
+		"status":       "open",
+	})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	// 同じメッセージへの重複通報は拒否される
+	resp2, close2 := request("POST", "/message/"+roomID+"/"+messageID+"/report", jwt, io.NopCloser(io.Reader(strings.NewReader(requestBody))), t)
+	defer close2()
+
+	assert.Equal(t, 409, resp2.StatusCode)
+}
+
+func TestModerationResolve(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Moderation Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid", "sender-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	messageID, err := mongoHelper.Insert(
+		model.MessageCollectionName,
+		model.Message{
+			RoomID:    roomID,
+			Sender:    "sender-test-uuid",
+			Message:   "Abusive message.",
+			CreatedAt: time.Now(),
+		},
+	)
+	assert.NoError(t, err)
+
+	reportID, err := mongoHelper.Insert(
+		model.ReportCollectionName,
+		model.Report{
+			RoomID:         roomID,
+			MessageID:      messageID,
+			ReporterID:     "test-uuid",
+			ReportedUserID: "sender-test-uuid",
+			Reason:         "harassment",
+			Status:         "open",
+			CreatedAt:      time.Now(),
+		},
+	)
+	assert.NoError(t, err)
+
+	// モデレーター以外はアクセスできない
+	userJwt := createJwt(
+		"test-uuid",
+		"test@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+	forbiddenResp, forbiddenClose := request("GET", "/moderation/reports", userJwt, nil, t)
+	defer forbiddenClose()
+	assert.Equal(t, 403, forbiddenResp.StatusCode)
+
+	jwt := createJwt(
+		"moderator-uuid",
+		"moderator@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+
+	listResp, listClose := request("GET", "/moderation/reports", jwt, nil, t)
+	defer listClose()
+	assert.Equal(t, 200, listResp.StatusCode)
+
+	bodyBytes, err := io.ReadAll(listResp.Body)
+	assert.NoError(t, err)
+	list := map[string][]map[string]any{}
+	err = json.Unmarshal(bodyBytes, &list)
+	assert.NoError(t, err)
+	assert.Len(t, list["reports"], 1)
+	assert.Equal(t, reportID, list["reports"][0]["ID"])
+
+	requestBody := `{
+			"action": "user_banned",
+			"note": "repeated harassment"
+		}`
+	resp, close := request("POST", "/moderation/reports/"+reportID+"/resolve", jwt, io.NopCloser(io.Reader(strings.NewReader(requestBody))), t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// 通報が対応済みとして記録されていることを確認
+	var report model.Report
+	singleResult, err := mongoHelper.FindOneContents(model.ReportCollectionName, reportID)
+	assert.NoError(t, err)
+	err = singleResult.Decode(&report)
+	assert.NoError(t, err)
+	assert.Equal(t, "resolved", report.Status)
+	assert.Equal(t, "user_banned", report.Resolution.Action)
+	assert.Equal(t, "moderator-uuid", report.Resolution.ResolvedBy)
+
+	// メッセージがモデレーターによって削除され、ユーザーがルームから追放されていることを確認
+	exists, err := mongoHelper.ExistContents(model.MessageCollectionName, bson.M{
+		"_id": func() primitive.ObjectID {
+			id, _ := primitive.ObjectIDFromHex(messageID)
+			return id
+		}(),
+		"deletedBy": "moderator-uuid",
+	})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	var updatedRoom model.Room
+	singleResult, err = mongoHelper.FindOneContents(model.RoomCollectionName, roomID)
+	assert.NoError(t, err)
+	err = singleResult.Decode(&updatedRoom)
+	assert.NoError(t, err)
+	assert.NotContains(t, updatedRoom.Members, "sender-test-uuid")
+	assert.Contains(t, updatedRoom.BannedMembers, "sender-test-uuid")
+
+	// 対応済みの通報は再度対応できない
+	resp2, close2 := request("POST", "/moderation/reports/"+reportID+"/resolve", jwt, io.NopCloser(io.Reader(strings.NewReader(requestBody))), t)
+	defer close2()
+	assert.Equal(t, 409, resp2.StatusCode)
+}
+
+func TestMessageSendRateLimit(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	// 過去の実行で記録されたカウントの影響を受けないようにユーザーを分ける
+	uuid := fmt.Sprintf("rate-limit-uuid-%d", time.Now().UnixNano())
+	room := model.Room{
+		Name:      "Rate Limit Room",
+		OwnerID:   uuid,
+		IsPrivate: false,
+		Members:   []string{uuid},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt(
+		uuid,
+		"test@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+
+	roomLimit := consts.RateLimitRules[consts.RateLimitGroups.MessageSend].RoomLimit
+	for i := 0; i < roomLimit; i++ {
+		resp, close := request("POST", "/message/"+roomID+"/send", jwt, io.NopCloser(strings.NewReader(`{"message": "spam"}`)), t)
+		assert.Equal(t, 200, resp.StatusCode)
+		assert.Equal(t, strconv.Itoa(roomLimit-i-1), resp.Header.Get("X-RateLimit-Remaining"))
+		close()
+	}
+
+	resp, close := request("POST", "/message/"+roomID+"/send", jwt, io.NopCloser(strings.NewReader(`{"message": "spam"}`)), t)
+	defer close()
+
+	assert.Equal(t, 429, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	assert.Equal(t, strconv.Itoa(roomLimit), resp.Header.Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+
+	count, err := mongoHelper.CountContents(model.MessageCollectionName, bson.M{"roomid": roomID})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(roomLimit), count)
+}
+
+func TestMessageSendSpamRejected(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	// 過去の実行で記録された投稿の影響を受けないようにユーザーを分ける
+	uuid := fmt.Sprintf("spam-uuid-%d", time.Now().UnixNano())
+	jwt := createJwt(
+		uuid,
+		"test@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+
+	statuses := []int{}
+	for i := 0; i < consts.SpamCrossRoomThreshold; i++ {
+		roomID, err := mongoHelper.Insert(
+			model.RoomCollectionName,
+			model.Room{
+				Name:      fmt.Sprintf("Spam Room %d", i),
+				OwnerID:   uuid,
+				IsPrivate: false,
+				Members:   []string{uuid},
+				CreatedAt: time.Now(),
+			},
+		)
+		assert.NoError(t, err)
+
+		requestBody := fmt.Sprintf(`{"message": "Buy cheap watches now!!! %d"}`, i)
+		resp, close := request("POST", "/message/"+roomID+"/send", jwt, io.NopCloser(strings.NewReader(requestBody)), t)
+		statuses = append(statuses, resp.StatusCode)
+		close()
+	}
+
+	// 規定数のルームに同じ内容を投稿した時点で拒否される
+	assert.Equal(t, []int{200, 200, 422}, statuses)
+
+	exists, err := mongoHelper.ExistContents(model.ReportCollectionName, bson.M{
+		"reporter":     consts.ReportSystemReporter,
+		"reportedUser": uuid,
+		"reason":       "spam",
+		"status":       "open",
+	})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestMessageSendIdempotent(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+	// クリーンアップでコレクションごと消えるので、インデックスを作り直す
+	err = usecase.NewMongoIndexUseCaseStruct(model.MongoIndexes).EnsureIndexes()
+	assert.NoError(t, err)
+
+	uuid := fmt.Sprintf("idempotent-uuid-%d", time.Now().UnixNano())
+	jwt := createJwt(
+		uuid,
+		"test@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		model.Room{
+			Name:      "Idempotent Room",
+			OwnerID:   uuid,
+			IsPrivate: false,
+			Members:   []string{uuid},
+			CreatedAt: time.Now(),
+		},
+	)
+	assert.NoError(t, err)
+
+	send := func(body string) string {
+		resp, close := request("POST", "/message/"+roomID+"/send", jwt, io.NopCloser(strings.NewReader(body)), t)
+		defer close()
+		assert.Equal(t, 200, resp.StatusCode)
+
+		result := map[string]string{}
+		err := json.NewDecoder(resp.Body).Decode(&result)
+		assert.NoError(t, err)
+		return result["message_id"]
+	}
+
+	// 同じ client_msg_id の再送は最初のメッセージIDが返り、重複して保存されない
+	first := send(`{"message": "Hello, retry!", "client_msg_id": "client-msg-1"}`)
+	retry := send(`{"message": "Hello, retry!", "client_msg_id": "client-msg-1"}`)
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, retry)
+
+	other := send(`{"message": "Hello, retry!", "client_msg_id": "client-msg-2"}`)
+	assert.NotEqual(t, first, other)
+
+	count, err := mongoHelper.CountContents(model.MessageCollectionName, bson.M{"roomid": roomID, "sender": uuid})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestMessageAttachment(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	uuid := fmt.Sprintf("attachment-uuid-%d", time.Now().UnixNano())
+	jwt := createJwt(
+		uuid,
+		"test@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+	outsiderJwt := createJwt(
+		"outsider-uuid",
+		"outsider@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		model.Room{
+			Name:      "Attachment Room",
+			OwnerID:   uuid,
+			IsPrivate: false,
+			Members:   []string{uuid},
+			CreatedAt: time.Now(),
+		},
+	)
+	assert.NoError(t, err)
+
+	upload := func(content []byte) *http.Response {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("files", "photo.png")
+		assert.NoError(t, err)
+		_, err = part.Write(content)
+		assert.NoError(t, err)
+		assert.NoError(t, writer.WriteField("message", "see attached"))
+		assert.NoError(t, writer.Close())
+
+		resp, close := requestWithContentType("POST", "/message/"+roomID+"/attachments", jwt, &body, writer.FormDataContentType(), t)
+		t.Cleanup(func() { close() })
+		return resp
+	}
+
+	// 拡張子に関わらず、中身が許可されていない形式であれば拒否する
+	resp := upload([]byte("<html><script>alert(1)</script></html>"))
+	assert.Equal(t, 415, resp.StatusCode)
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	resp = upload(png)
+	assert.Equal(t, 200, resp.StatusCode)
+	result := map[string]string{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+
+	// 一覧に添付のダウンロードURLが含まれる
+	resp, close := request("GET", "/message/"+roomID+"/list", jwt, nil, t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+	list := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	assert.Len(t, list["messages"], 1)
+	assert.Equal(t, result["message_id"], list["messages"][0].ID)
+	assert.Len(t, list["messages"][0].Attachments, 1)
+	url := list["messages"][0].Attachments[0].URL
+
+	resp, close = request("GET", url, jwt, nil, t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	downloaded, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, png, downloaded)
+
+	// ルームのメンバー以外はURLを知っていてもダウンロードできない
+	resp, close = request("GET", url, outsiderJwt, nil, t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+}
+
+func TestMessageThumbnail(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	uuid := fmt.Sprintf("thumbnail-uuid-%d", time.Now().UnixNano())
+	jwt := createJwt(
+		uuid,
+		"test@example.com",
+		time.Now().Add(1*time.Hour),
+	)
+
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		model.Room{
+			Name:      "Thumbnail Room",
+			OwnerID:   uuid,
+			IsPrivate: false,
+			Members:   []string{uuid},
+			CreatedAt: time.Now(),
+		},
+	)
+	assert.NoError(t, err)
+
+	var source bytes.Buffer
+	assert.NoError(t, png.Encode(&source, image.NewRGBA(image.Rect(0, 0, 1280, 960))))
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("files", "photo.png")
+	assert.NoError(t, err)
+	_, err = part.Write(source.Bytes())
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	resp, close := requestWithContentType("POST", "/message/"+roomID+"/attachments", jwt, &body, writer.FormDataContentType(), t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// サムネイルはワーカーが生成するので、一覧に現れるまで待つ
+	var attachment dto.AttachmentResponse
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		resp, close := request("GET", "/message/"+roomID+"/list", jwt, nil, t)
+		list := map[string][]dto.MessageResponse{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+		close()
+		if len(list["messages"]) == 1 && len(list["messages"][0].Attachments[0].Thumbnails) > 0 {
+			attachment = list["messages"][0].Attachments[0]
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	assert.Equal(t, 1280, attachment.Width)
+	assert.Equal(t, 960, attachment.Height)
+	if !assert.Len(t, attachment.Thumbnails, len(consts.ThumbnailSizes)) {
+		return
+	}
+	small := attachment.Thumbnails[0]
+	assert.Equal(t, "small", small.Size)
+	assert.Equal(t, 160, small.Width)
+	assert.Equal(t, 120, small.Height)
+
+	resp, close = request("GET", small.URL, jwt, nil, t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+	config, err := png.DecodeConfig(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, 160, config.Width)
+	assert.Equal(t, 120, config.Height)
+}
+
+func TestMessagePin(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Message Pin Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	message := model.Message{
+		RoomID:    roomID,
+		Sender:    "test-uuid",
+		Message:   "Maintenance starts at 22:00 tonight.",
+		CreatedAt: time.Now(),
+	}
+	messageID, err := mongoHelper.Insert(
+		model.MessageCollectionName,
+		message,
+	)
+	assert.NoError(t, err)
+
+	ownerJwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	// 管理者でないメンバーはピン留めできない
+	resp, close := request("POST", "/message/"+roomID+"/"+messageID+"/pin", memberJwt, nil, t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp2, close2 := request("POST", "/message/"+roomID+"/"+messageID+"/pin", ownerJwt, nil, t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	// 同じメッセージは重ねてピン留めできない
+	resp3, close3 := request("POST", "/message/"+roomID+"/"+messageID+"/pin", ownerJwt, nil, t)
+	defer close3()
+	assert.Equal(t, 409, resp3.StatusCode)
+
+	// メンバーはピン留めの一覧を見られる
+	resp4, close4 := request("GET", "/message/"+roomID+"/pins", memberJwt, nil, t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+
+	bodyBytes, err := io.ReadAll(resp4.Body)
+	assert.NoError(t, err)
+	result := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.Unmarshal(bodyBytes, &result))
+	assert.Len(t, result["pins"], 1)
+	assert.Equal(t, messageID, result["pins"][0].ID)
+	assert.True(t, result["pins"][0].Pinned)
+	assert.Equal(t, "test-uuid", result["pins"][0].PinnedBy)
+
+	resp5, close5 := request("DELETE", "/message/"+roomID+"/"+messageID+"/pin", ownerJwt, nil, t)
+	defer close5()
+	assert.Equal(t, 200, resp5.StatusCode)
+
+	exists, err := mongoHelper.ExistContents(model.MessageCollectionName, bson.M{"roomid": roomID, "pin": bson.M{"$exists": true}})
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestScheduledMessage(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Scheduled Message Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	sendAt := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	body := fmt.Sprintf(`{"message": "Good morning!", "send_at": %q}`, sendAt.Format(time.RFC3339))
+	resp, close := request("POST", "/message/"+roomID+"/scheduled", jwt, strings.NewReader(body), t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	created := map[string]string{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	scheduledID := created["scheduled_id"]
+	assert.NotEmpty(t, scheduledID)
+
+	// 過去の日時は指定できない
+	past := fmt.Sprintf(`{"message": "Too late", "send_at": %q}`, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	resp2, close2 := request("POST", "/message/"+roomID+"/scheduled", jwt, strings.NewReader(past), t)
+	defer close2()
+	assert.Equal(t, 400, resp2.StatusCode)
+
+	// 他のメンバーからは見えず、編集もできない
+	resp3, close3 := request("GET", "/message/"+roomID+"/scheduled", memberJwt, nil, t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+	others := map[string][]dto.ScheduledMessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp3.Body).Decode(&others))
+	assert.Len(t, others["scheduled_messages"], 0)
+
+	edited := fmt.Sprintf(`{"message": "Good morning, everyone!", "send_at": %q}`, sendAt.Add(time.Hour).Format(time.RFC3339))
+	resp4, close4 := request("PUT", "/message/"+roomID+"/scheduled/"+scheduledID, memberJwt, strings.NewReader(edited), t)
+	defer close4()
+	assert.Equal(t, 404, resp4.StatusCode)
+
+	resp5, close5 := request("PUT", "/message/"+roomID+"/scheduled/"+scheduledID, jwt, strings.NewReader(edited), t)
+	defer close5()
+	assert.Equal(t, 200, resp5.StatusCode)
+
+	resp6, close6 := request("GET", "/message/"+roomID+"/scheduled", jwt, nil, t)
+	defer close6()
+	assert.Equal(t, 200, resp6.StatusCode)
+	result := map[string][]dto.ScheduledMessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp6.Body).Decode(&result))
+	assert.Len(t, result["scheduled_messages"], 1)
+	assert.Equal(t, "Good morning, everyone!", result["scheduled_messages"][0].Message)
+	assert.Equal(t, sendAt.Add(time.Hour).Format(time.RFC3339), result["scheduled_messages"][0].SendAt)
+
+	resp7, close7 := request("DELETE", "/message/"+roomID+"/scheduled/"+scheduledID, jwt, nil, t)
+	defer close7()
+	assert.Equal(t, 200, resp7.StatusCode)
+
+	// キャンセル済みのものは編集できない
+	resp8, close8 := request("PUT", "/message/"+roomID+"/scheduled/"+scheduledID, jwt, strings.NewReader(edited), t)
+	defer close8()
+	assert.Equal(t, 409, resp8.StatusCode)
+
+	exists, err := mongoHelper.ExistContents(model.ScheduledMessageCollectionName, bson.M{"roomid": roomID, "status": consts.ScheduledMessageStatus.Canceled})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestMessageExpiry(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+	// クリーンアップでコレクションごと消えるので、インデックスを作り直す
+	err = usecase.NewMongoIndexUseCaseStruct(model.MongoIndexes).EnsureIndexes()
+	assert.NoError(t, err)
+
+	room := model.Room{
+		Name:      "Message Expiry Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	// 既定の有効期間を変更できるのは管理者だけ
+	resp, close := request("PUT", "/room/"+roomID+"/admin/message_ttl", memberJwt, strings.NewReader(`{"message_ttl": 3600}`), t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp2, close2 := request("PUT", "/room/"+roomID+"/admin/message_ttl", jwt, strings.NewReader(`{"message_ttl": 3600}`), t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	resp3, close3 := request("POST", "/message/"+roomID+"/send", jwt, strings.NewReader(`{"message": "room default"}`), t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	resp4, close4 := request("POST", "/message/"+roomID+"/send", jwt, strings.NewReader(`{"message": "password: hunter2", "ttl": 60}`), t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+
+	// 期限切れのメッセージは、削除される前でも一覧に出さない
+	expiresAt := time.Now().Add(-time.Second)
+	expiredID, err := mongoHelper.Insert(
+		model.MessageCollectionName,
+		model.Message{
+			RoomID:    roomID,
+			Sender:    "test-uuid",
+			Message:   "already expired",
+			CreatedAt: time.Now().Add(-time.Minute),
+			ExpiresAt: &expiresAt,
+		},
+	)
+	assert.NoError(t, err)
+
+	resp5, close5 := request("GET", "/message/"+roomID+"/list", memberJwt, nil, t)
+	defer close5()
+	assert.Equal(t, 200, resp5.StatusCode)
+	list := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp5.Body).Decode(&list))
+	ttls := map[string]int{}
+	for _, message := range list["messages"] {
+		ttls[message.Message] = message.TTL
+		assert.NotEmpty(t, message.ExpiresAt)
+	}
+	assert.Equal(t, map[string]int{"room default": 3600, "password: hunter2": 60}, ttls)
+
+	// 期限切れのメッセージはワーカーが削除する
+	objectID, err := primitive.ObjectIDFromHex(expiredID)
+	assert.NoError(t, err)
+	exists := true
+	deadline := time.Now().Add(15 * time.Second)
+	for time.Now().Before(deadline) {
+		exists, err = mongoHelper.ExistContents(model.MessageCollectionName, bson.M{"_id": objectID})
+		assert.NoError(t, err)
+		if !exists {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	assert.False(t, exists)
+}
+
+func TestRoomRetention(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Retention Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	// 保存日数を変更できるのは管理者だけ
+	resp, close := request("PUT", "/room/"+roomID+"/admin/retention", memberJwt, strings.NewReader(`{"retention_days": 30}`), t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp2, close2 := request("PUT", "/room/"+roomID+"/admin/retention", jwt, strings.NewReader(`{"retention_days": 99999}`), t)
+	defer close2()
+	assert.Equal(t, 400, resp2.StatusCode)
+
+	resp3, close3 := request("PUT", "/room/"+roomID+"/admin/retention", jwt, strings.NewReader(`{"retention_days": 30}`), t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	result, err := mongoHelper.FindOneContents(model.RoomCollectionName, roomID)
+	assert.NoError(t, err)
+	updated := model.Room{}
+	assert.NoError(t, result.Decode(&updated))
+	assert.Equal(t, 30, updated.RetentionDays)
+}
+
+func TestRoomExport(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Export Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	for i, text := range []string{"first message", "second message"} {
+		_, err = mongoHelper.Insert(model.MessageCollectionName, model.Message{
+			RoomID:    roomID,
+			Sender:    "test-uuid",
+			Message:   text,
+			CreatedAt: time.Date(2025, 1, 1, 15, i, 0, 0, time.UTC),
+		})
+		assert.NoError(t, err)
+	}
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	outsiderJwt := createJwt("outsider-uuid", "outsider@example.com", time.Now().Add(1*time.Hour))
+
+	// メンバー以外はエクスポートできない
+	resp, close := request("GET", "/room/"+roomID+"/export", outsiderJwt, nil, t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp2, close2 := request("GET", "/room/"+roomID+"/export?format=xml", jwt, nil, t)
+	defer close2()
+	assert.Equal(t, 400, resp2.StatusCode)
+
+	resp3, close3 := request("GET", "/room/"+roomID+"/export?format=csv&tz=Asia/Tokyo", jwt, nil, t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+	assert.Equal(t, "text/csv; charset=UTF-8", resp3.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="room-`+roomID+`.csv"`, resp3.Header.Get("Content-Disposition"))
+
+	body, err := io.ReadAll(resp3.Body)
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "2025-01-02T00:00:00+09:00")
+	assert.Contains(t, string(body), "first message")
+	assert.Contains(t, string(body), "second message")
+}
+
+func TestEventStream(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Event Stream Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: false,
+		Members:   []string{"test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	messageID, err := mongoHelper.Insert(model.MessageCollectionName, model.Message{
+		RoomID:    roomID,
+		Sender:    "test-uuid",
+		Message:   "Pin me",
+		CreatedAt: time.Now(),
+	})
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+
+	// SSE 以外のエンドポイントではクエリパラメータの JWT を受け付けない
+	resp, err := http.Get(baseURL + "/room/list?token=" + jwt)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 401, resp.StatusCode)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/events?token="+jwt, nil)
+	assert.NoError(t, err)
+	resp2, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp2.Body.Close()
+	assert.Equal(t, 200, resp2.StatusCode)
+	assert.Equal(t, "text/event-stream", resp2.Header.Get("Content-Type"))
+
+	resp3, close3 := request("POST", "/message/"+roomID+"/"+messageID+"/pin", jwt, nil, t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	reader := bufio.NewReader(resp2.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(t, err) {
+			return
+		}
+		if line == "event: "+consts.EventTypes.MessagePinned+"\n" {
+			break
+		}
+	}
+}
+
+func TestPresence(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Presence Room",
+		OwnerID:   "owner-uuid",
+		IsPrivate: false,
+		Members:   []string{"owner-uuid", "test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+
+	resp, close := request("POST", "/presence/heartbeat", jwt, nil, t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp2, close2 := request("PUT", "/presence/status", jwt, strings.NewReader(`{"status": "away", "status_text": "Lunch"}`), t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	resp3, close3 := request("GET", "/room/"+roomID+"/members", jwt, nil, t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	bodyBytes, err := io.ReadAll(resp3.Body)
+	assert.NoError(t, err)
+	result := map[string][]model.RoomMember{}
+	assert.NoError(t, json.Unmarshal(bodyBytes, &result))
+	presences := map[string]model.Presence{}
+	for _, member := range result["members"] {
+		if assert.NotNil(t, member.Presence) {
+			presences[member.Uuid] = *member.Presence
+		}
+	}
+	assert.Equal(t, model.Presence{Status: "away", StatusText: "Lunch"}, presences["test-uuid"])
+	assert.Equal(t, consts.PresenceStatuses.Offline, presences["owner-uuid"].Status)
+}
+
+func TestTyping(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Typing Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+	outsiderJwt := createJwt("outsider-uuid", "outsider@example.com", time.Now().Add(1*time.Hour))
+
+	resp, close := request("POST", "/message/"+roomID+"/typing", outsiderJwt, nil, t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp2, close2 := request("POST", "/message/"+roomID+"/typing", jwt, strings.NewReader(`{"typing": true}`), t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	resp3, close3 := request("GET", "/message/"+roomID+"/typing", memberJwt, nil, t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	bodyBytes, err := io.ReadAll(resp3.Body)
+	assert.NoError(t, err)
+	result := map[string][]string{}
+	assert.NoError(t, json.Unmarshal(bodyBytes, &result))
+	assert.Equal(t, []string{"test-uuid"}, result["typing"])
+}
+
+func TestReadReceipts(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Receipt Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	messageID, err := mongoHelper.Insert(
+		model.MessageCollectionName,
+		model.Message{
+			RoomID:        roomID,
+			Sender:        "test-uuid",
+			Message:       "Hello",
+			CreatedAt:     time.Now(),
+			IsReadUserIds: []string{"test-uuid"},
+		},
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	resp, close := request("POST", "/message/"+roomID+"/read", memberJwt, strings.NewReader(`{"message_ids": ["`+messageID+`"]}`), t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	resp2, close2 := request("GET", "/message/"+roomID+"/"+messageID+"/receipts", memberJwt, nil, t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	bodyBytes, err := io.ReadAll(resp2.Body)
+	assert.NoError(t, err)
+	result := map[string][]dto.ReadReceiptResponse{}
+	assert.NoError(t, json.Unmarshal(bodyBytes, &result))
+	if assert.Len(t, result["receipts"], 1) {
+		assert.Equal(t, "member-test-uuid", result["receipts"][0].Uuid)
+		assert.NotEmpty(t, result["receipts"][0].ReadAt)
+	}
+
+	// 送信者にだけ見せる設定にすると、送信者以外は取得できない
+	resp3, close3 := request("PUT", "/room/"+roomID+"/admin/read_receipts", jwt, strings.NewReader(`{"hide": true}`), t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	resp4, close4 := request("GET", "/message/"+roomID+"/"+messageID+"/receipts", memberJwt, nil, t)
+	defer close4()
+	assert.Equal(t, 403, resp4.StatusCode)
+
+	resp5, close5 := request("GET", "/message/"+roomID+"/"+messageID+"/receipts", jwt, nil, t)
+	defer close5()
+	assert.Equal(t, 200, resp5.StatusCode)
+}
+
+func TestWebhooks(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Webhook Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	// 管理者以外は登録できない
+	resp, close := request("POST", "/room/"+roomID+"/admin/webhooks", memberJwt, strings.NewReader(`{"url": "https://example.com/hook", "events": ["message.created"]}`), t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp2, close2 := request("POST", "/room/"+roomID+"/admin/webhooks", jwt, strings.NewReader(`{"url": "https://example.com/hook", "events": ["message.created"]}`), t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	bodyBytes, err := io.ReadAll(resp2.Body)
+	assert.NoError(t, err)
+	created := struct {
+		Webhook dto.WebhookResponse `json:"webhook"`
+		Secret  string              `json:"secret"`
+	}{}
+	assert.NoError(t, json.Unmarshal(bodyBytes, &created))
+	assert.NotEmpty(t, created.Secret)
+	webhookID := created.Webhook.ID
+
+	resp3, close3 := request("GET", "/room/"+roomID+"/admin/webhooks", jwt, nil, t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+	bodyBytes, err = io.ReadAll(resp3.Body)
+	assert.NoError(t, err)
+	list := map[string][]dto.WebhookResponse{}
+	assert.NoError(t, json.Unmarshal(bodyBytes, &list))
+	assert.Len(t, list["webhooks"], 1)
+	assert.NotContains(t, string(bodyBytes), created.Secret)
+
+	// 送信したメッセージの配信が登録される
+	resp4, close4 := request("POST", "/message/"+roomID+"/send", jwt, strings.NewReader(`{"message": "Hello webhook"}`), t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+
+	resp5, close5 := request("GET", "/room/"+roomID+"/admin/webhooks/"+webhookID+"/deliveries", jwt, nil, t)
+	defer close5()
+	assert.Equal(t, 200, resp5.StatusCode)
+	bodyBytes, err = io.ReadAll(resp5.Body)
+	assert.NoError(t, err)
+	deliveries := map[string][]dto.WebhookDeliveryResponse{}
+	assert.NoError(t, json.Unmarshal(bodyBytes, &deliveries))
+	if assert.Len(t, deliveries["deliveries"], 1) {
+		assert.Equal(t, "message.created", deliveries["deliveries"][0].Event)
+	}
+
+	resp6, close6 := request("DELETE", "/room/"+roomID+"/admin/webhooks/"+webhookID, jwt, nil, t)
+	defer close6()
+	assert.Equal(t, 200, resp6.StatusCode)
+
+	resp7, close7 := request("DELETE", "/room/"+roomID+"/admin/webhooks/"+webhookID, jwt, nil, t)
+	defer close7()
+	assert.Equal(t, 404, resp7.StatusCode)
+}
+
+func TestIncomingWebhooks(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Incoming Webhook Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid", "member-test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-test-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	// 管理者以外は作成できない
+	resp, close := request("POST", "/room/"+roomID+"/admin/incoming_webhooks", memberJwt, strings.NewReader(`{"name": "CI"}`), t)
+	defer close()
+	assert.Equal(t, 403, resp.StatusCode)
+
+	resp2, close2 := request("POST", "/room/"+roomID+"/admin/incoming_webhooks", jwt, strings.NewReader(`{"name": "CI", "icon_url": "https://example.com/ci.png"}`), t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	created := struct {
+		IncomingWebhook dto.IncomingWebhookResponse `json:"incoming_webhook"`
+		Token           string                      `json:"token"`
+		URL             string                      `json:"url"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp2.Body).Decode(&created))
+	assert.NotEmpty(t, created.Token)
+	webhookID := created.IncomingWebhook.ID
+
+	// JWT と CSRF トークンなしで投稿できる
+	resp3, err := http.Post(baseURL+"/hooks/"+webhookID+"/"+created.Token, "application/json", strings.NewReader(`{"text": "Build <https://ci.example.com/1|#1> passed", "username": "Deploy Bot"}`))
+	assert.NoError(t, err)
+	defer resp3.Body.Close()
+	assert.Equal(t, 200, resp3.StatusCode)
+	bodyBytes, err := io.ReadAll(resp3.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", string(bodyBytes))
+
+	resp4, close4 := request("GET", "/message/"+roomID+"/list", memberJwt, nil, t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+	list := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp4.Body).Decode(&list))
+	if assert.Len(t, list["messages"], 1) {
+		assert.Equal(t, "Build https://ci.example.com/1 passed", list["messages"][0].Message)
+		assert.Equal(t, "webhook:"+webhookID, list["messages"][0].Sender)
+		if assert.NotNil(t, list["messages"][0].Bot) {
+			assert.Equal(t, "Deploy Bot", list["messages"][0].Bot.Name)
+			assert.Equal(t, "https://example.com/ci.png", list["messages"][0].Bot.IconURL)
+		}
+	}
+
+	// トークンが違う場合は投稿できない
+	resp5, err := http.Post(baseURL+"/hooks/"+webhookID+"/wrong-token", "application/json", strings.NewReader(`{"text": "hello"}`))
+	assert.NoError(t, err)
+	defer resp5.Body.Close()
+	assert.Equal(t, 403, resp5.StatusCode)
+
+	resp6, close6 := request("DELETE", "/room/"+roomID+"/admin/incoming_webhooks/"+webhookID, jwt, nil, t)
+	defer close6()
+	assert.Equal(t, 200, resp6.StatusCode)
+
+	// 削除した Webhook の URL は使えなくなる
+	resp7, err := http.Post(baseURL+"/hooks/"+webhookID+"/"+created.Token, "application/json", strings.NewReader(`{"text": "hello"}`))
+	assert.NoError(t, err)
+	defer resp7.Body.Close()
+	assert.Equal(t, 404, resp7.StatusCode)
+}
+
+func TestBots(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Bot Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+
+	// API キーでのリクエストは CSRF トークンを付けない
+	botRequest := func(method string, url string, apiKey string, body io.Reader) *http.Response {
+		req, err := http.NewRequest(method, baseURL+url, body)
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		resp, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		return resp
+	}
+
+	resp, close := request("POST", "/bots", jwt, strings.NewReader(`{"name": "Deploy Bot", "icon_url": "https://example.com/bot.png"}`), t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+	createdBot := struct {
+		Bot dto.BotAccountResponse `json:"bot"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&createdBot))
+	botID := createdBot.Bot.ID
+
+	issue := func(body string) string {
+		resp, close := request("POST", "/bots/"+botID+"/keys", jwt, strings.NewReader(body), t)
+		defer close()
+		assert.Equal(t, 200, resp.StatusCode)
+		issued := struct {
+			APIKey string `json:"api_key"`
+		}{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&issued))
+		return issued.APIKey
+	}
+	apiKey := issue(`{"name": "ci"}`)
+	readOnlyKey := issue(`{"name": "reader", "read_only": true}`)
+
+	// ボットもユーザーと同じくルームのメンバーとして追加する
+	resp2, close2 := request("POST", "/room/"+roomID+"/admin/add_member", jwt, strings.NewReader(`{"member_id": "`+createdBot.Bot.Sender+`"}`), t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+
+	resp3 := botRequest("POST", "/message/"+roomID+"/send", apiKey, strings.NewReader(`{"message": "deployed"}`))
+	defer resp3.Body.Close()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	resp4, close4 := request("GET", "/message/"+roomID+"/list", jwt, nil, t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+	list := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp4.Body).Decode(&list))
+	if assert.Len(t, list["messages"], 1) {
+		assert.Equal(t, "bot:"+botID, list["messages"][0].Sender)
+		if assert.NotNil(t, list["messages"][0].Bot) {
+			assert.Equal(t, "bot", list["messages"][0].Bot.Kind)
+			assert.Equal(t, "Deploy Bot", list["messages"][0].Bot.Name)
+		}
+	}
+
+	// 読み取り専用のキーでは送信できない
+	resp5 := botRequest("POST", "/message/"+roomID+"/send", readOnlyKey, strings.NewReader(`{"message": "deployed"}`))
+	defer resp5.Body.Close()
+	assert.Equal(t, 403, resp5.StatusCode)
+
+	resp6 := botRequest("GET", "/message/"+roomID+"/list", readOnlyKey, nil)
+	defer resp6.Body.Close()
+	assert.Equal(t, 200, resp6.StatusCode)
+
+	// ボットはボットを管理できない
+	resp7 := botRequest("GET", "/bots", readOnlyKey, nil)
+	defer resp7.Body.Close()
+	assert.Equal(t, 403, resp7.StatusCode)
+
+	resp8, close8 := request("GET", "/bots/"+botID+"/keys", jwt, nil, t)
+	defer close8()
+	assert.Equal(t, 200, resp8.StatusCode)
+	keys := struct {
+		APIKeys []dto.BotAPIKeyResponse `json:"api_keys"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp8.Body).Decode(&keys))
+	assert.Len(t, keys.APIKeys, 2)
+	keyID := ""
+	for _, key := range keys.APIKeys {
+		if key.Name == "ci" {
+			keyID = key.ID
+		}
+	}
+
+	resp9, close9 := request("DELETE", "/bots/"+botID+"/keys/"+keyID, jwt, nil, t)
+	defer close9()
+	assert.Equal(t, 200, resp9.StatusCode)
+
+	// 失効したキーは使えなくなる
+	resp10 := botRequest("POST", "/message/"+roomID+"/send", apiKey, strings.NewReader(`{"message": "deployed"}`))
+	defer resp10.Body.Close()
+	assert.Equal(t, 401, resp10.StatusCode)
+}
+
+func TestSlashCommands(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Command Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+
+	send := func(message string) (int, map[string]string) {
+		body, _ := json.Marshal(map[string]string{"message": message})
+		resp, close := request("POST", "/message/"+roomID+"/send", jwt, strings.NewReader(string(body)), t)
+		defer close()
+		result := map[string]string{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return resp.StatusCode, result
+	}
+
+	status, result := send("/me waves")
+	assert.Equal(t, 200, status)
+	assert.NotEmpty(t, result["message_id"])
+
+	status, _ = send("/topic Release planning")
+	assert.Equal(t, 200, status)
+
+	status, result = send("/topic")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "Topic: Release planning", result["ephemeral"])
+
+	status, result = send("/invite @new-member")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "Invited @new-member.", result["ephemeral"])
+
+	status, result = send("/mute")
+	assert.Equal(t, 200, status)
+	assert.Contains(t, result["ephemeral"], "Muted")
+
+	// 存在しないコマンドは実行したユーザーにだけエラーを返し、メッセージとしては保存しない
+	status, result = send("/shrug")
+	assert.Equal(t, 404, status)
+	assert.Equal(t, "unknown command: /shrug", result["error"])
+
+	status, _ = send("//shrug")
+	assert.Equal(t, 200, status)
+
+	resp, close := request("GET", "/message/"+roomID+"/list", jwt, nil, t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+	list := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&list))
+	if assert.Len(t, list["messages"], 3) {
+		assert.Equal(t, "me", list["messages"][0].Type)
+		assert.Equal(t, "waves", list["messages"][0].Message)
+		assert.Equal(t, "topic", list["messages"][1].Type)
+		assert.Equal(t, "/shrug", list["messages"][2].Message)
+	}
+
+	resp2, close2 := request("GET", "/room/list", jwt, nil, t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+	rooms := map[string][]dto.RoomListResponse{}
+	assert.NoError(t, json.NewDecoder(resp2.Body).Decode(&rooms))
+	found := false
+	for _, r := range rooms["rooms"] {
+		if r.ID == roomID {
+			found = true
+			assert.Equal(t, "Release planning", r.Topic)
+			assert.True(t, r.IsMuted)
+			assert.Equal(t, 2, r.MemberCount)
+		}
+	}
+	assert.True(t, found)
+
+	// ボットが登録したコマンドは一覧に表示される
+	resp3, close3 := request("POST", "/bots", jwt, strings.NewReader(`{"name": "Deploy Bot"}`), t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+	createdBot := struct {
+		Bot dto.BotAccountResponse `json:"bot"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp3.Body).Decode(&createdBot))
+	botID := createdBot.Bot.ID
+
+	resp4, close4 := request("POST", "/bots/"+botID+"/commands", jwt, strings.NewReader(`{"name": "deploy", "description": "Deploy the app", "url": "https://example.com/deploy"}`), t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+	created := struct {
+		Command dto.SlashCommandResponse `json:"command"`
+		Secret  string                   `json:"secret"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp4.Body).Decode(&created))
+	assert.NotEmpty(t, created.Secret)
+
+	resp5, close5 := request("POST", "/bots/"+botID+"/commands", jwt, strings.NewReader(`{"name": "me", "url": "https://example.com/me"}`), t)
+	defer close5()
+	assert.Equal(t, 409, resp5.StatusCode)
+
+	resp6, close6 := request("GET", "/commands", jwt, nil, t)
+	defer close6()
+	assert.Equal(t, 200, resp6.StatusCode)
+	catalog := struct {
+		Commands []dto.CommandCatalogResponse `json:"commands"`
+	}{}
+	assert.NoError(t, json.NewDecoder(resp6.Body).Decode(&catalog))
+	names := []string{}
+	for _, command := range catalog.Commands {
+		names = append(names, command.Name)
+	}
+	assert.Contains(t, names, "me")
+	assert.Contains(t, names, "deploy")
+
+	resp7, close7 := request("DELETE", "/bots/"+botID+"/commands/"+created.Command.ID, jwt, nil, t)
+	defer close7()
+	assert.Equal(t, 200, resp7.StatusCode)
+
+	status, _ = send("/deploy prod")
+	assert.Equal(t, 404, status)
+}
+
+func TestPolls(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Poll Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid", "member-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	memberJwt := createJwt("member-uuid", "member@example.com", time.Now().Add(1*time.Hour))
+
+	resp, close := request("POST", "/message/"+roomID+"/polls", jwt, strings.NewReader(`{"question": "Lunch?", "options": ["Sushi", "Ramen", "Curry"], "multiple_choice": true}`), t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+	created := map[string]string{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	messageID := created["message_id"]
+
+	vote := func(jwt string, body string) (int, dto.PollResponse) {
+		resp, close := request("POST", "/message/"+roomID+"/"+messageID+"/poll/vote", jwt, strings.NewReader(body), t)
+		defer close()
+		result := map[string]dto.PollResponse{}
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return resp.StatusCode, result["poll"]
+	}
+
+	status, poll := vote(jwt, `{"options": [0, 2]}`)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, []int{0, 2}, poll.MyVotes)
+
+	// 投票し直すと前の投票を置き換える
+	status, _ = vote(memberJwt, `{"options": [1]}`)
+	assert.Equal(t, 200, status)
+	status, poll = vote(memberJwt, `{"options": [2]}`)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 2, poll.TotalVoters)
+	assert.Equal(t, []int{1, 0, 2}, []int{poll.Options[0].Count, poll.Options[1].Count, poll.Options[2].Count})
+
+	status, _ = vote(jwt, `{"options": [3]}`)
+	assert.Equal(t, 400, status)
+
+	// 作成者以外のメンバーは締め切れない
+	resp2, close2 := request("POST", "/message/"+roomID+"/"+messageID+"/poll/close", memberJwt, nil, t)
+	defer close2()
+	assert.Equal(t, 403, resp2.StatusCode)
+
+	resp3, close3 := request("POST", "/message/"+roomID+"/"+messageID+"/poll/close", jwt, nil, t)
+	defer close3()
+	assert.Equal(t, 200, resp3.StatusCode)
+
+	status, _ = vote(memberJwt, `{"options": [0]}`)
+	assert.Equal(t, 409, status)
+
+	// /poll でも単一選択の投票を作成できる
+	resp4, close4 := request("POST", "/message/"+roomID+"/send", jwt, strings.NewReader(`{"message": "/poll Meeting day? | Mon | Tue"}`), t)
+	defer close4()
+	assert.Equal(t, 200, resp4.StatusCode)
+
+	resp5, close5 := request("GET", "/message/"+roomID+"/list", jwt, nil, t)
+	defer close5()
+	assert.Equal(t, 200, resp5.StatusCode)
+	list := map[string][]dto.MessageResponse{}
+	assert.NoError(t, json.NewDecoder(resp5.Body).Decode(&list))
+	if assert.Len(t, list["messages"], 2) {
+		assert.Equal(t, "poll", list["messages"][0].Type)
+		if assert.NotNil(t, list["messages"][0].Poll) {
+			assert.True(t, list["messages"][0].Poll.Closed)
+			assert.Equal(t, []int{0, 2}, list["messages"][0].Poll.MyVotes)
+		}
+		if assert.NotNil(t, list["messages"][1].Poll) {
+			assert.Equal(t, "Meeting day?", list["messages"][1].Poll.Question)
+			assert.False(t, list["messages"][1].Poll.MultipleChoice)
+		}
+	}
+}
+
+func TestReminders(t *testing.T) {
+	var err error
+	mongoHelper.MongoCleanUp()
+
+	room := model.Room{
+		Name:      "Reminder Room",
+		OwnerID:   "test-uuid",
+		IsPrivate: true,
+		Members:   []string{"test-uuid", "member-uuid"},
+		CreatedAt: time.Now(),
+	}
+	roomID, err := mongoHelper.Insert(
+		model.RoomCollectionName,
+		room,
+	)
+	assert.NoError(t, err)
+
+	messageID, err := mongoHelper.Insert(
+		model.MessageCollectionName,
+		model.Message{
+			RoomID:        roomID,
+			Sender:        "member-uuid",
+			Message:       "Can you review this?",
+			CreatedAt:     time.Now(),
+			IsReadUserIds: []string{},
+		},
+	)
+	assert.NoError(t, err)
+
+	jwt := createJwt("test-uuid", "test@example.com", time.Now().Add(1*time.Hour))
+	outsiderJwt := createJwt("outsider-uuid", "outsider@example.com", time.Now().Add(1*time.Hour))
+
+	resp, close := request("POST", "/reminders", jwt, strings.NewReader(`{"text": "Call Bob", "in": "2h"}`), t)
+	defer close()
+	assert.Equal(t, 200, resp.StatusCode)
+	created := map[string]dto.ReminderResponse{}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	reminderID := created["reminder"].ID
+	assert.NotEmpty(t, reminderID)
+
+	// メッセージへのリマインダーは本文を控えておく。メンバー以外は設定できない
+	resp2, close2 := request("POST", "/message/"+roomID+"/"+messageID+"/reminders", jwt, strings.NewReader(`{"in": "1h"}`), t)
+	defer close2()
+	assert.Equal(t, 200, resp2.StatusCode)
+	onMessage := map[string]dto.ReminderResponse{}
+	assert.NoError(t, json.NewDecoder(resp2.Body).Decode(&onMessage))
+	assert.Equal(t, "Can you review this?", onMessage["reminder"].Snapshot)
+
+	resp3, close3 := request("POST", "/message/"+roomID+"/"+messageID+"/reminders", outsiderJwt, strings.NewReader(`{"in": "1h"}`), t)
+	defer close3()
+	assert.Equal(t, 403, resp3.StatusCode)
+
+	// 過去の日時は指定できない
+	past := fmt.Sprintf(`{"text": "Too late", "remind_at": %q}`, time.Now().Add(-time.Hour).UTC().Format(time.RFC3339))
+	resp4, close4 := request("POST", "/reminders", jwt, strings.NewReader(past), t)
+	defer close4()
+	assert.Equal(t, 400, resp4.StatusCode)
+
+	// /remind でも設定できる
+	resp5, close5 := request("POST", "/message/"+roomID+"/send", jwt, strings.NewReader(`{"message": "/remind me in 3d Renew the domain"}`), t)
+	defer close5()
+	assert.Equal(t, 200, resp5.StatusCode)
+
+	list := func(jwt string) []dto.ReminderResponse {
+		resp, close := request("GET", "/reminders", jwt, nil, t)
+		defer close()
+		assert.Equal(t, 200, resp.StatusCode)
+		result := map[string][]dto.ReminderResponse{}
+		_ = json.NewDecoder(resp.Body).Decode(&result)
+		return result["reminders"]
+	}
+
+	// 通知日時の早い順に返し、他のユーザーからは見えない
+	reminders := list(jwt)
+	if assert.Len(t, reminders, 3) {
+		assert.Equal(t, onMessage["reminder"].ID, reminders[0].ID)
+		assert.Equal(t, "Call Bob", reminders[1].Text)
+		assert.Equal(t, "Renew the domain", reminders[2].Text)
+	}
+	assert.Len(t, list(outsiderJwt), 0)
+
+	resp6, close6 := request("POST", "/reminders/"+reminderID+"/snooze", outsiderJwt, strings.NewReader(`{"in": "1d"}`), t)
+	defer close6()
+	assert.Equal(t, 404, resp6.StatusCode)
+
+	resp7, close7 := request("POST", "/reminders/"+reminderID+"/snooze", jwt, strings.NewReader(`{"in": "1d"}`), t)
+	defer close7()
+	assert.Equal(t, 200, resp7.StatusCode)
+
+	resp8, close8 := request("DELETE", "/reminders/"+reminderID, jwt, nil, t)
+	defer close8()
+	assert.Equal(t, 200, resp8.StatusCode)
+
+	// キャンセル済みのものはもう一度キャンセルできない
+	resp9, close9 := request("DELETE", "/reminders/"+reminderID, jwt, nil, t)
+	defer close9()
+	assert.Equal(t, 409, resp9.StatusCode)
+
+	assert.Len(t, list(jwt), 2)
+
+	exists, err := mongoHelper.ExistContents(model.ReminderCollectionName, bson.M{"userid": "test-uuid", "status": consts.ReminderStatus.Canceled})
+	assert.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestImportSlackRerun(t *testing.T) {
+	var err error
+	// API を起動する前のデータベースと同じく、インデックスがない状態から取り込む
+	mongoHelper.MongoCleanUp()
+
+	dir := t.TempDir()
+	archive := filepath.Join(dir, "export.zip")
+	file, err := os.Create(archive)
+	assert.NoError(t, err)
+	w := zip.NewWriter(file)
+	for path, content := range map[string]any{
+		"channels.json": []map[string]any{
+			{"id": "C01", "name": "general", "created": 1500000000, "creator": "U01", "members": []string{"U01"}},
+		},
+		"general/2017-07-14.json": []map[string]any{
+			{"type": "message", "user": "U01", "text": "hello", "ts": "1500000000.000100"},
+			{"type": "message", "user": "U01", "text": "again", "ts": "1500000001.000100"},
+		},
+	} {
+		entry, err := w.Create(path)
+		assert.NoError(t, err)
+		assert.NoError(t, json.NewEncoder(entry).Encode(content))
+	}
+	assert.NoError(t, w.Close())
+	assert.NoError(t, file.Close())
+
+	mapping := filepath.Join(dir, "mapping.json")
+	assert.NoError(t, os.WriteFile(mapping, []byte(`{"U01": "slack-uuid-1"}`), 0o644))
+
+	run := func() string {
+		cmd := command.NewImportSlackCommand()
+		cmd.SetUp(
+			usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo()),
+			command.ImportSlackOptions{TimeOut: 60, Mapping: mapping},
+		)
+		return funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{archive})
+		})
+	}
+
+	first := run()
+	assert.Contains(t, first, "Messages: 2 imported, 0 already imported")
+	assert.Contains(t, first, "処理完了")
+
+	// 再実行しても登録し直さない
+	second := run()
+	assert.Contains(t, second, "Channels: 0 imported, 1 already imported")
+	assert.Contains(t, second, "Messages: 0 imported, 2 already imported")
+	assert.Contains(t, second, "処理完了")
+
+	rooms, err := mongoHelper.CountContents(model.RoomCollectionName, bson.M{"external_id": "slack:C01"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), rooms)
+	messages, err := mongoHelper.CountContents(model.MessageCollectionName, bson.M{"sender": "slack-uuid-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), messages)
 }
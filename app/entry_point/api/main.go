@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/app"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabredis"
@@ -22,6 +23,10 @@ func SetupMongo() (*usecase.Mongo, error) {
 		fmt.Println("Failed to connect to MongoDB:", err)
 		return nil, err
 	}
+	if err := usecase.NewMongoIndexUseCaseStruct(model.MongoIndexes).EnsureIndexes(); err != nil {
+		fmt.Println("Failed to create MongoDB indexes:", err)
+		return nil, err
+	}
 	return mongo, nil
 }
 
@@ -64,6 +69,7 @@ func main() {
 	}
 	app := SetupRouter(mongo, redis)
 	defer app.Shutdown()
+	app.StartWorkers()
 
 	for _, route := range app.Echo.Routes() {
 		fmt.Printf("Method: %s, Path: %s\n", route.Method, route.Path)
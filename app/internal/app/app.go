@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"sync"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/provider"
@@ -15,6 +17,9 @@ type App struct {
 	provider   *provider.Provider
 	mongo      *usecase.Mongo
 	redis      *usecase.Redis
+
+	stopWorkers context.CancelFunc
+	workerGroup sync.WaitGroup
 }
 
 func NewApp() *App {
@@ -38,5 +43,11 @@ func (a *App) Init(
 func (a *App) Shutdown() {
 	fmt.Println("Shutting down the application...")
 	// ここにシャットダウン処理を追加
+	if a.stopWorkers != nil {
+		// 処理中のジョブが終わるのを待つ
+		a.stopWorkers()
+		a.workerGroup.Wait()
+		a.stopWorkers = nil
+	}
 	fmt.Println("Application shut down completed.")
 }
@@ -1,13 +1,19 @@
 package app
 
 import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/routing"
 )
 
 func (a *App) entryGlobalMiddleware() {
 	// 前処理系ミドルウェアをここに追加
-	a.Echo.Use(a.middleware.Jwt)
-	a.Echo.Use(a.middleware.Csrf)
+	// 受信 Webhook は外部サービスから呼ばれるので、URL のトークンで認証する（IncomingWebhookRoute を参照）
+	a.Echo.Use(middleware.SkipRoutes(a.middleware.BotAPIKey, consts.IncomingWebhookPath))
+	// ボットの API キーで認証できたリクエストは JWT を確認しない
+	// API キーはヘッダーで送られ、ブラウザから自動で送信されることはないので CSRF トークンも確認しない
+	a.Echo.Use(middleware.SkipRoutes(middleware.SkipBotRequests(a.middleware.Jwt), consts.IncomingWebhookPath))
+	a.Echo.Use(middleware.SkipRoutes(middleware.SkipBotRequests(a.middleware.Csrf), consts.IncomingWebhookPath))
 	// 後処理系ミドルウェアをここに追加
 	// 例: a.Echo.Use(a.middleware.Logging)
 }
@@ -26,4 +32,56 @@ func (a *App) entryRoutes() {
 	routing.MessageRoute(
 		a.provider.BindMessageHandler(),
 	)
+
+	routing.ReceiptRoute(
+		a.provider.BindReceiptHandler(),
+	)
+
+	routing.PollRoute(
+		a.provider.BindPollHandler(),
+	)
+
+	routing.AttachmentRoute(
+		a.provider.BindAttachmentHandler(),
+	)
+
+	routing.ScheduledMessageRoute(
+		a.provider.BindScheduledMessageHandler(),
+	)
+
+	routing.ReminderRoute(
+		a.provider.BindReminderHandler(),
+	)
+
+	routing.ModerationRoute(
+		a.provider.BindModerationHandler(),
+	)
+
+	routing.EventRoute(
+		a.provider.BindEventHandler(),
+	)
+
+	routing.PresenceRoute(
+		a.provider.BindPresenceHandler(),
+	)
+
+	routing.TypingRoute(
+		a.provider.BindTypingHandler(),
+	)
+
+	routing.WebhookRoute(
+		a.provider.BindWebhookHandler(),
+	)
+
+	routing.IncomingWebhookRoute(
+		a.provider.BindIncomingWebhookHandler(),
+	)
+
+	routing.BotRoute(
+		a.provider.BindBotHandler(),
+	)
+
+	routing.SlashCommandRoute(
+		a.provider.BindSlashCommandHandler(),
+	)
 }
@@ -1,9 +1,11 @@
 package app
 
 import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/provider"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/labstack/echo/v4"
 )
 
 func (a *App) initProviders(
@@ -20,8 +22,20 @@ func (a *App) initProviders(
 func (a *App) initMiddlewares() {
 	// ミドルウェアの初期化
 	a.middleware = &middleware.Middleware{
-		Csrf: a.provider.BindCsrfMiddleware().Handler(),
-		Jwt:  a.provider.BindJwtMiddleware().Handler(),
-		Room: a.provider.BindRoomMiddleware().Handler(),
+		Csrf:            a.provider.BindCsrfMiddleware().Handler(),
+		Jwt:             a.provider.BindJwtMiddleware().Handler(),
+		Room:            a.provider.BindRoomMiddleware().Handler(),
+		Moderator:       a.provider.BindModeratorMiddleware().Handler(),
+		BotAPIKey:       a.provider.BindBotAPIKeyMiddleware().Handler(),
+		IncomingWebhook: a.provider.BindIncomingWebhookMiddleware().Handler(),
+		RateLimit:       a.initRateLimitMiddlewares(),
 	}
 }
+
+func (a *App) initRateLimitMiddlewares() map[string]echo.MiddlewareFunc {
+	rateLimits := map[string]echo.MiddlewareFunc{}
+	for group := range consts.RateLimitRules {
+		rateLimits[group] = a.provider.BindRateLimitMiddleware(group).Handler()
+	}
+	return rateLimits
+}
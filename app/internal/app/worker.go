@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/worker"
+)
+
+func (a *App) workers() []*worker.Runner {
+	return []*worker.Runner{
+		worker.NewRunner(
+			"thumbnail",
+			a.provider.BindThumbnailSvc().RunNext,
+			consts.ThumbnailWorkerInterval,
+		),
+		worker.NewRunner(
+			"scheduled_message",
+			a.provider.BindScheduledMessageSvc().RunNext,
+			consts.ScheduledMessageWorkerInterval,
+		),
+		worker.NewRunner(
+			"reminder",
+			a.provider.BindReminderSvc().RunNext,
+			consts.ReminderWorkerInterval,
+		),
+		worker.NewRunner(
+			"message_expiry",
+			a.provider.BindMessageExpirySvc().RunNext,
+			consts.MessageExpiryWorkerInterval,
+		),
+		worker.NewRunner(
+			"presence",
+			a.provider.BindPresenceSvc().RunNext,
+			consts.PresenceWorkerInterval,
+		),
+		worker.NewRunner(
+			"webhook",
+			a.provider.BindWebhookSvc().RunNext,
+			consts.WebhookWorkerInterval,
+		),
+	}
+}
+
+// バックグラウンドの処理を開始する（Shutdown で停止する）
+func (a *App) StartWorkers() {
+	if a.stopWorkers != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.stopWorkers = cancel
+	for _, runner := range a.workers() {
+		a.workerGroup.Add(1)
+		go func() {
+			defer a.workerGroup.Done()
+			fmt.Println("Worker started:", runner.Name())
+			runner.Run(ctx)
+			fmt.Println("Worker stopped:", runner.Name())
+		}()
+	}
+}
@@ -12,6 +12,10 @@ type Cmd struct {
 	versionCmd        command.VersionCommandInterface
 	roomListCmd       command.RoomListCommandInterface
 	forbiddenWordsCmd command.ForbiddenWordsCommandInterface
+	retentionPurgeCmd command.RetentionPurgeCommandInterface
+	deletedPurgeCmd   command.DeletedPurgeCommandInterface
+	exportRoomCmd     command.ExportRoomCommandInterface
+	importSlackCmd    command.ImportSlackCommandInterface
 }
 
 func NewCmd() *Cmd {
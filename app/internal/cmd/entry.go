@@ -4,6 +4,7 @@ import (
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/command"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabredis"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +13,10 @@ func (c *Cmd) bind() {
 	c.versionCmd = command.NewVersionCommand()
 	c.roomListCmd = command.NewRoomListCommand()
 	c.forbiddenWordsCmd = command.NewForbiddenWordsCommand()
+	c.retentionPurgeCmd = command.NewRetentionPurgeCommand()
+	c.deletedPurgeCmd = command.NewDeletedPurgeCommand()
+	c.exportRoomCmd = command.NewExportRoomCommand()
+	c.importSlackCmd = command.NewImportSlackCommand()
 }
 
 func (c *Cmd) rootSetUp() {
@@ -41,30 +46,102 @@ func (c *Cmd) entry() {
 			c.roomListCmd.Run(args)
 		},
 	)
-	c.set(
+	c.forbiddenWordsFlags(c.set(
 		"forbidden-words",
 		"Manage forbidden words",
 		func(args []string) {
-			c.forbiddenWordsCmd.SetUp(c.initMongo(), 100)
+			c.forbiddenWordsCmd.SetUp(
+				c.initMongo(),
+				forbiddenWordsTimeout,
+				forbiddenWordsConcurrency,
+			)
 			c.forbiddenWordsCmd.Run(args)
 		},
-	)
+	))
+	c.retentionPurgeFlags(c.set(
+		"retention-purge",
+		"Purge messages older than each room's retention period",
+		func(args []string) {
+			c.retentionPurgeCmd.SetUp(
+				c.initMongo(),
+				c.initStorage(),
+				command.RetentionPurgeOptions{
+					TimeOut:     retentionPurgeTimeout,
+					Concurrency: retentionPurgeConcurrency,
+					BatchSize:   retentionPurgeBatchSize,
+					DryRun:      retentionPurgeDryRun,
+					Archive:     retentionPurgeArchive,
+				},
+			)
+			c.retentionPurgeCmd.Run(args)
+		},
+	))
+	c.deletedPurgeFlags(c.set(
+		"deleted-purge",
+		"Permanently delete messages whose deletion grace period has passed",
+		func(args []string) {
+			c.deletedPurgeCmd.SetUp(
+				c.initMongo(),
+				c.initStorage(),
+				command.DeletedPurgeOptions{
+					TimeOut:   deletedPurgeTimeout,
+					BatchSize: deletedPurgeBatchSize,
+					DryRun:    deletedPurgeDryRun,
+				},
+			)
+			c.deletedPurgeCmd.Run(args)
+		},
+	))
+	c.exportRoomFlags(c.set(
+		"export-room",
+		"Export a room's messages as json, csv, txt or html",
+		func(args []string) {
+			c.exportRoomCmd.SetUp(
+				c.initMongo(),
+				c.initRedis(),
+				command.ExportRoomOptions{
+					TimeOut:  exportRoomTimeout,
+					Format:   exportRoomFormat,
+					Timezone: exportRoomTimezone,
+					Output:   exportRoomOutput,
+				},
+			)
+			c.exportRoomCmd.Run(args)
+		},
+	))
+	c.importSlackFlags(c.set(
+		"import-slack",
+		"Import channels and messages from a Slack export archive",
+		func(args []string) {
+			c.importSlackCmd.SetUp(
+				c.initMongo(),
+				command.ImportSlackOptions{
+					TimeOut:      importSlackTimeout,
+					Mapping:      importSlackMapping,
+					DefaultOwner: importSlackDefaultOwner,
+					Report:       importSlackReport,
+					DryRun:       importSlackDryRun,
+				},
+			)
+			c.importSlackCmd.Run(args)
+		},
+	))
 }
 
 func (c *Cmd) set(
 	use string,
 	short string,
 	run func(args []string),
-) {
-	c.Cmd.AddCommand(
-		&cobra.Command{
-			Use:   use,
-			Short: short,
-			Run: func(cmd *cobra.Command, args []string) {
-				run(args)
-			},
+) *cobra.Command {
+	subCmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			run(args)
 		},
-	)
+	}
+	c.Cmd.AddCommand(subCmd)
+	return subCmd
 }
 
 func (c *Cmd) initMongo() *usecase.MongoUseCaseStruct {
@@ -75,3 +152,16 @@ func (c *Cmd) initMongo() *usecase.MongoUseCaseStruct {
 		mongo,
 	)
 }
+
+func (c *Cmd) initRedis() *usecase.RedisUseCaseStruct {
+	return usecase.NewRedisUseCaseStruct(
+		atylabredis.NewRedisConnectorStruct(),
+		usecase.NewRedis(),
+	)
+}
+
+func (c *Cmd) initStorage() *usecase.StorageUseCaseStruct {
+	return usecase.NewStorageUseCaseStruct(
+		usecase.NewStorage(),
+	)
+}
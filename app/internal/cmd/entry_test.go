@@ -3,6 +3,8 @@ package cmd
 import (
 	"testing"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/command"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/command_mock"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,6 +27,10 @@ func TestEntry(t *testing.T) {
 		"version":         {"cmd": "version"},
 		"room-list":       {"cmd": "room-list"},
 		"forbidden-words": {"cmd": "forbidden-words"},
+		"retention-purge": {"cmd": "retention-purge"},
+		"deleted-purge":   {"cmd": "deleted-purge"},
+		"export-room":     {"cmd": "export-room"},
+		"import-slack":    {"cmd": "import-slack"},
 	}
 
 	for name, expect := range expected {
@@ -35,17 +41,46 @@ func TestEntry(t *testing.T) {
 			versionCmd := new(command_mock.VersionCommandMock)
 			roomListCmd := new(command_mock.RoomListCommandMock)
 			forbiddenWordsCmd := new(command_mock.ForbiddenWordsCommandMock)
+			retentionPurgeCmd := new(command_mock.RetentionPurgeCommandMock)
+			deletedPurgeCmd := new(command_mock.DeletedPurgeCommandMock)
+			exportRoomCmd := new(command_mock.ExportRoomCommandMock)
+			importSlackCmd := new(command_mock.ImportSlackCommandMock)
 
 			versionCmd.On("Run", mock.Anything).Return()
 			roomListCmd.On("SetUp", mock.Anything).Return()
 			roomListCmd.On("Run", mock.Anything).Return()
-			forbiddenWordsCmd.On("SetUp", mock.Anything, mock.Anything).Return()
+			forbiddenWordsCmd.On("SetUp", mock.Anything, 100, 5).Return()
 			forbiddenWordsCmd.On("Run", mock.Anything).Return()
+			retentionPurgeCmd.On("SetUp", mock.Anything, mock.Anything, command.RetentionPurgeOptions{
+				TimeOut:     600,
+				Concurrency: 5,
+				BatchSize:   consts.RetentionPurgeBatchSize,
+			}).Return()
+			retentionPurgeCmd.On("Run", mock.Anything).Return()
+			deletedPurgeCmd.On("SetUp", mock.Anything, mock.Anything, command.DeletedPurgeOptions{
+				TimeOut:   600,
+				BatchSize: consts.DeletedPurgeBatchSize,
+			}).Return()
+			deletedPurgeCmd.On("Run", mock.Anything).Return()
+			exportRoomCmd.On("SetUp", mock.Anything, mock.Anything, command.ExportRoomOptions{
+				TimeOut:  600,
+				Format:   "json",
+				Timezone: "UTC",
+			}).Return()
+			exportRoomCmd.On("Run", mock.Anything).Return()
+			importSlackCmd.On("SetUp", mock.Anything, command.ImportSlackOptions{
+				TimeOut: 3600,
+			}).Return()
+			importSlackCmd.On("Run", mock.Anything).Return()
 
 			c.rootCmd = rootCmd
 			c.versionCmd = versionCmd
 			c.roomListCmd = roomListCmd
 			c.forbiddenWordsCmd = forbiddenWordsCmd
+			c.retentionPurgeCmd = retentionPurgeCmd
+			c.deletedPurgeCmd = deletedPurgeCmd
+			c.exportRoomCmd = exportRoomCmd
+			c.importSlackCmd = importSlackCmd
 			c.rootSetUp()
 
 			c.entry()
@@ -74,6 +109,34 @@ func TestEntry(t *testing.T) {
 				forbiddenWordsCmd.AssertNotCalled(t, "SetUp")
 			}
 
+			if expect["cmd"] == "retention-purge" {
+				retentionPurgeCmd.AssertExpectations(t)
+			} else {
+				retentionPurgeCmd.AssertNotCalled(t, "Run")
+				retentionPurgeCmd.AssertNotCalled(t, "SetUp")
+			}
+
+			if expect["cmd"] == "deleted-purge" {
+				deletedPurgeCmd.AssertExpectations(t)
+			} else {
+				deletedPurgeCmd.AssertNotCalled(t, "Run")
+				deletedPurgeCmd.AssertNotCalled(t, "SetUp")
+			}
+
+			if expect["cmd"] == "export-room" {
+				exportRoomCmd.AssertExpectations(t)
+			} else {
+				exportRoomCmd.AssertNotCalled(t, "Run")
+				exportRoomCmd.AssertNotCalled(t, "SetUp")
+			}
+
+			if expect["cmd"] == "import-slack" {
+				importSlackCmd.AssertExpectations(t)
+			} else {
+				importSlackCmd.AssertNotCalled(t, "Run")
+				importSlackCmd.AssertNotCalled(t, "SetUp")
+			}
+
 		})
 	}
 }
@@ -85,3 +148,130 @@ func TestInitMongo(t *testing.T) {
 		t.Errorf("Expected mongo to be initialized, got nil")
 	}
 }
+
+func TestInitRedis(t *testing.T) {
+	c := &Cmd{}
+	redis := c.initRedis()
+	if redis == nil {
+		t.Errorf("Expected redis to be initialized, got nil")
+	}
+}
+
+func TestInitStorage(t *testing.T) {
+	c := &Cmd{}
+	storage := c.initStorage()
+	if storage == nil {
+		t.Errorf("Expected storage to be initialized, got nil")
+	}
+}
+
+func TestEntryForbiddenWordsFlags(t *testing.T) {
+	c := &Cmd{}
+	rootCmd := new(command_mock.RootCommandMock)
+	forbiddenWordsCmd := new(command_mock.ForbiddenWordsCommandMock)
+	forbiddenWordsCmd.On("SetUp", mock.Anything, 30, 2).Return()
+	forbiddenWordsCmd.On("Run", mock.Anything).Return()
+
+	c.rootCmd = rootCmd
+	c.forbiddenWordsCmd = forbiddenWordsCmd
+	c.rootSetUp()
+	c.entry()
+
+	c.Cmd.SetArgs([]string{"forbidden-words", "--timeout", "30", "--concurrency", "2"})
+	c.Cmd.Execute()
+
+	forbiddenWordsCmd.AssertExpectations(t)
+}
+
+func TestEntryRetentionPurgeFlags(t *testing.T) {
+	c := &Cmd{}
+	rootCmd := new(command_mock.RootCommandMock)
+	retentionPurgeCmd := new(command_mock.RetentionPurgeCommandMock)
+	retentionPurgeCmd.On("SetUp", mock.Anything, mock.Anything, command.RetentionPurgeOptions{
+		TimeOut:     60,
+		Concurrency: 2,
+		BatchSize:   50,
+		DryRun:      true,
+		Archive:     true,
+	}).Return()
+	retentionPurgeCmd.On("Run", mock.Anything).Return()
+
+	c.rootCmd = rootCmd
+	c.retentionPurgeCmd = retentionPurgeCmd
+	c.rootSetUp()
+	c.entry()
+
+	c.Cmd.SetArgs([]string{"retention-purge", "--timeout", "60", "--concurrency", "2", "--batch-size", "50", "--dry-run", "--archive"})
+	c.Cmd.Execute()
+
+	retentionPurgeCmd.AssertExpectations(t)
+}
+
+func TestEntryDeletedPurgeFlags(t *testing.T) {
+	c := &Cmd{}
+	rootCmd := new(command_mock.RootCommandMock)
+	deletedPurgeCmd := new(command_mock.DeletedPurgeCommandMock)
+	deletedPurgeCmd.On("SetUp", mock.Anything, mock.Anything, command.DeletedPurgeOptions{
+		TimeOut:   60,
+		BatchSize: 50,
+		DryRun:    true,
+	}).Return()
+	deletedPurgeCmd.On("Run", mock.Anything).Return()
+
+	c.rootCmd = rootCmd
+	c.deletedPurgeCmd = deletedPurgeCmd
+	c.rootSetUp()
+	c.entry()
+
+	c.Cmd.SetArgs([]string{"deleted-purge", "--timeout", "60", "--batch-size", "50", "--dry-run"})
+	c.Cmd.Execute()
+
+	deletedPurgeCmd.AssertExpectations(t)
+}
+
+func TestEntryExportRoomFlags(t *testing.T) {
+	c := &Cmd{}
+	rootCmd := new(command_mock.RootCommandMock)
+	exportRoomCmd := new(command_mock.ExportRoomCommandMock)
+	exportRoomCmd.On("SetUp", mock.Anything, mock.Anything, command.ExportRoomOptions{
+		TimeOut:  60,
+		Format:   "csv",
+		Timezone: "Asia/Tokyo",
+		Output:   "room.csv",
+	}).Return()
+	exportRoomCmd.On("Run", []string{"room-id"}).Return()
+
+	c.rootCmd = rootCmd
+	c.exportRoomCmd = exportRoomCmd
+	c.rootSetUp()
+	c.entry()
+
+	c.Cmd.SetArgs([]string{"export-room", "room-id", "--timeout", "60", "--format", "csv", "--tz", "Asia/Tokyo", "-o", "room.csv"})
+	c.Cmd.Execute()
+
+	exportRoomCmd.AssertExpectations(t)
+}
+
+func TestEntryImportSlackFlags(t *testing.T) {
+	c := &Cmd{}
+	rootCmd := new(command_mock.RootCommandMock)
+	importSlackCmd := new(command_mock.ImportSlackCommandMock)
+	importSlackCmd.On("SetUp", mock.Anything, command.ImportSlackOptions{
+		TimeOut:      60,
+		Mapping:      "mapping.json",
+		DefaultOwner: "uuid-admin",
+		Report:       "skipped.csv",
+		DryRun:       true,
+	}).Return()
+	importSlackCmd.On("Run", []string{"export.zip"}).Return()
+
+	c.rootCmd = rootCmd
+	c.importSlackCmd = importSlackCmd
+	c.rootSetUp()
+	c.entry()
+
+	c.Cmd.SetArgs([]string{"import-slack", "export.zip", "--timeout", "60", "--mapping", "mapping.json", "--default-owner", "uuid-admin", "--report", "skipped.csv", "--dry-run"})
+	c.Cmd.Execute()
+
+	importSlackCmd.AssertExpectations(t)
+}
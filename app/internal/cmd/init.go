@@ -1,7 +1,36 @@
 package cmd
 
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/spf13/cobra"
+)
+
 var (
 	verbose bool
+
+	forbiddenWordsTimeout     int
+	forbiddenWordsConcurrency int
+
+	retentionPurgeTimeout     int
+	retentionPurgeConcurrency int
+	retentionPurgeBatchSize   int
+	retentionPurgeDryRun      bool
+	retentionPurgeArchive     bool
+
+	deletedPurgeTimeout   int
+	deletedPurgeBatchSize int
+	deletedPurgeDryRun    bool
+
+	exportRoomTimeout  int
+	exportRoomFormat   string
+	exportRoomTimezone string
+	exportRoomOutput   string
+
+	importSlackTimeout      int
+	importSlackMapping      string
+	importSlackDefaultOwner string
+	importSlackReport       string
+	importSlackDryRun       bool
 )
 
 func (c *Cmd) setupFlags() {
@@ -13,3 +42,133 @@ func (c *Cmd) setupFlags() {
 		"verbose output",
 	)
 }
+
+func (c *Cmd) forbiddenWordsFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&forbiddenWordsTimeout,
+		"timeout",
+		100,
+		"timeout in seconds for the whole scan",
+	)
+	cmd.Flags().IntVar(
+		&forbiddenWordsConcurrency,
+		"concurrency",
+		5,
+		"number of rooms scanned at the same time",
+	)
+}
+
+func (c *Cmd) retentionPurgeFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&retentionPurgeTimeout,
+		"timeout",
+		600,
+		"timeout in seconds for the whole purge",
+	)
+	cmd.Flags().IntVar(
+		&retentionPurgeConcurrency,
+		"concurrency",
+		5,
+		"number of rooms purged at the same time",
+	)
+	cmd.Flags().IntVar(
+		&retentionPurgeBatchSize,
+		"batch-size",
+		consts.RetentionPurgeBatchSize,
+		"number of messages deleted per batch",
+	)
+	cmd.Flags().BoolVar(
+		&retentionPurgeDryRun,
+		"dry-run",
+		false,
+		"only count the messages that would be purged",
+	)
+	cmd.Flags().BoolVar(
+		&retentionPurgeArchive,
+		"archive",
+		false,
+		"copy messages to the archive collection before deleting them",
+	)
+}
+
+func (c *Cmd) deletedPurgeFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&deletedPurgeTimeout,
+		"timeout",
+		600,
+		"timeout in seconds for the whole purge",
+	)
+	cmd.Flags().IntVar(
+		&deletedPurgeBatchSize,
+		"batch-size",
+		consts.DeletedPurgeBatchSize,
+		"number of messages deleted per batch",
+	)
+	cmd.Flags().BoolVar(
+		&deletedPurgeDryRun,
+		"dry-run",
+		false,
+		"only count the messages that would be purged",
+	)
+}
+
+func (c *Cmd) exportRoomFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&exportRoomTimeout,
+		"timeout",
+		int(consts.ExportTimeout.Seconds()),
+		"timeout in seconds for the whole export",
+	)
+	cmd.Flags().StringVar(
+		&exportRoomFormat,
+		"format",
+		consts.ExportFormats.JSON,
+		"output format (json, csv, txt, html)",
+	)
+	cmd.Flags().StringVar(
+		&exportRoomTimezone,
+		"tz",
+		consts.ExportDefaultTimezone,
+		"IANA time zone used for timestamps (e.g. Asia/Tokyo)",
+	)
+	cmd.Flags().StringVarP(
+		&exportRoomOutput,
+		"output",
+		"o",
+		"",
+		"file to write the export to (defaults to stdout)",
+	)
+}
+
+func (c *Cmd) importSlackFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(
+		&importSlackTimeout,
+		"timeout",
+		3600,
+		"timeout in seconds for the whole import",
+	)
+	cmd.Flags().StringVar(
+		&importSlackMapping,
+		"mapping",
+		"",
+		`JSON file mapping Slack user IDs to UUIDs (e.g. {"U0123": "uuid"})`,
+	)
+	cmd.Flags().StringVar(
+		&importSlackDefaultOwner,
+		"default-owner",
+		"",
+		"UUID that owns channels whose creator is not in the mapping file",
+	)
+	cmd.Flags().StringVar(
+		&importSlackReport,
+		"report",
+		"",
+		"CSV file to write the skipped records to",
+	)
+	cmd.Flags().BoolVar(
+		&importSlackDryRun,
+		"dry-run",
+		false,
+		"only count the records that would be imported",
+	)
+}
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/cmd_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+type DeletedPurgeOptions struct {
+	// 全体のタイムアウト（秒）
+	TimeOut   int
+	BatchSize int
+	// 対象の件数を数えるだけで、削除しない
+	DryRun bool
+}
+
+type DeletedPurgeCommandInterface interface {
+	SetUp(mongo usecase.MongoUseCaseInterface, storage usecase.StorageUseCaseInterface, options DeletedPurgeOptions)
+	Run(args []string)
+}
+
+type DeletedPurgeCommand struct {
+	BaseCommand
+	message_svc    cmd_svc.MessageSvcInterface
+	attachment_svc service.AttachmentSvcInterface
+	clock          atylabclock.ClockInterface
+	options        DeletedPurgeOptions
+}
+
+func NewDeletedPurgeCommand() *DeletedPurgeCommand {
+	return &DeletedPurgeCommand{}
+}
+
+func (c *DeletedPurgeCommand) SetUp(
+	mongo usecase.MongoUseCaseInterface,
+	storage usecase.StorageUseCaseInterface,
+	options DeletedPurgeOptions,
+) {
+	c.message_svc = cmd_svc.NewMessageSvcStruct(
+		mongo,
+		usecase.NewMongoDriverUseCaseStruct(),
+	)
+	c.attachment_svc = service.NewAttachmentSvc(
+		storage,
+	)
+	c.clock = atylabclock.NewClock()
+	c.options = options
+}
+
+// 削除してから猶予期間を過ぎたメッセージを、添付ファイルとともに物理削除する
+func (c *DeletedPurgeCommand) Run(args []string) {
+	gctx, gctxCancel := context.WithTimeout(context.Background(), time.Duration(c.options.TimeOut)*time.Second)
+	defer gctxCancel()
+	ctx := &atylabmongo.MongoCtxSvc{Ctx: gctx, Cancel: gctxCancel}
+
+	// 猶予期間の判定は開始時刻を基準にする（実行中に復元されたものは PurgeDeletedMessages が消さない）
+	before := c.clock.Now().Add(-consts.MessageDeleteGracePeriod)
+	batchSize := max(c.options.BatchSize, 1)
+	expired := 0
+	purged := 0
+
+	batch := make([]model.Message, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, purgedMessages, err := c.message_svc.PurgeDeletedMessages(batch, before, ctx)
+		if err != nil {
+			return err
+		}
+		purged += int(n)
+		// 実行中に復元されたメッセージの添付ファイルは残す
+		for _, message := range purgedMessages {
+			c.attachment_svc.Remove(gctx, message.Attachments)
+		}
+		fmt.Printf("Progress: %d/%d messages purged\n", purged, expired)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := c.message_svc.StreamDeletedMessagesBefore(before, ctx, func(message model.Message) error {
+		expired++
+		if c.options.DryRun {
+			return nil
+		}
+		batch = append(batch, message)
+		if len(batch) >= batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+
+	mode := "delete"
+	if c.options.DryRun {
+		mode += " (dry run)"
+	}
+	fmt.Println("Deleted message purge summary")
+	fmt.Printf("  Mode: %s\n", mode)
+	fmt.Printf("  Deleted before: %s\n", before.UTC().Format(time.RFC3339))
+	fmt.Printf("  Expired messages: %d\n", expired)
+	fmt.Printf("  Purged messages: %d\n", purged)
+
+	if err != nil {
+		fmt.Println("Error purging messages:", err.Error())
+		return
+	}
+	fmt.Println("処理完了")
+}
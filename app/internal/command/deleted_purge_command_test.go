@@ -0,0 +1,196 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/cmd_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var deletedPurgeNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+var deletedPurgeMessages = []model.Message{
+	{
+		ID:     primitive.NewObjectID(),
+		RoomID: "room1",
+		Attachments: []model.Attachment{
+			{ID: "a1", StorageKey: "rooms/a1"},
+		},
+	},
+	{
+		ID:     primitive.NewObjectID(),
+		RoomID: "room2",
+	},
+	{
+		ID:     primitive.NewObjectID(),
+		RoomID: "room1",
+	},
+}
+
+func newDeletedPurgeCommand(
+	messageSvc *cmd_svc_mock.MessageSvcMock,
+	attachmentSvc *svc_mock.AttachmentSvcMock,
+	options DeletedPurgeOptions,
+) *DeletedPurgeCommand {
+	cmd := NewDeletedPurgeCommand()
+	cmd.message_svc = messageSvc
+	cmd.attachment_svc = attachmentSvc
+	cmd.clock = atylabclock.NewClockMock(deletedPurgeNow)
+	cmd.options = options
+	return cmd
+}
+
+func TestDeletedPurgeCmdSetUp(t *testing.T) {
+	cmd := NewDeletedPurgeCommand()
+	cmd.SetUp(&usecase.MongoUseCaseStruct{}, &usecase.StorageUseCaseStruct{}, DeletedPurgeOptions{
+		TimeOut:   60,
+		BatchSize: 10,
+		DryRun:    true,
+	})
+
+	assert.NotNil(t, cmd.message_svc)
+	assert.NotNil(t, cmd.attachment_svc)
+	assert.NotNil(t, cmd.clock)
+	assert.Equal(t, 60, cmd.options.TimeOut)
+	assert.Equal(t, 10, cmd.options.BatchSize)
+	assert.True(t, cmd.options.DryRun)
+}
+
+func TestDeletedPurgeCmdRun(t *testing.T) {
+	before := deletedPurgeNow.Add(-consts.MessageDeleteGracePeriod)
+
+	expected := map[string]struct {
+		options  DeletedPurgeOptions
+		batches  [][]model.Message
+		progress int
+		contains []string
+	}{
+		"batched": {
+			options:  DeletedPurgeOptions{TimeOut: 100, BatchSize: 2},
+			batches:  [][]model.Message{deletedPurgeMessages[:2], deletedPurgeMessages[2:]},
+			progress: 2,
+			contains: []string{"Mode: delete", "Expired messages: 3", "Purged messages: 3"},
+		},
+		"single batch": {
+			options:  DeletedPurgeOptions{TimeOut: 100, BatchSize: 500},
+			batches:  [][]model.Message{deletedPurgeMessages},
+			progress: 1,
+			contains: []string{"Expired messages: 3", "Purged messages: 3"},
+		},
+		"dry run": {
+			options:  DeletedPurgeOptions{TimeOut: 100, BatchSize: 500, DryRun: true},
+			progress: 0,
+			contains: []string{"Mode: delete (dry run)", "Expired messages: 3", "Purged messages: 0"},
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+			// 猶予期間を過ぎたものだけを対象にする
+			messageSvcMock.On("StreamDeletedMessagesBefore", before, mock.Anything, mock.Anything).Return(deletedPurgeMessages, nil)
+
+			attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+			// バッチごとに1回でまとめて削除する
+			for _, batch := range tt.batches {
+				messageSvcMock.On("PurgeDeletedMessages", batch, before, mock.Anything).Return(int64(len(batch)), batch, nil).Once()
+				for _, message := range batch {
+					attachmentSvcMock.On("Remove", mock.Anything, message.Attachments).Return().Once()
+				}
+			}
+
+			cmd := newDeletedPurgeCommand(messageSvcMock, attachmentSvcMock, tt.options)
+
+			outPut := funcs.CaptureStdout(t, func() {
+				cmd.Run([]string{})
+			})
+
+			messageSvcMock.AssertExpectations(t)
+			attachmentSvcMock.AssertExpectations(t)
+			if tt.options.DryRun {
+				messageSvcMock.AssertNotCalled(t, "PurgeDeletedMessages", mock.Anything, mock.Anything, mock.Anything)
+				attachmentSvcMock.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+			}
+
+			assert.Equal(t, tt.progress, strings.Count(outPut, "Progress:"))
+			assert.Contains(t, outPut, "Deleted before: 2026-01-30T12:00:00Z")
+			for _, line := range tt.contains {
+				assert.Contains(t, outPut, line)
+			}
+			assert.Contains(t, outPut, "処理完了")
+		})
+	}
+}
+
+func TestDeletedPurgeCmdRunRestored(t *testing.T) {
+	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+	messageSvcMock.On("StreamDeletedMessagesBefore", mock.Anything, mock.Anything, mock.Anything).
+		Return(deletedPurgeMessages, nil)
+	// 実行中に復元されたメッセージは消えず、添付ファイルも残す
+	messageSvcMock.On("PurgeDeletedMessages", deletedPurgeMessages, mock.Anything, mock.Anything).
+		Return(int64(2), deletedPurgeMessages[1:], nil)
+	attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+	attachmentSvcMock.On("Remove", mock.Anything, mock.Anything).Return()
+
+	cmd := newDeletedPurgeCommand(messageSvcMock, attachmentSvcMock, DeletedPurgeOptions{TimeOut: 100, BatchSize: 10})
+
+	outPut := funcs.CaptureStdout(t, func() {
+		cmd.Run([]string{})
+	})
+
+	messageSvcMock.AssertExpectations(t)
+	attachmentSvcMock.AssertNumberOfCalls(t, "Remove", 2)
+	attachmentSvcMock.AssertNotCalled(t, "Remove", mock.Anything, deletedPurgeMessages[0].Attachments)
+	assert.Contains(t, outPut, "Expired messages: 3")
+	assert.Contains(t, outPut, "Purged messages: 2")
+	assert.Contains(t, outPut, "処理完了")
+}
+
+func TestDeletedPurgeCmdRunError(t *testing.T) {
+	t.Run("stream", func(t *testing.T) {
+		messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamDeletedMessagesBefore", mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Message{}, errors.New("cursor error"))
+
+		cmd := newDeletedPurgeCommand(messageSvcMock, new(svc_mock.AttachmentSvcMock), DeletedPurgeOptions{TimeOut: 100, BatchSize: 10})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{})
+		})
+
+		assert.Contains(t, outPut, "Deleted message purge summary")
+		assert.Contains(t, outPut, "Error purging messages: cursor error")
+		assert.NotContains(t, outPut, "処理完了")
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamDeletedMessagesBefore", mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Message{deletedPurgeMessages[0], deletedPurgeMessages[1]}, nil)
+		messageSvcMock.On("PurgeDeletedMessages", []model.Message{deletedPurgeMessages[0]}, mock.Anything, mock.Anything).
+			Return(int64(0), []model.Message(nil), errors.New("delete error"))
+		attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+
+		cmd := newDeletedPurgeCommand(messageSvcMock, attachmentSvcMock, DeletedPurgeOptions{TimeOut: 100, BatchSize: 1})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{})
+		})
+
+		// 失敗した時点で中断する
+		messageSvcMock.AssertNotCalled(t, "PurgeDeletedMessages", []model.Message{deletedPurgeMessages[1]}, mock.Anything, mock.Anything)
+		attachmentSvcMock.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+		assert.Contains(t, outPut, "Error purging messages: delete error")
+	})
+}
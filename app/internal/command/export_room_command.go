@@ -0,0 +1,117 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabapi"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+type ExportRoomOptions struct {
+	// 全体のタイムアウト（秒）
+	TimeOut  int
+	Format   string
+	Timezone string
+	// 書き出し先のファイル。空の場合は標準出力に書き出す
+	Output string
+}
+
+type ExportRoomCommandInterface interface {
+	SetUp(mongo usecase.MongoUseCaseInterface, redis usecase.RedisUseCaseInterface, options ExportRoomOptions)
+	Run(args []string)
+}
+
+type ExportRoomCommand struct {
+	BaseCommand
+	room_svc   service.RoomSvcInterface
+	export_svc service.ExportSvcInterface
+	options    ExportRoomOptions
+}
+
+func NewExportRoomCommand() *ExportRoomCommand {
+	return &ExportRoomCommand{}
+}
+
+func (c *ExportRoomCommand) SetUp(
+	mongo usecase.MongoUseCaseInterface,
+	redis usecase.RedisUseCaseInterface,
+	options ExportRoomOptions,
+) {
+	c.room_svc = service.NewRoomSvc(
+		redis,
+		mongo_svc.NewRoomSvcStruct(mongo),
+		atylabapi.NewApiPostStruct(os.Getenv("COMMON_KEY"), os.Getenv("API_BASE_URL")),
+	)
+	c.export_svc = service.NewExportSvc(
+		mongo_svc.NewMessageSvcStruct(mongo, usecase.NewMongoDriverUseCaseStruct()),
+		c.room_svc,
+	)
+	c.options = options
+}
+
+// 指定したルームの全メッセージをファイル（または標準出力）に書き出す
+// 標準出力に書き出す場合は、書き出した内容と混ざらないよう進捗を標準エラー出力に出す
+func (c *ExportRoomCommand) Run(args []string) {
+	log := io.Writer(os.Stdout)
+	if c.options.Output == "" {
+		log = os.Stderr
+	}
+
+	if len(args) != 1 {
+		fmt.Fprintln(log, "Usage: export-room <room_id>")
+		return
+	}
+	roomID := args[0]
+
+	if _, err := c.export_svc.ContentType(c.options.Format); err != nil {
+		fmt.Fprintln(log, "Error:", err.Error())
+		return
+	}
+	loc, err := time.LoadLocation(c.options.Timezone)
+	if err != nil {
+		fmt.Fprintln(log, "Invalid timezone:", c.options.Timezone)
+		return
+	}
+
+	gctx, gctxCancel := context.WithTimeout(context.Background(), time.Duration(c.options.TimeOut)*time.Second)
+	defer gctxCancel()
+	ctx := &atylabmongo.MongoCtxSvc{Ctx: gctx, Cancel: gctxCancel}
+
+	room, err := c.room_svc.GetRoom(roomID, ctx)
+	if err != nil {
+		fmt.Fprintln(log, "Error fetching room:", err.Error())
+		return
+	}
+
+	out := io.Writer(os.Stdout)
+	if c.options.Output != "" {
+		file, err := os.Create(c.options.Output)
+		if err != nil {
+			fmt.Fprintln(log, "Error creating output file:", err.Error())
+			return
+		}
+		defer file.Close()
+		out = file
+	}
+	w := bufio.NewWriter(out)
+
+	count, err := c.export_svc.Export(w, room, c.options.Format, loc, ctx)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(log, "Error exporting room:", err.Error())
+		return
+	}
+
+	fmt.Fprintln(log, "Exported", count, "messages from room", room.ID.Hex(), "("+room.Name+")")
+	fmt.Fprintln(log, "処理完了")
+}
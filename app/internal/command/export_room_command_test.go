@@ -0,0 +1,159 @@
+package command
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var exportRoom = model.Room{ID: primitive.NewObjectID(), Name: "Dev Room"}
+
+func newExportRoomCommand(
+	roomSvc *svc_mock.RoomSvcMock,
+	exportSvc *svc_mock.ExportSvcMock,
+	options ExportRoomOptions,
+) *ExportRoomCommand {
+	cmd := NewExportRoomCommand()
+	cmd.room_svc = roomSvc
+	cmd.export_svc = exportSvc
+	cmd.options = options
+	return cmd
+}
+
+func TestExportRoomCmdSetUp(t *testing.T) {
+	cmd := NewExportRoomCommand()
+	options := ExportRoomOptions{TimeOut: 60, Format: "csv", Timezone: "Asia/Tokyo", Output: "room.csv"}
+	cmd.SetUp(&usecase.MongoUseCaseStruct{}, &usecase.RedisUseCaseStruct{}, options)
+
+	assert.NotNil(t, cmd.room_svc)
+	assert.NotNil(t, cmd.export_svc)
+	assert.Equal(t, options, cmd.options)
+}
+
+func TestExportRoomCmdRun(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	t.Run("file", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "room.csv")
+		roomSvcMock := new(svc_mock.RoomSvcMock)
+		roomSvcMock.On("GetRoom", exportRoom.ID.Hex(), mock.Anything).Return(exportRoom, nil)
+		exportSvcMock := new(svc_mock.ExportSvcMock)
+		exportSvcMock.On("ContentType", "csv").Return("text/csv; charset=UTF-8", nil)
+		exportSvcMock.On("Export", mock.Anything, exportRoom, "csv", tokyo, mock.Anything).Run(func(args mock.Arguments) {
+			io.WriteString(args.Get(0).(io.Writer), "id,created_at\n")
+		}).Return(2, nil)
+
+		cmd := newExportRoomCommand(roomSvcMock, exportSvcMock, ExportRoomOptions{TimeOut: 100, Format: "csv", Timezone: "Asia/Tokyo", Output: output})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{exportRoom.ID.Hex()})
+		})
+
+		exportSvcMock.AssertExpectations(t)
+		written, err := os.ReadFile(output)
+		assert.NoError(t, err)
+		assert.Equal(t, "id,created_at\n", string(written))
+		assert.Contains(t, outPut, "Exported 2 messages from room "+exportRoom.ID.Hex()+" (Dev Room)")
+		assert.Contains(t, outPut, "処理完了")
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		roomSvcMock := new(svc_mock.RoomSvcMock)
+		roomSvcMock.On("GetRoom", exportRoom.ID.Hex(), mock.Anything).Return(exportRoom, nil)
+		exportSvcMock := new(svc_mock.ExportSvcMock)
+		exportSvcMock.On("ContentType", "json").Return("application/json; charset=UTF-8", nil)
+		exportSvcMock.On("Export", mock.Anything, exportRoom, "json", time.UTC, mock.Anything).Run(func(args mock.Arguments) {
+			io.WriteString(args.Get(0).(io.Writer), `{"messages":[]}`)
+		}).Return(0, nil)
+
+		cmd := newExportRoomCommand(roomSvcMock, exportSvcMock, ExportRoomOptions{TimeOut: 100, Format: "json", Timezone: "UTC"})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{exportRoom.ID.Hex()})
+		})
+
+		// 標準出力には書き出した内容だけを出す
+		assert.Equal(t, `{"messages":[]}`, outPut)
+	})
+}
+
+func TestExportRoomCmdRunError(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "room.json")
+
+	expected := map[string]struct {
+		args        []string
+		options     ExportRoomOptions
+		typeErr     error
+		getErr      error
+		exportErr   error
+		exportCall  int
+		contains    string
+		fileCreated bool
+	}{
+		"missing room id": {
+			args:     []string{},
+			options:  ExportRoomOptions{Format: "json", Timezone: "UTC", Output: output},
+			contains: "Usage: export-room <room_id>",
+		},
+		"unsupported format": {
+			args:     []string{exportRoom.ID.Hex()},
+			options:  ExportRoomOptions{Format: "xml", Timezone: "UTC", Output: output},
+			typeErr:  errors.New("format must be one of json, csv, txt, html"),
+			contains: "Error: format must be one of json, csv, txt, html",
+		},
+		"invalid timezone": {
+			args:     []string{exportRoom.ID.Hex()},
+			options:  ExportRoomOptions{Format: "json", Timezone: "Mars/Olympus", Output: output},
+			contains: "Invalid timezone: Mars/Olympus",
+		},
+		"room not found": {
+			args:     []string{exportRoom.ID.Hex()},
+			options:  ExportRoomOptions{TimeOut: 100, Format: "json", Timezone: "UTC", Output: output},
+			getErr:   errors.New("not found"),
+			contains: "Error fetching room: not found",
+		},
+		"export error": {
+			args:        []string{exportRoom.ID.Hex()},
+			options:     ExportRoomOptions{TimeOut: 100, Format: "json", Timezone: "UTC", Output: output},
+			exportErr:   errors.New("cursor error"),
+			exportCall:  1,
+			contains:    "Error exporting room: cursor error",
+			fileCreated: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			os.Remove(output)
+
+			roomSvcMock := new(svc_mock.RoomSvcMock)
+			roomSvcMock.On("GetRoom", exportRoom.ID.Hex(), mock.Anything).Return(exportRoom, tt.getErr)
+			exportSvcMock := new(svc_mock.ExportSvcMock)
+			exportSvcMock.On("ContentType", tt.options.Format).Return("", tt.typeErr)
+			exportSvcMock.On("Export", mock.Anything, exportRoom, tt.options.Format, mock.Anything, mock.Anything).Return(0, tt.exportErr)
+
+			cmd := newExportRoomCommand(roomSvcMock, exportSvcMock, tt.options)
+
+			outPut := funcs.CaptureStdout(t, func() {
+				cmd.Run(tt.args)
+			})
+
+			exportSvcMock.AssertNumberOfCalls(t, "Export", tt.exportCall)
+			assert.Contains(t, outPut, tt.contains)
+			assert.NotContains(t, outPut, "処理完了")
+			_, err := os.Stat(output)
+			assert.Equal(t, tt.fileCreated, err == nil)
+		})
+	}
+}
@@ -3,8 +3,10 @@ package command
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/cmd_svc"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
@@ -12,7 +14,7 @@ import (
 )
 
 type ForbiddenWordsCommandInterface interface {
-	SetUp(mongo usecase.MongoUseCaseInterface, timeOut int)
+	SetUp(mongo usecase.MongoUseCaseInterface, timeOut int, concurrency int)
 	Run(args []string)
 }
 
@@ -21,6 +23,7 @@ type ForbiddenWordsCommand struct {
 	room_svc    cmd_svc.RoomSvcInterface
 	message_svc cmd_svc.MessageSvcInterface
 	timeOut     int
+	concurrency int
 }
 
 func NewForbiddenWordsCommand() *ForbiddenWordsCommand {
@@ -30,22 +33,27 @@ func NewForbiddenWordsCommand() *ForbiddenWordsCommand {
 func (c *ForbiddenWordsCommand) SetUp(
 	mongo usecase.MongoUseCaseInterface,
 	timeOut int,
+	concurrency int,
 ) {
 	c.room_svc = cmd_svc.NewRoomSvcStruct(
 		mongo,
 	)
 	c.message_svc = cmd_svc.NewMessageSvcStruct(
 		mongo,
+		usecase.NewMongoDriverUseCaseStruct(),
 	)
 	c.timeOut = timeOut
+	c.concurrency = concurrency
 }
 
 func (c *ForbiddenWordsCommand) Run(args []string) {
-	// 全体のタイムアウトを100秒に設定
+	// 全体のタイムアウトを設定
 	gctx, gctxCancel := context.WithTimeout(context.Background(), time.Duration(c.timeOut)*time.Second)
 	defer gctxCancel()
 
 	g, gctx := errgroup.WithContext(gctx)
+	// 同時に開くカーソルの数をワーカー数で制限する
+	g.SetLimit(max(c.concurrency, 1))
 
 	ctx := atylabmongo.NewMongoCtxSvc()
 	defer ctx.Cancel()
@@ -56,27 +64,37 @@ func (c *ForbiddenWordsCommand) Run(args []string) {
 		return
 	}
 
+	total := len(rooms)
+	var done atomic.Int64
+	var found atomic.Int64
+
 	for _, room := range rooms {
 		g.Go(func() error {
 			room := room // クロージャ内で正しいroomを参照するために変数を再定義
 			roomId := room.ID.Hex()
 
-			mctx := atylabmongo.NewMongoCtxSvc()
+			// カーソルは全体のタイムアウトに従わせる
+			cctx, cancel := context.WithCancel(gctx)
+			mctx := &atylabmongo.MongoCtxSvc{Ctx: cctx, Cancel: cancel}
 			defer mctx.Cancel()
-			messageList, err := c.message_svc.GetMessageList(roomId, mctx)
 
-			if err != nil {
-				return err
-			}
-			for _, message := range messageList {
+			scanned := 0
+			err := c.message_svc.StreamMessageList(roomId, mctx, func(message model.Message) error {
 				if err := gctx.Err(); err != nil {
 					return err
 				}
+				scanned++
 				if c.message_svc.ContainsForbiddenWords(message.Message) {
+					found.Add(1)
 					fmt.Printf("Forbidden word found in Room ID: %s, Message ID: %s, Content: %s\n", roomId, message.ID.Hex(), message.Message)
 				}
+				return nil
+			})
+			if err != nil {
+				return err
 			}
-			// すべてのメッセージを処理した後の処理
+
+			fmt.Printf("Progress: %d/%d rooms scanned (Room ID: %s, Messages: %d)\n", done.Add(1), total, roomId, scanned)
 			return nil
 		})
 	}
@@ -88,5 +106,6 @@ func (c *ForbiddenWordsCommand) Run(args []string) {
 		return
 	}
 
+	fmt.Printf("Forbidden words found: %d\n", found.Load())
 	fmt.Println("処理完了")
 }
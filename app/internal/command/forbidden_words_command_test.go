@@ -51,13 +51,16 @@ var messages = []model.Message{
 
 func TestForbiddenWordsCmdSetUp(t *testing.T) {
 	cmd := NewForbiddenWordsCommand()
-	cmd.SetUp(&usecase.MongoUseCaseStruct{}, 150)
+	cmd.SetUp(&usecase.MongoUseCaseStruct{}, 150, 3)
 	if cmd.room_svc == nil {
 		t.Error("room_svc should not be nil after SetUp")
 	}
 	if cmd.timeOut != 150 {
 		t.Errorf("timeOut should be 150 after SetUp, got %d", cmd.timeOut)
 	}
+	if cmd.concurrency != 3 {
+		t.Errorf("concurrency should be 3 after SetUp, got %d", cmd.concurrency)
+	}
 }
 
 func TestForbiddenWordsCmdRun(t *testing.T) {
@@ -77,8 +80,8 @@ func TestForbiddenWordsCmdRun(t *testing.T) {
 			roomSvcMock.On("ListRooms", mock.Anything).Return(rooms, expect["ListRoomsError"])
 			messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
 			if expect["ListRoomsError"] == nil {
-				messageSvcMock.On("GetMessageList", rooms[0].ID.Hex(), mock.Anything).Return([]model.Message{messages[0], messages[1]}, nil)
-				messageSvcMock.On("GetMessageList", rooms[1].ID.Hex(), mock.Anything).Return([]model.Message{messages[2], messages[3]}, nil)
+				messageSvcMock.On("StreamMessageList", rooms[0].ID.Hex(), mock.Anything, mock.Anything).Return([]model.Message{messages[0], messages[1]}, nil)
+				messageSvcMock.On("StreamMessageList", rooms[1].ID.Hex(), mock.Anything, mock.Anything).Return([]model.Message{messages[2], messages[3]}, nil)
 				messageSvcMock.On("ContainsForbiddenWords", messages[0].Message).Return(false)
 				messageSvcMock.On("ContainsForbiddenWords", messages[1].Message).Return(true)
 				messageSvcMock.On("ContainsForbiddenWords", messages[2].Message).Return(false)
@@ -89,6 +92,7 @@ func TestForbiddenWordsCmdRun(t *testing.T) {
 			cmd.room_svc = roomSvcMock
 			cmd.message_svc = messageSvcMock
 			cmd.timeOut = 100
+			cmd.concurrency = 5
 
 			outPut := funcs.CaptureStdout(t, func() {
 				cmd.Run([]string{})
@@ -126,6 +130,14 @@ func TestForbiddenWordsCmdRun(t *testing.T) {
 				t.Errorf("Expected output to contain 2 forbidden word findings, but got %d", strings.Count(outPut, "Forbidden word found in Room ID:"))
 			}
 
+			if strings.Count(outPut, "Progress:") != 2 {
+				t.Errorf("Expected output to contain 2 progress lines, but got %d", strings.Count(outPut, "Progress:"))
+			}
+
+			if !strings.Contains(outPut, "Forbidden words found: 2") {
+				t.Error("Expected output to contain the summary, but it did not.")
+			}
+
 			if !strings.Contains(outPut, "処理完了") {
 				t.Error("Expected output to contain '処理完了', but it did not.")
 			}
@@ -134,18 +146,19 @@ func TestForbiddenWordsCmdRun(t *testing.T) {
 	}
 }
 
-func TestForbiddenWordsCmdRunGetMessageListError(t *testing.T) {
+func TestForbiddenWordsCmdRunStreamMessageListError(t *testing.T) {
 	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
 	roomSvcMock.On("ListRooms", mock.Anything).Return([]model.Room{
 		rooms[0],
 	}, nil)
 	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
-	messageSvcMock.On("GetMessageList", rooms[0].ID.Hex(), mock.Anything).Return([]model.Message{}, errors.New("failed to get message list"))
+	messageSvcMock.On("StreamMessageList", rooms[0].ID.Hex(), mock.Anything, mock.Anything).Return([]model.Message{}, errors.New("failed to stream message list"))
 
 	cmd := NewForbiddenWordsCommand()
 	cmd.room_svc = roomSvcMock
 	cmd.message_svc = messageSvcMock
 	cmd.timeOut = 100
+	cmd.concurrency = 5
 
 	outPut := funcs.CaptureStdout(t, func() {
 		cmd.Run([]string{})
@@ -163,16 +176,17 @@ func TestForbiddenWordsCmdRunGroupError(t *testing.T) {
 	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
 	roomSvcMock.On("ListRooms", mock.Anything).Return(rooms, nil)
 	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
-	messageSvcMock.On("GetMessageList", rooms[0].ID.Hex(), mock.Anything).
+	messageSvcMock.On("StreamMessageList", rooms[0].ID.Hex(), mock.Anything, mock.Anything).
 		Return([]model.Message{messages[0], messages[1]}, nil)
 
-	messageSvcMock.On("GetMessageList", rooms[1].ID.Hex(), mock.Anything).
+	messageSvcMock.On("StreamMessageList", rooms[1].ID.Hex(), mock.Anything, mock.Anything).
 		Return([]model.Message{messages[2], messages[3]}, nil)
 
 	cmd := NewForbiddenWordsCommand()
 	cmd.room_svc = roomSvcMock
 	cmd.message_svc = messageSvcMock
 	cmd.timeOut = 0
+	cmd.concurrency = 5
 
 	outPut := funcs.CaptureStdout(t, func() {
 		cmd.Run([]string{})
@@ -191,7 +205,7 @@ func TestForbiddenWordsCmdRunMultichan(t *testing.T) {
 	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
 	roomSvcMock.On("ListRooms", mock.Anything).Return(rooms, nil)
 	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
-	messageSvcMock.On("GetMessageList", mock.Anything, mock.Anything).
+	messageSvcMock.On("StreamMessageList", mock.Anything, mock.Anything, mock.Anything).
 		Return([]model.Message{}, nil).
 		Run(func(args mock.Arguments) {
 			roomId := args.String(0)
@@ -205,11 +219,17 @@ func TestForbiddenWordsCmdRunMultichan(t *testing.T) {
 	cmd.room_svc = roomSvcMock
 	cmd.message_svc = messageSvcMock
 	cmd.timeOut = 100
+	cmd.concurrency = 5
 
 	outPut := funcs.CaptureStdout(t, func() {
 		cmd.Run([]string{})
 	})
-	lines := strings.Split(strings.TrimSpace(outPut), "\n")
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(outPut), "\n") {
+		if strings.HasPrefix(line, "start") || strings.HasPrefix(line, "end") {
+			lines = append(lines, line)
+		}
+	}
 
 	for i, line := range lines {
 		switch i {
@@ -227,3 +247,49 @@ func TestForbiddenWordsCmdRunMultichan(t *testing.T) {
 	roomSvcMock.AssertExpectations(t)
 	messageSvcMock.AssertExpectations(t)
 }
+
+func TestForbiddenWordsCmdRunConcurrencyLimit(t *testing.T) {
+
+	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+	roomSvcMock.On("ListRooms", mock.Anything).Return(rooms, nil)
+	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+	messageSvcMock.On("StreamMessageList", mock.Anything, mock.Anything, mock.Anything).
+		Return([]model.Message{}, nil).
+		Run(func(args mock.Arguments) {
+			roomId := args.String(0)
+			fmt.Println("start", roomId)
+			time.Sleep(100 * time.Millisecond)
+			fmt.Println("end", roomId)
+		}).
+		Twice()
+
+	cmd := NewForbiddenWordsCommand()
+	cmd.room_svc = roomSvcMock
+	cmd.message_svc = messageSvcMock
+	cmd.timeOut = 100
+	cmd.concurrency = 1
+
+	outPut := funcs.CaptureStdout(t, func() {
+		cmd.Run([]string{})
+	})
+	lines := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(outPut), "\n") {
+		if strings.HasPrefix(line, "start") || strings.HasPrefix(line, "end") {
+			lines = append(lines, line)
+		}
+	}
+
+	// ワーカー数が1の場合は1部屋ずつ順番に処理される
+	expectedPrefixes := []string{"start", "end", "start", "end"}
+	if len(lines) != len(expectedPrefixes) {
+		t.Fatalf("Expected %d lines, but got %d", len(expectedPrefixes), len(lines))
+	}
+	for i, prefix := range expectedPrefixes {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Expected '%s' in line %d, but got: %s", prefix, i, lines[i])
+		}
+	}
+
+	roomSvcMock.AssertExpectations(t)
+	messageSvcMock.AssertExpectations(t)
+}
@@ -0,0 +1,380 @@
+package command
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/cmd_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+type ImportSlackOptions struct {
+	// 全体のタイムアウト（秒）
+	TimeOut int
+	// Slack のユーザーIDと UUID の対応表（{"U0123": "uuid"} 形式の JSON）
+	Mapping string
+	// チャンネルの作成者が対応表にない場合にオーナーにする UUID
+	DefaultOwner string
+	// 取り込まなかったレコードを書き出す CSV ファイル
+	Report string
+	// 件数を数えるだけで、登録しない
+	DryRun bool
+}
+
+type ImportSlackCommandInterface interface {
+	SetUp(mongo usecase.MongoUseCaseInterface, options ImportSlackOptions)
+	Run(args []string)
+}
+
+type ImportSlackCommand struct {
+	BaseCommand
+	room_svc    cmd_svc.RoomSvcInterface
+	message_svc cmd_svc.MessageSvcInterface
+	index       usecase.MongoIndexUseCaseInterface
+	options     ImportSlackOptions
+}
+
+func NewImportSlackCommand() *ImportSlackCommand {
+	return &ImportSlackCommand{}
+}
+
+func (c *ImportSlackCommand) SetUp(
+	mongo usecase.MongoUseCaseInterface,
+	options ImportSlackOptions,
+) {
+	c.room_svc = cmd_svc.NewRoomSvcStruct(
+		mongo,
+	)
+	c.message_svc = cmd_svc.NewMessageSvcStruct(
+		mongo,
+		usecase.NewMongoDriverUseCaseStruct(),
+	)
+	c.index = usecase.NewMongoIndexUseCaseStruct(model.MongoIndexes)
+	c.options = options
+}
+
+// 取り込まなかったレコード
+type slackSkippedRecord struct {
+	Channel string
+	Day     string
+	Ts      string
+	Reason  string
+}
+
+type slackImportStats struct {
+	channelsImported int
+	channelsExisting int
+	channelsSkipped  int
+	messagesImported int
+	messagesExisting int
+	messagesSkipped  int
+	threadReplies    int
+	reactions        int
+	files            int
+	skipped          []slackSkippedRecord
+}
+
+func (s *slackImportStats) skip(record slackSkippedRecord) {
+	s.skipped = append(s.skipped, record)
+}
+
+// Slack のエクスポート（ZIP）のチャンネルをルームとして、メッセージを元の投稿日時のまま取り込む
+// 取り込み済みのルームとメッセージは登録し直さないので、途中で失敗しても再実行できる
+// スレッドの返信はルームのメッセージとして投稿順に取り込む。リアクションと添付ファイルは取り込まず件数だけ報告する
+func (c *ImportSlackCommand) Run(args []string) {
+	if len(args) != 1 {
+		fmt.Println("Usage: import-slack <export.zip> --mapping <users.json>")
+		return
+	}
+
+	mapping, err := loadSlackUserMapping(c.options.Mapping)
+	if err != nil {
+		fmt.Println("Error reading mapping file:", err.Error())
+		return
+	}
+
+	archive, err := openSlackArchive(args[0])
+	if err != nil {
+		fmt.Println("Error opening slack archive:", err.Error())
+		return
+	}
+	defer archive.Close()
+
+	// 取り込み済みかどうかは一意インデックスで判定するので、API を起動する前のデータベースでも先に作成する
+	if !c.options.DryRun {
+		if err := c.index.EnsureIndexes(); err != nil {
+			fmt.Println("Error creating indexes:", err.Error())
+			return
+		}
+	}
+
+	gctx, gctxCancel := context.WithTimeout(context.Background(), time.Duration(c.options.TimeOut)*time.Second)
+	defer gctxCancel()
+	ctx := &atylabmongo.MongoCtxSvc{Ctx: gctx, Cancel: gctxCancel}
+
+	stats := &slackImportStats{}
+	for _, name := range archive.unsupported {
+		stats.skip(slackSkippedRecord{Reason: name + ": direct messages are not imported"})
+	}
+
+	var importErr error
+	for _, channel := range archive.channels {
+		if importErr = c.importChannel(archive, channel, mapping, stats, ctx); importErr != nil {
+			break
+		}
+	}
+
+	c.printSummary(stats)
+	if c.options.Report != "" {
+		if err := writeSlackSkippedReport(c.options.Report, stats.skipped); err != nil {
+			fmt.Println("Error writing report:", err.Error())
+		} else {
+			fmt.Println("Skipped records written to:", c.options.Report)
+		}
+	}
+
+	if importErr != nil {
+		fmt.Println("Error importing slack archive:", importErr.Error())
+		return
+	}
+	fmt.Println("処理完了")
+}
+
+func (c *ImportSlackCommand) importChannel(
+	archive *slackArchive,
+	channel slackChannel,
+	mapping map[string]string,
+	stats *slackImportStats,
+	ctx *atylabmongo.MongoCtxSvc,
+) error {
+	owner, ok := mapping[channel.Creator]
+	if !ok {
+		owner = c.options.DefaultOwner
+	}
+	if owner == "" {
+		stats.channelsSkipped++
+		stats.skip(slackSkippedRecord{
+			Channel: channel.Name,
+			Reason:  fmt.Sprintf("owner %s is not in the mapping file", channel.Creator),
+		})
+		return nil
+	}
+
+	members := []string{owner}
+	for _, slackUserID := range channel.Members {
+		uuid, ok := mapping[slackUserID]
+		if !ok {
+			stats.skip(slackSkippedRecord{
+				Channel: channel.Name,
+				Reason:  fmt.Sprintf("member %s is not in the mapping file", slackUserID),
+			})
+			continue
+		}
+		if !slices.Contains(members, uuid) {
+			members = append(members, uuid)
+		}
+	}
+
+	room := model.Room{
+		Name:          channel.Name,
+		OwnerID:       owner,
+		CreatedAt:     time.Unix(channel.Created, 0).UTC(),
+		Members:       members,
+		IsPrivate:     channel.IsPrivate,
+		BannedMembers: []string{},
+		ExternalID:    consts.SlackImportIDPrefix + channel.ID,
+	}
+	if c.options.DryRun {
+		stats.channelsImported++
+	} else {
+		imported, created, err := c.room_svc.ImportRoom(room, ctx)
+		if err != nil {
+			return err
+		}
+		room.ID = imported.ID
+		if created {
+			stats.channelsImported++
+		} else {
+			stats.channelsExisting++
+		}
+	}
+
+	processed := 0
+	err := archive.eachMessage(channel, func(day string, message slackMessage) error {
+		processed++
+		if processed%consts.SlackImportProgressInterval == 0 {
+			fmt.Printf("Progress: #%s %d messages processed\n", channel.Name, processed)
+		}
+
+		record := slackSkippedRecord{Channel: channel.Name, Day: day, Ts: message.Ts}
+		imported, err := c.importMessage(archive, room, message, mapping, stats, &record, ctx)
+		if err != nil {
+			return err
+		}
+		if !imported && record.Reason != "" {
+			stats.messagesSkipped++
+			stats.skip(record)
+		}
+		return nil
+	})
+	return err
+}
+
+// メッセージを1件取り込む。取り込まなかった場合は record に理由を設定する
+// 取り込み済みだった場合は理由を設定せずに false を返す
+func (c *ImportSlackCommand) importMessage(
+	archive *slackArchive,
+	room model.Room,
+	message slackMessage,
+	mapping map[string]string,
+	stats *slackImportStats,
+	record *slackSkippedRecord,
+	ctx *atylabmongo.MongoCtxSvc,
+) (bool, error) {
+	if message.Type != "message" {
+		record.Reason = fmt.Sprintf("type %s is not imported", message.Type)
+		return false, nil
+	}
+	if !consts.SlackImportSubtypes[message.Subtype] {
+		record.Reason = fmt.Sprintf("subtype %s is not imported", message.Subtype)
+		return false, nil
+	}
+	sender, ok := mapping[message.User]
+	if !ok {
+		record.Reason = fmt.Sprintf("user %s is not in the mapping file", message.User)
+		return false, nil
+	}
+	createdAt, err := parseSlackTs(message.Ts)
+	if err != nil {
+		record.Reason = err.Error()
+		return false, nil
+	}
+
+	stats.files += len(message.Files)
+	text := archive.convertText(message.Text)
+	if strings.TrimSpace(text) == "" {
+		record.Reason = "message has no text (files are not imported)"
+		return false, nil
+	}
+
+	if c.options.DryRun {
+		c.countImported(message, stats)
+		return true, nil
+	}
+
+	imported, err := c.message_svc.ImportMessage(model.Message{
+		RoomID:    room.ID.Hex(),
+		Sender:    sender,
+		Message:   text,
+		CreatedAt: createdAt,
+		// 取り込んだ履歴が未読として表示されないよう、ルームのメンバー全員を既読にする
+		IsReadUserIds: room.Members,
+		ClientMsgID:   consts.SlackImportIDPrefix + message.Ts,
+	}, ctx)
+	if err != nil {
+		return false, err
+	}
+	if !imported {
+		stats.messagesExisting++
+		return false, nil
+	}
+	c.countImported(message, stats)
+	return true, nil
+}
+
+func (c *ImportSlackCommand) countImported(message slackMessage, stats *slackImportStats) {
+	stats.messagesImported++
+	stats.reactions += message.reactionCount()
+	if message.isThreadReply() {
+		stats.threadReplies++
+	}
+}
+
+func (c *ImportSlackCommand) printSummary(stats *slackImportStats) {
+	mode := "import"
+	if c.options.DryRun {
+		mode = "import (dry run)"
+	}
+
+	fmt.Println("Slack import summary")
+	fmt.Println("Mode:", mode)
+	fmt.Printf("Channels: %d imported, %d already imported, %d skipped\n", stats.channelsImported, stats.channelsExisting, stats.channelsSkipped)
+	fmt.Printf("Messages: %d imported, %d already imported, %d skipped\n", stats.messagesImported, stats.messagesExisting, stats.messagesSkipped)
+	fmt.Println("Thread replies imported as room messages:", stats.threadReplies)
+	fmt.Println("Reactions not imported:", stats.reactions)
+	fmt.Println("Files not imported:", stats.files)
+
+	if len(stats.skipped) == 0 {
+		return
+	}
+	// 同じ理由のレコードはまとめて件数を表示する
+	reasons := map[string]int{}
+	for _, record := range stats.skipped {
+		reasons[record.Reason]++
+	}
+	keys := make([]string, 0, len(reasons))
+	for reason := range reasons {
+		keys = append(keys, reason)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if reasons[keys[i]] != reasons[keys[j]] {
+			return reasons[keys[i]] > reasons[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	fmt.Println("Skipped records:")
+	for _, reason := range keys {
+		fmt.Printf("  %s: %d\n", reason, reasons[reason])
+	}
+}
+
+func loadSlackUserMapping(name string) (map[string]string, error) {
+	if name == "" {
+		return nil, fmt.Errorf("--mapping is required")
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	mapping := map[string]string{}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, err
+	}
+	// UUID が空の行は対応表にないものとして扱う
+	for slackUserID, uuid := range mapping {
+		if uuid == "" {
+			delete(mapping, slackUserID)
+		}
+	}
+	return mapping, nil
+}
+
+func writeSlackSkippedReport(name string, records []slackSkippedRecord) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	if err := w.Write([]string{"channel", "day", "ts", "reason"}); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := w.Write([]string{record.Channel, record.Day, record.Ts, record.Reason}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
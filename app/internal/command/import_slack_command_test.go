@@ -0,0 +1,292 @@
+package command
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/cmd_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var slackImportRoomID = primitive.NewObjectID()
+
+func slackImportFiles() map[string]any {
+	return map[string]any{
+		"channels.json": []map[string]any{
+			{"id": "C01", "name": "general", "created": 1500000000, "creator": "U01", "members": []string{"U01", "U02", "U03"}},
+			{"id": "C02", "name": "orphan", "created": 1500000000, "creator": "U03", "members": []string{"U03"}},
+		},
+		"users.json": []map[string]any{
+			{"id": "U01", "name": "alice"},
+		},
+		"general/2017-07-14.json": []map[string]any{
+			{"type": "message", "user": "U01", "text": "hello &amp; welcome", "ts": "1500000000.000100",
+				"reactions": []map[string]any{{"name": "wave", "users": []string{"U02", "U03"}, "count": 2}}},
+			{"type": "message", "user": "U02", "text": "<@U01> thanks", "ts": "1500000001.000100", "thread_ts": "1500000000.000100"},
+			{"type": "message", "subtype": "channel_join", "user": "U02", "text": "<@U02> has joined the channel", "ts": "1500000002.000100"},
+			{"type": "message", "user": "U03", "text": "who am I", "ts": "1500000003.000100"},
+			{"type": "message", "subtype": "file_share", "user": "U01", "text": "", "ts": "1500000004.000100",
+				"files": []map[string]any{{"id": "F01", "name": "report.pdf"}}},
+			{"type": "message", "subtype": "bot_message", "text": "deploy finished", "ts": "1500000005.000100"},
+			{"type": "message", "user": "U01", "text": "already here", "ts": "1500000006.000100"},
+		},
+		"orphan/2017-07-14.json": []map[string]any{
+			{"type": "message", "user": "U01", "text": "orphan message", "ts": "1500000010.000100"},
+		},
+	}
+}
+
+func writeSlackMapping(t *testing.T) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "mapping.json")
+	os.WriteFile(name, []byte(`{"U01": "uuid-1", "U02": "uuid-2", "U99": ""}`), 0o644)
+	return name
+}
+
+func slackImportMessage(sender string, text string, ts string, createdAt time.Time) model.Message {
+	return model.Message{
+		RoomID:        slackImportRoomID.Hex(),
+		Sender:        sender,
+		Message:       text,
+		CreatedAt:     createdAt,
+		IsReadUserIds: []string{"uuid-1", "uuid-2"},
+		ClientMsgID:   "slack:" + ts,
+	}
+}
+
+func newImportSlackCommand(
+	roomSvc *cmd_svc_mock.RoomSvcMock,
+	messageSvc *cmd_svc_mock.MessageSvcMock,
+	options ImportSlackOptions,
+) *ImportSlackCommand {
+	cmd := NewImportSlackCommand()
+	cmd.room_svc = roomSvc
+	cmd.message_svc = messageSvc
+	index := new(usecase_mock.MongoIndexUseCaseMock)
+	index.On("EnsureIndexes").Return(nil)
+	cmd.index = index
+	cmd.options = options
+	return cmd
+}
+
+func TestImportSlackCmdSetUp(t *testing.T) {
+	cmd := NewImportSlackCommand()
+	options := ImportSlackOptions{TimeOut: 60, Mapping: "mapping.json", DefaultOwner: "uuid-1", Report: "skipped.csv", DryRun: true}
+	cmd.SetUp(&usecase.MongoUseCaseStruct{}, options)
+
+	assert.NotNil(t, cmd.room_svc)
+	assert.NotNil(t, cmd.message_svc)
+	assert.NotNil(t, cmd.index)
+	assert.Equal(t, options, cmd.options)
+}
+
+func TestImportSlackCmdRun(t *testing.T) {
+	archive := writeSlackArchive(t, slackImportFiles())
+	mapping := writeSlackMapping(t)
+	report := filepath.Join(t.TempDir(), "skipped.csv")
+
+	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+	roomSvcMock.On("ImportRoom", model.Room{
+		Name:          "general",
+		OwnerID:       "uuid-1",
+		CreatedAt:     time.Unix(1500000000, 0).UTC(),
+		Members:       []string{"uuid-1", "uuid-2"},
+		BannedMembers: []string{},
+		ExternalID:    "slack:C01",
+	}, mock.Anything).Return(model.Room{ID: slackImportRoomID}, true, nil)
+
+	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+	messageSvcMock.On("ImportMessage", slackImportMessage("uuid-1", "hello & welcome", "1500000000.000100", time.Unix(1500000000, 100000).UTC()), mock.Anything).Return(true, nil)
+	messageSvcMock.On("ImportMessage", slackImportMessage("uuid-2", "@alice thanks", "1500000001.000100", time.Unix(1500000001, 100000).UTC()), mock.Anything).Return(true, nil)
+	// 前回取り込んだメッセージは登録し直さない
+	messageSvcMock.On("ImportMessage", slackImportMessage("uuid-1", "already here", "1500000006.000100", time.Unix(1500000006, 100000).UTC()), mock.Anything).Return(false, nil)
+
+	cmd := newImportSlackCommand(roomSvcMock, messageSvcMock, ImportSlackOptions{TimeOut: 100, Mapping: mapping, Report: report})
+
+	outPut := funcs.CaptureStdout(t, func() {
+		cmd.Run([]string{archive})
+	})
+
+	roomSvcMock.AssertExpectations(t)
+	roomSvcMock.AssertNumberOfCalls(t, "ImportRoom", 1)
+	messageSvcMock.AssertExpectations(t)
+	messageSvcMock.AssertNumberOfCalls(t, "ImportMessage", 3)
+	// 取り込み済みの判定に使う一意インデックスを先に作成する
+	cmd.index.(*usecase_mock.MongoIndexUseCaseMock).AssertNumberOfCalls(t, "EnsureIndexes", 1)
+
+	for _, line := range []string{
+		"Mode: import\n",
+		"Channels: 1 imported, 0 already imported, 1 skipped",
+		"Messages: 2 imported, 1 already imported, 4 skipped",
+		"Thread replies imported as room messages: 1",
+		"Reactions not imported: 2",
+		"Files not imported: 1",
+		"  member U03 is not in the mapping file: 1",
+		"  owner U03 is not in the mapping file: 1",
+		"  user U03 is not in the mapping file: 1",
+		"  subtype channel_join is not imported: 1",
+		"  subtype bot_message is not imported: 1",
+		"  message has no text (files are not imported): 1",
+		"Skipped records written to: " + report,
+		"処理完了",
+	} {
+		assert.Contains(t, outPut, line)
+	}
+
+	file, err := os.Open(report)
+	assert.NoError(t, err)
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"channel", "day", "ts", "reason"}, records[0])
+	assert.Contains(t, records, []string{"general", "2017-07-14", "1500000003.000100", "user U03 is not in the mapping file"})
+	assert.Contains(t, records, []string{"orphan", "", "", "owner U03 is not in the mapping file"})
+	assert.Len(t, records, 7)
+}
+
+func TestImportSlackCmdRunDefaultOwner(t *testing.T) {
+	orphanID := primitive.NewObjectID()
+	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+	roomSvcMock.On("ImportRoom", mock.MatchedBy(func(room model.Room) bool { return room.Name == "general" }), mock.Anything).
+		Return(model.Room{ID: slackImportRoomID}, false, nil)
+	// 作成者が対応表にないチャンネルは既定のオーナーで取り込む
+	roomSvcMock.On("ImportRoom", mock.MatchedBy(func(room model.Room) bool {
+		return room.Name == "orphan" && room.OwnerID == "uuid-admin" && len(room.Members) == 1
+	}), mock.Anything).Return(model.Room{ID: orphanID}, true, nil)
+
+	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+	messageSvcMock.On("ImportMessage", mock.Anything, mock.Anything).Return(true, nil)
+
+	cmd := newImportSlackCommand(roomSvcMock, messageSvcMock, ImportSlackOptions{
+		TimeOut:      100,
+		Mapping:      writeSlackMapping(t),
+		DefaultOwner: "uuid-admin",
+	})
+
+	outPut := funcs.CaptureStdout(t, func() {
+		cmd.Run([]string{writeSlackArchive(t, slackImportFiles())})
+	})
+
+	roomSvcMock.AssertExpectations(t)
+	messageSvcMock.AssertCalled(t, "ImportMessage", mock.MatchedBy(func(message model.Message) bool {
+		return message.RoomID == orphanID.Hex() && message.Message == "orphan message"
+	}), mock.Anything)
+	assert.Contains(t, outPut, "Channels: 1 imported, 1 already imported, 0 skipped")
+	assert.Contains(t, outPut, "Messages: 4 imported, 0 already imported, 4 skipped")
+	assert.Contains(t, outPut, "処理完了")
+}
+
+func TestImportSlackCmdRunDryRun(t *testing.T) {
+	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+
+	cmd := newImportSlackCommand(roomSvcMock, messageSvcMock, ImportSlackOptions{TimeOut: 100, Mapping: writeSlackMapping(t), DryRun: true})
+
+	outPut := funcs.CaptureStdout(t, func() {
+		cmd.Run([]string{writeSlackArchive(t, slackImportFiles())})
+	})
+
+	roomSvcMock.AssertNotCalled(t, "ImportRoom", mock.Anything, mock.Anything)
+	messageSvcMock.AssertNotCalled(t, "ImportMessage", mock.Anything, mock.Anything)
+	cmd.index.(*usecase_mock.MongoIndexUseCaseMock).AssertNotCalled(t, "EnsureIndexes")
+	assert.Contains(t, outPut, "Mode: import (dry run)")
+	assert.Contains(t, outPut, "Channels: 1 imported, 0 already imported, 1 skipped")
+	assert.Contains(t, outPut, "Messages: 3 imported, 0 already imported, 4 skipped")
+	assert.Contains(t, outPut, "処理完了")
+}
+
+func TestImportSlackCmdRunError(t *testing.T) {
+	archive := writeSlackArchive(t, slackImportFiles())
+	mapping := writeSlackMapping(t)
+	brokenMapping := filepath.Join(t.TempDir(), "broken.json")
+	os.WriteFile(brokenMapping, []byte("broken"), 0o644)
+
+	expected := map[string]struct {
+		args      []string
+		mapping   string
+		indexErr  error
+		roomErr   error
+		importErr error
+		contains  []string
+	}{
+		"missing archive": {
+			args:     []string{},
+			mapping:  mapping,
+			contains: []string{"Usage: import-slack <export.zip> --mapping <users.json>"},
+		},
+		"missing mapping": {
+			args:     []string{archive},
+			contains: []string{"Error reading mapping file: --mapping is required"},
+		},
+		"broken mapping": {
+			args:     []string{archive},
+			mapping:  brokenMapping,
+			contains: []string{"Error reading mapping file:"},
+		},
+		"broken archive": {
+			args:     []string{mapping},
+			mapping:  mapping,
+			contains: []string{"Error opening slack archive:"},
+		},
+		"ensure indexes": {
+			args:     []string{archive},
+			mapping:  mapping,
+			indexErr: errors.New("index error"),
+			contains: []string{"Error creating indexes: index error"},
+		},
+		"import room": {
+			args:     []string{archive},
+			mapping:  mapping,
+			roomErr:  errors.New("room error"),
+			contains: []string{"Slack import summary", "Error importing slack archive: room error"},
+		},
+		"import message": {
+			args:      []string{archive},
+			mapping:   mapping,
+			importErr: errors.New("insert error"),
+			contains:  []string{"Messages: 0 imported", "Error importing slack archive: insert error"},
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+			roomSvcMock.On("ImportRoom", mock.Anything, mock.Anything).Return(model.Room{ID: slackImportRoomID}, true, tt.roomErr)
+			messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+			messageSvcMock.On("ImportMessage", mock.Anything, mock.Anything).Return(false, tt.importErr)
+
+			cmd := newImportSlackCommand(roomSvcMock, messageSvcMock, ImportSlackOptions{TimeOut: 100, Mapping: tt.mapping})
+			if tt.indexErr != nil {
+				index := new(usecase_mock.MongoIndexUseCaseMock)
+				index.On("EnsureIndexes").Return(tt.indexErr)
+				cmd.index = index
+			}
+
+			outPut := funcs.CaptureStdout(t, func() {
+				cmd.Run(tt.args)
+			})
+			// インデックスを作成できない場合は取り込まない
+			if tt.indexErr != nil {
+				roomSvcMock.AssertNotCalled(t, "ImportRoom", mock.Anything, mock.Anything)
+				assert.NotContains(t, outPut, "Slack import summary")
+			}
+
+			for _, line := range tt.contains {
+				assert.Contains(t, outPut, line)
+			}
+			assert.NotContains(t, outPut, "処理完了")
+			// 失敗した時点で中断する
+			assert.LessOrEqual(t, len(messageSvcMock.Calls), 1)
+		})
+	}
+}
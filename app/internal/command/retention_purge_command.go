@@ -0,0 +1,190 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/cmd_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"golang.org/x/sync/errgroup"
+)
+
+type RetentionPurgeOptions struct {
+	// 全体のタイムアウト（秒）
+	TimeOut     int
+	Concurrency int
+	BatchSize   int
+	// 対象の件数を数えるだけで、削除しない
+	DryRun bool
+	// 削除する前にアーカイブ用のコレクションへ複製する（添付ファイルは残す）
+	Archive bool
+}
+
+type RetentionPurgeCommandInterface interface {
+	SetUp(mongo usecase.MongoUseCaseInterface, storage usecase.StorageUseCaseInterface, options RetentionPurgeOptions)
+	Run(args []string)
+}
+
+type RetentionPurgeCommand struct {
+	BaseCommand
+	room_svc       cmd_svc.RoomSvcInterface
+	message_svc    cmd_svc.MessageSvcInterface
+	attachment_svc service.AttachmentSvcInterface
+	clock          atylabclock.ClockInterface
+	options        RetentionPurgeOptions
+}
+
+func NewRetentionPurgeCommand() *RetentionPurgeCommand {
+	return &RetentionPurgeCommand{}
+}
+
+func (c *RetentionPurgeCommand) SetUp(
+	mongo usecase.MongoUseCaseInterface,
+	storage usecase.StorageUseCaseInterface,
+	options RetentionPurgeOptions,
+) {
+	c.room_svc = cmd_svc.NewRoomSvcStruct(
+		mongo,
+	)
+	c.message_svc = cmd_svc.NewMessageSvcStruct(
+		mongo,
+		usecase.NewMongoDriverUseCaseStruct(),
+	)
+	c.attachment_svc = service.NewAttachmentSvc(
+		storage,
+	)
+	c.clock = atylabclock.NewClock()
+	c.options = options
+}
+
+// 保存期間が設定されたルームについて、期間を過ぎたメッセージを削除（またはアーカイブ）する
+// ルームの並列処理は ForbiddenWordsCommand と同じく errgroup で同時実行数を制限する
+func (c *RetentionPurgeCommand) Run(args []string) {
+	// 全体のタイムアウトを設定
+	gctx, gctxCancel := context.WithTimeout(context.Background(), time.Duration(c.options.TimeOut)*time.Second)
+	defer gctxCancel()
+
+	g, gctx := errgroup.WithContext(gctx)
+	g.SetLimit(max(c.options.Concurrency, 1))
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	rooms, err := c.room_svc.ListRooms(ctx)
+	if err != nil {
+		fmt.Println("Error fetching rooms:", err.Error())
+		return
+	}
+
+	targets := []model.Room{}
+	for _, room := range rooms {
+		if room.RetentionDays > 0 {
+			targets = append(targets, room)
+		}
+	}
+
+	// 実行中に基準がずれないよう、開始時刻を基準に期限を決める
+	now := c.clock.Now()
+	batchSize := max(c.options.BatchSize, 1)
+	total := len(targets)
+	var done atomic.Int64
+	var expired atomic.Int64
+	var deleted atomic.Int64
+	var archived atomic.Int64
+
+	for _, room := range targets {
+		g.Go(func() error {
+			roomId := room.ID.Hex()
+			cutoff := now.AddDate(0, 0, -room.RetentionDays)
+
+			// カーソルは全体のタイムアウトに従わせる
+			cctx, cancel := context.WithCancel(gctx)
+			mctx := &atylabmongo.MongoCtxSvc{Ctx: cctx, Cancel: cancel}
+			defer mctx.Cancel()
+
+			// アーカイブと削除は BatchSize 件ずつまとめて実行する
+			batch := make([]model.Message, 0, batchSize)
+			flush := func() error {
+				if len(batch) == 0 {
+					return nil
+				}
+				if c.options.Archive {
+					if err := c.message_svc.ArchiveMessages(batch, now, mctx); err != nil {
+						return err
+					}
+				}
+				n, err := c.message_svc.DeleteMessages(batch, mctx)
+				if err != nil {
+					return err
+				}
+				deleted.Add(n)
+				if c.options.Archive {
+					archived.Add(n)
+				} else if n > 0 {
+					// 他の処理が先に削除していたメッセージの添付も対象になるが、存在しないファイルの削除は失敗しても無視される
+					for _, message := range batch {
+						c.attachment_svc.Remove(gctx, message.Attachments)
+					}
+				}
+				batch = batch[:0]
+				return nil
+			}
+
+			matched := 0
+			err := c.message_svc.StreamMessagesBefore(roomId, cutoff, mctx, func(message model.Message) error {
+				if err := gctx.Err(); err != nil {
+					return err
+				}
+				matched++
+				expired.Add(1)
+				if c.options.DryRun {
+					return nil
+				}
+				batch = append(batch, message)
+				if len(batch) >= batchSize {
+					return flush()
+				}
+				return nil
+			})
+			if err == nil {
+				err = flush()
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Progress: %d/%d rooms purged (Room ID: %s, Retention: %d days, Expired: %d)\n", done.Add(1), total, roomId, room.RetentionDays, matched)
+			return nil
+		})
+	}
+
+	// 途中で失敗しても、それまでに処理した件数は summary に出す
+	err = g.Wait()
+
+	mode := "delete"
+	if c.options.Archive {
+		mode = "archive"
+	}
+	if c.options.DryRun {
+		mode += " (dry run)"
+	}
+	fmt.Println("Retention purge summary")
+	fmt.Printf("  Mode: %s\n", mode)
+	fmt.Printf("  Rooms with retention: %d/%d\n", total, len(rooms))
+	fmt.Printf("  Rooms purged: %d\n", done.Load())
+	fmt.Printf("  Expired messages: %d\n", expired.Load())
+	fmt.Printf("  Deleted messages: %d\n", deleted.Load())
+	fmt.Printf("  Archived messages: %d\n", archived.Load())
+
+	if err != nil {
+		fmt.Println("Error purging messages:", err.Error())
+		return
+	}
+	fmt.Println("処理完了")
+}
@@ -0,0 +1,295 @@
+package command
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/cmd_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var retentionNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+var retentionRooms = []model.Room{
+	{
+		ID:            primitive.NewObjectID(),
+		Name:          "Thirty days",
+		RetentionDays: 30,
+	},
+	{
+		ID:   primitive.NewObjectID(),
+		Name: "Forever",
+	},
+	{
+		ID:            primitive.NewObjectID(),
+		Name:          "One week",
+		RetentionDays: 7,
+	},
+}
+
+var retentionMessages = []model.Message{
+	{
+		ID:     primitive.NewObjectID(),
+		RoomID: retentionRooms[0].ID.Hex(),
+		Attachments: []model.Attachment{
+			{ID: "a1", StorageKey: "rooms/a1"},
+		},
+	},
+	{
+		ID:     primitive.NewObjectID(),
+		RoomID: retentionRooms[0].ID.Hex(),
+	},
+	{
+		ID:     primitive.NewObjectID(),
+		RoomID: retentionRooms[2].ID.Hex(),
+	},
+}
+
+func newRetentionPurgeCommand(
+	roomSvc *cmd_svc_mock.RoomSvcMock,
+	messageSvc *cmd_svc_mock.MessageSvcMock,
+	attachmentSvc *svc_mock.AttachmentSvcMock,
+	options RetentionPurgeOptions,
+) *RetentionPurgeCommand {
+	cmd := NewRetentionPurgeCommand()
+	cmd.room_svc = roomSvc
+	cmd.message_svc = messageSvc
+	cmd.attachment_svc = attachmentSvc
+	cmd.clock = atylabclock.NewClockMock(retentionNow)
+	cmd.options = options
+	return cmd
+}
+
+func TestRetentionPurgeCmdSetUp(t *testing.T) {
+	cmd := NewRetentionPurgeCommand()
+	cmd.SetUp(&usecase.MongoUseCaseStruct{}, &usecase.StorageUseCaseStruct{}, RetentionPurgeOptions{
+		TimeOut:     60,
+		Concurrency: 2,
+		BatchSize:   10,
+		Archive:     true,
+	})
+
+	assert.NotNil(t, cmd.room_svc)
+	assert.NotNil(t, cmd.message_svc)
+	assert.NotNil(t, cmd.attachment_svc)
+	assert.NotNil(t, cmd.clock)
+	assert.Equal(t, 60, cmd.options.TimeOut)
+	assert.Equal(t, 2, cmd.options.Concurrency)
+	assert.Equal(t, 10, cmd.options.BatchSize)
+	assert.True(t, cmd.options.Archive)
+}
+
+func TestRetentionPurgeCmdRun(t *testing.T) {
+	expected := map[string]struct {
+		options  RetentionPurgeOptions
+		batches  [][]model.Message
+		contains []string
+	}{
+		"delete": {
+			options: RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 1},
+			batches: [][]model.Message{{retentionMessages[0]}, {retentionMessages[1]}, {retentionMessages[2]}},
+			contains: []string{
+				"Mode: delete",
+				"Rooms with retention: 2/3",
+				"Expired messages: 3",
+				"Deleted messages: 3",
+				"Archived messages: 0",
+			},
+		},
+		"archive": {
+			options: RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 500, Archive: true},
+			// ルームごとに BatchSize 件までまとめて処理する
+			batches: [][]model.Message{{retentionMessages[0], retentionMessages[1]}, {retentionMessages[2]}},
+			contains: []string{
+				"Mode: archive",
+				"Expired messages: 3",
+				"Deleted messages: 3",
+				"Archived messages: 3",
+			},
+		},
+		"dry run": {
+			options: RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 500, DryRun: true},
+			contains: []string{
+				"Mode: delete (dry run)",
+				"Expired messages: 3",
+				"Deleted messages: 0",
+			},
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+			roomSvcMock.On("ListRooms", mock.Anything).Return(retentionRooms, nil)
+
+			messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+			// ルームごとの保存日数から基準日時が決まる
+			messageSvcMock.On("StreamMessagesBefore", retentionRooms[0].ID.Hex(), retentionNow.AddDate(0, 0, -30), mock.Anything, mock.Anything).
+				Return([]model.Message{retentionMessages[0], retentionMessages[1]}, nil)
+			messageSvcMock.On("StreamMessagesBefore", retentionRooms[2].ID.Hex(), retentionNow.AddDate(0, 0, -7), mock.Anything, mock.Anything).
+				Return([]model.Message{retentionMessages[2]}, nil)
+
+			attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+			for _, batch := range tt.batches {
+				messageSvcMock.On("DeleteMessages", batch, mock.Anything).Return(int64(len(batch)), nil).Once()
+				if tt.options.Archive {
+					messageSvcMock.On("ArchiveMessages", batch, retentionNow, mock.Anything).Return(nil).Once()
+					continue
+				}
+				for _, message := range batch {
+					attachmentSvcMock.On("Remove", mock.Anything, message.Attachments).Return().Once()
+				}
+			}
+
+			cmd := newRetentionPurgeCommand(roomSvcMock, messageSvcMock, attachmentSvcMock, tt.options)
+
+			outPut := funcs.CaptureStdout(t, func() {
+				cmd.Run([]string{})
+			})
+
+			roomSvcMock.AssertExpectations(t)
+			messageSvcMock.AssertExpectations(t)
+			attachmentSvcMock.AssertExpectations(t)
+			if tt.options.DryRun {
+				messageSvcMock.AssertNotCalled(t, "DeleteMessages", mock.Anything, mock.Anything)
+				messageSvcMock.AssertNotCalled(t, "ArchiveMessages", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.options.Archive {
+				// アーカイブした場合は添付ファイルを残す
+				attachmentSvcMock.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+			}
+
+			assert.Equal(t, 2, strings.Count(outPut, "Progress:"))
+			assert.NotContains(t, outPut, retentionRooms[1].ID.Hex())
+			for _, line := range tt.contains {
+				assert.Contains(t, outPut, line)
+			}
+			assert.Contains(t, outPut, "処理完了")
+		})
+	}
+}
+
+func TestRetentionPurgeCmdRunAlreadyDeleted(t *testing.T) {
+	roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+	roomSvcMock.On("ListRooms", mock.Anything).Return([]model.Room{retentionRooms[0]}, nil)
+
+	messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+	messageSvcMock.On("StreamMessagesBefore", retentionRooms[0].ID.Hex(), mock.Anything, mock.Anything, mock.Anything).
+		Return([]model.Message{retentionMessages[0]}, nil)
+	// 他の処理（期限切れのワーカーなど）で先に消えていた場合は数えず、添付ファイルも触らない
+	messageSvcMock.On("DeleteMessages", []model.Message{retentionMessages[0]}, mock.Anything).Return(int64(0), nil)
+
+	attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+
+	cmd := newRetentionPurgeCommand(roomSvcMock, messageSvcMock, attachmentSvcMock, RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 10})
+
+	outPut := funcs.CaptureStdout(t, func() {
+		cmd.Run([]string{})
+	})
+
+	messageSvcMock.AssertExpectations(t)
+	attachmentSvcMock.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+	assert.Contains(t, outPut, "Expired messages: 1")
+	assert.Contains(t, outPut, "Deleted messages: 0")
+	assert.Contains(t, outPut, "処理完了")
+}
+
+func TestRetentionPurgeCmdRunError(t *testing.T) {
+	t.Run("list rooms", func(t *testing.T) {
+		roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+		roomSvcMock.On("ListRooms", mock.Anything).Return([]model.Room{}, errors.New("failed to list rooms"))
+		messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+
+		cmd := newRetentionPurgeCommand(roomSvcMock, messageSvcMock, new(svc_mock.AttachmentSvcMock), RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 10})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{})
+		})
+
+		assert.Contains(t, outPut, "Error fetching rooms:")
+		assert.NotContains(t, outPut, "処理完了")
+		messageSvcMock.AssertNotCalled(t, "StreamMessagesBefore", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+		roomSvcMock.On("ListRooms", mock.Anything).Return([]model.Room{retentionRooms[0]}, nil)
+		messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamMessagesBefore", retentionRooms[0].ID.Hex(), mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Message{}, errors.New("cursor error"))
+
+		cmd := newRetentionPurgeCommand(roomSvcMock, messageSvcMock, new(svc_mock.AttachmentSvcMock), RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 10})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{})
+		})
+
+		assert.Contains(t, outPut, "Retention purge summary")
+		assert.Contains(t, outPut, "Error purging messages: cursor error")
+		assert.NotContains(t, outPut, "処理完了")
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+		roomSvcMock.On("ListRooms", mock.Anything).Return([]model.Room{retentionRooms[0]}, nil)
+		messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamMessagesBefore", retentionRooms[0].ID.Hex(), mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Message{retentionMessages[0]}, nil)
+		messageSvcMock.On("ArchiveMessages", []model.Message{retentionMessages[0]}, retentionNow, mock.Anything).Return(errors.New("archive error"))
+
+		cmd := newRetentionPurgeCommand(roomSvcMock, messageSvcMock, new(svc_mock.AttachmentSvcMock), RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 10, Archive: true})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{})
+		})
+
+		// アーカイブに失敗したメッセージは削除しない
+		messageSvcMock.AssertNotCalled(t, "DeleteMessages", mock.Anything, mock.Anything)
+		assert.Contains(t, outPut, "Error purging messages: archive error")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+		roomSvcMock.On("ListRooms", mock.Anything).Return([]model.Room{retentionRooms[0]}, nil)
+		messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamMessagesBefore", retentionRooms[0].ID.Hex(), mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Message{retentionMessages[0]}, nil)
+		messageSvcMock.On("DeleteMessages", []model.Message{retentionMessages[0]}, mock.Anything).Return(int64(0), errors.New("delete error"))
+		attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+
+		cmd := newRetentionPurgeCommand(roomSvcMock, messageSvcMock, attachmentSvcMock, RetentionPurgeOptions{TimeOut: 100, Concurrency: 5, BatchSize: 10})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{})
+		})
+
+		attachmentSvcMock.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+		assert.Contains(t, outPut, "Error purging messages: delete error")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		roomSvcMock := new(cmd_svc_mock.RoomSvcMock)
+		roomSvcMock.On("ListRooms", mock.Anything).Return([]model.Room{retentionRooms[0]}, nil)
+		messageSvcMock := new(cmd_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamMessagesBefore", retentionRooms[0].ID.Hex(), mock.Anything, mock.Anything, mock.Anything).
+			Return([]model.Message{retentionMessages[0]}, nil)
+
+		cmd := newRetentionPurgeCommand(roomSvcMock, messageSvcMock, new(svc_mock.AttachmentSvcMock), RetentionPurgeOptions{TimeOut: 0, Concurrency: 5, BatchSize: 10})
+
+		outPut := funcs.CaptureStdout(t, func() {
+			cmd.Run([]string{})
+		})
+
+		messageSvcMock.AssertNotCalled(t, "DeleteMessages", mock.Anything, mock.Anything)
+		assert.Contains(t, outPut, "Error purging messages:")
+	})
+}
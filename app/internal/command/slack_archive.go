@@ -0,0 +1,250 @@
+package command
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+)
+
+// Slack のエクスポート（ZIP）に含まれるチャンネル
+type slackChannel struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Created int64    `json:"created"`
+	Creator string   `json:"creator"`
+	Members []string `json:"members"`
+	// groups.json（プライベートチャンネル）から読み込んだ場合に true
+	IsPrivate bool `json:"-"`
+}
+
+type slackUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Profile  struct {
+		DisplayName string `json:"display_name"`
+		RealName    string `json:"real_name"`
+	} `json:"profile"`
+}
+
+type slackReaction struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+	Count int      `json:"count"`
+}
+
+type slackFile struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type slackMessage struct {
+	Type      string          `json:"type"`
+	Subtype   string          `json:"subtype"`
+	User      string          `json:"user"`
+	Text      string          `json:"text"`
+	Ts        string          `json:"ts"`
+	ThreadTs  string          `json:"thread_ts"`
+	Reactions []slackReaction `json:"reactions"`
+	Files     []slackFile     `json:"files"`
+}
+
+// スレッドの返信（スレッドの親メッセージ以外）かどうか
+func (m slackMessage) isThreadReply() bool {
+	return m.ThreadTs != "" && m.ThreadTs != m.Ts
+}
+
+func (m slackMessage) reactionCount() int {
+	count := 0
+	for _, reaction := range m.Reactions {
+		count += reaction.Count
+	}
+	return count
+}
+
+// 取り込みに対応していない会話の一覧（DM とグループ DM）
+var slackUnsupportedConversations = []string{"dms.json", "mpims.json"}
+
+type slackArchive struct {
+	reader       *zip.ReadCloser
+	channels     []slackChannel
+	userNames    map[string]string
+	channelNames map[string]string
+	// チャンネルのディレクトリごとの日別ファイル（日付順）
+	days map[string][]*zip.File
+	// 含まれていたが取り込まない会話のファイル
+	unsupported []string
+}
+
+// Slack のエクスポートを開き、チャンネルとユーザーの一覧を読み込む
+// メッセージは日別のファイルごとに読み込むので、全件をメモリに載せない
+func openSlackArchive(name string) (*slackArchive, error) {
+	reader, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &slackArchive{
+		reader:       reader,
+		userNames:    map[string]string{},
+		channelNames: map[string]string{},
+		days:         map[string][]*zip.File{},
+	}
+	if err := archive.load(); err != nil {
+		reader.Close()
+		return nil, err
+	}
+	return archive, nil
+}
+
+func (a *slackArchive) Close() error {
+	return a.reader.Close()
+}
+
+func (a *slackArchive) load() error {
+	// ZIP の作り方によっては全体が1つのディレクトリに入っているので、channels.json の場所を基準にする
+	root := ""
+	files := map[string]*zip.File{}
+	for _, file := range a.reader.File {
+		if path.Base(file.Name) == "channels.json" && (root == "" || len(file.Name) < len(root)) {
+			root = strings.TrimSuffix(file.Name, "channels.json")
+		}
+	}
+	for _, file := range a.reader.File {
+		if file.FileInfo().IsDir() || !strings.HasPrefix(file.Name, root) {
+			continue
+		}
+		name := strings.TrimPrefix(file.Name, root)
+		files[name] = file
+
+		dir, base := path.Split(name)
+		if dir != "" && path.Ext(base) == ".json" {
+			dir = strings.TrimSuffix(dir, "/")
+			a.days[dir] = append(a.days[dir], file)
+		}
+	}
+	if _, ok := files["channels.json"]; !ok {
+		return fmt.Errorf("channels.json not found in the archive")
+	}
+
+	for _, list := range []struct {
+		name      string
+		isPrivate bool
+	}{
+		{"channels.json", false},
+		{"groups.json", true},
+	} {
+		file, ok := files[list.name]
+		if !ok {
+			continue
+		}
+		var channels []slackChannel
+		if err := decodeZipJSON(file, &channels); err != nil {
+			return fmt.Errorf("failed to read %s: %w", list.name, err)
+		}
+		for _, channel := range channels {
+			channel.IsPrivate = list.isPrivate
+			a.channels = append(a.channels, channel)
+			a.channelNames[channel.ID] = channel.Name
+		}
+	}
+	sort.SliceStable(a.channels, func(i, j int) bool {
+		return a.channels[i].Name < a.channels[j].Name
+	})
+
+	if file, ok := files["users.json"]; ok {
+		var users []slackUser
+		if err := decodeZipJSON(file, &users); err != nil {
+			return fmt.Errorf("failed to read users.json: %w", err)
+		}
+		for _, user := range users {
+			a.userNames[user.ID] = user.displayName()
+		}
+	}
+
+	for _, name := range slackUnsupportedConversations {
+		if _, ok := files[name]; ok {
+			a.unsupported = append(a.unsupported, name)
+		}
+	}
+
+	// 日別のファイル名（YYYY-MM-DD.json）の順に並べると投稿順になる
+	for dir := range a.days {
+		sort.Slice(a.days[dir], func(i, j int) bool {
+			return a.days[dir][i].Name < a.days[dir][j].Name
+		})
+	}
+	return nil
+}
+
+func (u slackUser) displayName() string {
+	for _, name := range []string{u.Profile.DisplayName, u.Profile.RealName, u.RealName, u.Name} {
+		if name != "" {
+			return name
+		}
+	}
+	return u.ID
+}
+
+// チャンネルのメッセージを投稿順に1件ずつ fn に渡す
+func (a *slackArchive) eachMessage(channel slackChannel, fn func(day string, message slackMessage) error) error {
+	for _, file := range a.days[channel.Name] {
+		var messages []slackMessage
+		if err := decodeZipJSON(file, &messages); err != nil {
+			return fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+		day := strings.TrimSuffix(path.Base(file.Name), ".json")
+		for _, message := range messages {
+			if err := fn(day, message); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Slack の記法（<@U0123> のメンションや <https://...|label> のリンク）を読める形に直す
+// メンションはエクスポートに含まれるユーザー・チャンネルの名前に置き換える
+func (a *slackArchive) convertText(text string) string {
+	return service.ConvertSlackText(text, func(target string) (string, bool) {
+		names := a.userNames
+		if strings.HasPrefix(target, "#") {
+			names = a.channelNames
+		}
+		name, ok := names[target[1:]]
+		return name, ok
+	})
+}
+
+// Slack のタイムスタンプ（1512085950.000216）を日時に変換する
+func parseSlackTs(ts string) (time.Time, error) {
+	sec, frac, _ := strings.Cut(ts, ".")
+	seconds, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid ts %q", ts)
+	}
+	micros := int64(0)
+	if frac != "" {
+		micros, err = strconv.ParseInt((frac + "000000")[:6], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid ts %q", ts)
+		}
+	}
+	return time.Unix(seconds, micros*int64(time.Microsecond)).UTC(), nil
+}
+
+func decodeZipJSON(file *zip.File, v any) error {
+	reader, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(v)
+}
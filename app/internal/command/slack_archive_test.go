@@ -0,0 +1,148 @@
+package command
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// files の内容を JSON にして ZIP に書き込み、ZIP のパスを返す
+func writeSlackArchive(t *testing.T, files map[string]any) string {
+	t.Helper()
+
+	name := filepath.Join(t.TempDir(), "export.zip")
+	file, err := os.Create(name)
+	if err != nil {
+		t.Fatalf("create error: %v", err)
+	}
+	defer file.Close()
+
+	w := zip.NewWriter(file)
+	for path, content := range files {
+		entry, err := w.Create(path)
+		if err != nil {
+			t.Fatalf("zip error: %v", err)
+		}
+		if err := json.NewEncoder(entry).Encode(content); err != nil {
+			t.Fatalf("encode error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("zip close error: %v", err)
+	}
+	return name
+}
+
+func slackArchiveFiles(prefix string) map[string]any {
+	return map[string]any{
+		prefix + "channels.json": []map[string]any{
+			{"id": "C02", "name": "random", "created": 1500000000, "creator": "U01", "members": []string{"U01"}},
+			{"id": "C01", "name": "general", "created": 1500000000, "creator": "U01", "members": []string{"U01", "U02"}},
+		},
+		prefix + "groups.json": []map[string]any{
+			{"id": "G01", "name": "secret", "created": 1500000000, "creator": "U02", "members": []string{"U02"}},
+		},
+		prefix + "users.json": []map[string]any{
+			{"id": "U01", "name": "alice", "profile": map[string]any{"display_name": "Alice"}},
+			{"id": "U02", "name": "bob", "real_name": "Bob Smith"},
+		},
+		prefix + "dms.json": []map[string]any{},
+		prefix + "general/2017-07-15.json": []map[string]any{
+			{"type": "message", "user": "U01", "text": "second day", "ts": "1500100000.000100"},
+		},
+		prefix + "general/2017-07-14.json": []map[string]any{
+			{"type": "message", "user": "U01", "text": "first day", "ts": "1500000000.000100"},
+		},
+	}
+}
+
+func TestOpenSlackArchive(t *testing.T) {
+	for name, prefix := range map[string]string{"root": "", "nested": "export/"} {
+		t.Run(name, func(t *testing.T) {
+			archive, err := openSlackArchive(writeSlackArchive(t, slackArchiveFiles(prefix)))
+			assert.NoError(t, err)
+			defer archive.Close()
+
+			var names []string
+			for _, channel := range archive.channels {
+				names = append(names, channel.Name)
+			}
+			assert.Equal(t, []string{"general", "random", "secret"}, names)
+			assert.False(t, archive.channels[0].IsPrivate)
+			assert.True(t, archive.channels[2].IsPrivate)
+			assert.Equal(t, map[string]string{"U01": "Alice", "U02": "Bob Smith"}, archive.userNames)
+			assert.Equal(t, []string{"dms.json"}, archive.unsupported)
+
+			// 日別のファイルは日付順に読む
+			var texts []string
+			err = archive.eachMessage(archive.channels[0], func(day string, message slackMessage) error {
+				texts = append(texts, day+" "+message.Text)
+				return nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"2017-07-14 first day", "2017-07-15 second day"}, texts)
+		})
+	}
+}
+
+func TestOpenSlackArchiveError(t *testing.T) {
+	t.Run("not a zip", func(t *testing.T) {
+		name := filepath.Join(t.TempDir(), "export.zip")
+		os.WriteFile(name, []byte("not a zip"), 0o644)
+		_, err := openSlackArchive(name)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing channels.json", func(t *testing.T) {
+		_, err := openSlackArchive(writeSlackArchive(t, map[string]any{"users.json": []any{}}))
+		assert.EqualError(t, err, "channels.json not found in the archive")
+	})
+
+	t.Run("broken channels.json", func(t *testing.T) {
+		_, err := openSlackArchive(writeSlackArchive(t, map[string]any{"channels.json": "broken"}))
+		assert.ErrorContains(t, err, "failed to read channels.json")
+	})
+}
+
+func TestSlackConvertText(t *testing.T) {
+	archive := &slackArchive{
+		userNames:    map[string]string{"U01": "Alice"},
+		channelNames: map[string]string{"C01": "general"},
+	}
+
+	tests := map[string]string{
+		"hello":                                  "hello",
+		"hi <@U01>":                              "hi @Alice",
+		"hi <@U99|carol>":                        "hi @carol",
+		"hi <@U99>":                              "hi @U99",
+		"see <#C01>":                             "see #general",
+		"see <#C99|old-channel>":                 "see #old-channel",
+		"<!here> <!channel>":                     "@here @channel",
+		"<!subteam^S01|@devs> ping":              "@devs ping",
+		"<https://example.com|example> and more": "https://example.com and more",
+		"a &lt; b &amp;&amp; c &gt; d":           "a < b && c > d",
+	}
+	for text, expected := range tests {
+		assert.Equal(t, expected, archive.convertText(text), text)
+	}
+}
+
+func TestParseSlackTs(t *testing.T) {
+	ts, err := parseSlackTs("1512085950.000216")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 11, 30, 23, 52, 30, 216000, time.UTC), ts)
+
+	ts, err = parseSlackTs("1512085950")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2017, 11, 30, 23, 52, 30, 0, time.UTC), ts)
+
+	_, err = parseSlackTs("abc.123")
+	assert.Error(t, err)
+	_, err = parseSlackTs("1512085950.abc")
+	assert.Error(t, err)
+}
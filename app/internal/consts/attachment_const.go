@@ -0,0 +1,18 @@
+package consts
+
+// 添付ファイル1件あたりの上限サイズ（バイト）
+const AttachmentMaxSize int64 = 10 << 20
+
+// 1メッセージに添付できるファイル数
+const AttachmentMaxFiles = 5
+
+// 添付を許可するファイル形式
+// クライアントが送る Content-Type ではなく、ファイルの先頭から判定した形式で照合する
+var AttachmentAllowedTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+}
@@ -0,0 +1,25 @@
+package consts
+
+type messageBotKindsStruct struct {
+	IncomingWebhook string
+	Bot             string
+}
+
+// ユーザー以外が送信したメッセージの送信元の種類
+var MessageBotKinds = messageBotKindsStruct{
+	IncomingWebhook: "incoming_webhook",
+	Bot:             "bot",
+}
+
+const (
+	// ボットの API キーの先頭に付ける文字列。Authorization ヘッダーの値が JWT か API キーかをこれで見分ける
+	BotAPIKeyPrefix = "bot_"
+	// 一覧で API キーを見分けるために残す先頭の文字数（BotAPIKeyPrefix を含む）
+	BotAPIKeyDisplayLength = 12
+	// ボットが送信したメッセージの送信者（後ろにボットの ID を付ける）
+	BotSenderPrefix = "bot:"
+	// 1ユーザーが作成できるボットの数
+	BotMaxPerOwner = 10
+	// 1つのボットで同時に有効にできる API キーの数
+	BotAPIKeyMaxPerBot = 5
+)
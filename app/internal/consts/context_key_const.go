@@ -6,6 +6,11 @@ type contextKeysStruct struct {
 	RoomModel string
 	IsAdmin   string
 	IsMember  string
+	// 受信 Webhook のトークンで認証した場合の Webhook（model.IncomingWebhook）
+	IncomingWebhook string
+	// API キーで認証した場合のボット（model.Bot）と API キー（model.BotAPIKey）
+	Bot       string
+	BotAPIKey string
 }
 
 var ContextKeys = contextKeysStruct{
@@ -14,4 +19,8 @@ var ContextKeys = contextKeysStruct{
 	RoomModel: "room_model",
 	IsAdmin:   "is_admin",
 	IsMember:  "is_member",
+
+	IncomingWebhook: "incoming_webhook",
+	Bot:             "bot",
+	BotAPIKey:       "bot_api_key",
 }
@@ -15,6 +15,10 @@ func TestCtxConstList(t *testing.T) {
 		"RoomModel": "room_model",
 		"IsAdmin":   "is_admin",
 		"IsMember":  "is_member",
+
+		"IncomingWebhook": "incoming_webhook",
+		"Bot":             "bot",
+		"BotAPIKey":       "bot_api_key",
 	}
 
 	if tp.NumField() != len(expected) {
@@ -0,0 +1,36 @@
+package consts
+
+type eventTypesStruct struct {
+	MessageCreated  string
+	MessageUpdated  string
+	MessagePinned   string
+	MessageUnpinned string
+	MessageDeleted  string
+	PresenceChanged string
+	Typing          string
+	PollUpdated     string
+	MemberJoined    string
+	MemberLeft      string
+	RoomDeleted     string
+}
+
+// ルームのメンバーに通知するイベントの種類
+var EventTypes = eventTypesStruct{
+	MessageCreated:  "message.created",
+	MessageUpdated:  "message.updated",
+	MessagePinned:   "message.pinned",
+	MessageUnpinned: "message.unpinned",
+	MessageDeleted:  "message.deleted",
+	PresenceChanged: "presence.changed",
+	Typing:          "typing",
+	PollUpdated:     "poll.updated",
+	MemberJoined:    "member.joined",
+	MemberLeft:      "member.left",
+	RoomDeleted:     "room.deleted",
+}
+
+// ルームごとのイベントを保持する件数（Redis Stream の MAXLEN）
+const EventStreamMaxLen = 1000
+
+// 全ルームのイベントを保持する件数。SSE の再接続時に読み直せる範囲になる
+const EventGlobalStreamMaxLen = 10000
@@ -0,0 +1,51 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventConstList(t *testing.T) {
+	tests := map[string]struct {
+		target   any
+		expected map[string]string
+	}{
+		"EventTypes": {
+			target: EventTypes,
+			expected: map[string]string{
+				"MessageCreated":  "message.created",
+				"MessageUpdated":  "message.updated",
+				"MessagePinned":   "message.pinned",
+				"MessageUnpinned": "message.unpinned",
+				"MessageDeleted":  "message.deleted",
+				"PresenceChanged": "presence.changed",
+				"Typing":          "typing",
+				"PollUpdated":     "poll.updated",
+				"MemberJoined":    "member.joined",
+				"MemberLeft":      "member.left",
+				"RoomDeleted":     "room.deleted",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.target)
+			tp := v.Type()
+
+			if tp.NumField() != len(tt.expected) {
+				t.Fatalf("number of fields mismatch: expected %d, got %d",
+					len(tt.expected), tp.NumField())
+			}
+
+			for i := 0; i < tp.NumField(); i++ {
+				name := tp.Field(i).Name
+				value := v.Field(i).String()
+				if value != tt.expected[name] {
+					t.Errorf("value mismatch for %s: expected %s, got %s",
+						name, tt.expected[name], value)
+				}
+			}
+		})
+	}
+}
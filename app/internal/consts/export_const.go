@@ -0,0 +1,26 @@
+package consts
+
+import "time"
+
+type exportFormatsStruct struct {
+	JSON string
+	CSV  string
+	TXT  string
+	HTML string
+}
+
+var ExportFormats = exportFormatsStruct{
+	JSON: "json",
+	CSV:  "csv",
+	TXT:  "txt",
+	HTML: "html",
+}
+
+const (
+	// タイムゾーンを指定しなかった場合に使うタイムゾーン
+	ExportDefaultTimezone = "UTC"
+	// 書き出し中のレスポンスをクライアントへ送り出す間隔（メッセージ数）
+	ExportFlushInterval = 100
+	// 1回のエクスポートにかけられる時間の上限
+	ExportTimeout = 10 * time.Minute
+)
@@ -0,0 +1,37 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExportConstList(t *testing.T) {
+	v := reflect.ValueOf(ExportFormats)
+	tp := v.Type()
+
+	expected := map[string]string{
+		"JSON": "json",
+		"CSV":  "csv",
+		"TXT":  "txt",
+		"HTML": "html",
+	}
+
+	if tp.NumField() != len(expected) {
+		t.Fatalf("number of fields mismatch: expected %d, got %d",
+			len(expected), tp.NumField())
+	}
+
+	for i := 0; i < tp.NumField(); i++ {
+		name := tp.Field(i).Name
+		value := v.Field(i).String()
+
+		expVal, ok := expected[name]
+		if !ok {
+			t.Errorf("unexpected field added: %s", name)
+		}
+		if value != expVal {
+			t.Errorf("value mismatch for %s: expected %s, got %s",
+				name, expVal, value)
+		}
+	}
+}
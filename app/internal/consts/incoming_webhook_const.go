@@ -0,0 +1,14 @@
+package consts
+
+const (
+	// 受信 Webhook の投稿先。JWT・CSRF の代わりに URL に含めたトークンで認証する
+	IncomingWebhookPath = "/hooks/:webhook_id/:token"
+	// 受信 Webhook から投稿したメッセージの送信者（後ろに Webhook の ID を付ける）
+	IncomingWebhookSenderPrefix = "webhook:"
+	// 1ルームに作成できる受信 Webhook の数
+	IncomingWebhookMaxPerRoom = 10
+	// 投稿できる本文の最大長（文字数）
+	IncomingWebhookMaxTextLength = 4000
+	// 受け付けるリクエストの最大サイズ
+	IncomingWebhookMaxBodySize = 64 << 10
+)
@@ -0,0 +1,20 @@
+package consts
+
+import "time"
+
+const (
+	// 1つのメッセージでプレビューを作るURLの数
+	LinkPreviewMaxLinks = 3
+	// ページ取得のタイムアウトと、読み込む最大サイズ（OGP は head にあるので先頭だけ読めばよい）
+	LinkPreviewFetchTimeout = 5 * time.Second
+	LinkPreviewMaxBodySize  = 512 << 10
+	LinkPreviewMaxRedirects = 3
+	// 取得結果のキャッシュ期間（取得できなかったURLは短めにして再試行できるようにする）
+	LinkPreviewCacheTTL     = 24 * time.Hour
+	LinkPreviewMissCacheTTL = 10 * time.Minute
+	// 表示用に切り詰める文字数
+	LinkPreviewTitleMaxLength       = 200
+	LinkPreviewDescriptionMaxLength = 500
+	// 取得をまとめて行う時間の上限
+	LinkPreviewUnfurlTimeout = 20 * time.Second
+)
@@ -0,0 +1,11 @@
+package consts
+
+import "time"
+
+const (
+	// 削除したメッセージを管理者が閲覧・復元できる期間。過ぎたものは deleted-purge コマンドで物理削除する
+	MessageDeleteGracePeriod = 30 * 24 * time.Hour
+	// 削除したメッセージの本文の代わりに返す文言
+	DeletedMessagePlaceholder = "message deleted"
+	DeletedPurgeBatchSize     = 500
+)
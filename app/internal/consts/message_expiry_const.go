@@ -0,0 +1,16 @@
+package consts
+
+import "time"
+
+const (
+	// メッセージに指定できる有効期間（秒）の範囲
+	MessageTTLMinSeconds = 5
+	MessageTTLMaxSeconds = 7 * 24 * 60 * 60
+	// 期限切れのメッセージを1回で削除する件数
+	MessageExpiryBatchSize = 100
+	// 削除するメッセージがない場合に次に確認するまでの間隔
+	MessageExpiryWorkerInterval = 5 * time.Second
+	// TTL インデックスで削除するまでの猶予
+	// 通常はワーカーが期限どおりに削除（イベントの通知・添付の削除も）し、止まっていた場合の後始末を TTL インデックスに任せる
+	MessageExpiryGrace = 10 * time.Minute
+)
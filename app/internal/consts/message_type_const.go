@@ -0,0 +1,20 @@
+package consts
+
+type messageTypesStruct struct {
+	Me       string
+	Topic    string
+	Poll     string
+	Reminder string
+}
+
+// 通常のテキスト以外のメッセージの種類（通常のメッセージは空）
+var MessageTypes = messageTypesStruct{
+	// /me で送信した、自分の動作を表すメッセージ
+	Me: "me",
+	// /topic でトピックを変更したことを知らせるメッセージ（本文は新しいトピック）
+	Topic: "topic",
+	// 投票。本文は質問で、選択肢と投票は Message.Poll に持つ
+	Poll: "poll",
+	// 通知用のルームに届くリマインダー。本文はリマインダーの内容で、元のメッセージは Message.Reminder に持つ
+	Reminder: "reminder",
+}
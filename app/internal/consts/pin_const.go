@@ -0,0 +1,4 @@
+package consts
+
+// 1ルームでピン留めできるメッセージ数
+const PinMaxPerRoom = 50
@@ -0,0 +1,14 @@
+package consts
+
+import "time"
+
+const (
+	// 投票の選択肢の数
+	PollMinOptions = 2
+	PollMaxOptions = 10
+	// 質問と選択肢の最大長（文字数）
+	PollQuestionMaxLength = 300
+	PollOptionMaxLength   = 100
+	// 締め切りに指定できる日時の上限（現在時刻からの期間）
+	PollMaxDuration = 30 * 24 * time.Hour
+)
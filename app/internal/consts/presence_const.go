@@ -0,0 +1,26 @@
+package consts
+
+import "time"
+
+type presenceStatusesStruct struct {
+	Online  string
+	Away    string
+	Offline string
+}
+
+var PresenceStatuses = presenceStatusesStruct{
+	Online:  "online",
+	Away:    "away",
+	Offline: "offline",
+}
+
+const (
+	// 最後の heartbeat からオンラインとみなす時間
+	PresenceTTL = 60 * time.Second
+	// SSE の接続中に heartbeat を送る間隔（PresenceTTL より短くする）
+	PresenceHeartbeatInterval = 20 * time.Second
+	// heartbeat が途絶えたユーザーを1回でオフラインにする件数
+	PresenceSweepBatchSize = 100
+	// オフラインにするユーザーがいない場合に次に確認するまでの間隔
+	PresenceWorkerInterval = 5 * time.Second
+)
@@ -0,0 +1,43 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPresenceConstList(t *testing.T) {
+	tests := map[string]struct {
+		target   any
+		expected map[string]string
+	}{
+		"PresenceStatuses": {
+			target: PresenceStatuses,
+			expected: map[string]string{
+				"Online":  "online",
+				"Away":    "away",
+				"Offline": "offline",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.target)
+			tp := v.Type()
+
+			if tp.NumField() != len(tt.expected) {
+				t.Fatalf("number of fields mismatch: expected %d, got %d",
+					len(tt.expected), tp.NumField())
+			}
+
+			for i := 0; i < tp.NumField(); i++ {
+				name := tp.Field(i).Name
+				value := v.Field(i).String()
+				if value != tt.expected[name] {
+					t.Errorf("value mismatch for %s: expected %s, got %s",
+						name, tt.expected[name], value)
+				}
+			}
+		})
+	}
+}
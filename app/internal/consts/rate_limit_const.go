@@ -0,0 +1,37 @@
+package consts
+
+import "time"
+
+type rateLimitGroupsStruct struct {
+	Message     string
+	MessageSend string
+}
+
+// レートリミットを適用するルートグループ名
+// 環境変数 RATE_LIMIT_<グループ名の大文字> で上限を上書きできる
+var RateLimitGroups = rateLimitGroupsStruct{
+	Message:     "message",
+	MessageSend: "message_send",
+}
+
+type RateLimitRule struct {
+	// ユーザー単位の上限
+	UserLimit int
+	// ユーザー×ルーム単位の上限（0の場合はルーム単位では制限しない）
+	RoomLimit int
+	// スライディングウィンドウの幅
+	Window time.Duration
+}
+
+var RateLimitRules = map[string]RateLimitRule{
+	RateLimitGroups.Message: {
+		UserLimit: 300,
+		RoomLimit: 120,
+		Window:    time.Minute,
+	},
+	RateLimitGroups.MessageSend: {
+		UserLimit: 60,
+		RoomLimit: 20,
+		Window:    time.Minute,
+	},
+}
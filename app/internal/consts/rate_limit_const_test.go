@@ -0,0 +1,40 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRateLimitGroupsConstList(t *testing.T) {
+	v := reflect.ValueOf(RateLimitGroups)
+	tp := v.Type()
+
+	expected := map[string]string{
+		"Message":     "message",
+		"MessageSend": "message_send",
+	}
+
+	if tp.NumField() != len(expected) {
+		t.Fatalf("number of fields mismatch: expected %d, got %d",
+			len(expected), tp.NumField())
+	}
+
+	for i := 0; i < tp.NumField(); i++ {
+		name := tp.Field(i).Name
+		value := v.Field(i).String()
+
+		if value != expected[name] {
+			t.Errorf("value mismatch for %s: expected %s, got %s",
+				name, expected[name], value)
+		}
+
+		// 全てのグループにルールが定義されていること
+		rule, ok := RateLimitRules[value]
+		if !ok {
+			t.Errorf("rate limit rule is not defined for %s", value)
+		}
+		if rule.UserLimit <= 0 || rule.Window <= 0 {
+			t.Errorf("invalid rate limit rule for %s: %+v", value, rule)
+		}
+	}
+}
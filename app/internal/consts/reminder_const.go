@@ -0,0 +1,42 @@
+package consts
+
+import "time"
+
+type reminderStatusStruct struct {
+	Pending  string
+	Sending  string
+	Sent     string
+	Failed   string
+	Canceled string
+}
+
+var ReminderStatus = reminderStatusStruct{
+	Pending:  "pending",
+	Sending:  "sending",
+	Sent:     "sent",
+	Failed:   "failed",
+	Canceled: "canceled",
+}
+
+const (
+	// 設定できる通知日時の上限（現在時刻からの期間）
+	ReminderMaxAhead = 365 * 24 * time.Hour
+	// 1人が設定しておけるリマインダーの件数
+	ReminderMaxPending = 50
+	// リマインダーの内容の最大長（文字数）
+	ReminderTextMaxLength = 1000
+	// 通知処理中のリマインダーを確保しておく時間（過ぎると他のインスタンスが再送できる）
+	ReminderLease = time.Minute
+	// 通知に失敗したリマインダーを再送する最大回数
+	ReminderMaxAttempts = 5
+	// 通知に失敗したリマインダーを再送するまでの待ち時間（試行回数に応じて倍にする）
+	ReminderRetryBase = 15 * time.Second
+	// 通知するリマインダーがない場合に次に確認するまでの間隔
+	ReminderWorkerInterval = 5 * time.Second
+	// リマインダーを届けるメッセージの送信者
+	ReminderSender = "system"
+	// ユーザーごとの通知用ルームの external_id に付ける接頭辞（後ろにユーザーの uuid を付ける）
+	NotificationRoomIDPrefix = "notifications:"
+	// 通知用ルームの名前
+	NotificationRoomName = "Notifications"
+)
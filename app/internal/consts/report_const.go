@@ -0,0 +1,47 @@
+package consts
+
+type reportReasonsStruct struct {
+	Spam          string
+	Harassment    string
+	HateSpeech    string
+	Violence      string
+	SexualContent string
+	Other         string
+}
+
+// 通報理由のカテゴリ
+// リクエストのバリデーション(oneof)にも同じ値を定義しているので、追加する場合は合わせて修正すること
+var ReportReasons = reportReasonsStruct{
+	Spam:          "spam",
+	Harassment:    "harassment",
+	HateSpeech:    "hate_speech",
+	Violence:      "violence",
+	SexualContent: "sexual_content",
+	Other:         "other",
+}
+
+type reportStatusStruct struct {
+	Open     string
+	Resolved string
+}
+
+var ReportStatus = reportStatusStruct{
+	Open:     "open",
+	Resolved: "resolved",
+}
+
+type reportActionsStruct struct {
+	Dismissed  string
+	Deleted    string
+	UserBanned string
+}
+
+// 通報の対応結果
+var ReportActions = reportActionsStruct{
+	Dismissed:  "dismissed",
+	Deleted:    "deleted",
+	UserBanned: "user_banned",
+}
+
+// スパム検知などシステムが自動で起票した通報の通報者ID
+const ReportSystemReporter = "system"
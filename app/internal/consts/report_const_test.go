@@ -0,0 +1,61 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReportConstList(t *testing.T) {
+	tests := map[string]struct {
+		target   any
+		expected map[string]string
+	}{
+		"ReportReasons": {
+			target: ReportReasons,
+			expected: map[string]string{
+				"Spam":          "spam",
+				"Harassment":    "harassment",
+				"HateSpeech":    "hate_speech",
+				"Violence":      "violence",
+				"SexualContent": "sexual_content",
+				"Other":         "other",
+			},
+		},
+		"ReportStatus": {
+			target: ReportStatus,
+			expected: map[string]string{
+				"Open":     "open",
+				"Resolved": "resolved",
+			},
+		},
+		"ReportActions": {
+			target: ReportActions,
+			expected: map[string]string{
+				"Dismissed":  "dismissed",
+				"Deleted":    "deleted",
+				"UserBanned": "user_banned",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.target)
+			tp := v.Type()
+
+			if tp.NumField() != len(tt.expected) {
+				t.Fatalf("number of fields mismatch: expected %d, got %d",
+					len(tt.expected), tp.NumField())
+			}
+
+			for i := 0; i < tp.NumField(); i++ {
+				fieldName := tp.Field(i).Name
+				value := v.Field(i).String()
+				if expVal, ok := tt.expected[fieldName]; !ok || value != expVal {
+					t.Errorf("value mismatch for %s: expected %s, got %s",
+						fieldName, expVal, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package consts
+
+const (
+	// ルームに設定できるメッセージの保存期間（日）の上限
+	RetentionMaxDays = 3650
+	// retention-purge で1回にまとめて削除するメッセージ数の既定値
+	RetentionPurgeBatchSize = 500
+)
@@ -0,0 +1,34 @@
+package consts
+
+import "time"
+
+type scheduledMessageStatusStruct struct {
+	Pending  string
+	Sending  string
+	Sent     string
+	Failed   string
+	Canceled string
+}
+
+var ScheduledMessageStatus = scheduledMessageStatusStruct{
+	Pending:  "pending",
+	Sending:  "sending",
+	Sent:     "sent",
+	Failed:   "failed",
+	Canceled: "canceled",
+}
+
+const (
+	// 予約できる送信日時の上限（現在時刻からの期間）
+	ScheduledMessageMaxAhead = 30 * 24 * time.Hour
+	// 1ルームで1人が予約しておける件数
+	ScheduledMessageMaxPending = 20
+	// 送信処理中のメッセージを確保しておく時間（過ぎると他のインスタンスが再送できる）
+	ScheduledMessageLease = time.Minute
+	// 送信に失敗したメッセージを再送する最大回数
+	ScheduledMessageMaxAttempts = 5
+	// 送信に失敗したメッセージを再送するまでの待ち時間（試行回数に応じて倍にする）
+	ScheduledMessageRetryBase = 15 * time.Second
+	// 送信するメッセージがない場合に次に確認するまでの間隔
+	ScheduledMessageWorkerInterval = 5 * time.Second
+)
@@ -0,0 +1,45 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScheduledMessageConstList(t *testing.T) {
+	tests := map[string]struct {
+		target   any
+		expected map[string]string
+	}{
+		"ScheduledMessageStatus": {
+			target: ScheduledMessageStatus,
+			expected: map[string]string{
+				"Pending":  "pending",
+				"Sending":  "sending",
+				"Sent":     "sent",
+				"Failed":   "failed",
+				"Canceled": "canceled",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.target)
+			tp := v.Type()
+
+			if tp.NumField() != len(tt.expected) {
+				t.Fatalf("number of fields mismatch: expected %d, got %d",
+					len(tt.expected), tp.NumField())
+			}
+
+			for i := 0; i < tp.NumField(); i++ {
+				name := tp.Field(i).Name
+				value := v.Field(i).String()
+				if value != tt.expected[name] {
+					t.Errorf("value mismatch for %s: expected %s, got %s",
+						name, tt.expected[name], value)
+				}
+			}
+		})
+	}
+}
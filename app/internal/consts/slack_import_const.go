@@ -0,0 +1,18 @@
+package consts
+
+const (
+	// 取り込んだルームの external_id とメッセージの client_msg_id に付ける接頭辞
+	// 再実行したときに取り込み済みのものを判定するために使う
+	SlackImportIDPrefix = "slack:"
+	// import-slack で進捗を表示する間隔（メッセージ数）
+	SlackImportProgressInterval = 1000
+)
+
+// 本文として取り込むメッセージの subtype
+// 参加・退出やトピック変更などのシステムメッセージ、ボットの投稿は取り込まない
+var SlackImportSubtypes = map[string]bool{
+	"":                 true,
+	"thread_broadcast": true,
+	"me_message":       true,
+	"file_share":       true,
+}
@@ -0,0 +1,52 @@
+package consts
+
+import "time"
+
+type slashCommandResponseTypesStruct struct {
+	Ephemeral string
+	InChannel string
+}
+
+// 外部のコマンドが応答の表示先を指定する値（Slack の response_type と同じ）
+var SlashCommandResponseTypes = slashCommandResponseTypesStruct{
+	// 実行したユーザーにだけ返す（省略した場合もこちら）
+	Ephemeral: "ephemeral",
+	// ボットのメッセージとしてルームに送信する
+	InChannel: "in_channel",
+}
+
+type SlashCommandBuiltin struct {
+	Name        string
+	Usage       string
+	Description string
+}
+
+// サーバーに組み込みのコマンド。ボットは同じ名前のコマンドを登録できない
+var SlashCommandBuiltins = []SlashCommandBuiltin{
+	{Name: "me", Usage: "/me <text>", Description: "Describe what you are doing"},
+	{Name: "topic", Usage: "/topic [text]", Description: "Show or change the room topic"},
+	{Name: "invite", Usage: "/invite @user [@user...]", Description: "Add members to the room"},
+	{Name: "mute", Usage: "/mute", Description: "Mute or unmute notifications from the room"},
+	{Name: "poll", Usage: "/poll <question> | <option> | <option> [| <option>...]", Description: "Create a single choice poll"},
+	{Name: "remind", Usage: "/remind [me] [in] <duration> <text>", Description: "Remind yourself about something later"},
+}
+
+// 組み込みにする予定のため、ボットに登録させない名前
+var SlashCommandReservedNames = []string{}
+
+const (
+	// 1つのボットが登録できるコマンドの数
+	SlashCommandMaxPerBot = 10
+	// 外部のコマンドの応答を待つ時間。送信者を待たせるので短くする
+	SlashCommandTimeout = 3 * time.Second
+	// 外部のコマンドを呼び出すときの X-Webhook-Event ヘッダーの値
+	SlashCommandWebhookEvent = "slash_command"
+	// 外部のコマンドの応答として読み込む最大サイズ
+	SlashCommandResponseMaxBytes = 64 << 10
+	// 外部のコマンドの応答として表示する本文の最大長（文字数）
+	SlashCommandMaxTextLength = 4000
+	// /invite で一度に追加できるユーザーの数
+	SlashCommandInviteMaxUsers = 10
+	// ルームのトピックの最大長（文字数）
+	RoomTopicMaxLength = 250
+)
@@ -0,0 +1,42 @@
+package consts
+
+import "time"
+
+type spamActionsStruct struct {
+	Reject string
+	Review string
+}
+
+// スパムと判定した投稿の扱い（環境変数 SPAM_ACTION で指定する）
+// Reject: 投稿を拒否する / Review: 投稿は受け付け、モデレーターの確認待ちとして通報を起票する
+var SpamActions = spamActionsStruct{
+	Reject: "reject",
+	Review: "review",
+}
+
+type spamReasonsStruct struct {
+	DuplicateAcrossRooms string
+	DuplicateInRoom      string
+	LinkDensity          string
+}
+
+var SpamReasons = spamReasonsStruct{
+	DuplicateAcrossRooms: "duplicate_across_rooms",
+	DuplicateInRoom:      "duplicate_in_room",
+	LinkDensity:          "link_density",
+}
+
+const (
+	// 同じ内容の投稿を数える期間
+	SpamDuplicateWindow = 10 * time.Minute
+	// 期間内に同じ内容を投稿したルーム数の上限
+	SpamCrossRoomThreshold = 3
+	// 期間内に同じルームへ同じ内容を投稿できる回数の上限
+	SpamSameRoomThreshold = 5
+	// 重複判定の対象にする正規化後の最小文字数（短い挨拶などを除外する）
+	SpamMinDuplicateLength = 10
+	// 1投稿に含められるリンク数の上限
+	SpamMaxLinks = 5
+	// リンクが2つ以上ある場合に、本文に占めるリンクの割合の上限
+	SpamMaxLinkDensity = 0.6
+)
@@ -0,0 +1,50 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSpamConstList(t *testing.T) {
+	tests := map[string]struct {
+		target   any
+		expected map[string]string
+	}{
+		"SpamActions": {
+			target: SpamActions,
+			expected: map[string]string{
+				"Reject": "reject",
+				"Review": "review",
+			},
+		},
+		"SpamReasons": {
+			target: SpamReasons,
+			expected: map[string]string{
+				"DuplicateAcrossRooms": "duplicate_across_rooms",
+				"DuplicateInRoom":      "duplicate_in_room",
+				"LinkDensity":          "link_density",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.target)
+			tp := v.Type()
+
+			if tp.NumField() != len(tt.expected) {
+				t.Fatalf("number of fields mismatch: expected %d, got %d",
+					len(tt.expected), tp.NumField())
+			}
+
+			for i := 0; i < tp.NumField(); i++ {
+				name := tp.Field(i).Name
+				value := v.Field(i).String()
+				if value != tt.expected[name] {
+					t.Errorf("value mismatch for %s: expected %s, got %s",
+						name, tt.expected[name], value)
+				}
+			}
+		})
+	}
+}
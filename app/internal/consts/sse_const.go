@@ -0,0 +1,22 @@
+package consts
+
+import "time"
+
+const (
+	// SSE のエンドポイント。JWT をクエリパラメータで受け付けるのはこのパスだけ
+	SSEPath = "/events"
+	// EventSource はヘッダーを設定できないため、JWT をこのクエリパラメータで受け取る
+	SSETokenQueryParam = "token"
+	// 再接続時に読み直せなかったイベントがあることを知らせるイベント
+	SSEResetEvent = "reset"
+	// 切断時にクライアントが再接続するまでの待ち時間（ミリ秒）
+	SSERetry = 3000
+	// 新しいイベントを確認する間隔
+	SSEPollInterval = time.Second
+	// 1回に読み出すイベントの上限
+	SSEReadCount = 100
+	// イベントがない間もプロキシに切断されないよう、コメント行を送る間隔
+	SSEHeartbeatInterval = 15 * time.Second
+	// 参加しているルームの一覧を読み直す間隔
+	SSERoomRefreshInterval = 30 * time.Second
+)
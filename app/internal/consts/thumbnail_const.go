@@ -0,0 +1,52 @@
+package consts
+
+import "time"
+
+type ThumbnailSize struct {
+	Name string
+	// 長辺の最大ピクセル数
+	MaxEdge int
+}
+
+// 生成するサムネイルの種類
+// small: タイムライン表示用 / medium: プレビュー表示用
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", MaxEdge: 160},
+	{Name: "medium", MaxEdge: 640},
+}
+
+// サムネイルを生成する画像形式
+var ThumbnailSourceTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// 展開後のメモリ使用量を抑えるため、これを超える画素数の画像からはサムネイルを作らない
+const ThumbnailMaxPixels = 50_000_000
+
+type thumbnailJobStatusStruct struct {
+	Pending    string
+	Processing string
+	Done       string
+	Failed     string
+}
+
+var ThumbnailJobStatus = thumbnailJobStatusStruct{
+	Pending:    "pending",
+	Processing: "processing",
+	Done:       "done",
+	Failed:     "failed",
+}
+
+const (
+	// ワーカーが1件のジョブを確保しておく時間（過ぎると他のワーカーが再実行できる）
+	ThumbnailJobLease = 2 * time.Minute
+	// 失敗したジョブを再実行する最大回数
+	ThumbnailJobMaxAttempts = 5
+	// 失敗したジョブを再実行するまでの待ち時間（試行回数に応じて倍にする）
+	ThumbnailJobRetryBase = 30 * time.Second
+	// 実行可能なジョブがない場合に次に確認するまでの間隔
+	ThumbnailWorkerInterval = 5 * time.Second
+)
@@ -0,0 +1,11 @@
+package consts
+
+import "time"
+
+const (
+	// 入力中の通知を受け取ってから入力中とみなす時間
+	TypingTTL = 5 * time.Second
+	// 同じユーザーが同じルームで入力中を通知する最短の間隔（TypingTTL より短くする）
+	// 間隔内に届いた通知は入力中の期限だけを延ばし、ルームには流さない
+	TypingThrottle = 3 * time.Second
+)
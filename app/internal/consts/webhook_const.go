@@ -0,0 +1,69 @@
+package consts
+
+import "time"
+
+type webhookEventsStruct struct {
+	MessageCreated string
+	MemberJoined   string
+	MemberLeft     string
+	RoomDeleted    string
+}
+
+// Webhook で購読できるイベントの種類
+var WebhookEvents = webhookEventsStruct{
+	MessageCreated: "message.created",
+	MemberJoined:   "member.joined",
+	MemberLeft:     "member.left",
+	RoomDeleted:    "room.deleted",
+}
+
+type webhookDeliveryStatusStruct struct {
+	Pending    string
+	Processing string
+	Done       string
+	Failed     string
+}
+
+var WebhookDeliveryStatus = webhookDeliveryStatusStruct{
+	Pending:    "pending",
+	Processing: "processing",
+	Done:       "done",
+	Failed:     "failed",
+}
+
+type webhookHeadersStruct struct {
+	Event     string
+	Delivery  string
+	Timestamp string
+	Signature string
+}
+
+// 送信時に付けるヘッダー
+// 受信側は X-Webhook-Signature を「タイムスタンプ:本文」の HMAC-SHA256 と比べて検証する
+var WebhookHeaders = webhookHeadersStruct{
+	Event:     "X-Webhook-Event",
+	Delivery:  "X-Webhook-Delivery",
+	Timestamp: "X-Webhook-Timestamp",
+	Signature: "X-Webhook-Signature",
+}
+
+const (
+	// 署名の前に付ける方式名（X-Webhook-Signature: sha256=<hex>）
+	WebhookSignaturePrefix = "sha256="
+	// 1ルームに登録できる Webhook の数
+	WebhookMaxPerRoom = 10
+	// 送信のタイムアウト
+	WebhookTimeout = 10 * time.Second
+	// ワーカーが1件の配信を確保しておく時間（過ぎると他のワーカーが再実行できる）
+	WebhookDeliveryLease = time.Minute
+	// 失敗した配信を再実行する最大回数
+	WebhookDeliveryMaxAttempts = 6
+	// 失敗した配信を再実行するまでの待ち時間（試行回数に応じて倍にする）
+	WebhookDeliveryRetryBase = 30 * time.Second
+	// 配信の記録に残すエラー・レスポンスの最大長
+	WebhookDeliveryErrorMaxLength = 500
+	// 配信履歴として返す件数
+	WebhookDeliveryListLimit = 50
+	// 配信待ちがない場合に次に確認するまでの間隔
+	WebhookWorkerInterval = 5 * time.Second
+)
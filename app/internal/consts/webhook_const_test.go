@@ -0,0 +1,62 @@
+package consts
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWebhookConstList(t *testing.T) {
+	tests := map[string]struct {
+		target   any
+		expected map[string]string
+	}{
+		"WebhookEvents": {
+			target: WebhookEvents,
+			expected: map[string]string{
+				"MessageCreated": "message.created",
+				"MemberJoined":   "member.joined",
+				"MemberLeft":     "member.left",
+				"RoomDeleted":    "room.deleted",
+			},
+		},
+		"WebhookDeliveryStatus": {
+			target: WebhookDeliveryStatus,
+			expected: map[string]string{
+				"Pending":    "pending",
+				"Processing": "processing",
+				"Done":       "done",
+				"Failed":     "failed",
+			},
+		},
+		"WebhookHeaders": {
+			target: WebhookHeaders,
+			expected: map[string]string{
+				"Event":     "X-Webhook-Event",
+				"Delivery":  "X-Webhook-Delivery",
+				"Timestamp": "X-Webhook-Timestamp",
+				"Signature": "X-Webhook-Signature",
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := reflect.ValueOf(tt.target)
+			tp := v.Type()
+
+			if tp.NumField() != len(tt.expected) {
+				t.Fatalf("number of fields mismatch: expected %d, got %d",
+					len(tt.expected), tp.NumField())
+			}
+
+			for i := 0; i < tp.NumField(); i++ {
+				name := tp.Field(i).Name
+				value := v.Field(i).String()
+				if value != tt.expected[name] {
+					t.Errorf("value mismatch for %s: expected %s, got %s",
+						name, tt.expected[name], value)
+				}
+			}
+		})
+	}
+}
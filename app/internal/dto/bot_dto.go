@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+)
+
+type BotDtoInterface interface {
+	GetBotInfo(bot model.Bot) BotAccountResponse
+	ResponseBotList(bots []model.Bot) []BotAccountResponse
+	GetAPIKeyInfo(key model.BotAPIKey) BotAPIKeyResponse
+	ResponseAPIKeyList(keys []model.BotAPIKey) []BotAPIKeyResponse
+}
+
+type BotDtoStruct struct{}
+
+func NewBotDtoStruct() *BotDtoStruct {
+	return &BotDtoStruct{}
+}
+
+// Sender はボットが送信したメッセージの送信者、およびルームのメンバーとして追加するときの ID
+type BotAccountResponse struct {
+	ID        string `json:"ID"`
+	Sender    string `json:"Sender"`
+	Name      string `json:"Name"`
+	IconURL   string `json:"IconURL"`
+	CreatedAt string `json:"CreatedAt"`
+}
+
+// API キーは発行時のレスポンスでだけ返すので、ここには見分けるための先頭部分だけを含める
+type BotAPIKeyResponse struct {
+	ID            string   `json:"ID"`
+	BotID         string   `json:"BotID"`
+	Name          string   `json:"Name"`
+	DisplayPrefix string   `json:"DisplayPrefix"`
+	ReadOnly      bool     `json:"ReadOnly"`
+	RoomIDs       []string `json:"RoomIDs"`
+	CreatedAt     string   `json:"CreatedAt"`
+	RevokedAt     string   `json:"RevokedAt"`
+}
+
+func (d *BotDtoStruct) GetBotInfo(bot model.Bot) BotAccountResponse {
+	return BotAccountResponse{
+		ID:        bot.ID.Hex(),
+		Sender:    consts.BotSenderPrefix + bot.ID.Hex(),
+		Name:      bot.Name,
+		IconURL:   bot.IconURL,
+		CreatedAt: bot.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (d *BotDtoStruct) ResponseBotList(bots []model.Bot) []BotAccountResponse {
+	responses := []BotAccountResponse{}
+	for _, bot := range bots {
+		responses = append(responses, d.GetBotInfo(bot))
+	}
+	return responses
+}
+
+func (d *BotDtoStruct) GetAPIKeyInfo(key model.BotAPIKey) BotAPIKeyResponse {
+	roomIDs := key.Scope.RoomIDs
+	if roomIDs == nil {
+		roomIDs = []string{}
+	}
+	response := BotAPIKeyResponse{
+		ID:            key.ID.Hex(),
+		BotID:         key.BotID,
+		Name:          key.Name,
+		DisplayPrefix: key.DisplayPrefix,
+		ReadOnly:      key.Scope.ReadOnly,
+		RoomIDs:       roomIDs,
+		CreatedAt:     key.CreatedAt.Format(time.RFC3339),
+	}
+	if key.RevokedAt != nil {
+		response.RevokedAt = key.RevokedAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+func (d *BotDtoStruct) ResponseAPIKeyList(keys []model.BotAPIKey) []BotAPIKeyResponse {
+	responses := []BotAPIKeyResponse{}
+	for _, key := range keys {
+		responses = append(responses, d.GetAPIKeyInfo(key))
+	}
+	return responses
+}
@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetBotInfo(t *testing.T) {
+	dto := NewBotDtoStruct()
+
+	bot := model.Bot{
+		ID:        primitive.NewObjectID(),
+		OwnerID:   "owner-uuid",
+		Name:      "Deploy Bot",
+		IconURL:   "https://example.com/bot.png",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, BotAccountResponse{
+		ID:        bot.ID.Hex(),
+		Sender:    "bot:" + bot.ID.Hex(),
+		Name:      "Deploy Bot",
+		IconURL:   "https://example.com/bot.png",
+		CreatedAt: "2025-01-01T00:00:00Z",
+	}, dto.GetBotInfo(bot))
+}
+
+func TestResponseBotList(t *testing.T) {
+	dto := NewBotDtoStruct()
+
+	assert.Equal(t, []BotAccountResponse{}, dto.ResponseBotList(nil))
+
+	responses := dto.ResponseBotList([]model.Bot{
+		{ID: primitive.NewObjectID(), Name: "Deploy Bot"},
+		{ID: primitive.NewObjectID(), Name: "Alert Bot"},
+	})
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "Alert Bot", responses[1].Name)
+}
+
+func TestGetAPIKeyInfo(t *testing.T) {
+	dto := NewBotDtoStruct()
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	revokedAt := createdAt.Add(time.Hour)
+
+	key := model.BotAPIKey{
+		ID:            primitive.NewObjectID(),
+		BotID:         "bot-id",
+		Name:          "ci",
+		DisplayPrefix: "bot_1234abcd",
+		KeyHash:       "key-hash",
+		Scope:         model.BotAPIKeyScope{ReadOnly: true, RoomIDs: []string{"room1"}},
+		CreatedAt:     createdAt,
+		RevokedAt:     &revokedAt,
+	}
+
+	assert.Equal(t, BotAPIKeyResponse{
+		ID:            key.ID.Hex(),
+		BotID:         "bot-id",
+		Name:          "ci",
+		DisplayPrefix: "bot_1234abcd",
+		ReadOnly:      true,
+		RoomIDs:       []string{"room1"},
+		CreatedAt:     "2025-01-01T00:00:00Z",
+		RevokedAt:     "2025-01-01T01:00:00Z",
+	}, dto.GetAPIKeyInfo(key))
+
+	body, err := json.Marshal(dto.GetAPIKeyInfo(key))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "key-hash")
+
+	// 失効していない API キーは RevokedAt が空、ルームを限定しない場合は RoomIDs が空になる
+	response := dto.GetAPIKeyInfo(model.BotAPIKey{ID: primitive.NewObjectID()})
+	assert.Empty(t, response.RevokedAt)
+	assert.Equal(t, []string{}, response.RoomIDs)
+}
+
+func TestResponseAPIKeyList(t *testing.T) {
+	dto := NewBotDtoStruct()
+
+	assert.Equal(t, []BotAPIKeyResponse{}, dto.ResponseAPIKeyList(nil))
+
+	responses := dto.ResponseAPIKeyList([]model.BotAPIKey{
+		{ID: primitive.NewObjectID(), Name: "ci"},
+		{ID: primitive.NewObjectID(), Name: "monitoring"},
+	})
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "monitoring", responses[1].Name)
+}
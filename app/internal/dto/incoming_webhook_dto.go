@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+)
+
+type IncomingWebhookDtoInterface interface {
+	GetIncomingWebhookInfo(webhook model.IncomingWebhook) IncomingWebhookResponse
+	ResponseIncomingWebhookList(webhooks []model.IncomingWebhook) []IncomingWebhookResponse
+}
+
+type IncomingWebhookDtoStruct struct{}
+
+func NewIncomingWebhookDtoStruct() *IncomingWebhookDtoStruct {
+	return &IncomingWebhookDtoStruct{}
+}
+
+// トークンは作成時のレスポンスでだけ返すので、ここには含めない
+type IncomingWebhookResponse struct {
+	ID        string `json:"ID"`
+	RoomID    string `json:"RoomID"`
+	Name      string `json:"Name"`
+	IconURL   string `json:"IconURL"`
+	CreatedBy string `json:"CreatedBy"`
+	CreatedAt string `json:"CreatedAt"`
+}
+
+func (d *IncomingWebhookDtoStruct) GetIncomingWebhookInfo(webhook model.IncomingWebhook) IncomingWebhookResponse {
+	return IncomingWebhookResponse{
+		ID:        webhook.ID.Hex(),
+		RoomID:    webhook.RoomID,
+		Name:      webhook.Name,
+		IconURL:   webhook.IconURL,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (d *IncomingWebhookDtoStruct) ResponseIncomingWebhookList(webhooks []model.IncomingWebhook) []IncomingWebhookResponse {
+	responses := []IncomingWebhookResponse{}
+	for _, webhook := range webhooks {
+		responses = append(responses, d.GetIncomingWebhookInfo(webhook))
+	}
+	return responses
+}
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetIncomingWebhookInfo(t *testing.T) {
+	dto := NewIncomingWebhookDtoStruct()
+
+	webhook := model.IncomingWebhook{
+		ID:        primitive.NewObjectID(),
+		RoomID:    "room-uuid",
+		Name:      "CI",
+		IconURL:   "https://example.com/ci.png",
+		TokenHash: "token-hash",
+		CreatedBy: "admin-uuid",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, IncomingWebhookResponse{
+		ID:        webhook.ID.Hex(),
+		RoomID:    "room-uuid",
+		Name:      "CI",
+		IconURL:   "https://example.com/ci.png",
+		CreatedBy: "admin-uuid",
+		CreatedAt: "2025-01-01T00:00:00Z",
+	}, dto.GetIncomingWebhookInfo(webhook))
+
+	body, err := json.Marshal(dto.GetIncomingWebhookInfo(webhook))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "token-hash")
+}
+
+func TestResponseIncomingWebhookList(t *testing.T) {
+	dto := NewIncomingWebhookDtoStruct()
+
+	assert.Equal(t, []IncomingWebhookResponse{}, dto.ResponseIncomingWebhookList(nil))
+
+	responses := dto.ResponseIncomingWebhookList([]model.IncomingWebhook{
+		{ID: primitive.NewObjectID(), Name: "CI"},
+		{ID: primitive.NewObjectID(), Name: "Alert"},
+	})
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "Alert", responses[1].Name)
+}
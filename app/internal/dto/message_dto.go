@@ -1,10 +1,22 @@
 package dto
 
-import "github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+import (
+	"sort"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+)
 
 type MessageDtoInterface interface {
 	GetMessageInfo(message model.Message, userId string) MessageResponse
 	ResponseMessageList(messages []model.Message, uuid string) []MessageResponse
+	GetModeratorMessageInfo(message model.Message, userId string) MessageResponse
+	ResponseModeratorMessageList(messages []model.Message, uuid string) []MessageResponse
+	LinkPreviews(previews []model.LinkPreview) []LinkPreviewResponse
+	HideReaders(responses []MessageResponse, uuid string) []MessageResponse
+	GetPollInfo(poll model.MessagePoll, uuid string) PollResponse
+	ResponseReadReceipts(message model.Message, members []model.RoomMember) []ReadReceiptResponse
 }
 
 type MessageDtoStruct struct{}
@@ -14,16 +26,129 @@ func NewMessageDtoStruct() *MessageDtoStruct {
 }
 
 type MessageResponse struct {
-	ID        string   `json:"ID"`
-	RoomID    string   `json:"RoomID"`
-	Sender    string   `json:"Sender"`
-	Message   string   `json:"Message"`
-	CreatedAt string   `json:"CreatedAt"`
-	IsRead    bool     `json:"IsRead"`
-	Readers   []string `json:"Readers"`
+	ID      string `json:"ID"`
+	RoomID  string `json:"RoomID"`
+	Sender  string `json:"Sender"`
+	Message string `json:"Message"`
+	// 通常のメッセージは空。コマンドで送信したメッセージは "me" や "topic" になる
+	Type         string                `json:"Type"`
+	CreatedAt    string                `json:"CreatedAt"`
+	IsRead       bool                  `json:"IsRead"`
+	Readers      []string              `json:"Readers"`
+	ClientMsgID  string                `json:"ClientMsgID"`
+	Attachments  []AttachmentResponse  `json:"Attachments"`
+	LinkPreviews []LinkPreviewResponse `json:"LinkPreviews"`
+	Pinned       bool                  `json:"Pinned"`
+	PinnedBy     string                `json:"PinnedBy"`
+	PinnedAt     string                `json:"PinnedAt"`
+	// 有効期限（RFC3339）と、送信時に設定された有効期間（秒）。期限のないメッセージは空・0
+	// クライアントは ExpiresAt までの残り時間を表示する
+	ExpiresAt string `json:"ExpiresAt"`
+	TTL       int    `json:"TTL"`
+	// 削除されたメッセージは Deleted を true にし、本文・添付・プレビューを伏せる
+	Deleted   bool   `json:"Deleted"`
+	DeletedBy string `json:"DeletedBy"`
+	DeletedAt string `json:"DeletedAt"`
+	// 受信 Webhook などユーザー以外から送信されたメッセージの表示名とアイコン。ユーザーのメッセージは null
+	Bot *BotResponse `json:"Bot"`
+	// 投票の集計。投票以外のメッセージは null
+	Poll *PollResponse `json:"Poll"`
+	// リマインダーの通知メッセージでは、元のリマインダーとメッセージへのリンク。それ以外は null
+	Reminder *MessageReminderResponse `json:"Reminder"`
+}
+
+// メッセージに付けたリマインダーでは RoomID・MessageID から元のメッセージを開ける
+type MessageReminderResponse struct {
+	ReminderID string `json:"ReminderID"`
+	RoomID     string `json:"RoomID"`
+	MessageID  string `json:"MessageID"`
+}
+
+// 選択肢は作成したときの順で、投票では添字で指定する
+type PollResponse struct {
+	Question       string               `json:"Question"`
+	Options        []PollOptionResponse `json:"Options"`
+	MultipleChoice bool                 `json:"MultipleChoice"`
+	Anonymous      bool                 `json:"Anonymous"`
+	// 締め切り（RFC3339）。締め切りのない投票は空
+	ClosesAt string `json:"ClosesAt"`
+	// 締め切りを過ぎたか、締め切られた投票は true。締め切った日時とユーザーは締め切った場合だけ返す
+	Closed      bool   `json:"Closed"`
+	ClosedBy    string `json:"ClosedBy"`
+	ClosedAt    string `json:"ClosedAt"`
+	TotalVoters int    `json:"TotalVoters"`
+	// 自分が投票した選択肢の添字（投票していない場合は空）
+	MyVotes []int `json:"MyVotes"`
 }
 
+// 匿名の投票では Voters を空にする
+type PollOptionResponse struct {
+	Text   string   `json:"Text"`
+	Count  int      `json:"Count"`
+	Voters []string `json:"Voters"`
+}
+
+type BotResponse struct {
+	Kind    string `json:"Kind"`
+	ID      string `json:"ID"`
+	Name    string `json:"Name"`
+	IconURL string `json:"IconURL"`
+}
+
+type AttachmentResponse struct {
+	ID          string              `json:"ID"`
+	Name        string              `json:"Name"`
+	ContentType string              `json:"ContentType"`
+	Size        int64               `json:"Size"`
+	Width       int                 `json:"Width"`
+	Height      int                 `json:"Height"`
+	URL         string              `json:"URL"`
+	Thumbnails  []ThumbnailResponse `json:"Thumbnails"`
+}
+
+// 生成が終わるまでは空になるので、クライアントは元の画像を表示する
+type ThumbnailResponse struct {
+	Size   string `json:"Size"`
+	Width  int    `json:"Width"`
+	Height int    `json:"Height"`
+	URL    string `json:"URL"`
+}
+
+// プレビューは送信後に取得するので、取得が終わるまでは空になる
+type LinkPreviewResponse struct {
+	URL         string `json:"URL"`
+	Title       string `json:"Title"`
+	Description string `json:"Description"`
+	ImageURL    string `json:"ImageURL"`
+	SiteName    string `json:"SiteName"`
+}
+
+// 既読の日時を記録する前に既読になったユーザーは ReadAt が空になる
+// ルームを退出したユーザーは名前を取得できないので、Name に UUID を入れる
+type ReadReceiptResponse struct {
+	Uuid   string `json:"Uuid"`
+	Name   string `json:"Name"`
+	ReadAt string `json:"ReadAt"`
+}
+
+// 削除されたメッセージは本文の代わりに DeletedMessagePlaceholder を返す
 func (d *MessageDtoStruct) GetMessageInfo(message model.Message, userId string) MessageResponse {
+	response := d.messageInfo(message, userId)
+	if response.Deleted {
+		response.Message = consts.DeletedMessagePlaceholder
+		response.Attachments = []AttachmentResponse{}
+		response.LinkPreviews = []LinkPreviewResponse{}
+		response.Poll = nil
+	}
+	return response
+}
+
+// 管理者・モデレーター向けに、削除されたメッセージも本文を伏せずに返す
+func (d *MessageDtoStruct) GetModeratorMessageInfo(message model.Message, userId string) MessageResponse {
+	return d.messageInfo(message, userId)
+}
+
+func (d *MessageDtoStruct) messageInfo(message model.Message, userId string) MessageResponse {
 	isRead := false
 	for _, id := range message.IsReadUserIds {
 		if id == userId {
@@ -32,15 +157,138 @@ func (d *MessageDtoStruct) GetMessageInfo(message model.Message, userId string)
 		}
 	}
 
-	return MessageResponse{
-		ID:        message.ID.Hex(),
-		RoomID:    message.RoomID,
-		Sender:    message.Sender,
-		Message:   message.Message,
-		CreatedAt: message.CreatedAt.String(),
-		IsRead:    isRead,
-		Readers:   message.IsReadUserIds,
+	response := MessageResponse{
+		ID:           message.ID.Hex(),
+		RoomID:       message.RoomID,
+		Sender:       message.Sender,
+		Message:      message.Message,
+		Type:         message.Type,
+		CreatedAt:    message.CreatedAt.String(),
+		IsRead:       isRead,
+		Readers:      message.IsReadUserIds,
+		ClientMsgID:  message.ClientMsgID,
+		Attachments:  d.attachments(message),
+		LinkPreviews: d.LinkPreviews(message.LinkPreviews),
+	}
+	if message.Pin != nil {
+		response.Pinned = true
+		response.PinnedBy = message.Pin.PinnedBy
+		response.PinnedAt = message.Pin.PinnedAt.UTC().Format(time.RFC3339)
+	}
+	if message.ExpiresAt != nil {
+		response.ExpiresAt = message.ExpiresAt.UTC().Format(time.RFC3339)
+		response.TTL = int(message.ExpiresAt.Sub(message.CreatedAt).Seconds())
+	}
+	if message.Bot != nil {
+		response.Bot = &BotResponse{
+			Kind:    message.Bot.Kind,
+			ID:      message.Bot.ID,
+			Name:    message.Bot.Name,
+			IconURL: message.Bot.IconURL,
+		}
+	}
+	if message.Poll != nil {
+		poll := d.GetPollInfo(*message.Poll, userId)
+		response.Poll = &poll
+	}
+	if message.Reminder != nil {
+		response.Reminder = &MessageReminderResponse{
+			ReminderID: message.Reminder.ReminderID,
+			RoomID:     message.Reminder.RoomID,
+			MessageID:  message.Reminder.MessageID,
+		}
+	}
+	if message.DeletedAt != nil {
+		response.Deleted = true
+		response.DeletedBy = message.DeletedBy
+		response.DeletedAt = message.DeletedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
+
+// 投票を選択肢ごとに集計する。uuid には自分の投票を MyVotes に入れるユーザーを指定する（空の場合は入れない）
+func (d *MessageDtoStruct) GetPollInfo(poll model.MessagePoll, uuid string) PollResponse {
+	options := make([]PollOptionResponse, len(poll.Options))
+	for i, text := range poll.Options {
+		options[i] = PollOptionResponse{Text: text, Voters: []string{}}
+	}
+
+	myVotes := []int{}
+	for _, vote := range poll.Votes {
+		for _, option := range vote.Options {
+			if option < 0 || option >= len(options) {
+				continue
+			}
+			options[option].Count++
+			if !poll.Anonymous {
+				options[option].Voters = append(options[option].Voters, vote.UserID)
+			}
+		}
+		if uuid != "" && vote.UserID == uuid {
+			myVotes = vote.Options
+		}
+	}
+
+	response := PollResponse{
+		Question:       poll.Question,
+		Options:        options,
+		MultipleChoice: poll.MultipleChoice,
+		Anonymous:      poll.Anonymous,
+		Closed:         poll.ClosedAt != nil || (poll.ClosesAt != nil && !poll.ClosesAt.After(time.Now())),
+		ClosedBy:       poll.ClosedBy,
+		TotalVoters:    len(poll.Votes),
+		MyVotes:        myVotes,
+	}
+	if poll.ClosesAt != nil {
+		response.ClosesAt = poll.ClosesAt.UTC().Format(time.RFC3339)
+	}
+	if poll.ClosedAt != nil {
+		response.ClosedAt = poll.ClosedAt.UTC().Format(time.RFC3339)
+	}
+	return response
+}
+
+func (d *MessageDtoStruct) LinkPreviews(previews []model.LinkPreview) []LinkPreviewResponse {
+	responses := []LinkPreviewResponse{}
+	for _, preview := range previews {
+		responses = append(responses, LinkPreviewResponse{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+			SiteName:    preview.SiteName,
+		})
 	}
+	return responses
+}
+
+// ダウンロードはルームのメンバーに限定しているため、URLにはルームIDとメッセージIDを含める
+func (d *MessageDtoStruct) attachments(message model.Message) []AttachmentResponse {
+	responses := []AttachmentResponse{}
+	for _, attachment := range message.Attachments {
+		url := "/message/" + message.RoomID + "/" + message.ID.Hex() + "/attachments/" + attachment.ID
+		thumbnails := []ThumbnailResponse{}
+		for _, thumbnail := range attachment.Thumbnails {
+			thumbnails = append(thumbnails, ThumbnailResponse{
+				Size:   thumbnail.Size,
+				Width:  thumbnail.Width,
+				Height: thumbnail.Height,
+				URL:    url + "/thumbnails/" + thumbnail.Size,
+			})
+		}
+
+		responses = append(responses, AttachmentResponse{
+			ID:          attachment.ID,
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Size:        attachment.Size,
+			Width:       attachment.Width,
+			Height:      attachment.Height,
+			URL:         url,
+			Thumbnails:  thumbnails,
+		})
+	}
+	return responses
 }
 
 func (d *MessageDtoStruct) ResponseMessageList(messages []model.Message, uuid string) []MessageResponse {
@@ -50,3 +298,66 @@ func (d *MessageDtoStruct) ResponseMessageList(messages []model.Message, uuid st
 	}
 	return responses
 }
+
+func (d *MessageDtoStruct) ResponseModeratorMessageList(messages []model.Message, uuid string) []MessageResponse {
+	responses := []MessageResponse{}
+	for _, msg := range messages {
+		responses = append(responses, d.GetModeratorMessageInfo(msg, uuid))
+	}
+	return responses
+}
+
+// 既読の情報を送信者以外に見せないルームで、自分が送信したもの以外のメッセージの既読者を伏せる
+// 自分が読んだかどうか（IsRead）は本人の情報なので残す
+func (d *MessageDtoStruct) HideReaders(responses []MessageResponse, uuid string) []MessageResponse {
+	for i := range responses {
+		if responses[i].Sender != uuid {
+			responses[i].Readers = []string{}
+		}
+	}
+	return responses
+}
+
+// 送信者を除いた既読者を、読んだ順（日時のないものは最後）で返す
+func (d *MessageDtoStruct) ResponseReadReceipts(message model.Message, members []model.RoomMember) []ReadReceiptResponse {
+	names := map[string]string{}
+	for _, member := range members {
+		names[member.Uuid] = member.Name
+	}
+	readAt := map[string]time.Time{}
+	for _, receipt := range message.ReadReceipts {
+		readAt[receipt.UserID] = receipt.ReadAt
+	}
+
+	type reader struct {
+		uuid   string
+		readAt time.Time
+	}
+	readers := []reader{}
+	for _, id := range message.IsReadUserIds {
+		if id == message.Sender {
+			continue
+		}
+		readers = append(readers, reader{uuid: id, readAt: readAt[id]})
+	}
+	sort.SliceStable(readers, func(i, j int) bool {
+		if readers[i].readAt.IsZero() || readers[j].readAt.IsZero() {
+			return !readers[i].readAt.IsZero() && readers[j].readAt.IsZero()
+		}
+		return readers[i].readAt.Before(readers[j].readAt)
+	})
+
+	responses := []ReadReceiptResponse{}
+	for _, r := range readers {
+		name, ok := names[r.uuid]
+		if !ok || name == "" {
+			name = r.uuid
+		}
+		response := ReadReceiptResponse{Uuid: r.uuid, Name: name}
+		if !r.readAt.IsZero() {
+			response.ReadAt = r.readAt.UTC().Format(time.RFC3339)
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -12,14 +13,33 @@ import (
 func TestGetMessageInfo(t *testing.T) {
 
 	dto := NewMessageDtoStruct()
+	createdAt := time.Date(2025, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	expiresAt := createdAt.Add(time.Hour)
 
 	messageIsRead := model.Message{
 		ID:            primitive.NewObjectID(),
 		RoomID:        "room-uuid",
 		Sender:        "sender-uuid",
 		Message:       "Hello, World!",
-		CreatedAt:     time.Now(),
+		CreatedAt:     createdAt,
 		IsReadUserIds: []string{"reader-uuid-1", "reader-uuid-2"},
+		ClientMsgID:   "client-msg-1",
+		Type:          consts.MessageTypes.Me,
+		ExpiresAt:     &expiresAt,
+		Pin:           &model.MessagePin{PinnedBy: "moderator-uuid", PinnedAt: createdAt.Add(30*time.Minute + 500*time.Millisecond)},
+		LinkPreviews: []model.LinkPreview{
+			{URL: "https://example.com", Title: "Example", Description: "An example page", ImageURL: "https://example.com/og.png", SiteName: "Example Site"},
+		},
+		Attachments: []model.Attachment{
+			{
+				ID: "attachment-1", Name: "photo.png", ContentType: "image/png", Size: 128, StorageKey: "attachments/room-uuid/attachment-1",
+				Width: 1280, Height: 960,
+				Thumbnails: []model.AttachmentThumbnail{
+					{Size: "small", Width: 160, Height: 120, ContentType: "image/png", ByteSize: 64, StorageKey: "attachments/room-uuid/attachment-1_small"},
+				},
+			},
+			{ID: "attachment-2", Name: "memo.txt", ContentType: "text/plain", Size: 12, StorageKey: "attachments/room-uuid/attachment-2"},
+		},
 	}
 
 	messageIsNotRead := model.Message{
@@ -41,6 +61,44 @@ func TestGetMessageInfo(t *testing.T) {
 	assert.Equal(t, messageIsRead.Message, response.Message)
 	assert.Equal(t, messageIsRead.CreatedAt.String(), response.CreatedAt)
 	assert.True(t, response.IsRead)
+	assert.Equal(t, "client-msg-1", response.ClientMsgID)
+	assert.Equal(t, consts.MessageTypes.Me, response.Type)
+	assert.True(t, response.Pinned)
+	assert.Equal(t, "moderator-uuid", response.PinnedBy)
+	assert.Equal(t, "2025-01-01T00:30:00Z", response.PinnedAt)
+	assert.Equal(t, "2025-01-01T01:00:00Z", response.ExpiresAt)
+	assert.Equal(t, 3600, response.TTL)
+	assert.Equal(t, []AttachmentResponse{
+		{
+			ID:          "attachment-1",
+			Name:        "photo.png",
+			ContentType: "image/png",
+			Size:        128,
+			Width:       1280,
+			Height:      960,
+			URL:         "/message/room-uuid/" + messageIsRead.ID.Hex() + "/attachments/attachment-1",
+			Thumbnails: []ThumbnailResponse{
+				{
+					Size:   "small",
+					Width:  160,
+					Height: 120,
+					URL:    "/message/room-uuid/" + messageIsRead.ID.Hex() + "/attachments/attachment-1/thumbnails/small",
+				},
+			},
+		},
+		{
+			ID:          "attachment-2",
+			Name:        "memo.txt",
+			ContentType: "text/plain",
+			Size:        12,
+			URL:         "/message/room-uuid/" + messageIsRead.ID.Hex() + "/attachments/attachment-2",
+			Thumbnails:  []ThumbnailResponse{},
+		},
+	}, response.Attachments)
+
+	assert.Equal(t, []LinkPreviewResponse{
+		{URL: "https://example.com", Title: "Example", Description: "An example page", ImageURL: "https://example.com/og.png", SiteName: "Example Site"},
+	}, response.LinkPreviews)
 
 	response = dto.GetMessageInfo(messageIsNotRead, userId)
 
@@ -50,6 +108,176 @@ func TestGetMessageInfo(t *testing.T) {
 	assert.Equal(t, messageIsNotRead.Message, response.Message)
 	assert.Equal(t, messageIsNotRead.CreatedAt.String(), response.CreatedAt)
 	assert.False(t, response.IsRead)
+	assert.False(t, response.Pinned)
+	assert.Empty(t, response.PinnedBy)
+	assert.Empty(t, response.PinnedAt)
+	assert.Empty(t, response.ExpiresAt)
+	assert.Zero(t, response.TTL)
+	assert.False(t, response.Deleted)
+	assert.Empty(t, response.DeletedAt)
+	assert.Empty(t, response.Attachments)
+	assert.NotNil(t, response.LinkPreviews)
+	assert.Empty(t, response.LinkPreviews)
+	assert.Nil(t, response.Bot)
+}
+
+func TestGetMessageInfoBot(t *testing.T) {
+	dto := NewMessageDtoStruct()
+
+	message := model.Message{
+		ID:      primitive.NewObjectID(),
+		RoomID:  "room-uuid",
+		Sender:  "webhook:webhook-id",
+		Message: "Build passed",
+		Bot: &model.MessageBot{
+			Kind:    "incoming_webhook",
+			ID:      "webhook-id",
+			Name:    "CI",
+			IconURL: "https://example.com/ci.png",
+		},
+	}
+
+	response := dto.GetMessageInfo(message, "reader-uuid")
+	assert.Equal(t, "webhook:webhook-id", response.Sender)
+	assert.Equal(t, &BotResponse{
+		Kind:    "incoming_webhook",
+		ID:      "webhook-id",
+		Name:    "CI",
+		IconURL: "https://example.com/ci.png",
+	}, response.Bot)
+}
+
+func TestGetMessageInfoReminder(t *testing.T) {
+	dto := NewMessageDtoStruct()
+
+	message := model.Message{
+		ID:      primitive.NewObjectID(),
+		Sender:  "system",
+		Message: "Check this",
+		Type:    consts.MessageTypes.Reminder,
+		Reminder: &model.MessageReminder{
+			ReminderID: "reminder-id",
+			RoomID:     "room-id",
+			MessageID:  "message-id",
+		},
+	}
+
+	response := dto.GetMessageInfo(message, "user1")
+	assert.Equal(t, consts.MessageTypes.Reminder, response.Type)
+	assert.Equal(t, &MessageReminderResponse{
+		ReminderID: "reminder-id",
+		RoomID:     "room-id",
+		MessageID:  "message-id",
+	}, response.Reminder)
+
+	// リマインダー以外のメッセージは null
+	assert.Nil(t, dto.GetMessageInfo(model.Message{ID: primitive.NewObjectID()}, "user1").Reminder)
+}
+
+func TestGetMessageInfoPoll(t *testing.T) {
+	dto := NewMessageDtoStruct()
+	closesAt := time.Date(2025, 1, 2, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+	future := time.Now().Add(time.Hour)
+
+	poll := model.MessagePoll{
+		Question:       "Lunch?",
+		Options:        []string{"Sushi", "Ramen", "Curry"},
+		MultipleChoice: true,
+		ClosesAt:       &future,
+		Votes: []model.PollVote{
+			{UserID: "user1", Options: []int{0, 2}},
+			{UserID: "user2", Options: []int{2}},
+			// 範囲外の選択肢は集計しない
+			{UserID: "user3", Options: []int{5}},
+		},
+	}
+	message := model.Message{ID: primitive.NewObjectID(), Sender: "user1", Message: "Lunch?", Type: consts.MessageTypes.Poll, Poll: &poll}
+
+	response := dto.GetMessageInfo(message, "user1")
+	assert.Equal(t, consts.MessageTypes.Poll, response.Type)
+	assert.Equal(t, &PollResponse{
+		Question:       "Lunch?",
+		MultipleChoice: true,
+		Options: []PollOptionResponse{
+			{Text: "Sushi", Count: 1, Voters: []string{"user1"}},
+			{Text: "Ramen", Count: 0, Voters: []string{}},
+			{Text: "Curry", Count: 2, Voters: []string{"user1", "user2"}},
+		},
+		ClosesAt:    future.UTC().Format(time.RFC3339),
+		TotalVoters: 3,
+		MyVotes:     []int{0, 2},
+	}, response.Poll)
+
+	// 匿名の投票では投票者を伏せ、自分の投票だけを返す
+	poll.Anonymous = true
+	anonymous := dto.GetPollInfo(poll, "user2")
+	assert.Equal(t, 2, anonymous.Options[2].Count)
+	assert.Empty(t, anonymous.Options[2].Voters)
+	assert.Equal(t, []int{2}, anonymous.MyVotes)
+	assert.Empty(t, dto.GetPollInfo(poll, "").MyVotes)
+
+	// 締め切りを過ぎた投票と、締め切った投票
+	poll.ClosesAt = &closesAt
+	closed := dto.GetPollInfo(poll, "user1")
+	assert.True(t, closed.Closed)
+	assert.Equal(t, "2025-01-02T00:00:00Z", closed.ClosesAt)
+	assert.Empty(t, closed.ClosedAt)
+
+	poll.ClosesAt = nil
+	poll.ClosedAt = &closesAt
+	poll.ClosedBy = "user1"
+	closed = dto.GetPollInfo(poll, "user1")
+	assert.True(t, closed.Closed)
+	assert.Empty(t, closed.ClosesAt)
+	assert.Equal(t, "user1", closed.ClosedBy)
+	assert.Equal(t, "2025-01-02T00:00:00Z", closed.ClosedAt)
+
+	assert.Nil(t, dto.GetMessageInfo(model.Message{ID: primitive.NewObjectID()}, "user1").Poll)
+}
+
+func TestGetMessageInfoDeleted(t *testing.T) {
+	dto := NewMessageDtoStruct()
+	deletedAt := time.Date(2025, 1, 2, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	message := model.Message{
+		ID:            primitive.NewObjectID(),
+		RoomID:        "room-uuid",
+		Sender:        "sender-uuid",
+		Message:       "secret",
+		CreatedAt:     time.Now(),
+		IsReadUserIds: []string{"reader-uuid-1"},
+		Attachments:   []model.Attachment{{ID: "attachment-1", Name: "photo.png"}},
+		LinkPreviews:  []model.LinkPreview{{URL: "https://example.com", Title: "Example"}},
+		Poll:          &model.MessagePoll{Question: "secret", Options: []string{"a", "b"}},
+		DeletedAt:     &deletedAt,
+		DeletedBy:     "sender-uuid",
+	}
+
+	// メンバーには本文・添付・プレビューを伏せて返す
+	response := dto.GetMessageInfo(message, "reader-uuid-1")
+	assert.Equal(t, message.ID.Hex(), response.ID)
+	assert.Equal(t, "sender-uuid", response.Sender)
+	assert.Equal(t, "message deleted", response.Message)
+	assert.Empty(t, response.Attachments)
+	assert.Empty(t, response.LinkPreviews)
+	assert.Nil(t, response.Poll)
+	assert.True(t, response.Deleted)
+	assert.Equal(t, "sender-uuid", response.DeletedBy)
+	assert.Equal(t, "2025-01-02T00:00:00Z", response.DeletedAt)
+
+	// 管理者・モデレーターには削除前の内容を返す
+	response = dto.GetModeratorMessageInfo(message, "reader-uuid-1")
+	assert.Equal(t, "secret", response.Message)
+	assert.Len(t, response.Attachments, 1)
+	assert.Len(t, response.LinkPreviews, 1)
+	assert.NotNil(t, response.Poll)
+	assert.True(t, response.Deleted)
+	assert.Equal(t, "2025-01-02T00:00:00Z", response.DeletedAt)
+
+	responses := dto.ResponseModeratorMessageList([]model.Message{message}, "reader-uuid-1")
+	assert.Len(t, responses, 1)
+	assert.Equal(t, "secret", responses[0].Message)
+	assert.NotNil(t, dto.ResponseModeratorMessageList(nil, "reader-uuid-1"))
 }
 
 func TestResponseMessageList(t *testing.T) {
@@ -93,3 +321,49 @@ func TestResponseMessageList(t *testing.T) {
 	assert.Equal(t, messages[1].CreatedAt.String(), responses[1].CreatedAt)
 	assert.False(t, responses[1].IsRead)
 }
+
+func TestHideReaders(t *testing.T) {
+	dto := NewMessageDtoStruct()
+
+	responses := dto.HideReaders([]MessageResponse{
+		{Sender: "user-1", IsRead: true, Readers: []string{"user-1", "user-2"}},
+		{Sender: "user-2", IsRead: true, Readers: []string{"user-2", "user-1"}},
+	}, "user-1")
+
+	assert.Equal(t, []string{"user-1", "user-2"}, responses[0].Readers)
+	assert.Equal(t, []string{}, responses[1].Readers)
+	assert.True(t, responses[1].IsRead)
+}
+
+func TestResponseReadReceipts(t *testing.T) {
+	dto := NewMessageDtoStruct()
+	readAt := time.Date(2025, 1, 1, 9, 0, 0, 0, time.FixedZone("JST", 9*60*60))
+
+	message := model.Message{
+		Sender:        "sender",
+		IsReadUserIds: []string{"sender", "legacy", "late", "early", "left"},
+		ReadReceipts: []model.ReadReceipt{
+			{UserID: "late", ReadAt: readAt.Add(time.Minute)},
+			{UserID: "early", ReadAt: readAt},
+			{UserID: "left", ReadAt: readAt.Add(2 * time.Minute)},
+		},
+	}
+	members := []model.RoomMember{
+		{Uuid: "sender", Name: "Sender"},
+		{Uuid: "legacy", Name: "Legacy"},
+		{Uuid: "late", Name: "Late"},
+		{Uuid: "early", Name: "Early"},
+	}
+
+	assert.Equal(t, []ReadReceiptResponse{
+		{Uuid: "early", Name: "Early", ReadAt: "2025-01-01T00:00:00Z"},
+		{Uuid: "late", Name: "Late", ReadAt: "2025-01-01T00:01:00Z"},
+		{Uuid: "left", Name: "left", ReadAt: "2025-01-01T00:02:00Z"},
+		{Uuid: "legacy", Name: "Legacy", ReadAt: ""},
+	}, dto.ResponseReadReceipts(message, members))
+
+	assert.Equal(t, []ReadReceiptResponse{}, dto.ResponseReadReceipts(model.Message{
+		Sender:        "sender",
+		IsReadUserIds: []string{"sender"},
+	}, members))
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+)
+
+type ReminderDtoInterface interface {
+	GetReminderInfo(reminder model.Reminder) ReminderResponse
+	ResponseReminderList(reminders []model.Reminder) []ReminderResponse
+}
+
+type ReminderDtoStruct struct{}
+
+func NewReminderDtoStruct() *ReminderDtoStruct {
+	return &ReminderDtoStruct{}
+}
+
+// 自由入力のリマインダーは RoomID・MessageID・Snapshot が空になる
+// RemindAt はスヌーズ時にそのまま送り返せるよう、リクエストと同じ RFC3339 形式で返す
+type ReminderResponse struct {
+	ID        string `json:"ID"`
+	RoomID    string `json:"RoomID"`
+	MessageID string `json:"MessageID"`
+	Snapshot  string `json:"Snapshot"`
+	Text      string `json:"Text"`
+	RemindAt  string `json:"RemindAt"`
+	Status    string `json:"Status"`
+	CreatedAt string `json:"CreatedAt"`
+}
+
+func (d *ReminderDtoStruct) GetReminderInfo(reminder model.Reminder) ReminderResponse {
+	return ReminderResponse{
+		ID:        reminder.ID.Hex(),
+		RoomID:    reminder.RoomID,
+		MessageID: reminder.MessageID,
+		Snapshot:  reminder.Snapshot,
+		Text:      reminder.Text,
+		RemindAt:  reminder.RemindAt.UTC().Format(time.RFC3339),
+		Status:    reminder.Status,
+		CreatedAt: reminder.CreatedAt.String(),
+	}
+}
+
+func (d *ReminderDtoStruct) ResponseReminderList(reminders []model.Reminder) []ReminderResponse {
+	responses := []ReminderResponse{}
+	for _, r := range reminders {
+		responses = append(responses, d.GetReminderInfo(r))
+	}
+	return responses
+}
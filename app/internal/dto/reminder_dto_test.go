@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetReminderInfo(t *testing.T) {
+	dto := NewReminderDtoStruct()
+
+	reminder := model.Reminder{
+		ID:        primitive.NewObjectID(),
+		UserID:    "user-uuid",
+		RoomID:    "room-id",
+		MessageID: "message-id",
+		Snapshot:  "Can you review this?",
+		Text:      "Review the PR",
+		RemindAt:  time.Date(2025, 1, 2, 18, 0, 0, 0, time.FixedZone("JST", 9*60*60)),
+		Status:    "pending",
+		CreatedAt: time.Now(),
+	}
+
+	response := dto.GetReminderInfo(reminder)
+
+	assert.Equal(t, ReminderResponse{
+		ID:        reminder.ID.Hex(),
+		RoomID:    "room-id",
+		MessageID: "message-id",
+		Snapshot:  "Can you review this?",
+		Text:      "Review the PR",
+		RemindAt:  "2025-01-02T09:00:00Z",
+		Status:    "pending",
+		CreatedAt: reminder.CreatedAt.String(),
+	}, response)
+}
+
+func TestResponseReminderList(t *testing.T) {
+	dto := NewReminderDtoStruct()
+
+	responses := dto.ResponseReminderList([]model.Reminder{
+		{ID: primitive.NewObjectID(), Text: "first"},
+		{ID: primitive.NewObjectID(), Text: "second"},
+	})
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "first", responses[0].Text)
+	assert.Equal(t, "second", responses[1].Text)
+
+	// リマインダーがない場合も null ではなく空の配列を返す
+	empty := dto.ResponseReminderList(nil)
+	assert.NotNil(t, empty)
+	assert.Empty(t, empty)
+}
@@ -0,0 +1,69 @@
+package dto
+
+import "github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+
+type ReportDtoInterface interface {
+	GetReportInfo(report model.Report) ReportResponse
+	ResponseReportList(reports []model.Report) []ReportResponse
+}
+
+type ReportDtoStruct struct{}
+
+func NewReportDtoStruct() *ReportDtoStruct {
+	return &ReportDtoStruct{}
+}
+
+type ReportResolutionResponse struct {
+	Action     string `json:"Action"`
+	ResolvedBy string `json:"ResolvedBy"`
+	Note       string `json:"Note"`
+	ResolvedAt string `json:"ResolvedAt"`
+}
+
+type ReportResponse struct {
+	ID             string                    `json:"ID"`
+	RoomID         string                    `json:"RoomID"`
+	MessageID      string                    `json:"MessageID"`
+	ReporterID     string                    `json:"ReporterID"`
+	ReportedUserID string                    `json:"ReportedUserID"`
+	Reason         string                    `json:"Reason"`
+	Comment        string                    `json:"Comment"`
+	Snapshot       string                    `json:"Snapshot"`
+	Status         string                    `json:"Status"`
+	CreatedAt      string                    `json:"CreatedAt"`
+	Resolution     *ReportResolutionResponse `json:"Resolution"`
+}
+
+func (d *ReportDtoStruct) GetReportInfo(report model.Report) ReportResponse {
+	var resolution *ReportResolutionResponse
+	if report.Resolution != nil {
+		resolution = &ReportResolutionResponse{
+			Action:     report.Resolution.Action,
+			ResolvedBy: report.Resolution.ResolvedBy,
+			Note:       report.Resolution.Note,
+			ResolvedAt: report.Resolution.ResolvedAt.String(),
+		}
+	}
+
+	return ReportResponse{
+		ID:             report.ID.Hex(),
+		RoomID:         report.RoomID,
+		MessageID:      report.MessageID,
+		ReporterID:     report.ReporterID,
+		ReportedUserID: report.ReportedUserID,
+		Reason:         report.Reason,
+		Comment:        report.Comment,
+		Snapshot:       report.Snapshot,
+		Status:         report.Status,
+		CreatedAt:      report.CreatedAt.String(),
+		Resolution:     resolution,
+	}
+}
+
+func (d *ReportDtoStruct) ResponseReportList(reports []model.Report) []ReportResponse {
+	responses := []ReportResponse{}
+	for _, report := range reports {
+		responses = append(responses, d.GetReportInfo(report))
+	}
+	return responses
+}
@@ -0,0 +1,76 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetReportInfo(t *testing.T) {
+	dto := NewReportDtoStruct()
+
+	openReport := model.Report{
+		ID:             primitive.NewObjectID(),
+		RoomID:         "room-uuid",
+		MessageID:      "message-id",
+		ReporterID:     "reporter-uuid",
+		ReportedUserID: "reported-uuid",
+		Reason:         "spam",
+		Comment:        "advertisement",
+		Snapshot:       "buy now",
+		Status:         "open",
+		CreatedAt:      time.Now(),
+	}
+
+	response := dto.GetReportInfo(openReport)
+
+	assert.Equal(t, openReport.ID.Hex(), response.ID)
+	assert.Equal(t, openReport.RoomID, response.RoomID)
+	assert.Equal(t, openReport.MessageID, response.MessageID)
+	assert.Equal(t, openReport.ReporterID, response.ReporterID)
+	assert.Equal(t, openReport.ReportedUserID, response.ReportedUserID)
+	assert.Equal(t, openReport.Reason, response.Reason)
+	assert.Equal(t, openReport.Comment, response.Comment)
+	assert.Equal(t, openReport.Snapshot, response.Snapshot)
+	assert.Equal(t, openReport.Status, response.Status)
+	assert.Equal(t, openReport.CreatedAt.String(), response.CreatedAt)
+	assert.Nil(t, response.Resolution)
+
+	resolvedReport := openReport
+	resolvedReport.Status = "resolved"
+	resolvedReport.Resolution = &model.ReportResolution{
+		Action:     "deleted",
+		ResolvedBy: "moderator-uuid",
+		Note:       "removed",
+		ResolvedAt: time.Now(),
+	}
+
+	response = dto.GetReportInfo(resolvedReport)
+
+	assert.Equal(t, "resolved", response.Status)
+	assert.NotNil(t, response.Resolution)
+	assert.Equal(t, "deleted", response.Resolution.Action)
+	assert.Equal(t, "moderator-uuid", response.Resolution.ResolvedBy)
+	assert.Equal(t, "removed", response.Resolution.Note)
+	assert.Equal(t, resolvedReport.Resolution.ResolvedAt.String(), response.Resolution.ResolvedAt)
+}
+
+func TestResponseReportList(t *testing.T) {
+	dto := NewReportDtoStruct()
+
+	reports := []model.Report{
+		{ID: primitive.NewObjectID(), Reason: "spam"},
+		{ID: primitive.NewObjectID(), Reason: "harassment"},
+	}
+
+	responses := dto.ResponseReportList(reports)
+
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "spam", responses[0].Reason)
+	assert.Equal(t, "harassment", responses[1].Reason)
+
+	assert.Empty(t, dto.ResponseReportList([]model.Report{}))
+}
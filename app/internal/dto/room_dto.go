@@ -14,14 +14,20 @@ func NewRoomDtoStruct() *RoomDtoStruct {
 }
 
 type RoomListResponse struct {
-	ID          string `json:"ID"`
-	Name        string `json:"Name"`
-	OwnerID     string `json:"OwnerID"`
-	IsPrivate   bool   `json:"IsPrivate"`
-	IsMember    bool   `json:"IsMember"`
-	IsOwner     bool   `json:"IsOwner"`
-	MemberCount int    `json:"MemberCount"`
-	CreatedAt   string `json:"CreatedAt"`
+	ID               string `json:"ID"`
+	Name             string `json:"Name"`
+	OwnerID          string `json:"OwnerID"`
+	IsPrivate        bool   `json:"IsPrivate"`
+	IsMember         bool   `json:"IsMember"`
+	IsOwner          bool   `json:"IsOwner"`
+	MemberCount      int    `json:"MemberCount"`
+	CreatedAt        string `json:"CreatedAt"`
+	MessageTTL       int    `json:"MessageTTL"`
+	RetentionDays    int    `json:"RetentionDays"`
+	HideReadReceipts bool   `json:"HideReadReceipts"`
+	Topic            string `json:"Topic"`
+	// 自分がルームの通知を止めているかどうか
+	IsMuted bool `json:"IsMuted"`
 }
 
 func (s *RoomDtoStruct) contains(members []string, target string) bool {
@@ -35,14 +41,19 @@ func (s *RoomDtoStruct) contains(members []string, target string) bool {
 
 func (d *RoomDtoStruct) GetRoomInfo(room model.Room, userId string) RoomListResponse {
 	return RoomListResponse{
-		ID:          room.ID.Hex(),
-		Name:        room.Name,
-		OwnerID:     room.OwnerID,
-		IsPrivate:   room.IsPrivate,
-		IsMember:    d.contains(room.Members, userId),
-		IsOwner:     room.OwnerID == userId,
-		MemberCount: len(room.Members),
-		CreatedAt:   room.CreatedAt.String(),
+		ID:               room.ID.Hex(),
+		Name:             room.Name,
+		OwnerID:          room.OwnerID,
+		IsPrivate:        room.IsPrivate,
+		IsMember:         d.contains(room.Members, userId),
+		IsOwner:          room.OwnerID == userId,
+		MemberCount:      len(room.Members),
+		CreatedAt:        room.CreatedAt.String(),
+		MessageTTL:       room.MessageTTL,
+		RetentionDays:    room.RetentionDays,
+		HideReadReceipts: room.HideReadReceipts,
+		Topic:            room.Topic,
+		IsMuted:          d.contains(room.MutedMembers, userId),
 	}
 }
 
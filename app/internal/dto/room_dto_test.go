@@ -13,12 +13,16 @@ func TestGetRoomInfo(t *testing.T) {
 	dto := NewRoomDtoStruct()
 
 	room := model.Room{
-		ID:        primitive.NewObjectID(),
-		Name:      "Test Room",
-		OwnerID:   "owner-uuid",
-		IsPrivate: true,
-		Members:   []string{"member-uuid-1", "member-uuid-2"},
-		CreatedAt: time.Now(),
+		ID:            primitive.NewObjectID(),
+		Name:          "Test Room",
+		OwnerID:       "owner-uuid",
+		IsPrivate:     true,
+		Members:       []string{"member-uuid-1", "member-uuid-2"},
+		CreatedAt:     time.Now(),
+		MessageTTL:    3600,
+		RetentionDays: 30,
+		Topic:         "release planning",
+		MutedMembers:  []string{"member-uuid-1"},
 	}
 
 	userId := "member-uuid-1"
@@ -32,6 +36,11 @@ func TestGetRoomInfo(t *testing.T) {
 	assert.False(t, response.IsOwner)
 	assert.Equal(t, len(room.Members), response.MemberCount)
 	assert.Equal(t, room.CreatedAt.String(), response.CreatedAt)
+	assert.Equal(t, 3600, response.MessageTTL)
+	assert.Equal(t, 30, response.RetentionDays)
+	assert.Equal(t, "release planning", response.Topic)
+	assert.True(t, response.IsMuted)
+	assert.False(t, dto.GetRoomInfo(room, "member-uuid-2").IsMuted)
 }
 
 func TestResponseRoomList(t *testing.T) {
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+)
+
+type ScheduledMessageDtoInterface interface {
+	GetScheduledMessageInfo(scheduled model.ScheduledMessage) ScheduledMessageResponse
+	ResponseScheduledMessageList(scheduled []model.ScheduledMessage) []ScheduledMessageResponse
+}
+
+type ScheduledMessageDtoStruct struct{}
+
+func NewScheduledMessageDtoStruct() *ScheduledMessageDtoStruct {
+	return &ScheduledMessageDtoStruct{}
+}
+
+// SendAt は編集時にそのまま送り返せるよう、リクエストと同じ RFC3339 形式で返す
+type ScheduledMessageResponse struct {
+	ID        string `json:"ID"`
+	RoomID    string `json:"RoomID"`
+	Message   string `json:"Message"`
+	SendAt    string `json:"SendAt"`
+	Status    string `json:"Status"`
+	CreatedAt string `json:"CreatedAt"`
+}
+
+func (d *ScheduledMessageDtoStruct) GetScheduledMessageInfo(scheduled model.ScheduledMessage) ScheduledMessageResponse {
+	return ScheduledMessageResponse{
+		ID:        scheduled.ID.Hex(),
+		RoomID:    scheduled.RoomID,
+		Message:   scheduled.Message,
+		SendAt:    scheduled.SendAt.Format(time.RFC3339),
+		Status:    scheduled.Status,
+		CreatedAt: scheduled.CreatedAt.String(),
+	}
+}
+
+func (d *ScheduledMessageDtoStruct) ResponseScheduledMessageList(scheduled []model.ScheduledMessage) []ScheduledMessageResponse {
+	responses := []ScheduledMessageResponse{}
+	for _, s := range scheduled {
+		responses = append(responses, d.GetScheduledMessageInfo(s))
+	}
+	return responses
+}
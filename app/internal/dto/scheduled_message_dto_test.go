@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetScheduledMessageInfo(t *testing.T) {
+	dto := NewScheduledMessageDtoStruct()
+
+	scheduled := model.ScheduledMessage{
+		ID:        primitive.NewObjectID(),
+		RoomID:    "room-uuid",
+		Sender:    "sender-uuid",
+		Message:   "Good morning!",
+		SendAt:    time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC),
+		Status:    "pending",
+		CreatedAt: time.Now(),
+	}
+
+	response := dto.GetScheduledMessageInfo(scheduled)
+
+	assert.Equal(t, ScheduledMessageResponse{
+		ID:        scheduled.ID.Hex(),
+		RoomID:    "room-uuid",
+		Message:   "Good morning!",
+		SendAt:    "2025-01-02T09:00:00Z",
+		Status:    "pending",
+		CreatedAt: scheduled.CreatedAt.String(),
+	}, response)
+}
+
+func TestResponseScheduledMessageList(t *testing.T) {
+	dto := NewScheduledMessageDtoStruct()
+
+	responses := dto.ResponseScheduledMessageList([]model.ScheduledMessage{
+		{ID: primitive.NewObjectID(), Message: "first"},
+		{ID: primitive.NewObjectID(), Message: "second"},
+	})
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "first", responses[0].Message)
+	assert.Equal(t, "second", responses[1].Message)
+
+	// 予約がない場合も null ではなく空の配列を返す
+	empty := dto.ResponseScheduledMessageList(nil)
+	assert.NotNil(t, empty)
+	assert.Empty(t, empty)
+}
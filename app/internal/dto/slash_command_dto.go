@@ -0,0 +1,80 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+)
+
+type SlashCommandDtoInterface interface {
+	GetSlashCommandInfo(command model.SlashCommand) SlashCommandResponse
+	ResponseSlashCommandList(commands []model.SlashCommand) []SlashCommandResponse
+	ResponseCommandCatalog(builtins []consts.SlashCommandBuiltin, commands []model.SlashCommand) []CommandCatalogResponse
+}
+
+type SlashCommandDtoStruct struct{}
+
+func NewSlashCommandDtoStruct() *SlashCommandDtoStruct {
+	return &SlashCommandDtoStruct{}
+}
+
+// 署名の鍵は登録時のレスポンスでだけ返すので、ここには含めない
+type SlashCommandResponse struct {
+	ID          string `json:"ID"`
+	BotID       string `json:"BotID"`
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+	URL         string `json:"URL"`
+	CreatedAt   string `json:"CreatedAt"`
+}
+
+// 入力欄で補完に使う、実行できるコマンドの一覧
+// 組み込みのコマンドは BotID が空になる
+type CommandCatalogResponse struct {
+	Name        string `json:"Name"`
+	Usage       string `json:"Usage"`
+	Description string `json:"Description"`
+	Builtin     bool   `json:"Builtin"`
+	BotID       string `json:"BotID"`
+}
+
+func (d *SlashCommandDtoStruct) GetSlashCommandInfo(command model.SlashCommand) SlashCommandResponse {
+	return SlashCommandResponse{
+		ID:          command.ID.Hex(),
+		BotID:       command.BotID,
+		Name:        command.Name,
+		Description: command.Description,
+		URL:         command.URL,
+		CreatedAt:   command.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (d *SlashCommandDtoStruct) ResponseSlashCommandList(commands []model.SlashCommand) []SlashCommandResponse {
+	responses := []SlashCommandResponse{}
+	for _, command := range commands {
+		responses = append(responses, d.GetSlashCommandInfo(command))
+	}
+	return responses
+}
+
+func (d *SlashCommandDtoStruct) ResponseCommandCatalog(builtins []consts.SlashCommandBuiltin, commands []model.SlashCommand) []CommandCatalogResponse {
+	responses := []CommandCatalogResponse{}
+	for _, builtin := range builtins {
+		responses = append(responses, CommandCatalogResponse{
+			Name:        builtin.Name,
+			Usage:       builtin.Usage,
+			Description: builtin.Description,
+			Builtin:     true,
+		})
+	}
+	for _, command := range commands {
+		responses = append(responses, CommandCatalogResponse{
+			Name:        command.Name,
+			Usage:       "/" + command.Name + " [text]",
+			Description: command.Description,
+			BotID:       command.BotID,
+		})
+	}
+	return responses
+}
@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetSlashCommandInfo(t *testing.T) {
+	dto := NewSlashCommandDtoStruct()
+
+	command := model.SlashCommand{
+		ID:          primitive.NewObjectID(),
+		BotID:       "bot-id",
+		Name:        "deploy",
+		Description: "Deploy the app",
+		URL:         "https://example.com/deploy",
+		Secret:      "command-secret",
+		CreatedAt:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, SlashCommandResponse{
+		ID:          command.ID.Hex(),
+		BotID:       "bot-id",
+		Name:        "deploy",
+		Description: "Deploy the app",
+		URL:         "https://example.com/deploy",
+		CreatedAt:   "2025-01-01T00:00:00Z",
+	}, dto.GetSlashCommandInfo(command))
+
+	body, err := json.Marshal(dto.GetSlashCommandInfo(command))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "command-secret")
+}
+
+func TestResponseSlashCommandList(t *testing.T) {
+	dto := NewSlashCommandDtoStruct()
+
+	assert.Equal(t, []SlashCommandResponse{}, dto.ResponseSlashCommandList(nil))
+
+	responses := dto.ResponseSlashCommandList([]model.SlashCommand{
+		{ID: primitive.NewObjectID(), Name: "deploy"},
+		{ID: primitive.NewObjectID(), Name: "rollback"},
+	})
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "rollback", responses[1].Name)
+}
+
+func TestResponseCommandCatalog(t *testing.T) {
+	dto := NewSlashCommandDtoStruct()
+
+	responses := dto.ResponseCommandCatalog(
+		[]consts.SlashCommandBuiltin{{Name: "me", Usage: "/me <text>", Description: "Describe what you are doing"}},
+		[]model.SlashCommand{{ID: primitive.NewObjectID(), BotID: "bot-id", Name: "deploy", Description: "Deploy the app"}},
+	)
+	assert.Equal(t, []CommandCatalogResponse{
+		{Name: "me", Usage: "/me <text>", Description: "Describe what you are doing", Builtin: true},
+		{Name: "deploy", Usage: "/deploy [text]", Description: "Deploy the app", BotID: "bot-id"},
+	}, responses)
+
+	assert.Equal(t, []CommandCatalogResponse{}, dto.ResponseCommandCatalog(nil, nil))
+}
@@ -0,0 +1,105 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+)
+
+type WebhookDtoInterface interface {
+	GetWebhookInfo(webhook model.Webhook) WebhookResponse
+	ResponseWebhookList(webhooks []model.Webhook) []WebhookResponse
+	ResponseDeliveryList(deliveries []model.WebhookDelivery) []WebhookDeliveryResponse
+}
+
+type WebhookDtoStruct struct{}
+
+func NewWebhookDtoStruct() *WebhookDtoStruct {
+	return &WebhookDtoStruct{}
+}
+
+// 署名の鍵は登録時のレスポンスでだけ返すので、ここには含めない
+type WebhookResponse struct {
+	ID        string   `json:"ID"`
+	RoomID    string   `json:"RoomID"`
+	URL       string   `json:"URL"`
+	Events    []string `json:"Events"`
+	CreatedBy string   `json:"CreatedBy"`
+	CreatedAt string   `json:"CreatedAt"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID          string                   `json:"ID"`
+	Event       string                   `json:"Event"`
+	Payload     json.RawMessage          `json:"Payload"`
+	Status      string                   `json:"Status"`
+	Attempts    int                      `json:"Attempts"`
+	NextRetryAt string                   `json:"NextRetryAt,omitempty"`
+	LastError   string                   `json:"LastError,omitempty"`
+	History     []WebhookAttemptResponse `json:"History"`
+	CreatedAt   string                   `json:"CreatedAt"`
+}
+
+type WebhookAttemptResponse struct {
+	AttemptedAt string `json:"AttemptedAt"`
+	StatusCode  int    `json:"StatusCode"`
+	Error       string `json:"Error,omitempty"`
+	DurationMs  int64  `json:"DurationMs"`
+}
+
+func (d *WebhookDtoStruct) GetWebhookInfo(webhook model.Webhook) WebhookResponse {
+	events := webhook.Events
+	if events == nil {
+		events = []string{}
+	}
+	return WebhookResponse{
+		ID:        webhook.ID.Hex(),
+		RoomID:    webhook.RoomID,
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func (d *WebhookDtoStruct) ResponseWebhookList(webhooks []model.Webhook) []WebhookResponse {
+	responses := []WebhookResponse{}
+	for _, webhook := range webhooks {
+		responses = append(responses, d.GetWebhookInfo(webhook))
+	}
+	return responses
+}
+
+// 再送待ちの配信にだけ、次に送信する時刻を付ける
+func (d *WebhookDtoStruct) ResponseDeliveryList(deliveries []model.WebhookDelivery) []WebhookDeliveryResponse {
+	responses := []WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		history := []WebhookAttemptResponse{}
+		for _, attempt := range delivery.History {
+			history = append(history, WebhookAttemptResponse{
+				AttemptedAt: attempt.AttemptedAt.Format(time.RFC3339),
+				StatusCode:  attempt.StatusCode,
+				Error:       attempt.Error,
+				DurationMs:  attempt.DurationMs,
+			})
+		}
+
+		response := WebhookDeliveryResponse{
+			ID:        delivery.ID.Hex(),
+			Event:     delivery.Event,
+			Payload:   json.RawMessage(delivery.Payload),
+			Status:    delivery.Status,
+			Attempts:  delivery.Attempts,
+			LastError: delivery.LastError,
+			History:   history,
+			CreatedAt: delivery.CreatedAt.Format(time.RFC3339),
+		}
+		if delivery.Status == consts.WebhookDeliveryStatus.Pending && delivery.Attempts > 0 {
+			response.NextRetryAt = delivery.AvailableAt.Format(time.RFC3339)
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
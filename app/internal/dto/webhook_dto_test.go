@@ -0,0 +1,125 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetWebhookInfo(t *testing.T) {
+	dto := NewWebhookDtoStruct()
+
+	webhook := model.Webhook{
+		ID:        primitive.NewObjectID(),
+		RoomID:    "room-uuid",
+		URL:       "https://example.com/hook",
+		Events:    []string{"message.created"},
+		Secret:    "secret",
+		CreatedBy: "admin-uuid",
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	assert.Equal(t, WebhookResponse{
+		ID:        webhook.ID.Hex(),
+		RoomID:    "room-uuid",
+		URL:       "https://example.com/hook",
+		Events:    []string{"message.created"},
+		CreatedBy: "admin-uuid",
+		CreatedAt: "2025-01-01T00:00:00Z",
+	}, dto.GetWebhookInfo(webhook))
+
+	body, err := json.Marshal(dto.GetWebhookInfo(webhook))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(body), "secret")
+}
+
+func TestResponseWebhookList(t *testing.T) {
+	dto := NewWebhookDtoStruct()
+
+	assert.Equal(t, []WebhookResponse{}, dto.ResponseWebhookList(nil))
+
+	responses := dto.ResponseWebhookList([]model.Webhook{
+		{ID: primitive.NewObjectID(), URL: "https://example.com/1"},
+		{ID: primitive.NewObjectID(), URL: "https://example.com/2"},
+	})
+	assert.Len(t, responses, 2)
+	assert.Equal(t, "https://example.com/2", responses[1].URL)
+	assert.Equal(t, []string{}, responses[0].Events)
+}
+
+func TestResponseDeliveryList(t *testing.T) {
+	dto := NewWebhookDtoStruct()
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	done := model.WebhookDelivery{
+		ID:       primitive.NewObjectID(),
+		Event:    "message.created",
+		Payload:  `{"event":"message.created"}`,
+		Status:   "done",
+		Attempts: 1,
+		History: []model.WebhookAttempt{
+			{AttemptedAt: createdAt, StatusCode: 200, DurationMs: 12},
+		},
+		CreatedAt: createdAt,
+	}
+	retrying := model.WebhookDelivery{
+		ID:          primitive.NewObjectID(),
+		Event:       "member.joined",
+		Payload:     `{}`,
+		Status:      "pending",
+		Attempts:    1,
+		AvailableAt: createdAt.Add(30 * time.Second),
+		LastError:   "unexpected status code: 500",
+		History: []model.WebhookAttempt{
+			{AttemptedAt: createdAt, StatusCode: 500, Error: "unexpected status code: 500", DurationMs: 5},
+		},
+		CreatedAt: createdAt,
+	}
+	waiting := model.WebhookDelivery{
+		ID:          primitive.NewObjectID(),
+		Event:       "member.left",
+		Payload:     `{}`,
+		Status:      "pending",
+		AvailableAt: createdAt,
+		CreatedAt:   createdAt,
+	}
+
+	assert.Equal(t, []WebhookDeliveryResponse{
+		{
+			ID:       done.ID.Hex(),
+			Event:    "message.created",
+			Payload:  json.RawMessage(`{"event":"message.created"}`),
+			Status:   "done",
+			Attempts: 1,
+			History: []WebhookAttemptResponse{
+				{AttemptedAt: "2025-01-01T00:00:00Z", StatusCode: 200, DurationMs: 12},
+			},
+			CreatedAt: "2025-01-01T00:00:00Z",
+		},
+		{
+			ID:          retrying.ID.Hex(),
+			Event:       "member.joined",
+			Payload:     json.RawMessage(`{}`),
+			Status:      "pending",
+			Attempts:    1,
+			NextRetryAt: "2025-01-01T00:00:30Z",
+			LastError:   "unexpected status code: 500",
+			History: []WebhookAttemptResponse{
+				{AttemptedAt: "2025-01-01T00:00:00Z", StatusCode: 500, Error: "unexpected status code: 500", DurationMs: 5},
+			},
+			CreatedAt: "2025-01-01T00:00:00Z",
+		},
+		{
+			ID:        waiting.ID.Hex(),
+			Event:     "member.left",
+			Payload:   json.RawMessage(`{}`),
+			Status:    "pending",
+			History:   []WebhookAttemptResponse{},
+			CreatedAt: "2025-01-01T00:00:00Z",
+		},
+	}, dto.ResponseDeliveryList([]model.WebhookDelivery{done, retrying, waiting}))
+}
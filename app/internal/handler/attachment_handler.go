@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AttachmentHandlerInterface interface {
+	Upload(c echo.Context) error
+	Download(c echo.Context) error
+	Thumbnail(c echo.Context) error
+}
+
+type AttachmentHandler struct {
+	BaseHandler
+	messageSvc    mongo_svc.MessageSvcInterface
+	sendSvc       service.MessageSvcInterface
+	attachmentSvc service.AttachmentSvcInterface
+	thumbnailSvc  service.ThumbnailSvcInterface
+}
+
+func NewAttachmentHandler(
+	messageSvc mongo_svc.MessageSvcInterface,
+	sendSvc service.MessageSvcInterface,
+	attachmentSvc service.AttachmentSvcInterface,
+	thumbnailSvc service.ThumbnailSvcInterface,
+) *AttachmentHandler {
+	return &AttachmentHandler{
+		messageSvc:    messageSvc,
+		sendSvc:       sendSvc,
+		attachmentSvc: attachmentSvc,
+		thumbnailSvc:  thumbnailSvc,
+	}
+}
+
+// multipart/form-data で files（複数可）と任意の message, client_msg_id, ttl を受け取り、添付付きのメッセージを送信する
+func (h *AttachmentHandler) Upload(c echo.Context) error {
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.JSON(400, echo.Map{
+			"error": "invalid multipart form",
+		})
+	}
+	files := form.File["files"]
+	if len(files) == 0 || len(files) > consts.AttachmentMaxFiles {
+		return c.JSON(400, echo.Map{
+			"error": fmt.Sprintf("between 1 and %d files are required", consts.AttachmentMaxFiles),
+		})
+	}
+	clientMsgID := c.FormValue("client_msg_id")
+	if len(clientMsgID) > 64 {
+		return c.JSON(400, echo.Map{
+			"error": "client_msg_id is too long",
+		})
+	}
+	ttl := 0
+	if raw := c.FormValue("ttl"); raw != "" {
+		ttl, err = strconv.Atoi(raw)
+		if err != nil || !service.ValidMessageTTL(ttl) {
+			return c.JSON(400, echo.Map{
+				"error": fmt.Sprintf("ttl must be between %d and %d seconds", consts.MessageTTLMinSeconds, consts.MessageTTLMaxSeconds),
+			})
+		}
+	}
+
+	roomID := c.Param("room_id")
+	uuid := h.GetUuid(c)
+	reqCtx := c.Request().Context()
+
+	// 再送で既存のメッセージが返った場合に、今回アップロードした分を判別できるよう先に採番する
+	now := time.Now()
+	message := model.Message{
+		ID:            primitive.NewObjectID(),
+		RoomID:        roomID,
+		Sender:        uuid,
+		Message:       c.FormValue("message"),
+		CreatedAt:     now,
+		IsReadUserIds: []string{uuid},
+		ClientMsgID:   clientMsgID,
+		ExpiresAt:     service.MessageExpiresAt(ttl, h.GetRoomModel(c), now),
+		Bot:           h.messageBot(c),
+	}
+
+	for _, file := range files {
+		attachment, err := h.attachmentSvc.Upload(reqCtx, roomID, file)
+		if err != nil {
+			h.attachmentSvc.Remove(reqCtx, message.Attachments)
+			return h.uploadError(c, err)
+		}
+		message.Attachments = append(message.Attachments, attachment)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	messageId, err := h.sendSvc.Send(message, ctx)
+	if err != nil {
+		h.attachmentSvc.Remove(reqCtx, message.Attachments)
+		if errors.Is(err, service.ErrSpamRejected) {
+			return c.JSON(422, echo.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if messageId != message.ID.Hex() {
+		h.attachmentSvc.Remove(reqCtx, message.Attachments)
+	} else if err := h.thumbnailSvc.Enqueue(message, ctx); err != nil {
+		// サムネイルがなくても元の画像は表示できるので、送信は成功とする
+		fmt.Println("Failed to enqueue thumbnail jobs:", err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"message_id": messageId,
+	})
+}
+
+func (h *AttachmentHandler) uploadError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrAttachmentTooLarge):
+		return c.JSON(413, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrAttachmentTypeNotAllowed):
+		return c.JSON(415, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(500, echo.Map{
+		"error": err.Error(),
+	})
+}
+
+func (h *AttachmentHandler) Download(c echo.Context) error {
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	attachment, ok := h.findAttachment(c)
+	if !ok {
+		return c.JSON(404, echo.Map{
+			"error": "attachment not found",
+		})
+	}
+
+	// 画像はそのまま表示し、それ以外はダウンロードさせる
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") {
+		disposition = "inline"
+	}
+	return h.stream(c, attachment, disposition)
+}
+
+// 生成前・生成に失敗した場合は 404 を返すので、クライアントは元の画像を表示する
+func (h *AttachmentHandler) Thumbnail(c echo.Context) error {
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	attachment, ok := h.findAttachment(c)
+	if !ok {
+		return c.JSON(404, echo.Map{
+			"error": "attachment not found",
+		})
+	}
+
+	for _, thumbnail := range attachment.Thumbnails {
+		if thumbnail.Size != c.Param("size") {
+			continue
+		}
+		return h.stream(c, model.Attachment{
+			Name:        attachment.Name,
+			ContentType: thumbnail.ContentType,
+			Size:        thumbnail.ByteSize,
+			StorageKey:  thumbnail.StorageKey,
+		}, "inline")
+	}
+
+	return c.JSON(404, echo.Map{
+		"error": "thumbnail not found",
+	})
+}
+
+func (h *AttachmentHandler) findAttachment(c echo.Context) (model.Attachment, bool) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	message, err := h.messageSvc.GetMessage(c.Param("message_id"), c.Param("room_id"), ctx)
+	// 削除されたメッセージの添付ファイルはメンバーに返さない
+	if err != nil || message.DeletedAt != nil {
+		return model.Attachment{}, false
+	}
+
+	for _, attachment := range message.Attachments {
+		if attachment.ID == c.Param("attachment_id") {
+			return attachment, true
+		}
+	}
+	return model.Attachment{}, false
+}
+
+func (h *AttachmentHandler) stream(c echo.Context, attachment model.Attachment, disposition string) error {
+	reader, err := h.attachmentSvc.Open(c.Request().Context(), attachment)
+	if errors.Is(err, usecase.ErrStorageObjectNotFound) {
+		return c.JSON(404, echo.Map{
+			"error": "attachment not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	defer reader.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Name}))
+	header.Set(echo.HeaderContentLength, strconv.FormatInt(attachment.Size, 10))
+	header.Set(echo.HeaderXContentTypeOptions, "nosniff")
+
+	return c.Stream(200, attachment.ContentType, reader)
+}
@@ -0,0 +1,470 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newUploadRequest(t *testing.T, fileCount int, fields map[string]string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for i := 0; i < fileCount; i++ {
+		part, err := writer.CreateFormFile("files", "photo.png")
+		assert.NoError(t, err)
+		_, err = part.Write([]byte("\x89PNG\r\n\x1a\n"))
+		assert.NoError(t, err)
+	}
+	for key, value := range fields {
+		assert.NoError(t, writer.WriteField(key, value))
+	}
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, "/message/:room_id/attachments", &body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestAttachmentUpload(t *testing.T) {
+	uploaded := model.Attachment{ID: "attachment-1", Name: "photo.png", ContentType: "image/png", Size: 8}
+
+	expected := map[string]struct {
+		isMember     bool
+		fileCount    int
+		fields       map[string]string
+		room         model.Room
+		uploadErr    error
+		sendCalled   bool
+		sendErr      error
+		retry        bool
+		enqueueErr   error
+		status       int
+		expectRemove bool
+		expectTTL    time.Duration
+	}{
+		"success": {
+			isMember:   true,
+			fileCount:  2,
+			fields:     map[string]string{"message": "look at this", "client_msg_id": "client-1"},
+			sendCalled: true,
+			status:     200,
+		},
+		"success with ttl": {
+			isMember:   true,
+			fileCount:  1,
+			fields:     map[string]string{"ttl": "60"},
+			sendCalled: true,
+			status:     200,
+			expectTTL:  time.Minute,
+		},
+		"success with room default ttl": {
+			isMember:   true,
+			fileCount:  1,
+			room:       model.Room{MessageTTL: 3600},
+			sendCalled: true,
+			status:     200,
+			expectTTL:  time.Hour,
+		},
+		"ttl out of range": {
+			isMember:  true,
+			fileCount: 1,
+			fields:    map[string]string{"ttl": "1"},
+			status:    400,
+		},
+		"ttl not a number": {
+			isMember:  true,
+			fileCount: 1,
+			fields:    map[string]string{"ttl": "soon"},
+			status:    400,
+		},
+		"thumbnail enqueue error does not fail the send": {
+			isMember:   true,
+			fileCount:  1,
+			sendCalled: true,
+			enqueueErr: assert.AnError,
+			status:     200,
+		},
+		"retry returns original message": {
+			isMember:     true,
+			fileCount:    1,
+			fields:       map[string]string{"client_msg_id": "client-1"},
+			sendCalled:   true,
+			retry:        true,
+			status:       200,
+			expectRemove: true,
+		},
+		"forbidden (not a member)": {
+			isMember:  false,
+			fileCount: 1,
+			status:    403,
+		},
+		"no files": {
+			isMember:  true,
+			fileCount: 0,
+			status:    400,
+		},
+		"too many files": {
+			isMember:  true,
+			fileCount: 6,
+			status:    400,
+		},
+		"client_msg_id too long": {
+			isMember:  true,
+			fileCount: 1,
+			fields:    map[string]string{"client_msg_id": strings.Repeat("a", 65)},
+			status:    400,
+		},
+		"too large": {
+			isMember:     true,
+			fileCount:    1,
+			uploadErr:    service.ErrAttachmentTooLarge,
+			status:       413,
+			expectRemove: true,
+		},
+		"type not allowed": {
+			isMember:     true,
+			fileCount:    1,
+			uploadErr:    service.ErrAttachmentTypeNotAllowed,
+			status:       415,
+			expectRemove: true,
+		},
+		"upload error": {
+			isMember:     true,
+			fileCount:    1,
+			uploadErr:    assert.AnError,
+			status:       500,
+			expectRemove: true,
+		},
+		"rejected as spam": {
+			isMember:     true,
+			fileCount:    1,
+			sendCalled:   true,
+			sendErr:      service.ErrSpamRejected,
+			status:       422,
+			expectRemove: true,
+		},
+		"send error": {
+			isMember:     true,
+			fileCount:    1,
+			sendCalled:   true,
+			sendErr:      assert.AnError,
+			status:       500,
+			expectRemove: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(newUploadRequest(t, tt.fileCount, tt.fields), rec)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", tt.isMember)
+			c.Set("room_model", tt.room)
+
+			attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+			attachmentSvcMock.On("Upload", mock.Anything, "test-room-id", mock.Anything).Return(uploaded, tt.uploadErr)
+			attachmentSvcMock.On("Remove", mock.Anything, mock.Anything).Return()
+
+			// 送信されたメッセージのIDをそのまま返す（再送の場合は既存のIDを返す）
+			var sent model.Message
+			sendSvcMock := new(svc_mock.MessageSvcMock)
+			sendCall := sendSvcMock.On("Send", mock.AnythingOfType("model.Message"), mock.Anything)
+			sendCall.Run(func(args mock.Arguments) {
+				sent = args.Get(0).(model.Message)
+				messageID := sent.ID.Hex()
+				if tt.retry {
+					messageID = "original-message-id"
+				}
+				sendCall.Return(messageID, tt.sendErr)
+			})
+
+			thumbnailSvcMock := new(svc_mock.ThumbnailSvcMock)
+			thumbnailSvcMock.On("Enqueue", mock.AnythingOfType("model.Message"), mock.Anything).Return(tt.enqueueErr)
+
+			handler := NewAttachmentHandler(new(mongo_svc_mock.MessageSvcMock), sendSvcMock, attachmentSvcMock, thumbnailSvcMock)
+			err := handler.Upload(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+
+			if tt.sendCalled {
+				sendSvcMock.AssertNumberOfCalls(t, "Send", 1)
+				assert.Equal(t, "test-room-id", sent.RoomID)
+				assert.Equal(t, "test-uuid-1234", sent.Sender)
+				assert.Equal(t, tt.fields["message"], sent.Message)
+				assert.Equal(t, tt.fields["client_msg_id"], sent.ClientMsgID)
+				assert.Len(t, sent.Attachments, tt.fileCount)
+				if tt.expectTTL > 0 {
+					assert.Equal(t, sent.CreatedAt.Add(tt.expectTTL), *sent.ExpiresAt)
+				} else {
+					assert.Nil(t, sent.ExpiresAt)
+				}
+			} else {
+				sendSvcMock.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+			}
+			if tt.expectRemove {
+				attachmentSvcMock.AssertNumberOfCalls(t, "Remove", 1)
+			} else {
+				attachmentSvcMock.AssertNotCalled(t, "Remove", mock.Anything, mock.Anything)
+			}
+			// サムネイルは新しく保存したメッセージについてだけ作る
+			if tt.status == http.StatusOK && !tt.retry {
+				thumbnailSvcMock.AssertCalled(t, "Enqueue", sent, mock.Anything)
+			} else {
+				thumbnailSvcMock.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+			}
+
+			if tt.status != http.StatusOK {
+				return
+			}
+			result := map[string]string{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			if tt.retry {
+				assert.Equal(t, "original-message-id", result["message_id"])
+			} else {
+				assert.Equal(t, sent.ID.Hex(), result["message_id"])
+			}
+		})
+	}
+}
+
+func TestAttachmentDownload(t *testing.T) {
+	messageID := primitive.NewObjectID()
+	message := model.Message{
+		ID:     messageID,
+		RoomID: "test-room-id",
+		Attachments: []model.Attachment{
+			{ID: "image-1", Name: "photo.png", ContentType: "image/png", Size: 5, StorageKey: "attachments/test-room-id/image-1"},
+			{ID: "pdf-1", Name: "資料.pdf", ContentType: "application/pdf", Size: 5, StorageKey: "attachments/test-room-id/pdf-1"},
+		},
+	}
+
+	expected := map[string]struct {
+		isMember          bool
+		deleted           bool
+		attachmentID      string
+		getMessageErr     error
+		openErr           error
+		status            int
+		expectType        string
+		expectDisposition string
+	}{
+		"image is shown inline": {
+			isMember:          true,
+			attachmentID:      "image-1",
+			status:            200,
+			expectType:        "image/png",
+			expectDisposition: `inline; filename=photo.png`,
+		},
+		"other files are downloaded": {
+			isMember:          true,
+			attachmentID:      "pdf-1",
+			status:            200,
+			expectType:        "application/pdf",
+			expectDisposition: `attachment; filename*=utf-8''%E8%B3%87%E6%96%99.pdf`,
+		},
+		"forbidden (not a member)": {
+			isMember:     false,
+			attachmentID: "image-1",
+			status:       403,
+		},
+		"message not found": {
+			isMember:      true,
+			attachmentID:  "image-1",
+			getMessageErr: assert.AnError,
+			status:        404,
+		},
+		"attachment not found": {
+			isMember:     true,
+			attachmentID: "unknown",
+			status:       404,
+		},
+		"message deleted": {
+			isMember:     true,
+			deleted:      true,
+			attachmentID: "image-1",
+			status:       404,
+		},
+		"file missing in storage": {
+			isMember:     true,
+			attachmentID: "image-1",
+			openErr:      usecase.ErrStorageObjectNotFound,
+			status:       404,
+		},
+		"storage error": {
+			isMember:     true,
+			attachmentID: "image-1",
+			openErr:      assert.AnError,
+			status:       500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/message/:room_id/:message_id/attachments/:attachment_id", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id", "message_id", "attachment_id")
+			c.SetParamValues("test-room-id", messageID.Hex(), tt.attachmentID)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", tt.isMember)
+
+			message := message
+			if tt.deleted {
+				deletedAt := time.Now()
+				message.DeletedAt = &deletedAt
+			}
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetMessage", messageID.Hex(), "test-room-id", mock.Anything).Return(message, tt.getMessageErr)
+
+			attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+			var reader io.ReadCloser
+			if tt.openErr == nil {
+				reader = io.NopCloser(strings.NewReader("bytes"))
+			}
+			attachmentSvcMock.On("Open", mock.Anything, mock.Anything).Return(reader, tt.openErr)
+
+			handler := NewAttachmentHandler(messageSvcMock, new(svc_mock.MessageSvcMock), attachmentSvcMock, new(svc_mock.ThumbnailSvcMock))
+			err := handler.Download(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if !tt.isMember {
+				messageSvcMock.AssertNotCalled(t, "GetMessage", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, "bytes", rec.Body.String())
+			assert.Equal(t, tt.expectType, rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, tt.expectDisposition, rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+		})
+	}
+}
+
+func TestAttachmentThumbnail(t *testing.T) {
+	messageID := primitive.NewObjectID()
+	message := model.Message{
+		ID:     messageID,
+		RoomID: "test-room-id",
+		Attachments: []model.Attachment{
+			{
+				ID: "image-1", Name: "photo.jpg", ContentType: "image/jpeg", Size: 100, StorageKey: "attachments/test-room-id/image-1",
+				Thumbnails: []model.AttachmentThumbnail{
+					{Size: "small", Width: 160, Height: 120, ContentType: "image/jpeg", ByteSize: 5, StorageKey: "attachments/test-room-id/image-1_small"},
+				},
+			},
+			{ID: "image-2", Name: "new.png", ContentType: "image/png", Size: 100, StorageKey: "attachments/test-room-id/image-2"},
+		},
+	}
+
+	expected := map[string]struct {
+		isMember      bool
+		attachmentID  string
+		size          string
+		getMessageErr error
+		openErr       error
+		status        int
+	}{
+		"success": {
+			isMember:     true,
+			attachmentID: "image-1",
+			size:         "small",
+			status:       200,
+		},
+		"forbidden (not a member)": {
+			isMember:     false,
+			attachmentID: "image-1",
+			size:         "small",
+			status:       403,
+		},
+		"message not found": {
+			isMember:      true,
+			attachmentID:  "image-1",
+			size:          "small",
+			getMessageErr: assert.AnError,
+			status:        404,
+		},
+		"unknown size": {
+			isMember:     true,
+			attachmentID: "image-1",
+			size:         "huge",
+			status:       404,
+		},
+		"not generated yet": {
+			isMember:     true,
+			attachmentID: "image-2",
+			size:         "small",
+			status:       404,
+		},
+		"file missing in storage": {
+			isMember:     true,
+			attachmentID: "image-1",
+			size:         "small",
+			openErr:      usecase.ErrStorageObjectNotFound,
+			status:       404,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/message/:room_id/:message_id/attachments/:attachment_id/thumbnails/:size", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id", "message_id", "attachment_id", "size")
+			c.SetParamValues("test-room-id", messageID.Hex(), tt.attachmentID, tt.size)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", tt.isMember)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetMessage", messageID.Hex(), "test-room-id", mock.Anything).Return(message, tt.getMessageErr)
+
+			attachmentSvcMock := new(svc_mock.AttachmentSvcMock)
+			var reader io.ReadCloser
+			if tt.openErr == nil {
+				reader = io.NopCloser(strings.NewReader("thumb"))
+			}
+			attachmentSvcMock.On("Open", mock.Anything, mock.Anything).Return(reader, tt.openErr)
+
+			handler := NewAttachmentHandler(messageSvcMock, new(svc_mock.MessageSvcMock), attachmentSvcMock, new(svc_mock.ThumbnailSvcMock))
+			err := handler.Thumbnail(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			attachmentSvcMock.AssertCalled(t, "Open", mock.Anything, mock.MatchedBy(func(attachment model.Attachment) bool {
+				return attachment.StorageKey == "attachments/test-room-id/image-1_small"
+			}))
+			assert.Equal(t, "thumb", rec.Body.String())
+			assert.Equal(t, "image/jpeg", rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, "5", rec.Header().Get(echo.HeaderContentLength))
+			assert.Equal(t, `inline; filename=photo.jpg`, rec.Header().Get(echo.HeaderContentDisposition))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
 	"github.com/labstack/echo/v4"
 )
 
@@ -30,6 +31,21 @@ func (h *BaseHandler) IsMember(c echo.Context) bool {
 	return isMember
 }
 
+// ボットの API キーで認証したリクエストの場合は、そのボットを返す
+func (h *BaseHandler) GetBot(c echo.Context) (model.Bot, bool) {
+	bot, ok := c.Get(consts.ContextKeys.Bot).(model.Bot)
+	return bot, ok
+}
+
+// 送信するメッセージに付ける送信元の情報。ユーザーが送信する場合は nil
+func (h *BaseHandler) messageBot(c echo.Context) *model.MessageBot {
+	bot, ok := h.GetBot(c)
+	if !ok {
+		return nil
+	}
+	return service.BotMessageSender(bot)
+}
+
 func (h *BaseHandler) validateRequest(c echo.Context, req interface{}) error {
 	// JSON or Form の自動バインド
 	if err := c.Bind(req); err != nil {
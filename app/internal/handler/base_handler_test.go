@@ -45,6 +45,26 @@ func TestGetEmail(t *testing.T) {
 	assert.Equal(t, expectedEmail, email)
 }
 
+func TestGetBot(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	handler := &BaseHandler{}
+	_, ok := handler.GetBot(c)
+	assert.False(t, ok)
+	assert.Nil(t, handler.messageBot(c))
+
+	bot := model.Bot{ID: primitive.NewObjectID(), Name: "Deploy Bot"}
+	c.Set(consts.ContextKeys.Bot, bot)
+
+	gotBot, ok := handler.GetBot(c)
+	assert.True(t, ok)
+	assert.Equal(t, bot, gotBot)
+	assert.Equal(t, &model.MessageBot{Kind: consts.MessageBotKinds.Bot, ID: bot.ID.Hex(), Name: "Deploy Bot"}, handler.messageBot(c))
+}
+
 func TestGetRoomModel(t *testing.T) {
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/", nil)
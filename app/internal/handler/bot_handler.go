@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type BotHandlerInterface interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Delete(c echo.Context) error
+	APIKeys(c echo.Context) error
+	IssueAPIKey(c echo.Context) error
+	RevokeAPIKey(c echo.Context) error
+}
+
+type BotHandler struct {
+	BaseHandler
+	mongoBotSvc mongo_svc.BotSvcInterface
+	apiKeySvc   mongo_svc.BotAPIKeySvcInterface
+	botSvc      service.BotSvcInterface
+	clock       atylabclock.ClockInterface
+	dto         dto.BotDtoInterface
+}
+
+func NewBotHandler(
+	mongoBotSvc mongo_svc.BotSvcInterface,
+	apiKeySvc mongo_svc.BotAPIKeySvcInterface,
+	botSvc service.BotSvcInterface,
+	clock atylabclock.ClockInterface,
+	dto dto.BotDtoInterface,
+) *BotHandler {
+	return &BotHandler{
+		mongoBotSvc: mongoBotSvc,
+		apiKeySvc:   apiKeySvc,
+		botSvc:      botSvc,
+		clock:       clock,
+		dto:         dto,
+	}
+}
+
+// 自分が所有するボットを返す
+func (h *BotHandler) List(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bots, err := h.mongoBotSvc.GetBots(h.GetUuid(c), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"bots": h.dto.ResponseBotList(bots),
+	})
+}
+
+type CreateBotRequest struct {
+	Name    string `json:"name" form:"name" validate:"required,max=80"`
+	IconURL string `json:"icon_url" form:"icon_url" validate:"omitempty,url,startswith=http,max=2048"`
+}
+
+func (h *BotHandler) Create(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	var req CreateBotRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bot, err := h.botSvc.Create(model.Bot{
+		OwnerID: h.GetUuid(c),
+		Name:    strings.TrimSpace(req.Name),
+		IconURL: req.IconURL,
+	}, ctx)
+	switch {
+	case errors.Is(err, service.ErrBotLimitReached):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"bot": h.dto.GetBotInfo(bot),
+	})
+}
+
+// ボットを削除し、API キーをすべて失効させる
+func (h *BotHandler) Delete(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	deleted, err := h.botSvc.Delete(c.Param("bot_id"), h.GetUuid(c), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if !deleted {
+		return c.JSON(404, echo.Map{
+			"error": "bot not found",
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// 失効させたものも含めて API キーの一覧を返す
+func (h *BotHandler) APIKeys(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bot, ok := h.ownBot(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	keys, err := h.apiKeySvc.GetAPIKeys(bot.ID.Hex(), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"api_keys": h.dto.ResponseAPIKeyList(keys),
+	})
+}
+
+type IssueBotAPIKeyRequest struct {
+	Name     string   `json:"name" form:"name" validate:"required,max=80"`
+	ReadOnly bool     `json:"read_only" form:"read_only"`
+	RoomIDs  []string `json:"room_ids" form:"room_ids" validate:"max=50,dive,required"`
+}
+
+// API キーはこのレスポンスでだけ返す
+func (h *BotHandler) IssueAPIKey(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	var req IssueBotAPIKeyRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bot, ok := h.ownBot(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	key, apiKey, err := h.botSvc.IssueAPIKey(model.BotAPIKey{
+		BotID: bot.ID.Hex(),
+		Name:  strings.TrimSpace(req.Name),
+		Scope: model.BotAPIKeyScope{
+			ReadOnly: req.ReadOnly,
+			RoomIDs:  uniqueStrings(req.RoomIDs),
+		},
+	}, ctx)
+	switch {
+	case errors.Is(err, service.ErrBotAPIKeyLimitReached):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"api_key_info": h.dto.GetAPIKeyInfo(key),
+		"api_key":      apiKey,
+	})
+}
+
+func (h *BotHandler) RevokeAPIKey(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bot, ok := h.ownBot(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	revoked, err := h.apiKeySvc.RevokeAPIKey(c.Param("key_id"), bot.ID.Hex(), h.clock.Now(), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if !revoked {
+		return c.JSON(404, echo.Map{
+			"error": "api key not found",
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// 他のユーザーのボットは存在しないものとして扱う
+func (h *BotHandler) ownBot(c echo.Context, ctx *atylabmongo.MongoCtxSvc) (model.Bot, bool) {
+	bot, err := h.mongoBotSvc.GetBot(c.Param("bot_id"), ctx)
+	if err != nil || bot.OwnerID != h.GetUuid(c) {
+		return model.Bot{}, false
+	}
+	return bot, true
+}
+
+// ボットの管理は所有者のユーザーだけができ、API キーではできない
+func (h *BotHandler) forbidden(c echo.Context) error {
+	return c.JSON(403, echo.Map{
+		"error": "Bots cannot manage bots",
+	})
+}
+
+func (h *BotHandler) notFound(c echo.Context) error {
+	return c.JSON(404, echo.Map{
+		"error": "bot not found",
+	})
+}
@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// API キーで認証したリクエストでは、ボットをコンテキストに設定する
+func newBotContext(method string, path string, body string, asBot bool) (echo.Context, *httptest.ResponseRecorder) {
+	c, rec := newWebhookContext(method, path, body, false)
+	if asBot {
+		c.Set("bot", model.Bot{ID: primitive.NewObjectID(), Name: "Deploy Bot"})
+	}
+	return c, rec
+}
+
+func TestBotList(t *testing.T) {
+	expected := map[string]struct {
+		asBot  bool
+		getErr error
+		status int
+	}{
+		"success":             {status: 200},
+		"forbidden (bot)":     {asBot: true, status: 403},
+		"failure to get bots": {getErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodGet, "/bots", "", tt.asBot)
+
+			mongoBotSvcMock := new(mongo_svc_mock.BotSvcMock)
+			mongoBotSvcMock.On("GetBots", "test-uuid-1234", mock.Anything).Return([]model.Bot{
+				{ID: primitive.NewObjectID(), OwnerID: "test-uuid-1234", Name: "Deploy Bot"},
+			}, tt.getErr)
+
+			handler := NewBotHandler(mongoBotSvcMock, nil, nil, atylabclock.NewClock(), dto.NewBotDtoStruct())
+			err := handler.List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Bots []dto.BotAccountResponse `json:"bots"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result.Bots, 1)
+			assert.Equal(t, "Deploy Bot", result.Bots[0].Name)
+		})
+	}
+}
+
+func TestBotCreate(t *testing.T) {
+	botID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		body         string
+		asBot        bool
+		createErr    error
+		createCalled int
+		status       int
+	}{
+		"success": {
+			body:         `{"name": " Deploy Bot ", "icon_url": "https://example.com/bot.png"}`,
+			createCalled: 1,
+			status:       200,
+		},
+		"forbidden (bot)": {
+			body:   `{"name": "Deploy Bot", "icon_url": "https://example.com/bot.png"}`,
+			asBot:  true,
+			status: 403,
+		},
+		"validation error (no name)": {
+			body:   `{"name": ""}`,
+			status: 400,
+		},
+		"validation error (icon is not http)": {
+			body:   `{"name": "Deploy Bot", "icon_url": "ftp://example.com/bot.png"}`,
+			status: 400,
+		},
+		"limit reached": {
+			body:         `{"name": "Deploy Bot", "icon_url": "https://example.com/bot.png"}`,
+			createErr:    service.ErrBotLimitReached,
+			createCalled: 1,
+			status:       409,
+		},
+		"failure to create": {
+			body:         `{"name": "Deploy Bot", "icon_url": "https://example.com/bot.png"}`,
+			createErr:    assert.AnError,
+			createCalled: 1,
+			status:       500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodPost, "/bots", tt.body, tt.asBot)
+
+			botSvcMock := new(svc_mock.BotSvcMock)
+			botSvcMock.On("Create", model.Bot{
+				OwnerID: "test-uuid-1234",
+				Name:    "Deploy Bot",
+				IconURL: "https://example.com/bot.png",
+			}, mock.Anything).Return(model.Bot{ID: botID, OwnerID: "test-uuid-1234", Name: "Deploy Bot"}, tt.createErr)
+
+			handler := NewBotHandler(nil, nil, botSvcMock, atylabclock.NewClock(), dto.NewBotDtoStruct())
+			err := handler.Create(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			botSvcMock.AssertNumberOfCalls(t, "Create", tt.createCalled)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Bot dto.BotAccountResponse `json:"bot"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, botID.Hex(), result.Bot.ID)
+			assert.Equal(t, "bot:"+botID.Hex(), result.Bot.Sender)
+		})
+	}
+}
+
+func TestBotDelete(t *testing.T) {
+	expected := map[string]struct {
+		asBot     bool
+		deleted   bool
+		deleteErr error
+		status    int
+	}{
+		"success":           {deleted: true, status: 200},
+		"forbidden (bot)":   {asBot: true, status: 403},
+		"not found":         {deleted: false, status: 404},
+		"failure to delete": {deleteErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodDelete, "/bots/:bot_id", "", tt.asBot)
+			c.SetParamNames("bot_id")
+			c.SetParamValues("bot-id")
+
+			botSvcMock := new(svc_mock.BotSvcMock)
+			botSvcMock.On("Delete", "bot-id", "test-uuid-1234", mock.Anything).Return(tt.deleted, tt.deleteErr)
+
+			handler := NewBotHandler(nil, nil, botSvcMock, atylabclock.NewClock(), dto.NewBotDtoStruct())
+			err := handler.Delete(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestBotAPIKeys(t *testing.T) {
+	botID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		asBot   bool
+		bot     model.Bot
+		botErr  error
+		keysErr error
+		getKeys int
+		status  int
+	}{
+		"success": {
+			bot:     model.Bot{ID: botID, OwnerID: "test-uuid-1234"},
+			getKeys: 1,
+			status:  200,
+		},
+		"forbidden (bot)": {
+			asBot:  true,
+			status: 403,
+		},
+		"not found": {
+			botErr: mongo.ErrNoDocuments,
+			status: 404,
+		},
+		"bot of another user": {
+			bot:    model.Bot{ID: botID, OwnerID: "other-uuid"},
+			status: 404,
+		},
+		"failure to get api keys": {
+			bot:     model.Bot{ID: botID, OwnerID: "test-uuid-1234"},
+			keysErr: assert.AnError,
+			getKeys: 1,
+			status:  500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodGet, "/bots/:bot_id/keys", "", tt.asBot)
+			c.SetParamNames("bot_id")
+			c.SetParamValues(botID.Hex())
+
+			mongoBotSvcMock := new(mongo_svc_mock.BotSvcMock)
+			mongoBotSvcMock.On("GetBot", botID.Hex(), mock.Anything).Return(tt.bot, tt.botErr)
+			apiKeySvcMock := new(mongo_svc_mock.BotAPIKeySvcMock)
+			apiKeySvcMock.On("GetAPIKeys", botID.Hex(), mock.Anything).Return([]model.BotAPIKey{
+				{ID: primitive.NewObjectID(), BotID: botID.Hex(), Name: "ci", KeyHash: "key-hash"},
+			}, tt.keysErr)
+
+			handler := NewBotHandler(mongoBotSvcMock, apiKeySvcMock, nil, atylabclock.NewClock(), dto.NewBotDtoStruct())
+			err := handler.APIKeys(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			apiKeySvcMock.AssertNumberOfCalls(t, "GetAPIKeys", tt.getKeys)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				APIKeys []dto.BotAPIKeyResponse `json:"api_keys"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result.APIKeys, 1)
+			assert.NotContains(t, rec.Body.String(), "key-hash")
+		})
+	}
+}
+
+func TestBotIssueAPIKey(t *testing.T) {
+	botID := primitive.NewObjectID()
+	keyID := primitive.NewObjectID()
+	ownBot := model.Bot{ID: botID, OwnerID: "test-uuid-1234"}
+
+	expected := map[string]struct {
+		body        string
+		asBot       bool
+		bot         model.Bot
+		botErr      error
+		scope       model.BotAPIKeyScope
+		issueErr    error
+		issueCalled int
+		status      int
+	}{
+		"success": {
+			body:        `{"name": "ci"}`,
+			bot:         ownBot,
+			scope:       model.BotAPIKeyScope{RoomIDs: []string{}},
+			issueCalled: 1,
+			status:      200,
+		},
+		"success (read-only, limited rooms)": {
+			body:        `{"name": "ci", "read_only": true, "room_ids": ["room1", "room2", "room1"]}`,
+			bot:         ownBot,
+			scope:       model.BotAPIKeyScope{ReadOnly: true, RoomIDs: []string{"room1", "room2"}},
+			issueCalled: 1,
+			status:      200,
+		},
+		"forbidden (bot)": {
+			body:   `{"name": "ci"}`,
+			asBot:  true,
+			status: 403,
+		},
+		"validation error (no name)": {
+			body:   `{"name": ""}`,
+			bot:    ownBot,
+			status: 400,
+		},
+		"validation error (empty room id)": {
+			body:   `{"name": "ci", "room_ids": [""]}`,
+			bot:    ownBot,
+			status: 400,
+		},
+		"bot of another user": {
+			body:   `{"name": "ci"}`,
+			bot:    model.Bot{ID: botID, OwnerID: "other-uuid"},
+			status: 404,
+		},
+		"not found": {
+			body:   `{"name": "ci"}`,
+			botErr: mongo.ErrNoDocuments,
+			status: 404,
+		},
+		"limit reached": {
+			body:        `{"name": "ci"}`,
+			bot:         ownBot,
+			scope:       model.BotAPIKeyScope{RoomIDs: []string{}},
+			issueErr:    service.ErrBotAPIKeyLimitReached,
+			issueCalled: 1,
+			status:      409,
+		},
+		"failure to issue": {
+			body:        `{"name": "ci"}`,
+			bot:         ownBot,
+			scope:       model.BotAPIKeyScope{RoomIDs: []string{}},
+			issueErr:    assert.AnError,
+			issueCalled: 1,
+			status:      500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodPost, "/bots/:bot_id/keys", tt.body, tt.asBot)
+			c.SetParamNames("bot_id")
+			c.SetParamValues(botID.Hex())
+
+			mongoBotSvcMock := new(mongo_svc_mock.BotSvcMock)
+			mongoBotSvcMock.On("GetBot", botID.Hex(), mock.Anything).Return(tt.bot, tt.botErr)
+			botSvcMock := new(svc_mock.BotSvcMock)
+			botSvcMock.On("IssueAPIKey", model.BotAPIKey{
+				BotID: botID.Hex(),
+				Name:  "ci",
+				Scope: tt.scope,
+			}, mock.Anything).Return(model.BotAPIKey{ID: keyID, BotID: botID.Hex(), Name: "ci", Scope: tt.scope}, "bot_generated", tt.issueErr)
+
+			handler := NewBotHandler(mongoBotSvcMock, nil, botSvcMock, atylabclock.NewClock(), dto.NewBotDtoStruct())
+			err := handler.IssueAPIKey(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			botSvcMock.AssertNumberOfCalls(t, "IssueAPIKey", tt.issueCalled)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				APIKeyInfo dto.BotAPIKeyResponse `json:"api_key_info"`
+				APIKey     string                `json:"api_key"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, keyID.Hex(), result.APIKeyInfo.ID)
+			assert.Equal(t, "bot_generated", result.APIKey)
+		})
+	}
+}
+
+func TestBotRevokeAPIKey(t *testing.T) {
+	botID := primitive.NewObjectID()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	expected := map[string]struct {
+		asBot     bool
+		bot       model.Bot
+		botErr    error
+		revoked   bool
+		revokeErr error
+		status    int
+	}{
+		"success":                {bot: model.Bot{ID: botID, OwnerID: "test-uuid-1234"}, revoked: true, status: 200},
+		"forbidden (bot)":        {asBot: true, status: 403},
+		"bot of another user":    {bot: model.Bot{ID: botID, OwnerID: "other-uuid"}, status: 404},
+		"bot not found":          {botErr: mongo.ErrNoDocuments, status: 404},
+		"key not found":          {bot: model.Bot{ID: botID, OwnerID: "test-uuid-1234"}, revoked: false, status: 404},
+		"failure to revoke keys": {bot: model.Bot{ID: botID, OwnerID: "test-uuid-1234"}, revokeErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodDelete, "/bots/:bot_id/keys/:key_id", "", tt.asBot)
+			c.SetParamNames("bot_id", "key_id")
+			c.SetParamValues(botID.Hex(), "key-id")
+
+			mongoBotSvcMock := new(mongo_svc_mock.BotSvcMock)
+			mongoBotSvcMock.On("GetBot", botID.Hex(), mock.Anything).Return(tt.bot, tt.botErr)
+			apiKeySvcMock := new(mongo_svc_mock.BotAPIKeySvcMock)
+			apiKeySvcMock.On("RevokeAPIKey", "key-id", botID.Hex(), now, mock.Anything).Return(tt.revoked, tt.revokeErr)
+
+			handler := NewBotHandler(mongoBotSvcMock, apiKeySvcMock, nil, atylabclock.NewClockMock(now), dto.NewBotDtoStruct())
+			err := handler.RevokeAPIKey(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type EventHandlerInterface interface {
+	Stream(c echo.Context) error
+}
+
+type EventHandler struct {
+	BaseHandler
+	mongoRoomSvc mongo_svc.RoomSvcInterface
+	eventSvc     service.EventSvcInterface
+	presenceSvc  service.PresenceSvcInterface
+}
+
+func NewEventHandler(
+	mongoRoomSvc mongo_svc.RoomSvcInterface,
+	eventSvc service.EventSvcInterface,
+	presenceSvc service.PresenceSvcInterface,
+) *EventHandler {
+	return &EventHandler{
+		mongoRoomSvc: mongoRoomSvc,
+		eventSvc:     eventSvc,
+		presenceSvc:  presenceSvc,
+	}
+}
+
+// 参加しているすべてのルームのイベントを Server-Sent Events で送り続ける
+// Last-Event-ID（ヘッダーまたは last_event_id クエリ）を指定した場合は、その後のイベントから送り直す
+// 途中で参加・退出したルームは、本人の参加・退出のイベントを受け取った時点で反映する
+// それ以外の方法で参加したルームのイベントは、ルームの一覧を読み直すまで届かない
+// 接続している間はオンラインとして扱う
+func (h *EventHandler) Stream(c echo.Context) error {
+	uuid := h.GetUuid(c)
+	ctx := c.Request().Context()
+
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+
+	rooms, err := h.joinedRooms(uuid)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	cursor, replayable, err := h.eventSvc.Resume(lastEventID, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	// nginx などのプロキシにバッファリングさせない
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", consts.SSERetry); err != nil {
+		return nil
+	}
+	if !replayable {
+		// 取りこぼしたイベントがあるので、クライアントに状態を取得し直してもらう
+		if err := writeSSEEvent(res, cursor, consts.SSEResetEvent, "{}"); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	h.heartbeat(uuid)
+
+	lastWrite := time.Now()
+	roomsLoadedAt := time.Now()
+	heartbeatAt := time.Now()
+	poll := time.NewTicker(consts.SSEPollInterval)
+	defer poll.Stop()
+
+	for {
+		if time.Since(roomsLoadedAt) >= consts.SSERoomRefreshInterval {
+			if reloaded, err := h.joinedRooms(uuid); err != nil {
+				fmt.Println("Failed to reload rooms:", err)
+			} else {
+				rooms = reloaded
+			}
+			roomsLoadedAt = time.Now()
+		}
+		if time.Since(heartbeatAt) >= consts.PresenceHeartbeatInterval {
+			h.heartbeat(uuid)
+			heartbeatAt = time.Now()
+		}
+
+		written, err := h.sendEvents(res, uuid, rooms, &cursor, ctx)
+		if err != nil {
+			// 切断した場合はクライアントが Last-Event-ID を付けて再接続する
+			if ctx.Err() == nil {
+				fmt.Println("Failed to stream events:", err)
+			}
+			return nil
+		}
+
+		if written == 0 && time.Since(lastWrite) >= consts.SSEHeartbeatInterval {
+			// 他のルームのイベントだけが続いた場合も再接続時に読み直す範囲が広がらないよう、ID を進めておく
+			if _, err := fmt.Fprintf(res, ": ping\nid: %s\n\n", cursor); err != nil {
+				return nil
+			}
+			written = 1
+		}
+		if written > 0 {
+			res.Flush()
+			lastWrite = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+		}
+	}
+}
+
+// cursor より後のイベントのうち、参加しているルームのものを書き出す
+// 本人の参加・退出とルームの削除は rooms に反映する
+// 溜まっているイベントは続けて読み出し、書き出した件数を返す
+func (h *EventHandler) sendEvents(
+	w io.Writer,
+	uuid string,
+	rooms map[string]bool,
+	cursor *string,
+	ctx context.Context,
+) (int, error) {
+	written := 0
+	for {
+		events, err := h.eventSvc.Read(*cursor, ctx)
+		if err != nil {
+			return written, err
+		}
+		for _, event := range events {
+			*cursor = event.ID
+			if event.Type == consts.EventTypes.MemberJoined && event.Member == uuid {
+				rooms[event.RoomID] = true
+			}
+			if !rooms[event.RoomID] || event.ExceptUuid == uuid {
+				continue
+			}
+			if err := writeSSEEvent(w, event.ID, event.Type, event.Payload); err != nil {
+				return written, err
+			}
+			written++
+			// 退出したルームのイベントは、退出のイベントを最後に届けない
+			if event.Type == consts.EventTypes.RoomDeleted ||
+				(event.Type == consts.EventTypes.MemberLeft && event.Member == uuid) {
+				delete(rooms, event.RoomID)
+			}
+		}
+		if len(events) < consts.SSEReadCount {
+			return written, nil
+		}
+	}
+}
+
+func (h *EventHandler) joinedRooms(uuid string) (map[string]bool, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	rooms, err := h.mongoRoomSvc.GetRoomList(uuid, "joined", ctx)
+	if err != nil {
+		return nil, err
+	}
+	joined := make(map[string]bool, len(rooms))
+	for _, room := range rooms {
+		joined[room.ID.Hex()] = true
+	}
+	return joined, nil
+}
+
+// オンライン状態を記録できなくても、イベントは送り続ける
+func (h *EventHandler) heartbeat(uuid string) {
+	if _, err := h.presenceSvc.Heartbeat(uuid); err != nil {
+		fmt.Println("Failed to record presence:", err)
+	}
+}
+
+func writeSSEEvent(w io.Writer, id string, event string, data string) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, event, data)
+	return err
+}
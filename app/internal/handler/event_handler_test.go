@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEventStream(t *testing.T) {
+	joinedRoomID := primitive.NewObjectID()
+	otherRoomID := primitive.NewObjectID()
+	joinedPayload := `{"type":"message.updated","room_id":"` + joinedRoomID.Hex() + `","data":null}`
+
+	tests := map[string]struct {
+		header      string
+		query       string
+		lastEventID string
+		cursor      string
+		replayable  bool
+		roomsErr    error
+		resumeErr   error
+		readErr     error
+		status      int
+		contains    []string
+		notContains []string
+	}{
+		"stream joined rooms": {
+			header:      "5-0",
+			lastEventID: "5-0",
+			cursor:      "5-0",
+			replayable:  true,
+			status:      200,
+			contains: []string{
+				"retry: 3000\n\n",
+				"id: 6-0\nevent: message.updated\ndata: " + joinedPayload + "\n\n",
+			},
+			notContains: []string{"id: 7-0", "id: 8-0", "event: reset"},
+		},
+		"last event id from query": {
+			query:       "?last_event_id=5-0",
+			lastEventID: "5-0",
+			cursor:      "5-0",
+			replayable:  true,
+			status:      200,
+			contains:    []string{"id: 6-0"},
+		},
+		"reset when events were trimmed": {
+			header:      "1-0",
+			lastEventID: "1-0",
+			cursor:      "5-0",
+			replayable:  false,
+			status:      200,
+			contains:    []string{"id: 5-0\nevent: reset\ndata: {}\n\n", "id: 6-0"},
+		},
+		"read error": {
+			lastEventID: "",
+			cursor:      "5-0",
+			replayable:  true,
+			readErr:     assert.AnError,
+			status:      200,
+			contains:    []string{"retry: 3000"},
+			notContains: []string{"id: 6-0"},
+		},
+		"failure to get rooms": {
+			roomsErr: assert.AnError,
+			status:   500,
+		},
+		"failure to resume": {
+			resumeErr: assert.AnError,
+			status:    500,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/events"+tt.query, nil).WithContext(ctx)
+			if tt.header != "" {
+				req.Header.Set("Last-Event-ID", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+
+			mongoSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			mongoSvcMock.On("GetRoomList", "test-uuid-1234", "joined", mock.Anything).Return([]model.Room{{ID: joinedRoomID}}, tt.roomsErr)
+
+			eventSvcMock := new(svc_mock.EventSvcMock)
+			eventSvcMock.On("Resume", tt.lastEventID, mock.Anything).Return(tt.cursor, tt.replayable, tt.resumeErr)
+			// 1回読み出したらクライアントが切断したものとして終了させる
+			eventSvcMock.On("Read", tt.cursor, mock.Anything).Return([]service.StreamEvent{
+				{ID: "6-0", Type: "message.updated", RoomID: joinedRoomID.Hex(), Payload: joinedPayload},
+				{ID: "7-0", Type: "message.updated", RoomID: otherRoomID.Hex(), Payload: "{}"},
+				// 本人の操作による通知は本人には送らない
+				{ID: "8-0", Type: "typing", RoomID: joinedRoomID.Hex(), ExceptUuid: "test-uuid-1234", Payload: "{}"},
+			}, tt.readErr).Run(func(mock.Arguments) { cancel() }).Once()
+
+			presenceSvcMock := new(svc_mock.PresenceSvcMock)
+			presenceSvcMock.On("Heartbeat", "test-uuid-1234").Return(model.Presence{Status: "online"}, nil)
+
+			handler := NewEventHandler(mongoSvcMock, eventSvcMock, presenceSvcMock)
+			err := handler.Stream(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			for _, s := range tt.contains {
+				assert.Contains(t, rec.Body.String(), s)
+			}
+			for _, s := range tt.notContains {
+				assert.NotContains(t, rec.Body.String(), s)
+			}
+			if tt.status == 200 {
+				assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+				eventSvcMock.AssertNumberOfCalls(t, "Read", 1)
+				// 接続した時点でオンラインになる
+				presenceSvcMock.AssertCalled(t, "Heartbeat", "test-uuid-1234")
+			} else {
+				presenceSvcMock.AssertNotCalled(t, "Heartbeat", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestEventSendEvents(t *testing.T) {
+	uuid := "test-uuid-1234"
+	event := func(id string, eventType string, roomID string, member string) service.StreamEvent {
+		return service.StreamEvent{ID: id, Type: eventType, RoomID: roomID, Member: member, Payload: "{}"}
+	}
+
+	tests := map[string]struct {
+		rooms    map[string]bool
+		events   []service.StreamEvent
+		expected []string
+		after    map[string]bool
+	}{
+		"new message in joined room": {
+			rooms: map[string]bool{"room1": true},
+			events: []service.StreamEvent{
+				event("1-0", "message.created", "room1", ""),
+				event("2-0", "message.created", "room2", ""),
+			},
+			expected: []string{"1-0"},
+			after:    map[string]bool{"room1": true},
+		},
+		"joined room is streamed without reloading": {
+			rooms: map[string]bool{},
+			events: []service.StreamEvent{
+				event("1-0", "member.joined", "room1", uuid),
+				event("2-0", "message.created", "room1", ""),
+			},
+			expected: []string{"1-0", "2-0"},
+			after:    map[string]bool{"room1": true},
+		},
+		"other member joining does not add the room": {
+			rooms: map[string]bool{},
+			events: []service.StreamEvent{
+				event("1-0", "member.joined", "room1", "other-uuid"),
+				event("2-0", "message.created", "room1", ""),
+			},
+			expected: []string{},
+			after:    map[string]bool{},
+		},
+		"left room stops after the left event": {
+			rooms: map[string]bool{"room1": true},
+			events: []service.StreamEvent{
+				event("1-0", "member.left", "room1", uuid),
+				event("2-0", "message.created", "room1", ""),
+			},
+			expected: []string{"1-0"},
+			after:    map[string]bool{},
+		},
+		"other member leaving keeps the room": {
+			rooms: map[string]bool{"room1": true},
+			events: []service.StreamEvent{
+				event("1-0", "member.left", "room1", "other-uuid"),
+				event("2-0", "message.created", "room1", ""),
+			},
+			expected: []string{"1-0", "2-0"},
+			after:    map[string]bool{"room1": true},
+		},
+		"deleted room stops after the deleted event": {
+			rooms: map[string]bool{"room1": true},
+			events: []service.StreamEvent{
+				event("1-0", "room.deleted", "room1", ""),
+				event("2-0", "message.created", "room1", ""),
+			},
+			expected: []string{"1-0"},
+			after:    map[string]bool{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			eventSvcMock := new(svc_mock.EventSvcMock)
+			eventSvcMock.On("Read", "0-0", mock.Anything).Return(tt.events, nil)
+
+			handler := NewEventHandler(nil, eventSvcMock, nil)
+			w := &strings.Builder{}
+			cursor := "0-0"
+			written, err := handler.sendEvents(w, uuid, tt.rooms, &cursor, context.Background())
+
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.expected), written)
+			assert.Equal(t, tt.events[len(tt.events)-1].ID, cursor)
+			for _, id := range tt.expected {
+				assert.Contains(t, w.String(), "id: "+id+"\n")
+			}
+			assert.Equal(t, tt.after, tt.rooms)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type IncomingWebhookHandlerInterface interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Delete(c echo.Context) error
+	Post(c echo.Context) error
+}
+
+type IncomingWebhookHandler struct {
+	BaseHandler
+	incomingWebhookSvc mongo_svc.IncomingWebhookSvcInterface
+	postSvc            service.IncomingWebhookSvcInterface
+	dto                dto.IncomingWebhookDtoInterface
+}
+
+func NewIncomingWebhookHandler(
+	incomingWebhookSvc mongo_svc.IncomingWebhookSvcInterface,
+	postSvc service.IncomingWebhookSvcInterface,
+	dto dto.IncomingWebhookDtoInterface,
+) *IncomingWebhookHandler {
+	return &IncomingWebhookHandler{
+		incomingWebhookSvc: incomingWebhookSvc,
+		postSvc:            postSvc,
+		dto:                dto,
+	}
+}
+
+func (h *IncomingWebhookHandler) List(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	webhooks, err := h.incomingWebhookSvc.GetIncomingWebhooks(c.Param("room_id"), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"incoming_webhooks": h.dto.ResponseIncomingWebhookList(webhooks),
+	})
+}
+
+type CreateIncomingWebhookRequest struct {
+	Name    string `json:"name" form:"name" validate:"required,max=80"`
+	IconURL string `json:"icon_url" form:"icon_url" validate:"omitempty,url,startswith=http,max=2048"`
+}
+
+// 投稿先の URL（トークンを含む）はこのレスポンスでだけ返す
+func (h *IncomingWebhookHandler) Create(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return h.forbidden(c)
+	}
+
+	var req CreateIncomingWebhookRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	webhook, token, err := h.postSvc.Create(model.IncomingWebhook{
+		RoomID:    c.Param("room_id"),
+		Name:      strings.TrimSpace(req.Name),
+		IconURL:   req.IconURL,
+		CreatedBy: h.GetUuid(c),
+	}, ctx)
+	switch {
+	case errors.Is(err, service.ErrIncomingWebhookLimitReached):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	path := strings.NewReplacer(":webhook_id", webhook.ID.Hex(), ":token", token).Replace(consts.IncomingWebhookPath)
+	return c.JSON(200, echo.Map{
+		"incoming_webhook": h.dto.GetIncomingWebhookInfo(webhook),
+		"token":            token,
+		"url":              c.Scheme() + "://" + c.Request().Host + path,
+	})
+}
+
+func (h *IncomingWebhookHandler) Delete(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	deleted, err := h.incomingWebhookSvc.DeleteIncomingWebhook(c.Param("webhook_id"), c.Param("room_id"), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if !deleted {
+		return c.JSON(404, echo.Map{
+			"error": "incoming webhook not found",
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// Slack の Incoming Webhook と同じく、JSON の本文か payload パラメータ（フォーム送信）を受け付け、
+// 結果は "ok" や "no_text" などのテキストで返す
+func (h *IncomingWebhookHandler) Post(c echo.Context) error {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, consts.IncomingWebhookMaxBodySize+1))
+	if err != nil {
+		return c.String(400, "invalid_payload")
+	}
+	if len(body) > consts.IncomingWebhookMaxBodySize {
+		return c.String(413, "payload_too_large")
+	}
+
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationForm) {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return c.String(400, "invalid_payload")
+		}
+		body = []byte(values.Get("payload"))
+	}
+
+	var payload service.SlackPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return c.String(400, "invalid_payload")
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	webhook := c.Get(consts.ContextKeys.IncomingWebhook).(model.IncomingWebhook)
+	_, err = h.postSvc.Post(webhook, h.GetRoomModel(c), payload, ctx)
+	switch {
+	case errors.Is(err, service.ErrIncomingWebhookNoText):
+		return c.String(400, "no_text")
+	case errors.Is(err, service.ErrIncomingWebhookTextTooLong):
+		return c.String(400, "msg_too_long")
+	case errors.Is(err, service.ErrSpamRejected):
+		return c.String(422, "rejected_as_spam")
+	case err != nil:
+		fmt.Println("Failed to post incoming webhook message:", err)
+		return c.String(500, "internal_error")
+	}
+
+	return c.String(200, "ok")
+}
+
+func (h *IncomingWebhookHandler) forbidden(c echo.Context) error {
+	return c.JSON(403, echo.Map{
+		"error": "Only admin can manage incoming webhooks",
+	})
+}
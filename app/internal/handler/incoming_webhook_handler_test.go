@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIncomingWebhookList(t *testing.T) {
+	expected := map[string]struct {
+		isAdmin bool
+		getErr  error
+		status  int
+	}{
+		"success":                {isAdmin: true, status: 200},
+		"forbidden (not admin)":  {isAdmin: false, status: 403},
+		"failure to get webhook": {isAdmin: true, getErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodGet, "/room/:room_id/admin/incoming_webhooks", "", tt.isAdmin)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			incomingWebhookSvcMock := new(mongo_svc_mock.IncomingWebhookSvcMock)
+			incomingWebhookSvcMock.On("GetIncomingWebhooks", "test-room-id", mock.Anything).Return([]model.IncomingWebhook{
+				{ID: primitive.NewObjectID(), RoomID: "test-room-id", Name: "CI", TokenHash: "token-hash"},
+			}, tt.getErr)
+
+			handler := NewIncomingWebhookHandler(incomingWebhookSvcMock, nil, dto.NewIncomingWebhookDtoStruct())
+			err := handler.List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				IncomingWebhooks []dto.IncomingWebhookResponse `json:"incoming_webhooks"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result.IncomingWebhooks, 1)
+			assert.Equal(t, "CI", result.IncomingWebhooks[0].Name)
+			assert.NotContains(t, rec.Body.String(), "token-hash")
+		})
+	}
+}
+
+func TestIncomingWebhookCreate(t *testing.T) {
+	webhookID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		body         string
+		isAdmin      bool
+		createErr    error
+		createCalled int
+		status       int
+	}{
+		"success": {
+			body:         `{"name": " CI ", "icon_url": "https://example.com/ci.png"}`,
+			isAdmin:      true,
+			createCalled: 1,
+			status:       200,
+		},
+		"forbidden (not admin)": {
+			body:    `{"name": "CI"}`,
+			isAdmin: false,
+			status:  403,
+		},
+		"validation error (no name)": {
+			body:    `{"name": ""}`,
+			isAdmin: true,
+			status:  400,
+		},
+		"validation error (icon is not http)": {
+			body:    `{"name": "CI", "icon_url": "ftp://example.com/ci.png"}`,
+			isAdmin: true,
+			status:  400,
+		},
+		"limit reached": {
+			body:         `{"name": "CI", "icon_url": "https://example.com/ci.png"}`,
+			isAdmin:      true,
+			createErr:    service.ErrIncomingWebhookLimitReached,
+			createCalled: 1,
+			status:       409,
+		},
+		"failure to create": {
+			body:         `{"name": "CI", "icon_url": "https://example.com/ci.png"}`,
+			isAdmin:      true,
+			createErr:    assert.AnError,
+			createCalled: 1,
+			status:       500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodPost, "/room/:room_id/admin/incoming_webhooks", tt.body, tt.isAdmin)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			postSvcMock := new(svc_mock.IncomingWebhookSvcMock)
+			postSvcMock.On("Create", model.IncomingWebhook{
+				RoomID:    "test-room-id",
+				Name:      "CI",
+				IconURL:   "https://example.com/ci.png",
+				CreatedBy: "test-uuid-1234",
+			}, mock.Anything).Return(model.IncomingWebhook{
+				ID:     webhookID,
+				RoomID: "test-room-id",
+				Name:   "CI",
+			}, "generated-token", tt.createErr)
+
+			handler := NewIncomingWebhookHandler(nil, postSvcMock, dto.NewIncomingWebhookDtoStruct())
+			err := handler.Create(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			postSvcMock.AssertNumberOfCalls(t, "Create", tt.createCalled)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				IncomingWebhook dto.IncomingWebhookResponse `json:"incoming_webhook"`
+				Token           string                      `json:"token"`
+				URL             string                      `json:"url"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, webhookID.Hex(), result.IncomingWebhook.ID)
+			assert.Equal(t, "generated-token", result.Token)
+			assert.Equal(t, "http://example.com/hooks/"+webhookID.Hex()+"/generated-token", result.URL)
+		})
+	}
+}
+
+func TestIncomingWebhookDelete(t *testing.T) {
+	expected := map[string]struct {
+		isAdmin   bool
+		deleted   bool
+		deleteErr error
+		status    int
+	}{
+		"success":               {isAdmin: true, deleted: true, status: 200},
+		"forbidden (not admin)": {isAdmin: false, status: 403},
+		"not found":             {isAdmin: true, deleted: false, status: 404},
+		"failure to delete":     {isAdmin: true, deleteErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodDelete, "/room/:room_id/admin/incoming_webhooks/:webhook_id", "", tt.isAdmin)
+			c.SetParamNames("room_id", "webhook_id")
+			c.SetParamValues("test-room-id", "webhook-id")
+
+			incomingWebhookSvcMock := new(mongo_svc_mock.IncomingWebhookSvcMock)
+			incomingWebhookSvcMock.On("DeleteIncomingWebhook", "webhook-id", "test-room-id", mock.Anything).Return(tt.deleted, tt.deleteErr)
+
+			handler := NewIncomingWebhookHandler(incomingWebhookSvcMock, nil, dto.NewIncomingWebhookDtoStruct())
+			err := handler.Delete(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestIncomingWebhookPost(t *testing.T) {
+	webhook := model.IncomingWebhook{ID: primitive.NewObjectID(), RoomID: "test-room-id", Name: "CI"}
+	room := model.Room{ID: primitive.NewObjectID()}
+
+	expected := map[string]struct {
+		contentType string
+		body        string
+		postErr     error
+		postCalled  int
+		status      int
+		response    string
+	}{
+		"success (json)": {
+			contentType: "application/json",
+			body:        `{"text": "Build passed"}`,
+			postCalled:  1,
+			status:      200,
+			response:    "ok",
+		},
+		"success (form payload)": {
+			contentType: "application/x-www-form-urlencoded",
+			body:        "payload=" + url.QueryEscape(`{"text": "Build passed"}`),
+			postCalled:  1,
+			status:      200,
+			response:    "ok",
+		},
+		"invalid json": {
+			contentType: "application/json",
+			body:        `{"text": `,
+			status:      400,
+			response:    "invalid_payload",
+		},
+		"form without payload": {
+			contentType: "application/x-www-form-urlencoded",
+			body:        "text=Build+passed",
+			status:      400,
+			response:    "invalid_payload",
+		},
+		"too large": {
+			contentType: "application/json",
+			body:        `{"text": "` + strings.Repeat("a", consts.IncomingWebhookMaxBodySize) + `"}`,
+			status:      413,
+			response:    "payload_too_large",
+		},
+		"no text": {
+			contentType: "application/json",
+			body:        `{"text": "Build passed"}`,
+			postErr:     service.ErrIncomingWebhookNoText,
+			postCalled:  1,
+			status:      400,
+			response:    "no_text",
+		},
+		"text too long": {
+			contentType: "application/json",
+			body:        `{"text": "Build passed"}`,
+			postErr:     service.ErrIncomingWebhookTextTooLong,
+			postCalled:  1,
+			status:      400,
+			response:    "msg_too_long",
+		},
+		"rejected as spam": {
+			contentType: "application/json",
+			body:        `{"text": "Build passed"}`,
+			postErr:     service.ErrSpamRejected,
+			postCalled:  1,
+			status:      422,
+			response:    "rejected_as_spam",
+		},
+		"failure to post": {
+			contentType: "application/json",
+			body:        `{"text": "Build passed"}`,
+			postErr:     assert.AnError,
+			postCalled:  1,
+			status:      500,
+			response:    "internal_error",
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodPost, "/hooks/:webhook_id/:token", tt.body, false)
+			c.Request().Header.Set("Content-Type", tt.contentType)
+			c.Set("room_model", room)
+			c.Set("incoming_webhook", webhook)
+
+			postSvcMock := new(svc_mock.IncomingWebhookSvcMock)
+			postSvcMock.On("Post", webhook, room, service.SlackPayload{Text: "Build passed"}, mock.Anything).Return("message-id", tt.postErr)
+
+			handler := NewIncomingWebhookHandler(nil, postSvcMock, dto.NewIncomingWebhookDtoStruct())
+			err := handler.Post(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.response, rec.Body.String())
+			postSvcMock.AssertNumberOfCalls(t, "Post", tt.postCalled)
+		})
+	}
+}
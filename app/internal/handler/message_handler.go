@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/labstack/echo/v4"
@@ -16,20 +19,38 @@ type MessageHandlerInterface interface {
 	Send(c echo.Context) error
 	Read(c echo.Context) error
 	Delete(c echo.Context) error
+	Report(c echo.Context) error
+	Pin(c echo.Context) error
+	Unpin(c echo.Context) error
+	Pins(c echo.Context) error
+	Deleted(c echo.Context) error
+	Restore(c echo.Context) error
 }
 
 type MessageHandler struct {
 	BaseHandler
 	messageSvc mongo_svc.MessageSvcInterface
+	sendSvc    service.MessageSvcInterface
+	reportSvc  mongo_svc.ReportSvcInterface
+	pinSvc     service.PinSvcInterface
+	commandSvc service.SlashCommandSvcInterface
 	dto        dto.MessageDtoInterface
 }
 
 func NewMessageHandler(
 	messageSvc mongo_svc.MessageSvcInterface,
+	sendSvc service.MessageSvcInterface,
+	reportSvc mongo_svc.ReportSvcInterface,
+	pinSvc service.PinSvcInterface,
+	commandSvc service.SlashCommandSvcInterface,
 	dto dto.MessageDtoInterface,
 ) *MessageHandler {
 	return &MessageHandler{
 		messageSvc: messageSvc,
+		sendSvc:    sendSvc,
+		reportSvc:  reportSvc,
+		pinSvc:     pinSvc,
+		commandSvc: commandSvc,
 		dto:        dto,
 	}
 }
@@ -54,12 +75,15 @@ func (h *MessageHandler) List(c echo.Context) error {
 	}
 
 	return c.JSON(200, echo.Map{
-		"messages": h.dto.ResponseMessageList(messages, uuid),
+		"messages": h.hideReaders(c, h.dto.ResponseMessageList(messages, uuid)),
 	})
 }
 
 type SendMessageRequest struct {
-	Message string `json:"message" form:"message" validate:"required"`
+	Message     string `json:"message" form:"message" validate:"required"`
+	ClientMsgID string `json:"client_msg_id" form:"client_msg_id" validate:"omitempty,max=64"`
+	// 有効期間（秒）。省略した場合はルームの既定に従う
+	TTL int `json:"ttl" form:"ttl"`
 }
 
 func (h *MessageHandler) Send(c echo.Context) error {
@@ -70,6 +94,11 @@ func (h *MessageHandler) Send(c echo.Context) error {
 			"error": err.Error(),
 		})
 	}
+	if !service.ValidMessageTTL(req.TTL) {
+		return c.JSON(400, echo.Map{
+			"error": fmt.Sprintf("ttl must be between %d and %d seconds", consts.MessageTTLMinSeconds, consts.MessageTTLMaxSeconds),
+		})
+	}
 
 	ctx := atylabmongo.NewMongoCtxSvc()
 	defer ctx.Cancel()
@@ -83,15 +112,29 @@ func (h *MessageHandler) Send(c echo.Context) error {
 		})
 	}
 
+	// "/" で始まるメッセージはコマンドとして実行し、メッセージとしては保存しない
+	if name, args, ok := service.ParseSlashCommand(req.Message); ok {
+		return h.executeSlashCommand(c, name, args, ctx)
+	}
+
+	now := time.Now()
 	message := model.Message{
 		RoomID:        roomID,
 		Sender:        uuid,
-		Message:       req.Message,
-		CreatedAt:     time.Now(),
+		Message:       service.UnescapeSlashCommand(req.Message),
+		CreatedAt:     now,
 		IsReadUserIds: []string{uuid},
+		ClientMsgID:   req.ClientMsgID,
+		ExpiresAt:     service.MessageExpiresAt(req.TTL, h.GetRoomModel(c), now),
+		Bot:           h.messageBot(c),
 	}
 
-	messageId, err := h.messageSvc.SendMessage(message, ctx)
+	messageId, err := h.sendSvc.Send(message, ctx)
+	if errors.Is(err, service.ErrSpamRejected) {
+		return c.JSON(422, echo.Map{
+			"error": err.Error(),
+		})
+	}
 	if err != nil {
 		return c.JSON(500, echo.Map{
 			"error": err.Error(),
@@ -102,6 +145,53 @@ func (h *MessageHandler) Send(c echo.Context) error {
 	})
 }
 
+// コマンドの結果やエラーは、実行したユーザーにだけ返す
+func (h *MessageHandler) executeSlashCommand(c echo.Context, name string, args string, ctx *atylabmongo.MongoCtxSvc) error {
+	result, err := h.commandSvc.Execute(service.SlashCommandRequest{
+		Name:    name,
+		Text:    args,
+		Room:    h.GetRoomModel(c),
+		Uuid:    h.GetUuid(c),
+		IsAdmin: h.IsAdmin(c),
+		Bot:     h.messageBot(c),
+	}, ctx)
+	switch {
+	case errors.Is(err, service.ErrSlashCommandUnknown):
+		return c.JSON(404, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrSlashCommandUsage):
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrSlashCommandForbidden):
+		return c.JSON(403, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrSpamRejected):
+		return c.JSON(422, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrSlashCommandFailed):
+		return c.JSON(502, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	response := echo.Map{}
+	if result.MessageID != "" {
+		response["message_id"] = result.MessageID
+	}
+	if result.Ephemeral != "" {
+		response["ephemeral"] = result.Ephemeral
+	}
+	return c.JSON(200, response)
+}
+
 type ReadMessageRequest struct {
 	MessageIds []string `json:"message_ids" form:"message_ids" validate:"required"`
 }
@@ -174,7 +264,7 @@ func (h *MessageHandler) Delete(c echo.Context) error {
 		}
 	}
 
-	if err := h.messageSvc.DeleteMessage(messageID, roomID, ctx); err != nil {
+	if err := h.messageSvc.DeleteMessage(messageID, roomID, uuid, ctx); err != nil {
 		return c.JSON(500, echo.Map{
 			"error": err.Error(),
 		})
@@ -184,3 +274,242 @@ func (h *MessageHandler) Delete(c echo.Context) error {
 		"status": "success",
 	})
 }
+
+type ReportMessageRequest struct {
+	Reason  string `json:"reason" form:"reason" validate:"required,oneof=spam harassment hate_speech violence sexual_content other"`
+	Comment string `json:"comment" form:"comment" validate:"max=1000"`
+}
+
+func (h *MessageHandler) Report(c echo.Context) error {
+	var req ReportMessageRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	messageID := c.Param("message_id")
+	uuid := h.GetUuid(c)
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	message, err := h.messageSvc.GetMessage(messageID, roomID, ctx)
+	if err != nil || message.DeletedAt != nil {
+		return c.JSON(404, echo.Map{
+			"error": "message not found",
+		})
+	}
+
+	// 同じユーザーによる未対応の通報が既にある場合は重複させない
+	reported, err := h.reportSvc.HasOpenReport(messageID, uuid, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if reported {
+		return c.JSON(409, echo.Map{
+			"error": "You have already reported this message.",
+		})
+	}
+
+	report := model.Report{
+		RoomID:         roomID,
+		MessageID:      messageID,
+		ReporterID:     uuid,
+		ReportedUserID: message.Sender,
+		Reason:         req.Reason,
+		Comment:        req.Comment,
+		Status:         consts.ReportStatus.Open,
+		CreatedAt:      time.Now(),
+	}
+
+	reportID, err := h.reportSvc.CreateReport(report, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"report_id": reportID,
+	})
+}
+
+// ピン留めはルームの管理者に限る
+func (h *MessageHandler) Pin(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	messageID := c.Param("message_id")
+	uuid := h.GetUuid(c)
+	if !h.IsAdmin(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not authorized to pin messages in this room.",
+		})
+	}
+
+	message, err := h.messageSvc.GetMessage(messageID, roomID, ctx)
+	if err != nil || message.DeletedAt != nil {
+		return c.JSON(404, echo.Map{
+			"error": "message not found",
+		})
+	}
+
+	pin, err := h.pinSvc.Pin(message, uuid, ctx)
+	if errors.Is(err, service.ErrAlreadyPinned) || errors.Is(err, service.ErrPinLimitReached) {
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	message.Pin = &pin
+	return c.JSON(200, echo.Map{
+		"message": h.hideReaders(c, []dto.MessageResponse{h.dto.GetMessageInfo(message, uuid)})[0],
+	})
+}
+
+func (h *MessageHandler) Unpin(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	messageID := c.Param("message_id")
+	if !h.IsAdmin(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not authorized to unpin messages in this room.",
+		})
+	}
+
+	message, err := h.messageSvc.GetMessage(messageID, roomID, ctx)
+	if err != nil {
+		return c.JSON(404, echo.Map{
+			"error": "message not found",
+		})
+	}
+
+	err = h.pinSvc.Unpin(message, ctx)
+	if errors.Is(err, service.ErrNotPinned) {
+		return c.JSON(404, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// ピン留めされたメッセージを、新しくピン留めした順で返す
+func (h *MessageHandler) Pins(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	uuid := h.GetUuid(c)
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	messages, err := h.messageSvc.GetPinnedMessages(roomID, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	pins := []dto.MessageResponse{}
+	for _, message := range messages {
+		pins = append(pins, h.dto.GetMessageInfo(message, uuid))
+	}
+
+	return c.JSON(200, echo.Map{
+		"pins": h.hideReaders(c, pins),
+	})
+}
+
+// 猶予期間内に削除されたメッセージを、削除前の内容とともに新しく削除した順で返す
+func (h *MessageHandler) Deleted(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	uuid := h.GetUuid(c)
+	if !h.IsAdmin(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not authorized to view deleted messages in this room.",
+		})
+	}
+
+	since := time.Now().Add(-consts.MessageDeleteGracePeriod)
+	messages, err := h.messageSvc.GetDeletedMessages(roomID, since, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"messages": h.dto.ResponseModeratorMessageList(messages, uuid),
+	})
+}
+
+// 猶予期間内に削除されたメッセージを元に戻す（外れたピン留めは戻さない）
+func (h *MessageHandler) Restore(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	messageID := c.Param("message_id")
+	if !h.IsAdmin(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not authorized to restore messages in this room.",
+		})
+	}
+
+	since := time.Now().Add(-consts.MessageDeleteGracePeriod)
+	restored, err := h.messageSvc.RestoreMessage(messageID, roomID, since, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if !restored {
+		return c.JSON(404, echo.Map{
+			"error": "deleted message not found or the grace period has passed",
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// ルームの設定で既読の情報を送信者にだけ見せる場合は、他人のメッセージの既読者を伏せる
+func (h *MessageHandler) hideReaders(c echo.Context, responses []dto.MessageResponse) []dto.MessageResponse {
+	if !h.GetRoomModel(c).HideReadReceipts {
+		return responses
+	}
+	return h.dto.HideReaders(responses, h.GetUuid(c))
+}
@@ -9,9 +9,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -29,6 +32,14 @@ func TestMessageList(t *testing.T) {
 			"GetMessageListSuccess": true,
 			"success":               true,
 		},
+		"success (read receipts hidden)": {
+			"status":                200,
+			"IsMember":              true,
+			"GetMessageListCalled":  1,
+			"GetMessageListSuccess": true,
+			"HideReadReceipts":      true,
+			"success":               true,
+		},
 		"forbidden (not a member)": {
 			"status":                403,
 			"IsMember":              false,
@@ -56,6 +67,8 @@ func TestMessageList(t *testing.T) {
 			c.SetParamValues("test-room-id")
 			c.Set("uuid", "test-uuid-1234")
 			c.Set("is_member", expect["IsMember"].(bool))
+			hideReadReceipts, _ := expect["HideReadReceipts"].(bool)
+			c.Set("room_model", model.Room{HideReadReceipts: hideReadReceipts})
 
 			dto := dto.NewMessageDtoStruct()
 			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
@@ -91,7 +104,7 @@ func TestMessageList(t *testing.T) {
 					Times(expect["GetMessageListCalled"].(int))
 			}
 
-			handler := NewMessageHandler(messageSvcMock, dto)
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto)
 			err = handler.List(c)
 
 			assert.NoError(t, err)
@@ -122,11 +135,20 @@ func TestMessageList(t *testing.T) {
 			assert.Equal(t, "another test message", result["messages"][1].(map[string]interface{})["Message"])
 			assert.Equal(t, "another-sender-id", result["messages"][1].(map[string]interface{})["Sender"])
 			assert.False(t, result["messages"][1].(map[string]interface{})["IsRead"].(bool))
+
+			// 既読の情報を伏せるルームでは、自分が送信していないメッセージの既読者を返さない
+			readers := result["messages"][0].(map[string]interface{})["Readers"].([]interface{})
+			if hideReadReceipts {
+				assert.Empty(t, readers)
+			} else {
+				assert.Len(t, readers, 2)
+			}
 		})
 	}
 }
 
 func TestMessageSend(t *testing.T) {
+	botID := primitive.NewObjectID()
 	expected := map[string]map[string]any{
 		"success": {
 			"status": 200,
@@ -138,6 +160,97 @@ func TestMessageSend(t *testing.T) {
 			"SendMessageCalled":  1,
 			"SendMessageSuccess": true,
 		},
+		"success with client_msg_id": {
+			"status": 200,
+			"body": map[string]interface{}{
+				"message":       "Hello, world!",
+				"client_msg_id": "client-msg-1",
+			},
+			"IsMember":           true,
+			"success":            true,
+			"SendMessageCalled":  1,
+			"SendMessageSuccess": true,
+			"ClientMsgID":        "client-msg-1",
+		},
+		"success (sent by bot)": {
+			"status": 200,
+			"body": map[string]interface{}{
+				"message": "Deployed v1.2.3",
+			},
+			"Bot":                model.Bot{ID: botID, Name: "Deploy Bot"},
+			"IsMember":           true,
+			"success":            true,
+			"SendMessageCalled":  1,
+			"SendMessageSuccess": true,
+		},
+		"success (escaped slash is sent as text)": {
+			"status": 200,
+			"body": map[string]interface{}{
+				"message": "//shrug is not a command",
+			},
+			"IsMember":           true,
+			"success":            true,
+			"SendMessageCalled":  1,
+			"SendMessageSuccess": true,
+			"ExpectedMessage":    "/shrug is not a command",
+		},
+		"success (path is not a command)": {
+			"status": 200,
+			"body": map[string]interface{}{
+				"message": "/usr/bin is missing",
+			},
+			"IsMember":           true,
+			"success":            true,
+			"SendMessageCalled":  1,
+			"SendMessageSuccess": true,
+			"ExpectedMessage":    "/usr/bin is missing",
+		},
+		"validation error (client_msg_id too long)": {
+			"status": 400,
+			"body": map[string]interface{}{
+				"message":       "Hello, world!",
+				"client_msg_id": strings.Repeat("a", 65),
+			},
+			"IsMember":           true,
+			"success":            false,
+			"SendMessageCalled":  0,
+			"SendMessageSuccess": true,
+		},
+		"success with ttl": {
+			"status": 200,
+			"body": map[string]interface{}{
+				"message": "password: hunter2",
+				"ttl":     60,
+			},
+			"IsMember":           true,
+			"success":            true,
+			"SendMessageCalled":  1,
+			"SendMessageSuccess": true,
+			"ExpectedTTL":        60,
+		},
+		"success with room default ttl": {
+			"status": 200,
+			"body": map[string]interface{}{
+				"message": "password: hunter2",
+			},
+			"Room":               model.Room{MessageTTL: 3600},
+			"IsMember":           true,
+			"success":            true,
+			"SendMessageCalled":  1,
+			"SendMessageSuccess": true,
+			"ExpectedTTL":        3600,
+		},
+		"validation error (ttl out of range)": {
+			"status": 400,
+			"body": map[string]interface{}{
+				"message": "Hello, world!",
+				"ttl":     consts.MessageTTLMaxSeconds + 1,
+			},
+			"IsMember":           true,
+			"success":            false,
+			"SendMessageCalled":  0,
+			"SendMessageSuccess": true,
+		},
 		"validation error (missing message)": {
 			"status":             400,
 			"body":               map[string]interface{}{},
@@ -166,6 +279,17 @@ func TestMessageSend(t *testing.T) {
 			"SendMessageCalled":  1,
 			"SendMessageSuccess": false,
 		},
+		"rejected as spam": {
+			"status": 422,
+			"body": map[string]interface{}{
+				"message": "Hello, world!",
+			},
+			"IsMember":           true,
+			"success":            false,
+			"SendMessageCalled":  1,
+			"SendMessageSuccess": false,
+			"SendMessageError":   service.ErrSpamRejected,
+		},
 	}
 
 	for name, expect := range expected {
@@ -186,24 +310,50 @@ func TestMessageSend(t *testing.T) {
 			c.SetParamValues("test-room-id")
 			c.Set("uuid", "test-uuid-1234")
 			c.Set("is_member", expect["IsMember"].(bool))
+			room, _ := expect["Room"].(model.Room)
+			c.Set("room_model", room)
+			bot, isBot := expect["Bot"].(model.Bot)
+			if isBot {
+				c.Set("bot", bot)
+			}
 
 			dto := dto.NewMessageDtoStruct()
 
-			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock := new(svc_mock.MessageSvcMock)
 
 			var sendMessageErr error = nil
 			if !expect["SendMessageSuccess"].(bool) {
 				sendMessageErr = assert.AnError
+				if err, ok := expect["SendMessageError"].(error); ok {
+					sendMessageErr = err
+				}
 			}
 
+			clientMsgID, _ := expect["ClientMsgID"].(string)
 			if expect["SendMessageCalled"].(int) > 0 {
 				messageSvcMock.
-					On("SendMessage", mock.AnythingOfType("model.Message"), mock.Anything).
+					On("Send", mock.MatchedBy(func(m model.Message) bool {
+						if ttl, ok := expect["ExpectedTTL"].(int); ok {
+							if m.ExpiresAt == nil || m.ExpiresAt.Sub(m.CreatedAt) != time.Duration(ttl)*time.Second {
+								return false
+							}
+						} else if m.ExpiresAt != nil {
+							return false
+						}
+						// ボットが送信したメッセージには送信元のボットを記録する
+						if isBot != (m.Bot != nil) || (isBot && *m.Bot != *service.BotMessageSender(bot)) {
+							return false
+						}
+						if expectedMessage, ok := expect["ExpectedMessage"].(string); ok && m.Message != expectedMessage {
+							return false
+						}
+						return m.ClientMsgID == clientMsgID
+					}), mock.Anything).
 					Return("new-message-id-5678", sendMessageErr).
 					Times(expect["SendMessageCalled"].(int))
 			}
 
-			handler := NewMessageHandler(messageSvcMock, dto)
+			handler := NewMessageHandler(new(mongo_svc_mock.MessageSvcMock), messageSvcMock, new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto)
 			err := handler.Send(c)
 
 			assert.NoError(t, err)
@@ -213,7 +363,7 @@ func TestMessageSend(t *testing.T) {
 			if expect["SendMessageCalled"].(int) > 0 {
 				messageSvcMock.AssertExpectations(t)
 			} else {
-				messageSvcMock.AssertNotCalled(t, "SendMessage")
+				messageSvcMock.AssertNotCalled(t, "Send")
 			}
 
 			if expect["status"].(int) != http.StatusOK {
@@ -229,6 +379,130 @@ func TestMessageSend(t *testing.T) {
 	}
 }
 
+func TestMessageSendSlashCommand(t *testing.T) {
+	roomID := primitive.NewObjectID()
+	room := model.Room{ID: roomID, Topic: "release"}
+
+	expected := map[string]struct {
+		message    string
+		isMember   bool
+		result     service.SlashCommandResult
+		executeErr error
+		status     int
+		expectName string
+		expectArgs string
+		expectBody map[string]any
+	}{
+		"message sent by command": {
+			message:    "/me waves",
+			isMember:   true,
+			result:     service.SlashCommandResult{MessageID: "new-message-id"},
+			status:     200,
+			expectName: "me",
+			expectArgs: "waves",
+			expectBody: map[string]any{"message_id": "new-message-id"},
+		},
+		"ephemeral response": {
+			message:    "/topic",
+			isMember:   true,
+			result:     service.SlashCommandResult{Ephemeral: "Topic: release"},
+			status:     200,
+			expectName: "topic",
+			expectBody: map[string]any{"ephemeral": "Topic: release"},
+		},
+		"not a member": {
+			message: "/me waves",
+			status:  403,
+		},
+		"unknown command": {
+			message:    "/shrug",
+			isMember:   true,
+			executeErr: fmt.Errorf("%w: /shrug", service.ErrSlashCommandUnknown),
+			status:     404,
+			expectName: "shrug",
+			expectBody: map[string]any{"error": "unknown command: /shrug"},
+		},
+		"usage error": {
+			message:    "/me",
+			isMember:   true,
+			executeErr: service.ErrSlashCommandUsage,
+			status:     400,
+			expectName: "me",
+		},
+		"forbidden": {
+			message:    "/topic new topic",
+			isMember:   true,
+			executeErr: service.ErrSlashCommandForbidden,
+			status:     403,
+			expectName: "topic",
+			expectArgs: "new topic",
+		},
+		"rejected as spam": {
+			message:    "/me buy now",
+			isMember:   true,
+			executeErr: service.ErrSpamRejected,
+			status:     422,
+			expectName: "me",
+			expectArgs: "buy now",
+		},
+		"external command failed": {
+			message:    "/deploy prod",
+			isMember:   true,
+			executeErr: service.ErrSlashCommandFailed,
+			status:     502,
+			expectName: "deploy",
+			expectArgs: "prod",
+		},
+		"failure to execute": {
+			message:    "/mute",
+			isMember:   true,
+			executeErr: assert.AnError,
+			status:     500,
+			expectName: "mute",
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodPost, "/message/:room_id/send", `{"message": "`+tt.message+`"}`, true)
+			c.SetParamNames("room_id")
+			c.SetParamValues(roomID.Hex())
+			c.Set("is_member", tt.isMember)
+			c.Set("room_model", room)
+
+			commandSvcMock := new(svc_mock.SlashCommandSvcMock)
+			commandSvcMock.On("Execute", service.SlashCommandRequest{
+				Name:    tt.expectName,
+				Text:    tt.expectArgs,
+				Room:    room,
+				Uuid:    "test-uuid-1234",
+				IsAdmin: true,
+			}, mock.Anything).Return(tt.result, tt.executeErr)
+			messageSvcMock := new(svc_mock.MessageSvcMock)
+
+			handler := NewMessageHandler(new(mongo_svc_mock.MessageSvcMock), messageSvcMock, new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), commandSvcMock, dto.NewMessageDtoStruct())
+			err := handler.Send(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			// コマンドはメッセージとして保存しない
+			messageSvcMock.AssertNotCalled(t, "Send", mock.Anything, mock.Anything)
+			if tt.expectName == "" {
+				commandSvcMock.AssertNotCalled(t, "Execute", mock.Anything, mock.Anything)
+				return
+			}
+			commandSvcMock.AssertNumberOfCalls(t, "Execute", 1)
+			if tt.expectBody == nil {
+				return
+			}
+
+			var result map[string]any
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, tt.expectBody, result)
+		})
+	}
+}
+
 func TestMessageRead(t *testing.T) {
 	expected := map[string]map[string]any{
 		"success": {
@@ -302,7 +576,7 @@ func TestMessageRead(t *testing.T) {
 					Times(expect["ReadMessagesCalled"].(int))
 			}
 
-			handler := NewMessageHandler(messageSvcMock, dto)
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto)
 			err := handler.Read(c)
 
 			assert.NoError(t, err)
@@ -443,12 +717,12 @@ func TestMessageDelete(t *testing.T) {
 
 			if expect["DeleteMessageCalled"].(int) > 0 {
 				messageSvcMock.
-					On("DeleteMessage", "msgid1", "test-room-id", mock.Anything).
+					On("DeleteMessage", "msgid1", "test-room-id", "test-uuid-1234", mock.Anything).
 					Return(deleteMessageErr).
 					Times(expect["DeleteMessageCalled"].(int))
 			}
 
-			handler := NewMessageHandler(messageSvcMock, dto)
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto)
 			err := handler.Delete(c)
 
 			assert.NoError(t, err)
@@ -480,3 +754,542 @@ func TestMessageDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageReport(t *testing.T) {
+	expected := map[string]map[string]any{
+		"success": {
+			"status":             200,
+			"body":               map[string]interface{}{"reason": "spam", "comment": "advertisement"},
+			"IsMember":           true,
+			"GetMessageError":    nil,
+			"HasOpenReport":      false,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 1,
+			"CreateReportError":  nil,
+		},
+		"validation error (missing reason)": {
+			"status":             400,
+			"body":               map[string]interface{}{},
+			"IsMember":           true,
+			"GetMessageError":    nil,
+			"HasOpenReport":      false,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 0,
+			"CreateReportError":  nil,
+		},
+		"validation error (invalid reason)": {
+			"status":             400,
+			"body":               map[string]interface{}{"reason": "boring"},
+			"IsMember":           true,
+			"GetMessageError":    nil,
+			"HasOpenReport":      false,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 0,
+			"CreateReportError":  nil,
+		},
+		"forbidden (not a member)": {
+			"status":             403,
+			"body":               map[string]interface{}{"reason": "spam"},
+			"IsMember":           false,
+			"GetMessageError":    nil,
+			"HasOpenReport":      false,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 0,
+			"CreateReportError":  nil,
+		},
+		"message not found": {
+			"status":             404,
+			"body":               map[string]interface{}{"reason": "spam"},
+			"IsMember":           true,
+			"GetMessageError":    assert.AnError,
+			"HasOpenReport":      false,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 0,
+			"CreateReportError":  nil,
+		},
+		"message already deleted": {
+			"status":             404,
+			"body":               map[string]interface{}{"reason": "spam"},
+			"IsMember":           true,
+			"Deleted":            true,
+			"GetMessageError":    nil,
+			"HasOpenReport":      false,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 0,
+			"CreateReportError":  nil,
+		},
+		"already reported": {
+			"status":             409,
+			"body":               map[string]interface{}{"reason": "spam"},
+			"IsMember":           true,
+			"GetMessageError":    nil,
+			"HasOpenReport":      true,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 0,
+			"CreateReportError":  nil,
+		},
+		"failure to check open report": {
+			"status":             500,
+			"body":               map[string]interface{}{"reason": "spam"},
+			"IsMember":           true,
+			"GetMessageError":    nil,
+			"HasOpenReport":      false,
+			"HasOpenReportError": assert.AnError,
+			"CreateReportCalled": 0,
+			"CreateReportError":  nil,
+		},
+		"failure to create report": {
+			"status":             500,
+			"body":               map[string]interface{}{"reason": "spam"},
+			"IsMember":           true,
+			"GetMessageError":    nil,
+			"HasOpenReport":      false,
+			"HasOpenReportError": nil,
+			"CreateReportCalled": 1,
+			"CreateReportError":  assert.AnError,
+		},
+	}
+
+	for name, expect := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+			jsonBody, _ := json.Marshal(expect["body"].(map[string]interface{}))
+			req := httptest.NewRequest(http.MethodPost, "/message/:room_id/:message_id/report", strings.NewReader(string(jsonBody)))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", "test-message-id")
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", expect["IsMember"].(bool))
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+
+			getMessageErr, _ := expect["GetMessageError"].(error)
+			hasOpenReportErr, _ := expect["HasOpenReportError"].(error)
+			createReportErr, _ := expect["CreateReportError"].(error)
+
+			reported := model.Message{Sender: "reported-uuid-5678"}
+			if deleted, _ := expect["Deleted"].(bool); deleted {
+				deletedAt := time.Now()
+				reported.DeletedAt = &deletedAt
+			}
+			messageSvcMock.
+				On("GetMessage", "test-message-id", "test-room-id", mock.Anything).
+				Return(reported, getMessageErr)
+			reportSvcMock.
+				On("HasOpenReport", "test-message-id", "test-uuid-1234", mock.Anything).
+				Return(expect["HasOpenReport"].(bool), hasOpenReportErr)
+			reportSvcMock.
+				On("CreateReport", mock.MatchedBy(func(r model.Report) bool {
+					return r.ReporterID == "test-uuid-1234" &&
+						r.ReportedUserID == "reported-uuid-5678" &&
+						r.Status == "open"
+				}), mock.Anything).
+				Return("new-report-id", createReportErr)
+
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), reportSvcMock, new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto.NewMessageDtoStruct())
+			err := handler.Report(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expect["status"].(int), rec.Code)
+			reportSvcMock.AssertNumberOfCalls(t, "CreateReport", expect["CreateReportCalled"].(int))
+
+			if expect["status"].(int) != http.StatusOK {
+				return
+			}
+
+			result := map[string]interface{}{}
+			err = json.Unmarshal(rec.Body.Bytes(), &result)
+			assert.NoError(t, err)
+			assert.Equal(t, "new-report-id", result["report_id"])
+		})
+	}
+}
+
+func TestMessagePin(t *testing.T) {
+	messageID := primitive.NewObjectID()
+	message := model.Message{ID: messageID, RoomID: "test-room-id", Sender: "sender-uuid", Message: "announcement"}
+	pin := model.MessagePin{PinnedBy: "test-uuid-1234", PinnedAt: time.Now()}
+
+	deletedAt := time.Now()
+	deleted := message
+	deleted.DeletedAt = &deletedAt
+
+	expected := map[string]struct {
+		isAdmin       bool
+		deleted       bool
+		getMessageErr error
+		pinErr        error
+		pinCalled     int
+		status        int
+	}{
+		"success": {
+			isAdmin:   true,
+			pinCalled: 1,
+			status:    200,
+		},
+		"forbidden (not an admin)": {
+			isAdmin: false,
+			status:  403,
+		},
+		"message not found": {
+			isAdmin:       true,
+			getMessageErr: assert.AnError,
+			status:        404,
+		},
+		"message deleted": {
+			isAdmin: true,
+			deleted: true,
+			status:  404,
+		},
+		"already pinned": {
+			isAdmin:   true,
+			pinErr:    service.ErrAlreadyPinned,
+			pinCalled: 1,
+			status:    409,
+		},
+		"pin limit reached": {
+			isAdmin:   true,
+			pinErr:    service.ErrPinLimitReached,
+			pinCalled: 1,
+			status:    409,
+		},
+		"failure to pin": {
+			isAdmin:   true,
+			pinErr:    assert.AnError,
+			pinCalled: 1,
+			status:    500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/message/:room_id/:message_id/pin", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", messageID.Hex())
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_admin", tt.isAdmin)
+			c.Set("room_model", model.Room{})
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			if tt.deleted {
+				messageSvcMock.On("GetMessage", messageID.Hex(), "test-room-id", mock.Anything).Return(deleted, tt.getMessageErr)
+			} else {
+				messageSvcMock.On("GetMessage", messageID.Hex(), "test-room-id", mock.Anything).Return(message, tt.getMessageErr)
+			}
+			pinSvcMock := new(svc_mock.PinSvcMock)
+			pinSvcMock.On("Pin", message, "test-uuid-1234", mock.Anything).Return(pin, tt.pinErr)
+
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), pinSvcMock, new(svc_mock.SlashCommandSvcMock), dto.NewMessageDtoStruct())
+			err := handler.Pin(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			pinSvcMock.AssertNumberOfCalls(t, "Pin", tt.pinCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string]dto.MessageResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, messageID.Hex(), result["message"].ID)
+			assert.True(t, result["message"].Pinned)
+			assert.Equal(t, "test-uuid-1234", result["message"].PinnedBy)
+			assert.Equal(t, pin.PinnedAt.UTC().Format(time.RFC3339), result["message"].PinnedAt)
+		})
+	}
+}
+
+func TestMessageUnpin(t *testing.T) {
+	messageID := primitive.NewObjectID()
+	message := model.Message{
+		ID:     messageID,
+		RoomID: "test-room-id",
+		Pin:    &model.MessagePin{PinnedBy: "moderator-uuid", PinnedAt: time.Now()},
+	}
+
+	expected := map[string]struct {
+		isAdmin       bool
+		getMessageErr error
+		unpinErr      error
+		unpinCalled   int
+		status        int
+	}{
+		"success": {
+			isAdmin:     true,
+			unpinCalled: 1,
+			status:      200,
+		},
+		"forbidden (not an admin)": {
+			isAdmin: false,
+			status:  403,
+		},
+		"message not found": {
+			isAdmin:       true,
+			getMessageErr: assert.AnError,
+			status:        404,
+		},
+		"not pinned": {
+			isAdmin:     true,
+			unpinErr:    service.ErrNotPinned,
+			unpinCalled: 1,
+			status:      404,
+		},
+		"failure to unpin": {
+			isAdmin:     true,
+			unpinErr:    assert.AnError,
+			unpinCalled: 1,
+			status:      500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/message/:room_id/:message_id/pin", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", messageID.Hex())
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_admin", tt.isAdmin)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetMessage", messageID.Hex(), "test-room-id", mock.Anything).Return(message, tt.getMessageErr)
+			pinSvcMock := new(svc_mock.PinSvcMock)
+			pinSvcMock.On("Unpin", message, mock.Anything).Return(tt.unpinErr)
+
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), pinSvcMock, new(svc_mock.SlashCommandSvcMock), dto.NewMessageDtoStruct())
+			err := handler.Unpin(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			pinSvcMock.AssertNumberOfCalls(t, "Unpin", tt.unpinCalled)
+		})
+	}
+}
+
+func TestMessagePins(t *testing.T) {
+	pinnedAt := time.Now()
+	messages := []model.Message{
+		{
+			ID:      primitive.NewObjectID(),
+			RoomID:  "test-room-id",
+			Sender:  "sender-uuid",
+			Message: "announcement",
+			Pin:     &model.MessagePin{PinnedBy: "moderator-uuid", PinnedAt: pinnedAt},
+		},
+	}
+
+	expected := map[string]struct {
+		isMember bool
+		messages []model.Message
+		getErr   error
+		status   int
+		count    int
+	}{
+		"success": {
+			isMember: true,
+			messages: messages,
+			status:   200,
+			count:    1,
+		},
+		"no pins": {
+			isMember: true,
+			messages: []model.Message{},
+			status:   200,
+			count:    0,
+		},
+		"forbidden (not a member)": {
+			isMember: false,
+			messages: messages,
+			status:   403,
+		},
+		"failure to get pins": {
+			isMember: true,
+			messages: []model.Message{},
+			getErr:   assert.AnError,
+			status:   500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/message/:room_id/pins", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", tt.isMember)
+			c.Set("room_model", model.Room{})
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetPinnedMessages", "test-room-id", mock.Anything).Return(tt.messages, tt.getErr)
+
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto.NewMessageDtoStruct())
+			err := handler.Pins(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string][]dto.MessageResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.NotNil(t, result["pins"])
+			assert.Len(t, result["pins"], tt.count)
+			if tt.count > 0 {
+				assert.Equal(t, "moderator-uuid", result["pins"][0].PinnedBy)
+				assert.Equal(t, pinnedAt.UTC().Format(time.RFC3339), result["pins"][0].PinnedAt)
+			}
+		})
+	}
+}
+
+func TestMessageDeleted(t *testing.T) {
+	deletedAt := time.Now()
+	messages := []model.Message{
+		{
+			ID:        primitive.NewObjectID(),
+			RoomID:    "test-room-id",
+			Sender:    "sender-uuid",
+			Message:   "deleted by mistake",
+			DeletedAt: &deletedAt,
+			DeletedBy: "sender-uuid",
+		},
+	}
+
+	expected := map[string]struct {
+		isAdmin bool
+		getErr  error
+		status  int
+		called  int
+	}{
+		"success": {
+			isAdmin: true,
+			status:  200,
+			called:  1,
+		},
+		"forbidden (not an admin)": {
+			isAdmin: false,
+			status:  403,
+		},
+		"failure to get deleted messages": {
+			isAdmin: true,
+			getErr:  assert.AnError,
+			status:  500,
+			called:  1,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/message/:room_id/deleted", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_admin", tt.isAdmin)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			// 猶予期間の始まり以降に削除されたものを取得する
+			messageSvcMock.On("GetDeletedMessages", "test-room-id", mock.MatchedBy(func(since time.Time) bool {
+				return time.Since(since) >= consts.MessageDeleteGracePeriod && time.Since(since) < consts.MessageDeleteGracePeriod+time.Minute
+			}), mock.Anything).Return(messages, tt.getErr)
+
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto.NewMessageDtoStruct())
+			err := handler.Deleted(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			messageSvcMock.AssertNumberOfCalls(t, "GetDeletedMessages", tt.called)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			// 管理者には削除前の本文を返す
+			result := map[string][]dto.MessageResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result["messages"], 1)
+			assert.Equal(t, "deleted by mistake", result["messages"][0].Message)
+			assert.True(t, result["messages"][0].Deleted)
+			assert.Equal(t, "sender-uuid", result["messages"][0].DeletedBy)
+		})
+	}
+}
+
+func TestMessageRestore(t *testing.T) {
+	messageID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		isAdmin    bool
+		restored   bool
+		restoreErr error
+		called     int
+		status     int
+	}{
+		"success": {
+			isAdmin:  true,
+			restored: true,
+			called:   1,
+			status:   200,
+		},
+		"forbidden (not an admin)": {
+			isAdmin: false,
+			status:  403,
+		},
+		"not deleted or grace period passed": {
+			isAdmin:  true,
+			restored: false,
+			called:   1,
+			status:   404,
+		},
+		"failure to restore": {
+			isAdmin:    true,
+			restoreErr: assert.AnError,
+			called:     1,
+			status:     500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/message/:room_id/:message_id/restore", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", messageID.Hex())
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_admin", tt.isAdmin)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("RestoreMessage", messageID.Hex(), "test-room-id", mock.MatchedBy(func(since time.Time) bool {
+				return time.Since(since) >= consts.MessageDeleteGracePeriod && time.Since(since) < consts.MessageDeleteGracePeriod+time.Minute
+			}), mock.Anything).Return(tt.restored, tt.restoreErr)
+
+			handler := NewMessageHandler(messageSvcMock, new(svc_mock.MessageSvcMock), new(mongo_svc_mock.ReportSvcMock), new(svc_mock.PinSvcMock), new(svc_mock.SlashCommandSvcMock), dto.NewMessageDtoStruct())
+			err := handler.Restore(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			messageSvcMock.AssertNumberOfCalls(t, "RestoreMessage", tt.called)
+		})
+	}
+}
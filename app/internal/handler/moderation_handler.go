@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type ModerationHandlerInterface interface {
+	Reports(c echo.Context) error
+	ReportDetail(c echo.Context) error
+	Resolve(c echo.Context) error
+}
+
+type ModerationHandler struct {
+	BaseHandler
+	reportSvc     mongo_svc.ReportSvcInterface
+	moderationSvc service.ModerationSvcInterface
+	reportDto     dto.ReportDtoInterface
+	messageDto    dto.MessageDtoInterface
+}
+
+func NewModerationHandler(
+	reportSvc mongo_svc.ReportSvcInterface,
+	moderationSvc service.ModerationSvcInterface,
+	reportDto dto.ReportDtoInterface,
+	messageDto dto.MessageDtoInterface,
+) *ModerationHandler {
+	return &ModerationHandler{
+		reportSvc:     reportSvc,
+		moderationSvc: moderationSvc,
+		reportDto:     reportDto,
+		messageDto:    messageDto,
+	}
+}
+
+func (h *ModerationHandler) Reports(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status == "" {
+		status = consts.ReportStatus.Open
+	}
+	if status != consts.ReportStatus.Open && status != consts.ReportStatus.Resolved {
+		return c.JSON(400, echo.Map{
+			"error": "invalid status",
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	reports, err := h.reportSvc.GetReportList(status, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"reports": h.reportDto.ResponseReportList(reports),
+	})
+}
+
+func (h *ModerationHandler) ReportDetail(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	uuid := h.GetUuid(c)
+
+	report, err := h.reportSvc.GetReport(c.Param("report_id"), ctx)
+	if err != nil {
+		return c.JSON(404, echo.Map{
+			"error": "report not found",
+		})
+	}
+
+	message, context, err := h.moderationSvc.GetReportContext(report, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// 猶予期間を過ぎてメッセージが物理削除されている場合は通報内容のみを返す
+		return c.JSON(200, echo.Map{
+			"report":   h.reportDto.GetReportInfo(report),
+			"message":  nil,
+			"messages": []dto.MessageResponse{},
+		})
+	}
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"report":   h.reportDto.GetReportInfo(report),
+		"message":  h.messageDto.GetModeratorMessageInfo(message, uuid),
+		"messages": h.messageDto.ResponseModeratorMessageList(context, uuid),
+	})
+}
+
+type ResolveReportRequest struct {
+	Action string `json:"action" form:"action" validate:"required,oneof=dismissed deleted user_banned"`
+	Note   string `json:"note" form:"note" validate:"max=1000"`
+}
+
+func (h *ModerationHandler) Resolve(c echo.Context) error {
+	var req ResolveReportRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	report, err := h.reportSvc.GetReport(c.Param("report_id"), ctx)
+	if err != nil {
+		return c.JSON(404, echo.Map{
+			"error": "report not found",
+		})
+	}
+
+	err = h.moderationSvc.Resolve(report, req.Action, h.GetUuid(c), req.Note, ctx)
+	if errors.Is(err, service.ErrReportAlreadyResolved) {
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newTestModerationHandler(
+	reportSvcMock *mongo_svc_mock.ReportSvcMock,
+	moderationSvcMock *svc_mock.ModerationSvcMock,
+) *ModerationHandler {
+	return NewModerationHandler(
+		reportSvcMock,
+		moderationSvcMock,
+		dto.NewReportDtoStruct(),
+		dto.NewMessageDtoStruct(),
+	)
+}
+
+func TestModerationReports(t *testing.T) {
+	expected := map[string]map[string]any{
+		"success (default open)": {
+			"status":       200,
+			"query":        "",
+			"queryStatus":  "open",
+			"called":       1,
+			"GetListError": nil,
+		},
+		"success (resolved)": {
+			"status":       200,
+			"query":        "?status=resolved",
+			"queryStatus":  "resolved",
+			"called":       1,
+			"GetListError": nil,
+		},
+		"invalid status": {
+			"status":       400,
+			"query":        "?status=unknown",
+			"queryStatus":  "unknown",
+			"called":       0,
+			"GetListError": nil,
+		},
+		"failure to get report list": {
+			"status":       500,
+			"query":        "",
+			"queryStatus":  "open",
+			"called":       1,
+			"GetListError": assert.AnError,
+		},
+	}
+
+	for name, expect := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/moderation/reports"+expect["query"].(string), nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "moderator-uuid")
+
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+			getListErr, _ := expect["GetListError"].(error)
+			reportSvcMock.
+				On("GetReportList", expect["queryStatus"].(string), mock.Anything).
+				Return([]model.Report{
+					{ID: primitive.NewObjectID(), Reason: "spam", Status: expect["queryStatus"].(string)},
+				}, getListErr)
+
+			handler := newTestModerationHandler(reportSvcMock, new(svc_mock.ModerationSvcMock))
+			err := handler.Reports(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expect["status"].(int), rec.Code)
+			reportSvcMock.AssertNumberOfCalls(t, "GetReportList", expect["called"].(int))
+
+			if expect["status"].(int) != http.StatusOK {
+				return
+			}
+
+			result := map[string][]map[string]interface{}{}
+			err = json.Unmarshal(rec.Body.Bytes(), &result)
+			assert.NoError(t, err)
+			assert.Len(t, result["reports"], 1)
+			assert.Equal(t, "spam", result["reports"][0]["Reason"])
+		})
+	}
+}
+
+func TestModerationReportDetail(t *testing.T) {
+	report := model.Report{
+		ID:        primitive.NewObjectID(),
+		RoomID:    "test-room-id",
+		MessageID: "test-message-id",
+		Status:    "open",
+	}
+	message := model.Message{
+		ID:        primitive.NewObjectID(),
+		RoomID:    "test-room-id",
+		Sender:    "reported-uuid",
+		Message:   "reported message",
+		CreatedAt: time.Now(),
+	}
+
+	expected := map[string]map[string]any{
+		"success": {
+			"status":         200,
+			"GetReportError": nil,
+			"ContextError":   nil,
+			"expectMessage":  true,
+			"expectContexts": 2,
+		},
+		"message soft deleted": {
+			"status":         200,
+			"GetReportError": nil,
+			"ContextError":   nil,
+			"deleted":        true,
+			"expectMessage":  true,
+			"expectContexts": 2,
+		},
+		"report not found": {
+			"status":         404,
+			"GetReportError": assert.AnError,
+			"ContextError":   nil,
+		},
+		"message already deleted": {
+			"status":         200,
+			"GetReportError": nil,
+			"ContextError":   mongo.ErrNoDocuments,
+			"expectMessage":  false,
+			"expectContexts": 0,
+		},
+		"failure to get context": {
+			"status":         500,
+			"GetReportError": nil,
+			"ContextError":   assert.AnError,
+		},
+	}
+
+	for name, expect := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/moderation/reports/:report_id", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("report_id")
+			c.SetParamValues(report.ID.Hex())
+			c.Set("uuid", "moderator-uuid")
+
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+			moderationSvcMock := new(svc_mock.ModerationSvcMock)
+
+			getReportErr, _ := expect["GetReportError"].(error)
+			contextErr, _ := expect["ContextError"].(error)
+			reportSvcMock.On("GetReport", report.ID.Hex(), mock.Anything).Return(report, getReportErr)
+			message := message
+			if deleted, _ := expect["deleted"].(bool); deleted {
+				deletedAt := time.Now()
+				message.DeletedAt = &deletedAt
+				message.DeletedBy = "reported-uuid"
+			}
+			moderationSvcMock.
+				On("GetReportContext", report, mock.Anything).
+				Return(message, []model.Message{message, {ID: primitive.NewObjectID()}}, contextErr)
+
+			handler := newTestModerationHandler(reportSvcMock, moderationSvcMock)
+			err := handler.ReportDetail(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expect["status"].(int), rec.Code)
+
+			if expect["status"].(int) != http.StatusOK {
+				return
+			}
+
+			result := map[string]interface{}{}
+			err = json.Unmarshal(rec.Body.Bytes(), &result)
+			assert.NoError(t, err)
+			assert.Equal(t, report.ID.Hex(), result["report"].(map[string]interface{})["ID"])
+			if expect["expectMessage"].(bool) {
+				// 削除されたメッセージでも、モデレーターには本文を返す
+				assert.Equal(t, "reported message", result["message"].(map[string]interface{})["Message"])
+				assert.Equal(t, expect["deleted"] == true, result["message"].(map[string]interface{})["Deleted"])
+			} else {
+				assert.Nil(t, result["message"])
+			}
+			assert.Len(t, result["messages"], expect["expectContexts"].(int))
+		})
+	}
+}
+
+func TestModerationResolve(t *testing.T) {
+	report := model.Report{
+		ID:     primitive.NewObjectID(),
+		Status: "open",
+	}
+
+	expected := map[string]map[string]any{
+		"success": {
+			"status":         200,
+			"body":           map[string]interface{}{"action": "deleted", "note": "spam"},
+			"GetReportError": nil,
+			"ResolveCalled":  1,
+			"ResolveError":   nil,
+		},
+		"validation error (missing action)": {
+			"status":         400,
+			"body":           map[string]interface{}{},
+			"GetReportError": nil,
+			"ResolveCalled":  0,
+			"ResolveError":   nil,
+		},
+		"validation error (invalid action)": {
+			"status":         400,
+			"body":           map[string]interface{}{"action": "ignored"},
+			"GetReportError": nil,
+			"ResolveCalled":  0,
+			"ResolveError":   nil,
+		},
+		"report not found": {
+			"status":         404,
+			"body":           map[string]interface{}{"action": "dismissed"},
+			"GetReportError": assert.AnError,
+			"ResolveCalled":  0,
+			"ResolveError":   nil,
+		},
+		"already resolved": {
+			"status":         409,
+			"body":           map[string]interface{}{"action": "dismissed"},
+			"GetReportError": nil,
+			"ResolveCalled":  1,
+			"ResolveError":   service.ErrReportAlreadyResolved,
+		},
+		"failure to resolve": {
+			"status":         500,
+			"body":           map[string]interface{}{"action": "user_banned"},
+			"GetReportError": nil,
+			"ResolveCalled":  1,
+			"ResolveError":   assert.AnError,
+		},
+	}
+
+	for name, expect := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+			body := expect["body"].(map[string]interface{})
+			jsonBody, _ := json.Marshal(body)
+			req := httptest.NewRequest(http.MethodPost, "/moderation/reports/:report_id/resolve", strings.NewReader(string(jsonBody)))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("report_id")
+			c.SetParamValues(report.ID.Hex())
+			c.Set("uuid", "moderator-uuid")
+
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+			moderationSvcMock := new(svc_mock.ModerationSvcMock)
+
+			getReportErr, _ := expect["GetReportError"].(error)
+			resolveErr, _ := expect["ResolveError"].(error)
+			reportSvcMock.On("GetReport", report.ID.Hex(), mock.Anything).Return(report, getReportErr)
+			moderationSvcMock.
+				On("Resolve", report, body["action"], "moderator-uuid", mock.Anything, mock.Anything).
+				Return(resolveErr)
+
+			handler := newTestModerationHandler(reportSvcMock, moderationSvcMock)
+			err := handler.Resolve(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, expect["status"].(int), rec.Code)
+			moderationSvcMock.AssertNumberOfCalls(t, "Resolve", expect["ResolveCalled"].(int))
+		})
+	}
+}
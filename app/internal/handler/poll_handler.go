@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type PollHandlerInterface interface {
+	Create(c echo.Context) error
+	Vote(c echo.Context) error
+	Close(c echo.Context) error
+}
+
+type PollHandler struct {
+	BaseHandler
+	messageSvc mongo_svc.MessageSvcInterface
+	pollSvc    service.PollSvcInterface
+	dto        dto.MessageDtoInterface
+}
+
+func NewPollHandler(
+	messageSvc mongo_svc.MessageSvcInterface,
+	pollSvc service.PollSvcInterface,
+	dto dto.MessageDtoInterface,
+) *PollHandler {
+	return &PollHandler{
+		messageSvc: messageSvc,
+		pollSvc:    pollSvc,
+		dto:        dto,
+	}
+}
+
+// closes_at は RFC3339 形式で指定する。省略した場合は手動で締め切るまで投票を受け付ける
+type CreatePollRequest struct {
+	Question       string     `json:"question" form:"question" validate:"required"`
+	Options        []string   `json:"options" form:"options" validate:"required"`
+	MultipleChoice bool       `json:"multiple_choice" form:"multiple_choice"`
+	Anonymous      bool       `json:"anonymous" form:"anonymous"`
+	ClosesAt       *time.Time `json:"closes_at" form:"closes_at"`
+}
+
+func (h *PollHandler) Create(c echo.Context) error {
+	var req CreatePollRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	messageID, err := h.pollSvc.Create(service.PollInput{
+		Question:       req.Question,
+		Options:        req.Options,
+		MultipleChoice: req.MultipleChoice,
+		Anonymous:      req.Anonymous,
+		ClosesAt:       req.ClosesAt,
+	}, h.GetRoomModel(c), h.GetUuid(c), h.messageBot(c), ctx)
+	if errors.Is(err, service.ErrPollInvalid) {
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, service.ErrSpamRejected) {
+		return c.JSON(422, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"message_id": messageID,
+	})
+}
+
+// 選んだ選択肢の番号（0 始まり）。投票し直すと前の投票を置き換える
+type VotePollRequest struct {
+	Options []int `json:"options" form:"options" validate:"required"`
+}
+
+func (h *PollHandler) Vote(c echo.Context) error {
+	var req VotePollRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	uuid := h.GetUuid(c)
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	message, err := h.messageSvc.GetMessage(c.Param("message_id"), c.Param("room_id"), ctx)
+	if err != nil || message.DeletedAt != nil {
+		return c.JSON(404, echo.Map{
+			"error": "message not found",
+		})
+	}
+
+	poll, err := h.pollSvc.Vote(message, uuid, req.Options, ctx)
+	if err != nil {
+		return h.pollError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"poll": h.dto.GetPollInfo(poll, uuid),
+	})
+}
+
+// 締め切れるのは投票を作成したユーザーとルームの管理者に限る
+func (h *PollHandler) Close(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	uuid := h.GetUuid(c)
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	message, err := h.messageSvc.GetMessage(c.Param("message_id"), c.Param("room_id"), ctx)
+	if err != nil || message.DeletedAt != nil {
+		return c.JSON(404, echo.Map{
+			"error": "message not found",
+		})
+	}
+
+	poll, err := h.pollSvc.Close(message, uuid, h.IsAdmin(c), ctx)
+	if err != nil {
+		return h.pollError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"poll": h.dto.GetPollInfo(poll, uuid),
+	})
+}
+
+func (h *PollHandler) pollError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotPoll):
+		return c.JSON(404, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrPollInvalidVote):
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrPollCloseNotAllowed):
+		return c.JSON(403, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrPollClosed):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(500, echo.Map{
+		"error": err.Error(),
+	})
+}
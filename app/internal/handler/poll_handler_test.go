@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPollCreate(t *testing.T) {
+	closesAt := time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC)
+	room := model.Room{ID: primitive.NewObjectID()}
+
+	expected := map[string]struct {
+		body        string
+		isMember    bool
+		createErr   error
+		expectInput *service.PollInput
+		status      int
+	}{
+		"success": {
+			body:     `{"question": "Lunch?", "options": ["Sushi", "Ramen"], "multiple_choice": true, "anonymous": true, "closes_at": "2025-01-02T09:00:00Z"}`,
+			isMember: true,
+			expectInput: &service.PollInput{
+				Question:       "Lunch?",
+				Options:        []string{"Sushi", "Ramen"},
+				MultipleChoice: true,
+				Anonymous:      true,
+				ClosesAt:       &closesAt,
+			},
+			status: 200,
+		},
+		"success (no close time)": {
+			body:        `{"question": "Lunch?", "options": ["Sushi", "Ramen"]}`,
+			isMember:    true,
+			expectInput: &service.PollInput{Question: "Lunch?", Options: []string{"Sushi", "Ramen"}},
+			status:      200,
+		},
+		"validation error": {
+			body:     `{"options": ["Sushi", "Ramen"]}`,
+			isMember: true,
+			status:   400,
+		},
+		"invalid close time": {
+			body:     `{"question": "Lunch?", "options": ["Sushi", "Ramen"], "closes_at": "tomorrow"}`,
+			isMember: true,
+			status:   400,
+		},
+		"forbidden (not a member)": {
+			body:   `{"question": "Lunch?", "options": ["Sushi", "Ramen"]}`,
+			status: 403,
+		},
+		"invalid poll": {
+			body:        `{"question": "Lunch?", "options": ["Sushi"]}`,
+			isMember:    true,
+			createErr:   service.ErrPollInvalid,
+			expectInput: &service.PollInput{Question: "Lunch?", Options: []string{"Sushi"}},
+			status:      400,
+		},
+		"rejected as spam": {
+			body:        `{"question": "Lunch?", "options": ["Sushi", "Ramen"]}`,
+			isMember:    true,
+			createErr:   service.ErrSpamRejected,
+			expectInput: &service.PollInput{Question: "Lunch?", Options: []string{"Sushi", "Ramen"}},
+			status:      422,
+		},
+		"failure to create": {
+			body:        `{"question": "Lunch?", "options": ["Sushi", "Ramen"]}`,
+			isMember:    true,
+			createErr:   assert.AnError,
+			expectInput: &service.PollInput{Question: "Lunch?", Options: []string{"Sushi", "Ramen"}},
+			status:      500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodPost, "/message/:room_id/polls", tt.body, false)
+			c.SetParamNames("room_id")
+			c.SetParamValues(room.ID.Hex())
+			c.Set("is_member", tt.isMember)
+			c.Set("room_model", room)
+
+			pollSvcMock := new(svc_mock.PollSvcMock)
+			pollSvcMock.On("Create", mock.Anything, room, "test-uuid-1234", (*model.MessageBot)(nil), mock.Anything).Return("message-id", tt.createErr)
+
+			handler := NewPollHandler(nil, pollSvcMock, dto.NewMessageDtoStruct())
+			err := handler.Create(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.expectInput == nil {
+				pollSvcMock.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			pollSvcMock.AssertCalled(t, "Create", *tt.expectInput, room, "test-uuid-1234", (*model.MessageBot)(nil), mock.Anything)
+
+			if tt.status == http.StatusOK {
+				assert.JSONEq(t, `{"message_id": "message-id"}`, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPollVote(t *testing.T) {
+	deletedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	poll := model.MessagePoll{
+		Question: "Lunch?",
+		Options:  []string{"Sushi", "Ramen"},
+		Votes:    []model.PollVote{{UserID: "test-uuid-1234", Options: []int{1}}},
+	}
+	message := model.Message{ID: primitive.NewObjectID(), RoomID: "test-room-id", Poll: &poll}
+	deleted := message
+	deleted.DeletedAt = &deletedAt
+
+	expected := map[string]struct {
+		body          string
+		isMember      bool
+		message       model.Message
+		getMessageErr error
+		voteErr       error
+		voteCalled    int
+		status        int
+	}{
+		"success":                  {body: `{"options": [1]}`, isMember: true, message: message, voteCalled: 1, status: 200},
+		"validation error":         {body: `{}`, isMember: true, message: message, status: 400},
+		"forbidden (not a member)": {body: `{"options": [1]}`, message: message, status: 403},
+		"message not found":        {body: `{"options": [1]}`, isMember: true, getMessageErr: assert.AnError, status: 404},
+		"message deleted":          {body: `{"options": [1]}`, isMember: true, message: deleted, status: 404},
+		"not a poll":               {body: `{"options": [1]}`, isMember: true, message: message, voteErr: service.ErrNotPoll, voteCalled: 1, status: 404},
+		"invalid vote":             {body: `{"options": [5]}`, isMember: true, message: message, voteErr: service.ErrPollInvalidVote, voteCalled: 1, status: 400},
+		"closed":                   {body: `{"options": [1]}`, isMember: true, message: message, voteErr: service.ErrPollClosed, voteCalled: 1, status: 409},
+		"failure to vote":          {body: `{"options": [1]}`, isMember: true, message: message, voteErr: assert.AnError, voteCalled: 1, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodPost, "/message/:room_id/:message_id/poll/vote", tt.body, false)
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", message.ID.Hex())
+			c.Set("is_member", tt.isMember)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetMessage", message.ID.Hex(), "test-room-id", mock.Anything).Return(tt.message, tt.getMessageErr)
+			pollSvcMock := new(svc_mock.PollSvcMock)
+			pollSvcMock.On("Vote", tt.message, "test-uuid-1234", mock.Anything, mock.Anything).Return(poll, tt.voteErr)
+
+			handler := NewPollHandler(messageSvcMock, pollSvcMock, dto.NewMessageDtoStruct())
+			err := handler.Vote(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			pollSvcMock.AssertNumberOfCalls(t, "Vote", tt.voteCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+			result := map[string]dto.PollResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, dto.NewMessageDtoStruct().GetPollInfo(poll, "test-uuid-1234"), result["poll"])
+			assert.Equal(t, []int{1}, result["poll"].MyVotes)
+		})
+	}
+}
+
+func TestPollClose(t *testing.T) {
+	closedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	poll := model.MessagePoll{Question: "Lunch?", Options: []string{"Sushi", "Ramen"}, ClosedAt: &closedAt, ClosedBy: "test-uuid-1234"}
+	message := model.Message{ID: primitive.NewObjectID(), RoomID: "test-room-id", Poll: &poll}
+	deleted := message
+	deleted.DeletedAt = &closedAt
+
+	expected := map[string]struct {
+		isMember      bool
+		isAdmin       bool
+		message       model.Message
+		getMessageErr error
+		closeErr      error
+		closeCalled   int
+		status        int
+	}{
+		"success":                  {isMember: true, message: message, closeCalled: 1, status: 200},
+		"success (admin)":          {isMember: true, isAdmin: true, message: message, closeCalled: 1, status: 200},
+		"forbidden (not a member)": {message: message, status: 403},
+		"message not found":        {isMember: true, getMessageErr: assert.AnError, status: 404},
+		"message deleted":          {isMember: true, message: deleted, status: 404},
+		"not a poll":               {isMember: true, message: message, closeErr: service.ErrNotPoll, closeCalled: 1, status: 404},
+		"not allowed":              {isMember: true, message: message, closeErr: service.ErrPollCloseNotAllowed, closeCalled: 1, status: 403},
+		"already closed":           {isMember: true, message: message, closeErr: service.ErrPollClosed, closeCalled: 1, status: 409},
+		"failure to close":         {isMember: true, message: message, closeErr: assert.AnError, closeCalled: 1, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodPost, "/message/:room_id/:message_id/poll/close", "", tt.isAdmin)
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", message.ID.Hex())
+			c.Set("is_member", tt.isMember)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetMessage", message.ID.Hex(), "test-room-id", mock.Anything).Return(tt.message, tt.getMessageErr)
+			pollSvcMock := new(svc_mock.PollSvcMock)
+			pollSvcMock.On("Close", tt.message, "test-uuid-1234", tt.isAdmin, mock.Anything).Return(poll, tt.closeErr)
+
+			handler := NewPollHandler(messageSvcMock, pollSvcMock, dto.NewMessageDtoStruct())
+			err := handler.Close(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			pollSvcMock.AssertNumberOfCalls(t, "Close", tt.closeCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+			result := map[string]dto.PollResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.True(t, result["poll"].Closed)
+			assert.Equal(t, "test-uuid-1234", result["poll"].ClosedBy)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type PresenceHandlerInterface interface {
+	Heartbeat(c echo.Context) error
+	SetStatus(c echo.Context) error
+}
+
+type PresenceHandler struct {
+	BaseHandler
+	presenceSvc service.PresenceSvcInterface
+}
+
+func NewPresenceHandler(
+	presenceSvc service.PresenceSvcInterface,
+) *PresenceHandler {
+	return &PresenceHandler{
+		presenceSvc: presenceSvc,
+	}
+}
+
+// 接続中であることを記録する（SSE で接続していないクライアント向け）
+// consts.PresenceTTL より短い間隔で呼び出している間はオンラインとして扱う
+func (h *PresenceHandler) Heartbeat(c echo.Context) error {
+	presence, err := h.presenceSvc.Heartbeat(h.GetUuid(c))
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"presence": presence,
+	})
+}
+
+type SetPresenceStatusRequest struct {
+	Status     string `json:"status" form:"status" validate:"required,oneof=online away"`
+	StatusText string `json:"status_text" form:"status_text" validate:"max=100"`
+}
+
+// 離席中かどうかとステータスのテキストを設定する。テキストを空にすると消える
+func (h *PresenceHandler) SetStatus(c echo.Context) error {
+	var req SetPresenceStatusRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	presence, err := h.presenceSvc.SetStatus(
+		h.GetUuid(c),
+		req.Status == consts.PresenceStatuses.Away,
+		strings.TrimSpace(req.StatusText),
+	)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"presence": presence,
+	})
+}
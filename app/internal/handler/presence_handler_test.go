@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresenceHeartbeat(t *testing.T) {
+	expected := map[string]struct {
+		err    error
+		status int
+	}{
+		"success":                  {status: 200},
+		"failure to record status": {err: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/presence/heartbeat", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+
+			presenceSvcMock := new(svc_mock.PresenceSvcMock)
+			presenceSvcMock.On("Heartbeat", "test-uuid-1234").Return(model.Presence{Status: "online"}, tt.err)
+
+			err := NewPresenceHandler(presenceSvcMock).Heartbeat(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == 200 {
+				result := map[string]model.Presence{}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+				assert.Equal(t, "online", result["presence"].Status)
+			}
+		})
+	}
+}
+
+func TestPresenceSetStatus(t *testing.T) {
+	expected := map[string]struct {
+		body       string
+		setCalled  int
+		away       bool
+		statusText string
+		setErr     error
+		status     int
+	}{
+		"away with text": {
+			body:       `{"status": "away", "status_text": "  In a meeting  "}`,
+			setCalled:  1,
+			away:       true,
+			statusText: "In a meeting",
+			status:     200,
+		},
+		"back online": {
+			body:      `{"status": "online"}`,
+			setCalled: 1,
+			away:      false,
+			status:    200,
+		},
+		"validation error (invalid status)": {
+			body:   `{"status": "offline"}`,
+			status: 400,
+		},
+		"validation error (text too long)": {
+			body:   `{"status": "online", "status_text": "` + strings.Repeat("あ", 101) + `"}`,
+			status: 400,
+		},
+		"failure to set status": {
+			body:      `{"status": "online"}`,
+			setCalled: 1,
+			setErr:    assert.AnError,
+			status:    500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+			req := httptest.NewRequest(http.MethodPut, "/presence/status", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+
+			presenceSvcMock := new(svc_mock.PresenceSvcMock)
+			presenceSvcMock.On("SetStatus", "test-uuid-1234", tt.away, tt.statusText).Return(model.Presence{Status: "away"}, tt.setErr)
+
+			err := NewPresenceHandler(presenceSvcMock).SetStatus(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			presenceSvcMock.AssertNumberOfCalls(t, "SetStatus", tt.setCalled)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabapi"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type ReceiptHandlerInterface interface {
+	List(c echo.Context) error
+}
+
+type ReceiptHandler struct {
+	BaseHandler
+	messageSvc mongo_svc.MessageSvcInterface
+	roomSvc    service.RoomSvcInterface
+	dto        dto.MessageDtoInterface
+}
+
+func NewReceiptHandler(
+	messageSvc mongo_svc.MessageSvcInterface,
+	roomSvc service.RoomSvcInterface,
+	dto dto.MessageDtoInterface,
+) *ReceiptHandler {
+	return &ReceiptHandler{
+		messageSvc: messageSvc,
+		roomSvc:    roomSvc,
+		dto:        dto,
+	}
+}
+
+// メッセージを読んだメンバーの名前と既読にした日時を返す
+// 既読の情報を送信者にだけ見せるルームでは、送信者以外には返さない
+func (h *ReceiptHandler) List(c echo.Context) error {
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	message, err := h.messageSvc.GetMessage(c.Param("message_id"), c.Param("room_id"), ctx)
+	if err != nil || message.DeletedAt != nil {
+		return c.JSON(404, echo.Map{
+			"error": "message not found",
+		})
+	}
+
+	room := h.GetRoomModel(c)
+	if room.HideReadReceipts && message.Sender != h.GetUuid(c) {
+		return c.JSON(403, echo.Map{
+			"error": "Read receipts are only visible to the sender in this room.",
+		})
+	}
+
+	apiCtx := atylabapi.NewApiCtxSvc()
+	defer apiCtx.Cancel()
+
+	members, err := h.roomSvc.GetMemberInfos(room, apiCtx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"receipts": h.dto.ResponseReadReceipts(message, members),
+	})
+}
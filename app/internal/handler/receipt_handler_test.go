@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReceiptList(t *testing.T) {
+	readAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := readAt.Add(time.Hour)
+	message := model.Message{
+		ID:            primitive.NewObjectID(),
+		RoomID:        "test-room-id",
+		Sender:        "sender-uuid",
+		IsReadUserIds: []string{"sender-uuid", "reader-uuid"},
+		ReadReceipts:  []model.ReadReceipt{{UserID: "reader-uuid", ReadAt: readAt}},
+	}
+	deleted := message
+	deleted.DeletedAt = &deletedAt
+	members := []model.RoomMember{
+		{Uuid: "sender-uuid", Name: "Sender"},
+		{Uuid: "reader-uuid", Name: "Reader"},
+	}
+
+	expected := map[string]struct {
+		uuid          string
+		isMember      bool
+		hide          bool
+		message       model.Message
+		getMessageErr error
+		membersErr    error
+		membersCalled int
+		status        int
+	}{
+		"success": {
+			uuid:          "reader-uuid",
+			isMember:      true,
+			message:       message,
+			membersCalled: 1,
+			status:        200,
+		},
+		"success (hidden, sender)": {
+			uuid:          "sender-uuid",
+			isMember:      true,
+			hide:          true,
+			message:       message,
+			membersCalled: 1,
+			status:        200,
+		},
+		"forbidden (hidden, not sender)": {
+			uuid:     "reader-uuid",
+			isMember: true,
+			hide:     true,
+			message:  message,
+			status:   403,
+		},
+		"forbidden (not a member)": {
+			uuid:     "reader-uuid",
+			isMember: false,
+			message:  message,
+			status:   403,
+		},
+		"message not found": {
+			uuid:          "reader-uuid",
+			isMember:      true,
+			message:       model.Message{},
+			getMessageErr: assert.AnError,
+			status:        404,
+		},
+		"message deleted": {
+			uuid:     "reader-uuid",
+			isMember: true,
+			message:  deleted,
+			status:   404,
+		},
+		"failure to get members": {
+			uuid:          "reader-uuid",
+			isMember:      true,
+			message:       message,
+			membersErr:    assert.AnError,
+			membersCalled: 1,
+			status:        500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/message/:room_id/:message_id/receipts", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", message.ID.Hex())
+			c.Set("uuid", tt.uuid)
+			c.Set("is_member", tt.isMember)
+			room := model.Room{Members: []string{"sender-uuid", "reader-uuid"}, HideReadReceipts: tt.hide}
+			c.Set("room_model", room)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetMessage", message.ID.Hex(), "test-room-id", mock.Anything).Return(tt.message, tt.getMessageErr)
+			roomSvcMock := new(svc_mock.RoomSvcMock)
+			roomSvcMock.On("GetMemberInfos", room, mock.Anything).Return(members, tt.membersErr)
+
+			handler := NewReceiptHandler(messageSvcMock, roomSvcMock, dto.NewMessageDtoStruct())
+			err := handler.List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			roomSvcMock.AssertNumberOfCalls(t, "GetMemberInfos", tt.membersCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string][]dto.ReadReceiptResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, []dto.ReadReceiptResponse{
+				{Uuid: "reader-uuid", Name: "Reader", ReadAt: "2025-01-01T00:00:00Z"},
+			}, result["receipts"])
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type ReminderHandlerInterface interface {
+	Create(c echo.Context) error
+	CreateForMessage(c echo.Context) error
+	List(c echo.Context) error
+	Snooze(c echo.Context) error
+	Cancel(c echo.Context) error
+}
+
+type ReminderHandler struct {
+	BaseHandler
+	mongoReminderSvc mongo_svc.ReminderSvcInterface
+	messageSvc       mongo_svc.MessageSvcInterface
+	reminderSvc      service.ReminderSvcInterface
+	dto              dto.ReminderDtoInterface
+}
+
+func NewReminderHandler(
+	mongoReminderSvc mongo_svc.ReminderSvcInterface,
+	messageSvc mongo_svc.MessageSvcInterface,
+	reminderSvc service.ReminderSvcInterface,
+	dto dto.ReminderDtoInterface,
+) *ReminderHandler {
+	return &ReminderHandler{
+		mongoReminderSvc: mongoReminderSvc,
+		messageSvc:       messageSvc,
+		reminderSvc:      reminderSvc,
+		dto:              dto,
+	}
+}
+
+// 通知日時は remind_at（RFC3339 形式）か in（"30m" や "2h"、"3d" など現在からの期間）のどちらか一方で指定する
+type ReminderTimeRequest struct {
+	RemindAt *time.Time `json:"remind_at" form:"remind_at"`
+	In       string     `json:"in" form:"in"`
+}
+
+func (r ReminderTimeRequest) reminderTime() service.ReminderTime {
+	return service.ReminderTime{At: r.RemindAt, In: r.In}
+}
+
+// メッセージに設定する場合は text を省略できる
+type CreateReminderRequest struct {
+	ReminderTimeRequest
+	Text string `json:"text" form:"text"`
+}
+
+// 自由入力のリマインダーを設定する
+func (h *ReminderHandler) Create(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	var req CreateReminderRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	reminder, err := h.reminderSvc.Create(model.Reminder{
+		UserID: h.GetUuid(c),
+		Text:   req.Text,
+	}, req.reminderTime(), ctx)
+	if err != nil {
+		return h.reminderError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"reminder": h.dto.GetReminderInfo(reminder),
+	})
+}
+
+// メッセージにリマインダーを設定する。通知には設定した時点の本文を添える
+func (h *ReminderHandler) CreateForMessage(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	var req CreateReminderRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	message, err := h.messageSvc.GetMessage(c.Param("message_id"), c.Param("room_id"), ctx)
+	if err != nil || message.DeletedAt != nil {
+		return c.JSON(404, echo.Map{
+			"error": "message not found",
+		})
+	}
+
+	reminder, err := h.reminderSvc.Create(model.Reminder{
+		UserID:    h.GetUuid(c),
+		RoomID:    message.RoomID,
+		MessageID: message.ID.Hex(),
+		Snapshot:  message.Message,
+		Text:      req.Text,
+	}, req.reminderTime(), ctx)
+	if err != nil {
+		return h.reminderError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"reminder": h.dto.GetReminderInfo(reminder),
+	})
+}
+
+// 自分の通知前のリマインダーを、通知日時の早い順で返す
+func (h *ReminderHandler) List(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	reminders, err := h.mongoReminderSvc.GetReminders(h.GetUuid(c), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"reminders": h.dto.ResponseReminderList(reminders),
+	})
+}
+
+// 通知日時を変更する。通知済みのリマインダーも、もう一度通知するよう設定し直せる
+func (h *ReminderHandler) Snooze(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	var req ReminderTimeRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	reminder, ok := h.findReminder(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	reminder, err := h.reminderSvc.Snooze(reminder, req.reminderTime(), ctx)
+	if err != nil {
+		return h.reminderError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"reminder": h.dto.GetReminderInfo(reminder),
+	})
+}
+
+func (h *ReminderHandler) Cancel(c echo.Context) error {
+	if _, ok := h.GetBot(c); ok {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	reminder, ok := h.findReminder(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	if err := h.reminderSvc.Cancel(reminder, ctx); err != nil {
+		return h.reminderError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// 他人のリマインダーは存在しないものとして扱う
+func (h *ReminderHandler) findReminder(c echo.Context, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, bool) {
+	reminder, err := h.mongoReminderSvc.GetReminder(c.Param("reminder_id"), h.GetUuid(c), ctx)
+	if err != nil {
+		return model.Reminder{}, false
+	}
+	return reminder, true
+}
+
+func (h *ReminderHandler) reminderError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidRemindAt), errors.Is(err, service.ErrReminderInvalid):
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrReminderLimitReached),
+		errors.Is(err, service.ErrReminderNotPending),
+		errors.Is(err, service.ErrReminderNotSnoozable):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(500, echo.Map{
+		"error": err.Error(),
+	})
+}
+
+func (h *ReminderHandler) forbidden(c echo.Context) error {
+	return c.JSON(403, echo.Map{
+		"error": "Bots cannot use reminders",
+	})
+}
+
+func (h *ReminderHandler) notFound(c echo.Context) error {
+	return c.JSON(404, echo.Map{
+		"error": "reminder not found",
+	})
+}
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReminderCreate(t *testing.T) {
+	remindAt := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	expected := map[string]struct {
+		body         string
+		asBot        bool
+		text         string
+		when         service.ReminderTime
+		createErr    error
+		createCalled int
+		status       int
+	}{
+		"success (in)": {
+			text:         "call Bob",
+			body:         `{"text": "call Bob", "in": "2h"}`,
+			when:         service.ReminderTime{In: "2h"},
+			createCalled: 1,
+			status:       200,
+		},
+		"success (remind_at)": {
+			text:         "call Bob",
+			body:         `{"text": "call Bob", "remind_at": "2030-01-02T09:00:00Z"}`,
+			when:         service.ReminderTime{At: &remindAt},
+			createCalled: 1,
+			status:       200,
+		},
+		"validation error (invalid remind_at)": {
+			body:   `{"text": "call Bob", "remind_at": "tomorrow"}`,
+			status: 400,
+		},
+		"forbidden (bot)": {
+			body:   `{"text": "call Bob", "in": "2h"}`,
+			asBot:  true,
+			status: 403,
+		},
+		"invalid time": {
+			text:         "call Bob",
+			body:         `{"text": "call Bob", "in": "soon"}`,
+			when:         service.ReminderTime{In: "soon"},
+			createErr:    service.ErrInvalidRemindAt,
+			createCalled: 1,
+			status:       400,
+		},
+		"missing text": {
+			body:         `{"in": "2h"}`,
+			when:         service.ReminderTime{In: "2h"},
+			createErr:    service.ErrReminderInvalid,
+			createCalled: 1,
+			status:       400,
+		},
+		"limit reached": {
+			text:         "call Bob",
+			body:         `{"text": "call Bob", "in": "2h"}`,
+			when:         service.ReminderTime{In: "2h"},
+			createErr:    service.ErrReminderLimitReached,
+			createCalled: 1,
+			status:       409,
+		},
+		"failure to create": {
+			text:         "call Bob",
+			body:         `{"text": "call Bob", "in": "2h"}`,
+			when:         service.ReminderTime{In: "2h"},
+			createErr:    assert.AnError,
+			createCalled: 1,
+			status:       500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodPost, "/reminders", tt.body, tt.asBot)
+
+			created := model.Reminder{ID: primitive.NewObjectID(), UserID: "test-uuid-1234", Text: tt.text, RemindAt: remindAt, Status: "pending"}
+			reminderSvcMock := new(svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("Create", model.Reminder{UserID: "test-uuid-1234", Text: tt.text}, tt.when, mock.Anything).Return(created, tt.createErr)
+
+			handler := NewReminderHandler(new(mongo_svc_mock.ReminderSvcMock), new(mongo_svc_mock.MessageSvcMock), reminderSvcMock, dto.NewReminderDtoStruct())
+			err := handler.Create(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			reminderSvcMock.AssertNumberOfCalls(t, "Create", tt.createCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string]dto.ReminderResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, created.ID.Hex(), result["reminder"].ID)
+			assert.Equal(t, "2030-01-02T09:00:00Z", result["reminder"].RemindAt)
+		})
+	}
+}
+
+func TestReminderCreateForMessage(t *testing.T) {
+	messageID := primitive.NewObjectID()
+	deletedAt := time.Now()
+	message := model.Message{ID: messageID, RoomID: "test-room-id", Sender: "sender-uuid", Message: "Can you review this?"}
+
+	expected := map[string]struct {
+		body         string
+		asBot        bool
+		isMember     bool
+		message      model.Message
+		getErr       error
+		createErr    error
+		createCalled int
+		status       int
+	}{
+		"success": {
+			body:         `{"in": "2h"}`,
+			isMember:     true,
+			message:      message,
+			createCalled: 1,
+			status:       200,
+		},
+		"forbidden (bot)": {
+			body:     `{"in": "2h"}`,
+			asBot:    true,
+			isMember: true,
+			message:  message,
+			status:   403,
+		},
+		"forbidden (not a member)": {
+			body:    `{"in": "2h"}`,
+			message: message,
+			status:  403,
+		},
+		"message not found": {
+			body:     `{"in": "2h"}`,
+			isMember: true,
+			getErr:   assert.AnError,
+			status:   404,
+		},
+		"deleted message": {
+			body:     `{"in": "2h"}`,
+			isMember: true,
+			message:  model.Message{ID: messageID, RoomID: "test-room-id", DeletedAt: &deletedAt},
+			status:   404,
+		},
+		"limit reached": {
+			body:         `{"in": "2h"}`,
+			isMember:     true,
+			message:      message,
+			createErr:    service.ErrReminderLimitReached,
+			createCalled: 1,
+			status:       409,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodPost, "/message/:room_id/:message_id/reminders", tt.body, tt.asBot)
+			c.Set("is_member", tt.isMember)
+			c.SetParamNames("room_id", "message_id")
+			c.SetParamValues("test-room-id", messageID.Hex())
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetMessage", messageID.Hex(), "test-room-id", mock.Anything).Return(tt.message, tt.getErr)
+			reminderSvcMock := new(svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("Create", model.Reminder{
+				UserID:    "test-uuid-1234",
+				RoomID:    "test-room-id",
+				MessageID: messageID.Hex(),
+				Snapshot:  "Can you review this?",
+			}, service.ReminderTime{In: "2h"}, mock.Anything).Return(model.Reminder{ID: primitive.NewObjectID(), MessageID: messageID.Hex()}, tt.createErr)
+
+			handler := NewReminderHandler(new(mongo_svc_mock.ReminderSvcMock), messageSvcMock, reminderSvcMock, dto.NewReminderDtoStruct())
+			err := handler.CreateForMessage(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			reminderSvcMock.AssertNumberOfCalls(t, "Create", tt.createCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string]dto.ReminderResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, messageID.Hex(), result["reminder"].MessageID)
+		})
+	}
+}
+
+func TestReminderList(t *testing.T) {
+	reminders := []model.Reminder{
+		{ID: primitive.NewObjectID(), Text: "first", RemindAt: time.Now().Add(time.Hour)},
+		{ID: primitive.NewObjectID(), Text: "second", RemindAt: time.Now().Add(2 * time.Hour)},
+	}
+
+	expected := map[string]struct {
+		asBot  bool
+		getErr error
+		status int
+	}{
+		"success":                  {status: 200},
+		"forbidden (bot)":          {asBot: true, status: 403},
+		"failure to get reminders": {getErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodGet, "/reminders", "", tt.asBot)
+
+			mongoReminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			mongoReminderSvcMock.On("GetReminders", "test-uuid-1234", mock.Anything).Return(reminders, tt.getErr)
+
+			handler := NewReminderHandler(mongoReminderSvcMock, new(mongo_svc_mock.MessageSvcMock), new(svc_mock.ReminderSvcMock), dto.NewReminderDtoStruct())
+			err := handler.List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string][]dto.ReminderResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result["reminders"], 2)
+			assert.Equal(t, "first", result["reminders"][0].Text)
+		})
+	}
+}
+
+func TestReminderSnooze(t *testing.T) {
+	reminderID := primitive.NewObjectID()
+	reminder := model.Reminder{ID: reminderID, UserID: "test-uuid-1234", Text: "call Bob", Status: "sent"}
+
+	expected := map[string]struct {
+		body         string
+		asBot        bool
+		getErr       error
+		snoozeErr    error
+		snoozeCalled int
+		status       int
+	}{
+		"success": {
+			body:         `{"in": "1h"}`,
+			snoozeCalled: 1,
+			status:       200,
+		},
+		"forbidden (bot)": {
+			body:   `{"in": "1h"}`,
+			asBot:  true,
+			status: 403,
+		},
+		"not found (or someone else's)": {
+			body:   `{"in": "1h"}`,
+			getErr: assert.AnError,
+			status: 404,
+		},
+		"invalid time": {
+			body:         `{"in": "1h"}`,
+			snoozeErr:    service.ErrInvalidRemindAt,
+			snoozeCalled: 1,
+			status:       400,
+		},
+		"canceled": {
+			body:         `{"in": "1h"}`,
+			snoozeErr:    service.ErrReminderNotSnoozable,
+			snoozeCalled: 1,
+			status:       409,
+		},
+		"failure to snooze": {
+			body:         `{"in": "1h"}`,
+			snoozeErr:    assert.AnError,
+			snoozeCalled: 1,
+			status:       500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodPost, "/reminders/:reminder_id/snooze", tt.body, tt.asBot)
+			c.SetParamNames("reminder_id")
+			c.SetParamValues(reminderID.Hex())
+
+			mongoReminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			mongoReminderSvcMock.On("GetReminder", reminderID.Hex(), "test-uuid-1234", mock.Anything).Return(reminder, tt.getErr)
+			snoozed := reminder
+			snoozed.Status = "pending"
+			reminderSvcMock := new(svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("Snooze", reminder, service.ReminderTime{In: "1h"}, mock.Anything).Return(snoozed, tt.snoozeErr)
+
+			handler := NewReminderHandler(mongoReminderSvcMock, new(mongo_svc_mock.MessageSvcMock), reminderSvcMock, dto.NewReminderDtoStruct())
+			err := handler.Snooze(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			reminderSvcMock.AssertNumberOfCalls(t, "Snooze", tt.snoozeCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string]dto.ReminderResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, "pending", result["reminder"].Status)
+		})
+	}
+}
+
+func TestReminderCancel(t *testing.T) {
+	reminderID := primitive.NewObjectID()
+	reminder := model.Reminder{ID: reminderID, UserID: "test-uuid-1234", Text: "call Bob", Status: "pending"}
+
+	expected := map[string]struct {
+		asBot        bool
+		getErr       error
+		cancelErr    error
+		cancelCalled int
+		status       int
+	}{
+		"success":                       {cancelCalled: 1, status: 200},
+		"forbidden (bot)":               {asBot: true, status: 403},
+		"not found (or someone else's)": {getErr: assert.AnError, status: 404},
+		"already delivered":             {cancelErr: service.ErrReminderNotPending, cancelCalled: 1, status: 409},
+		"failure to cancel":             {cancelErr: assert.AnError, cancelCalled: 1, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newBotContext(http.MethodDelete, "/reminders/:reminder_id", "", tt.asBot)
+			c.SetParamNames("reminder_id")
+			c.SetParamValues(reminderID.Hex())
+
+			mongoReminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			mongoReminderSvcMock.On("GetReminder", reminderID.Hex(), "test-uuid-1234", mock.Anything).Return(reminder, tt.getErr)
+			reminderSvcMock := new(svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("Cancel", reminder, mock.Anything).Return(tt.cancelErr)
+
+			handler := NewReminderHandler(mongoReminderSvcMock, new(mongo_svc_mock.MessageSvcMock), reminderSvcMock, dto.NewReminderDtoStruct())
+			err := handler.Cancel(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			reminderSvcMock.AssertNumberOfCalls(t, "Cancel", tt.cancelCalled)
+		})
+	}
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"time"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
@@ -22,23 +24,39 @@ type RoomHandlerInterface interface {
 	Delete(c echo.Context) error
 	AddMember(c echo.Context) error
 	RemoveMember(c echo.Context) error
+	SetMessageTTL(c echo.Context) error
+	SetRetention(c echo.Context) error
+	SetReadReceipts(c echo.Context) error
+	Export(c echo.Context) error
 }
 
 type RoomHandler struct {
 	BaseHandler
 	mongoRoomSvc mongo_svc.RoomSvcInterface
 	roomSvc      service.RoomSvcInterface
+	exportSvc    service.ExportSvcInterface
+	presenceSvc  service.PresenceSvcInterface
+	webhookSvc   service.WebhookSvcInterface
+	eventSvc     service.EventSvcInterface
 	dto          dto.RoomDtoInterface
 }
 
 func NewRoomHandler(
 	mongoRoomSvc mongo_svc.RoomSvcInterface,
 	roomSvc service.RoomSvcInterface,
+	exportSvc service.ExportSvcInterface,
+	presenceSvc service.PresenceSvcInterface,
+	webhookSvc service.WebhookSvcInterface,
+	eventSvc service.EventSvcInterface,
 	dto dto.RoomDtoInterface,
 ) *RoomHandler {
 	return &RoomHandler{
 		mongoRoomSvc: mongoRoomSvc,
 		roomSvc:      roomSvc,
+		exportSvc:    exportSvc,
+		presenceSvc:  presenceSvc,
+		webhookSvc:   webhookSvc,
+		eventSvc:     eventSvc,
 		dto:          dto,
 	}
 }
@@ -132,6 +150,12 @@ func (h *RoomHandler) Join(c echo.Context) error {
 		})
 	}
 
+	if h.roomSvc.IsBanned(h.GetRoomModel(c), h.GetUuid(c)) {
+		return c.JSON(403, echo.Map{
+			"error": "You are banned from this room",
+		})
+	}
+
 	err = h.mongoRoomSvc.JoinRoom(req.RoomID, h.GetUuid(c), ctx)
 	if err != nil {
 		return c.JSON(500, echo.Map{
@@ -139,6 +163,12 @@ func (h *RoomHandler) Join(c echo.Context) error {
 		})
 	}
 
+	member := service.WebhookMemberData{
+		UserID: h.GetUuid(c),
+	}
+	h.webhookSvc.Dispatch(req.RoomID, consts.WebhookEvents.MemberJoined, member)
+	h.publishMember(consts.EventTypes.MemberJoined, req.RoomID, member)
+
 	return c.JSON(200, echo.Map{
 		"message": "Joined room successfully",
 	})
@@ -163,6 +193,18 @@ func (h *RoomHandler) Members(c echo.Context) error {
 		})
 	}
 
+	// オンライン状態を取得できなくても、メンバーの一覧は返す
+	presences, err := h.presenceSvc.GetPresences(room.Members)
+	if err != nil {
+		fmt.Println("Failed to get presences:", err)
+	} else {
+		for i := range members {
+			if presence, ok := presences[members[i].Uuid]; ok {
+				members[i].Presence = &presence
+			}
+		}
+	}
+
 	return c.JSON(200, echo.Map{
 		"members": members,
 	})
@@ -195,6 +237,12 @@ func (h *RoomHandler) Leave(c echo.Context) error {
 		})
 	}
 
+	member := service.WebhookMemberData{
+		UserID: uuid,
+	}
+	h.webhookSvc.Dispatch(roomID, consts.WebhookEvents.MemberLeft, member)
+	h.publishMember(consts.EventTypes.MemberLeft, roomID, member)
+
 	return c.JSON(200, echo.Map{
 		"message": "left room",
 	})
@@ -219,6 +267,12 @@ func (h *RoomHandler) Delete(c echo.Context) error {
 		})
 	}
 
+	h.webhookSvc.Dispatch(roomID, consts.WebhookEvents.RoomDeleted, nil)
+	h.publish(service.RoomEvent{
+		Type:   consts.EventTypes.RoomDeleted,
+		RoomID: roomID,
+	})
+
 	return c.JSON(200, echo.Map{
 		"message": "room deleted",
 	})
@@ -255,6 +309,13 @@ func (h *RoomHandler) AddMember(c echo.Context) error {
 		})
 	}
 
+	member := service.WebhookMemberData{
+		UserID:  req.MemberID,
+		ActorID: h.GetUuid(c),
+	}
+	h.webhookSvc.Dispatch(roomID, consts.WebhookEvents.MemberJoined, member)
+	h.publishMember(consts.EventTypes.MemberJoined, roomID, member)
+
 	return c.JSON(200, echo.Map{
 		"message": "member added",
 	})
@@ -291,7 +352,211 @@ func (h *RoomHandler) RemoveMember(c echo.Context) error {
 		})
 	}
 
+	member := service.WebhookMemberData{
+		UserID:  req.MemberID,
+		ActorID: h.GetUuid(c),
+	}
+	h.webhookSvc.Dispatch(roomID, consts.WebhookEvents.MemberLeft, member)
+	h.publishMember(consts.EventTypes.MemberLeft, roomID, member)
+
 	return c.JSON(200, echo.Map{
 		"message": "member removed",
 	})
 }
+
+type SetMessageTTLRequest struct {
+	// 既定の有効期間（秒）。0 を指定すると期限なしに戻す
+	MessageTTL *int `json:"message_ttl" form:"message_ttl" validate:"required"`
+}
+
+// ルームのメッセージの既定の有効期間を設定する
+// 設定後に送信されたメッセージから適用し、送信済みのメッセージの期限は変えない
+func (h *RoomHandler) SetMessageTTL(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return c.JSON(403, echo.Map{
+			"error": "Only admin can change the message lifetime",
+		})
+	}
+
+	var req SetMessageTTLRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if !service.ValidMessageTTL(*req.MessageTTL) {
+		return c.JSON(400, echo.Map{
+			"error": fmt.Sprintf("message_ttl must be 0 or between %d and %d seconds", consts.MessageTTLMinSeconds, consts.MessageTTLMaxSeconds),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	err := h.mongoRoomSvc.SetMessageTTL(c.Param("room_id"), *req.MessageTTL, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"message_ttl": *req.MessageTTL,
+	})
+}
+
+type SetRetentionRequest struct {
+	// 保存日数。0 を指定すると無期限に保存する
+	RetentionDays *int `json:"retention_days" form:"retention_days" validate:"required"`
+}
+
+// ルームのメッセージの保存日数を設定する
+// 期間を過ぎたメッセージは retention-purge コマンドの実行時に削除（またはアーカイブ）される
+func (h *RoomHandler) SetRetention(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return c.JSON(403, echo.Map{
+			"error": "Only admin can change the retention period",
+		})
+	}
+
+	var req SetRetentionRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if *req.RetentionDays < 0 || *req.RetentionDays > consts.RetentionMaxDays {
+		return c.JSON(400, echo.Map{
+			"error": fmt.Sprintf("retention_days must be between 0 and %d", consts.RetentionMaxDays),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	err := h.mongoRoomSvc.SetRetentionDays(c.Param("room_id"), *req.RetentionDays, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"retention_days": *req.RetentionDays,
+	})
+}
+
+type SetReadReceiptsRequest struct {
+	// true を指定すると、既読の情報をメッセージの送信者にだけ見せる
+	Hide *bool `json:"hide" form:"hide" validate:"required"`
+}
+
+// 既読の情報（誰がいつ読んだか）を送信者以外に見せるかどうかを設定する
+func (h *RoomHandler) SetReadReceipts(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return c.JSON(403, echo.Map{
+			"error": "Only admin can change the read receipt setting",
+		})
+	}
+
+	var req SetReadReceiptsRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	err := h.mongoRoomSvc.SetHideReadReceipts(c.Param("room_id"), *req.Hide, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"hide_read_receipts": *req.Hide,
+	})
+}
+
+// ルームの全メッセージを format（json, csv, txt, html）で指定した形式でダウンロードさせる
+// 日時は tz で指定したタイムゾーン（既定は UTC）に変換する
+// 件数が多くても全件をメモリに載せないよう、カーソルから読みながらレスポンスに書き出す
+func (h *RoomHandler) Export(c echo.Context) error {
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "Only members can export the room",
+		})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = consts.ExportFormats.JSON
+	}
+	contentType, err := h.exportSvc.ContentType(format)
+	if err != nil {
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	tz := c.QueryParam("tz")
+	if tz == "" {
+		tz = consts.ExportDefaultTimezone
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return c.JSON(400, echo.Map{
+			"error": "invalid timezone: " + tz,
+		})
+	}
+
+	room := h.GetRoomModel(c)
+
+	// クライアントが切断した場合はカーソルを閉じて書き出しを止める
+	cctx, cancel := context.WithTimeout(c.Request().Context(), consts.ExportTimeout)
+	ctx := &atylabmongo.MongoCtxSvc{Ctx: cctx, Cancel: cancel}
+	defer ctx.Cancel()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="room-%s.%s"`, room.ID.Hex(), format))
+
+	count, err := h.exportSvc.Export(res, room, format, loc, ctx)
+	if err != nil {
+		if !res.Committed {
+			res.Header().Del(echo.HeaderContentDisposition)
+			return c.JSON(500, echo.Map{
+				"error": err.Error(),
+			})
+		}
+		// 書き出しを始めた後はステータスを変えられないので、途中で打ち切る
+		fmt.Println("Failed to export room:", err)
+		return nil
+	}
+
+	fmt.Println("Exported", count, "messages from room", room.ID.Hex())
+	return nil
+}
+
+// 参加・退出したユーザーの接続が、ルームの一覧を読み直さなくてもルームのイベントを受け取れる（受け取らなくなる）ようにする
+func (h *RoomHandler) publishMember(eventType string, roomID string, member service.WebhookMemberData) {
+	h.publish(service.RoomEvent{
+		Type:   eventType,
+		RoomID: roomID,
+		Data:   member,
+		Member: member.UserID,
+	})
+}
+
+// イベントを記録できなくても、ルームの操作は成功として扱う
+func (h *RoomHandler) publish(event service.RoomEvent) {
+	if _, err := h.eventSvc.Publish(event); err != nil {
+		fmt.Println("Failed to publish room event:", err)
+	}
+}
@@ -3,14 +3,17 @@ package handler
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
@@ -100,7 +103,7 @@ func TestRoomList(t *testing.T) {
 
 			mongoSvcMock.On("GetRoomList", "test-uuid-1234", expect["expect_target"].(string), mock.Anything).Return(returnData, returnErr).Times(expect["GetRoomListCalled"].(int))
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), new(svc_mock.WebhookSvcMock), new(svc_mock.EventSvcMock), dto)
 			err = handler.List(c)
 
 			if err != nil {
@@ -196,7 +199,7 @@ func TestRoomCreate(t *testing.T) {
 				mongoSvcMock.On("CreateRoom", mock.AnythingOfType("model.Room"), mock.Anything).Return(roomId, returnErr).Times(expect["createRoomCalled"].(int))
 			}
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), new(svc_mock.WebhookSvcMock), new(svc_mock.EventSvcMock), dto)
 			err := handler.Create(c)
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
@@ -255,6 +258,14 @@ func TestRoomJoin(t *testing.T) {
 			"JoinRoomSuccess": false,
 			"is_member":       false,
 		},
+		"banned from room": {
+			"status":          403,
+			"body":            map[string]interface{}{"room_id": "existing-room-id-1234"},
+			"JoinRoomCalled":  0,
+			"JoinRoomSuccess": false,
+			"is_member":       false,
+			"is_banned":       true,
+		},
 	}
 
 	for name, expect := range expected {
@@ -288,6 +299,9 @@ func TestRoomJoin(t *testing.T) {
 			mongoSvcMock := new(mongo_svc_mock.RoomSvcMock)
 			roomSvcMock := new(svc_mock.RoomSvcMock)
 
+			isBanned, _ := expect["is_banned"].(bool)
+			roomSvcMock.On("IsBanned", room, "test-uuid-1234").Return(isBanned)
+
 			if expect["JoinRoomCalled"].(int) != 0 {
 				var returnErr error = nil
 				if !expect["JoinRoomSuccess"].(bool) {
@@ -296,7 +310,18 @@ func TestRoomJoin(t *testing.T) {
 				mongoSvcMock.On("JoinRoom", "existing-room-id-1234", "test-uuid-1234", mock.Anything).Return(returnErr).Times(expect["JoinRoomCalled"].(int))
 			}
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			webhookSvcMock := new(svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("Dispatch", mock.Anything, consts.WebhookEvents.MemberJoined, mock.Anything).Return()
+			event := service.RoomEvent{
+				Type:   consts.EventTypes.MemberJoined,
+				RoomID: "existing-room-id-1234",
+				Data:   service.WebhookMemberData{UserID: "test-uuid-1234"},
+				Member: "test-uuid-1234",
+			}
+			eventSvcMock := new(svc_mock.EventSvcMock)
+			eventSvcMock.On("Publish", event).Return("1-0", nil)
+
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), webhookSvcMock, eventSvcMock, dto)
 			err := handler.Join(c)
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
@@ -304,6 +329,15 @@ func TestRoomJoin(t *testing.T) {
 
 			assert.Equal(t, expect["status"].(int), rec.Code)
 
+			// 成功した場合だけルームのメンバーと Webhook に配信する
+			if expect["status"].(int) == http.StatusOK {
+				webhookSvcMock.AssertNumberOfCalls(t, "Dispatch", 1)
+				eventSvcMock.AssertCalled(t, "Publish", event)
+			} else {
+				webhookSvcMock.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything, mock.Anything)
+				eventSvcMock.AssertNotCalled(t, "Publish", mock.Anything)
+			}
+
 			if expect["JoinRoomCalled"].(int) != 0 {
 				mongoSvcMock.AssertExpectations(t)
 			} else {
@@ -316,19 +350,28 @@ func TestRoomJoin(t *testing.T) {
 func TestRoomMembers(t *testing.T) {
 	expected := map[string]map[string]any{
 		"success": {
-			"status":   200,
-			"error":    nil,
-			"IsMember": true,
+			"status":      200,
+			"error":       nil,
+			"IsMember":    true,
+			"presenceErr": nil,
+		},
+		"success without presence": {
+			"status":      200,
+			"error":       nil,
+			"IsMember":    true,
+			"presenceErr": fmt.Errorf("GetPresences error"),
 		},
 		"validation error (not a member)": {
-			"status":   400,
-			"error":    nil,
-			"IsMember": false,
+			"status":      400,
+			"error":       nil,
+			"IsMember":    false,
+			"presenceErr": nil,
 		},
 		"failure to get member infos": {
-			"status":   500,
-			"error":    fmt.Errorf("GetMemberInfos error"),
-			"IsMember": true,
+			"status":      500,
+			"error":       fmt.Errorf("GetMemberInfos error"),
+			"IsMember":    true,
+			"presenceErr": nil,
 		},
 	}
 
@@ -372,7 +415,13 @@ func TestRoomMembers(t *testing.T) {
 					},
 				}, expect["error"]).Once()
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			presenceErr, _ := expect["presenceErr"].(error)
+			presenceSvcMock := new(svc_mock.PresenceSvcMock)
+			presenceSvcMock.On("GetPresences", room.Members).Return(map[string]model.Presence{
+				"test-uuid": {Status: "away", StatusText: "In a meeting"},
+			}, presenceErr)
+
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), presenceSvcMock, new(svc_mock.WebhookSvcMock), new(svc_mock.EventSvcMock), dto)
 			err := handler.Members(c)
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
@@ -392,6 +441,14 @@ func TestRoomMembers(t *testing.T) {
 			assert.Equal(t, "Test User", result["members"][0].(map[string]interface{})["name"])
 			assert.Equal(t, "Owner User", result["members"][1].(map[string]interface{})["name"])
 
+			// オンライン状態を取得できたメンバーだけ presence を含める
+			if presenceErr == nil {
+				assert.Equal(t, map[string]interface{}{"status": "away", "status_text": "In a meeting"}, result["members"][0].(map[string]interface{})["presence"])
+			} else {
+				assert.NotContains(t, result["members"][0], "presence")
+			}
+			assert.NotContains(t, result["members"][1], "presence")
+
 			roomSvcMock.AssertExpectations(t)
 		})
 	}
@@ -455,7 +512,18 @@ func TestRoomLeave(t *testing.T) {
 			}
 			mongoSvcMock.On("LeaveRoom", "test-room-id", "test-uuid-1234", mock.Anything).Return(returnErr).Times(expect["LeaveRoomCalled"].(int))
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			webhookSvcMock := new(svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("Dispatch", mock.Anything, consts.WebhookEvents.MemberLeft, mock.Anything).Return()
+			event := service.RoomEvent{
+				Type:   consts.EventTypes.MemberLeft,
+				RoomID: "test-room-id",
+				Data:   service.WebhookMemberData{UserID: "test-uuid-1234"},
+				Member: "test-uuid-1234",
+			}
+			eventSvcMock := new(svc_mock.EventSvcMock)
+			eventSvcMock.On("Publish", event).Return("1-0", nil)
+
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), webhookSvcMock, eventSvcMock, dto)
 			err := handler.Leave(c)
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
@@ -463,6 +531,15 @@ func TestRoomLeave(t *testing.T) {
 
 			assert.Equal(t, expect["status"].(int), rec.Code)
 
+			// 成功した場合だけルームのメンバーと Webhook に配信する
+			if expect["status"].(int) == http.StatusOK {
+				webhookSvcMock.AssertNumberOfCalls(t, "Dispatch", 1)
+				eventSvcMock.AssertCalled(t, "Publish", event)
+			} else {
+				webhookSvcMock.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything, mock.Anything)
+				eventSvcMock.AssertNotCalled(t, "Publish", mock.Anything)
+			}
+
 			if expect["LeaveRoomCalled"].(int) != 0 {
 				mongoSvcMock.AssertExpectations(t)
 			} else {
@@ -519,7 +596,16 @@ func TestRoomDelete(t *testing.T) {
 			}
 			mongoSvcMock.On("DeleteRoom", "test-room-id", mock.Anything).Return(returnErr).Times(expect["DeleteRoomCalled"].(int))
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			webhookSvcMock := new(svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("Dispatch", mock.Anything, consts.WebhookEvents.RoomDeleted, mock.Anything).Return()
+			event := service.RoomEvent{
+				Type:   consts.EventTypes.RoomDeleted,
+				RoomID: "test-room-id",
+			}
+			eventSvcMock := new(svc_mock.EventSvcMock)
+			eventSvcMock.On("Publish", event).Return("1-0", nil)
+
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), webhookSvcMock, eventSvcMock, dto)
 			err := handler.Delete(c)
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
@@ -527,6 +613,15 @@ func TestRoomDelete(t *testing.T) {
 
 			assert.Equal(t, expect["status"].(int), rec.Code)
 
+			// 成功した場合だけルームのメンバーと Webhook に配信する
+			if expect["status"].(int) == http.StatusOK {
+				webhookSvcMock.AssertNumberOfCalls(t, "Dispatch", 1)
+				eventSvcMock.AssertCalled(t, "Publish", event)
+			} else {
+				webhookSvcMock.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything, mock.Anything)
+				eventSvcMock.AssertNotCalled(t, "Publish", mock.Anything)
+			}
+
 			if expect["DeleteRoomCalled"].(int) != 0 {
 				mongoSvcMock.AssertExpectations(t)
 			} else {
@@ -604,7 +699,18 @@ func TestRoomAddMember(t *testing.T) {
 				mongoSvcMock.On("JoinRoom", "test-room-id", expect["member_id"].(string), mock.Anything).Return(returnErr).Times(expect["JoinRoomCalled"].(int))
 			}
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			webhookSvcMock := new(svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("Dispatch", mock.Anything, consts.WebhookEvents.MemberJoined, mock.Anything).Return()
+			event := service.RoomEvent{
+				Type:   consts.EventTypes.MemberJoined,
+				RoomID: "test-room-id",
+				Data:   service.WebhookMemberData{UserID: expect["member_id"].(string), ActorID: "test-uuid-1234"},
+				Member: expect["member_id"].(string),
+			}
+			eventSvcMock := new(svc_mock.EventSvcMock)
+			eventSvcMock.On("Publish", event).Return("1-0", nil)
+
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), webhookSvcMock, eventSvcMock, dto)
 			err := handler.AddMember(c)
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
@@ -612,6 +718,15 @@ func TestRoomAddMember(t *testing.T) {
 
 			assert.Equal(t, expect["status"].(int), rec.Code)
 
+			// 成功した場合だけルームのメンバーと Webhook に配信する
+			if expect["status"].(int) == http.StatusOK {
+				webhookSvcMock.AssertNumberOfCalls(t, "Dispatch", 1)
+				eventSvcMock.AssertCalled(t, "Publish", event)
+			} else {
+				webhookSvcMock.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything, mock.Anything)
+				eventSvcMock.AssertNotCalled(t, "Publish", mock.Anything)
+			}
+
 			if expect["JoinRoomCalled"].(int) != 0 {
 				mongoSvcMock.AssertExpectations(t)
 			} else {
@@ -689,7 +804,18 @@ func TestRoomRemoveMember(t *testing.T) {
 				mongoSvcMock.On("LeaveRoom", "test-room-id", expect["member_id"].(string), mock.Anything).Return(returnErr).Times(expect["LeaveRoomCalled"].(int))
 			}
 
-			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, dto)
+			webhookSvcMock := new(svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("Dispatch", mock.Anything, consts.WebhookEvents.MemberLeft, mock.Anything).Return()
+			event := service.RoomEvent{
+				Type:   consts.EventTypes.MemberLeft,
+				RoomID: "test-room-id",
+				Data:   service.WebhookMemberData{UserID: expect["member_id"].(string), ActorID: "test-uuid-1234"},
+				Member: expect["member_id"].(string),
+			}
+			eventSvcMock := new(svc_mock.EventSvcMock)
+			eventSvcMock.On("Publish", event).Return("1-0", nil)
+
+			handler := NewRoomHandler(mongoSvcMock, roomSvcMock, new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), webhookSvcMock, eventSvcMock, dto)
 			err := handler.RemoveMember(c)
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
@@ -697,6 +823,15 @@ func TestRoomRemoveMember(t *testing.T) {
 
 			assert.Equal(t, expect["status"].(int), rec.Code)
 
+			// 成功した場合だけルームのメンバーと Webhook に配信する
+			if expect["status"].(int) == http.StatusOK {
+				webhookSvcMock.AssertNumberOfCalls(t, "Dispatch", 1)
+				eventSvcMock.AssertCalled(t, "Publish", event)
+			} else {
+				webhookSvcMock.AssertNotCalled(t, "Dispatch", mock.Anything, mock.Anything, mock.Anything)
+				eventSvcMock.AssertNotCalled(t, "Publish", mock.Anything)
+			}
+
 			if expect["LeaveRoomCalled"].(int) != 0 {
 				mongoSvcMock.AssertExpectations(t)
 			} else {
@@ -705,3 +840,343 @@ func TestRoomRemoveMember(t *testing.T) {
 		})
 	}
 }
+
+func TestRoomSetMessageTTL(t *testing.T) {
+	expected := map[string]struct {
+		isAdmin   bool
+		body      string
+		setCalled int
+		setErr    error
+		ttl       int
+		status    int
+	}{
+		"success": {
+			isAdmin:   true,
+			body:      `{"message_ttl": 3600}`,
+			setCalled: 1,
+			ttl:       3600,
+			status:    200,
+		},
+		"disable": {
+			isAdmin:   true,
+			body:      `{"message_ttl": 0}`,
+			setCalled: 1,
+			ttl:       0,
+			status:    200,
+		},
+		"forbidden (not admin)": {
+			isAdmin: false,
+			body:    `{"message_ttl": 3600}`,
+			status:  403,
+		},
+		"validation error (missing message_ttl)": {
+			isAdmin: true,
+			body:    `{}`,
+			status:  400,
+		},
+		"validation error (out of range)": {
+			isAdmin: true,
+			body:    `{"message_ttl": 1}`,
+			status:  400,
+		},
+		"failure to set message ttl": {
+			isAdmin:   true,
+			body:      `{"message_ttl": 3600}`,
+			setCalled: 1,
+			setErr:    assert.AnError,
+			ttl:       3600,
+			status:    500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+			req := httptest.NewRequest(http.MethodPut, "/room/:room_id/admin/message_ttl", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_admin", tt.isAdmin)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			mongoSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			mongoSvcMock.On("SetMessageTTL", "test-room-id", tt.ttl, mock.Anything).Return(tt.setErr)
+
+			handler := NewRoomHandler(mongoSvcMock, new(svc_mock.RoomSvcMock), new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), new(svc_mock.WebhookSvcMock), new(svc_mock.EventSvcMock), dto.NewRoomDtoStruct())
+			err := handler.SetMessageTTL(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			mongoSvcMock.AssertNumberOfCalls(t, "SetMessageTTL", tt.setCalled)
+		})
+	}
+}
+
+func TestRoomSetRetention(t *testing.T) {
+	expected := map[string]struct {
+		isAdmin   bool
+		body      string
+		setCalled int
+		setErr    error
+		days      int
+		status    int
+	}{
+		"success": {
+			isAdmin:   true,
+			body:      `{"retention_days": 30}`,
+			setCalled: 1,
+			days:      30,
+			status:    200,
+		},
+		"disable": {
+			isAdmin:   true,
+			body:      `{"retention_days": 0}`,
+			setCalled: 1,
+			days:      0,
+			status:    200,
+		},
+		"forbidden (not admin)": {
+			isAdmin: false,
+			body:    `{"retention_days": 30}`,
+			status:  403,
+		},
+		"validation error (missing retention_days)": {
+			isAdmin: true,
+			body:    `{}`,
+			status:  400,
+		},
+		"validation error (out of range)": {
+			isAdmin: true,
+			body:    `{"retention_days": -1}`,
+			status:  400,
+		},
+		"validation error (too long)": {
+			isAdmin: true,
+			body:    `{"retention_days": 3651}`,
+			status:  400,
+		},
+		"failure to set retention": {
+			isAdmin:   true,
+			body:      `{"retention_days": 30}`,
+			setCalled: 1,
+			setErr:    assert.AnError,
+			days:      30,
+			status:    500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+			req := httptest.NewRequest(http.MethodPut, "/room/:room_id/admin/retention", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_admin", tt.isAdmin)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			mongoSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			mongoSvcMock.On("SetRetentionDays", "test-room-id", tt.days, mock.Anything).Return(tt.setErr)
+
+			handler := NewRoomHandler(mongoSvcMock, new(svc_mock.RoomSvcMock), new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), new(svc_mock.WebhookSvcMock), new(svc_mock.EventSvcMock), dto.NewRoomDtoStruct())
+			err := handler.SetRetention(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			mongoSvcMock.AssertNumberOfCalls(t, "SetRetentionDays", tt.setCalled)
+		})
+	}
+}
+
+func TestRoomSetReadReceipts(t *testing.T) {
+	expected := map[string]struct {
+		isAdmin   bool
+		body      string
+		setCalled int
+		setErr    error
+		hide      bool
+		status    int
+	}{
+		"hide": {
+			isAdmin:   true,
+			body:      `{"hide": true}`,
+			setCalled: 1,
+			hide:      true,
+			status:    200,
+		},
+		"show": {
+			isAdmin:   true,
+			body:      `{"hide": false}`,
+			setCalled: 1,
+			hide:      false,
+			status:    200,
+		},
+		"forbidden (not admin)": {
+			isAdmin: false,
+			body:    `{"hide": true}`,
+			status:  403,
+		},
+		"validation error (missing hide)": {
+			isAdmin: true,
+			body:    `{}`,
+			status:  400,
+		},
+		"failure to set read receipts": {
+			isAdmin:   true,
+			body:      `{"hide": true}`,
+			setCalled: 1,
+			setErr:    assert.AnError,
+			hide:      true,
+			status:    500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+			req := httptest.NewRequest(http.MethodPut, "/room/:room_id/admin/read_receipts", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_admin", tt.isAdmin)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			mongoSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			mongoSvcMock.On("SetHideReadReceipts", "test-room-id", tt.hide, mock.Anything).Return(tt.setErr)
+
+			handler := NewRoomHandler(mongoSvcMock, new(svc_mock.RoomSvcMock), new(svc_mock.ExportSvcMock), new(svc_mock.PresenceSvcMock), new(svc_mock.WebhookSvcMock), new(svc_mock.EventSvcMock), dto.NewRoomDtoStruct())
+			err := handler.SetReadReceipts(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			mongoSvcMock.AssertNumberOfCalls(t, "SetHideReadReceipts", tt.setCalled)
+		})
+	}
+}
+
+func TestRoomExport(t *testing.T) {
+	room := model.Room{ID: primitive.NewObjectID(), Name: "Test Room"}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+
+	expected := map[string]struct {
+		isMember    bool
+		query       string
+		format      string
+		contentType string
+		typeErr     error
+		loc         *time.Location
+		exportCall  int
+		written     string
+		exportErr   error
+		status      int
+		body        string
+		disposition string
+	}{
+		"success (default json, utc)": {
+			isMember:    true,
+			format:      "json",
+			contentType: "application/json; charset=UTF-8",
+			loc:         time.UTC,
+			exportCall:  1,
+			written:     `{"messages":[]}`,
+			status:      200,
+			body:        `{"messages":[]}`,
+			disposition: `attachment; filename="room-` + room.ID.Hex() + `.json"`,
+		},
+		"success (csv, timezone)": {
+			isMember:    true,
+			query:       "?format=csv&tz=Asia/Tokyo",
+			format:      "csv",
+			contentType: "text/csv; charset=UTF-8",
+			loc:         tokyo,
+			exportCall:  1,
+			written:     "id,created_at\n",
+			status:      200,
+			body:        "id,created_at\n",
+			disposition: `attachment; filename="room-` + room.ID.Hex() + `.csv"`,
+		},
+		"forbidden (not member)": {
+			isMember: false,
+			status:   403,
+		},
+		"unsupported format": {
+			isMember: true,
+			query:    "?format=xml",
+			format:   "xml",
+			typeErr:  assert.AnError,
+			status:   400,
+		},
+		"invalid timezone": {
+			isMember:    true,
+			query:       "?tz=Mars/Olympus",
+			format:      "json",
+			contentType: "application/json; charset=UTF-8",
+			status:      400,
+		},
+		"failure before writing": {
+			isMember:    true,
+			format:      "json",
+			contentType: "application/json; charset=UTF-8",
+			loc:         time.UTC,
+			exportCall:  1,
+			exportErr:   assert.AnError,
+			status:      500,
+		},
+		"failure after writing": {
+			isMember:    true,
+			format:      "json",
+			contentType: "application/json; charset=UTF-8",
+			loc:         time.UTC,
+			exportCall:  1,
+			written:     `{"messages":[`,
+			exportErr:   assert.AnError,
+			status:      200,
+			body:        `{"messages":[`,
+			disposition: `attachment; filename="room-` + room.ID.Hex() + `.json"`,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/room/:room_id/export"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", tt.isMember)
+			c.Set("room_model", room)
+
+			exportSvcMock := new(svc_mock.ExportSvcMock)
+			exportSvcMock.On("ContentType", tt.format).Return(tt.contentType, tt.typeErr)
+			exportSvcMock.On("Export", mock.Anything, room, tt.format, tt.loc, mock.Anything).Run(func(args mock.Arguments) {
+				if tt.written != "" {
+					args.Get(0).(io.Writer).Write([]byte(tt.written))
+				}
+			}).Return(0, tt.exportErr)
+
+			handler := NewRoomHandler(new(mongo_svc_mock.RoomSvcMock), new(svc_mock.RoomSvcMock), exportSvcMock, new(svc_mock.PresenceSvcMock), new(svc_mock.WebhookSvcMock), new(svc_mock.EventSvcMock), dto.NewRoomDtoStruct())
+			err := handler.Export(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			exportSvcMock.AssertNumberOfCalls(t, "Export", tt.exportCall)
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rec.Body.String())
+				assert.Equal(t, tt.contentType, rec.Header().Get(echo.HeaderContentType))
+			}
+			assert.Equal(t, tt.disposition, rec.Header().Get(echo.HeaderContentDisposition))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type ScheduledMessageHandlerInterface interface {
+	Create(c echo.Context) error
+	List(c echo.Context) error
+	Update(c echo.Context) error
+	Cancel(c echo.Context) error
+}
+
+type ScheduledMessageHandler struct {
+	BaseHandler
+	scheduledSvc mongo_svc.ScheduledMessageSvcInterface
+	scheduleSvc  service.ScheduledMessageSvcInterface
+	dto          dto.ScheduledMessageDtoInterface
+}
+
+func NewScheduledMessageHandler(
+	scheduledSvc mongo_svc.ScheduledMessageSvcInterface,
+	scheduleSvc service.ScheduledMessageSvcInterface,
+	dto dto.ScheduledMessageDtoInterface,
+) *ScheduledMessageHandler {
+	return &ScheduledMessageHandler{
+		scheduledSvc: scheduledSvc,
+		scheduleSvc:  scheduleSvc,
+		dto:          dto,
+	}
+}
+
+// send_at は RFC3339 形式（例: 2025-01-02T09:00:00+09:00）で指定する
+type ScheduleMessageRequest struct {
+	Message string    `json:"message" form:"message" validate:"required"`
+	SendAt  time.Time `json:"send_at" form:"send_at" validate:"required"`
+}
+
+func (h *ScheduledMessageHandler) Create(c echo.Context) error {
+	var req ScheduleMessageRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	uuid := h.GetUuid(c)
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	scheduledID, err := h.scheduleSvc.Schedule(model.ScheduledMessage{
+		RoomID:  roomID,
+		Sender:  uuid,
+		Message: req.Message,
+		SendAt:  req.SendAt,
+	}, ctx)
+	if err != nil {
+		return h.scheduleError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"scheduled_id": scheduledID,
+	})
+}
+
+// 自分が予約した送信前のメッセージを、送信日時の早い順で返す
+func (h *ScheduledMessageHandler) List(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	roomID := c.Param("room_id")
+	uuid := h.GetUuid(c)
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	scheduled, err := h.scheduledSvc.GetScheduledMessages(roomID, uuid, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"scheduled_messages": h.dto.ResponseScheduledMessageList(scheduled),
+	})
+}
+
+func (h *ScheduledMessageHandler) Update(c echo.Context) error {
+	var req ScheduleMessageRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	scheduled, ok := h.findScheduled(c, ctx)
+	if !ok {
+		return c.JSON(404, echo.Map{
+			"error": "scheduled message not found",
+		})
+	}
+
+	if err := h.scheduleSvc.Reschedule(scheduled, req.Message, req.SendAt, ctx); err != nil {
+		return h.scheduleError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+func (h *ScheduledMessageHandler) Cancel(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	scheduled, ok := h.findScheduled(c, ctx)
+	if !ok {
+		return c.JSON(404, echo.Map{
+			"error": "scheduled message not found",
+		})
+	}
+
+	if err := h.scheduleSvc.Cancel(scheduled, ctx); err != nil {
+		return h.scheduleError(c, err)
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// 他人の予約は存在しないものとして扱う
+func (h *ScheduledMessageHandler) findScheduled(c echo.Context, ctx *atylabmongo.MongoCtxSvc) (model.ScheduledMessage, bool) {
+	scheduled, err := h.scheduledSvc.GetScheduledMessage(c.Param("scheduled_id"), c.Param("room_id"), h.GetUuid(c), ctx)
+	if err != nil {
+		return model.ScheduledMessage{}, false
+	}
+	return scheduled, true
+}
+
+func (h *ScheduledMessageHandler) scheduleError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, service.ErrInvalidSendAt):
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrScheduledLimitReached), errors.Is(err, service.ErrScheduledMessageNotPending):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.JSON(500, echo.Map{
+		"error": err.Error(),
+	})
+}
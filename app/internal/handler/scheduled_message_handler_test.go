@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newScheduledMessageContext(method string, path string, body string, isMember bool) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("uuid", "test-uuid-1234")
+	c.Set("is_member", isMember)
+	return c, rec
+}
+
+func TestScheduledMessageCreate(t *testing.T) {
+	sendAt := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	expected := map[string]struct {
+		body           string
+		isMember       bool
+		scheduleErr    error
+		scheduleCalled int
+		status         int
+	}{
+		"success": {
+			body:           `{"message": "good morning", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember:       true,
+			scheduleCalled: 1,
+			status:         200,
+		},
+		"validation error (missing send_at)": {
+			body:     `{"message": "good morning"}`,
+			isMember: true,
+			status:   400,
+		},
+		"validation error (invalid send_at)": {
+			body:     `{"message": "good morning", "send_at": "tomorrow"}`,
+			isMember: true,
+			status:   400,
+		},
+		"forbidden (not a member)": {
+			body:     `{"message": "good morning", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember: false,
+			status:   403,
+		},
+		"send_at out of range": {
+			body:           `{"message": "good morning", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember:       true,
+			scheduleErr:    service.ErrInvalidSendAt,
+			scheduleCalled: 1,
+			status:         400,
+		},
+		"limit reached": {
+			body:           `{"message": "good morning", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember:       true,
+			scheduleErr:    service.ErrScheduledLimitReached,
+			scheduleCalled: 1,
+			status:         409,
+		},
+		"failure to schedule": {
+			body:           `{"message": "good morning", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember:       true,
+			scheduleErr:    assert.AnError,
+			scheduleCalled: 1,
+			status:         500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newScheduledMessageContext(http.MethodPost, "/message/:room_id/scheduled", tt.body, tt.isMember)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			scheduleSvcMock := new(svc_mock.ScheduledMessageSvcMock)
+			scheduleSvcMock.On("Schedule", model.ScheduledMessage{
+				RoomID:  "test-room-id",
+				Sender:  "test-uuid-1234",
+				Message: "good morning",
+				SendAt:  sendAt,
+			}, mock.Anything).Return("scheduled-id", tt.scheduleErr)
+
+			handler := NewScheduledMessageHandler(new(mongo_svc_mock.ScheduledMessageSvcMock), scheduleSvcMock, dto.NewScheduledMessageDtoStruct())
+			err := handler.Create(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			scheduleSvcMock.AssertNumberOfCalls(t, "Schedule", tt.scheduleCalled)
+
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string]string{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, "scheduled-id", result["scheduled_id"])
+		})
+	}
+}
+
+func TestScheduledMessageList(t *testing.T) {
+	scheduled := []model.ScheduledMessage{
+		{ID: primitive.NewObjectID(), RoomID: "test-room-id", Message: "first", SendAt: time.Now().Add(time.Hour)},
+		{ID: primitive.NewObjectID(), RoomID: "test-room-id", Message: "second", SendAt: time.Now().Add(2 * time.Hour)},
+	}
+
+	expected := map[string]struct {
+		isMember bool
+		getErr   error
+		status   int
+	}{
+		"success": {
+			isMember: true,
+			status:   200,
+		},
+		"forbidden (not a member)": {
+			isMember: false,
+			status:   403,
+		},
+		"failure to get scheduled messages": {
+			isMember: true,
+			getErr:   assert.AnError,
+			status:   500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newScheduledMessageContext(http.MethodGet, "/message/:room_id/scheduled", "", tt.isMember)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+			scheduledSvcMock.On("GetScheduledMessages", "test-room-id", "test-uuid-1234", mock.Anything).Return(scheduled, tt.getErr)
+
+			handler := NewScheduledMessageHandler(scheduledSvcMock, new(svc_mock.ScheduledMessageSvcMock), dto.NewScheduledMessageDtoStruct())
+			err := handler.List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+
+			if !tt.isMember {
+				scheduledSvcMock.AssertNotCalled(t, "GetScheduledMessages", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			result := map[string][]dto.ScheduledMessageResponse{}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result["scheduled_messages"], 2)
+			assert.Equal(t, "first", result["scheduled_messages"][0].Message)
+		})
+	}
+}
+
+func TestScheduledMessageUpdate(t *testing.T) {
+	scheduledID := primitive.NewObjectID()
+	scheduled := model.ScheduledMessage{ID: scheduledID, RoomID: "test-room-id", Sender: "test-uuid-1234"}
+	sendAt := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	expected := map[string]struct {
+		body             string
+		isMember         bool
+		getErr           error
+		rescheduleErr    error
+		rescheduleCalled int
+		status           int
+	}{
+		"success": {
+			body:             `{"message": "edited", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember:         true,
+			rescheduleCalled: 1,
+			status:           200,
+		},
+		"validation error": {
+			body:     `{"message": ""}`,
+			isMember: true,
+			status:   400,
+		},
+		"forbidden (not a member)": {
+			body:     `{"message": "edited", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember: false,
+			status:   403,
+		},
+		"not found (or someone else's)": {
+			body:     `{"message": "edited", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember: true,
+			getErr:   assert.AnError,
+			status:   404,
+		},
+		"already sent": {
+			body:             `{"message": "edited", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember:         true,
+			rescheduleErr:    service.ErrScheduledMessageNotPending,
+			rescheduleCalled: 1,
+			status:           409,
+		},
+		"failure to reschedule": {
+			body:             `{"message": "edited", "send_at": "2030-01-02T09:00:00Z"}`,
+			isMember:         true,
+			rescheduleErr:    assert.AnError,
+			rescheduleCalled: 1,
+			status:           500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newScheduledMessageContext(http.MethodPut, "/message/:room_id/scheduled/:scheduled_id", tt.body, tt.isMember)
+			c.SetParamNames("room_id", "scheduled_id")
+			c.SetParamValues("test-room-id", scheduledID.Hex())
+
+			scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+			scheduledSvcMock.On("GetScheduledMessage", scheduledID.Hex(), "test-room-id", "test-uuid-1234", mock.Anything).Return(scheduled, tt.getErr)
+			scheduleSvcMock := new(svc_mock.ScheduledMessageSvcMock)
+			scheduleSvcMock.On("Reschedule", scheduled, "edited", sendAt, mock.Anything).Return(tt.rescheduleErr)
+
+			handler := NewScheduledMessageHandler(scheduledSvcMock, scheduleSvcMock, dto.NewScheduledMessageDtoStruct())
+			err := handler.Update(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			scheduleSvcMock.AssertNumberOfCalls(t, "Reschedule", tt.rescheduleCalled)
+		})
+	}
+}
+
+func TestScheduledMessageCancel(t *testing.T) {
+	scheduledID := primitive.NewObjectID()
+	scheduled := model.ScheduledMessage{ID: scheduledID, RoomID: "test-room-id", Sender: "test-uuid-1234"}
+
+	expected := map[string]struct {
+		isMember     bool
+		getErr       error
+		cancelErr    error
+		cancelCalled int
+		status       int
+	}{
+		"success": {
+			isMember:     true,
+			cancelCalled: 1,
+			status:       200,
+		},
+		"forbidden (not a member)": {
+			isMember: false,
+			status:   403,
+		},
+		"not found (or someone else's)": {
+			isMember: true,
+			getErr:   assert.AnError,
+			status:   404,
+		},
+		"already sent": {
+			isMember:     true,
+			cancelErr:    service.ErrScheduledMessageNotPending,
+			cancelCalled: 1,
+			status:       409,
+		},
+		"failure to cancel": {
+			isMember:     true,
+			cancelErr:    assert.AnError,
+			cancelCalled: 1,
+			status:       500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newScheduledMessageContext(http.MethodDelete, "/message/:room_id/scheduled/:scheduled_id", "", tt.isMember)
+			c.SetParamNames("room_id", "scheduled_id")
+			c.SetParamValues("test-room-id", scheduledID.Hex())
+
+			scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+			scheduledSvcMock.On("GetScheduledMessage", scheduledID.Hex(), "test-room-id", "test-uuid-1234", mock.Anything).Return(scheduled, tt.getErr)
+			scheduleSvcMock := new(svc_mock.ScheduledMessageSvcMock)
+			scheduleSvcMock.On("Cancel", scheduled, mock.Anything).Return(tt.cancelErr)
+
+			handler := NewScheduledMessageHandler(scheduledSvcMock, scheduleSvcMock, dto.NewScheduledMessageDtoStruct())
+			err := handler.Cancel(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			scheduleSvcMock.AssertNumberOfCalls(t, "Cancel", tt.cancelCalled)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type SlashCommandHandlerInterface interface {
+	Catalog(c echo.Context) error
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Delete(c echo.Context) error
+}
+
+type SlashCommandHandler struct {
+	BaseHandler
+	slashCommandSvc mongo_svc.SlashCommandSvcInterface
+	botSvc          mongo_svc.BotSvcInterface
+	registerSvc     service.SlashCommandSvcInterface
+	dto             dto.SlashCommandDtoInterface
+}
+
+func NewSlashCommandHandler(
+	slashCommandSvc mongo_svc.SlashCommandSvcInterface,
+	botSvc mongo_svc.BotSvcInterface,
+	registerSvc service.SlashCommandSvcInterface,
+	dto dto.SlashCommandDtoInterface,
+) *SlashCommandHandler {
+	return &SlashCommandHandler{
+		slashCommandSvc: slashCommandSvc,
+		botSvc:          botSvc,
+		registerSvc:     registerSvc,
+		dto:             dto,
+	}
+}
+
+// 組み込みのコマンドと、ボットが登録したコマンドをまとめて返す
+func (h *SlashCommandHandler) Catalog(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	commands, err := h.slashCommandSvc.GetAllSlashCommands(ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"commands": h.dto.ResponseCommandCatalog(consts.SlashCommandBuiltins, commands),
+	})
+}
+
+func (h *SlashCommandHandler) List(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bot, ok := h.manageableBot(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	commands, err := h.slashCommandSvc.GetSlashCommands(bot.ID.Hex(), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"commands": h.dto.ResponseSlashCommandList(commands),
+	})
+}
+
+type CreateSlashCommandRequest struct {
+	Name        string `json:"name" form:"name" validate:"required"`
+	Description string `json:"description" form:"description" validate:"max=200"`
+	URL         string `json:"url" form:"url" validate:"required"`
+}
+
+// 署名の鍵はこのレスポンスでだけ返す
+func (h *SlashCommandHandler) Create(c echo.Context) error {
+	var req CreateSlashCommandRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bot, ok := h.manageableBot(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	command, err := h.registerSvc.Register(model.SlashCommand{
+		BotID:       bot.ID.Hex(),
+		Name:        strings.ToLower(strings.TrimPrefix(strings.TrimSpace(req.Name), "/")),
+		Description: strings.TrimSpace(req.Description),
+		URL:         req.URL,
+	}, ctx)
+	switch {
+	case errors.Is(err, service.ErrSlashCommandNameInvalid), errors.Is(err, usecase.ErrWebhookAddressNotAllowed):
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrSlashCommandNameTaken), errors.Is(err, service.ErrSlashCommandLimitReached):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"command": h.dto.GetSlashCommandInfo(command),
+		"secret":  command.Secret,
+	})
+}
+
+func (h *SlashCommandHandler) Delete(c echo.Context) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	bot, ok := h.manageableBot(c, ctx)
+	if !ok {
+		return h.notFound(c)
+	}
+
+	deleted, err := h.slashCommandSvc.DeleteSlashCommand(c.Param("command_id"), bot.ID.Hex(), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if !deleted {
+		return c.JSON(404, echo.Map{
+			"error": "command not found",
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// コマンドはボットの所有者か、ボット自身（API キー）が管理できる
+// それ以外のボットは存在しないものとして扱う
+func (h *SlashCommandHandler) manageableBot(c echo.Context, ctx *atylabmongo.MongoCtxSvc) (model.Bot, bool) {
+	if bot, ok := h.GetBot(c); ok {
+		return bot, bot.ID.Hex() == c.Param("bot_id")
+	}
+
+	bot, err := h.botSvc.GetBot(c.Param("bot_id"), ctx)
+	if err != nil || bot.OwnerID != h.GetUuid(c) {
+		return model.Bot{}, false
+	}
+	return bot, true
+}
+
+func (h *SlashCommandHandler) notFound(c echo.Context) error {
+	return c.JSON(404, echo.Map{
+		"error": "bot not found",
+	})
+}
@@ -0,0 +1,278 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// caller には "owner"（所有者のユーザー）・"other"（他のユーザー）・"self"（ボット自身）・"other bot" を指定する
+func newSlashCommandContext(method string, path string, body string, bot model.Bot, caller string) (echo.Context, *httptest.ResponseRecorder) {
+	c, rec := newWebhookContext(method, path, body, false)
+	switch caller {
+	case "other":
+		c.Set("uuid", "other-uuid")
+	case "self":
+		c.Set("uuid", consts.BotSenderPrefix+bot.ID.Hex())
+		c.Set("bot", bot)
+	case "other bot":
+		other := model.Bot{ID: primitive.NewObjectID(), OwnerID: "test-uuid-1234"}
+		c.Set("uuid", consts.BotSenderPrefix+other.ID.Hex())
+		c.Set("bot", other)
+	}
+	return c, rec
+}
+
+func TestSlashCommandCatalog(t *testing.T) {
+	expected := map[string]struct {
+		getErr error
+		status int
+	}{
+		"success":                 {status: 200},
+		"failure to get commands": {getErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodGet, "/commands", "", false)
+
+			slashCommandSvcMock := new(mongo_svc_mock.SlashCommandSvcMock)
+			slashCommandSvcMock.On("GetAllSlashCommands", mock.Anything).Return([]model.SlashCommand{
+				{ID: primitive.NewObjectID(), BotID: "bot-id", Name: "deploy"},
+			}, tt.getErr)
+
+			handler := NewSlashCommandHandler(slashCommandSvcMock, nil, nil, dto.NewSlashCommandDtoStruct())
+			err := handler.Catalog(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Commands []dto.CommandCatalogResponse `json:"commands"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result.Commands, len(consts.SlashCommandBuiltins)+1)
+			assert.True(t, result.Commands[0].Builtin)
+			assert.Equal(t, "deploy", result.Commands[len(result.Commands)-1].Name)
+		})
+	}
+}
+
+func TestSlashCommandList(t *testing.T) {
+	bot := model.Bot{ID: primitive.NewObjectID(), OwnerID: "test-uuid-1234", Name: "Deploy Bot"}
+
+	expected := map[string]struct {
+		caller string
+		botErr error
+		getErr error
+		status int
+	}{
+		"success (owner)":         {caller: "owner", status: 200},
+		"success (bot itself)":    {caller: "self", status: 200},
+		"another user's bot":      {caller: "other", status: 404},
+		"another bot":             {caller: "other bot", status: 404},
+		"bot not found":           {caller: "owner", botErr: mongo.ErrNoDocuments, status: 404},
+		"failure to get commands": {caller: "owner", getErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newSlashCommandContext(http.MethodGet, "/bots/:bot_id/commands", "", bot, tt.caller)
+			c.SetParamNames("bot_id")
+			c.SetParamValues(bot.ID.Hex())
+
+			botSvcMock := new(mongo_svc_mock.BotSvcMock)
+			botSvcMock.On("GetBot", bot.ID.Hex(), mock.Anything).Return(bot, tt.botErr)
+			slashCommandSvcMock := new(mongo_svc_mock.SlashCommandSvcMock)
+			slashCommandSvcMock.On("GetSlashCommands", bot.ID.Hex(), mock.Anything).Return([]model.SlashCommand{
+				{ID: primitive.NewObjectID(), BotID: bot.ID.Hex(), Name: "deploy", Secret: "command-secret"},
+			}, tt.getErr)
+
+			handler := NewSlashCommandHandler(slashCommandSvcMock, botSvcMock, nil, dto.NewSlashCommandDtoStruct())
+			err := handler.List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Commands []dto.SlashCommandResponse `json:"commands"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result.Commands, 1)
+			assert.NotContains(t, rec.Body.String(), "command-secret")
+		})
+	}
+}
+
+func TestSlashCommandCreate(t *testing.T) {
+	bot := model.Bot{ID: primitive.NewObjectID(), OwnerID: "test-uuid-1234", Name: "Deploy Bot"}
+	commandID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		body           string
+		caller         string
+		registerErr    error
+		registerCalled int
+		status         int
+	}{
+		"success (owner)": {
+			body:           `{"name": "/Deploy", "description": " Deploy the app ", "url": "https://example.com/deploy"}`,
+			caller:         "owner",
+			registerCalled: 1,
+			status:         200,
+		},
+		"success (bot itself)": {
+			body:           `{"name": "deploy", "description": "Deploy the app", "url": "https://example.com/deploy"}`,
+			caller:         "self",
+			registerCalled: 1,
+			status:         200,
+		},
+		"another user's bot": {
+			body:   `{"name": "deploy", "url": "https://example.com/deploy"}`,
+			caller: "other",
+			status: 404,
+		},
+		"validation error (missing url)": {
+			body:   `{"name": "deploy"}`,
+			caller: "owner",
+			status: 400,
+		},
+		"invalid name": {
+			body:           `{"name": "deploy", "url": "https://example.com/deploy"}`,
+			caller:         "owner",
+			registerErr:    service.ErrSlashCommandNameInvalid,
+			registerCalled: 1,
+			status:         400,
+		},
+		"invalid url": {
+			body:           `{"name": "deploy", "url": "https://example.com/deploy"}`,
+			caller:         "owner",
+			registerErr:    usecase.ErrWebhookAddressNotAllowed,
+			registerCalled: 1,
+			status:         400,
+		},
+		"name taken": {
+			body:           `{"name": "deploy", "url": "https://example.com/deploy"}`,
+			caller:         "owner",
+			registerErr:    service.ErrSlashCommandNameTaken,
+			registerCalled: 1,
+			status:         409,
+		},
+		"limit reached": {
+			body:           `{"name": "deploy", "url": "https://example.com/deploy"}`,
+			caller:         "owner",
+			registerErr:    service.ErrSlashCommandLimitReached,
+			registerCalled: 1,
+			status:         409,
+		},
+		"failure to register": {
+			body:           `{"name": "deploy", "url": "https://example.com/deploy"}`,
+			caller:         "owner",
+			registerErr:    assert.AnError,
+			registerCalled: 1,
+			status:         500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newSlashCommandContext(http.MethodPost, "/bots/:bot_id/commands", tt.body, bot, tt.caller)
+			c.SetParamNames("bot_id")
+			c.SetParamValues(bot.ID.Hex())
+
+			botSvcMock := new(mongo_svc_mock.BotSvcMock)
+			botSvcMock.On("GetBot", bot.ID.Hex(), mock.Anything).Return(bot, nil)
+			registerSvcMock := new(svc_mock.SlashCommandSvcMock)
+			registerSvcMock.On("Register", mock.MatchedBy(func(command model.SlashCommand) bool {
+				return command.BotID == bot.ID.Hex() && command.Name == "deploy" && command.URL == "https://example.com/deploy"
+			}), mock.Anything).Return(model.SlashCommand{
+				ID:          commandID,
+				BotID:       bot.ID.Hex(),
+				Name:        "deploy",
+				Description: "Deploy the app",
+				URL:         "https://example.com/deploy",
+				Secret:      "generated-secret",
+			}, tt.registerErr)
+
+			handler := NewSlashCommandHandler(nil, botSvcMock, registerSvcMock, dto.NewSlashCommandDtoStruct())
+			err := handler.Create(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			registerSvcMock.AssertNumberOfCalls(t, "Register", tt.registerCalled)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			registerSvcMock.AssertCalled(t, "Register", mock.MatchedBy(func(command model.SlashCommand) bool {
+				return command.Description == "Deploy the app"
+			}), mock.Anything)
+			var result struct {
+				Command dto.SlashCommandResponse `json:"command"`
+				Secret  string                   `json:"secret"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, commandID.Hex(), result.Command.ID)
+			assert.Equal(t, "generated-secret", result.Secret)
+		})
+	}
+}
+
+func TestSlashCommandDelete(t *testing.T) {
+	bot := model.Bot{ID: primitive.NewObjectID(), OwnerID: "test-uuid-1234", Name: "Deploy Bot"}
+
+	expected := map[string]struct {
+		caller       string
+		deleted      bool
+		deleteErr    error
+		deleteCalled int
+		status       int
+	}{
+		"success (owner)":      {caller: "owner", deleted: true, deleteCalled: 1, status: 200},
+		"success (bot itself)": {caller: "self", deleted: true, deleteCalled: 1, status: 200},
+		"another user's bot":   {caller: "other", status: 404},
+		"another bot":          {caller: "other bot", status: 404},
+		"command not found":    {caller: "owner", deleted: false, deleteCalled: 1, status: 404},
+		"failure to delete":    {caller: "owner", deleteErr: assert.AnError, deleteCalled: 1, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newSlashCommandContext(http.MethodDelete, "/bots/:bot_id/commands/:command_id", "", bot, tt.caller)
+			c.SetParamNames("bot_id", "command_id")
+			c.SetParamValues(bot.ID.Hex(), "command-id")
+
+			botSvcMock := new(mongo_svc_mock.BotSvcMock)
+			botSvcMock.On("GetBot", bot.ID.Hex(), mock.Anything).Return(bot, nil)
+			slashCommandSvcMock := new(mongo_svc_mock.SlashCommandSvcMock)
+			slashCommandSvcMock.On("DeleteSlashCommand", "command-id", bot.ID.Hex(), mock.Anything).Return(tt.deleted, tt.deleteErr)
+
+			handler := NewSlashCommandHandler(slashCommandSvcMock, botSvcMock, nil, dto.NewSlashCommandDtoStruct())
+			err := handler.Delete(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			slashCommandSvcMock.AssertNumberOfCalls(t, "DeleteSlashCommand", tt.deleteCalled)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type TypingHandlerInterface interface {
+	Update(c echo.Context) error
+	List(c echo.Context) error
+}
+
+type TypingHandler struct {
+	BaseHandler
+	typingSvc service.TypingSvcInterface
+}
+
+func NewTypingHandler(
+	typingSvc service.TypingSvcInterface,
+) *TypingHandler {
+	return &TypingHandler{
+		typingSvc: typingSvc,
+	}
+}
+
+// typing を省略した場合は入力中として扱う。送信や入力欄を空にしたときは false を送る
+type TypingRequest struct {
+	Typing *bool `json:"typing" form:"typing"`
+}
+
+// 入力中であることをルームの他のメンバーに通知する
+// 入力している間は consts.TypingTTL より短い間隔で送り直す
+func (h *TypingHandler) Update(c echo.Context) error {
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	var req TypingRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	typing := req.Typing == nil || *req.Typing
+
+	if err := h.typingSvc.SetTyping(c.Param("room_id"), h.GetUuid(c), typing); err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"typing": typing,
+	})
+}
+
+// ルームで入力中の他のメンバーを返す（接続し直したときに表示を復元するため）
+func (h *TypingHandler) List(c echo.Context) error {
+	if !h.IsMember(c) {
+		return c.JSON(403, echo.Map{
+			"error": "You are not a member of this room.",
+		})
+	}
+
+	uuids, err := h.typingSvc.GetTyping(c.Param("room_id"))
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	uuid := h.GetUuid(c)
+	others := []string{}
+	for _, typing := range uuids {
+		if typing != uuid {
+			others = append(others, typing)
+		}
+	}
+
+	return c.JSON(200, echo.Map{
+		"typing": others,
+	})
+}
@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypingUpdate(t *testing.T) {
+	expected := map[string]struct {
+		isMember  bool
+		body      string
+		setCalled int
+		typing    bool
+		setErr    error
+		status    int
+	}{
+		"typing": {
+			isMember:  true,
+			body:      `{"typing": true}`,
+			setCalled: 1,
+			typing:    true,
+			status:    200,
+		},
+		"typing (default)": {
+			isMember:  true,
+			body:      `{}`,
+			setCalled: 1,
+			typing:    true,
+			status:    200,
+		},
+		"stopped": {
+			isMember:  true,
+			body:      `{"typing": false}`,
+			setCalled: 1,
+			typing:    false,
+			status:    200,
+		},
+		"forbidden (not a member)": {
+			isMember: false,
+			body:     `{}`,
+			status:   403,
+		},
+		"validation error (invalid body)": {
+			isMember: true,
+			body:     `{"typing": "yes"}`,
+			status:   400,
+		},
+		"failure to set typing": {
+			isMember:  true,
+			body:      `{}`,
+			setCalled: 1,
+			typing:    true,
+			setErr:    assert.AnError,
+			status:    500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+			req := httptest.NewRequest(http.MethodPost, "/message/:room_id/typing", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", tt.isMember)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			typingSvcMock := new(svc_mock.TypingSvcMock)
+			typingSvcMock.On("SetTyping", "test-room-id", "test-uuid-1234", tt.typing).Return(tt.setErr)
+
+			err := NewTypingHandler(typingSvcMock).Update(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			typingSvcMock.AssertNumberOfCalls(t, "SetTyping", tt.setCalled)
+		})
+	}
+}
+
+func TestTypingList(t *testing.T) {
+	expected := map[string]struct {
+		isMember bool
+		getErr   error
+		status   int
+	}{
+		"success":                  {isMember: true, status: 200},
+		"forbidden (not a member)": {isMember: false, status: 403},
+		"failure to get typing":    {isMember: true, getErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/message/:room_id/typing", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("uuid", "test-uuid-1234")
+			c.Set("is_member", tt.isMember)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			typingSvcMock := new(svc_mock.TypingSvcMock)
+			typingSvcMock.On("GetTyping", "test-room-id").Return([]string{"test-uuid-1234", "other-uuid"}, tt.getErr)
+
+			err := NewTypingHandler(typingSvcMock).List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status == 200 {
+				// 本人は含めない
+				result := map[string][]string{}
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+				assert.Equal(t, []string{"other-uuid"}, result["typing"])
+			}
+		})
+	}
+}
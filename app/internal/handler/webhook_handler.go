@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type WebhookHandlerInterface interface {
+	List(c echo.Context) error
+	Create(c echo.Context) error
+	Delete(c echo.Context) error
+	Deliveries(c echo.Context) error
+}
+
+type WebhookHandler struct {
+	BaseHandler
+	webhookSvc  mongo_svc.WebhookSvcInterface
+	deliverySvc mongo_svc.WebhookDeliverySvcInterface
+	registerSvc service.WebhookSvcInterface
+	dto         dto.WebhookDtoInterface
+}
+
+func NewWebhookHandler(
+	webhookSvc mongo_svc.WebhookSvcInterface,
+	deliverySvc mongo_svc.WebhookDeliverySvcInterface,
+	registerSvc service.WebhookSvcInterface,
+	dto dto.WebhookDtoInterface,
+) *WebhookHandler {
+	return &WebhookHandler{
+		webhookSvc:  webhookSvc,
+		deliverySvc: deliverySvc,
+		registerSvc: registerSvc,
+		dto:         dto,
+	}
+}
+
+func (h *WebhookHandler) List(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	webhooks, err := h.webhookSvc.GetWebhooks(c.Param("room_id"), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"webhooks": h.dto.ResponseWebhookList(webhooks),
+	})
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" form:"url" validate:"required"`
+	Events []string `json:"events" form:"events" validate:"required,min=1,dive,oneof=message.created member.joined member.left room.deleted"`
+}
+
+// 署名の鍵はこのレスポンスでだけ返す
+func (h *WebhookHandler) Create(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return h.forbidden(c)
+	}
+
+	var req CreateWebhookRequest
+	if err := h.validateRequest(c, &req); err != nil {
+		fmt.Println("Validation error:", err)
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	webhook, err := h.registerSvc.Register(model.Webhook{
+		RoomID:    c.Param("room_id"),
+		URL:       req.URL,
+		Events:    uniqueStrings(req.Events),
+		CreatedBy: h.GetUuid(c),
+	}, ctx)
+	switch {
+	case errors.Is(err, usecase.ErrWebhookAddressNotAllowed):
+		return c.JSON(400, echo.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrWebhookLimitReached):
+		return c.JSON(409, echo.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"webhook": h.dto.GetWebhookInfo(webhook),
+		"secret":  webhook.Secret,
+	})
+}
+
+func (h *WebhookHandler) Delete(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	deleted, err := h.webhookSvc.DeleteWebhook(c.Param("webhook_id"), c.Param("room_id"), ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+	if !deleted {
+		return c.JSON(404, echo.Map{
+			"error": "webhook not found",
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"status": "success",
+	})
+}
+
+// 直近の配信を新しい順で返す
+func (h *WebhookHandler) Deliveries(c echo.Context) error {
+	if !h.IsAdmin(c) {
+		return h.forbidden(c)
+	}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	// 他のルームの Webhook は存在しないものとして扱う
+	webhook, err := h.webhookSvc.GetWebhook(c.Param("webhook_id"), ctx)
+	if err != nil || webhook.RoomID != c.Param("room_id") {
+		return c.JSON(404, echo.Map{
+			"error": "webhook not found",
+		})
+	}
+
+	deliveries, err := h.deliverySvc.GetDeliveries(webhook.ID.Hex(), consts.WebhookDeliveryListLimit, ctx)
+	if err != nil {
+		return c.JSON(500, echo.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(200, echo.Map{
+		"deliveries": h.dto.ResponseDeliveryList(deliveries),
+	})
+}
+
+func (h *WebhookHandler) forbidden(c echo.Context) error {
+	return c.JSON(403, echo.Map{
+		"error": "Only admin can manage webhooks",
+	})
+}
+
+// 重複を取り除く（最初に現れた順を保つ）
+func uniqueStrings(values []string) []string {
+	unique := []string{}
+	for _, value := range values {
+		if !slices.Contains(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
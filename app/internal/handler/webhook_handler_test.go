@@ -0,0 +1,289 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func newWebhookContext(method string, path string, body string, isAdmin bool) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	e.Validator = &usecase.CustomValidator{Validator: validator.New()}
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("uuid", "test-uuid-1234")
+	c.Set("is_member", true)
+	c.Set("is_admin", isAdmin)
+	return c, rec
+}
+
+func TestWebhookList(t *testing.T) {
+	expected := map[string]struct {
+		isAdmin bool
+		getErr  error
+		status  int
+	}{
+		"success":                {isAdmin: true, status: 200},
+		"forbidden (not admin)":  {isAdmin: false, status: 403},
+		"failure to get webhook": {isAdmin: true, getErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodGet, "/room/:room_id/webhooks", "", tt.isAdmin)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("GetWebhooks", "test-room-id", mock.Anything).Return([]model.Webhook{
+				{ID: primitive.NewObjectID(), RoomID: "test-room-id", URL: "https://example.com/hook", Secret: "secret"},
+			}, tt.getErr)
+
+			handler := NewWebhookHandler(webhookSvcMock, nil, nil, dto.NewWebhookDtoStruct())
+			err := handler.List(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Webhooks []dto.WebhookResponse `json:"webhooks"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result.Webhooks, 1)
+			assert.Equal(t, "https://example.com/hook", result.Webhooks[0].URL)
+			assert.NotContains(t, rec.Body.String(), "secret")
+		})
+	}
+}
+
+func TestWebhookCreate(t *testing.T) {
+	webhookID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		body           string
+		isAdmin        bool
+		registerErr    error
+		registerCalled int
+		events         []string
+		status         int
+	}{
+		"success": {
+			body:           `{"url": "https://example.com/hook", "events": ["message.created", "member.joined"]}`,
+			isAdmin:        true,
+			registerCalled: 1,
+			events:         []string{"message.created", "member.joined"},
+			status:         200,
+		},
+		"duplicated events are stored once": {
+			body:           `{"url": "https://example.com/hook", "events": ["room.deleted", "room.deleted"]}`,
+			isAdmin:        true,
+			registerCalled: 1,
+			events:         []string{"room.deleted"},
+			status:         200,
+		},
+		"forbidden (not admin)": {
+			body:    `{"url": "https://example.com/hook", "events": ["message.created"]}`,
+			isAdmin: false,
+			status:  403,
+		},
+		"validation error (no events)": {
+			body:    `{"url": "https://example.com/hook", "events": []}`,
+			isAdmin: true,
+			status:  400,
+		},
+		"validation error (unknown event)": {
+			body:    `{"url": "https://example.com/hook", "events": ["message.deleted"]}`,
+			isAdmin: true,
+			status:  400,
+		},
+		"invalid url": {
+			body:           `{"url": "ftp://example.com/hook", "events": ["message.created"]}`,
+			isAdmin:        true,
+			registerErr:    usecase.ErrWebhookAddressNotAllowed,
+			registerCalled: 1,
+			events:         []string{"message.created"},
+			status:         400,
+		},
+		"limit reached": {
+			body:           `{"url": "https://example.com/hook", "events": ["message.created"]}`,
+			isAdmin:        true,
+			registerErr:    service.ErrWebhookLimitReached,
+			registerCalled: 1,
+			events:         []string{"message.created"},
+			status:         409,
+		},
+		"failure to register": {
+			body:           `{"url": "https://example.com/hook", "events": ["message.created"]}`,
+			isAdmin:        true,
+			registerErr:    assert.AnError,
+			registerCalled: 1,
+			events:         []string{"message.created"},
+			status:         500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodPost, "/room/:room_id/webhooks", tt.body, tt.isAdmin)
+			c.SetParamNames("room_id")
+			c.SetParamValues("test-room-id")
+
+			registerSvcMock := new(svc_mock.WebhookSvcMock)
+			registerSvcMock.On("Register", mock.MatchedBy(func(w model.Webhook) bool {
+				return w.RoomID == "test-room-id" && w.CreatedBy == "test-uuid-1234" && assert.ObjectsAreEqual(tt.events, w.Events)
+			}), mock.Anything).Return(model.Webhook{
+				ID:     webhookID,
+				RoomID: "test-room-id",
+				URL:    "https://example.com/hook",
+				Events: tt.events,
+				Secret: "generated-secret",
+			}, tt.registerErr)
+
+			handler := NewWebhookHandler(nil, nil, registerSvcMock, dto.NewWebhookDtoStruct())
+			err := handler.Create(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			registerSvcMock.AssertNumberOfCalls(t, "Register", tt.registerCalled)
+			if tt.status != http.StatusOK {
+				return
+			}
+
+			var result struct {
+				Webhook dto.WebhookResponse `json:"webhook"`
+				Secret  string              `json:"secret"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Equal(t, webhookID.Hex(), result.Webhook.ID)
+			assert.Equal(t, "generated-secret", result.Secret)
+		})
+	}
+}
+
+func TestWebhookDelete(t *testing.T) {
+	expected := map[string]struct {
+		isAdmin   bool
+		deleted   bool
+		deleteErr error
+		status    int
+	}{
+		"success":                 {isAdmin: true, deleted: true, status: 200},
+		"forbidden (not admin)":   {isAdmin: false, status: 403},
+		"not found":               {isAdmin: true, deleted: false, status: 404},
+		"failure to delete hooks": {isAdmin: true, deleteErr: assert.AnError, status: 500},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodDelete, "/room/:room_id/webhooks/:webhook_id", "", tt.isAdmin)
+			c.SetParamNames("room_id", "webhook_id")
+			c.SetParamValues("test-room-id", "webhook-id")
+
+			webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("DeleteWebhook", "webhook-id", "test-room-id", mock.Anything).Return(tt.deleted, tt.deleteErr)
+
+			handler := NewWebhookHandler(webhookSvcMock, nil, nil, dto.NewWebhookDtoStruct())
+			err := handler.Delete(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	webhookID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		isAdmin        bool
+		webhook        model.Webhook
+		webhookErr     error
+		deliveriesErr  error
+		getDeliveries  int
+		status         int
+		expectDelivery bool
+	}{
+		"success": {
+			isAdmin:        true,
+			webhook:        model.Webhook{ID: webhookID, RoomID: "test-room-id"},
+			getDeliveries:  1,
+			status:         200,
+			expectDelivery: true,
+		},
+		"forbidden (not admin)": {
+			isAdmin: false,
+			status:  403,
+		},
+		"not found": {
+			isAdmin:    true,
+			webhookErr: mongo.ErrNoDocuments,
+			status:     404,
+		},
+		"webhook of another room": {
+			isAdmin: true,
+			webhook: model.Webhook{ID: webhookID, RoomID: "other-room-id"},
+			status:  404,
+		},
+		"failure to get deliveries": {
+			isAdmin:       true,
+			webhook:       model.Webhook{ID: webhookID, RoomID: "test-room-id"},
+			deliveriesErr: assert.AnError,
+			getDeliveries: 1,
+			status:        500,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			c, rec := newWebhookContext(http.MethodGet, "/room/:room_id/webhooks/:webhook_id/deliveries", "", tt.isAdmin)
+			c.SetParamNames("room_id", "webhook_id")
+			c.SetParamValues("test-room-id", webhookID.Hex())
+
+			webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("GetWebhook", webhookID.Hex(), mock.Anything).Return(tt.webhook, tt.webhookErr)
+			deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+			deliverySvcMock.On("GetDeliveries", webhookID.Hex(), consts.WebhookDeliveryListLimit, mock.Anything).Return([]model.WebhookDelivery{
+				{ID: primitive.NewObjectID(), Event: consts.WebhookEvents.MessageCreated, Payload: `{"event":"message.created"}`, Status: consts.WebhookDeliveryStatus.Done},
+			}, tt.deliveriesErr)
+
+			handler := NewWebhookHandler(webhookSvcMock, deliverySvcMock, nil, dto.NewWebhookDtoStruct())
+			err := handler.Deliveries(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.status, rec.Code)
+			deliverySvcMock.AssertNumberOfCalls(t, "GetDeliveries", tt.getDeliveries)
+			if !tt.expectDelivery {
+				return
+			}
+
+			var result struct {
+				Deliveries []map[string]any `json:"deliveries"`
+			}
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+			assert.Len(t, result.Deliveries, 1)
+			assert.Equal(t, map[string]any{"event": "message.created"}, result.Deliveries[0]["Payload"])
+		})
+	}
+}
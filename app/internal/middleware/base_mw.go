@@ -1,13 +1,23 @@
 package middleware
 
 import (
+	"slices"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/labstack/echo/v4"
 )
 
 type Middleware struct {
-	Csrf echo.MiddlewareFunc
-	Jwt  echo.MiddlewareFunc
-	Room echo.MiddlewareFunc
+	Csrf      echo.MiddlewareFunc
+	Jwt       echo.MiddlewareFunc
+	Room      echo.MiddlewareFunc
+	Moderator echo.MiddlewareFunc
+	// ボットの API キーで認証する。API キーでないリクエストはそのまま通す
+	BotAPIKey echo.MiddlewareFunc
+	// 受信 Webhook の URL に含まれるトークンで認証する
+	IncomingWebhook echo.MiddlewareFunc
+	// consts.RateLimitGroups のグループ名をキーとする
+	RateLimit map[string]echo.MiddlewareFunc
 }
 
 func BeforeHandler(
@@ -43,3 +53,36 @@ func AfterHandler(
 		}
 	}
 }
+
+// 指定したルートではミドルウェアを通さずに次へ進める
+// JWT を持たない外部サービスから呼ばれるルートを、グローバルミドルウェアの対象から外すために使う
+func SkipRoutes(
+	mw echo.MiddlewareFunc,
+	paths ...string,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handler := mw(next)
+		return func(c echo.Context) error {
+			if slices.Contains(paths, c.Path()) {
+				return next(c)
+			}
+			return handler(c)
+		}
+	}
+}
+
+// ボットの API キーで認証済みのリクエストでは、ミドルウェアを通さずに次へ進める
+// API キーはヘッダーで送るので、Cookie を前提にした CSRF の確認も必要ない
+func SkipBotRequests(
+	mw echo.MiddlewareFunc,
+) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		handler := mw(next)
+		return func(c echo.Context) error {
+			if c.Get(consts.ContextKeys.Bot) != nil {
+				return next(c)
+			}
+			return handler(c)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"testing"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/labstack/echo/v4"
 )
 
@@ -85,3 +86,81 @@ func TestAfterHandlerForError(t *testing.T) {
 		t.Errorf("Expected error %v, got %v", expectedErr, err)
 	}
 }
+
+func TestSkipRoutes(t *testing.T) {
+	tests := map[string]struct {
+		path         string
+		expectCalled bool
+	}{
+		"skipped route":     {path: "/hooks/:webhook_id/:token", expectCalled: false},
+		"not skipped route": {path: "/room/:room_id", expectCalled: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			mw := SkipRoutes(BeforeHandler(func(c echo.Context) error {
+				called = true
+				return nil
+			}), "/hooks/:webhook_id/:token")
+
+			handlerCalled := false
+			handler := mw(func(c echo.Context) error {
+				handlerCalled = true
+				return nil
+			})
+
+			c := echo.New().NewContext(nil, nil)
+			c.SetPath(tt.path)
+			if err := handler(c); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if called != tt.expectCalled {
+				t.Errorf("Expected middleware called to be %v, got %v", tt.expectCalled, called)
+			}
+			if !handlerCalled {
+				t.Errorf("Expected handler to be called")
+			}
+		})
+	}
+}
+
+func TestSkipBotRequests(t *testing.T) {
+	tests := map[string]struct {
+		bot          any
+		expectCalled bool
+	}{
+		"bot request":  {bot: model.Bot{Name: "Deploy Bot"}, expectCalled: false},
+		"user request": {bot: nil, expectCalled: true},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			mw := SkipBotRequests(BeforeHandler(func(c echo.Context) error {
+				called = true
+				return nil
+			}))
+
+			handlerCalled := false
+			handler := mw(func(c echo.Context) error {
+				handlerCalled = true
+				return nil
+			})
+
+			c := echo.New().NewContext(nil, nil)
+			if tt.bot != nil {
+				c.Set("bot", tt.bot)
+			}
+			if err := handler(c); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if called != tt.expectCalled {
+				t.Errorf("Expected middleware called to be %v, got %v", tt.expectCalled, called)
+			}
+			if !handlerCalled {
+				t.Errorf("Expected handler to be called")
+			}
+		})
+	}
+}
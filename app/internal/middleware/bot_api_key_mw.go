@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type BotAPIKeyMiddlewareInterface interface {
+	Handler() echo.MiddlewareFunc
+}
+
+type BotAPIKeyMiddleware struct {
+	botSvc service.BotSvcInterface
+}
+
+func NewBotAPIKeyMiddleware(
+	botSvc service.BotSvcInterface,
+) BotAPIKeyMiddlewareInterface {
+	return &BotAPIKeyMiddleware{
+		botSvc: botSvc,
+	}
+}
+
+// Authorization ヘッダーにボットの API キーが指定されたリクエストを認証する
+// API キーでないリクエストはそのまま通し、JWTMiddleware に任せる（SkipBotRequests を参照）
+func (m *BotAPIKeyMiddleware) Handler() echo.MiddlewareFunc {
+	return BeforeHandler(func(c echo.Context) error {
+		apiKey, ok := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(apiKey, consts.BotAPIKeyPrefix) {
+			return nil
+		}
+
+		ctx := atylabmongo.NewMongoCtxSvc()
+		defer ctx.Cancel()
+
+		bot, key, err := m.botSvc.Authenticate(apiKey, ctx)
+		if errors.Is(err, service.ErrBotAPIKeyInvalid) {
+			return echo.NewHTTPError(http.StatusUnauthorized, echo.Map{
+				"error": err.Error(),
+			})
+		}
+		if err != nil {
+			fmt.Println("Failed to authenticate bot api key:", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, echo.Map{
+				"error": "failed to authenticate api key",
+			})
+		}
+
+		if err := service.CheckBotAPIKeyScope(key.Scope, c.Request().Method, c.Param("room_id")); err != nil {
+			return echo.NewHTTPError(http.StatusForbidden, echo.Map{
+				"error": err.Error(),
+			})
+		}
+
+		// ボットはルームのメンバーにも "bot:<ID>" で追加するので、ユーザーと同じく uuid として扱う
+		c.Set(consts.ContextKeys.Uuid, consts.BotSenderPrefix+bot.ID.Hex())
+		c.Set(consts.ContextKeys.Email, "")
+		c.Set(consts.ContextKeys.Bot, bot)
+		c.Set(consts.ContextKeys.BotAPIKey, key)
+
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestBotAPIKeyMiddlewareHandler(t *testing.T) {
+	bot := model.Bot{ID: primitive.NewObjectID(), OwnerID: "owner-uuid", Name: "Deploy Bot"}
+
+	tests := map[string]struct {
+		authorization string
+		method        string
+		roomID        string
+		scope         model.BotAPIKeyScope
+		authErr       error
+		authCalls     int
+		status        int
+		expectBot     bool
+	}{
+		"success": {
+			authorization: "Bearer bot_secret",
+			method:        http.MethodPost,
+			roomID:        "room1",
+			authCalls:     1,
+			status:        http.StatusOK,
+			expectBot:     true,
+		},
+		"not an api key (jwt)": {
+			authorization: "Bearer jwt-token",
+			method:        http.MethodPost,
+			roomID:        "room1",
+			status:        http.StatusOK,
+		},
+		"no authorization header": {
+			method: http.MethodGet,
+			roomID: "room1",
+			status: http.StatusOK,
+		},
+		"invalid api key": {
+			authorization: "Bearer bot_secret",
+			method:        http.MethodGet,
+			roomID:        "room1",
+			authErr:       service.ErrBotAPIKeyInvalid,
+			authCalls:     1,
+			status:        http.StatusUnauthorized,
+		},
+		"failure to authenticate": {
+			authorization: "Bearer bot_secret",
+			method:        http.MethodGet,
+			roomID:        "room1",
+			authErr:       assert.AnError,
+			authCalls:     1,
+			status:        http.StatusInternalServerError,
+		},
+		"read-only key": {
+			authorization: "Bearer bot_secret",
+			method:        http.MethodPost,
+			roomID:        "room1",
+			scope:         model.BotAPIKeyScope{ReadOnly: true},
+			authCalls:     1,
+			status:        http.StatusForbidden,
+		},
+		"key for another room": {
+			authorization: "Bearer bot_secret",
+			method:        http.MethodGet,
+			roomID:        "room2",
+			scope:         model.BotAPIKeyScope{RoomIDs: []string{"room1"}},
+			authCalls:     1,
+			status:        http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			key := model.BotAPIKey{ID: primitive.NewObjectID(), BotID: bot.ID.Hex(), Scope: tt.scope}
+			botSvcMock := new(svc_mock.BotSvcMock)
+			botSvcMock.On("Authenticate", "bot_secret", mock.Anything).Return(bot, key, tt.authErr)
+
+			e := echo.New()
+			e.Add(tt.method, "/test/:room_id", func(c echo.Context) error {
+				if tt.expectBot {
+					assert.Equal(t, "bot:"+bot.ID.Hex(), c.Get("uuid"))
+					assert.Equal(t, "", c.Get("email"))
+					assert.Equal(t, bot, c.Get("bot"))
+					assert.Equal(t, key, c.Get("bot_api_key"))
+				} else {
+					assert.Nil(t, c.Get("uuid"))
+					assert.Nil(t, c.Get("bot"))
+				}
+				return c.JSON(200, echo.Map{"message": "success"})
+			}, NewBotAPIKeyMiddleware(botSvcMock).Handler())
+
+			req := httptest.NewRequest(tt.method, "/test/"+tt.roomID, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			botSvcMock.AssertNumberOfCalls(t, "Authenticate", tt.authCalls)
+		})
+	}
+}
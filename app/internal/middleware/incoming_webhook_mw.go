@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/labstack/echo/v4"
+)
+
+type IncomingWebhookMiddlewareInterface interface {
+	Handler() echo.MiddlewareFunc
+}
+
+type IncomingWebhookMiddleware struct {
+	incomingWebhookSvc service.IncomingWebhookSvcInterface
+	roomSvc            service.RoomSvcInterface
+}
+
+func NewIncomingWebhookMiddleware(
+	incomingWebhookSvc service.IncomingWebhookSvcInterface,
+	roomSvc service.RoomSvcInterface,
+) IncomingWebhookMiddlewareInterface {
+	return &IncomingWebhookMiddleware{
+		incomingWebhookSvc: incomingWebhookSvc,
+		roomSvc:            roomSvc,
+	}
+}
+
+// JWT の代わりに URL のトークンで認証し、投稿先のルームを読み込む
+// 送信者には Webhook ごとの ID を設定するので、レート制限も Webhook 単位でかかる
+func (m *IncomingWebhookMiddleware) Handler() echo.MiddlewareFunc {
+	return BeforeHandler(func(c echo.Context) error {
+		ctx := atylabmongo.NewMongoCtxSvc()
+		defer ctx.Cancel()
+
+		webhook, err := m.incomingWebhookSvc.Authenticate(c.Param("webhook_id"), c.Param("token"), ctx)
+		switch {
+		case errors.Is(err, service.ErrIncomingWebhookNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "no_service")
+		case errors.Is(err, service.ErrIncomingWebhookInvalidToken):
+			return echo.NewHTTPError(http.StatusForbidden, "invalid_token")
+		case err != nil:
+			fmt.Println("Failed to authenticate incoming webhook:", err)
+			return echo.NewHTTPError(http.StatusInternalServerError, "internal_error")
+		}
+
+		room, err := m.roomSvc.GetRoom(webhook.RoomID, ctx)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, "channel_not_found")
+		}
+
+		c.Set(consts.ContextKeys.Uuid, consts.IncomingWebhookSenderPrefix+webhook.ID.Hex())
+		c.Set(consts.ContextKeys.RoomModel, room)
+		c.Set(consts.ContextKeys.IncomingWebhook, webhook)
+
+		return nil
+	})
+}
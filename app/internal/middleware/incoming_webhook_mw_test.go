@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIncomingWebhookMiddlewareHandler(t *testing.T) {
+	room := model.Room{ID: primitive.NewObjectID()}
+	webhook := model.IncomingWebhook{ID: primitive.NewObjectID(), RoomID: room.ID.Hex(), Name: "CI"}
+
+	tests := map[string]struct {
+		authErr      error
+		roomErr      error
+		getRoomCalls int
+		status       int
+	}{
+		"success":             {getRoomCalls: 1, status: http.StatusOK},
+		"webhook not found":   {authErr: service.ErrIncomingWebhookNotFound, status: http.StatusNotFound},
+		"invalid token":       {authErr: service.ErrIncomingWebhookInvalidToken, status: http.StatusForbidden},
+		"failure to auth":     {authErr: assert.AnError, status: http.StatusInternalServerError},
+		"room already closed": {roomErr: assert.AnError, getRoomCalls: 1, status: http.StatusNotFound},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			incomingWebhookSvcMock := new(svc_mock.IncomingWebhookSvcMock)
+			incomingWebhookSvcMock.On("Authenticate", webhook.ID.Hex(), "test-token", mock.Anything).Return(webhook, tt.authErr)
+			roomSvcMock := new(svc_mock.RoomSvcMock)
+			roomSvcMock.On("GetRoom", room.ID.Hex(), mock.Anything).Return(room, tt.roomErr)
+
+			e := echo.New()
+			e.POST("/hooks/:webhook_id/:token", func(c echo.Context) error {
+				assert.Equal(t, "webhook:"+webhook.ID.Hex(), c.Get("uuid"))
+				assert.Equal(t, room, c.Get("room_model"))
+				assert.Equal(t, webhook, c.Get("incoming_webhook"))
+				return c.String(http.StatusOK, "ok")
+			}, NewIncomingWebhookMiddleware(incomingWebhookSvcMock, roomSvcMock).Handler())
+
+			req := httptest.NewRequest(http.MethodPost, "/hooks/"+webhook.ID.Hex()+"/test-token", nil)
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			roomSvcMock.AssertNumberOfCalls(t, "GetRoom", tt.getRoomCalls)
+		})
+	}
+}
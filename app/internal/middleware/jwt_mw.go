@@ -32,6 +32,11 @@ func (m *JWTMiddleware) extractBearerToken(c echo.Context) string {
 	if strings.HasPrefix(authHeader, "Bearer ") {
 		return strings.TrimPrefix(authHeader, "Bearer ")
 	}
+	// EventSource はヘッダーを設定できないため、SSE のエンドポイントに限りクエリパラメータを受け付ける
+	// URL はアクセスログに残るので、他のエンドポイントでは受け付けない
+	if c.Path() == consts.SSEPath {
+		return c.QueryParam(consts.SSETokenQueryParam)
+	}
 	return ""
 }
 
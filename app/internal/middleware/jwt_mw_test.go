@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabjwt"
 	"github.com/labstack/echo/v4"
@@ -88,3 +89,38 @@ func TestJwtHandler_InvalidToken(t *testing.T) {
 		assert.Contains(t, w.Body.String(), assert.AnError.Error())
 	})
 }
+
+func TestJwtHandler_QueryToken(t *testing.T) {
+	funcs.WithEnv("JWT_SECRET_KEY", "testsecretkey", t, func() {
+		jwt := "tokenstring"
+		mockJwt := new(atylabjwt.JwtMock)
+		mockJwt.On("Validate", "testsecretkey", jwt).Return(nil)
+		mockJwt.On("GetUUID").Return("test-uuid")
+		mockJwt.On("GetEmail").Return("test@example.com")
+
+		e := echo.New()
+		e.Use(NewJWTMiddleware(mockJwt).Handler())
+		e.GET(consts.SSEPath, func(c echo.Context) error {
+			return c.JSON(200, echo.Map{"uuid": c.Get("uuid")})
+		})
+		e.GET("/test", func(c echo.Context) error {
+			return c.JSON(200, echo.Map{"uuid": c.Get("uuid")})
+		})
+
+		tests := map[string]struct {
+			path     string
+			expected int
+		}{
+			"sse endpoint":   {path: consts.SSEPath + "?token=" + jwt, expected: http.StatusOK},
+			"other endpoint": {path: "/test?token=" + jwt, expected: http.StatusUnauthorized},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+				w := httptest.NewRecorder()
+				e.ServeHTTP(w, req)
+				assert.Equal(t, tt.expected, w.Code)
+			})
+		}
+	})
+}
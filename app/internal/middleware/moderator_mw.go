@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/labstack/echo/v4"
+)
+
+type ModeratorMiddlewareInterface interface {
+	Handler() echo.MiddlewareFunc
+}
+
+type ModeratorMiddleware struct{}
+
+func NewModeratorMiddleware() ModeratorMiddlewareInterface {
+	return &ModeratorMiddleware{}
+}
+
+// MODERATOR_UUIDS はカンマ区切りでモデレーターのUUIDを指定する
+func (m *ModeratorMiddleware) moderators() []string {
+	moderators := []string{}
+	for _, uuid := range strings.Split(os.Getenv("MODERATOR_UUIDS"), ",") {
+		if uuid = strings.TrimSpace(uuid); uuid != "" {
+			moderators = append(moderators, uuid)
+		}
+	}
+	return moderators
+}
+
+func (m *ModeratorMiddleware) Handler() echo.MiddlewareFunc {
+	return BeforeHandler(func(c echo.Context) error {
+		uuid, _ := c.Get(consts.ContextKeys.Uuid).(string)
+		if uuid == "" || !slices.Contains(m.moderators(), uuid) {
+			return echo.NewHTTPError(http.StatusForbidden, echo.Map{
+				"error": "moderator only",
+			})
+		}
+
+		return nil
+	})
+}
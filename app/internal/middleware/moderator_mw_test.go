@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeratorHandler(t *testing.T) {
+	expected := map[string]struct {
+		moderators string
+		uuid       string
+		status     int
+	}{
+		"moderator": {
+			moderators: "moderator-uuid-1, moderator-uuid-2",
+			uuid:       "moderator-uuid-2",
+			status:     http.StatusOK,
+		},
+		"not moderator": {
+			moderators: "moderator-uuid-1,moderator-uuid-2",
+			uuid:       "test-uuid",
+			status:     http.StatusForbidden,
+		},
+		"no moderators configured": {
+			moderators: "",
+			uuid:       "test-uuid",
+			status:     http.StatusForbidden,
+		},
+		"empty uuid": {
+			moderators: "moderator-uuid-1,",
+			uuid:       "",
+			status:     http.StatusForbidden,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			funcs.WithEnv("MODERATOR_UUIDS", tt.moderators, t, func() {
+				e := echo.New()
+				e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+					return func(c echo.Context) error {
+						c.Set("uuid", tt.uuid)
+						return next(c)
+					}
+				})
+				e.Use(NewModeratorMiddleware().Handler())
+				e.GET("/test", func(c echo.Context) error {
+					return c.JSON(200, echo.Map{"message": "success"})
+				})
+
+				req := httptest.NewRequest(http.MethodGet, "/test", nil)
+				w := httptest.NewRecorder()
+				e.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.status, w.Code)
+			})
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/labstack/echo/v4"
+)
+
+type RateLimitMiddlewareInterface interface {
+	Handler() echo.MiddlewareFunc
+}
+
+type RateLimitMiddleware struct {
+	rateLimitSvc service.RateLimitSvcInterface
+	group        string
+	rule         consts.RateLimitRule
+}
+
+func NewRateLimitMiddleware(
+	rateLimitSvc service.RateLimitSvcInterface,
+	group string,
+) RateLimitMiddlewareInterface {
+	return &RateLimitMiddleware{
+		rateLimitSvc: rateLimitSvc,
+		group:        group,
+		rule:         loadRateLimitRule(group),
+	}
+}
+
+// consts.RateLimitRules の値を環境変数 RATE_LIMIT_<GROUP> で上書きする
+// 書式は "ユーザー単位の上限,ルーム単位の上限,ウィンドウ幅" (例: "60,20,1m")
+func loadRateLimitRule(group string) consts.RateLimitRule {
+	rule := consts.RateLimitRules[group]
+
+	value := os.Getenv("RATE_LIMIT_" + strings.ToUpper(group))
+	if value == "" {
+		return rule
+	}
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		fmt.Println("Invalid rate limit setting for", group, ":", value)
+		return rule
+	}
+	userLimit, err1 := strconv.Atoi(strings.TrimSpace(parts[0]))
+	roomLimit, err2 := strconv.Atoi(strings.TrimSpace(parts[1]))
+	window, err3 := time.ParseDuration(strings.TrimSpace(parts[2]))
+	if err1 != nil || err2 != nil || err3 != nil || userLimit <= 0 || roomLimit < 0 || window <= 0 {
+		fmt.Println("Invalid rate limit setting for", group, ":", value)
+		return rule
+	}
+
+	return consts.RateLimitRule{
+		UserLimit: userLimit,
+		RoomLimit: roomLimit,
+		Window:    window,
+	}
+}
+
+func (m *RateLimitMiddleware) keys(c echo.Context) []service.RateLimitKey {
+	uuid, _ := c.Get(consts.ContextKeys.Uuid).(string)
+	if uuid == "" || m.rule.UserLimit <= 0 {
+		return []service.RateLimitKey{}
+	}
+
+	keys := []service.RateLimitKey{
+		{
+			Key:   "ratelimit:" + m.group + ":user:" + uuid,
+			Limit: m.rule.UserLimit,
+		},
+	}
+
+	roomID := c.Param("room_id")
+	if roomID != "" && m.rule.RoomLimit > 0 {
+		keys = append(keys, service.RateLimitKey{
+			Key:   "ratelimit:" + m.group + ":room:" + roomID + ":user:" + uuid,
+			Limit: m.rule.RoomLimit,
+		})
+	}
+
+	return keys
+}
+
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+func (m *RateLimitMiddleware) Handler() echo.MiddlewareFunc {
+	return BeforeHandler(func(c echo.Context) error {
+		keys := m.keys(c)
+		if len(keys) == 0 {
+			return nil
+		}
+
+		result, err := m.rateLimitSvc.Allow(keys, m.rule.Window)
+		if err != nil {
+			// Redisの障害でチャット全体が使えなくなるのを避けるため、判定できない場合は通過させる
+			fmt.Println("Failed to check rate limit:", err)
+			return nil
+		}
+
+		header := c.Response().Header()
+		header.Set("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		header.Set("X-RateLimit-Reset", seconds(result.ResetAfter))
+
+		if !result.Allowed {
+			header.Set("Retry-After", seconds(result.RetryAfter))
+			return echo.NewHTTPError(http.StatusTooManyRequests, echo.Map{
+				"error": "too many requests",
+			})
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimitHandler(t *testing.T) {
+	rule := consts.RateLimitRules[consts.RateLimitGroups.MessageSend]
+
+	expected := map[string]struct {
+		uuid         string
+		path         string
+		expectKeys   []service.RateLimitKey
+		result       service.RateLimitResult
+		err          error
+		status       int
+		expectHeader map[string]string
+	}{
+		"allowed (user and room)": {
+			uuid: "test-uuid",
+			path: "/test/room-1",
+			expectKeys: []service.RateLimitKey{
+				{Key: "ratelimit:message_send:user:test-uuid", Limit: rule.UserLimit},
+				{Key: "ratelimit:message_send:room:room-1:user:test-uuid", Limit: rule.RoomLimit},
+			},
+			result: service.RateLimitResult{Allowed: true, Limit: 20, Remaining: 19, ResetAfter: 60 * time.Second},
+			status: http.StatusOK,
+			expectHeader: map[string]string{
+				"X-RateLimit-Limit":     "20",
+				"X-RateLimit-Remaining": "19",
+				"X-RateLimit-Reset":     "60",
+				"Retry-After":           "",
+			},
+		},
+		"blocked": {
+			uuid: "test-uuid",
+			path: "/test/room-1",
+			expectKeys: []service.RateLimitKey{
+				{Key: "ratelimit:message_send:user:test-uuid", Limit: rule.UserLimit},
+				{Key: "ratelimit:message_send:room:room-1:user:test-uuid", Limit: rule.RoomLimit},
+			},
+			result: service.RateLimitResult{Allowed: false, Limit: 20, Remaining: 0, ResetAfter: 1500 * time.Millisecond, RetryAfter: 1500 * time.Millisecond},
+			status: http.StatusTooManyRequests,
+			expectHeader: map[string]string{
+				"X-RateLimit-Limit":     "20",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "2",
+				"Retry-After":           "2",
+			},
+		},
+		"allowed (user only)": {
+			uuid: "test-uuid",
+			path: "/test",
+			expectKeys: []service.RateLimitKey{
+				{Key: "ratelimit:message_send:user:test-uuid", Limit: rule.UserLimit},
+			},
+			result: service.RateLimitResult{Allowed: true, Limit: 60, Remaining: 59, ResetAfter: 60 * time.Second},
+			status: http.StatusOK,
+			expectHeader: map[string]string{
+				"X-RateLimit-Limit": "60",
+			},
+		},
+		"fail open on error": {
+			uuid: "test-uuid",
+			path: "/test",
+			expectKeys: []service.RateLimitKey{
+				{Key: "ratelimit:message_send:user:test-uuid", Limit: rule.UserLimit},
+			},
+			err:    assert.AnError,
+			status: http.StatusOK,
+			expectHeader: map[string]string{
+				"X-RateLimit-Limit": "",
+			},
+		},
+		"no uuid": {
+			uuid:   "",
+			path:   "/test",
+			status: http.StatusOK,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			rateLimitSvcMock := new(svc_mock.RateLimitSvcMock)
+			rateLimitSvcMock.On("Allow", tt.expectKeys, rule.Window).Return(tt.result, tt.err)
+
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set("uuid", tt.uuid)
+					return next(c)
+				}
+			})
+			g := e.Group("/test", NewRateLimitMiddleware(rateLimitSvcMock, consts.RateLimitGroups.MessageSend).Handler())
+			g.GET("", func(c echo.Context) error {
+				return c.JSON(200, echo.Map{"message": "success"})
+			})
+			g.GET("/:room_id", func(c echo.Context) error {
+				return c.JSON(200, echo.Map{"message": "success"})
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			for key, value := range tt.expectHeader {
+				assert.Equal(t, value, w.Header().Get(key), key)
+			}
+			if tt.expectKeys == nil {
+				rateLimitSvcMock.AssertNotCalled(t, "Allow", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestLoadRateLimitRule(t *testing.T) {
+	defaultRule := consts.RateLimitRules[consts.RateLimitGroups.MessageSend]
+
+	expected := map[string]struct {
+		env    string
+		expect consts.RateLimitRule
+	}{
+		"default": {
+			env:    "",
+			expect: defaultRule,
+		},
+		"override": {
+			env:    "10, 5, 30s",
+			expect: consts.RateLimitRule{UserLimit: 10, RoomLimit: 5, Window: 30 * time.Second},
+		},
+		"invalid format": {
+			env:    "10,5",
+			expect: defaultRule,
+		},
+		"invalid number": {
+			env:    "ten,5,30s",
+			expect: defaultRule,
+		},
+		"invalid window": {
+			env:    "10,5,0s",
+			expect: defaultRule,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			funcs.WithEnv("RATE_LIMIT_MESSAGE_SEND", tt.env, t, func() {
+				assert.Equal(t, tt.expect, loadRateLimitRule(consts.RateLimitGroups.MessageSend))
+			})
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+const ArchivedMessageCollectionName = "archived_messages"
+
+// 保存期間を過ぎてアーカイブしたメッセージ（_id は元のメッセージのものを引き継ぐ）
+type ArchivedMessage struct {
+	Message    `bson:",inline"`
+	ArchivedAt time.Time `bson:"archivedAt"`
+}
@@ -0,0 +1,22 @@
+package model
+
+type Attachment struct {
+	ID          string `bson:"id"`
+	Name        string `bson:"name"`
+	ContentType string `bson:"contentType"`
+	Size        int64  `bson:"size"`
+	StorageKey  string `bson:"storageKey"`
+	// 画像の場合のみ、サムネイル生成後に設定される
+	Width      int                   `bson:"width,omitempty"`
+	Height     int                   `bson:"height,omitempty"`
+	Thumbnails []AttachmentThumbnail `bson:"thumbnails,omitempty"`
+}
+
+type AttachmentThumbnail struct {
+	Size        string `bson:"size"`
+	Width       int    `bson:"width"`
+	Height      int    `bson:"height"`
+	ContentType string `bson:"contentType"`
+	ByteSize    int64  `bson:"byteSize"`
+	StorageKey  string `bson:"storageKey"`
+}
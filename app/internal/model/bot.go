@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	BotCollectionName       = "bots"
+	BotAPIKeyCollectionName = "bot_api_keys"
+)
+
+// ユーザーが所有するボット。API キーで認証し、ユーザーとは別の送信者としてメッセージを送る
+// ルームには通常のメンバーと同じく "bot:<ID>" で追加する
+type Bot struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	OwnerID   string             `bson:"ownerid"`
+	Name      string             `bson:"name"`
+	IconURL   string             `bson:"iconUrl,omitempty"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+type BotAPIKey struct {
+	ID    primitive.ObjectID `bson:"_id,omitempty"`
+	BotID string             `bson:"botid"`
+	Name  string             `bson:"name"`
+	// 一覧で見分けるための API キーの先頭部分と、API キー全体の SHA-256（API キー自体は発行時にだけ返し、保存しない）
+	DisplayPrefix string         `bson:"displayPrefix"`
+	KeyHash       string         `bson:"keyHash"`
+	Scope         BotAPIKeyScope `bson:"scope"`
+	CreatedAt     time.Time      `bson:"createdAt"`
+	// 失効させた API キーは残しておき、認証には使えなくする
+	RevokedAt *time.Time `bson:"revokedAt,omitempty"`
+}
+
+// API キーで実行できる操作の範囲
+type BotAPIKeyScope struct {
+	// 読み取り（GET・HEAD）のリクエストだけを受け付ける
+	ReadOnly bool `bson:"readOnly"`
+	// 空でない場合は、ここに含まれるルームへのリクエストだけを受け付ける
+	RoomIDs []string `bson:"roomIds,omitempty"`
+}
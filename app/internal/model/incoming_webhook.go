@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const IncomingWebhookCollectionName = "incoming_webhooks"
+
+// 外部のサービスからルームにメッセージを投稿するための受信 Webhook
+type IncomingWebhook struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	RoomID string             `bson:"roomid"`
+	// 投稿したメッセージの送信者として表示する名前とアイコン
+	Name    string `bson:"name"`
+	IconURL string `bson:"iconUrl,omitempty"`
+	// トークンの SHA-256（トークン自体は作成時にだけ返し、保存しない）
+	TokenHash string    `bson:"tokenHash"`
+	CreatedBy string    `bson:"createdBy"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
@@ -0,0 +1,151 @@
+package model
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 起動時に作成するインデックス（コレクション名ごと）
+var MongoIndexes = map[string][]mongo.IndexModel{
+	MessageCollectionName: {
+		{
+			// 再送されたメッセージを重複登録しないためのインデックス
+			// client_msg_id を指定しない送信は対象外にする
+			Keys: bson.D{{Key: "roomid", Value: 1}, {Key: "sender", Value: 1}, {Key: "clientMsgId", Value: 1}},
+			Options: options.Index().
+				SetName("roomid_sender_clientMsgId").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"clientMsgId": bson.M{"$exists": true}}),
+		},
+		{
+			// ルームのピン留め一覧を取得するためのインデックス
+			Keys: bson.D{{Key: "roomid", Value: 1}, {Key: "pin.pinnedAt", Value: -1}},
+			Options: options.Index().
+				SetName("roomid_pin_pinnedAt").
+				SetPartialFilterExpression(bson.M{"pin": bson.M{"$exists": true}}),
+		},
+		{
+			// 保存期間を過ぎたメッセージをルームごとに探し、エクスポートで投稿順に並べるためのインデックス
+			Keys:    bson.D{{Key: "roomid", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}},
+			Options: options.Index().SetName("roomid_createdAt_id"),
+		},
+		{
+			// 削除したメッセージの一覧と、猶予期間を過ぎたメッセージを探すためのインデックス
+			Keys: bson.D{{Key: "deletedAt", Value: 1}},
+			Options: options.Index().
+				SetName("deletedAt").
+				SetSparse(true),
+		},
+		{
+			// 有効期限を過ぎたメッセージを探す・削除するためのインデックス
+			Keys: bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().
+				SetName("expiresAt_ttl").
+				SetExpireAfterSeconds(int32(consts.MessageExpiryGrace.Seconds())),
+		},
+	},
+	RoomCollectionName: {
+		{
+			// 取り込み元のIDでルームを探し、同じルームを重複して作らないためのインデックス
+			Keys: bson.D{{Key: "external_id", Value: 1}},
+			Options: options.Index().
+				SetName("external_id").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"external_id": bson.M{"$exists": true}}),
+		},
+	},
+	ThumbnailJobCollectionName: {
+		{
+			// ワーカーが実行可能なジョブを探すためのインデックス
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "availableAt", Value: 1}},
+			Options: options.Index().SetName("status_availableAt"),
+		},
+	},
+	WebhookCollectionName: {
+		{
+			// ルームの Webhook の一覧と、イベントを購読している Webhook を探すためのインデックス
+			Keys:    bson.D{{Key: "roomid", Value: 1}, {Key: "events", Value: 1}},
+			Options: options.Index().SetName("roomid_events"),
+		},
+	},
+	WebhookDeliveryCollectionName: {
+		{
+			// ワーカーが送信時刻を過ぎた配信を探すためのインデックス
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "availableAt", Value: 1}},
+			Options: options.Index().SetName("status_availableAt"),
+		},
+		{
+			// Webhook ごとの配信履歴を取得するためのインデックス
+			Keys:    bson.D{{Key: "webhookid", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("webhookid_createdAt"),
+		},
+	},
+	IncomingWebhookCollectionName: {
+		{
+			// ルームの受信 Webhook の一覧を取得するためのインデックス
+			Keys:    bson.D{{Key: "roomid", Value: 1}},
+			Options: options.Index().SetName("roomid"),
+		},
+	},
+	BotCollectionName: {
+		{
+			// ユーザーが所有するボットの一覧を取得するためのインデックス
+			Keys:    bson.D{{Key: "ownerid", Value: 1}},
+			Options: options.Index().SetName("ownerid"),
+		},
+	},
+	BotAPIKeyCollectionName: {
+		{
+			// リクエストの API キーを探すためのインデックス
+			Keys: bson.D{{Key: "keyHash", Value: 1}},
+			Options: options.Index().
+				SetName("keyHash").
+				SetUnique(true),
+		},
+		{
+			// ボットの API キーの一覧を取得するためのインデックス
+			Keys:    bson.D{{Key: "botid", Value: 1}},
+			Options: options.Index().SetName("botid"),
+		},
+	},
+	SlashCommandCollectionName: {
+		{
+			// 実行されたコマンドを名前で探すためのインデックス（同じ名前は登録できない）
+			Keys: bson.D{{Key: "name", Value: 1}},
+			Options: options.Index().
+				SetName("name").
+				SetUnique(true),
+		},
+		{
+			// ボットが登録したコマンドの一覧を取得するためのインデックス
+			Keys:    bson.D{{Key: "botid", Value: 1}},
+			Options: options.Index().SetName("botid"),
+		},
+	},
+	ReminderCollectionName: {
+		{
+			// ディスパッチャーが通知時刻を過ぎたリマインダーを探すためのインデックス
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "availableAt", Value: 1}},
+			Options: options.Index().SetName("status_availableAt"),
+		},
+		{
+			// 設定したリマインダーの一覧を取得するためのインデックス
+			Keys:    bson.D{{Key: "userid", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("userid_status"),
+		},
+	},
+	ScheduledMessageCollectionName: {
+		{
+			// ディスパッチャーが送信時刻を過ぎたメッセージを探すためのインデックス
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "availableAt", Value: 1}},
+			Options: options.Index().SetName("status_availableAt"),
+		},
+		{
+			// 予約したメッセージの一覧を取得するためのインデックス
+			Keys:    bson.D{{Key: "roomid", Value: 1}, {Key: "sender", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("roomid_sender_status"),
+		},
+	},
+}
@@ -0,0 +1,10 @@
+package model
+
+// メッセージ中のURLから取得したページの情報（OGP）
+type LinkPreview struct {
+	URL         string `bson:"url"`
+	Title       string `bson:"title"`
+	Description string `bson:"description,omitempty"`
+	ImageURL    string `bson:"imageUrl,omitempty"`
+	SiteName    string `bson:"siteName,omitempty"`
+}
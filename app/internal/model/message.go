@@ -15,4 +15,74 @@ type Message struct {
 	Message       string             `bson:"message"`
 	CreatedAt     time.Time          `bson:"createdAt"`
 	IsReadUserIds []string           `bson:"isReadUserIds"`
+	// 既読にした日時（IsReadUserIds に追加したときに記録する）
+	// 記録を始める前に既読になったユーザーや、送信者自身は含まれない
+	ReadReceipts []ReadReceipt `bson:"readReceipts,omitempty"`
+	ClientMsgID  string        `bson:"clientMsgId,omitempty"`
+	Attachments  []Attachment  `bson:"attachments,omitempty"`
+	LinkPreviews []LinkPreview `bson:"linkPreviews,omitempty"`
+	Pin          *MessagePin   `bson:"pin,omitempty"`
+	// 有効期限（期限のないメッセージは nil）
+	ExpiresAt *time.Time `bson:"expiresAt,omitempty"`
+	// 削除した日時と削除したユーザー（削除されていないメッセージは nil・空）
+	// 削除しても猶予期間が過ぎるまでは残し、一覧では本文を伏せて返す
+	DeletedAt *time.Time `bson:"deletedAt,omitempty"`
+	DeletedBy string     `bson:"deletedBy,omitempty"`
+	// ユーザー以外（受信 Webhook など）が送信したメッセージの送信元（ユーザーの送信は nil）
+	Bot *MessageBot `bson:"bot,omitempty"`
+	// メッセージの種類（consts.MessageTypes）。通常のテキストは空
+	Type string `bson:"type,omitempty"`
+	// 投票のメッセージの選択肢と投票（投票以外のメッセージは nil）
+	Poll *MessagePoll `bson:"poll,omitempty"`
+	// 通知用ルームに届けたリマインダーの情報（リマインダー以外のメッセージは nil）
+	Reminder *MessageReminder `bson:"reminder,omitempty"`
+}
+
+type ReadReceipt struct {
+	UserID string    `bson:"userId"`
+	ReadAt time.Time `bson:"readAt"`
+}
+
+// ピン留めしたモデレーターと日時（ピン留めされていないメッセージは nil）
+type MessagePin struct {
+	PinnedBy string    `bson:"pinnedBy"`
+	PinnedAt time.Time `bson:"pinnedAt"`
+}
+
+type MessagePoll struct {
+	Question string `bson:"question"`
+	// 投票では選択肢を添字で指定する
+	Options        []string `bson:"options"`
+	MultipleChoice bool     `bson:"multipleChoice"`
+	// 匿名の投票でも二重に投票させないために投票者は記録し、返すときに伏せる
+	Anonymous bool `bson:"anonymous"`
+	// 締め切り（締め切りのない投票は nil）。過ぎると投票できなくなる
+	ClosesAt *time.Time `bson:"closesAt,omitempty"`
+	// 締め切りの前に締め切った日時とユーザー（締め切っていない投票は nil・空）
+	ClosedAt *time.Time `bson:"closedAt,omitempty"`
+	ClosedBy string     `bson:"closedBy,omitempty"`
+	// 1人1件。投票し直した場合は置き換える
+	Votes []PollVote `bson:"votes"`
+}
+
+type PollVote struct {
+	UserID  string    `bson:"userId"`
+	Options []int     `bson:"options"`
+	VotedAt time.Time `bson:"votedAt"`
+}
+
+// スヌーズや取り消しに使うリマインダーのIDと、設定した元のメッセージ（内容だけのリマインダーは空）
+type MessageReminder struct {
+	ReminderID string `bson:"reminderId"`
+	RoomID     string `bson:"roomId,omitempty"`
+	MessageID  string `bson:"messageId,omitempty"`
+}
+
+// メッセージを送信したボットの表示情報
+// 送信後に名前やアイコンが変わっても、送信した時点の表示のまま残す
+type MessageBot struct {
+	Kind    string `bson:"kind"`
+	ID      string `bson:"id"`
+	Name    string `bson:"name"`
+	IconURL string `bson:"iconUrl,omitempty"`
 }
@@ -0,0 +1,7 @@
+package model
+
+// ユーザーのオンライン状態（Redis にだけ保存し、Mongo には保存しない）
+type Presence struct {
+	Status     string `json:"status"`
+	StatusText string `json:"status_text"`
+}
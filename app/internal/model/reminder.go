@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const ReminderCollectionName = "reminders"
+
+// メッセージに設定したリマインダーは RoomID・MessageID と、設定した時点の本文（Snapshot）を持つ
+// 内容だけのリマインダーはどちらも空
+type Reminder struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	UserID    string             `bson:"userid"`
+	RoomID    string             `bson:"roomid,omitempty"`
+	MessageID string             `bson:"messageid,omitempty"`
+	Snapshot  string             `bson:"snapshot,omitempty"`
+	Text      string             `bson:"text"`
+	RemindAt  time.Time          `bson:"remindAt"`
+	Status    string             `bson:"status"`
+	Attempts  int                `bson:"attempts"`
+	// pending: 通知する時刻 / sending: リースの期限
+	AvailableAt time.Time `bson:"availableAt"`
+	// 通知用ルームに届けたメッセージのID（スヌーズして再通知した場合は最後のもの）
+	NotificationID string    `bson:"notificationid,omitempty"`
+	LastError      string    `bson:"lastError,omitempty"`
+	CreatedAt      time.Time `bson:"createdAt"`
+	UpdatedAt      time.Time `bson:"updatedAt"`
+}
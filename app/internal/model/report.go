@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const ReportCollectionName = "reports"
+
+type Report struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty"`
+	RoomID         string             `bson:"roomid"`
+	MessageID      string             `bson:"messageid"`
+	ReporterID     string             `bson:"reporter"`
+	ReportedUserID string             `bson:"reportedUser"`
+	Reason         string             `bson:"reason"`
+	Comment        string             `bson:"comment"`
+	Snapshot       string             `bson:"snapshot,omitempty"` // 通報時点の本文（拒否された投稿の確認用）
+	Status         string             `bson:"status"`
+	CreatedAt      time.Time          `bson:"createdAt"`
+	Resolution     *ReportResolution  `bson:"resolution,omitempty"`
+}
+
+type ReportResolution struct {
+	Action     string    `bson:"action"`
+	ResolvedBy string    `bson:"resolvedBy"`
+	Note       string    `bson:"note"`
+	ResolvedAt time.Time `bson:"resolvedAt"`
+}
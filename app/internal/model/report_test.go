@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportModel(t *testing.T) {
+	timeNow := time.Now()
+	report := Report{
+		RoomID:         "room123",
+		MessageID:      "message456",
+		ReporterID:     "789",
+		ReportedUserID: "101",
+		Reason:         "spam",
+		Status:         "open",
+		CreatedAt:      timeNow,
+		Resolution: &ReportResolution{
+			Action:     "dismissed",
+			ResolvedBy: "moderator",
+			ResolvedAt: timeNow,
+		},
+	}
+
+	if report.RoomID != "room123" {
+		t.Errorf("Expected RoomID to be 'room123', got %s", report.RoomID)
+	}
+	if report.MessageID != "message456" {
+		t.Errorf("Expected MessageID to be 'message456', got %s", report.MessageID)
+	}
+	if report.ReporterID != "789" {
+		t.Errorf("Expected ReporterID to be '789', got %s", report.ReporterID)
+	}
+	if report.ReportedUserID != "101" {
+		t.Errorf("Expected ReportedUserID to be '101', got %s", report.ReportedUserID)
+	}
+	if report.Reason != "spam" {
+		t.Errorf("Expected Reason to be 'spam', got %s", report.Reason)
+	}
+	if !report.CreatedAt.Equal(timeNow) {
+		t.Errorf("Expected CreatedAt to be %v, got %v", timeNow, report.CreatedAt)
+	}
+	if report.Resolution.Action != "dismissed" {
+		t.Errorf("Expected Resolution.Action to be 'dismissed', got %s", report.Resolution.Action)
+	}
+}
@@ -9,10 +9,24 @@ import (
 const RoomCollectionName = "rooms"
 
 type Room struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty"`
-	Name      string             `bson:"name"`
-	OwnerID   string             `bson:"owner"`
-	CreatedAt time.Time          `bson:"created_at"`
-	Members   []string           `bson:"members"`
-	IsPrivate bool               `bson:"is_private"`
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	Name          string             `bson:"name"`
+	OwnerID       string             `bson:"owner"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	Members       []string           `bson:"members"`
+	IsPrivate     bool               `bson:"is_private"`
+	BannedMembers []string           `bson:"banned_members"`
+	// メッセージの既定の有効期間（秒）。0 の場合は期限なし
+	MessageTTL int `bson:"message_ttl,omitempty"`
+	// メッセージの保存期間（日）。0 の場合は無期限に保存する
+	RetentionDays int `bson:"retention_days,omitempty"`
+	// true の場合、既読の情報をメッセージの送信者にだけ見せる
+	HideReadReceipts bool `bson:"hide_read_receipts,omitempty"`
+	// 他のサービスから取り込んだルームの取り込み元のID（例: slack:C0123）
+	// 再実行時に同じルームを重複して作らないために使う
+	ExternalID string `bson:"external_id,omitempty"`
+	// ルームのトピック（/topic で変更する）
+	Topic string `bson:"topic,omitempty"`
+	// 通知を止めたメンバー（/mute で切り替える）。メッセージやイベントは届き、通知を出すかはクライアントが判断する
+	MutedMembers []string `bson:"muted_members,omitempty"`
 }
@@ -4,4 +4,6 @@ type RoomMember struct {
 	Uuid  string `json:"uuid"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	// メンバー一覧を返す場合にだけ設定する
+	Presence *Presence `json:"presence,omitempty"`
 }
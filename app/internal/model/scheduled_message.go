@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const ScheduledMessageCollectionName = "scheduled_messages"
+
+type ScheduledMessage struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	RoomID      string             `bson:"roomid"`
+	Sender      string             `bson:"sender"`
+	Message     string             `bson:"message"`
+	SendAt      time.Time          `bson:"sendAt"`
+	Status      string             `bson:"status"`
+	Attempts    int                `bson:"attempts"`
+	AvailableAt time.Time          `bson:"availableAt"` // pending: 送信する時刻 / sending: リースの期限
+	MessageID   string             `bson:"messageid,omitempty"`
+	LastError   string             `bson:"lastError,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt"`
+}
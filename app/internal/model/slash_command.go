@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const SlashCommandCollectionName = "slash_commands"
+
+// ボットが登録した外部のコマンド
+// 実行されると URL に内容を POST し、応答を実行したユーザーかルームに返す
+type SlashCommand struct {
+	ID    primitive.ObjectID `bson:"_id,omitempty"`
+	BotID string             `bson:"botid"`
+	// 先頭の / を除いたコマンド名。全ルームで共通なので重複しない
+	Name        string `bson:"name"`
+	Description string `bson:"description,omitempty"`
+	URL         string `bson:"url"`
+	// 呼び出しの本文に署名する鍵（Webhook と同じ方式）
+	Secret    string    `bson:"secret"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
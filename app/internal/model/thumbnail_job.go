@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const ThumbnailJobCollectionName = "thumbnail_jobs"
+
+type ThumbnailJob struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	RoomID       string             `bson:"roomid"`
+	MessageID    string             `bson:"messageid"`
+	AttachmentID string             `bson:"attachmentId"`
+	StorageKey   string             `bson:"storageKey"`
+	Status       string             `bson:"status"`
+	Attempts     int                `bson:"attempts"`
+	AvailableAt  time.Time          `bson:"availableAt"` // pending: 実行可能になる時刻 / processing: リースの期限
+	LastError    string             `bson:"lastError,omitempty"`
+	CreatedAt    time.Time          `bson:"createdAt"`
+	UpdatedAt    time.Time          `bson:"updatedAt"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const WebhookCollectionName = "webhooks"
+const WebhookDeliveryCollectionName = "webhook_deliveries"
+
+// ルームのイベントを外部のサービスに送信する Webhook
+type Webhook struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty"`
+	RoomID string             `bson:"roomid"`
+	URL    string             `bson:"url"`
+	Events []string           `bson:"events"`
+	// 署名に使う鍵。登録時にだけ返し、一覧では返さない
+	Secret    string    `bson:"secret"`
+	CreatedBy string    `bson:"createdBy"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+// Webhook への1回分の配信
+// 再送しても同じ内容になるよう、本文は登録時に作って保存しておく
+type WebhookDelivery struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	WebhookID   string             `bson:"webhookid"`
+	RoomID      string             `bson:"roomid"`
+	Event       string             `bson:"event"`
+	Payload     string             `bson:"payload"`
+	Status      string             `bson:"status"`
+	Attempts    int                `bson:"attempts"`
+	AvailableAt time.Time          `bson:"availableAt"` // pending: 送信する時刻 / processing: リースの期限
+	LastError   string             `bson:"lastError,omitempty"`
+	History     []WebhookAttempt   `bson:"history,omitempty"`
+	CreatedAt   time.Time          `bson:"createdAt"`
+	UpdatedAt   time.Time          `bson:"updatedAt"`
+}
+
+// 配信を試みた結果（接続できなかった場合は StatusCode が 0 になる）
+type WebhookAttempt struct {
+	AttemptedAt time.Time `bson:"attemptedAt"`
+	StatusCode  int       `bson:"statusCode"`
+	Error       string    `bson:"error,omitempty"`
+	DurationMs  int64     `bson:"durationMs"`
+}
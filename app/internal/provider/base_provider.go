@@ -3,8 +3,11 @@ package provider
 import "github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 
 type Provider struct {
-	mongo *usecase.Mongo
-	redis *usecase.Redis
+	mongo   *usecase.Mongo
+	redis   *usecase.Redis
+	storage *usecase.Storage
+	// 並び順の指定などで使うドライバーへの接続。接続は初回利用時に作り、以降は使い回す
+	mongoDriver *usecase.MongoDriverUseCaseStruct
 }
 
 func NewProvider(
@@ -14,5 +17,8 @@ func NewProvider(
 	return &Provider{
 		mongo: mongo,
 		redis: redis,
+		// 添付ファイルの保存先は初回利用時に接続する
+		storage:     usecase.NewStorage(),
+		mongoDriver: usecase.NewMongoDriverUseCaseStruct(),
 	}
 }
@@ -3,6 +3,7 @@ package provider
 import (
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
 )
 
 func (p *Provider) BindHealthCheckHandler() *handler.HealthCheckHandler {
@@ -13,6 +14,10 @@ func (p *Provider) BindRoomHandler() *handler.RoomHandler {
 	return handler.NewRoomHandler(
 		p.bindMongoRoomSvc(),
 		p.bindRoomSvc(),
+		p.bindExportSvc(),
+		p.BindPresenceSvc(),
+		p.BindWebhookSvc(),
+		p.bindEventSvc(),
 		dto.NewRoomDtoStruct(),
 	)
 }
@@ -20,6 +25,117 @@ func (p *Provider) BindRoomHandler() *handler.RoomHandler {
 func (p *Provider) BindMessageHandler() *handler.MessageHandler {
 	return handler.NewMessageHandler(
 		p.bindMongoMessageSvc(),
+		p.bindMessageSvc(),
+		p.bindMongoReportSvc(),
+		p.bindPinSvc(),
+		p.bindSlashCommandSvc(),
 		dto.NewMessageDtoStruct(),
 	)
 }
+
+func (p *Provider) BindReceiptHandler() *handler.ReceiptHandler {
+	return handler.NewReceiptHandler(
+		p.bindMongoMessageSvc(),
+		p.bindRoomSvc(),
+		dto.NewMessageDtoStruct(),
+	)
+}
+
+func (p *Provider) BindAttachmentHandler() *handler.AttachmentHandler {
+	return handler.NewAttachmentHandler(
+		p.bindMongoMessageSvc(),
+		p.bindMessageSvc(),
+		p.bindAttachmentSvc(),
+		p.BindThumbnailSvc(),
+	)
+}
+
+func (p *Provider) BindScheduledMessageHandler() *handler.ScheduledMessageHandler {
+	return handler.NewScheduledMessageHandler(
+		p.bindMongoScheduledMessageSvc(),
+		p.BindScheduledMessageSvc(),
+		dto.NewScheduledMessageDtoStruct(),
+	)
+}
+
+func (p *Provider) BindModerationHandler() *handler.ModerationHandler {
+	return handler.NewModerationHandler(
+		p.bindMongoReportSvc(),
+		p.bindModerationSvc(),
+		dto.NewReportDtoStruct(),
+		dto.NewMessageDtoStruct(),
+	)
+}
+
+func (p *Provider) BindEventHandler() *handler.EventHandler {
+	return handler.NewEventHandler(
+		p.bindMongoRoomSvc(),
+		p.bindEventSvc(),
+		p.BindPresenceSvc(),
+	)
+}
+
+func (p *Provider) BindPresenceHandler() *handler.PresenceHandler {
+	return handler.NewPresenceHandler(
+		p.BindPresenceSvc(),
+	)
+}
+
+func (p *Provider) BindTypingHandler() *handler.TypingHandler {
+	return handler.NewTypingHandler(
+		p.bindTypingSvc(),
+	)
+}
+
+func (p *Provider) BindWebhookHandler() *handler.WebhookHandler {
+	return handler.NewWebhookHandler(
+		p.bindMongoWebhookSvc(),
+		p.bindMongoWebhookDeliverySvc(),
+		p.BindWebhookSvc(),
+		dto.NewWebhookDtoStruct(),
+	)
+}
+
+func (p *Provider) BindIncomingWebhookHandler() *handler.IncomingWebhookHandler {
+	return handler.NewIncomingWebhookHandler(
+		p.bindMongoIncomingWebhookSvc(),
+		p.bindIncomingWebhookSvc(),
+		dto.NewIncomingWebhookDtoStruct(),
+	)
+}
+
+func (p *Provider) BindBotHandler() *handler.BotHandler {
+	return handler.NewBotHandler(
+		p.bindMongoBotSvc(),
+		p.bindMongoBotAPIKeySvc(),
+		p.bindBotSvc(),
+		atylabclock.NewClock(),
+		dto.NewBotDtoStruct(),
+	)
+}
+
+func (p *Provider) BindSlashCommandHandler() *handler.SlashCommandHandler {
+	return handler.NewSlashCommandHandler(
+		p.bindMongoSlashCommandSvc(),
+		p.bindMongoBotSvc(),
+		p.bindSlashCommandSvc(),
+		dto.NewSlashCommandDtoStruct(),
+	)
+}
+
+func (p *Provider) BindPollHandler() *handler.PollHandler {
+	return handler.NewPollHandler(
+		p.bindMongoMessageSvc(),
+		p.bindPollSvc(),
+		dto.NewMessageDtoStruct(),
+	)
+}
+
+func (p *Provider) BindReminderHandler() *handler.ReminderHandler {
+	return handler.NewReminderHandler(
+		p.bindMongoReminderSvc(),
+		p.bindMongoMessageSvc(),
+		p.BindReminderSvc(),
+		dto.NewReminderDtoStruct(),
+	)
+}
@@ -32,3 +32,120 @@ func TestBindMessageHandler(t *testing.T) {
 		t.Fatal("BindMessageHandler returned nil")
 	}
 }
+
+func TestBindAttachmentHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	attachmentHandler := provider.BindAttachmentHandler()
+
+	if attachmentHandler == nil {
+		t.Fatal("BindAttachmentHandler returned nil")
+	}
+}
+
+func TestBindScheduledMessageHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	scheduledMessageHandler := provider.BindScheduledMessageHandler()
+
+	if scheduledMessageHandler == nil {
+		t.Fatal("BindScheduledMessageHandler returned nil")
+	}
+}
+
+func TestBindModerationHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	moderationHandler := provider.BindModerationHandler()
+
+	if moderationHandler == nil {
+		t.Fatal("BindModerationHandler returned nil")
+	}
+}
+
+func TestBindEventHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	eventHandler := provider.BindEventHandler()
+
+	if eventHandler == nil {
+		t.Fatal("BindEventHandler returned nil")
+	}
+}
+
+func TestBindPresenceHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	presenceHandler := provider.BindPresenceHandler()
+
+	if presenceHandler == nil {
+		t.Fatal("BindPresenceHandler returned nil")
+	}
+}
+
+func TestBindTypingHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	typingHandler := provider.BindTypingHandler()
+
+	if typingHandler == nil {
+		t.Fatal("BindTypingHandler returned nil")
+	}
+}
+
+func TestBindReceiptHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	receiptHandler := provider.BindReceiptHandler()
+
+	if receiptHandler == nil {
+		t.Fatal("BindReceiptHandler returned nil")
+	}
+}
+
+func TestBindWebhookHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	webhookHandler := provider.BindWebhookHandler()
+
+	if webhookHandler == nil {
+		t.Fatal("BindWebhookHandler returned nil")
+	}
+}
+
+func TestBindIncomingWebhookHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	incomingWebhookHandler := provider.BindIncomingWebhookHandler()
+
+	if incomingWebhookHandler == nil {
+		t.Fatal("BindIncomingWebhookHandler returned nil")
+	}
+}
+
+func TestBindBotHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	botHandler := provider.BindBotHandler()
+
+	if botHandler == nil {
+		t.Fatal("BindBotHandler returned nil")
+	}
+}
+
+func TestBindSlashCommandHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	slashCommandHandler := provider.BindSlashCommandHandler()
+
+	if slashCommandHandler == nil {
+		t.Fatal("BindSlashCommandHandler returned nil")
+	}
+}
+
+func TestBindPollHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	pollHandler := provider.BindPollHandler()
+
+	if pollHandler == nil {
+		t.Fatal("BindPollHandler returned nil")
+	}
+}
+
+func TestBindReminderHandler(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	reminderHandler := provider.BindReminderHandler()
+
+	if reminderHandler == nil {
+		t.Fatal("BindReminderHandler returned nil")
+	}
+}
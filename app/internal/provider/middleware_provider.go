@@ -22,3 +22,27 @@ func (p *Provider) BindRoomMiddleware() middleware.RoomMVMiddlewareInterface {
 		p.bindRoomSvc(),
 	)
 }
+
+func (p *Provider) BindModeratorMiddleware() middleware.ModeratorMiddlewareInterface {
+	return middleware.NewModeratorMiddleware()
+}
+
+func (p *Provider) BindBotAPIKeyMiddleware() middleware.BotAPIKeyMiddlewareInterface {
+	return middleware.NewBotAPIKeyMiddleware(
+		p.bindBotSvc(),
+	)
+}
+
+func (p *Provider) BindIncomingWebhookMiddleware() middleware.IncomingWebhookMiddlewareInterface {
+	return middleware.NewIncomingWebhookMiddleware(
+		p.bindIncomingWebhookSvc(),
+		p.bindRoomSvc(),
+	)
+}
+
+func (p *Provider) BindRateLimitMiddleware(group string) middleware.RateLimitMiddlewareInterface {
+	return middleware.NewRateLimitMiddleware(
+		p.bindRateLimitSvc(),
+		group,
+	)
+}
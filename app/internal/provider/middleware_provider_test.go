@@ -32,3 +32,39 @@ func TestBindRoomMiddleware(t *testing.T) {
 		t.Fatal("BindRoomMiddleware returned nil")
 	}
 }
+
+func TestBindModeratorMiddleware(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	moderatorMiddleware := provider.BindModeratorMiddleware()
+
+	if moderatorMiddleware == nil {
+		t.Fatal("BindModeratorMiddleware returned nil")
+	}
+}
+
+func TestBindBotAPIKeyMiddleware(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	botAPIKeyMiddleware := provider.BindBotAPIKeyMiddleware()
+
+	if botAPIKeyMiddleware == nil {
+		t.Fatal("BindBotAPIKeyMiddleware returned nil")
+	}
+}
+
+func TestBindIncomingWebhookMiddleware(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	incomingWebhookMiddleware := provider.BindIncomingWebhookMiddleware()
+
+	if incomingWebhookMiddleware == nil {
+		t.Fatal("BindIncomingWebhookMiddleware returned nil")
+	}
+}
+
+func TestBindRateLimitMiddleware(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	rateLimitMiddleware := provider.BindRateLimitMiddleware("message")
+
+	if rateLimitMiddleware == nil {
+		t.Fatal("BindRateLimitMiddleware returned nil")
+	}
+}
@@ -3,9 +3,11 @@ package provider
 import (
 	"os"
 
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabapi"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabcsrf"
 )
 
@@ -18,6 +20,68 @@ func (p *Provider) bindMongoRoomSvc() mongo_svc.RoomSvcInterface {
 func (p *Provider) bindMongoMessageSvc() mongo_svc.MessageSvcInterface {
 	return mongo_svc.NewMessageSvcStruct(
 		p.bindMongoSvc(),
+		p.mongoDriver,
+	)
+}
+
+func (p *Provider) bindMongoReportSvc() mongo_svc.ReportSvcInterface {
+	return mongo_svc.NewReportSvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoThumbnailJobSvc() mongo_svc.ThumbnailJobSvcInterface {
+	return mongo_svc.NewThumbnailJobSvcStruct(
+		p.bindMongoSvc(),
+		p.mongoDriver,
+	)
+}
+
+func (p *Provider) bindMongoScheduledMessageSvc() mongo_svc.ScheduledMessageSvcInterface {
+	return mongo_svc.NewScheduledMessageSvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoReminderSvc() mongo_svc.ReminderSvcInterface {
+	return mongo_svc.NewReminderSvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoWebhookSvc() mongo_svc.WebhookSvcInterface {
+	return mongo_svc.NewWebhookSvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoWebhookDeliverySvc() mongo_svc.WebhookDeliverySvcInterface {
+	return mongo_svc.NewWebhookDeliverySvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoIncomingWebhookSvc() mongo_svc.IncomingWebhookSvcInterface {
+	return mongo_svc.NewIncomingWebhookSvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoBotSvc() mongo_svc.BotSvcInterface {
+	return mongo_svc.NewBotSvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoBotAPIKeySvc() mongo_svc.BotAPIKeySvcInterface {
+	return mongo_svc.NewBotAPIKeySvcStruct(
+		p.bindMongoSvc(),
+	)
+}
+
+func (p *Provider) bindMongoSlashCommandSvc() mongo_svc.SlashCommandSvcInterface {
+	return mongo_svc.NewSlashCommandSvcStruct(
+		p.bindMongoSvc(),
 	)
 }
 
@@ -37,3 +101,181 @@ func (p *Provider) bindRoomSvc() service.RoomSvcInterface {
 		),
 	)
 }
+
+func (p *Provider) bindExportSvc() service.ExportSvcInterface {
+	return service.NewExportSvc(
+		p.bindMongoMessageSvc(),
+		p.bindRoomSvc(),
+	)
+}
+
+func (p *Provider) bindModerationSvc() service.ModerationSvcInterface {
+	return service.NewModerationSvc(
+		p.bindMongoReportSvc(),
+		p.bindMongoMessageSvc(),
+		p.bindMongoRoomSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindRateLimitSvc() service.RateLimitSvcInterface {
+	return service.NewRateLimitSvc(
+		p.bindRedisSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindSpamSvc() service.SpamSvcInterface {
+	return service.NewSpamSvc(
+		p.bindRedisSvc(),
+	)
+}
+
+func (p *Provider) bindEventSvc() service.EventSvcInterface {
+	return service.NewEventSvc(
+		p.bindRedisSvc(),
+	)
+}
+
+func (p *Provider) bindPinSvc() service.PinSvcInterface {
+	return service.NewPinSvc(
+		p.bindMongoMessageSvc(),
+		p.bindEventSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindLinkPreviewSvc() service.LinkPreviewSvcInterface {
+	return service.NewLinkPreviewSvc(
+		p.bindLinkFetcher(),
+		p.bindRedisSvc(),
+		p.bindMongoMessageSvc(),
+		p.bindEventSvc(),
+		dto.NewMessageDtoStruct(),
+	)
+}
+
+func (p *Provider) bindMessageSvc() service.MessageSvcInterface {
+	return service.NewMessageSvc(
+		p.bindMongoMessageSvc(),
+		p.bindMongoReportSvc(),
+		p.bindSpamSvc(),
+		p.bindLinkPreviewSvc(),
+		p.BindWebhookSvc(),
+		p.bindEventSvc(),
+		dto.NewMessageDtoStruct(),
+		os.Getenv("SPAM_ACTION"),
+	)
+}
+
+func (p *Provider) BindMessageExpirySvc() service.MessageExpirySvcInterface {
+	return service.NewMessageExpirySvc(
+		p.bindMongoMessageSvc(),
+		p.bindAttachmentSvc(),
+		p.bindEventSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) BindPresenceSvc() service.PresenceSvcInterface {
+	return service.NewPresenceSvc(
+		p.bindRedisSvc(),
+		p.bindMongoRoomSvc(),
+		p.bindEventSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindTypingSvc() service.TypingSvcInterface {
+	return service.NewTypingSvc(
+		p.bindRedisSvc(),
+		p.bindEventSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindAttachmentSvc() service.AttachmentSvcInterface {
+	return service.NewAttachmentSvc(
+		p.bindStorageSvc(),
+	)
+}
+
+func (p *Provider) BindThumbnailSvc() service.ThumbnailSvcInterface {
+	return service.NewThumbnailSvc(
+		p.bindMongoThumbnailJobSvc(),
+		p.bindMongoMessageSvc(),
+		p.bindStorageSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) BindScheduledMessageSvc() service.ScheduledMessageSvcInterface {
+	return service.NewScheduledMessageSvc(
+		p.bindMongoScheduledMessageSvc(),
+		p.bindMongoRoomSvc(),
+		p.bindMessageSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) BindReminderSvc() service.ReminderSvcInterface {
+	return service.NewReminderSvc(
+		p.bindMongoReminderSvc(),
+		p.bindMongoRoomSvc(),
+		p.bindMongoMessageSvc(),
+		p.bindEventSvc(),
+		dto.NewMessageDtoStruct(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) BindWebhookSvc() service.WebhookSvcInterface {
+	return service.NewWebhookSvc(
+		p.bindMongoWebhookSvc(),
+		p.bindMongoWebhookDeliverySvc(),
+		p.bindWebhookPoster(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindIncomingWebhookSvc() service.IncomingWebhookSvcInterface {
+	return service.NewIncomingWebhookSvc(
+		p.bindMongoIncomingWebhookSvc(),
+		p.bindMessageSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindBotSvc() service.BotSvcInterface {
+	return service.NewBotSvc(
+		p.bindMongoBotSvc(),
+		p.bindMongoBotAPIKeySvc(),
+		p.bindMongoSlashCommandSvc(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindSlashCommandSvc() service.SlashCommandSvcInterface {
+	return service.NewSlashCommandSvc(
+		p.bindMongoSlashCommandSvc(),
+		p.bindMongoBotSvc(),
+		p.bindMongoRoomSvc(),
+		p.bindMessageSvc(),
+		p.BindWebhookSvc(),
+		p.bindEventSvc(),
+		p.bindPollSvc(),
+		p.BindReminderSvc(),
+		p.bindWebhookPoster(),
+		atylabclock.NewClock(),
+	)
+}
+
+func (p *Provider) bindPollSvc() service.PollSvcInterface {
+	return service.NewPollSvc(
+		p.bindMongoMessageSvc(),
+		p.bindMessageSvc(),
+		p.bindEventSvc(),
+		dto.NewMessageDtoStruct(),
+		atylabclock.NewClock(),
+	)
+}
@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+)
+
+func TestBindThumbnailSvc(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	thumbnailSvc := provider.BindThumbnailSvc()
+
+	if thumbnailSvc == nil {
+		t.Fatal("BindThumbnailSvc returned nil")
+	}
+}
+
+func TestBindMessageExpirySvc(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	messageExpirySvc := provider.BindMessageExpirySvc()
+
+	if messageExpirySvc == nil {
+		t.Fatal("BindMessageExpirySvc returned nil")
+	}
+}
+
+func TestBindPresenceSvc(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	presenceSvc := provider.BindPresenceSvc()
+
+	if presenceSvc == nil {
+		t.Fatal("BindPresenceSvc returned nil")
+	}
+}
+
+func TestBindScheduledMessageSvc(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	scheduledMessageSvc := provider.BindScheduledMessageSvc()
+
+	if scheduledMessageSvc == nil {
+		t.Fatal("BindScheduledMessageSvc returned nil")
+	}
+}
+
+func TestBindReminderSvc(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	reminderSvc := provider.BindReminderSvc()
+
+	if reminderSvc == nil {
+		t.Fatal("BindReminderSvc returned nil")
+	}
+}
+
+func TestBindWebhookSvc(t *testing.T) {
+	provider := NewProvider(usecase.NewMongo(), usecase.NewRedis())
+	webhookSvc := provider.BindWebhookSvc()
+
+	if webhookSvc == nil {
+		t.Fatal("BindWebhookSvc returned nil")
+	}
+}
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabredis"
@@ -19,3 +20,23 @@ func (p *Provider) bindRedisSvc() *usecase.RedisUseCaseStruct {
 		p.redis,
 	)
 }
+
+func (p *Provider) bindStorageSvc() *usecase.StorageUseCaseStruct {
+	return usecase.NewStorageUseCaseStruct(
+		p.storage,
+	)
+}
+
+func (p *Provider) bindLinkFetcher() *usecase.LinkFetcherStruct {
+	return usecase.NewLinkFetcherStruct(usecase.LinkFetcherConfig{
+		Timeout:      consts.LinkPreviewFetchTimeout,
+		MaxBodySize:  consts.LinkPreviewMaxBodySize,
+		MaxRedirects: consts.LinkPreviewMaxRedirects,
+	})
+}
+
+func (p *Provider) bindWebhookPoster() *usecase.WebhookPosterStruct {
+	return usecase.NewWebhookPosterStruct(usecase.WebhookPosterConfig{
+		Timeout: consts.WebhookTimeout,
+	})
+}
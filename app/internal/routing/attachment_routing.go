@@ -0,0 +1,34 @@
+package routing
+
+import (
+	"fmt"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+	echomw "github.com/labstack/echo/v4/middleware"
+)
+
+func (r *Routing) AttachmentRoute(
+	handler handler.AttachmentHandlerInterface,
+) {
+	// ダウンロードもルームのメンバー確認を通すため、メッセージと同じグループ構成にする
+	attachmentGroup := r.echo.Group(
+		"/message",
+		r.middleware.Room,
+		r.middleware.RateLimit[consts.RateLimitGroups.Message],
+	)
+
+	// 添付の上限サイズ×件数に、フォームの他の項目分の余裕を持たせる
+	bodyLimit := fmt.Sprintf("%dM", consts.AttachmentMaxSize*consts.AttachmentMaxFiles>>20+1)
+
+	attachmentGroup.POST(
+		"/:room_id/attachments",
+		handler.Upload,
+		echomw.BodyLimit(bodyLimit),
+		r.middleware.RateLimit[consts.RateLimitGroups.MessageSend],
+	)
+	attachmentGroup.GET("/:room_id/:message_id/attachments/:attachment_id", handler.Download)
+	attachmentGroup.GET("/:room_id/:message_id/attachments/:attachment_id/thumbnails/:size", handler.Thumbnail)
+
+	r.Finalize(attachmentGroup)
+}
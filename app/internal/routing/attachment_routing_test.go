@@ -0,0 +1,24 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestAttachmentRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/message/:room_id/attachments", Method: "POST"},
+		{Path: "/message/:room_id/:message_id/attachments/:attachment_id", Method: "GET"},
+		{Path: "/message/:room_id/:message_id/attachments/:attachment_id/thumbnails/:size", Method: "GET"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.AttachmentRoute(&handler_mock.MockAttachmentHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
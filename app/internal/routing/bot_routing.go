@@ -0,0 +1,20 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) BotRoute(
+	handler handler.BotHandlerInterface,
+) {
+	botGroup := r.echo.Group("/bots")
+
+	botGroup.GET("", handler.List)
+	botGroup.POST("", handler.Create)
+	botGroup.DELETE("/:bot_id", handler.Delete)
+	botGroup.GET("/:bot_id/keys", handler.APIKeys)
+	botGroup.POST("/:bot_id/keys", handler.IssueAPIKey)
+	botGroup.DELETE("/:bot_id/keys/:key_id", handler.RevokeAPIKey)
+
+	r.Finalize(botGroup)
+}
@@ -0,0 +1,27 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestBotRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/bots", Method: "GET"},
+		{Path: "/bots", Method: "POST"},
+		{Path: "/bots/:bot_id", Method: "DELETE"},
+		{Path: "/bots/:bot_id/keys", Method: "GET"},
+		{Path: "/bots/:bot_id/keys", Method: "POST"},
+		{Path: "/bots/:bot_id/keys/:key_id", Method: "DELETE"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.BotRoute(&handler_mock.MockBotHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
@@ -0,0 +1,12 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) EventRoute(
+	handler handler.EventHandlerInterface,
+) {
+	r.echo.GET(consts.SSEPath, handler.Stream)
+}
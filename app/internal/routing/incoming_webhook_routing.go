@@ -0,0 +1,21 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) IncomingWebhookRoute(
+	handler handler.IncomingWebhookHandlerInterface,
+) {
+	incomingWebhookGroup := r.echo.Group("/room/:room_id/admin/incoming_webhooks", r.middleware.Room)
+
+	incomingWebhookGroup.GET("", handler.List)
+	incomingWebhookGroup.POST("", handler.Create)
+	incomingWebhookGroup.DELETE("/:webhook_id", handler.Delete)
+
+	r.Finalize(incomingWebhookGroup)
+
+	// 外部サービスから呼ばれるため、JWT と CSRF トークンの代わりに URL のトークンで認証する
+	r.echo.POST(consts.IncomingWebhookPath, handler.Post, r.middleware.IncomingWebhook, r.middleware.RateLimit[consts.RateLimitGroups.MessageSend])
+}
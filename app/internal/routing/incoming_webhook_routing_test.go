@@ -0,0 +1,25 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestIncomingWebhookRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/room/:room_id/admin/incoming_webhooks", Method: "GET"},
+		{Path: "/room/:room_id/admin/incoming_webhooks", Method: "POST"},
+		{Path: "/room/:room_id/admin/incoming_webhooks/:webhook_id", Method: "DELETE"},
+		{Path: "/hooks/:webhook_id/:token", Method: "POST"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.IncomingWebhookRoute(&handler_mock.MockIncomingWebhookHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
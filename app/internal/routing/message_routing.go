@@ -1,16 +1,29 @@
 package routing
 
-import "github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
 
 func (r *Routing) MessageRoute(
 	handler handler.MessageHandlerInterface,
 ) {
-	messageGroup := r.echo.Group("/message", r.middleware.Room)
+	messageGroup := r.echo.Group(
+		"/message",
+		r.middleware.Room,
+		r.middleware.RateLimit[consts.RateLimitGroups.Message],
+	)
 
 	messageGroup.GET("/:room_id/list", handler.List)
-	messageGroup.POST("/:room_id/send", handler.Send)
+	messageGroup.POST("/:room_id/send", handler.Send, r.middleware.RateLimit[consts.RateLimitGroups.MessageSend])
 	messageGroup.POST("/:room_id/read", handler.Read)
 	messageGroup.DELETE("/:room_id/delete", handler.Delete)
+	messageGroup.POST("/:room_id/:message_id/report", handler.Report)
+	messageGroup.GET("/:room_id/pins", handler.Pins)
+	messageGroup.POST("/:room_id/:message_id/pin", handler.Pin)
+	messageGroup.DELETE("/:room_id/:message_id/pin", handler.Unpin)
+	messageGroup.GET("/:room_id/deleted", handler.Deleted)
+	messageGroup.POST("/:room_id/:message_id/restore", handler.Restore)
 
 	r.Finalize(messageGroup)
 }
@@ -15,6 +15,12 @@ func TestMessageRoute(t *testing.T) {
 		{Path: "/message/:room_id/send", Method: "POST"},
 		{Path: "/message/:room_id/read", Method: "POST"},
 		{Path: "/message/:room_id/delete", Method: "DELETE"},
+		{Path: "/message/:room_id/:message_id/report", Method: "POST"},
+		{Path: "/message/:room_id/pins", Method: "GET"},
+		{Path: "/message/:room_id/:message_id/pin", Method: "POST"},
+		{Path: "/message/:room_id/:message_id/pin", Method: "DELETE"},
+		{Path: "/message/:room_id/deleted", Method: "GET"},
+		{Path: "/message/:room_id/:message_id/restore", Method: "POST"},
 	}
 	e := echo.New()
 	mw := &middleware.Middleware{}
@@ -0,0 +1,15 @@
+package routing
+
+import "github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+
+func (r *Routing) ModerationRoute(
+	handler handler.ModerationHandlerInterface,
+) {
+	moderationGroup := r.echo.Group("/moderation", r.middleware.Moderator)
+
+	moderationGroup.GET("/reports", handler.Reports)
+	moderationGroup.GET("/reports/:report_id", handler.ReportDetail)
+	moderationGroup.POST("/reports/:report_id/resolve", handler.Resolve)
+
+	r.Finalize(moderationGroup)
+}
@@ -0,0 +1,24 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestModerationRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/moderation/reports", Method: "GET"},
+		{Path: "/moderation/reports/:report_id", Method: "GET"},
+		{Path: "/moderation/reports/:report_id/resolve", Method: "POST"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.ModerationRoute(&handler_mock.MockModerationHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
@@ -0,0 +1,22 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) PollRoute(
+	handler handler.PollHandlerInterface,
+) {
+	pollGroup := r.echo.Group(
+		"/message",
+		r.middleware.Room,
+		r.middleware.RateLimit[consts.RateLimitGroups.Message],
+	)
+
+	pollGroup.POST("/:room_id/polls", handler.Create, r.middleware.RateLimit[consts.RateLimitGroups.MessageSend])
+	pollGroup.POST("/:room_id/:message_id/poll/vote", handler.Vote)
+	pollGroup.POST("/:room_id/:message_id/poll/close", handler.Close)
+
+	r.Finalize(pollGroup)
+}
@@ -0,0 +1,24 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestPollRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/message/:room_id/polls", Method: "POST"},
+		{Path: "/message/:room_id/:message_id/poll/vote", Method: "POST"},
+		{Path: "/message/:room_id/:message_id/poll/close", Method: "POST"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.PollRoute(&handler_mock.MockPollHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
@@ -0,0 +1,18 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) PresenceRoute(
+	handler handler.PresenceHandlerInterface,
+) {
+	presenceGroup := r.echo.Group("/presence")
+
+	presenceGroup.POST("/heartbeat", handler.Heartbeat)
+	// ステータスの設定は参加しているルームすべてに通知するので、回数を制限する
+	presenceGroup.PUT("/status", handler.SetStatus, r.middleware.RateLimit[consts.RateLimitGroups.Message])
+
+	r.Finalize(presenceGroup)
+}
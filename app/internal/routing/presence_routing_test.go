@@ -0,0 +1,23 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestPresenceRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/presence/heartbeat", Method: "POST"},
+		{Path: "/presence/status", Method: "PUT"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.PresenceRoute(&handler_mock.MockPresenceHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
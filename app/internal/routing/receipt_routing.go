@@ -0,0 +1,20 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) ReceiptRoute(
+	handler handler.ReceiptHandlerInterface,
+) {
+	receiptGroup := r.echo.Group(
+		"/message",
+		r.middleware.Room,
+		r.middleware.RateLimit[consts.RateLimitGroups.Message],
+	)
+
+	receiptGroup.GET("/:room_id/:message_id/receipts", handler.List)
+
+	r.Finalize(receiptGroup)
+}
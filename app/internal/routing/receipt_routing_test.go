@@ -0,0 +1,22 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestReceiptRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/message/:room_id/:message_id/receipts", Method: "GET"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.ReceiptRoute(&handler_mock.MockReceiptHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
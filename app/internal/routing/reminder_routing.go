@@ -0,0 +1,30 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) ReminderRoute(
+	handler handler.ReminderHandlerInterface,
+) {
+	reminderGroup := r.echo.Group("/reminders")
+
+	reminderGroup.GET("", handler.List)
+	reminderGroup.POST("", handler.Create)
+	reminderGroup.POST("/:reminder_id/snooze", handler.Snooze)
+	reminderGroup.DELETE("/:reminder_id", handler.Cancel)
+
+	r.Finalize(reminderGroup)
+
+	// メッセージへのリマインダーはルームのメンバーだけが設定できる
+	messageGroup := r.echo.Group(
+		"/message",
+		r.middleware.Room,
+		r.middleware.RateLimit[consts.RateLimitGroups.Message],
+	)
+
+	messageGroup.POST("/:room_id/:message_id/reminders", handler.CreateForMessage)
+
+	r.Finalize(messageGroup)
+}
@@ -0,0 +1,26 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestReminderRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/reminders", Method: "GET"},
+		{Path: "/reminders", Method: "POST"},
+		{Path: "/reminders/:reminder_id/snooze", Method: "POST"},
+		{Path: "/reminders/:reminder_id", Method: "DELETE"},
+		{Path: "/message/:room_id/:message_id/reminders", Method: "POST"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.ReminderRoute(&handler_mock.MockReminderHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
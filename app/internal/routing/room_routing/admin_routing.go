@@ -10,5 +10,8 @@ func (r GroupRouting) AdminRoute() *echo.Group {
 	roomAdminGroup.DELETE("/delete", r.handler.Delete)
 	roomAdminGroup.POST("/add_member", r.handler.AddMember)
 	roomAdminGroup.DELETE("/remove_member", r.handler.RemoveMember)
+	roomAdminGroup.PUT("/message_ttl", r.handler.SetMessageTTL)
+	roomAdminGroup.PUT("/retention", r.handler.SetRetention)
+	roomAdminGroup.PUT("/read_receipts", r.handler.SetReadReceipts)
 	return roomAdminGroup
 }
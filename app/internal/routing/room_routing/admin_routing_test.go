@@ -15,6 +15,9 @@ func TestAdminRoute(t *testing.T) {
 		{Path: "/room/:room_id/admin/delete", Method: "DELETE"},
 		{Path: "/room/:room_id/admin/add_member", Method: "POST"},
 		{Path: "/room/:room_id/admin/remove_member", Method: "DELETE"},
+		{Path: "/room/:room_id/admin/message_ttl", Method: "PUT"},
+		{Path: "/room/:room_id/admin/retention", Method: "PUT"},
+		{Path: "/room/:room_id/admin/read_receipts", Method: "PUT"},
 	}
 	e := echo.New()
 	mw := &middleware.Middleware{
@@ -9,6 +9,7 @@ func (r GroupRouting) DetailRoute() *echo.Group {
 	roomDetailGroup.POST("/join", r.handler.Join)
 	roomDetailGroup.GET("/members", r.handler.Members)
 	roomDetailGroup.POST("/leave", r.handler.Leave)
+	roomDetailGroup.GET("/export", r.handler.Export)
 
 	return roomDetailGroup
 }
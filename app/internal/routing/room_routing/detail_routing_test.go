@@ -15,6 +15,7 @@ func TestDetailRoute(t *testing.T) {
 		{Path: "/room/:room_id/members", Method: "GET"},
 		{Path: "/room/:room_id/join", Method: "POST"},
 		{Path: "/room/:room_id/leave", Method: "POST"},
+		{Path: "/room/:room_id/export", Method: "GET"},
 	}
 	e := echo.New()
 	mw := &middleware.Middleware{
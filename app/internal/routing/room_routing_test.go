@@ -32,9 +32,13 @@ func TestRoomRouting(t *testing.T) {
 		{Path: "/room/:room_id/members", Method: "GET"},
 		{Path: "/room/:room_id/join", Method: "POST"},
 		{Path: "/room/:room_id/leave", Method: "POST"},
+		{Path: "/room/:room_id/export", Method: "GET"},
 		{Path: "/room/:room_id/admin/delete", Method: "DELETE"},
 		{Path: "/room/:room_id/admin/add_member", Method: "POST"},
 		{Path: "/room/:room_id/admin/remove_member", Method: "DELETE"},
+		{Path: "/room/:room_id/admin/message_ttl", Method: "PUT"},
+		{Path: "/room/:room_id/admin/retention", Method: "PUT"},
+		{Path: "/room/:room_id/admin/read_receipts", Method: "PUT"},
 	}
 
 	e := echo.New()
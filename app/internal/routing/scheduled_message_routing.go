@@ -0,0 +1,23 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) ScheduledMessageRoute(
+	handler handler.ScheduledMessageHandlerInterface,
+) {
+	scheduledGroup := r.echo.Group(
+		"/message",
+		r.middleware.Room,
+		r.middleware.RateLimit[consts.RateLimitGroups.Message],
+	)
+
+	scheduledGroup.GET("/:room_id/scheduled", handler.List)
+	scheduledGroup.POST("/:room_id/scheduled", handler.Create, r.middleware.RateLimit[consts.RateLimitGroups.MessageSend])
+	scheduledGroup.PUT("/:room_id/scheduled/:scheduled_id", handler.Update)
+	scheduledGroup.DELETE("/:room_id/scheduled/:scheduled_id", handler.Cancel)
+
+	r.Finalize(scheduledGroup)
+}
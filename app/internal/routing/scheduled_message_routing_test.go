@@ -0,0 +1,25 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestScheduledMessageRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/message/:room_id/scheduled", Method: "GET"},
+		{Path: "/message/:room_id/scheduled", Method: "POST"},
+		{Path: "/message/:room_id/scheduled/:scheduled_id", Method: "PUT"},
+		{Path: "/message/:room_id/scheduled/:scheduled_id", Method: "DELETE"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.ScheduledMessageRoute(&handler_mock.MockScheduledMessageHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
@@ -0,0 +1,19 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) SlashCommandRoute(
+	handler handler.SlashCommandHandlerInterface,
+) {
+	commandGroup := r.echo.Group("/commands")
+	commandGroup.GET("", handler.Catalog)
+	r.Finalize(commandGroup)
+
+	botCommandGroup := r.echo.Group("/bots/:bot_id/commands")
+	botCommandGroup.GET("", handler.List)
+	botCommandGroup.POST("", handler.Create)
+	botCommandGroup.DELETE("/:command_id", handler.Delete)
+	r.Finalize(botCommandGroup)
+}
@@ -0,0 +1,25 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestSlashCommandRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/commands", Method: "GET"},
+		{Path: "/bots/:bot_id/commands", Method: "GET"},
+		{Path: "/bots/:bot_id/commands", Method: "POST"},
+		{Path: "/bots/:bot_id/commands/:command_id", Method: "DELETE"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.SlashCommandRoute(&handler_mock.MockSlashCommandHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
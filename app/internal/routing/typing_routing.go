@@ -0,0 +1,21 @@
+package routing
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+)
+
+func (r *Routing) TypingRoute(
+	handler handler.TypingHandlerInterface,
+) {
+	typingGroup := r.echo.Group(
+		"/message",
+		r.middleware.Room,
+		r.middleware.RateLimit[consts.RateLimitGroups.Message],
+	)
+
+	typingGroup.GET("/:room_id/typing", handler.List)
+	typingGroup.POST("/:room_id/typing", handler.Update)
+
+	r.Finalize(typingGroup)
+}
@@ -0,0 +1,23 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestTypingRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/message/:room_id/typing", Method: "GET"},
+		{Path: "/message/:room_id/typing", Method: "POST"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.TypingRoute(&handler_mock.MockTypingHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
@@ -0,0 +1,16 @@
+package routing
+
+import "github.com/AtsuyaOotsuka/portfolio-go-chat/internal/handler"
+
+func (r *Routing) WebhookRoute(
+	handler handler.WebhookHandlerInterface,
+) {
+	webhookGroup := r.echo.Group("/room/:room_id/admin/webhooks", r.middleware.Room)
+
+	webhookGroup.GET("", handler.List)
+	webhookGroup.POST("", handler.Create)
+	webhookGroup.DELETE("/:webhook_id", handler.Delete)
+	webhookGroup.GET("/:webhook_id/deliveries", handler.Deliveries)
+
+	r.Finalize(webhookGroup)
+}
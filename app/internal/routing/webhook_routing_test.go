@@ -0,0 +1,25 @@
+package routing
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/middleware"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/handler_mock"
+	"github.com/labstack/echo/v4"
+)
+
+func TestWebhookRoute(t *testing.T) {
+	expected := []funcs.ExpectedRoute{
+		{Path: "/room/:room_id/admin/webhooks", Method: "GET"},
+		{Path: "/room/:room_id/admin/webhooks", Method: "POST"},
+		{Path: "/room/:room_id/admin/webhooks/:webhook_id", Method: "DELETE"},
+		{Path: "/room/:room_id/admin/webhooks/:webhook_id/deliveries", Method: "GET"},
+	}
+	e := echo.New()
+	mw := &middleware.Middleware{}
+	r := NewRouting(e, mw)
+	r.WebhookRoute(&handler_mock.MockWebhookHandler{})
+
+	funcs.EachExepectedRoute(expected, e, t)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrAttachmentTooLarge       = errors.New("attachment is too large")
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type is not allowed")
+)
+
+// ファイル形式の判定に使う先頭のバイト数（http.DetectContentType が参照する長さ）
+const attachmentSniffSize = 512
+
+type AttachmentSvcInterface interface {
+	Upload(ctx context.Context, roomID string, file *multipart.FileHeader) (model.Attachment, error)
+	Open(ctx context.Context, attachment model.Attachment) (io.ReadCloser, error)
+	Remove(ctx context.Context, attachments []model.Attachment)
+}
+
+type AttachmentSvc struct {
+	storage usecase.StorageUseCaseInterface
+}
+
+func NewAttachmentSvc(
+	storage usecase.StorageUseCaseInterface,
+) AttachmentSvcInterface {
+	return &AttachmentSvc{
+		storage: storage,
+	}
+}
+
+func (s *AttachmentSvc) Upload(ctx context.Context, roomID string, file *multipart.FileHeader) (model.Attachment, error) {
+	if file.Size > consts.AttachmentMaxSize {
+		return model.Attachment{}, ErrAttachmentTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return model.Attachment{}, err
+	}
+	defer src.Close()
+
+	head := make([]byte, attachmentSniffSize)
+	n, err := io.ReadFull(src, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return model.Attachment{}, err
+	}
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !consts.AttachmentAllowedTypes[contentType] {
+		return model.Attachment{}, fmt.Errorf("%w: %s", ErrAttachmentTypeNotAllowed, contentType)
+	}
+
+	storage, err := s.storage.StorageInit()
+	if err != nil {
+		fmt.Println("Failed to initialize storage:", err)
+		return model.Attachment{}, err
+	}
+
+	id := primitive.NewObjectID().Hex()
+	attachment := model.Attachment{
+		ID:          id,
+		Name:        sanitizeFileName(file.Filename),
+		ContentType: contentType,
+		Size:        file.Size,
+		StorageKey:  "attachments/" + roomID + "/" + id,
+	}
+
+	var body io.Reader = io.MultiReader(bytes.NewReader(head), src)
+	// 写真の撮影場所が他のメンバーに漏れないよう、位置情報を消してから保存する
+	if contentType == "image/jpeg" {
+		data, err := io.ReadAll(io.LimitReader(body, consts.AttachmentMaxSize))
+		if err != nil {
+			return model.Attachment{}, err
+		}
+		data = stripJpegLocation(data)
+		attachment.Size = int64(len(data))
+		body = bytes.NewReader(data)
+	}
+
+	if err := storage.Backend.Put(ctx, attachment.StorageKey, body, attachment.Size, contentType); err != nil {
+		return model.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+func (s *AttachmentSvc) Open(ctx context.Context, attachment model.Attachment) (io.ReadCloser, error) {
+	storage, err := s.storage.StorageInit()
+	if err != nil {
+		fmt.Println("Failed to initialize storage:", err)
+		return nil, err
+	}
+
+	return storage.Backend.Get(ctx, attachment.StorageKey)
+}
+
+// 保存に失敗したメッセージの添付を片付ける
+// 消し損ねても動作に影響はないため、エラーはログに残すだけにする
+func (s *AttachmentSvc) Remove(ctx context.Context, attachments []model.Attachment) {
+	if len(attachments) == 0 {
+		return
+	}
+
+	storage, err := s.storage.StorageInit()
+	if err != nil {
+		fmt.Println("Failed to initialize storage:", err)
+		return
+	}
+
+	for _, attachment := range attachments {
+		if err := storage.Backend.Delete(ctx, attachment.StorageKey); err != nil {
+			fmt.Println("Failed to delete attachment:", attachment.StorageKey, err)
+		}
+		for _, thumbnail := range attachment.Thumbnails {
+			if err := storage.Backend.Delete(ctx, thumbnail.StorageKey); err != nil {
+				fmt.Println("Failed to delete thumbnail:", thumbnail.StorageKey, err)
+			}
+		}
+	}
+}
+
+// ダウンロード時のファイル名に使うため、パスや制御文字を取り除く
+func sanitizeFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+
+	if name == "." || name == "/" || name == "" {
+		return "file"
+	}
+	if runes := []rune(name); len(runes) > 255 {
+		name = string(runes[:255])
+	}
+	return name
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newFileHeader(t *testing.T, name string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("files", name)
+	assert.NoError(t, err)
+	_, err = part.Write(content)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+
+	req := httptest.NewRequest("POST", "/", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	assert.NoError(t, req.ParseMultipartForm(1<<20))
+	return req.MultipartForm.File["files"][0]
+}
+
+func TestAttachmentUpload(t *testing.T) {
+	expected := map[string]struct {
+		name        string
+		content     []byte
+		stored      []byte
+		size        int64
+		initErr     error
+		putErr      error
+		expectType  string
+		expectName  string
+		expectPut   bool
+		expectErr   error
+		expectAnErr bool
+	}{
+		"png": {
+			name:       "photo.png",
+			content:    pngHeader,
+			expectType: "image/png",
+			expectName: "photo.png",
+			expectPut:  true,
+		},
+		"text with charset": {
+			name:       "memo.txt",
+			content:    []byte("hello world"),
+			expectType: "text/plain",
+			expectName: "memo.txt",
+			expectPut:  true,
+		},
+		"path in file name": {
+			name:       "../../etc/passwd.txt",
+			content:    []byte("hello world"),
+			expectType: "text/plain",
+			expectName: "passwd.txt",
+			expectPut:  true,
+		},
+		"jpeg with location": {
+			name:       "photo.jpg",
+			content:    newTestJpeg(t, 8, 4, 1),
+			stored:     stripJpegLocation(newTestJpeg(t, 8, 4, 1)),
+			expectType: "image/jpeg",
+			expectName: "photo.jpg",
+			expectPut:  true,
+		},
+		"disguised html": {
+			name:      "image.png",
+			content:   []byte("<html><script>alert(1)</script></html>"),
+			expectErr: ErrAttachmentTypeNotAllowed,
+		},
+		"too large": {
+			name:      "large.png",
+			content:   pngHeader,
+			size:      consts.AttachmentMaxSize + 1,
+			expectErr: ErrAttachmentTooLarge,
+		},
+		"storage init error": {
+			name:        "photo.png",
+			content:     pngHeader,
+			initErr:     assert.AnError,
+			expectType:  "image/png",
+			expectAnErr: true,
+		},
+		"put error": {
+			name:        "photo.png",
+			content:     pngHeader,
+			putErr:      assert.AnError,
+			expectType:  "image/png",
+			expectPut:   true,
+			expectAnErr: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			file := newFileHeader(t, tt.name, tt.content)
+			if tt.size > 0 {
+				file.Size = tt.size
+			}
+
+			stored := tt.content
+			if tt.stored != nil {
+				stored = tt.stored
+			}
+
+			backendMock := new(usecase_mock.StorageBackendMock)
+			backendMock.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+				return strings.HasPrefix(key, "attachments/room1/")
+			}), stored, int64(len(stored)), tt.expectType).Return(tt.putErr)
+
+			storageMock := new(usecase_mock.StorageUseCaseMock)
+			storageMock.On("StorageInit").Return(&usecase.Storage{Backend: backendMock}, tt.initErr)
+
+			svc := NewAttachmentSvc(storageMock)
+			attachment, err := svc.Upload(t.Context(), "room1", file)
+
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.expectAnErr:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.NotEmpty(t, attachment.ID)
+				assert.Equal(t, tt.expectName, attachment.Name)
+				assert.Equal(t, tt.expectType, attachment.ContentType)
+				assert.Equal(t, int64(len(stored)), attachment.Size)
+				assert.Equal(t, "attachments/room1/"+attachment.ID, attachment.StorageKey)
+			}
+
+			if tt.expectPut {
+				backendMock.AssertNumberOfCalls(t, "Put", 1)
+			} else {
+				backendMock.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAttachmentOpen(t *testing.T) {
+	attachment := model.Attachment{StorageKey: "attachments/room1/file1"}
+
+	t.Run("success", func(t *testing.T) {
+		backendMock := new(usecase_mock.StorageBackendMock)
+		backendMock.On("Get", mock.Anything, "attachments/room1/file1").Return(io.NopCloser(strings.NewReader("hello")), nil)
+		storageMock := new(usecase_mock.StorageUseCaseMock)
+		storageMock.On("StorageInit").Return(&usecase.Storage{Backend: backendMock}, nil)
+
+		reader, err := NewAttachmentSvc(storageMock).Open(t.Context(), attachment)
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(reader)
+		assert.Equal(t, "hello", string(body))
+	})
+
+	t.Run("init_error", func(t *testing.T) {
+		storageMock := new(usecase_mock.StorageUseCaseMock)
+		storageMock.On("StorageInit").Return(&usecase.Storage{}, assert.AnError)
+
+		_, err := NewAttachmentSvc(storageMock).Open(t.Context(), attachment)
+		assert.Error(t, err)
+	})
+}
+
+func TestAttachmentRemove(t *testing.T) {
+	attachments := []model.Attachment{
+		{StorageKey: "attachments/room1/file1"},
+		{StorageKey: "attachments/room1/file2", Thumbnails: []model.AttachmentThumbnail{
+			{StorageKey: "attachments/room1/file2_small"},
+		}},
+	}
+
+	backendMock := new(usecase_mock.StorageBackendMock)
+	backendMock.On("Delete", mock.Anything, "attachments/room1/file1").Return(assert.AnError)
+	backendMock.On("Delete", mock.Anything, "attachments/room1/file2").Return(nil)
+	backendMock.On("Delete", mock.Anything, "attachments/room1/file2_small").Return(nil)
+	storageMock := new(usecase_mock.StorageUseCaseMock)
+	storageMock.On("StorageInit").Return(&usecase.Storage{Backend: backendMock}, nil)
+
+	svc := NewAttachmentSvc(storageMock)
+	// 1件目の削除に失敗しても残りは（サムネイルも）削除する
+	svc.Remove(t.Context(), attachments)
+	backendMock.AssertNumberOfCalls(t, "Delete", 3)
+
+	// 添付がなければストレージに接続しない
+	emptyStorageMock := new(usecase_mock.StorageUseCaseMock)
+	NewAttachmentSvc(emptyStorageMock).Remove(t.Context(), nil)
+	emptyStorageMock.AssertNotCalled(t, "StorageInit")
+}
+
+func TestSanitizeFileName(t *testing.T) {
+	assert.Equal(t, "report.pdf", sanitizeFileName("report.pdf"))
+	assert.Equal(t, "report.pdf", sanitizeFileName(`C:\Users\me\report.pdf`))
+	assert.Equal(t, "report.pdf", sanitizeFileName("../report.pdf"))
+	assert.Equal(t, "report.pdf", sanitizeFileName("re\"port\n.pdf"))
+	assert.Equal(t, "file", sanitizeFileName(""))
+	assert.Equal(t, "file", sanitizeFileName("/"))
+	assert.Len(t, []rune(sanitizeFileName(strings.Repeat("あ", 300))), 255)
+}
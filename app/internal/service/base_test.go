@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabredis"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Luaスクリプトやキャッシュの動作を確認するため、インメモリのRedisに接続したモックを返す
+func setupMiniRedis(t *testing.T) *usecase_mock.RedisUseCaseMock {
+	redisMock, _ := setupMiniRedisServer(t)
+	return redisMock
+}
+
+// 期限切れなどを確認するため、インメモリのRedis自体も返す
+func setupMiniRedisServer(t *testing.T) (*usecase_mock.RedisUseCaseMock, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	redisMock := new(usecase_mock.RedisUseCaseMock)
+	redisMock.On("RedisInit").Return(&usecase.Redis{
+		RedisConnector: &atylabredis.RedisConnector{
+			Client: atylabredis.NewRedisClientStruct(rdb),
+		},
+		Cmd:         usecase.NewRedisCmdStruct(rdb),
+		IsConnected: true,
+	}, nil)
+	return redisMock, mr
+}
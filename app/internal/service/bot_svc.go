@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrBotLimitReached       = fmt.Errorf("a user can have at most %d bots", consts.BotMaxPerOwner)
+	ErrBotAPIKeyLimitReached = fmt.Errorf("a bot can have at most %d active api keys", consts.BotAPIKeyMaxPerBot)
+	ErrBotAPIKeyInvalid      = errors.New("invalid api key")
+	ErrBotScopeReadOnly      = errors.New("this api key is read-only")
+	ErrBotScopeRoom          = errors.New("this api key is not allowed to access this room")
+)
+
+type BotSvcInterface interface {
+	Create(bot model.Bot, ctx *atylabmongo.MongoCtxSvc) (model.Bot, error)
+	Delete(botID string, ownerID string, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	IssueAPIKey(key model.BotAPIKey, ctx *atylabmongo.MongoCtxSvc) (model.BotAPIKey, string, error)
+	Authenticate(apiKey string, ctx *atylabmongo.MongoCtxSvc) (model.Bot, model.BotAPIKey, error)
+}
+
+type BotSvc struct {
+	botSvc          mongo_svc.BotSvcInterface
+	apiKeySvc       mongo_svc.BotAPIKeySvcInterface
+	slashCommandSvc mongo_svc.SlashCommandSvcInterface
+	clock           atylabclock.ClockInterface
+}
+
+func NewBotSvc(
+	botSvc mongo_svc.BotSvcInterface,
+	apiKeySvc mongo_svc.BotAPIKeySvcInterface,
+	slashCommandSvc mongo_svc.SlashCommandSvcInterface,
+	clock atylabclock.ClockInterface,
+) BotSvcInterface {
+	return &BotSvc{
+		botSvc:          botSvc,
+		apiKeySvc:       apiKeySvc,
+		slashCommandSvc: slashCommandSvc,
+		clock:           clock,
+	}
+}
+
+// 所有するボットの数を確認してボットを作成する
+func (s *BotSvc) Create(bot model.Bot, ctx *atylabmongo.MongoCtxSvc) (model.Bot, error) {
+	bots, err := s.botSvc.GetBots(bot.OwnerID, ctx)
+	if err != nil {
+		return model.Bot{}, err
+	}
+	if len(bots) >= consts.BotMaxPerOwner {
+		return model.Bot{}, ErrBotLimitReached
+	}
+
+	bot.CreatedAt = s.clock.Now()
+	botID, err := s.botSvc.CreateBot(bot, ctx)
+	if err != nil {
+		return model.Bot{}, err
+	}
+	bot.ID, err = primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return model.Bot{}, err
+	}
+	return bot, nil
+}
+
+// ボットを削除し、発行済みの API キーをすべて失効させて、登録したコマンドを削除する
+// ボットが送信したメッセージとルームのメンバーには残る
+func (s *BotSvc) Delete(botID string, ownerID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	deleted, err := s.botSvc.DeleteBot(botID, ownerID, ctx)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	if err := s.apiKeySvc.RevokeAPIKeys(botID, s.clock.Now(), ctx); err != nil {
+		return true, err
+	}
+	if err := s.slashCommandSvc.DeleteSlashCommands(botID, ctx); err != nil {
+		return true, err
+	}
+	return true, nil
+}
+
+// 有効な API キーの数を確認して API キーを発行する
+// API キーはハッシュだけを保存するので、あとから確認することはできない
+func (s *BotSvc) IssueAPIKey(key model.BotAPIKey, ctx *atylabmongo.MongoCtxSvc) (model.BotAPIKey, string, error) {
+	keys, err := s.apiKeySvc.GetAPIKeys(key.BotID, ctx)
+	if err != nil {
+		return model.BotAPIKey{}, "", err
+	}
+	active := 0
+	for _, k := range keys {
+		if k.RevokedAt == nil {
+			active++
+		}
+	}
+	if active >= consts.BotAPIKeyMaxPerBot {
+		return model.BotAPIKey{}, "", ErrBotAPIKeyLimitReached
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return model.BotAPIKey{}, "", err
+	}
+	apiKey := consts.BotAPIKeyPrefix + token
+	key.DisplayPrefix = apiKey[:consts.BotAPIKeyDisplayLength]
+	key.KeyHash = hashSecretToken(apiKey)
+	key.CreatedAt = s.clock.Now()
+
+	keyID, err := s.apiKeySvc.CreateAPIKey(key, ctx)
+	if err != nil {
+		return model.BotAPIKey{}, "", err
+	}
+	key.ID, err = primitive.ObjectIDFromHex(keyID)
+	if err != nil {
+		return model.BotAPIKey{}, "", err
+	}
+	return key, apiKey, nil
+}
+
+// API キーから、失効していない API キーとその持ち主のボットを特定する
+// ハッシュで検索するので、API キーを一定時間で比べる必要はない
+func (s *BotSvc) Authenticate(apiKey string, ctx *atylabmongo.MongoCtxSvc) (model.Bot, model.BotAPIKey, error) {
+	if !strings.HasPrefix(apiKey, consts.BotAPIKeyPrefix) {
+		return model.Bot{}, model.BotAPIKey{}, ErrBotAPIKeyInvalid
+	}
+
+	key, err := s.apiKeySvc.GetActiveAPIKeyByHash(hashSecretToken(apiKey), ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Bot{}, model.BotAPIKey{}, ErrBotAPIKeyInvalid
+	}
+	if err != nil {
+		return model.Bot{}, model.BotAPIKey{}, err
+	}
+
+	bot, err := s.botSvc.GetBot(key.BotID, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.Bot{}, model.BotAPIKey{}, ErrBotAPIKeyInvalid
+	}
+	if err != nil {
+		return model.Bot{}, model.BotAPIKey{}, err
+	}
+	return bot, key, nil
+}
+
+// API キーのスコープでリクエストを実行できるか確認する
+// ルームを限定した API キーは、ルームを指定しないリクエスト（ルームの作成など）にも使えない
+func CheckBotAPIKeyScope(scope model.BotAPIKeyScope, method string, roomID string) error {
+	if scope.ReadOnly && method != http.MethodGet && method != http.MethodHead {
+		return ErrBotScopeReadOnly
+	}
+	if len(scope.RoomIDs) > 0 && !slices.Contains(scope.RoomIDs, roomID) {
+		return ErrBotScopeRoom
+	}
+	return nil
+}
+
+// ボットが送信するメッセージに付ける送信元の情報
+func BotMessageSender(bot model.Bot) *model.MessageBot {
+	return &model.MessageBot{
+		Kind:    consts.MessageBotKinds.Bot,
+		ID:      bot.ID.Hex(),
+		Name:    bot.Name,
+		IconURL: bot.IconURL,
+	}
+}
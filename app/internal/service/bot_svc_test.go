@@ -0,0 +1,237 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestBotCreate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	botID := primitive.NewObjectID()
+
+	tests := []struct {
+		name         string
+		count        int
+		getErr       error
+		createErr    error
+		expectedErr  error
+		expectCreate bool
+	}{
+		{"success", 0, nil, nil, nil, true},
+		{"limit reached", consts.BotMaxPerOwner, nil, nil, ErrBotLimitReached, false},
+		{"get error", 0, assert.AnError, nil, assert.AnError, false},
+		{"create error", 0, nil, assert.AnError, assert.AnError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botSvcMock := new(mongo_svc_mock.BotSvcMock)
+			botSvcMock.On("GetBots", "owner-uuid", mock.Anything).Return(make([]model.Bot, tt.count), tt.getErr)
+			botSvcMock.On("CreateBot", model.Bot{OwnerID: "owner-uuid", Name: "Deploy Bot", CreatedAt: now}, mock.Anything).Return(botID.Hex(), tt.createErr)
+
+			svc := NewBotSvc(botSvcMock, nil, nil, atylabclock.NewClockMock(now))
+			bot, err := svc.Create(model.Bot{OwnerID: "owner-uuid", Name: "Deploy Bot"}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, model.Bot{ID: botID, OwnerID: "owner-uuid", Name: "Deploy Bot", CreatedAt: now}, bot)
+			}
+			if tt.expectCreate {
+				botSvcMock.AssertNumberOfCalls(t, "CreateBot", 1)
+			} else {
+				botSvcMock.AssertNotCalled(t, "CreateBot", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestBotDelete(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		deleted              bool
+		deleteErr            error
+		revokeErr            error
+		deleteCommandsErr    error
+		expected             bool
+		expectedErr          error
+		revokeCalled         int
+		deleteCommandsCalled int
+	}{
+		{"success", true, nil, nil, nil, true, nil, 1, 1},
+		{"not found", false, nil, nil, nil, false, nil, 0, 0},
+		{"delete error", false, assert.AnError, nil, nil, false, assert.AnError, 0, 0},
+		{"revoke error", true, nil, assert.AnError, nil, true, assert.AnError, 1, 0},
+		{"delete commands error", true, nil, nil, assert.AnError, true, assert.AnError, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			botSvcMock := new(mongo_svc_mock.BotSvcMock)
+			botSvcMock.On("DeleteBot", "bot-id", "owner-uuid", mock.Anything).Return(tt.deleted, tt.deleteErr)
+			apiKeySvcMock := new(mongo_svc_mock.BotAPIKeySvcMock)
+			apiKeySvcMock.On("RevokeAPIKeys", "bot-id", now, mock.Anything).Return(tt.revokeErr)
+			slashCommandSvcMock := new(mongo_svc_mock.SlashCommandSvcMock)
+			slashCommandSvcMock.On("DeleteSlashCommands", "bot-id", mock.Anything).Return(tt.deleteCommandsErr)
+
+			svc := NewBotSvc(botSvcMock, apiKeySvcMock, slashCommandSvcMock, atylabclock.NewClockMock(now))
+			deleted, err := svc.Delete("bot-id", "owner-uuid", nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, deleted)
+			apiKeySvcMock.AssertNumberOfCalls(t, "RevokeAPIKeys", tt.revokeCalled)
+			slashCommandSvcMock.AssertNumberOfCalls(t, "DeleteSlashCommands", tt.deleteCommandsCalled)
+		})
+	}
+}
+
+func TestBotIssueAPIKey(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	keyID := primitive.NewObjectID()
+	revokedAt := now.Add(-time.Hour)
+
+	active := func(n int) []model.BotAPIKey {
+		keys := []model.BotAPIKey{{RevokedAt: &revokedAt}}
+		for i := 0; i < n; i++ {
+			keys = append(keys, model.BotAPIKey{})
+		}
+		return keys
+	}
+
+	tests := []struct {
+		name         string
+		keys         []model.BotAPIKey
+		getErr       error
+		createErr    error
+		expectedErr  error
+		expectCreate bool
+	}{
+		{"success", active(0), nil, nil, nil, true},
+		{"revoked keys are not counted", active(consts.BotAPIKeyMaxPerBot - 1), nil, nil, nil, true},
+		{"limit reached", active(consts.BotAPIKeyMaxPerBot), nil, nil, ErrBotAPIKeyLimitReached, false},
+		{"get error", []model.BotAPIKey{}, assert.AnError, nil, assert.AnError, false},
+		{"create error", active(0), nil, assert.AnError, assert.AnError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored model.BotAPIKey
+			apiKeySvcMock := new(mongo_svc_mock.BotAPIKeySvcMock)
+			apiKeySvcMock.On("GetAPIKeys", "bot-id", mock.Anything).Return(tt.keys, tt.getErr)
+			apiKeySvcMock.On("CreateAPIKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(0).(model.BotAPIKey)
+			}).Return(keyID.Hex(), tt.createErr)
+
+			svc := NewBotSvc(nil, apiKeySvcMock, nil, atylabclock.NewClockMock(now))
+			scope := model.BotAPIKeyScope{ReadOnly: true, RoomIDs: []string{"room1"}}
+			key, apiKey, err := svc.IssueAPIKey(model.BotAPIKey{BotID: "bot-id", Name: "ci", Scope: scope}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, keyID, key.ID)
+				assert.True(t, strings.HasPrefix(apiKey, consts.BotAPIKeyPrefix))
+				// API キー自体は保存しない
+				assert.Equal(t, hashSecretToken(apiKey), stored.KeyHash)
+				assert.Equal(t, apiKey[:consts.BotAPIKeyDisplayLength], stored.DisplayPrefix)
+				assert.Equal(t, scope, stored.Scope)
+				assert.Equal(t, now, stored.CreatedAt)
+			}
+			if tt.expectCreate {
+				apiKeySvcMock.AssertNumberOfCalls(t, "CreateAPIKey", 1)
+			} else {
+				apiKeySvcMock.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestBotAuthenticate(t *testing.T) {
+	bot := model.Bot{ID: primitive.NewObjectID(), OwnerID: "owner-uuid", Name: "Deploy Bot"}
+	key := model.BotAPIKey{ID: primitive.NewObjectID(), BotID: bot.ID.Hex()}
+	apiKey := consts.BotAPIKeyPrefix + "secret"
+
+	tests := []struct {
+		name        string
+		apiKey      string
+		keyErr      error
+		botErr      error
+		expectedErr error
+	}{
+		{"success", apiKey, nil, nil, nil},
+		{"without prefix", "secret", nil, nil, ErrBotAPIKeyInvalid},
+		{"unknown or revoked key", apiKey, mongo.ErrNoDocuments, nil, ErrBotAPIKeyInvalid},
+		{"key lookup error", apiKey, assert.AnError, nil, assert.AnError},
+		{"deleted bot", apiKey, nil, mongo.ErrNoDocuments, ErrBotAPIKeyInvalid},
+		{"bot lookup error", apiKey, nil, assert.AnError, assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			apiKeySvcMock := new(mongo_svc_mock.BotAPIKeySvcMock)
+			apiKeySvcMock.On("GetActiveAPIKeyByHash", hashSecretToken(apiKey), mock.Anything).Return(key, tt.keyErr)
+			botSvcMock := new(mongo_svc_mock.BotSvcMock)
+			botSvcMock.On("GetBot", bot.ID.Hex(), mock.Anything).Return(bot, tt.botErr)
+
+			svc := NewBotSvc(botSvcMock, apiKeySvcMock, nil, atylabclock.NewClock())
+			authenticatedBot, authenticatedKey, err := svc.Authenticate(tt.apiKey, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, model.Bot{}, authenticatedBot)
+				assert.Equal(t, model.BotAPIKey{}, authenticatedKey)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, bot, authenticatedBot)
+				assert.Equal(t, key, authenticatedKey)
+			}
+		})
+	}
+}
+
+func TestCheckBotAPIKeyScope(t *testing.T) {
+	tests := map[string]struct {
+		scope    model.BotAPIKeyScope
+		method   string
+		roomID   string
+		expected error
+	}{
+		"no restriction":                    {scope: model.BotAPIKeyScope{}, method: "POST", roomID: "room1"},
+		"read-only allows GET":              {scope: model.BotAPIKeyScope{ReadOnly: true}, method: "GET", roomID: "room1"},
+		"read-only allows HEAD":             {scope: model.BotAPIKeyScope{ReadOnly: true}, method: "HEAD"},
+		"read-only rejects POST":            {scope: model.BotAPIKeyScope{ReadOnly: true}, method: "POST", roomID: "room1", expected: ErrBotScopeReadOnly},
+		"room scope allows listed room":     {scope: model.BotAPIKeyScope{RoomIDs: []string{"room1"}}, method: "POST", roomID: "room1"},
+		"room scope rejects other room":     {scope: model.BotAPIKeyScope{RoomIDs: []string{"room1"}}, method: "GET", roomID: "room2", expected: ErrBotScopeRoom},
+		"room scope rejects room-less call": {scope: model.BotAPIKeyScope{RoomIDs: []string{"room1"}}, method: "GET", roomID: "", expected: ErrBotScopeRoom},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CheckBotAPIKeyScope(tt.scope, tt.method, tt.roomID))
+		})
+	}
+}
+
+func TestBotMessageSender(t *testing.T) {
+	bot := model.Bot{ID: primitive.NewObjectID(), Name: "Deploy Bot", IconURL: "https://example.com/bot.png"}
+	assert.Equal(t, &model.MessageBot{
+		Kind:    "bot",
+		ID:      bot.ID.Hex(),
+		Name:    "Deploy Bot",
+		IconURL: "https://example.com/bot.png",
+	}, BotMessageSender(bot))
+}
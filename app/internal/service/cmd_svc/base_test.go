@@ -1,6 +1,7 @@
 package cmd_svc
 
 import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/stretchr/testify/assert"
@@ -23,3 +24,16 @@ func setupInitMock(wantErr bool, mongoConnectorStruct *atylabmongo.MongoConnecto
 	mongoConnectionStructMock.On("NewMongoConnect", "chatapp", mock.Anything).Return(mongoConnectorStruct, returnErr)
 	return mongoConnectionStructMock
 }
+
+func setupCollectionMock(collectionName string, initErr bool) (*atylabmongo.MongoCollectionStructMock, *usecase.MongoUseCaseStruct) {
+	mongoCollectionMock := new(atylabmongo.MongoCollectionStructMock)
+	mongoDatabaseMock := new(atylabmongo.MongoDatabaseStructMock)
+	mongoDatabaseMock.On("Collection", collectionName).Return(mongoCollectionMock)
+
+	mongoConnectorStruct := &atylabmongo.MongoConnector{
+		Db: mongoDatabaseMock,
+	}
+	mongoConnectionStructMock := setupInitMock(initErr, mongoConnectorStruct)
+
+	return mongoCollectionMock, usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
+}
@@ -1,62 +1,263 @@
 package cmd_svc
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MessageSvcInterface interface {
-	GetMessageList(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error)
+	StreamMessageList(roomID string, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error
+	StreamMessagesBefore(roomID string, before time.Time, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error
+	ArchiveMessages(messages []model.Message, archivedAt time.Time, ctx *atylabmongo.MongoCtxSvc) error
+	DeleteMessages(messages []model.Message, ctx *atylabmongo.MongoCtxSvc) (int64, error)
+	StreamDeletedMessagesBefore(before time.Time, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error
+	PurgeDeletedMessages(messages []model.Message, before time.Time, ctx *atylabmongo.MongoCtxSvc) (int64, []model.Message, error)
+	ImportMessage(message model.Message, ctx *atylabmongo.MongoCtxSvc) (bool, error)
 	ContainsForbiddenWords(message string) bool
 }
 
 type MessageSvcStruct struct {
-	mongo usecase.MongoUseCaseInterface
+	mongo  usecase.MongoUseCaseInterface
+	driver usecase.MongoDriverUseCaseInterface
 }
 
 func NewMessageSvcStruct(
 	mongo usecase.MongoUseCaseInterface,
+	driver usecase.MongoDriverUseCaseInterface,
 ) *MessageSvcStruct {
 	return &MessageSvcStruct{
-		mongo: mongo,
+		mongo:  mongo,
+		driver: driver,
 	}
 }
 
-func (s *MessageSvcStruct) GetMessageList(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+func (s *MessageSvcStruct) StreamMessageList(
+	roomID string,
+	ctx *atylabmongo.MongoCtxSvc,
+	fn func(message model.Message) error,
+) error {
+	return s.stream(bson.M{"roomid": roomID}, ctx, fn)
+}
+
+// 指定日時より前に投稿されたメッセージを1件ずつ処理する
+func (s *MessageSvcStruct) StreamMessagesBefore(
+	roomID string,
+	before time.Time,
+	ctx *atylabmongo.MongoCtxSvc,
+	fn func(message model.Message) error,
+) error {
+	return s.stream(bson.M{
+		"roomid":    roomID,
+		"createdAt": bson.M{"$lt": before},
+	}, ctx, fn)
+}
+
+func (s *MessageSvcStruct) stream(
+	filter bson.M,
+	ctx *atylabmongo.MongoCtxSvc,
+	fn func(message model.Message) error,
+) error {
 	mongo, err := s.mongo.MongoInit()
 	if err != nil {
 		fmt.Println("Failed to initialize MongoDB:", err)
-		return []model.Message{}, err
+		return err
 	}
 
 	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
-	filter := bson.M{"roomid": roomID}
 
 	cursor, err := collection.Find(ctx.Ctx, filter)
 	if err != nil {
 		fmt.Println("Failed to find messages:", err)
-		return []model.Message{}, err
+		return err
 	}
 
 	defer cursor.Close(ctx.Ctx)
 
-	var messages []model.Message
+	// 全件をメモリに載せず、カーソルから1件ずつ処理する
 	for cursor.Next(ctx.Ctx) {
 		var message model.Message
 		if err := cursor.Decode(&message); err != nil {
 			fmt.Println("Failed to decode message:", err)
-			return []model.Message{}, err
+			return err
+		}
+		if err := fn(message); err != nil {
+			return err
 		}
-		messages = append(messages, message)
 	}
 
-	return messages, nil
+	// Nextがfalseを返した理由がタイムアウトやキャンセルの場合はエラーとして扱う
+	return ctx.Ctx.Err()
+}
+
+// メッセージをまとめてアーカイブ用のコレクションに複製する
+// 削除前に中断して再実行した場合に備え、アーカイブ済みのメッセージは重複エラーにせず残りの挿入を続ける
+func (s *MessageSvcStruct) ArchiveMessages(messages []model.Message, archivedAt time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	db, err := s.driver.Database()
+	if err != nil {
+		fmt.Println("Failed to connect to MongoDB:", err)
+		return err
+	}
+
+	documents := make([]interface{}, 0, len(messages))
+	for _, message := range messages {
+		documents = append(documents, model.ArchivedMessage{
+			Message:    message,
+			ArchivedAt: archivedAt,
+		})
+	}
+
+	_, err = db.Collection(model.ArchivedMessageCollectionName).InsertMany(
+		ctx.Ctx,
+		documents,
+		options.InsertMany().SetOrdered(false),
+	)
+	if err != nil && !onlyDuplicateKeyErrors(err) {
+		return err
+	}
+	return nil
+}
+
+func onlyDuplicateKeyErrors(err error) bool {
+	var bulkErr mongodriver.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil || len(bulkErr.WriteErrors) == 0 {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongodriver.IsDuplicateKeyError(writeErr) {
+			return false
+		}
+	}
+	return true
+}
+
+// 他のサービスから取り込んだメッセージを登録する
+// 取り込み済み（同じルーム・送信者・client_msg_id のメッセージがある）の場合は false を返す
+func (s *MessageSvcStruct) ImportMessage(message model.Message, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	_, err = collection.InsertOne(ctx.Ctx, message)
+	if mongodriver.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// メッセージをまとめて削除し、削除した件数を返す
+// 他の処理が先に削除していたメッセージは件数に含まれない
+func (s *MessageSvcStruct) DeleteMessages(messages []model.Message, ctx *atylabmongo.MongoCtxSvc) (int64, error) {
+	if len(messages) == 0 {
+		return 0, nil
+	}
+
+	db, err := s.driver.Database()
+	if err != nil {
+		fmt.Println("Failed to connect to MongoDB:", err)
+		return 0, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+
+	result, err := db.Collection(model.MessageCollectionName).DeleteMany(ctx.Ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// 指定日時より前に削除されたメッセージを、ルームをまたいで1件ずつ処理する
+func (s *MessageSvcStruct) StreamDeletedMessagesBefore(
+	before time.Time,
+	ctx *atylabmongo.MongoCtxSvc,
+	fn func(message model.Message) error,
+) error {
+	return s.stream(bson.M{
+		"deletedAt": bson.M{"$lte": before},
+	}, ctx, fn)
+}
+
+// 削除したメッセージをまとめて物理削除し、削除した件数と削除したメッセージを返す
+// 処理中に復元された、または他の処理が先に削除していたメッセージは件数に含まれない
+// 一部が残った場合は、残ったメッセージを読み直して削除したメッセージだけを返す（添付ファイルを消してよいもの）
+func (s *MessageSvcStruct) PurgeDeletedMessages(messages []model.Message, before time.Time, ctx *atylabmongo.MongoCtxSvc) (int64, []model.Message, error) {
+	if len(messages) == 0 {
+		return 0, []model.Message{}, nil
+	}
+
+	db, err := s.driver.Database()
+	if err != nil {
+		fmt.Println("Failed to connect to MongoDB:", err)
+		return 0, nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+
+	collection := db.Collection(model.MessageCollectionName)
+	result, err := collection.DeleteMany(ctx.Ctx, bson.M{
+		"_id":       bson.M{"$in": ids},
+		"deletedAt": bson.M{"$lte": before},
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	if result.DeletedCount == int64(len(messages)) {
+		return result.DeletedCount, messages, nil
+	}
+
+	cursor, err := collection.Find(
+		ctx.Ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return result.DeletedCount, nil, err
+	}
+	var remaining []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx.Ctx, &remaining); err != nil {
+		return result.DeletedCount, nil, err
+	}
+
+	kept := make(map[primitive.ObjectID]bool, len(remaining))
+	for _, message := range remaining {
+		kept[message.ID] = true
+	}
+	purged := make([]model.Message, 0, len(messages))
+	for _, message := range messages {
+		if !kept[message.ID] {
+			purged = append(purged, message)
+		}
+	}
+	return result.DeletedCount, purged, nil
 }
 
 func (s *MessageSvcStruct) ContainsForbiddenWords(message string) bool {
@@ -2,30 +2,39 @@ package cmd_svc
 
 import (
 	"testing"
+	"time"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 )
 
-func TestGetMessageList(t *testing.T) {
+func TestStreamMessageList(t *testing.T) {
 	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
 		tests := []struct {
 			name       string
 			initErr    bool
 			findOneErr bool
 			decodeErr  bool
+			fnErr      bool
+			canceled   bool
 			returnErr  bool
 		}{
-			{"success", false, false, false, false},
-			{"error", true, false, false, true},
-			{"findone_error", false, true, false, true},
-			{"decode_error", false, false, true, true},
+			{"success", false, false, false, false, false, false},
+			{"error", true, false, false, false, false, true},
+			{"findone_error", false, true, false, false, false, true},
+			{"decode_error", false, false, true, false, false, true},
+			{"callback_error", false, false, false, true, false, true},
+			{"context_canceled", false, false, false, false, true, true},
 		}
 
 		for _, tt := range tests {
@@ -61,14 +70,27 @@ func TestGetMessageList(t *testing.T) {
 
 				mongoConnectionStructMock := setupInitMock(tt.initErr, mongoConnectorStruct)
 				mongoUseCase := usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
-				messageSvc := NewMessageSvcStruct(mongoUseCase)
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
 
-				messages, err := messageSvc.GetMessageList("room1", atylabmongo.NewMongoCtxSvc())
+				ctx := atylabmongo.NewMongoCtxSvc()
+				defer ctx.Cancel()
+
+				count := 0
+				err := messageSvc.StreamMessageList("room1", ctx, func(message model.Message) error {
+					count++
+					if tt.canceled {
+						ctx.Cancel()
+					}
+					if tt.fnErr {
+						return assert.AnError
+					}
+					return nil
+				})
 				if (err != nil) != tt.returnErr {
-					t.Errorf("GetMessageList() [%s] error = %v, initErr %v", tt.name, err, tt.initErr)
+					t.Errorf("StreamMessageList() [%s] error = %v, initErr %v", tt.name, err, tt.initErr)
 				}
-				if len(messages) != 1 && !tt.returnErr {
-					t.Errorf("expected 1 message, got %d", len(messages))
+				if count != 1 && !tt.returnErr {
+					t.Errorf("expected 1 message, got %d", count)
 				}
 
 				if tt.returnErr {
@@ -95,3 +117,335 @@ func TestContainsForbiddenWords(t *testing.T) {
 	assert.True(t, messageSvc.ContainsForbiddenWords("This message contains a forbidden word."))
 	assert.False(t, messageSvc.ContainsForbiddenWords("This message is clean."))
 }
+
+func TestStreamMessagesBefore(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"find_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCursorMock := new(atylabmongo.MongoCursorStructMock)
+				mongoCursorMock.On("Next", mock.Anything).Return(true).Once()
+				mongoCursorMock.On("Next", mock.Anything).Return(false).Once()
+				mongoCursorMock.On("Decode", mock.Anything).Return(nil)
+				mongoCursorMock.On("Close", mock.Anything).Return(nil)
+
+				filter := bson.M{
+					"roomid":    "room1",
+					"createdAt": bson.M{"$lt": before},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(mongoCursorMock, tt.findErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				ctx := atylabmongo.NewMongoCtxSvc()
+				defer ctx.Cancel()
+
+				count := 0
+				err := messageSvc.StreamMessagesBefore("room1", before, ctx, func(message model.Message) error {
+					count++
+					return nil
+				})
+				if (err != nil) != tt.returnErr {
+					t.Errorf("StreamMessagesBefore() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Equal(t, 1, count)
+			})
+		}
+	})
+}
+
+func TestArchiveMessages(t *testing.T) {
+	archivedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	messages := []model.Message{
+		{ID: primitive.NewObjectID(), RoomID: "room1", Message: "old"},
+		{ID: primitive.NewObjectID(), RoomID: "room1", Message: "older"},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	run := func(mt *mtest.T, messages []model.Message) error {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(mt.DB, nil)
+		return NewMessageSvcStruct(nil, driver).ArchiveMessages(messages, archivedAt, atylabmongo.NewMongoCtxSvc())
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		assert.NoError(mt, run(mt, messages))
+
+		// 1回の insert でまとめて複製する
+		started := mt.GetAllStartedEvents()
+		if assert.Len(mt, started, 1) {
+			assert.Equal(mt, "insert", started[0].CommandName)
+			documents, err := started[0].Command.Lookup("documents").Array().Values()
+			assert.NoError(mt, err)
+			assert.Len(mt, documents, 2)
+		}
+	})
+
+	// 中断後の再実行でアーカイブ済みのものがあっても、残りは複製できる
+	mt.Run("already archived", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}))
+		assert.NoError(mt, run(mt, messages))
+	})
+
+	mt.Run("write error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(
+			mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"},
+			mtest.WriteError{Index: 1, Code: 2, Message: "bad value"},
+		))
+		assert.Error(mt, run(mt, messages))
+	})
+
+	mt.Run("command error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}))
+		assert.Error(mt, run(mt, messages))
+	})
+
+	mt.Run("empty", func(mt *mtest.T) {
+		assert.NoError(mt, NewMessageSvcStruct(nil, new(usecase_mock.MongoDriverUseCaseMock)).ArchiveMessages(nil, archivedAt, atylabmongo.NewMongoCtxSvc()))
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(nil, assert.AnError)
+		err := NewMessageSvcStruct(nil, driver).ArchiveMessages(messages, archivedAt, atylabmongo.NewMongoCtxSvc())
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestImportMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		message := model.Message{RoomID: "room1", Sender: "uuid-1", Message: "hello", ClientMsgID: "slack:1512085950.000216"}
+		duplicateErr := mongodriver.WriteException{WriteErrors: []mongodriver.WriteError{{Code: 11000}}}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"imported", false, nil, true, false},
+			{"already imported", false, duplicateErr, false, false},
+			{"init_error", true, nil, false, true},
+			{"insert_error", false, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, message).Return(primitive.NewObjectID().Hex(), tt.insertErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				imported, err := messageSvc.ImportMessage(message, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("ImportMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, imported)
+			})
+		}
+	})
+}
+
+func TestDeleteMessages(t *testing.T) {
+	messages := []model.Message{
+		{ID: primitive.NewObjectID(), RoomID: "room1"},
+		{ID: primitive.NewObjectID(), RoomID: "room1"},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	run := func(mt *mtest.T, messages []model.Message) (int64, error) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(mt.DB, nil)
+		return NewMessageSvcStruct(nil, driver).DeleteMessages(messages, atylabmongo.NewMongoCtxSvc())
+	}
+
+	mt.Run("deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+		deleted, err := run(mt, messages)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(2), deleted)
+
+		// 1回の delete で _id を $in で指定して削除する
+		started := mt.GetAllStartedEvents()
+		if assert.Len(mt, started, 1) {
+			assert.Equal(mt, "delete", started[0].CommandName)
+			ids, err := started[0].Command.Lookup("deletes", "0", "q", "_id", "$in").Array().Values()
+			assert.NoError(mt, err)
+			assert.Len(mt, ids, 2)
+		}
+	})
+
+	// 他の処理が先に削除していたものは数えない
+	mt.Run("partly deleted", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		deleted, err := run(mt, messages)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(1), deleted)
+	})
+
+	mt.Run("delete error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}))
+		_, err := run(mt, messages)
+		assert.Error(mt, err)
+	})
+
+	mt.Run("empty", func(mt *mtest.T) {
+		deleted, err := NewMessageSvcStruct(nil, new(usecase_mock.MongoDriverUseCaseMock)).DeleteMessages(nil, atylabmongo.NewMongoCtxSvc())
+		assert.NoError(mt, err)
+		assert.Zero(mt, deleted)
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(nil, assert.AnError)
+		_, err := NewMessageSvcStruct(nil, driver).DeleteMessages(messages, atylabmongo.NewMongoCtxSvc())
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestStreamDeletedMessagesBefore(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"find_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCursorMock := new(atylabmongo.MongoCursorStructMock)
+				mongoCursorMock.On("Next", mock.Anything).Return(true).Twice()
+				mongoCursorMock.On("Next", mock.Anything).Return(false).Once()
+				mongoCursorMock.On("Decode", mock.Anything).Return(nil)
+				mongoCursorMock.On("Close", mock.Anything).Return(nil)
+
+				filter := bson.M{
+					"deletedAt": bson.M{"$lte": before},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(mongoCursorMock, tt.findErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				ctx := atylabmongo.NewMongoCtxSvc()
+				defer ctx.Cancel()
+
+				count := 0
+				err := messageSvc.StreamDeletedMessagesBefore(before, ctx, func(message model.Message) error {
+					count++
+					return nil
+				})
+				if (err != nil) != tt.returnErr {
+					t.Errorf("StreamDeletedMessagesBefore() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Equal(t, 2, count)
+			})
+		}
+	})
+}
+
+func TestPurgeDeletedMessages(t *testing.T) {
+	before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	messages := []model.Message{
+		{ID: primitive.NewObjectID(), RoomID: "room1"},
+		{ID: primitive.NewObjectID(), RoomID: "room1"},
+	}
+	ns := "chatapp." + model.MessageCollectionName
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	run := func(mt *mtest.T, messages []model.Message) (int64, []model.Message, error) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(mt.DB, nil)
+		return NewMessageSvcStruct(nil, driver).PurgeDeletedMessages(messages, before, atylabmongo.NewMongoCtxSvc())
+	}
+
+	mt.Run("purged", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+		deleted, purged, err := run(mt, messages)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(2), deleted)
+		assert.Equal(mt, messages, purged)
+
+		// 1回の delete で削除し、復元されたメッセージを消さないよう削除日時も条件に含める
+		started := mt.GetAllStartedEvents()
+		if assert.Len(mt, started, 1) {
+			assert.Equal(mt, "delete", started[0].CommandName)
+			query := started[0].Command.Lookup("deletes", "0", "q")
+			ids, err := query.Document().Lookup("_id", "$in").Array().Values()
+			assert.NoError(mt, err)
+			assert.Len(mt, ids, 2)
+			assert.Equal(mt, before, query.Document().Lookup("deletedAt", "$lte").Time().UTC())
+			assert.Equal(mt, int32(0), started[0].Command.Lookup("deletes", "0", "limit").Int32())
+		}
+	})
+
+	// 実行中に復元されたメッセージは、削除したメッセージに含めない
+	mt.Run("restored during run", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "_id", Value: messages[1].ID}}),
+		)
+		deleted, purged, err := run(mt, messages)
+		assert.NoError(mt, err)
+		assert.Equal(mt, int64(1), deleted)
+		assert.Equal(mt, []model.Message{messages[0]}, purged)
+	})
+
+	mt.Run("delete error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}))
+		_, _, err := run(mt, messages)
+		assert.Error(mt, err)
+	})
+
+	mt.Run("find error", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}),
+		)
+		deleted, _, err := run(mt, messages)
+		assert.Error(mt, err)
+		assert.Equal(mt, int64(1), deleted)
+	})
+
+	mt.Run("empty", func(mt *mtest.T) {
+		deleted, purged, err := NewMessageSvcStruct(nil, new(usecase_mock.MongoDriverUseCaseMock)).PurgeDeletedMessages(nil, before, atylabmongo.NewMongoCtxSvc())
+		assert.NoError(mt, err)
+		assert.Zero(mt, deleted)
+		assert.Empty(mt, purged)
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(nil, assert.AnError)
+		_, _, err := NewMessageSvcStruct(nil, driver).PurgeDeletedMessages(messages, before, atylabmongo.NewMongoCtxSvc())
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
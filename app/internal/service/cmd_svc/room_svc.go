@@ -1,16 +1,20 @@
 package cmd_svc
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 )
 
 type RoomSvcInterface interface {
 	ListRooms(ctx *atylabmongo.MongoCtxSvc) ([]model.Room, error)
+	ImportRoom(room model.Room, ctx *atylabmongo.MongoCtxSvc) (model.Room, bool, error)
 }
 
 type RoomSvcStruct struct {
@@ -47,3 +51,44 @@ func (s *RoomSvcStruct) ListRooms(ctx *atylabmongo.MongoCtxSvc) ([]model.Room, e
 	}
 	return rooms, nil
 }
+
+// 取り込み元のIDでルームを探し、なければ作成する。作成した場合は true を返す
+// 既にある場合は取り込んだメンバーを追加するだけにし、再実行してもルームを重複して作らない
+func (s *RoomSvcStruct) ImportRoom(room model.Room, ctx *atylabmongo.MongoCtxSvc) (model.Room, bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Room{}, false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	var existing model.Room
+	err = collection.FindOne(ctx.Ctx, bson.M{"external_id": room.ExternalID}, &existing)
+	if err == nil {
+		_, err = collection.UpdateOne(
+			ctx.Ctx,
+			bson.M{"_id": existing.ID},
+			bson.M{"$addToSet": bson.M{
+				"members": bson.M{"$each": room.Members},
+			}},
+		)
+		if err != nil {
+			return model.Room{}, false, err
+		}
+		return existing, false, nil
+	}
+	if !errors.Is(err, mongodriver.ErrNoDocuments) {
+		return model.Room{}, false, err
+	}
+
+	insertedID, err := collection.InsertOne(ctx.Ctx, room)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+	room.ID, err = primitive.ObjectIDFromHex(insertedID)
+	if err != nil {
+		return model.Room{}, false, err
+	}
+	return room, true, nil
+}
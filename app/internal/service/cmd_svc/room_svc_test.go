@@ -9,6 +9,9 @@ import (
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestListRooms(t *testing.T) {
@@ -113,3 +116,63 @@ func TestListRooms(t *testing.T) {
 		}
 	})
 }
+
+func TestImportRoom(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		existingID := primitive.NewObjectID()
+		insertedID := primitive.NewObjectID()
+		room := model.Room{
+			Name:       "general",
+			OwnerID:    "owner-uuid",
+			Members:    []string{"owner-uuid", "member-uuid"},
+			ExternalID: "slack:C01",
+		}
+		addMembers := bson.M{"$addToSet": bson.M{
+			"members": bson.M{"$each": room.Members},
+		}}
+
+		tests := []struct {
+			name       string
+			initErr    bool
+			findErr    error
+			updateErr  error
+			insertErr  error
+			expectedID primitive.ObjectID
+			created    bool
+			returnErr  bool
+		}{
+			{"created", false, mongodriver.ErrNoDocuments, nil, nil, insertedID, true, false},
+			{"already imported", false, nil, nil, nil, existingID, false, false},
+			{"init_error", true, nil, nil, nil, primitive.NilObjectID, false, true},
+			{"find_error", false, assert.AnError, nil, nil, primitive.NilObjectID, false, true},
+			{"update_error", false, nil, assert.AnError, nil, primitive.NilObjectID, false, true},
+			{"insert_error", false, mongodriver.ErrNoDocuments, nil, assert.AnError, primitive.NilObjectID, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				mongoCollectionMock.On("FindOne", mock.Anything, bson.M{"external_id": "slack:C01"}, mock.Anything).Run(func(args mock.Arguments) {
+					*(args.Get(2).(*model.Room)) = model.Room{ID: existingID, Name: "general", ExternalID: "slack:C01"}
+				}).Return(tt.findErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, bson.M{"_id": existingID}, addMembers).Return(&mongodriver.UpdateResult{MatchedCount: 1}, tt.updateErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, room).Return(insertedID.Hex(), tt.insertErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				result, created, err := roomSvc.ImportRoom(room, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("ImportRoom() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expectedID, result.ID)
+				assert.Equal(t, tt.created, created)
+				if tt.name == "created" {
+					mongoCollectionMock.AssertNotCalled(t, "UpdateOne", mock.Anything, mock.Anything, mock.Anything)
+				}
+				if tt.name == "already imported" {
+					mongoCollectionMock.AssertCalled(t, "UpdateOne", mock.Anything, bson.M{"_id": existingID}, addMembers)
+					mongoCollectionMock.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+)
+
+// ルームのイベントを全ルーム共通の Stream とルームの Stream に追加し、古いものから削除する
+// 全ルーム共通の Stream で採番した ID をルームの Stream でも使うので、どちらから読んでも同じ ID になる
+// KEYS[1]: ルームのイベントキー KEYS[2]: 全ルームのイベントキー
+// ARGV[1]: ルームで保持する件数 ARGV[2]: イベント(JSON) ARGV[3]: 全ルームで保持する件数
+// 戻り値: 追加したイベントのID
+const eventPublishScript = `
+local id = redis.call('XADD', KEYS[2], 'MAXLEN', '~', ARGV[3], '*', 'event', ARGV[2])
+redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], id, 'event', ARGV[2])
+return id
+`
+
+// Stream に残っている最も古いイベントと最も新しいイベントの ID を返す
+// KEYS[1]: イベントキー
+// 戻り値: {最も古いID, 最も新しいID}（空の場合は空の配列）
+const eventRangeScript = `
+local first = redis.call('XRANGE', KEYS[1], '-', '+', 'COUNT', 1)
+if #first == 0 then
+	return {}
+end
+local last = redis.call('XREVRANGE', KEYS[1], '+', '-', 'COUNT', 1)
+return {first[1][1], last[1][1]}
+`
+
+// 指定した ID より後のイベントを古い順に読み出す
+// KEYS[1]: イベントキー / ARGV[1]: 読み出しを始める ID（この ID は含まない） ARGV[2]: 読み出す件数
+// 戻り値: {ID, イベント(JSON), ID, イベント(JSON), ...}
+const eventReadScript = `
+local streams = redis.call('XREAD', 'COUNT', ARGV[2], 'STREAMS', KEYS[1], ARGV[1])
+local events = {}
+if not streams or not streams[1] then
+	return events
+end
+for _, entry in ipairs(streams[1][2]) do
+	table.insert(events, entry[1])
+	table.insert(events, entry[2][2])
+end
+return events
+`
+
+// 全ルームのイベントを記録する Stream のキー
+const EventGlobalStreamKey = "events:all"
+
+// イベントが1件もない Stream を読み始める位置
+const eventStreamStartID = "0-0"
+
+type RoomEvent struct {
+	Type   string `json:"type"`
+	RoomID string `json:"room_id"`
+	Data   any    `json:"data"`
+	// 指定したユーザーには届けない（本人の操作を本人に返さない場合に使う）
+	ExceptUuid string `json:"except_uuid,omitempty"`
+	// 参加・退出したユーザー。本人の接続では、ルームの一覧を読み直す前でもルームへの参加・退出を反映する
+	Member string `json:"member,omitempty"`
+}
+
+// Stream から読み出したイベント
+type StreamEvent struct {
+	ID         string
+	Type       string
+	RoomID     string
+	ExceptUuid string
+	Member     string
+	// Stream に記録した RoomEvent の JSON
+	Payload string
+}
+
+type EventSvcInterface interface {
+	Publish(event RoomEvent) (string, error)
+	Resume(lastEventID string, ctx context.Context) (string, bool, error)
+	Read(cursor string, ctx context.Context) ([]StreamEvent, error)
+}
+
+type EventSvc struct {
+	redis usecase.RedisUseCaseInterface
+}
+
+func NewEventSvc(
+	redis usecase.RedisUseCaseInterface,
+) EventSvcInterface {
+	return &EventSvc{
+		redis: redis,
+	}
+}
+
+func EventStreamKey(roomID string) string {
+	return "events:room:" + roomID
+}
+
+// ルームのメンバーに向けたイベントを記録する
+// 接続が切れていたクライアントも、受け取ったイベントのID以降を読み直せるよう Stream に残す
+func (s *EventSvc) Publish(event RoomEvent) (string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	raw, err := redis.Cmd.Eval(
+		ctx,
+		eventPublishScript,
+		[]string{EventStreamKey(event.RoomID), EventGlobalStreamKey},
+		consts.EventStreamMaxLen,
+		string(payload),
+		consts.EventGlobalStreamMaxLen,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	id, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected event id: %v", raw)
+	}
+	return id, nil
+}
+
+// 全ルームのイベントを読み始める位置を決める
+// lastEventID が空の場合は、これから追加されるイベントだけを読む
+// lastEventID より後のイベントが Stream から削除されている可能性がある場合は false を返し、
+// 読み飛ばしたイベントがあることをクライアントに知らせられるようにする
+// 削除されたかどうかは Stream に残っている範囲で判断するため、取りこぼしがなくても false になることがある
+func (s *EventSvc) Resume(lastEventID string, ctx context.Context) (string, bool, error) {
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return "", false, err
+	}
+
+	raw, err := redis.Cmd.Eval(ctx, eventRangeScript, []string{EventGlobalStreamKey})
+	if err != nil {
+		return "", false, err
+	}
+	ids, err := redisStrings(raw)
+	if err != nil {
+		return "", false, err
+	}
+
+	oldest, newest := eventStreamStartID, eventStreamStartID
+	if len(ids) == 2 {
+		oldest, newest = ids[0], ids[1]
+	}
+
+	if lastEventID == "" {
+		return newest, true, nil
+	}
+	if _, _, ok := parseEventID(lastEventID); !ok {
+		return newest, false, nil
+	}
+	// Stream が作り直された場合は、クライアントの ID が残っている最新の ID より新しくなる
+	if compareEventID(lastEventID, oldest) < 0 || compareEventID(lastEventID, newest) > 0 {
+		return newest, false, nil
+	}
+	return lastEventID, true, nil
+}
+
+// cursor より後のイベントを古い順に読み出す
+// 読み出したイベントの最後の ID を次の cursor にする
+func (s *EventSvc) Read(cursor string, ctx context.Context) ([]StreamEvent, error) {
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return nil, err
+	}
+
+	raw, err := redis.Cmd.Eval(ctx, eventReadScript, []string{EventGlobalStreamKey}, cursor, consts.SSEReadCount)
+	if err != nil {
+		return nil, err
+	}
+	values, err := redisStrings(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(values)%2 != 0 {
+		return nil, fmt.Errorf("unexpected event stream result: %v", raw)
+	}
+
+	events := make([]StreamEvent, 0, len(values)/2)
+	for i := 0; i < len(values); i += 2 {
+		event := RoomEvent{}
+		if err := json.Unmarshal([]byte(values[i+1]), &event); err != nil {
+			return nil, fmt.Errorf("invalid event %s: %w", values[i], err)
+		}
+		events = append(events, StreamEvent{
+			ID:         values[i],
+			Type:       event.Type,
+			RoomID:     event.RoomID,
+			ExceptUuid: event.ExceptUuid,
+			Member:     event.Member,
+			Payload:    values[i+1],
+		})
+	}
+	return events, nil
+}
+
+func redisStrings(raw any) ([]string, error) {
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result: %v", raw)
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected result: %v", raw)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// Stream の ID（<ミリ秒>-<連番>）を比較する
+func compareEventID(a string, b string) int {
+	aMs, aSeq, _ := parseEventID(a)
+	bMs, bSeq, _ := parseEventID(b)
+	if c := cmp.Compare(aMs, bMs); c != 0 {
+		return c
+	}
+	return cmp.Compare(aSeq, bSeq)
+}
+
+func parseEventID(id string) (uint64, uint64, bool) {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	msValue, err := strconv.ParseUint(ms, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seqValue, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return msValue, seqValue, true
+}
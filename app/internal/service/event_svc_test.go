@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEventPublish(t *testing.T) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	redisMock := new(usecase_mock.RedisUseCaseMock)
+	redisMock.On("RedisInit").Return(&usecase.Redis{
+		Cmd:         usecase.NewRedisCmdStruct(rdb),
+		IsConnected: true,
+	}, nil)
+
+	svc := NewEventSvc(redisMock)
+	event := RoomEvent{
+		Type:   consts.EventTypes.MessageUpdated,
+		RoomID: "room1",
+		Data:   map[string]string{"message_id": "msg1"},
+	}
+
+	first, err := svc.Publish(event)
+	assert.NoError(t, err)
+	second, err := svc.Publish(event)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	entries, err := rdb.XRange(context.Background(), EventStreamKey("room1"), "-", "+").Result()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, first, entries[0].ID)
+
+	published := RoomEvent{}
+	assert.NoError(t, json.Unmarshal([]byte(entries[0].Values["event"].(string)), &published))
+	assert.Equal(t, consts.EventTypes.MessageUpdated, published.Type)
+	assert.Equal(t, "room1", published.RoomID)
+	assert.Equal(t, map[string]any{"message_id": "msg1"}, published.Data)
+
+	// 全ルームの Stream にも同じ ID で追加される
+	all, err := rdb.XRange(context.Background(), EventGlobalStreamKey, "-", "+").Result()
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+	assert.Equal(t, first, all[0].ID)
+	assert.Equal(t, second, all[1].ID)
+
+	// 他のルームの Stream には追加されない
+	exists, err := rdb.Exists(context.Background(), EventStreamKey("room2")).Result()
+	assert.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestEventPublishError(t *testing.T) {
+	t.Run("init error", func(t *testing.T) {
+		redisMock := new(usecase_mock.RedisUseCaseMock)
+		redisMock.On("RedisInit").Return(&usecase.Redis{}, assert.AnError)
+
+		_, err := NewEventSvc(redisMock).Publish(RoomEvent{RoomID: "room1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("eval error", func(t *testing.T) {
+		cmdMock := new(usecase_mock.RedisCmdMock)
+		cmdMock.On("Eval", mock.Anything, eventPublishScript, []string{"events:room:room1", "events:all"}, mock.Anything).Return(nil, assert.AnError)
+		redisMock := new(usecase_mock.RedisUseCaseMock)
+		redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, nil)
+
+		_, err := NewEventSvc(redisMock).Publish(RoomEvent{RoomID: "room1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("unexpected result", func(t *testing.T) {
+		cmdMock := new(usecase_mock.RedisCmdMock)
+		cmdMock.On("Eval", mock.Anything, eventPublishScript, []string{"events:room:room1", "events:all"}, mock.Anything).Return(int64(1), nil)
+		redisMock := new(usecase_mock.RedisUseCaseMock)
+		redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, nil)
+
+		_, err := NewEventSvc(redisMock).Publish(RoomEvent{RoomID: "room1"})
+		assert.Error(t, err)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		redisMock := new(usecase_mock.RedisUseCaseMock)
+		_, err := NewEventSvc(redisMock).Publish(RoomEvent{RoomID: "room1", Data: make(chan int)})
+		assert.Error(t, err)
+		redisMock.AssertNotCalled(t, "RedisInit")
+	})
+}
+
+// 全ルームの Stream にイベントを追加し、追加した ID を返す
+func publishEvents(t *testing.T, svc EventSvcInterface, roomIDs ...string) []string {
+	t.Helper()
+
+	ids := []string{}
+	for _, roomID := range roomIDs {
+		id, err := svc.Publish(RoomEvent{Type: consts.EventTypes.MessageUpdated, RoomID: roomID})
+		if err != nil {
+			t.Fatalf("publish error: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestEventResume(t *testing.T) {
+	t.Run("empty stream", func(t *testing.T) {
+		svc := NewEventSvc(setupMiniRedis(t))
+
+		cursor, replayable, err := svc.Resume("", context.Background())
+		assert.NoError(t, err)
+		assert.True(t, replayable)
+		assert.Equal(t, "0-0", cursor)
+
+		// Stream が作り直された場合は読み直せない
+		cursor, replayable, err = svc.Resume("1700000000000-0", context.Background())
+		assert.NoError(t, err)
+		assert.False(t, replayable)
+		assert.Equal(t, "0-0", cursor)
+	})
+
+	svc := NewEventSvc(setupMiniRedis(t))
+	ids := publishEvents(t, svc, "room1", "room2", "room1")
+
+	tests := map[string]struct {
+		lastEventID string
+		cursor      string
+		replayable  bool
+	}{
+		"new connection": {lastEventID: "", cursor: ids[2], replayable: true},
+		"oldest":         {lastEventID: ids[0], cursor: ids[0], replayable: true},
+		"newest":         {lastEventID: ids[2], cursor: ids[2], replayable: true},
+		"trimmed":        {lastEventID: "0-1", cursor: ids[2], replayable: false},
+		"future":         {lastEventID: "99999999999999-0", cursor: ids[2], replayable: false},
+		"invalid":        {lastEventID: "abc", cursor: ids[2], replayable: false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cursor, replayable, err := svc.Resume(tt.lastEventID, context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.replayable, replayable)
+			assert.Equal(t, tt.cursor, cursor)
+		})
+	}
+}
+
+func TestEventRead(t *testing.T) {
+	svc := NewEventSvc(setupMiniRedis(t))
+	ids := publishEvents(t, svc, "room1", "room2", "room1")
+
+	events, err := svc.Read(ids[0], context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, ids[1], events[0].ID)
+	assert.Equal(t, "room2", events[0].RoomID)
+	assert.Equal(t, consts.EventTypes.MessageUpdated, events[0].Type)
+	assert.JSONEq(t, `{"type":"message.updated","room_id":"room2","data":null}`, events[0].Payload)
+	assert.Equal(t, ids[2], events[1].ID)
+
+	events, err = svc.Read("0-0", context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	events, err = svc.Read(ids[2], context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// 届けないユーザーも読み出せる
+	id, err := svc.Publish(RoomEvent{Type: consts.EventTypes.Typing, RoomID: "room1", ExceptUuid: "uuid1"})
+	assert.NoError(t, err)
+	events, err = svc.Read(ids[2], context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, id, events[0].ID)
+	assert.Equal(t, "uuid1", events[0].ExceptUuid)
+}
+
+func TestEventResumeAndReadError(t *testing.T) {
+	t.Run("init error", func(t *testing.T) {
+		redisMock := new(usecase_mock.RedisUseCaseMock)
+		redisMock.On("RedisInit").Return(&usecase.Redis{}, assert.AnError)
+
+		_, _, err := NewEventSvc(redisMock).Resume("", context.Background())
+		assert.Error(t, err)
+		_, err = NewEventSvc(redisMock).Read("0-0", context.Background())
+		assert.Error(t, err)
+	})
+
+	tests := map[string]struct {
+		result any
+		err    error
+	}{
+		"eval error":        {result: nil, err: assert.AnError},
+		"unexpected result": {result: int64(1), err: nil},
+		"unexpected item":   {result: []any{int64(1)}, err: nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmdMock := new(usecase_mock.RedisCmdMock)
+			cmdMock.On("Eval", mock.Anything, mock.Anything, []string{"events:all"}, mock.Anything).Return(tt.result, tt.err)
+			redisMock := new(usecase_mock.RedisUseCaseMock)
+			redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, nil)
+
+			_, _, err := NewEventSvc(redisMock).Resume("", context.Background())
+			assert.Error(t, err)
+			_, err = NewEventSvc(redisMock).Read("0-0", context.Background())
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("broken event", func(t *testing.T) {
+		for _, result := range []any{[]any{"1-0"}, []any{"1-0", "broken"}} {
+			cmdMock := new(usecase_mock.RedisCmdMock)
+			cmdMock.On("Eval", mock.Anything, eventReadScript, []string{"events:all"}, mock.Anything).Return(result, nil)
+			redisMock := new(usecase_mock.RedisUseCaseMock)
+			redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, nil)
+
+			_, err := NewEventSvc(redisMock).Read("0-0", context.Background())
+			assert.Error(t, err)
+		}
+	})
+}
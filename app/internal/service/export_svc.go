@@ -0,0 +1,379 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	// 実行環境にタイムゾーン情報がなくてもタイムゾーンを指定できるよう埋め込む
+	_ "time/tzdata"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabapi"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+var ErrUnsupportedExportFormat = errors.New("format must be one of json, csv, txt, html")
+
+type ExportSvcInterface interface {
+	ContentType(format string) (string, error)
+	Export(w io.Writer, room model.Room, format string, loc *time.Location, ctx *atylabmongo.MongoCtxSvc) (int, error)
+}
+
+type ExportSvc struct {
+	mongoMessageSvc mongo_svc.MessageSvcInterface
+	roomSvc         RoomSvcInterface
+}
+
+func NewExportSvc(
+	mongoMessageSvc mongo_svc.MessageSvcInterface,
+	roomSvc RoomSvcInterface,
+) ExportSvcInterface {
+	return &ExportSvc{
+		mongoMessageSvc: mongoMessageSvc,
+		roomSvc:         roomSvc,
+	}
+}
+
+var exportContentTypes = map[string]string{
+	consts.ExportFormats.JSON: "application/json; charset=UTF-8",
+	consts.ExportFormats.CSV:  "text/csv; charset=UTF-8",
+	consts.ExportFormats.TXT:  "text/plain; charset=UTF-8",
+	consts.ExportFormats.HTML: "text/html; charset=UTF-8",
+}
+
+func (s *ExportSvc) ContentType(format string) (string, error) {
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		return "", ErrUnsupportedExportFormat
+	}
+	return contentType, nil
+}
+
+// ルームの全メッセージを指定した形式で w に書き出し、書き出した件数を返す
+// メッセージはカーソルから1件ずつ書き出し、全件をメモリに載せない
+// 最初のメッセージを受け取るまでは何も書き込まないので、取得に失敗した場合は呼び出し側でエラーを返せる
+func (s *ExportSvc) Export(w io.Writer, room model.Room, format string, loc *time.Location, ctx *atylabmongo.MongoCtxSvc) (int, error) {
+	writer, err := newExportWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	names := s.memberNames(room)
+	flusher, _ := w.(http.Flusher)
+
+	count := 0
+	begun := false
+	err = s.mongoMessageSvc.StreamMessages(room.ID.Hex(), ctx, func(message model.Message) error {
+		if !begun {
+			if err := writer.begin(room, loc); err != nil {
+				return err
+			}
+			begun = true
+		}
+		if err := writer.write(newExportMessage(message, names, loc)); err != nil {
+			return err
+		}
+		count++
+
+		// 件数の多いルームでもクライアントが途中から受け取れるよう、一定件数ごとに送り出す
+		if count%consts.ExportFlushInterval == 0 {
+			if err := writer.flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if !begun {
+		if err := writer.begin(room, loc); err != nil {
+			return count, err
+		}
+	}
+	if err := writer.end(); err != nil {
+		return count, err
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	return count, nil
+}
+
+// 送信者のUUIDから名前を引けるようにする
+// 退出済みのメンバーや名前を取得できなかった場合はUUIDをそのまま使う
+func (s *ExportSvc) memberNames(room model.Room) map[string]string {
+	ctx := atylabapi.NewApiCtxSvc()
+	defer ctx.Cancel()
+
+	names := map[string]string{}
+	members, err := s.roomSvc.GetMemberInfos(room, ctx)
+	if err != nil {
+		// 名前の解決に失敗してもエクスポート自体は続ける
+		fmt.Println("Failed to get member infos for export:", err)
+		return names
+	}
+	for _, member := range members {
+		if member.Name != "" {
+			names[member.Uuid] = member.Name
+		}
+	}
+	return names
+}
+
+type exportMessage struct {
+	ID          string
+	SenderID    string
+	SenderName  string
+	Message     string
+	CreatedAt   time.Time
+	Attachments []string
+	Deleted     bool
+}
+
+func newExportMessage(message model.Message, names map[string]string, loc *time.Location) exportMessage {
+	senderName, ok := names[message.Sender]
+	if !ok {
+		senderName = message.Sender
+	}
+
+	result := exportMessage{
+		ID:          message.ID.Hex(),
+		SenderID:    message.Sender,
+		SenderName:  senderName,
+		Message:     message.Message,
+		CreatedAt:   message.CreatedAt.In(loc),
+		Attachments: []string{},
+	}
+	// 削除済みのメッセージは一覧と同じく本文と添付を伏せる
+	if message.DeletedAt != nil {
+		result.Message = consts.DeletedMessagePlaceholder
+		result.Deleted = true
+		return result
+	}
+	for _, attachment := range message.Attachments {
+		result.Attachments = append(result.Attachments, attachment.Name)
+	}
+	return result
+}
+
+type exportWriter interface {
+	begin(room model.Room, loc *time.Location) error
+	write(message exportMessage) error
+	flush() error
+	end() error
+}
+
+func newExportWriter(w io.Writer, format string) (exportWriter, error) {
+	switch format {
+	case consts.ExportFormats.JSON:
+		return &jsonExportWriter{w: w}, nil
+	case consts.ExportFormats.CSV:
+		return &csvExportWriter{w: csv.NewWriter(w)}, nil
+	case consts.ExportFormats.TXT:
+		return &txtExportWriter{w: w}, nil
+	case consts.ExportFormats.HTML:
+		return &htmlExportWriter{w: w}, nil
+	}
+	return nil, ErrUnsupportedExportFormat
+}
+
+// 配列全体を組み立てずに済むよう、要素を1件ずつ書き出す
+type jsonExportWriter struct {
+	w     io.Writer
+	wrote bool
+}
+
+type jsonExportRoom struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type jsonExportMessage struct {
+	ID          string   `json:"id"`
+	SenderID    string   `json:"sender_id"`
+	SenderName  string   `json:"sender_name"`
+	Message     string   `json:"message"`
+	CreatedAt   string   `json:"created_at"`
+	Attachments []string `json:"attachments"`
+	Deleted     bool     `json:"deleted"`
+}
+
+func (j *jsonExportWriter) begin(room model.Room, loc *time.Location) error {
+	roomJSON, err := json.Marshal(jsonExportRoom{ID: room.ID.Hex(), Name: room.Name})
+	if err != nil {
+		return err
+	}
+	timezoneJSON, err := json.Marshal(loc.String())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, `{"room":%s,"timezone":%s,"messages":[`, roomJSON, timezoneJSON)
+	return err
+}
+
+func (j *jsonExportWriter) write(message exportMessage) error {
+	messageJSON, err := json.Marshal(jsonExportMessage{
+		ID:          message.ID,
+		SenderID:    message.SenderID,
+		SenderName:  message.SenderName,
+		Message:     message.Message,
+		CreatedAt:   message.CreatedAt.Format(time.RFC3339),
+		Attachments: message.Attachments,
+		Deleted:     message.Deleted,
+	})
+	if err != nil {
+		return err
+	}
+	if j.wrote {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.wrote = true
+	_, err = j.w.Write(messageJSON)
+	return err
+}
+
+func (j *jsonExportWriter) flush() error {
+	return nil
+}
+
+func (j *jsonExportWriter) end() error {
+	_, err := io.WriteString(j.w, "]}\n")
+	return err
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvExportWriter) begin(room model.Room, loc *time.Location) error {
+	return c.w.Write([]string{"id", "created_at", "sender_id", "sender_name", "message", "attachments", "deleted"})
+}
+
+func (c *csvExportWriter) write(message exportMessage) error {
+	return c.w.Write([]string{
+		message.ID,
+		message.CreatedAt.Format(time.RFC3339),
+		csvSafe(message.SenderID),
+		csvSafe(message.SenderName),
+		csvSafe(message.Message),
+		csvSafe(strings.Join(message.Attachments, ";")),
+		strconv.FormatBool(message.Deleted),
+	})
+}
+
+func (c *csvExportWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) end() error {
+	return c.flush()
+}
+
+// 表計算ソフトで開いたときに数式として解釈されないよう、先頭が記号の値はエスケープする
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+const exportTimeLayout = "2006-01-02 15:04:05 -07:00"
+
+type txtExportWriter struct {
+	w io.Writer
+}
+
+func (t *txtExportWriter) begin(room model.Room, loc *time.Location) error {
+	_, err := fmt.Fprintf(t.w, "# %s (%s)\n# Timezone: %s\n\n", room.Name, room.ID.Hex(), loc.String())
+	return err
+}
+
+func (t *txtExportWriter) write(message exportMessage) error {
+	// 複数行のメッセージは2行目以降を字下げし、次のメッセージと区別できるようにする
+	body := strings.ReplaceAll(message.Message, "\n", "\n    ")
+	if _, err := fmt.Fprintf(t.w, "[%s] %s: %s\n", message.CreatedAt.Format(exportTimeLayout), message.SenderName, body); err != nil {
+		return err
+	}
+	for _, name := range message.Attachments {
+		if _, err := fmt.Fprintf(t.w, "    [attachment] %s\n", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *txtExportWriter) flush() error {
+	return nil
+}
+
+func (t *txtExportWriter) end() error {
+	return nil
+}
+
+// メッセージや名前はすべてエスケープしてから埋め込む
+type htmlExportWriter struct {
+	w io.Writer
+}
+
+func (h *htmlExportWriter) begin(room model.Room, loc *time.Location) error {
+	name := html.EscapeString(room.Name)
+	_, err := fmt.Fprintf(h.w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n<h1>%s</h1>\n<p>Timezone: %s</p>\n<ol class=\"messages\">\n",
+		name, name, html.EscapeString(loc.String()))
+	return err
+}
+
+func (h *htmlExportWriter) write(message exportMessage) error {
+	class := "message"
+	if message.Deleted {
+		class = "message deleted"
+	}
+	body := strings.ReplaceAll(html.EscapeString(message.Message), "\n", "<br>")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<li class=\"%s\" id=\"%s\"><time datetime=\"%s\">%s</time> <span class=\"sender\">%s</span><div class=\"body\">%s</div>",
+		class,
+		html.EscapeString(message.ID),
+		message.CreatedAt.Format(time.RFC3339),
+		message.CreatedAt.Format(exportTimeLayout),
+		html.EscapeString(message.SenderName),
+		body,
+	)
+	if len(message.Attachments) > 0 {
+		b.WriteString("<ul class=\"attachments\">")
+		for _, name := range message.Attachments {
+			fmt.Fprintf(&b, "<li>%s</li>", html.EscapeString(name))
+		}
+		b.WriteString("</ul>")
+	}
+	b.WriteString("</li>\n")
+
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlExportWriter) flush() error {
+	return nil
+}
+
+func (h *htmlExportWriter) end() error {
+	_, err := io.WriteString(h.w, "</ol>\n</body>\n</html>\n")
+	return err
+}
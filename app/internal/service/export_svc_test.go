@@ -0,0 +1,257 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabapi"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type roomSvcStub struct {
+	RoomSvcInterface
+	members []model.RoomMember
+	err     error
+}
+
+func (s *roomSvcStub) GetMemberInfos(room model.Room, ctx *atylabapi.ApiCtxSvc) ([]model.RoomMember, error) {
+	return s.members, s.err
+}
+
+func setupExportMessages() (model.Room, []model.Message) {
+	room := model.Room{ID: primitive.NewObjectID(), Name: "Dev <Team>"}
+	deletedAt := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	messages := []model.Message{
+		{
+			ID:          primitive.NewObjectID(),
+			RoomID:      room.ID.Hex(),
+			Sender:      "uuid-1",
+			Message:     "hello\n<b>world</b>",
+			CreatedAt:   time.Date(2025, 1, 1, 15, 0, 0, 0, time.UTC),
+			Attachments: []model.Attachment{{Name: "report.pdf"}},
+		},
+		{
+			ID:        primitive.NewObjectID(),
+			RoomID:    room.ID.Hex(),
+			Sender:    "left-uuid",
+			Message:   "=SUM(A1)",
+			CreatedAt: time.Date(2025, 1, 1, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:          primitive.NewObjectID(),
+			RoomID:      room.ID.Hex(),
+			Sender:      "uuid-1",
+			Message:     "secret",
+			CreatedAt:   time.Date(2025, 1, 1, 17, 0, 0, 0, time.UTC),
+			Attachments: []model.Attachment{{Name: "secret.png"}},
+			DeletedAt:   &deletedAt,
+		},
+	}
+	return room, messages
+}
+
+func TestExportContentType(t *testing.T) {
+	svc := NewExportSvc(nil, nil)
+
+	tests := []struct {
+		format   string
+		expected string
+		err      error
+	}{
+		{"json", "application/json; charset=UTF-8", nil},
+		{"csv", "text/csv; charset=UTF-8", nil},
+		{"txt", "text/plain; charset=UTF-8", nil},
+		{"html", "text/html; charset=UTF-8", nil},
+		{"xml", "", ErrUnsupportedExportFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			contentType, err := svc.ContentType(tt.format)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, contentType)
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	room, messages := setupExportMessages()
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	members := []model.RoomMember{{Uuid: "uuid-1", Name: "Alice"}}
+
+	tests := []struct {
+		name   string
+		format string
+		check  func(t *testing.T, body string)
+	}{
+		{
+			name:   "json",
+			format: consts.ExportFormats.JSON,
+			check: func(t *testing.T, body string) {
+				var result struct {
+					Room     jsonExportRoom      `json:"room"`
+					Timezone string              `json:"timezone"`
+					Messages []jsonExportMessage `json:"messages"`
+				}
+				assert.NoError(t, json.Unmarshal([]byte(body), &result))
+				assert.Equal(t, jsonExportRoom{ID: room.ID.Hex(), Name: "Dev <Team>"}, result.Room)
+				assert.Equal(t, "Asia/Tokyo", result.Timezone)
+				assert.Equal(t, []jsonExportMessage{
+					{
+						ID:          messages[0].ID.Hex(),
+						SenderID:    "uuid-1",
+						SenderName:  "Alice",
+						Message:     "hello\n<b>world</b>",
+						CreatedAt:   "2025-01-02T00:00:00+09:00",
+						Attachments: []string{"report.pdf"},
+					},
+					{
+						ID:          messages[1].ID.Hex(),
+						SenderID:    "left-uuid",
+						SenderName:  "left-uuid",
+						Message:     "=SUM(A1)",
+						CreatedAt:   "2025-01-02T01:00:00+09:00",
+						Attachments: []string{},
+					},
+					{
+						ID:          messages[2].ID.Hex(),
+						SenderID:    "uuid-1",
+						SenderName:  "Alice",
+						Message:     consts.DeletedMessagePlaceholder,
+						CreatedAt:   "2025-01-02T02:00:00+09:00",
+						Attachments: []string{},
+						Deleted:     true,
+					},
+				}, result.Messages)
+			},
+		},
+		{
+			name:   "csv",
+			format: consts.ExportFormats.CSV,
+			check: func(t *testing.T, body string) {
+				records, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+				assert.NoError(t, err)
+				assert.Equal(t, [][]string{
+					{"id", "created_at", "sender_id", "sender_name", "message", "attachments", "deleted"},
+					{messages[0].ID.Hex(), "2025-01-02T00:00:00+09:00", "uuid-1", "Alice", "hello\n<b>world</b>", "report.pdf", "false"},
+					{messages[1].ID.Hex(), "2025-01-02T01:00:00+09:00", "left-uuid", "left-uuid", "'=SUM(A1)", "", "false"},
+					{messages[2].ID.Hex(), "2025-01-02T02:00:00+09:00", "uuid-1", "Alice", consts.DeletedMessagePlaceholder, "", "true"},
+				}, records)
+			},
+		},
+		{
+			name:   "txt",
+			format: consts.ExportFormats.TXT,
+			check: func(t *testing.T, body string) {
+				assert.Equal(t, "# Dev <Team> ("+room.ID.Hex()+")\n"+
+					"# Timezone: Asia/Tokyo\n\n"+
+					"[2025-01-02 00:00:00 +09:00] Alice: hello\n    <b>world</b>\n"+
+					"    [attachment] report.pdf\n"+
+					"[2025-01-02 01:00:00 +09:00] left-uuid: =SUM(A1)\n"+
+					"[2025-01-02 02:00:00 +09:00] Alice: message deleted\n", body)
+			},
+		},
+		{
+			name:   "html",
+			format: consts.ExportFormats.HTML,
+			check: func(t *testing.T, body string) {
+				assert.Contains(t, body, "<title>Dev &lt;Team&gt;</title>")
+				assert.Contains(t, body, `<time datetime="2025-01-02T00:00:00+09:00">2025-01-02 00:00:00 +09:00</time> <span class="sender">Alice</span><div class="body">hello<br>&lt;b&gt;world&lt;/b&gt;</div><ul class="attachments"><li>report.pdf</li></ul>`)
+				assert.Contains(t, body, `<li class="message deleted" id="`+messages[2].ID.Hex()+`">`)
+				assert.NotContains(t, body, "secret")
+				assert.True(t, strings.HasSuffix(body, "</ol>\n</body>\n</html>\n"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("StreamMessages", room.ID.Hex(), mock.Anything, mock.Anything).Return(messages, nil)
+
+			svc := NewExportSvc(messageSvcMock, &roomSvcStub{members: members})
+			rec := httptest.NewRecorder()
+			count, err := svc.Export(rec, room, tt.format, tokyo, atylabmongo.NewMongoCtxSvc())
+
+			assert.NoError(t, err)
+			assert.Equal(t, 3, count)
+			assert.True(t, rec.Flushed)
+			tt.check(t, rec.Body.String())
+		})
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	room, messages := setupExportMessages()
+
+	t.Run("unsupported format", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		svc := NewExportSvc(messageSvcMock, &roomSvcStub{})
+
+		var b strings.Builder
+		_, err := svc.Export(&b, room, "xml", time.UTC, nil)
+		assert.ErrorIs(t, err, ErrUnsupportedExportFormat)
+		messageSvcMock.AssertNotCalled(t, "StreamMessages", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("stream error before the first message writes nothing", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamMessages", room.ID.Hex(), mock.Anything, mock.Anything).Return(nil, assert.AnError)
+		svc := NewExportSvc(messageSvcMock, &roomSvcStub{})
+
+		var b strings.Builder
+		count, err := svc.Export(&b, room, consts.ExportFormats.JSON, time.UTC, nil)
+		assert.ErrorIs(t, err, assert.AnError)
+		assert.Equal(t, 0, count)
+		assert.Empty(t, b.String())
+	})
+
+	t.Run("member lookup failure falls back to uuid", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamMessages", room.ID.Hex(), mock.Anything, mock.Anything).Return(messages[:1], nil)
+		svc := NewExportSvc(messageSvcMock, &roomSvcStub{err: assert.AnError})
+
+		var b strings.Builder
+		count, err := svc.Export(&b, room, consts.ExportFormats.TXT, time.UTC, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		assert.Contains(t, b.String(), "[2025-01-01 15:00:00 +00:00] uuid-1: hello")
+	})
+
+	t.Run("empty room still writes a complete document", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		messageSvcMock.On("StreamMessages", room.ID.Hex(), mock.Anything, mock.Anything).Return(nil, nil)
+		svc := NewExportSvc(messageSvcMock, &roomSvcStub{})
+
+		var b strings.Builder
+		count, err := svc.Export(&b, room, consts.ExportFormats.JSON, time.UTC, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, count)
+		assert.JSONEq(t, `{"room":{"id":"`+room.ID.Hex()+`","name":"Dev <Team>"},"timezone":"UTC","messages":[]}`, b.String())
+	})
+}
+
+func TestCsvSafe(t *testing.T) {
+	tests := map[string]string{
+		"":         "",
+		"hello":    "hello",
+		"=1+1":     "'=1+1",
+		"+81":      "'+81",
+		"-1":       "'-1",
+		"@user":    "'@user",
+		"\tindent": "'\tindent",
+	}
+	for value, expected := range tests {
+		assert.Equal(t, expected, csvSafe(value))
+	}
+}
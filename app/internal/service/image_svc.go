@@ -0,0 +1,316 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var errImageTooLarge = errors.New("image has too many pixels")
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	xmpHeader  = []byte("http://ns.adobe.com/xap/1.0/\x00")
+)
+
+const (
+	exifTagOrientation = 0x0112
+	exifTagGPSInfo     = 0x8825
+)
+
+// EXIF の型ごとの1要素あたりのバイト数
+var exifTypeSizes = map[uint16]int{
+	1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8,
+}
+
+type decodedImage struct {
+	image       image.Image
+	format      string
+	orientation int
+}
+
+// 画素数を確認してから展開する
+func decodeImage(data []byte) (decodedImage, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return decodedImage{}, err
+	}
+	if config.Width*config.Height > consts.ThumbnailMaxPixels {
+		return decodedImage{}, fmt.Errorf("%w: %dx%d", errImageTooLarge, config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return decodedImage{}, err
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = jpegOrientation(data)
+	}
+
+	return decodedImage{image: img, format: format, orientation: orientation}, nil
+}
+
+// EXIF の向きを反映した表示上のサイズ
+func (d decodedImage) size() (int, int) {
+	bounds := d.image.Bounds()
+	if d.orientation >= 5 {
+		return bounds.Dy(), bounds.Dx()
+	}
+	return bounds.Dx(), bounds.Dy()
+}
+
+// 長辺が maxEdge に収まるよう縮小し、EXIF の向きを反映した画像を返す（拡大はしない）
+func (d decodedImage) thumbnail(maxEdge int) image.Image {
+	bounds := d.image.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if longest := max(width, height); longest > maxEdge {
+		width = max(1, width*maxEdge/longest)
+		height = max(1, height*maxEdge/longest)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), d.image, bounds, draw.Src, nil)
+
+	// 縮小後に回転させたほうが処理が軽い
+	return orient(dst, d.orientation)
+}
+
+// 透過を保つため PNG/GIF は PNG で、それ以外は JPEG で保存する
+// 再エンコードするので、元画像のメタデータはサムネイルに引き継がれない
+func (d decodedImage) encode(img image.Image) ([]byte, string, error) {
+	var buf bytes.Buffer
+	if d.format == "png" || d.format == "gif" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+// EXIF の Orientation（1〜8）に従って画像を回転・反転する
+func orient(src *image.RGBA, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = width-1-x, y
+			case 3:
+				dx, dy = width-1-x, height-1-y
+			case 4:
+				dx, dy = x, height-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = height-1-y, x
+			case 7:
+				dx, dy = height-1-y, width-1-x
+			case 8:
+				dx, dy = y, width-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// JPEG から位置情報を取り除く
+// EXIF の GPS 情報は値を 0 で埋めて空にし、位置情報を含みうる XMP は丸ごと削除する
+// 画素データには手を加えないので画質は変わらない
+func stripJpegLocation(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:2]...)
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		// 画像データ（SOS）以降にメタデータは現れない
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+
+		segment := data[pos:end]
+		if marker == 0xE1 {
+			payload := segment[4:]
+			if bytes.HasPrefix(payload, xmpHeader) {
+				pos = end
+				continue
+			}
+			if bytes.HasPrefix(payload, exifHeader) {
+				segment = bytes.Clone(segment)
+				clearExifGPS(segment[4+len(exifHeader):])
+			}
+		}
+		out = append(out, segment...)
+		pos = end
+	}
+
+	return append(out, data[pos:]...)
+}
+
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) && data[pos] == 0xFF {
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+		if payload := data[pos+4 : end]; marker == 0xE1 && bytes.HasPrefix(payload, exifHeader) {
+			tiff, ok := newTiff(payload[len(exifHeader):])
+			if !ok {
+				return 1
+			}
+			entry, ok := tiff.findTag(tiff.ifd0, exifTagOrientation)
+			if !ok {
+				return 1
+			}
+			return int(tiff.u16(entry + 8))
+		}
+		pos = end
+	}
+	return 1
+}
+
+func clearExifGPS(data []byte) {
+	tiff, ok := newTiff(data)
+	if !ok {
+		return
+	}
+	entry, ok := tiff.findTag(tiff.ifd0, exifTagGPSInfo)
+	if !ok {
+		return
+	}
+
+	gps := int(tiff.u32(entry + 8))
+	count, ok := tiff.entryCount(gps)
+	if !ok {
+		return
+	}
+	for i := 0; i < count; i++ {
+		e := gps + 2 + i*12
+		// 4バイトに収まらない値は別の場所に格納されているので、そちらも消す
+		size := exifTypeSizes[tiff.u16(e+2)] * int(tiff.u32(e+4))
+		if size > 4 {
+			offset := int(tiff.u32(e + 8))
+			if offset >= 0 && size <= len(data) && offset+size <= len(data) {
+				clear(data[offset : offset+size])
+			}
+		}
+		clear(data[e : e+12])
+	}
+	tiff.order.PutUint16(data[gps:], 0)
+}
+
+// EXIF（TIFF 形式）の読み取り
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+	ifd0  int
+}
+
+func newTiff(data []byte) (tiff, bool) {
+	if len(data) < 8 {
+		return tiff{}, false
+	}
+
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return tiff{}, false
+	}
+
+	t := tiff{data: data, order: order}
+	t.ifd0 = int(t.u32(4))
+	return t, true
+}
+
+func (t tiff) u16(offset int) uint16 {
+	if offset < 0 || offset+2 > len(t.data) {
+		return 0
+	}
+	return t.order.Uint16(t.data[offset:])
+}
+
+func (t tiff) u32(offset int) uint32 {
+	if offset < 0 || offset+4 > len(t.data) {
+		return 0
+	}
+	return t.order.Uint32(t.data[offset:])
+}
+
+// IFD のエントリ数を返す（エントリがデータの範囲に収まらない場合は false）
+func (t tiff) entryCount(ifd int) (int, bool) {
+	if ifd < 8 || ifd+2 > len(t.data) {
+		return 0, false
+	}
+	count := int(t.u16(ifd))
+	if ifd+2+count*12 > len(t.data) {
+		return 0, false
+	}
+	return count, true
+}
+
+// 指定したタグのエントリの位置を返す
+func (t tiff) findTag(ifd int, tag uint16) (int, bool) {
+	count, ok := t.entryCount(ifd)
+	if !ok {
+		return 0, false
+	}
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if t.u16(entry) == tag {
+			return entry, true
+		}
+	}
+	return 0, false
+}
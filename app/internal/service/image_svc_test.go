@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/stretchr/testify/assert"
+)
+
+// 緯度 35°41'22" を表す GPSLatitude の値（RATIONAL 3つ）
+var testGPSLatitude = []byte{
+	35, 0, 0, 0, 1, 0, 0, 0,
+	41, 0, 0, 0, 1, 0, 0, 0,
+	22, 0, 0, 0, 1, 0, 0, 0,
+}
+
+var testXMP = []byte(`<x:xmpmeta><exif:GPSLatitude>35,41.22N</exif:GPSLatitude></x:xmpmeta>`)
+
+func newTestImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+	// 左上の画素だけ赤にして、回転後の位置を確認できるようにする
+	img.Set(0, 0, color.RGBA{R: 0xff, A: 0xff})
+	return img
+}
+
+// IFD0 に Orientation と GPS IFD へのポインタを持つ EXIF（リトルエンディアン）
+func newTestExif(orientation uint16) []byte {
+	tiff := make([]byte, 8+2+2*12+4+2+2*12+4)
+	order := binary.LittleEndian
+	copy(tiff, "II")
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+
+	// IFD0
+	order.PutUint16(tiff[8:], 2)
+	order.PutUint16(tiff[10:], exifTagOrientation)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+	gps := 8 + 2 + 2*12 + 4
+	order.PutUint16(tiff[22:], exifTagGPSInfo)
+	order.PutUint16(tiff[24:], 4)
+	order.PutUint32(tiff[26:], 1)
+	order.PutUint32(tiff[30:], uint32(gps))
+
+	// GPS IFD（GPSLatitudeRef は値が4バイト以内、GPSLatitude は IFD の後ろに格納）
+	latitude := gps + 2 + 2*12 + 4
+	order.PutUint16(tiff[gps:], 2)
+	order.PutUint16(tiff[gps+2:], 1)
+	order.PutUint16(tiff[gps+4:], 2)
+	order.PutUint32(tiff[gps+6:], 2)
+	copy(tiff[gps+10:], "N\x00")
+	order.PutUint16(tiff[gps+14:], 2)
+	order.PutUint16(tiff[gps+16:], 5)
+	order.PutUint32(tiff[gps+18:], 3)
+	order.PutUint32(tiff[gps+22:], uint32(latitude))
+	tiff = append(tiff, testGPSLatitude...)
+
+	return append(append([]byte{}, exifHeader...), tiff...)
+}
+
+func newAppSegment(marker byte, payload []byte) []byte {
+	segment := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// SOI の直後に EXIF と XMP の APP1 を差し込んだ JPEG を作る
+func newTestJpeg(t *testing.T, width, height int, orientation uint16) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, newTestImage(width, height), nil))
+	encoded := buf.Bytes()
+
+	data := append([]byte{}, encoded[:2]...)
+	data = append(data, newAppSegment(0xE1, newTestExif(orientation))...)
+	data = append(data, newAppSegment(0xE1, append(append([]byte{}, xmpHeader...), testXMP...))...)
+	return append(data, encoded[2:]...)
+}
+
+func TestStripJpegLocation(t *testing.T) {
+	data := newTestJpeg(t, 8, 4, 6)
+	assert.True(t, bytes.Contains(data, testGPSLatitude))
+	assert.True(t, bytes.Contains(data, testXMP))
+
+	stripped := stripJpegLocation(data)
+
+	// 位置情報は消え、向きの情報と画像は残る
+	assert.False(t, bytes.Contains(stripped, testGPSLatitude))
+	assert.False(t, bytes.Contains(stripped, testXMP))
+	assert.False(t, bytes.Contains(stripped, []byte("N\x00\x00\x00")))
+	assert.Equal(t, 6, jpegOrientation(stripped))
+	assert.Len(t, stripped, len(data)-len(testXMP)-len(xmpHeader)-4)
+
+	img, err := jpeg.Decode(bytes.NewReader(stripped))
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 8, 4), img.Bounds())
+
+	// 元のデータは書き換えない
+	assert.True(t, bytes.Contains(data, testGPSLatitude))
+
+	// 2回目は何も変わらない
+	assert.Equal(t, stripped, stripJpegLocation(stripped))
+}
+
+func TestStripJpegLocationInvalid(t *testing.T) {
+	assert.Equal(t, []byte("not a jpeg"), stripJpegLocation([]byte("not a jpeg")))
+
+	// セグメント長が壊れていても、読めたところまでで処理を止める
+	broken := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF, 0x00}
+	assert.Equal(t, broken, stripJpegLocation(broken))
+
+	// GPS IFD の位置がデータの範囲外
+	exif := newTestExif(1)
+	binary.LittleEndian.PutUint32(exif[len(exifHeader)+30:], 0xFFFF)
+	segment := newAppSegment(0xE1, exif)
+	data := append(append([]byte{0xFF, 0xD8}, segment...), 0xFF, 0xD9)
+	assert.Equal(t, data, stripJpegLocation(data))
+}
+
+func TestJpegOrientation(t *testing.T) {
+	assert.Equal(t, 6, jpegOrientation(newTestJpeg(t, 8, 4, 6)))
+	assert.Equal(t, 3, jpegOrientation(newTestJpeg(t, 8, 4, 3)))
+
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, newTestImage(8, 4), nil))
+	assert.Equal(t, 1, jpegOrientation(buf.Bytes()))
+	assert.Equal(t, 1, jpegOrientation([]byte("not a jpeg")))
+}
+
+func TestDecodeImage(t *testing.T) {
+	t.Run("jpeg with orientation", func(t *testing.T) {
+		decoded, err := decodeImage(newTestJpeg(t, 800, 400, 6))
+		assert.NoError(t, err)
+		assert.Equal(t, "jpeg", decoded.format)
+		assert.Equal(t, 6, decoded.orientation)
+
+		width, height := decoded.size()
+		assert.Equal(t, 400, width)
+		assert.Equal(t, 800, height)
+
+		thumbnail := decoded.thumbnail(160)
+		assert.Equal(t, image.Rect(0, 0, 80, 160), thumbnail.Bounds())
+
+		encoded, contentType, err := decoded.encode(thumbnail)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/jpeg", contentType)
+		assert.False(t, bytes.Contains(encoded, exifHeader))
+	})
+
+	t.Run("png is not upscaled", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, png.Encode(&buf, newTestImage(100, 50)))
+
+		decoded, err := decodeImage(buf.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, decoded.orientation)
+
+		thumbnail := decoded.thumbnail(640)
+		assert.Equal(t, image.Rect(0, 0, 100, 50), thumbnail.Bounds())
+
+		_, contentType, err := decoded.encode(thumbnail)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", contentType)
+	})
+
+	t.Run("gif is encoded as png", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, gif.Encode(&buf, newTestImage(20, 10), nil))
+
+		decoded, err := decodeImage(buf.Bytes())
+		assert.NoError(t, err)
+		_, contentType, err := decoded.encode(decoded.thumbnail(160))
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", contentType)
+	})
+
+	t.Run("too many pixels", func(t *testing.T) {
+		// ヘッダーだけで判定するので、画素データがなくても弾かれる
+		var buf bytes.Buffer
+		assert.NoError(t, png.Encode(&buf, newTestImage(1, 1)))
+		data := buf.Bytes()
+		binary.BigEndian.PutUint32(data[16:], uint32(consts.ThumbnailMaxPixels))
+		binary.BigEndian.PutUint32(data[20:], 2)
+		binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+		_, err := decodeImage(data)
+		assert.ErrorIs(t, err, errImageTooLarge)
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		_, err := decodeImage([]byte("hello world"))
+		assert.Error(t, err)
+	})
+}
+
+func TestOrient(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	// 左上の画素がどこへ移るか（幅3・高さ2の画像）
+	expected := map[int]struct {
+		bounds image.Rectangle
+		x, y   int
+	}{
+		1: {image.Rect(0, 0, 3, 2), 0, 0},
+		2: {image.Rect(0, 0, 3, 2), 2, 0},
+		3: {image.Rect(0, 0, 3, 2), 2, 1},
+		4: {image.Rect(0, 0, 3, 2), 0, 1},
+		5: {image.Rect(0, 0, 2, 3), 0, 0},
+		6: {image.Rect(0, 0, 2, 3), 1, 0},
+		7: {image.Rect(0, 0, 2, 3), 1, 2},
+		8: {image.Rect(0, 0, 2, 3), 0, 2},
+		9: {image.Rect(0, 0, 3, 2), 0, 0},
+	}
+
+	for orientation, tt := range expected {
+		img := orient(newTestImage(3, 2), orientation)
+		assert.Equal(t, tt.bounds, img.Bounds(), "orientation %d", orientation)
+		assert.Equal(t, red, img.At(tt.x, tt.y), "orientation %d", orientation)
+	}
+}
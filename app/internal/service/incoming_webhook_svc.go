@@ -0,0 +1,291 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrIncomingWebhookLimitReached = fmt.Errorf("a room can have at most %d incoming webhooks", consts.IncomingWebhookMaxPerRoom)
+	ErrIncomingWebhookNotFound     = errors.New("incoming webhook not found")
+	ErrIncomingWebhookInvalidToken = errors.New("invalid incoming webhook token")
+	ErrIncomingWebhookNoText       = errors.New("no text")
+	ErrIncomingWebhookTextTooLong  = fmt.Errorf("text must be at most %d characters", consts.IncomingWebhookMaxTextLength)
+)
+
+type IncomingWebhookSvcInterface interface {
+	Create(webhook model.IncomingWebhook, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, string, error)
+	Authenticate(webhookID string, token string, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, error)
+	Post(webhook model.IncomingWebhook, room model.Room, payload SlackPayload, ctx *atylabmongo.MongoCtxSvc) (string, error)
+}
+
+// Slack の Incoming Webhook と同じ形式の投稿内容
+// 表示できない要素（画像・ボタンなど）は読み飛ばし、文字として読める部分だけを本文にする
+type SlackPayload struct {
+	Text        string            `json:"text"`
+	Username    string            `json:"username"`
+	IconURL     string            `json:"icon_url"`
+	Attachments []SlackAttachment `json:"attachments"`
+	Blocks      []SlackBlock      `json:"blocks"`
+}
+
+type SlackAttachment struct {
+	Fallback string       `json:"fallback"`
+	Pretext  string       `json:"pretext"`
+	Title    string       `json:"title"`
+	Text     string       `json:"text"`
+	Fields   []SlackField `json:"fields"`
+}
+
+type SlackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text"`
+	Fields   []SlackText `json:"fields"`
+	Elements []SlackText `json:"elements"`
+}
+
+type SlackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type IncomingWebhookSvc struct {
+	incomingWebhookSvc mongo_svc.IncomingWebhookSvcInterface
+	sendSvc            MessageSvcInterface
+	clock              atylabclock.ClockInterface
+}
+
+func NewIncomingWebhookSvc(
+	incomingWebhookSvc mongo_svc.IncomingWebhookSvcInterface,
+	sendSvc MessageSvcInterface,
+	clock atylabclock.ClockInterface,
+) IncomingWebhookSvcInterface {
+	return &IncomingWebhookSvc{
+		incomingWebhookSvc: incomingWebhookSvc,
+		sendSvc:            sendSvc,
+		clock:              clock,
+	}
+}
+
+// 作成数を確認して受信 Webhook を作成し、投稿に使うトークンと一緒に返す
+// トークンはハッシュだけを保存するので、あとから確認することはできない
+func (s *IncomingWebhookSvc) Create(webhook model.IncomingWebhook, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, string, error) {
+	webhooks, err := s.incomingWebhookSvc.GetIncomingWebhooks(webhook.RoomID, ctx)
+	if err != nil {
+		return model.IncomingWebhook{}, "", err
+	}
+	if len(webhooks) >= consts.IncomingWebhookMaxPerRoom {
+		return model.IncomingWebhook{}, "", ErrIncomingWebhookLimitReached
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		return model.IncomingWebhook{}, "", err
+	}
+	webhook.TokenHash = hashSecretToken(token)
+	webhook.CreatedAt = s.clock.Now()
+
+	webhookID, err := s.incomingWebhookSvc.CreateIncomingWebhook(webhook, ctx)
+	if err != nil {
+		return model.IncomingWebhook{}, "", err
+	}
+	webhook.ID, err = primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return model.IncomingWebhook{}, "", err
+	}
+	return webhook, token, nil
+}
+
+// URL に含まれる ID とトークンから受信 Webhook を特定する
+func (s *IncomingWebhookSvc) Authenticate(webhookID string, token string, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, error) {
+	if !primitive.IsValidObjectID(webhookID) {
+		return model.IncomingWebhook{}, ErrIncomingWebhookNotFound
+	}
+
+	webhook, err := s.incomingWebhookSvc.GetIncomingWebhook(webhookID, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.IncomingWebhook{}, ErrIncomingWebhookNotFound
+	}
+	if err != nil {
+		return model.IncomingWebhook{}, err
+	}
+
+	// トークンの一致する長さから推測されないよう、ハッシュ同士を一定時間で比べる
+	if subtle.ConstantTimeCompare([]byte(hashSecretToken(token)), []byte(webhook.TokenHash)) != 1 {
+		return model.IncomingWebhook{}, ErrIncomingWebhookInvalidToken
+	}
+	return webhook, nil
+}
+
+// 投稿内容をメッセージにしてルームに送信する
+// 送信者は Webhook ごとに決まり、表示名とアイコンは投稿ごとに上書きできる
+func (s *IncomingWebhookSvc) Post(webhook model.IncomingWebhook, room model.Room, payload SlackPayload, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	text := payload.PlainText()
+	if text == "" {
+		return "", ErrIncomingWebhookNoText
+	}
+	if utf8.RuneCountInString(text) > consts.IncomingWebhookMaxTextLength {
+		return "", ErrIncomingWebhookTextTooLong
+	}
+
+	bot := &model.MessageBot{
+		Kind:    consts.MessageBotKinds.IncomingWebhook,
+		ID:      webhook.ID.Hex(),
+		Name:    webhook.Name,
+		IconURL: webhook.IconURL,
+	}
+	if username := strings.TrimSpace(payload.Username); username != "" {
+		bot.Name = username
+	}
+	if isHTTPURL(payload.IconURL) {
+		bot.IconURL = payload.IconURL
+	}
+
+	now := s.clock.Now()
+	return s.sendSvc.Send(model.Message{
+		RoomID:        room.ID.Hex(),
+		Sender:        consts.IncomingWebhookSenderPrefix + webhook.ID.Hex(),
+		Message:       text,
+		CreatedAt:     now,
+		IsReadUserIds: []string{},
+		ExpiresAt:     MessageExpiresAt(0, room, now),
+		Bot:           bot,
+	}, ctx)
+}
+
+// URL や API キーに使う、推測できないトークンを作る
+func newSecretToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// トークンは保存せず、照合用のハッシュだけを保存する
+func hashSecretToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// アイコンとして表示できる URL かどうか（javascript: などは受け付けない）
+func isHTTPURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "https://") || strings.HasPrefix(rawURL, "http://")
+}
+
+// 投稿内容を1つの本文にまとめる
+// blocks がある場合は Slack と同じく text より優先し、添付（attachments）は後ろに続ける
+func (p SlackPayload) PlainText() string {
+	lines := []string{}
+	if blocks := p.blocksText(); blocks != "" {
+		lines = append(lines, blocks)
+	} else if p.Text != "" {
+		lines = append(lines, p.Text)
+	}
+	for _, attachment := range p.Attachments {
+		if text := attachment.plainText(); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	return strings.TrimSpace(ConvertSlackText(strings.Join(lines, "\n"), nil))
+}
+
+func (p SlackPayload) blocksText() string {
+	lines := []string{}
+	for _, block := range p.Blocks {
+		switch block.Type {
+		case "header", "section":
+			if block.Text != nil && block.Text.Text != "" {
+				lines = append(lines, block.Text.Text)
+			}
+			for _, field := range block.Fields {
+				if field.Text != "" {
+					lines = append(lines, field.Text)
+				}
+			}
+		case "context":
+			for _, element := range block.Elements {
+				if element.Text != "" {
+					lines = append(lines, element.Text)
+				}
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 表示用の項目がない添付は fallback を使う
+func (a SlackAttachment) plainText() string {
+	lines := []string{}
+	for _, line := range []string{a.Pretext, a.Title, a.Text} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	for _, field := range a.Fields {
+		switch {
+		case field.Title != "" && field.Value != "":
+			lines = append(lines, field.Title+": "+field.Value)
+		case field.Value != "":
+			lines = append(lines, field.Value)
+		}
+	}
+	if len(lines) == 0 {
+		return a.Fallback
+	}
+	return strings.Join(lines, "\n")
+}
+
+var slackTokenPattern = regexp.MustCompile(`<([^<>]+)>`)
+
+var slackEntityReplacer = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+
+// Slack の記法（<@U0123> のメンションや <https://...|label> のリンク）を読める形に直す
+// resolve にはメンション先（"@U0123" や "#C0123"）の名前を返す関数を渡す。nil の場合はラベルか ID をそのまま使う
+func ConvertSlackText(text string, resolve func(target string) (string, bool)) string {
+	text = slackTokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		target, label, _ := strings.Cut(token[1:len(token)-1], "|")
+		switch {
+		case strings.HasPrefix(target, "@"), strings.HasPrefix(target, "#"):
+			if resolve != nil {
+				if name, ok := resolve(target); ok {
+					return target[:1] + name
+				}
+			}
+			if label != "" {
+				return target[:1] + label
+			}
+			return target
+		case strings.HasPrefix(target, "!"):
+			// <!here> や <!subteam^S0123|@team> などの一斉メンション
+			if label != "" {
+				return label
+			}
+			command, _, _ := strings.Cut(target[1:], "^")
+			return "@" + command
+		}
+		// リンクはプレビューを取得できるよう URL を残す
+		return target
+	})
+	return slackEntityReplacer.Replace(text)
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIncomingWebhookCreate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhookID := primitive.NewObjectID()
+
+	tests := []struct {
+		name         string
+		count        int
+		getErr       error
+		createErr    error
+		expectedErr  error
+		expectCreate bool
+	}{
+		{"success", 0, nil, nil, nil, true},
+		{"limit reached", consts.IncomingWebhookMaxPerRoom, nil, nil, ErrIncomingWebhookLimitReached, false},
+		{"get error", 0, assert.AnError, nil, assert.AnError, false},
+		{"create error", 0, nil, assert.AnError, assert.AnError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored model.IncomingWebhook
+			incomingWebhookSvcMock := new(mongo_svc_mock.IncomingWebhookSvcMock)
+			incomingWebhookSvcMock.On("GetIncomingWebhooks", "room1", mock.Anything).Return(make([]model.IncomingWebhook, tt.count), tt.getErr)
+			incomingWebhookSvcMock.On("CreateIncomingWebhook", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(0).(model.IncomingWebhook)
+			}).Return(webhookID.Hex(), tt.createErr)
+
+			svc := NewIncomingWebhookSvc(incomingWebhookSvcMock, nil, atylabclock.NewClockMock(now))
+			webhook, token, err := svc.Create(model.IncomingWebhook{RoomID: "room1", Name: "CI", CreatedBy: "uuid"}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, webhookID, webhook.ID)
+				assert.Len(t, token, 64)
+				// トークン自体は保存しない
+				assert.Equal(t, hashSecretToken(token), stored.TokenHash)
+				assert.NotContains(t, stored.TokenHash, token)
+				assert.Equal(t, now, stored.CreatedAt)
+				assert.Equal(t, "CI", stored.Name)
+			}
+			if tt.expectCreate {
+				incomingWebhookSvcMock.AssertNumberOfCalls(t, "CreateIncomingWebhook", 1)
+			} else {
+				incomingWebhookSvcMock.AssertNotCalled(t, "CreateIncomingWebhook", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestIncomingWebhookAuthenticate(t *testing.T) {
+	webhookID := primitive.NewObjectID()
+	webhook := model.IncomingWebhook{ID: webhookID, RoomID: "room1", TokenHash: hashSecretToken("valid-token")}
+
+	tests := []struct {
+		name        string
+		id          string
+		token       string
+		getErr      error
+		expectedErr error
+	}{
+		{"success", webhookID.Hex(), "valid-token", nil, nil},
+		{"invalid token", webhookID.Hex(), "wrong-token", nil, ErrIncomingWebhookInvalidToken},
+		{"empty token", webhookID.Hex(), "", nil, ErrIncomingWebhookInvalidToken},
+		{"invalid id", "invalid", "valid-token", nil, ErrIncomingWebhookNotFound},
+		{"not found", webhookID.Hex(), "valid-token", mongo.ErrNoDocuments, ErrIncomingWebhookNotFound},
+		{"get error", webhookID.Hex(), "valid-token", assert.AnError, assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			incomingWebhookSvcMock := new(mongo_svc_mock.IncomingWebhookSvcMock)
+			incomingWebhookSvcMock.On("GetIncomingWebhook", webhookID.Hex(), mock.Anything).Return(webhook, tt.getErr)
+
+			svc := NewIncomingWebhookSvc(incomingWebhookSvcMock, nil, atylabclock.NewClock())
+			authenticated, err := svc.Authenticate(tt.id, tt.token, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Equal(t, model.IncomingWebhook{}, authenticated)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, webhook, authenticated)
+			}
+		})
+	}
+}
+
+func TestIncomingWebhookPost(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhook := model.IncomingWebhook{ID: primitive.NewObjectID(), RoomID: "room1", Name: "CI", IconURL: "https://example.com/ci.png"}
+	room := model.Room{ID: primitive.NewObjectID(), MessageTTL: 60}
+
+	tests := []struct {
+		name        string
+		payload     string
+		sendErr     error
+		expectedErr error
+		expectText  string
+		expectBot   model.MessageBot
+	}{
+		{
+			name:       "text",
+			payload:    `{"text": "Build <https://ci.example.com/1|#1> passed"}`,
+			expectText: "Build https://ci.example.com/1 passed",
+			expectBot:  model.MessageBot{Kind: consts.MessageBotKinds.IncomingWebhook, ID: webhook.ID.Hex(), Name: "CI", IconURL: "https://example.com/ci.png"},
+		},
+		{
+			name:       "username and icon override",
+			payload:    `{"text": "deployed", "username": "Deploy Bot", "icon_url": "https://example.com/deploy.png"}`,
+			expectText: "deployed",
+			expectBot:  model.MessageBot{Kind: consts.MessageBotKinds.IncomingWebhook, ID: webhook.ID.Hex(), Name: "Deploy Bot", IconURL: "https://example.com/deploy.png"},
+		},
+		{
+			name:       "unsafe icon is ignored",
+			payload:    `{"text": "deployed", "icon_url": "javascript:alert(1)"}`,
+			expectText: "deployed",
+			expectBot:  model.MessageBot{Kind: consts.MessageBotKinds.IncomingWebhook, ID: webhook.ID.Hex(), Name: "CI", IconURL: "https://example.com/ci.png"},
+		},
+		{
+			name:        "no text",
+			payload:     `{"text": "  "}`,
+			expectedErr: ErrIncomingWebhookNoText,
+		},
+		{
+			name:        "too long",
+			payload:     `{"text": "` + strings.Repeat("あ", consts.IncomingWebhookMaxTextLength+1) + `"}`,
+			expectedErr: ErrIncomingWebhookTextTooLong,
+		},
+		{
+			name:        "rejected as spam",
+			payload:     `{"text": "buy now"}`,
+			sendErr:     ErrSpamRejected,
+			expectedErr: ErrSpamRejected,
+			expectText:  "buy now",
+			expectBot:   model.MessageBot{Kind: consts.MessageBotKinds.IncomingWebhook, ID: webhook.ID.Hex(), Name: "CI", IconURL: "https://example.com/ci.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload SlackPayload
+			assert.NoError(t, json.Unmarshal([]byte(tt.payload), &payload))
+			sendSvc := &sendSvcStub{id: "message-id", err: tt.sendErr}
+
+			svc := NewIncomingWebhookSvc(nil, sendSvc, atylabclock.NewClockMock(now))
+			messageID, err := svc.Post(webhook, room, payload, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "message-id", messageID)
+			}
+
+			if tt.expectText == "" {
+				assert.Empty(t, sendSvc.messages)
+				return
+			}
+			bot := tt.expectBot
+			assert.Equal(t, []model.Message{{
+				RoomID:        room.ID.Hex(),
+				Sender:        "webhook:" + webhook.ID.Hex(),
+				Message:       tt.expectText,
+				CreatedAt:     now,
+				IsReadUserIds: []string{},
+				ExpiresAt:     MessageExpiresAt(0, room, now),
+				Bot:           &bot,
+			}}, sendSvc.messages)
+		})
+	}
+}
+
+func TestSlackPayloadPlainText(t *testing.T) {
+	tests := map[string]struct {
+		payload  string
+		expected string
+	}{
+		"text": {
+			payload:  `{"text": "hello &lt;world&gt;"}`,
+			expected: "hello <world>",
+		},
+		"blocks take precedence over text": {
+			payload: `{"text": "fallback", "blocks": [
+				{"type": "header", "text": {"type": "plain_text", "text": "Deploy"}},
+				{"type": "divider"},
+				{"type": "section", "text": {"type": "mrkdwn", "text": "*prod* finished"}, "fields": [{"type": "mrkdwn", "text": "v1.2.3"}]},
+				{"type": "context", "elements": [{"type": "image", "image_url": "https://example.com/a.png"}, {"type": "mrkdwn", "text": "by <@U01|alice>"}]}
+			]}`,
+			expected: "Deploy\n*prod* finished\nv1.2.3\nby @alice",
+		},
+		"attachments follow the text": {
+			payload: `{"text": "Alert", "attachments": [
+				{"fallback": "ignored", "pretext": "CPU", "title": "web-1", "text": "95%", "fields": [{"title": "Region", "value": "tokyo"}, {"value": "critical"}]},
+				{"fallback": "only fallback"}
+			]}`,
+			expected: "Alert\nCPU\nweb-1\n95%\nRegion: tokyo\ncritical\nonly fallback",
+		},
+		"attachments only": {
+			payload:  `{"attachments": [{"text": "<!here> disk full"}]}`,
+			expected: "@here disk full",
+		},
+		"empty": {
+			payload:  `{}`,
+			expected: "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var payload SlackPayload
+			assert.NoError(t, json.Unmarshal([]byte(tt.payload), &payload))
+			assert.Equal(t, tt.expected, payload.PlainText())
+		})
+	}
+}
+
+func TestConvertSlackText(t *testing.T) {
+	resolve := func(target string) (string, bool) {
+		name, ok := map[string]string{"@U01": "Alice", "#C01": "general"}[target]
+		return name, ok
+	}
+
+	tests := map[string]string{
+		"hi <@U01>":                              "hi @Alice",
+		"hi <@U99|carol>":                        "hi @carol",
+		"hi <@U99>":                              "hi @U99",
+		"see <#C01>":                             "see #general",
+		"<!subteam^S01|@devs> ping":              "@devs ping",
+		"<https://example.com|example> and more": "https://example.com and more",
+	}
+	for text, expected := range tests {
+		assert.Equal(t, expected, ConvertSlackText(text, resolve), text)
+	}
+
+	// 名前を解決しない場合はラベルか ID を使う
+	assert.Equal(t, "hi @U01 @carol", ConvertSlackText("hi <@U01> <@U02|carol>", nil))
+}
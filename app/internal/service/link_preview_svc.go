@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// 日本語の文章に続けて書かれたURLも切り出せるよう、全角の括弧・句読点で区切る
+var previewLinkPattern = regexp.MustCompile(`https?://[^\s<>"'「」『』（）【】、。]+`)
+
+type LinkPreviewSvcInterface interface {
+	Unfurl(message model.Message) error
+}
+
+type LinkPreviewSvc struct {
+	fetcher    usecase.LinkFetcherInterface
+	redis      usecase.RedisUseCaseInterface
+	messageSvc mongo_svc.MessageSvcInterface
+	eventSvc   EventSvcInterface
+	messageDto dto.MessageDtoInterface
+}
+
+func NewLinkPreviewSvc(
+	fetcher usecase.LinkFetcherInterface,
+	redis usecase.RedisUseCaseInterface,
+	messageSvc mongo_svc.MessageSvcInterface,
+	eventSvc EventSvcInterface,
+	messageDto dto.MessageDtoInterface,
+) LinkPreviewSvcInterface {
+	return &LinkPreviewSvc{
+		fetcher:    fetcher,
+		redis:      redis,
+		messageSvc: messageSvc,
+		eventSvc:   eventSvc,
+		messageDto: messageDto,
+	}
+}
+
+type linkPreviewsUpdatedEvent struct {
+	MessageID    string                    `json:"message_id"`
+	LinkPreviews []dto.LinkPreviewResponse `json:"link_previews"`
+}
+
+// メッセージ中のURLのプレビューを取得してメッセージに記録し、ルームのメンバーに通知する
+// 送信後にバックグラウンドで実行する想定なので、取得できなかったURLは単に飛ばす
+func (s *LinkPreviewSvc) Unfurl(message model.Message) error {
+	links := extractPreviewLinks(message.Message)
+	if len(links) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.LinkPreviewUnfurlTimeout)
+	defer cancel()
+
+	previews := []model.LinkPreview{}
+	for _, link := range links {
+		if preview, ok := s.preview(ctx, link); ok {
+			previews = append(previews, preview)
+		}
+	}
+	if len(previews) == 0 {
+		return nil
+	}
+
+	mongoCtx := atylabmongo.NewMongoCtxSvc()
+	defer mongoCtx.Cancel()
+
+	if err := s.messageSvc.SetLinkPreviews(message.ID.Hex(), message.RoomID, previews, mongoCtx); err != nil {
+		return err
+	}
+
+	_, err := s.eventSvc.Publish(RoomEvent{
+		Type:   consts.EventTypes.MessageUpdated,
+		RoomID: message.RoomID,
+		Data: linkPreviewsUpdatedEvent{
+			MessageID:    message.ID.Hex(),
+			LinkPreviews: s.messageDto.LinkPreviews(previews),
+		},
+	})
+	return err
+}
+
+// キャッシュがあればそれを使い、なければページを取得する
+// 取得できなかった場合もキャッシュし、同じURLが続けて投稿されても取得し直さない
+func (s *LinkPreviewSvc) preview(ctx context.Context, link string) (model.LinkPreview, bool) {
+	key := linkPreviewCacheKey(link)
+	if preview, ok := s.cached(ctx, key); ok {
+		return preview, preview.Title != ""
+	}
+
+	preview := model.LinkPreview{URL: link}
+	ttl := consts.LinkPreviewMissCacheTTL
+	page, err := s.fetcher.Fetch(ctx, link)
+	if err != nil {
+		fmt.Println("Failed to fetch link preview:", link, err)
+	} else if parsed, ok := parseLinkPreview(page); ok {
+		parsed.URL = link
+		preview = parsed
+		ttl = consts.LinkPreviewCacheTTL
+	}
+
+	s.cache(ctx, key, preview, ttl)
+	return preview, preview.Title != ""
+}
+
+func (s *LinkPreviewSvc) cached(ctx context.Context, key string) (model.LinkPreview, bool) {
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return model.LinkPreview{}, false
+	}
+
+	raw, err := redis.RedisConnector.Client.Get(ctx, key)
+	if err != nil || raw == "" {
+		return model.LinkPreview{}, false
+	}
+
+	preview := model.LinkPreview{}
+	if err := json.Unmarshal([]byte(raw), &preview); err != nil {
+		return model.LinkPreview{}, false
+	}
+	return preview, true
+}
+
+func (s *LinkPreviewSvc) cache(ctx context.Context, key string, preview model.LinkPreview, ttl time.Duration) {
+	raw, err := json.Marshal(preview)
+	if err != nil {
+		return
+	}
+
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return
+	}
+
+	// キャッシュできなくてもプレビュー自体は作れているので、ログに残すだけにする
+	if err := redis.RedisConnector.Client.Set(ctx, key, string(raw), ttl); err != nil {
+		fmt.Println("Failed to cache link preview:", err)
+	}
+}
+
+func linkPreviewCacheKey(link string) string {
+	hash := sha256.Sum256([]byte(link))
+	return "link_preview:" + hex.EncodeToString(hash[:])
+}
+
+// プレビューを作るURLを、重複を除いて先頭から上限件数まで取り出す
+func extractPreviewLinks(body string) []string {
+	links := []string{}
+	seen := map[string]bool{}
+	for _, link := range previewLinkPattern.FindAllString(body, -1) {
+		// 文末の句読点や閉じ括弧はURLに含めない
+		link = strings.TrimRight(link, ".,;:!?)]}>")
+		u, err := url.Parse(link)
+		if err != nil || u.Host == "" || seen[link] {
+			continue
+		}
+
+		seen[link] = true
+		links = append(links, link)
+		if len(links) >= consts.LinkPreviewMaxLinks {
+			break
+		}
+	}
+	return links
+}
+
+// head の OGP（なければ Twitter Card、title・description）からプレビューを作る
+func parseLinkPreview(page usecase.LinkPage) (model.LinkPreview, bool) {
+	reader, err := charset.NewReader(bytes.NewReader(page.Body), page.ContentType)
+	if err != nil {
+		reader = bytes.NewReader(page.Body)
+	}
+
+	meta, title := readHead(reader)
+
+	preview := model.LinkPreview{
+		Title:       firstNonEmpty(meta["og:title"], meta["twitter:title"], title),
+		Description: firstNonEmpty(meta["og:description"], meta["twitter:description"], meta["description"]),
+		SiteName:    meta["og:site_name"],
+	}
+	preview.Title = truncateRunes(preview.Title, consts.LinkPreviewTitleMaxLength)
+	preview.Description = truncateRunes(preview.Description, consts.LinkPreviewDescriptionMaxLength)
+	preview.SiteName = truncateRunes(preview.SiteName, consts.LinkPreviewTitleMaxLength)
+
+	image := firstNonEmpty(meta["og:image:secure_url"], meta["og:image"], meta["og:image:url"], meta["twitter:image"])
+	preview.ImageURL = resolvePreviewURL(page.URL, image)
+
+	return preview, preview.Title != ""
+}
+
+// meta タグ（property / name → content）と title を読み取る
+func readHead(reader io.Reader) (map[string]string, string) {
+	meta := map[string]string{}
+	title := ""
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(reader)
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return meta, title
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "body":
+				return meta, title
+			case "title":
+				inTitle = title == ""
+			case "meta":
+				var key, content string
+				for _, attr := range token.Attr {
+					switch attr.Key {
+					case "property", "name":
+						key = strings.ToLower(strings.TrimSpace(attr.Val))
+					case "content":
+						content = attr.Val
+					}
+				}
+				if key != "" && meta[key] == "" {
+					meta[key] = normalizeSpace(content)
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				title = normalizeSpace(string(tokenizer.Text()))
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = false
+			case "head":
+				return meta, title
+			}
+		}
+	}
+}
+
+// 相対パスをページのURLで解決し、http(s) 以外（javascript: など）は捨てる
+func resolvePreviewURL(pageURL string, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") || resolved.Host == "" {
+		return ""
+	}
+	return resolved.String()
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func normalizeSpace(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
+
+func truncateRunes(value string, max int) string {
+	if utf8.RuneCountInString(value) <= max {
+		return value
+	}
+	return string([]rune(value)[:max-1]) + "…"
+}
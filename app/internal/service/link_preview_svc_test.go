@@ -0,0 +1,270 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/text/encoding/japanese"
+)
+
+// svc_mock は service パッケージに依存しているため、同一パッケージのテストではスタブを使う
+type eventSvcStub struct {
+	EventSvcInterface
+	events []RoomEvent
+	err    error
+}
+
+func (s *eventSvcStub) Publish(event RoomEvent) (string, error) {
+	s.events = append(s.events, event)
+	return "1-0", s.err
+}
+
+const testOgpPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Fallback Title</title>
+  <meta property="og:title" content="  Example
+    Article ">
+  <meta property="og:description" content="An example article">
+  <meta property="og:image" content="/images/og.png">
+  <meta property="og:site_name" content="Example">
+</head>
+<body><meta property="og:title" content="ignored"></body>
+</html>`
+
+// 検証のためループバックアドレスへの接続を許可したフェッチャー
+func newLocalLinkFetcher() usecase.LinkFetcherInterface {
+	return usecase.NewLinkFetcherStruct(usecase.LinkFetcherConfig{
+		Timeout:      time.Second,
+		MaxBodySize:  consts.LinkPreviewMaxBodySize,
+		MaxRedirects: consts.LinkPreviewMaxRedirects,
+		AllowAddr:    func(netip.Addr) bool { return true },
+	})
+}
+
+func TestLinkPreviewUnfurl(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/article" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(testOgpPage))
+	}))
+	defer server.Close()
+
+	messageID := primitive.NewObjectID()
+	message := model.Message{
+		ID:      messageID,
+		RoomID:  "room1",
+		Message: "読んで " + server.URL + "/article。あと " + server.URL + "/missing も",
+	}
+	expectedPreviews := []model.LinkPreview{{
+		URL:         server.URL + "/article",
+		Title:       "Example Article",
+		Description: "An example article",
+		ImageURL:    server.URL + "/images/og.png",
+		SiteName:    "Example",
+	}}
+
+	redisMock := setupMiniRedis(t)
+	messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+	messageSvcMock.On("SetLinkPreviews", messageID.Hex(), "room1", expectedPreviews, mock.Anything).Return(nil)
+	eventSvc := &eventSvcStub{}
+
+	svc := NewLinkPreviewSvc(newLocalLinkFetcher(), redisMock, messageSvcMock, eventSvc, dto.NewMessageDtoStruct())
+	assert.NoError(t, svc.Unfurl(message))
+	assert.Equal(t, int32(2), requests.Load())
+
+	// 更新をルームに通知する
+	assert.Len(t, eventSvc.events, 1)
+	assert.Equal(t, RoomEvent{
+		Type:   consts.EventTypes.MessageUpdated,
+		RoomID: "room1",
+		Data: linkPreviewsUpdatedEvent{
+			MessageID:    messageID.Hex(),
+			LinkPreviews: dto.NewMessageDtoStruct().LinkPreviews(expectedPreviews),
+		},
+	}, eventSvc.events[0])
+
+	// 同じURLは取得できなかったものも含めてキャッシュを使う
+	assert.NoError(t, svc.Unfurl(message))
+	assert.Equal(t, int32(2), requests.Load())
+	messageSvcMock.AssertNumberOfCalls(t, "SetLinkPreviews", 2)
+}
+
+func TestLinkPreviewUnfurlWithoutPreview(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	t.Run("no links", func(t *testing.T) {
+		redisMock := setupMiniRedis(t)
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		eventSvc := &eventSvcStub{}
+
+		svc := NewLinkPreviewSvc(newLocalLinkFetcher(), redisMock, messageSvcMock, eventSvc, dto.NewMessageDtoStruct())
+		assert.NoError(t, svc.Unfurl(model.Message{ID: primitive.NewObjectID(), Message: "hello"}))
+		redisMock.AssertNotCalled(t, "RedisInit")
+		assert.Empty(t, eventSvc.events)
+	})
+
+	t.Run("nothing could be fetched", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		eventSvc := &eventSvcStub{}
+
+		svc := NewLinkPreviewSvc(newLocalLinkFetcher(), setupMiniRedis(t), messageSvcMock, eventSvc, dto.NewMessageDtoStruct())
+		assert.NoError(t, svc.Unfurl(model.Message{ID: primitive.NewObjectID(), Message: server.URL + "/missing"}))
+		messageSvcMock.AssertNotCalled(t, "SetLinkPreviews", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		assert.Empty(t, eventSvc.events)
+	})
+
+	t.Run("private address is not fetched", func(t *testing.T) {
+		fetcher := usecase.NewLinkFetcherStruct(usecase.LinkFetcherConfig{
+			Timeout:     time.Second,
+			MaxBodySize: consts.LinkPreviewMaxBodySize,
+		})
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+
+		svc := NewLinkPreviewSvc(fetcher, setupMiniRedis(t), messageSvcMock, &eventSvcStub{}, dto.NewMessageDtoStruct())
+		assert.NoError(t, svc.Unfurl(model.Message{ID: primitive.NewObjectID(), Message: "http://169.254.169.254/latest/meta-data/"}))
+		messageSvcMock.AssertNotCalled(t, "SetLinkPreviews", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLinkPreviewUnfurlError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(testOgpPage))
+	}))
+	defer server.Close()
+	message := model.Message{ID: primitive.NewObjectID(), RoomID: "room1", Message: server.URL}
+
+	t.Run("update error", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		messageSvcMock.On("SetLinkPreviews", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+		eventSvc := &eventSvcStub{}
+
+		svc := NewLinkPreviewSvc(newLocalLinkFetcher(), setupMiniRedis(t), messageSvcMock, eventSvc, dto.NewMessageDtoStruct())
+		assert.Error(t, svc.Unfurl(message))
+		assert.Empty(t, eventSvc.events)
+	})
+
+	t.Run("publish error", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		messageSvcMock.On("SetLinkPreviews", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		svc := NewLinkPreviewSvc(newLocalLinkFetcher(), setupMiniRedis(t), messageSvcMock, &eventSvcStub{err: assert.AnError}, dto.NewMessageDtoStruct())
+		assert.Error(t, svc.Unfurl(message))
+	})
+
+	t.Run("redis unavailable", func(t *testing.T) {
+		// キャッシュが使えなくてもプレビューは作る
+		redisMock := setupMiniRedis(t)
+		redisMock.ExpectedCalls = nil
+		redisMock.On("RedisInit").Return(&usecase.Redis{}, assert.AnError)
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		messageSvcMock.On("SetLinkPreviews", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		svc := NewLinkPreviewSvc(newLocalLinkFetcher(), redisMock, messageSvcMock, &eventSvcStub{}, dto.NewMessageDtoStruct())
+		assert.NoError(t, svc.Unfurl(message))
+		messageSvcMock.AssertNumberOfCalls(t, "SetLinkPreviews", 1)
+	})
+}
+
+func TestParseLinkPreview(t *testing.T) {
+	shiftJIS, err := japanese.ShiftJIS.NewEncoder().String(`<html><head><meta charset="Shift_JIS"><title>日本語のページ</title></head></html>`)
+	assert.NoError(t, err)
+
+	expected := map[string]struct {
+		page     usecase.LinkPage
+		expected model.LinkPreview
+		ok       bool
+	}{
+		"opengraph": {
+			page: usecase.LinkPage{URL: "https://example.com/a/b", ContentType: "text/html", Body: []byte(testOgpPage)},
+			expected: model.LinkPreview{
+				Title:       "Example Article",
+				Description: "An example article",
+				ImageURL:    "https://example.com/images/og.png",
+				SiteName:    "Example",
+			},
+			ok: true,
+		},
+		"twitter card and description fallback": {
+			page: usecase.LinkPage{URL: "https://example.com", ContentType: "text/html", Body: []byte(
+				`<head><meta name="twitter:title" content="Tweet"><meta name="description" content="Plain description"><meta name="twitter:image" content="https://cdn.example.com/x.png"></head>`,
+			)},
+			expected: model.LinkPreview{
+				Title:       "Tweet",
+				Description: "Plain description",
+				ImageURL:    "https://cdn.example.com/x.png",
+			},
+			ok: true,
+		},
+		"title only": {
+			page:     usecase.LinkPage{URL: "https://example.com", ContentType: "text/html", Body: []byte(`<title>Just a title</title>`)},
+			expected: model.LinkPreview{Title: "Just a title"},
+			ok:       true,
+		},
+		"unsafe image scheme is dropped": {
+			page: usecase.LinkPage{URL: "https://example.com", ContentType: "text/html", Body: []byte(
+				`<head><meta property="og:title" content="x"><meta property="og:image" content="javascript:alert(1)"></head>`,
+			)},
+			expected: model.LinkPreview{Title: "x"},
+			ok:       true,
+		},
+		"shift_jis": {
+			page:     usecase.LinkPage{URL: "https://example.jp", ContentType: "text/html", Body: []byte(shiftJIS)},
+			expected: model.LinkPreview{Title: "日本語のページ"},
+			ok:       true,
+		},
+		"long title is truncated": {
+			page: usecase.LinkPage{URL: "https://example.com", ContentType: "text/html", Body: []byte(
+				`<title>` + strings.Repeat("あ", consts.LinkPreviewTitleMaxLength+10) + `</title>`,
+			)},
+			expected: model.LinkPreview{Title: strings.Repeat("あ", consts.LinkPreviewTitleMaxLength-1) + "…"},
+			ok:       true,
+		},
+		"no title": {
+			page: usecase.LinkPage{URL: "https://example.com", ContentType: "text/html", Body: []byte(`<head></head><body><title>late</title></body>`)},
+			ok:   false,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			preview, ok := parseLinkPreview(tt.page)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected, preview)
+			}
+		})
+	}
+}
+
+func TestExtractPreviewLinks(t *testing.T) {
+	assert.Equal(t, []string{}, extractPreviewLinks("no links here"))
+	assert.Equal(t, []string{"https://example.com/a"}, extractPreviewLinks("see https://example.com/a."))
+	assert.Equal(t, []string{"https://example.com/a"}, extractPreviewLinks("これ（https://example.com/a）を見て。"))
+	assert.Equal(t, []string{"https://example.com/a", "http://example.org"}, extractPreviewLinks("https://example.com/a https://example.com/a http://example.org"))
+	assert.Len(t, extractPreviewLinks("https://a.example https://b.example https://c.example https://d.example"), consts.LinkPreviewMaxLinks)
+	// 日本語の文章に続くURLも切り出す
+	assert.Equal(t, []string{"https://example.com/page"}, extractPreviewLinks("詳細はhttps://example.com/page、確認してください"))
+}
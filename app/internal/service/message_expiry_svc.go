@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+// 有効期間（秒）として指定できる値か。0 は期限なしを表す
+func ValidMessageTTL(ttl int) bool {
+	return ttl == 0 || (ttl >= consts.MessageTTLMinSeconds && ttl <= consts.MessageTTLMaxSeconds)
+}
+
+// 送信するメッセージの有効期限を返す
+// メッセージごとの指定がなければルームの既定を使い、どちらもなければ nil（期限なし）
+func MessageExpiresAt(ttl int, room model.Room, createdAt time.Time) *time.Time {
+	if ttl == 0 {
+		ttl = room.MessageTTL
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	expiresAt := createdAt.Add(time.Duration(ttl) * time.Second)
+	return &expiresAt
+}
+
+type MessageExpirySvcInterface interface {
+	RunNext() (bool, error)
+}
+
+type MessageExpirySvc struct {
+	mongoMessageSvc mongo_svc.MessageSvcInterface
+	attachmentSvc   AttachmentSvcInterface
+	eventSvc        EventSvcInterface
+	clock           atylabclock.ClockInterface
+}
+
+func NewMessageExpirySvc(
+	mongoMessageSvc mongo_svc.MessageSvcInterface,
+	attachmentSvc AttachmentSvcInterface,
+	eventSvc EventSvcInterface,
+	clock atylabclock.ClockInterface,
+) MessageExpirySvcInterface {
+	return &MessageExpirySvc{
+		mongoMessageSvc: mongoMessageSvc,
+		attachmentSvc:   attachmentSvc,
+		eventSvc:        eventSvc,
+		clock:           clock,
+	}
+}
+
+type messageDeletedEvent struct {
+	MessageID string `json:"message_id"`
+	Reason    string `json:"reason"`
+}
+
+// 有効期限を過ぎたメッセージを添付ファイルごと削除し、ルームのメンバーに通知する
+// TTL インデックスは猶予を置いて削除するので、期限どおりの削除と通知はこちらで行う
+func (s *MessageExpirySvc) RunNext() (bool, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	messages, err := s.mongoMessageSvc.GetExpiredMessages(s.clock.Now(), consts.MessageExpiryBatchSize, ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(messages) == 0 {
+		return false, nil
+	}
+
+	for _, message := range messages {
+		deleted, err := s.mongoMessageSvc.DeleteExpiredMessage(message, ctx)
+		if err != nil {
+			return false, err
+		}
+		// 他のインスタンスが削除した場合は、そちらで通知している
+		if !deleted {
+			continue
+		}
+
+		s.attachmentSvc.Remove(context.Background(), message.Attachments)
+
+		_, err = s.eventSvc.Publish(RoomEvent{
+			Type:   consts.EventTypes.MessageDeleted,
+			RoomID: message.RoomID,
+			Data: messageDeletedEvent{
+				MessageID: message.ID.Hex(),
+				Reason:    "expired",
+			},
+		})
+		if err != nil {
+			// 通知できなくても、クライアントは有効期限を元に表示を消せる
+			fmt.Println("Failed to publish message deleted event:", err)
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidMessageTTL(t *testing.T) {
+	assert.True(t, ValidMessageTTL(0))
+	assert.True(t, ValidMessageTTL(consts.MessageTTLMinSeconds))
+	assert.True(t, ValidMessageTTL(consts.MessageTTLMaxSeconds))
+	assert.False(t, ValidMessageTTL(consts.MessageTTLMinSeconds-1))
+	assert.False(t, ValidMessageTTL(consts.MessageTTLMaxSeconds+1))
+	assert.False(t, ValidMessageTTL(-1))
+}
+
+func TestMessageExpiresAt(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		ttl      int
+		room     model.Room
+		expected *time.Time
+	}{
+		{"no expiry", 0, model.Room{}, nil},
+		{"message ttl", 60, model.Room{}, ptrTime(createdAt.Add(time.Minute))},
+		{"room default", 0, model.Room{MessageTTL: 3600}, ptrTime(createdAt.Add(time.Hour))},
+		{"message ttl takes precedence", 60, model.Room{MessageTTL: 3600}, ptrTime(createdAt.Add(time.Minute))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MessageExpiresAt(tt.ttl, tt.room, createdAt))
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestMessageExpiryRunNext(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	expired := model.Message{
+		ID:        primitive.NewObjectID(),
+		RoomID:    "room1",
+		ExpiresAt: ptrTime(now.Add(-time.Second)),
+		Attachments: []model.Attachment{
+			{StorageKey: "attachments/room1/secret"},
+		},
+	}
+	other := model.Message{
+		ID:        primitive.NewObjectID(),
+		RoomID:    "room2",
+		ExpiresAt: ptrTime(now.Add(-time.Second)),
+	}
+
+	tests := []struct {
+		name            string
+		messages        []model.Message
+		getErr          error
+		deleted         bool
+		deleteErr       error
+		publishErr      error
+		expectProcessed bool
+		expectErr       bool
+		expectEvents    int
+		expectRemoved   int
+	}{
+		{"no expired messages", []model.Message{}, nil, true, nil, nil, false, false, 0, 0},
+		{"deleted", []model.Message{expired, other}, nil, true, nil, nil, true, false, 2, 1},
+		{"publish error is ignored", []model.Message{expired}, nil, true, nil, assert.AnError, true, false, 1, 1},
+		{"already deleted elsewhere", []model.Message{expired}, nil, false, nil, nil, true, false, 0, 0},
+		{"get error", nil, assert.AnError, true, nil, nil, false, true, 0, 0},
+		{"delete error", []model.Message{expired}, nil, false, assert.AnError, nil, false, true, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetExpiredMessages", now, consts.MessageExpiryBatchSize, mock.Anything).Return(tt.messages, tt.getErr)
+			messageSvcMock.On("DeleteExpiredMessage", mock.Anything, mock.Anything).Return(tt.deleted, tt.deleteErr)
+
+			backendMock := new(usecase_mock.StorageBackendMock)
+			backendMock.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			storageMock := new(usecase_mock.StorageUseCaseMock)
+			storageMock.On("StorageInit").Return(&usecase.Storage{Backend: backendMock}, nil)
+
+			events := &eventSvcStub{err: tt.publishErr}
+			svc := NewMessageExpirySvc(messageSvcMock, NewAttachmentSvc(storageMock), events, atylabclock.NewClockMock(now))
+
+			processed, err := svc.RunNext()
+			assert.Equal(t, tt.expectProcessed, processed)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Len(t, events.events, tt.expectEvents)
+			backendMock.AssertNumberOfCalls(t, "Delete", tt.expectRemoved)
+			if tt.expectEvents > 0 {
+				assert.Equal(t, consts.EventTypes.MessageDeleted, events.events[0].Type)
+				assert.Equal(t, "room1", events.events[0].RoomID)
+				assert.Equal(t, messageDeletedEvent{MessageID: expired.ID.Hex(), Reason: "expired"}, events.events[0].Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrSpamRejected = errors.New("message rejected as spam")
+
+type MessageSvcInterface interface {
+	Send(message model.Message, ctx *atylabmongo.MongoCtxSvc) (string, error)
+}
+
+type MessageSvc struct {
+	mongoMessageSvc mongo_svc.MessageSvcInterface
+	mongoReportSvc  mongo_svc.ReportSvcInterface
+	spamSvc         SpamSvcInterface
+	linkPreviewSvc  LinkPreviewSvcInterface
+	webhookSvc      WebhookSvcInterface
+	eventSvc        EventSvcInterface
+	messageDto      dto.MessageDtoInterface
+	spamAction      string
+}
+
+func NewMessageSvc(
+	mongoMessageSvc mongo_svc.MessageSvcInterface,
+	mongoReportSvc mongo_svc.ReportSvcInterface,
+	spamSvc SpamSvcInterface,
+	linkPreviewSvc LinkPreviewSvcInterface,
+	webhookSvc WebhookSvcInterface,
+	eventSvc EventSvcInterface,
+	messageDto dto.MessageDtoInterface,
+	spamAction string,
+) MessageSvcInterface {
+	if spamAction != consts.SpamActions.Review {
+		spamAction = consts.SpamActions.Reject
+	}
+	return &MessageSvc{
+		mongoMessageSvc: mongoMessageSvc,
+		mongoReportSvc:  mongoReportSvc,
+		spamSvc:         spamSvc,
+		linkPreviewSvc:  linkPreviewSvc,
+		webhookSvc:      webhookSvc,
+		eventSvc:        eventSvc,
+		messageDto:      messageDto,
+		spamAction:      spamAction,
+	}
+}
+
+// メッセージ送信の共通処理
+// スパム判定を行い、設定に応じて拒否するかモデレーターの確認待ちとして通報を起票する
+// client_msg_id 付きの再送は、保存済みのメッセージIDをそのまま返す
+// 保存したメッセージは、ルームのメンバーへのイベントと Webhook にも配信する
+func (s *MessageSvc) Send(message model.Message, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	if message.ClientMsgID != "" {
+		messageID, err := s.findSent(message, ctx)
+		if err != nil || messageID != "" {
+			return messageID, err
+		}
+	}
+
+	spam, err := s.spamSvc.Check(message)
+	if err != nil {
+		// 判定できない場合は送信を優先する
+		fmt.Println("Failed to check spam:", err)
+		spam = SpamCheckResult{}
+	}
+
+	if spam.Flagged && s.spamAction == consts.SpamActions.Reject {
+		s.reportSpam(message, "", spam, ctx)
+		return "", ErrSpamRejected
+	}
+
+	messageID, err := s.mongoMessageSvc.SendMessage(message, ctx)
+	if err != nil && message.ClientMsgID != "" && mongo.IsDuplicateKeyError(err) {
+		// 同じ再送が並行して保存された場合は、先に保存された方を正とする
+		return s.findSent(message, ctx)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if spam.Flagged {
+		s.reportSpam(message, messageID, spam, ctx)
+	}
+
+	s.unfurlLinks(message, messageID)
+	s.publishCreated(message, messageID)
+
+	s.webhookSvc.Dispatch(message.RoomID, consts.WebhookEvents.MessageCreated, WebhookMessageData{
+		MessageID: messageID,
+		Sender:    message.Sender,
+		Message:   message.Message,
+		CreatedAt: message.CreatedAt,
+	})
+
+	return messageID, nil
+}
+
+// URLを含むメッセージは、送信のレスポンスを待たせないようプレビューの取得を裏で行う
+func (s *MessageSvc) unfurlLinks(message model.Message, messageID string) {
+	if !previewLinkPattern.MatchString(message.Message) {
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return
+	}
+	message.ID = id
+
+	go func() {
+		if err := s.linkPreviewSvc.Unfurl(message); err != nil {
+			fmt.Println("Failed to unfurl links:", err)
+		}
+	}()
+}
+
+// 送信した本人の他の端末にも届けるため、ExceptUuid は指定しない
+// イベントを記録できなくてもメッセージの送信は成功として扱う
+func (s *MessageSvc) publishCreated(message model.Message, messageID string) {
+	id, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return
+	}
+	message.ID = id
+
+	if _, err := s.eventSvc.Publish(RoomEvent{
+		Type:   consts.EventTypes.MessageCreated,
+		RoomID: message.RoomID,
+		Data:   s.messageDto.GetMessageInfo(message, ""),
+	}); err != nil {
+		fmt.Println("Failed to publish message event:", err)
+	}
+}
+
+// 送信済みであればそのメッセージIDを、未送信であれば空文字を返す
+func (s *MessageSvc) findSent(message model.Message, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	sent, err := s.mongoMessageSvc.FindByClientMsgID(message.RoomID, message.Sender, message.ClientMsgID, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return sent.ID.Hex(), nil
+}
+
+func (s *MessageSvc) reportSpam(message model.Message, messageID string, spam SpamCheckResult, ctx *atylabmongo.MongoCtxSvc) {
+	report := model.Report{
+		RoomID:         message.RoomID,
+		MessageID:      messageID,
+		ReporterID:     consts.ReportSystemReporter,
+		ReportedUserID: message.Sender,
+		Reason:         consts.ReportReasons.Spam,
+		Comment:        fmt.Sprintf("[%s] %s (%s)", s.spamAction, spam.Reason, spam.Detail),
+		Snapshot:       message.Message,
+		Status:         consts.ReportStatus.Open,
+		CreatedAt:      time.Now(),
+	}
+
+	if _, err := s.mongoReportSvc.CreateReport(report, ctx); err != nil {
+		fmt.Println("Failed to create spam report:", err)
+	}
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// svc_mock は service パッケージに依存しているため、同一パッケージのテストではスタブを使う
+type spamSvcStub struct {
+	result SpamCheckResult
+	err    error
+}
+
+func (s *spamSvcStub) Check(message model.Message) (SpamCheckResult, error) {
+	return s.result, s.err
+}
+
+type linkPreviewSvcStub struct {
+	unfurled chan model.Message
+}
+
+func (s *linkPreviewSvcStub) Unfurl(message model.Message) error {
+	if s.unfurled != nil {
+		s.unfurled <- message
+	}
+	return nil
+}
+
+type webhookSvcStub struct {
+	dispatched []WebhookMessageData
+	joined     []WebhookMemberData
+}
+
+func (s *webhookSvcStub) Register(webhook model.Webhook, ctx *atylabmongo.MongoCtxSvc) (model.Webhook, error) {
+	return webhook, nil
+}
+
+func (s *webhookSvcStub) Dispatch(roomID string, event string, data any) {
+	switch event {
+	case consts.WebhookEvents.MessageCreated:
+		s.dispatched = append(s.dispatched, data.(WebhookMessageData))
+	case consts.WebhookEvents.MemberJoined:
+		s.joined = append(s.joined, data.(WebhookMemberData))
+	}
+}
+
+func (s *webhookSvcStub) RunNext() (bool, error) {
+	return false, nil
+}
+
+func TestMessageSend(t *testing.T) {
+	sentID := primitive.NewObjectID()
+	flagged := SpamCheckResult{Flagged: true, Reason: consts.SpamReasons.DuplicateAcrossRooms, Detail: "same message posted to 3 rooms"}
+
+	expected := map[string]struct {
+		spamAction     string
+		spam           SpamCheckResult
+		spamErr        error
+		sendErr        error
+		expectSend     bool
+		expectReport   bool
+		expectReportID string
+		expectErr      error
+		expectAnyErr   bool
+	}{
+		"not spam": {
+			spamAction: consts.SpamActions.Reject,
+			expectSend: true,
+		},
+		"spam rejected": {
+			spamAction:     consts.SpamActions.Reject,
+			spam:           flagged,
+			expectReport:   true,
+			expectReportID: "",
+			expectErr:      ErrSpamRejected,
+		},
+		"spam sent to review": {
+			spamAction:     consts.SpamActions.Review,
+			spam:           flagged,
+			expectSend:     true,
+			expectReport:   true,
+			expectReportID: sentID.Hex(),
+		},
+		"unknown action defaults to reject": {
+			spamAction:   "",
+			spam:         flagged,
+			expectReport: true,
+			expectErr:    ErrSpamRejected,
+		},
+		"spam check error is ignored": {
+			spamAction: consts.SpamActions.Reject,
+			spamErr:    assert.AnError,
+			expectSend: true,
+		},
+		"send error": {
+			spamAction:   consts.SpamActions.Reject,
+			sendErr:      assert.AnError,
+			expectSend:   true,
+			expectAnyErr: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+
+			message := model.Message{RoomID: "room1", Sender: "sender-uuid", Message: "buy cheap watches"}
+
+			messageSvcMock.On("SendMessage", message, mock.Anything).Return(sentID.Hex(), tt.sendErr)
+			reportSvcMock.On("CreateReport", mock.MatchedBy(func(r model.Report) bool {
+				return r.MessageID == tt.expectReportID &&
+					r.RoomID == "room1" &&
+					r.ReporterID == consts.ReportSystemReporter &&
+					r.ReportedUserID == "sender-uuid" &&
+					r.Reason == consts.ReportReasons.Spam &&
+					r.Snapshot == "buy cheap watches" &&
+					r.Status == consts.ReportStatus.Open
+			}), mock.Anything).Return("report-id", nil)
+
+			webhookSvc := &webhookSvcStub{}
+			eventSvc := &eventSvcStub{}
+			svc := NewMessageSvc(messageSvcMock, reportSvcMock, &spamSvcStub{result: tt.spam, err: tt.spamErr}, &linkPreviewSvcStub{}, webhookSvc, eventSvc, dto.NewMessageDtoStruct(), tt.spamAction)
+			messageID, err := svc.Send(message, nil)
+
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.expectAnyErr:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, sentID.Hex(), messageID)
+			}
+
+			if tt.expectSend {
+				messageSvcMock.AssertNumberOfCalls(t, "SendMessage", 1)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+			}
+			// 保存できたメッセージだけをルームのメンバーと Webhook に配信する
+			if tt.expectSend && tt.sendErr == nil {
+				assert.Equal(t, []WebhookMessageData{{
+					MessageID: sentID.Hex(),
+					Sender:    "sender-uuid",
+					Message:   "buy cheap watches",
+				}}, webhookSvc.dispatched)
+				assert.Len(t, eventSvc.events, 1)
+				assert.Equal(t, consts.EventTypes.MessageCreated, eventSvc.events[0].Type)
+				assert.Equal(t, "room1", eventSvc.events[0].RoomID)
+				assert.Empty(t, eventSvc.events[0].ExceptUuid)
+				data := eventSvc.events[0].Data.(dto.MessageResponse)
+				assert.Equal(t, sentID.Hex(), data.ID)
+				assert.Equal(t, "buy cheap watches", data.Message)
+			} else {
+				assert.Empty(t, webhookSvc.dispatched)
+				assert.Empty(t, eventSvc.events)
+			}
+			if tt.expectReport {
+				reportSvcMock.AssertNumberOfCalls(t, "CreateReport", 1)
+			} else {
+				reportSvcMock.AssertNotCalled(t, "CreateReport", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestMessageSendReportError(t *testing.T) {
+	messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+	reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+	reportSvcMock.On("CreateReport", mock.Anything, mock.Anything).Return("", assert.AnError)
+
+	svc := NewMessageSvc(messageSvcMock, reportSvcMock, &spamSvcStub{result: SpamCheckResult{Flagged: true}}, &linkPreviewSvcStub{}, &webhookSvcStub{}, &eventSvcStub{}, dto.NewMessageDtoStruct(), consts.SpamActions.Reject)
+	_, err := svc.Send(model.Message{}, nil)
+
+	// 通報の起票に失敗しても拒否の結果は変わらない
+	assert.ErrorIs(t, err, ErrSpamRejected)
+}
+
+func TestMessageSendWithClientMsgID(t *testing.T) {
+	sentID := primitive.NewObjectID()
+	duplicateErr := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}
+
+	expected := map[string]struct {
+		findResults  []model.Message
+		findErrs     []error
+		sendErr      error
+		expectSend   bool
+		expectID     string
+		expectAnyErr bool
+	}{
+		"first send": {
+			findResults: []model.Message{{}},
+			findErrs:    []error{mongo.ErrNoDocuments},
+			expectSend:  true,
+			expectID:    "new-message-id",
+		},
+		"retry returns original message": {
+			findResults: []model.Message{{ID: sentID}},
+			findErrs:    []error{nil},
+			expectID:    sentID.Hex(),
+		},
+		"concurrent retry hits unique index": {
+			findResults: []model.Message{{}, {ID: sentID}},
+			findErrs:    []error{mongo.ErrNoDocuments, nil},
+			sendErr:     duplicateErr,
+			expectSend:  true,
+			expectID:    sentID.Hex(),
+		},
+		"find error": {
+			findResults:  []model.Message{{}},
+			findErrs:     []error{assert.AnError},
+			expectAnyErr: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+
+			message := model.Message{RoomID: "room1", Sender: "sender-uuid", Message: "hello", ClientMsgID: "client-1"}
+
+			for i := range tt.findResults {
+				messageSvcMock.On("FindByClientMsgID", "room1", "sender-uuid", "client-1", mock.Anything).
+					Return(tt.findResults[i], tt.findErrs[i]).Once()
+			}
+			messageSvcMock.On("SendMessage", message, mock.Anything).Return("new-message-id", tt.sendErr)
+
+			svc := NewMessageSvc(messageSvcMock, reportSvcMock, &spamSvcStub{}, &linkPreviewSvcStub{}, &webhookSvcStub{}, &eventSvcStub{}, dto.NewMessageDtoStruct(), consts.SpamActions.Reject)
+			messageID, err := svc.Send(message, nil)
+
+			if tt.expectAnyErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectID, messageID)
+			}
+			if tt.expectSend {
+				messageSvcMock.AssertNumberOfCalls(t, "SendMessage", 1)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestMessageSendUnfurlsLinks(t *testing.T) {
+	sentID := primitive.NewObjectID()
+
+	expected := map[string]struct {
+		body         string
+		expectUnfurl bool
+	}{
+		"with link":    {body: "see https://example.com", expectUnfurl: true},
+		"without link": {body: "hello"},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			message := model.Message{RoomID: "room1", Sender: "user1", Message: tt.body}
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("SendMessage", message, mock.Anything).Return(sentID.Hex(), nil)
+
+			linkPreviewSvc := &linkPreviewSvcStub{unfurled: make(chan model.Message, 1)}
+			svc := NewMessageSvc(messageSvcMock, new(mongo_svc_mock.ReportSvcMock), &spamSvcStub{}, linkPreviewSvc, &webhookSvcStub{}, &eventSvcStub{}, dto.NewMessageDtoStruct(), consts.SpamActions.Reject)
+			_, err := svc.Send(message, nil)
+			assert.NoError(t, err)
+
+			if !tt.expectUnfurl {
+				assert.Empty(t, linkPreviewSvc.unfurled)
+				return
+			}
+			// 保存後のIDでプレビューを取得する
+			select {
+			case unfurled := <-linkPreviewSvc.unfurled:
+				assert.Equal(t, sentID, unfurled.ID)
+				assert.Equal(t, tt.body, unfurled.Message)
+			case <-time.After(time.Second):
+				t.Fatal("links were not unfurled")
+			}
+		})
+	}
+}
+
+func TestMessageSendPublishesToStream(t *testing.T) {
+	sentID := primitive.NewObjectID()
+	message := model.Message{RoomID: "room1", Sender: "sender-uuid", Message: "hello", ClientMsgID: "client-1"}
+
+	messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+	messageSvcMock.On("FindByClientMsgID", "room1", "sender-uuid", "client-1", mock.Anything).Return(model.Message{}, mongo.ErrNoDocuments)
+	messageSvcMock.On("SendMessage", message, mock.Anything).Return(sentID.Hex(), nil)
+
+	eventSvc := NewEventSvc(setupMiniRedis(t))
+	cursor, _, err := eventSvc.Resume("", context.Background())
+	assert.NoError(t, err)
+
+	svc := NewMessageSvc(messageSvcMock, new(mongo_svc_mock.ReportSvcMock), &spamSvcStub{}, &linkPreviewSvcStub{}, &webhookSvcStub{}, eventSvc, dto.NewMessageDtoStruct(), consts.SpamActions.Reject)
+	_, err = svc.Send(message, nil)
+	assert.NoError(t, err)
+
+	// 送信したメッセージが全ルームの Stream から読み出せる
+	events, err := eventSvc.Read(cursor, context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Equal(t, consts.EventTypes.MessageCreated, events[0].Type)
+	assert.Equal(t, "room1", events[0].RoomID)
+	assert.Empty(t, events[0].ExceptUuid)
+
+	published := struct {
+		Data dto.MessageResponse `json:"data"`
+	}{}
+	assert.NoError(t, json.Unmarshal([]byte(events[0].Payload), &published))
+	assert.Equal(t, sentID.Hex(), published.Data.ID)
+	assert.Equal(t, "sender-uuid", published.Data.Sender)
+	assert.Equal(t, "hello", published.Data.Message)
+	assert.Equal(t, "client-1", published.Data.ClientMsgID)
+}
+
+func TestMessageSendPublishError(t *testing.T) {
+	sentID := primitive.NewObjectID()
+	message := model.Message{RoomID: "room1", Sender: "sender-uuid", Message: "hello"}
+
+	messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+	messageSvcMock.On("SendMessage", message, mock.Anything).Return(sentID.Hex(), nil)
+
+	webhookSvc := &webhookSvcStub{}
+	svc := NewMessageSvc(messageSvcMock, new(mongo_svc_mock.ReportSvcMock), &spamSvcStub{}, &linkPreviewSvcStub{}, webhookSvc, &eventSvcStub{err: assert.AnError}, dto.NewMessageDtoStruct(), consts.SpamActions.Reject)
+	messageID, err := svc.Send(message, nil)
+
+	// イベントを記録できなくても送信は成功し、Webhook にも配信する
+	assert.NoError(t, err)
+	assert.Equal(t, sentID.Hex(), messageID)
+	assert.Len(t, webhookSvc.dispatched, 1)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrReportAlreadyResolved = errors.New("report already resolved")
+
+const (
+	// 通報されたメッセージの前後に表示するメッセージ数
+	reportContextSize = 5
+	// 前後のメッセージを検索する時間幅
+	reportContextWindow = 24 * time.Hour
+)
+
+type ModerationSvcInterface interface {
+	GetReportContext(report model.Report, ctx *atylabmongo.MongoCtxSvc) (model.Message, []model.Message, error)
+	Resolve(report model.Report, action string, moderatorID string, note string, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type ModerationSvc struct {
+	mongoReportSvc  mongo_svc.ReportSvcInterface
+	mongoMessageSvc mongo_svc.MessageSvcInterface
+	mongoRoomSvc    mongo_svc.RoomSvcInterface
+	clock           atylabclock.ClockInterface
+}
+
+func NewModerationSvc(
+	mongoReportSvc mongo_svc.ReportSvcInterface,
+	mongoMessageSvc mongo_svc.MessageSvcInterface,
+	mongoRoomSvc mongo_svc.RoomSvcInterface,
+	clock atylabclock.ClockInterface,
+) ModerationSvcInterface {
+	return &ModerationSvc{
+		mongoReportSvc:  mongoReportSvc,
+		mongoMessageSvc: mongoMessageSvc,
+		mongoRoomSvc:    mongoRoomSvc,
+		clock:           clock,
+	}
+}
+
+// 通報されたメッセージと、その前後のメッセージを返す
+func (s *ModerationSvc) GetReportContext(report model.Report, ctx *atylabmongo.MongoCtxSvc) (model.Message, []model.Message, error) {
+	// 拒否された投稿の通報はメッセージが保存されていない
+	if report.MessageID == "" {
+		return model.Message{}, nil, mongo.ErrNoDocuments
+	}
+
+	message, err := s.mongoMessageSvc.GetMessage(report.MessageID, report.RoomID, ctx)
+	if err != nil {
+		return model.Message{}, nil, err
+	}
+
+	around, err := s.mongoMessageSvc.GetMessagesAround(report.RoomID, message.CreatedAt, reportContextWindow, ctx)
+	if err != nil {
+		return model.Message{}, nil, err
+	}
+
+	index := 0
+	for i, m := range around {
+		if m.ID == message.ID {
+			index = i
+			break
+		}
+	}
+
+	start := max(index-reportContextSize, 0)
+	end := min(index+reportContextSize+1, len(around))
+
+	return message, around[start:end], nil
+}
+
+// 同時に対応した他のモデレーターと処分が重ならないよう、先に通報を解決済みにしてから処分する
+// 処分に失敗した場合は、もう一度対応できるよう通報を未対応に戻す
+func (s *ModerationSvc) Resolve(report model.Report, action string, moderatorID string, note string, ctx *atylabmongo.MongoCtxSvc) error {
+	if report.Status != consts.ReportStatus.Open {
+		return ErrReportAlreadyResolved
+	}
+
+	switch action {
+	case consts.ReportActions.Dismissed, consts.ReportActions.Deleted, consts.ReportActions.UserBanned:
+	default:
+		return fmt.Errorf("invalid action: %s", action)
+	}
+
+	resolution := model.ReportResolution{
+		Action:     action,
+		ResolvedBy: moderatorID,
+		Note:       note,
+		ResolvedAt: s.clock.Now(),
+	}
+	resolved, err := s.mongoReportSvc.ResolveReport(report.ID.Hex(), resolution, ctx)
+	if err != nil {
+		return err
+	}
+	if !resolved {
+		return ErrReportAlreadyResolved
+	}
+
+	if err := s.applyAction(report, action, moderatorID, ctx); err != nil {
+		if reopenErr := s.mongoReportSvc.ReopenReport(report.ID.Hex(), resolution, ctx); reopenErr != nil {
+			fmt.Println("Failed to reopen report:", reopenErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (s *ModerationSvc) applyAction(report model.Report, action string, moderatorID string, ctx *atylabmongo.MongoCtxSvc) error {
+	switch action {
+	case consts.ReportActions.Deleted:
+		return s.deleteMessage(report, moderatorID, ctx)
+	case consts.ReportActions.UserBanned:
+		if err := s.deleteMessage(report, moderatorID, ctx); err != nil {
+			return err
+		}
+		return s.mongoRoomSvc.BanMember(report.RoomID, report.ReportedUserID, ctx)
+	}
+	return nil
+}
+
+func (s *ModerationSvc) deleteMessage(report model.Report, moderatorID string, ctx *atylabmongo.MongoCtxSvc) error {
+	if report.MessageID == "" {
+		return nil
+	}
+	return s.mongoMessageSvc.DeleteMessage(report.MessageID, report.RoomID, moderatorID, ctx)
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestGetReportContext(t *testing.T) {
+	now := time.Now()
+	around := []model.Message{}
+	for i := range 15 {
+		around = append(around, model.Message{
+			ID:        primitive.NewObjectID(),
+			RoomID:    "roomId",
+			CreatedAt: now.Add(time.Duration(i-7) * time.Minute),
+		})
+	}
+	target := around[7]
+
+	expected := map[string]struct {
+		getMessageErr error
+		aroundErr     error
+		around        []model.Message
+		expectLen     int
+		expectErr     bool
+	}{
+		"success": {
+			around:    around,
+			expectLen: reportContextSize*2 + 1,
+		},
+		"success_near_edge": {
+			around:    around[5:10],
+			expectLen: 5,
+		},
+		"get_message_error": {
+			getMessageErr: assert.AnError,
+			expectErr:     true,
+		},
+		"around_error": {
+			aroundErr: assert.AnError,
+			expectErr: true,
+		},
+	}
+
+	t.Run("rejected_message", func(t *testing.T) {
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		svc := NewModerationSvc(new(mongo_svc_mock.ReportSvcMock), messageSvcMock, new(mongo_svc_mock.RoomSvcMock), atylabclock.NewClockMock(time.Now()))
+
+		_, _, err := svc.GetReportContext(model.Report{RoomID: "roomId"}, nil)
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		messageSvcMock.AssertNotCalled(t, "GetMessage", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+
+			messageSvcMock.On("GetMessage", target.ID.Hex(), "roomId", mock.Anything).Return(target, tt.getMessageErr)
+			messageSvcMock.On("GetMessagesAround", "roomId", target.CreatedAt, reportContextWindow, mock.Anything).Return(tt.around, tt.aroundErr)
+
+			svc := NewModerationSvc(reportSvcMock, messageSvcMock, roomSvcMock, atylabclock.NewClockMock(time.Now()))
+			report := model.Report{RoomID: "roomId", MessageID: target.ID.Hex()}
+
+			message, context, err := svc.GetReportContext(report, nil)
+			if tt.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, target.ID, message.ID)
+			assert.Len(t, context, tt.expectLen)
+			assert.Contains(t, context, target)
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	expected := map[string]struct {
+		status          string
+		action          string
+		deleteCalled    bool
+		deleteErr       error
+		banCalled       bool
+		banErr          error
+		resolveCalled   bool
+		resolved        bool
+		resolveErr      error
+		reopenCalled    bool
+		rejectedMessage bool
+		expectErr       error
+		expectAnyError  bool
+	}{
+		"dismissed": {
+			status:        consts.ReportStatus.Open,
+			action:        consts.ReportActions.Dismissed,
+			resolveCalled: true,
+			resolved:      true,
+		},
+		"deleted": {
+			status:        consts.ReportStatus.Open,
+			action:        consts.ReportActions.Deleted,
+			deleteCalled:  true,
+			resolveCalled: true,
+			resolved:      true,
+		},
+		"user_banned": {
+			status:        consts.ReportStatus.Open,
+			action:        consts.ReportActions.UserBanned,
+			deleteCalled:  true,
+			banCalled:     true,
+			resolveCalled: true,
+			resolved:      true,
+		},
+		"user_banned_rejected_message": {
+			status:          consts.ReportStatus.Open,
+			action:          consts.ReportActions.UserBanned,
+			rejectedMessage: true,
+			banCalled:       true,
+			resolveCalled:   true,
+			resolved:        true,
+		},
+		"already_resolved": {
+			status:    consts.ReportStatus.Resolved,
+			action:    consts.ReportActions.Dismissed,
+			expectErr: ErrReportAlreadyResolved,
+		},
+		// 他のモデレーターが先に対応した場合は処分しない
+		"resolved_by_other_moderator": {
+			status:        consts.ReportStatus.Open,
+			action:        consts.ReportActions.UserBanned,
+			resolveCalled: true,
+			resolved:      false,
+			expectErr:     ErrReportAlreadyResolved,
+		},
+		"invalid_action": {
+			status:         consts.ReportStatus.Open,
+			action:         "invalid",
+			expectAnyError: true,
+		},
+		"delete_error": {
+			status:         consts.ReportStatus.Open,
+			action:         consts.ReportActions.Deleted,
+			deleteCalled:   true,
+			deleteErr:      assert.AnError,
+			resolveCalled:  true,
+			resolved:       true,
+			reopenCalled:   true,
+			expectAnyError: true,
+		},
+		"ban_error": {
+			status:         consts.ReportStatus.Open,
+			action:         consts.ReportActions.UserBanned,
+			deleteCalled:   true,
+			banCalled:      true,
+			banErr:         assert.AnError,
+			resolveCalled:  true,
+			resolved:       true,
+			reopenCalled:   true,
+			expectAnyError: true,
+		},
+		"resolve_error": {
+			status:         consts.ReportStatus.Open,
+			action:         consts.ReportActions.Deleted,
+			resolveCalled:  true,
+			resolveErr:     assert.AnError,
+			expectAnyError: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			reportSvcMock := new(mongo_svc_mock.ReportSvcMock)
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+
+			report := model.Report{
+				ID:             primitive.NewObjectID(),
+				RoomID:         "roomId",
+				MessageID:      "messageId",
+				ReportedUserID: "reportedUuid",
+				Status:         tt.status,
+			}
+			if tt.rejectedMessage {
+				report.MessageID = ""
+			}
+
+			resolution := model.ReportResolution{
+				Action:     tt.action,
+				ResolvedBy: "moderatorUuid",
+				Note:       "note",
+				ResolvedAt: now,
+			}
+			messageSvcMock.On("DeleteMessage", "messageId", "roomId", "moderatorUuid", mock.Anything).Return(tt.deleteErr)
+			roomSvcMock.On("BanMember", "roomId", "reportedUuid", mock.Anything).Return(tt.banErr)
+			reportSvcMock.On("ResolveReport", report.ID.Hex(), resolution, mock.Anything).Return(tt.resolved, tt.resolveErr)
+			reportSvcMock.On("ReopenReport", report.ID.Hex(), resolution, mock.Anything).Return(nil)
+
+			svc := NewModerationSvc(reportSvcMock, messageSvcMock, roomSvcMock, atylabclock.NewClockMock(now))
+			err := svc.Resolve(report, tt.action, "moderatorUuid", "note", nil)
+
+			switch {
+			case tt.expectErr != nil:
+				assert.ErrorIs(t, err, tt.expectErr)
+			case tt.expectAnyError:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+			}
+
+			if tt.deleteCalled {
+				messageSvcMock.AssertCalled(t, "DeleteMessage", "messageId", "roomId", "moderatorUuid", mock.Anything)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "DeleteMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.banCalled {
+				roomSvcMock.AssertCalled(t, "BanMember", "roomId", "reportedUuid", mock.Anything)
+			} else {
+				roomSvcMock.AssertNotCalled(t, "BanMember", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.resolveCalled {
+				reportSvcMock.AssertNumberOfCalls(t, "ResolveReport", 1)
+			} else {
+				reportSvcMock.AssertNotCalled(t, "ResolveReport", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.reopenCalled {
+				reportSvcMock.AssertNumberOfCalls(t, "ReopenReport", 1)
+			} else {
+				reportSvcMock.AssertNotCalled(t, "ReopenReport", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
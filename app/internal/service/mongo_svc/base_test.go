@@ -1,6 +1,7 @@
 package mongo_svc
 
 import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/stretchr/testify/assert"
@@ -23,3 +24,38 @@ func setupInitMock(wantErr bool, mongoConnectorStruct *atylabmongo.MongoConnecto
 	mongoConnectionStructMock.On("NewMongoConnect", "chatapp", mock.Anything).Return(mongoConnectorStruct, returnErr)
 	return mongoConnectionStructMock
 }
+
+// 指定したコレクションのモックを返すMongoUseCaseを作成する
+func setupCollectionMock(collectionName string, initErr bool) (*atylabmongo.MongoCollectionStructMock, *usecase.MongoUseCaseStruct) {
+	mongoCollectionMock := new(atylabmongo.MongoCollectionStructMock)
+	mongoDatabaseMock := new(atylabmongo.MongoDatabaseStructMock)
+	mongoDatabaseMock.On("Collection", collectionName).Return(mongoCollectionMock)
+
+	mongoConnectorStruct := &atylabmongo.MongoConnector{
+		Db: mongoDatabaseMock,
+	}
+	mongoConnectionStructMock := setupInitMock(initErr, mongoConnectorStruct)
+
+	return mongoCollectionMock, usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
+}
+
+// docsを順番にDecodeするカーソルのモックを作成する
+func setupCursorMock[T any](docs []T, decodeErr error) *atylabmongo.MongoCursorStructMock {
+	mongoCursorMock := new(atylabmongo.MongoCursorStructMock)
+	for range docs {
+		mongoCursorMock.On("Next", mock.Anything).Return(true).Once()
+	}
+	mongoCursorMock.On("Next", mock.Anything).Return(false)
+
+	i := 0
+	mongoCursorMock.On("Decode", mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(0).(*T)) = docs[i]
+		i++
+	}).Return(decodeErr)
+	mongoCursorMock.On("All", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*[]T)) = docs
+	}).Return(decodeErr)
+	mongoCursorMock.On("Close", mock.Anything).Return(nil)
+
+	return mongoCursorMock
+}
@@ -0,0 +1,158 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BotAPIKeySvcInterface interface {
+	CreateAPIKey(key model.BotAPIKey, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetAPIKeys(botID string, ctx *atylabmongo.MongoCtxSvc) ([]model.BotAPIKey, error)
+	GetActiveAPIKeyByHash(keyHash string, ctx *atylabmongo.MongoCtxSvc) (model.BotAPIKey, error)
+	RevokeAPIKey(keyID string, botID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	RevokeAPIKeys(botID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type BotAPIKeySvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewBotAPIKeySvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *BotAPIKeySvcStruct {
+	return &BotAPIKeySvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *BotAPIKeySvcStruct) CreateAPIKey(key model.BotAPIKey, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotAPIKeyCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// ボットの API キーを失効させたものも含めて発行した順で返す
+func (s *BotAPIKeySvcStruct) GetAPIKeys(botID string, ctx *atylabmongo.MongoCtxSvc) ([]model.BotAPIKey, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.BotAPIKey{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotAPIKeyCollectionName)
+	cursor, err := collection.Find(ctx.Ctx, bson.M{"botid": botID})
+	if err != nil {
+		fmt.Println("Failed to find bot api keys:", err)
+		return []model.BotAPIKey{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	keys := []model.BotAPIKey{}
+	if err = cursor.All(ctx.Ctx, &keys); err != nil {
+		fmt.Println("Failed to decode bot api keys:", err)
+		return []model.BotAPIKey{}, err
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// 失効していない API キーをハッシュから探す
+func (s *BotAPIKeySvcStruct) GetActiveAPIKeyByHash(keyHash string, ctx *atylabmongo.MongoCtxSvc) (model.BotAPIKey, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.BotAPIKey{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotAPIKeyCollectionName)
+
+	var key model.BotAPIKey
+	err = collection.FindOne(ctx.Ctx, bson.M{
+		"keyHash":   keyHash,
+		"revokedAt": bson.M{"$exists": false},
+	}, &key)
+	if err != nil {
+		return model.BotAPIKey{}, err
+	}
+
+	return key, nil
+}
+
+// ボットの API キーを失効させる。該当する有効な API キーがない場合は false を返す
+func (s *BotAPIKeySvcStruct) RevokeAPIKey(keyID string, botID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotAPIKeyCollectionName)
+	keyObjectID, err := primitive.ObjectIDFromHex(keyID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":       keyObjectID,
+			"botid":     botID,
+			"revokedAt": bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{"revokedAt": now},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// ボットの有効な API キーをすべて失効させる（ボットを削除したとき）
+func (s *BotAPIKeySvcStruct) RevokeAPIKeys(botID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotAPIKeyCollectionName)
+	_, err = collection.UpdateMany(
+		ctx.Ctx,
+		bson.M{
+			"botid":     botID,
+			"revokedAt": bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{"revokedAt": now},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewBotAPIKeySvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewBotAPIKeySvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotAPIKeyCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("key-id", tt.insertErr)
+
+				svc := NewBotAPIKeySvcStruct(mongoUseCase)
+				keyID, err := svc.CreateAPIKey(model.BotAPIKey{BotID: "bot1"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateAPIKey() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "key-id", keyID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetAPIKeys(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.BotAPIKey{
+			{Name: "newer", CreatedAt: now},
+			{Name: "older", CreatedAt: now.Add(-time.Hour)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			decodeErr error
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, []string{"older", "newer"}, false},
+			{"init_error", true, nil, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, nil, true},
+			{"decode_error", false, nil, assert.AnError, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotAPIKeyCollectionName, tt.initErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{"botid": "bot1"}).Return(setupCursorMock(docs, tt.decodeErr), tt.findErr)
+
+				svc := NewBotAPIKeySvcStruct(mongoUseCase)
+				keys, err := svc.GetAPIKeys("bot1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetAPIKeys() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					assert.Empty(t, keys)
+					return
+				}
+				names := []string{}
+				for _, key := range keys {
+					names = append(names, key.Name)
+				}
+				assert.Equal(t, tt.expected, names)
+			})
+		}
+	})
+}
+
+func TestGetActiveAPIKeyByHash(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name       string
+			initErr    bool
+			findOneErr error
+			returnErr  bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"not_found", false, mongo.ErrNoDocuments, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotAPIKeyCollectionName, tt.initErr)
+				// 失効させた API キーは対象にしない
+				filter := bson.M{"keyHash": "key-hash", "revokedAt": bson.M{"$exists": false}}
+				mongoCollectionMock.On("FindOne", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+					key := args.Get(2).(*model.BotAPIKey)
+					key.BotID = "bot1"
+				}).Return(tt.findOneErr)
+
+				svc := NewBotAPIKeySvcStruct(mongoUseCase)
+				key, err := svc.GetActiveAPIKeyByHash("key-hash", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetActiveAPIKeyByHash() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.findOneErr != nil {
+					assert.ErrorIs(t, err, tt.findOneErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "bot1", key.BotID)
+				}
+			})
+		}
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		keyID := primitive.NewObjectID()
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		tests := []struct {
+			name      string
+			id        string
+			initErr   bool
+			matched   int64
+			updateErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"revoked", keyID.Hex(), false, 1, nil, true, false},
+			{"not_found_or_already_revoked", keyID.Hex(), false, 0, nil, false, false},
+			{"init_error", keyID.Hex(), true, 0, nil, false, true},
+			{"invalid_id", "invalid_object_id", false, 0, nil, false, true},
+			{"update_error", keyID.Hex(), false, 0, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotAPIKeyCollectionName, tt.initErr)
+				filter := bson.M{"_id": keyID, "botid": "bot1", "revokedAt": bson.M{"$exists": false}}
+				update := bson.M{"$set": bson.M{"revokedAt": now}}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, update).Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				svc := NewBotAPIKeySvcStruct(mongoUseCase)
+				revoked, err := svc.RevokeAPIKey(tt.id, "bot1", now, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("RevokeAPIKey() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, revoked)
+			})
+		}
+	})
+}
+
+func TestRevokeAPIKeys(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"update_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotAPIKeyCollectionName, tt.initErr)
+				filter := bson.M{"botid": "bot1", "revokedAt": bson.M{"$exists": false}}
+				update := bson.M{"$set": bson.M{"revokedAt": now}}
+				mongoCollectionMock.On("UpdateMany", mock.Anything, filter, update).Return(&mongo.UpdateResult{}, tt.updateErr)
+
+				svc := NewBotAPIKeySvcStruct(mongoUseCase)
+				err := svc.RevokeAPIKeys("bot1", now, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("RevokeAPIKeys() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
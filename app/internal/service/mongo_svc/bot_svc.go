@@ -0,0 +1,120 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type BotSvcInterface interface {
+	CreateBot(bot model.Bot, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetBots(ownerID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Bot, error)
+	GetBot(botID string, ctx *atylabmongo.MongoCtxSvc) (model.Bot, error)
+	DeleteBot(botID string, ownerID string, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+}
+
+type BotSvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewBotSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *BotSvcStruct {
+	return &BotSvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *BotSvcStruct) CreateBot(bot model.Bot, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, bot)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// ユーザーが所有するボットを作成した順で返す
+func (s *BotSvcStruct) GetBots(ownerID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Bot, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Bot{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotCollectionName)
+	cursor, err := collection.Find(ctx.Ctx, bson.M{"ownerid": ownerID})
+	if err != nil {
+		fmt.Println("Failed to find bots:", err)
+		return []model.Bot{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	bots := []model.Bot{}
+	if err = cursor.All(ctx.Ctx, &bots); err != nil {
+		fmt.Println("Failed to decode bots:", err)
+		return []model.Bot{}, err
+	}
+
+	sort.SliceStable(bots, func(i, j int) bool {
+		return bots[i].CreatedAt.Before(bots[j].CreatedAt)
+	})
+
+	return bots, nil
+}
+
+func (s *BotSvcStruct) GetBot(botID string, ctx *atylabmongo.MongoCtxSvc) (model.Bot, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Bot{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotCollectionName)
+	botObjectID, err := primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return model.Bot{}, err
+	}
+
+	var bot model.Bot
+	err = collection.FindOne(ctx.Ctx, bson.M{"_id": botObjectID}, &bot)
+	if err != nil {
+		return model.Bot{}, err
+	}
+
+	return bot, nil
+}
+
+// 所有者のボットを削除する。該当するボットがない場合は false を返す
+func (s *BotSvcStruct) DeleteBot(botID string, ownerID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.BotCollectionName)
+	botObjectID, err := primitive.ObjectIDFromHex(botID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.DeleteOne(ctx.Ctx, bson.M{"_id": botObjectID, "ownerid": ownerID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
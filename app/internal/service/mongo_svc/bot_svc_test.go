@@ -0,0 +1,177 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewBotSvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewBotSvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateBot(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("bot-id", tt.insertErr)
+
+				svc := NewBotSvcStruct(mongoUseCase)
+				botID, err := svc.CreateBot(model.Bot{OwnerID: "owner1"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateBot() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "bot-id", botID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetBots(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.Bot{
+			{Name: "newer", CreatedAt: now},
+			{Name: "older", CreatedAt: now.Add(-time.Hour)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			decodeErr error
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, []string{"older", "newer"}, false},
+			{"init_error", true, nil, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, nil, true},
+			{"decode_error", false, nil, assert.AnError, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotCollectionName, tt.initErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{"ownerid": "owner1"}).Return(setupCursorMock(docs, tt.decodeErr), tt.findErr)
+
+				svc := NewBotSvcStruct(mongoUseCase)
+				bots, err := svc.GetBots("owner1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetBots() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					assert.Empty(t, bots)
+					return
+				}
+				names := []string{}
+				for _, bot := range bots {
+					names = append(names, bot.Name)
+				}
+				assert.Equal(t, tt.expected, names)
+			})
+		}
+	})
+}
+
+func TestGetBot(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		botID := primitive.NewObjectID()
+
+		tests := []struct {
+			name       string
+			id         string
+			initErr    bool
+			findOneErr error
+			returnErr  bool
+		}{
+			{"success", botID.Hex(), false, nil, false},
+			{"init_error", botID.Hex(), true, nil, true},
+			{"invalid_id", "invalid_object_id", false, nil, true},
+			{"not_found", botID.Hex(), false, mongo.ErrNoDocuments, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotCollectionName, tt.initErr)
+				mongoCollectionMock.On("FindOne", mock.Anything, bson.M{"_id": botID}, mock.Anything).Run(func(args mock.Arguments) {
+					bot := args.Get(2).(*model.Bot)
+					bot.Name = "Deploy Bot"
+				}).Return(tt.findOneErr)
+
+				svc := NewBotSvcStruct(mongoUseCase)
+				bot, err := svc.GetBot(tt.id, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetBot() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.findOneErr != nil {
+					assert.ErrorIs(t, err, tt.findOneErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "Deploy Bot", bot.Name)
+				}
+			})
+		}
+	})
+}
+
+func TestDeleteBot(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		botID := primitive.NewObjectID()
+
+		tests := []struct {
+			name      string
+			id        string
+			initErr   bool
+			result    *mongo.DeleteResult
+			deleteErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"deleted", botID.Hex(), false, &mongo.DeleteResult{DeletedCount: 1}, nil, true, false},
+			{"not_found", botID.Hex(), false, &mongo.DeleteResult{DeletedCount: 0}, nil, false, false},
+			{"init_error", botID.Hex(), true, nil, nil, false, true},
+			{"invalid_id", "invalid_object_id", false, nil, nil, false, true},
+			{"delete_error", botID.Hex(), false, &mongo.DeleteResult{}, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.BotCollectionName, tt.initErr)
+				filter := bson.M{"_id": botID, "ownerid": "owner1"}
+				mongoCollectionMock.On("DeleteOne", mock.Anything, filter).Return(tt.result, tt.deleteErr)
+
+				svc := NewBotSvcStruct(mongoUseCase)
+				deleted, err := svc.DeleteBot(tt.id, "owner1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("DeleteBot() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, deleted)
+			})
+		}
+	})
+}
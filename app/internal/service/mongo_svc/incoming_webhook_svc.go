@@ -0,0 +1,121 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type IncomingWebhookSvcInterface interface {
+	CreateIncomingWebhook(webhook model.IncomingWebhook, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetIncomingWebhooks(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.IncomingWebhook, error)
+	GetIncomingWebhook(webhookID string, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, error)
+	DeleteIncomingWebhook(webhookID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+}
+
+type IncomingWebhookSvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewIncomingWebhookSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *IncomingWebhookSvcStruct {
+	return &IncomingWebhookSvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *IncomingWebhookSvcStruct) CreateIncomingWebhook(webhook model.IncomingWebhook, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.IncomingWebhookCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, webhook)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// ルームの受信 Webhook を作成した順で返す
+func (s *IncomingWebhookSvcStruct) GetIncomingWebhooks(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.IncomingWebhook, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.IncomingWebhook{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.IncomingWebhookCollectionName)
+	cursor, err := collection.Find(ctx.Ctx, bson.M{"roomid": roomID})
+	if err != nil {
+		fmt.Println("Failed to find incoming webhooks:", err)
+		return []model.IncomingWebhook{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	webhooks := []model.IncomingWebhook{}
+	if err = cursor.All(ctx.Ctx, &webhooks); err != nil {
+		fmt.Println("Failed to decode incoming webhooks:", err)
+		return []model.IncomingWebhook{}, err
+	}
+
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
+
+func (s *IncomingWebhookSvcStruct) GetIncomingWebhook(webhookID string, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.IncomingWebhook{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.IncomingWebhookCollectionName)
+	webhookObjectID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return model.IncomingWebhook{}, err
+	}
+
+	var webhook model.IncomingWebhook
+	err = collection.FindOne(ctx.Ctx, bson.M{"_id": webhookObjectID}, &webhook)
+	if err != nil {
+		return model.IncomingWebhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// ルームの受信 Webhook を削除する。該当する Webhook がない場合は false を返す
+// 削除した Webhook の URL には、以降は投稿できなくなる
+func (s *IncomingWebhookSvcStruct) DeleteIncomingWebhook(webhookID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.IncomingWebhookCollectionName)
+	webhookObjectID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.DeleteOne(ctx.Ctx, bson.M{"_id": webhookObjectID, "roomid": roomID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
@@ -0,0 +1,177 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewIncomingWebhookSvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewIncomingWebhookSvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateIncomingWebhook(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.IncomingWebhookCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("webhook-id", tt.insertErr)
+
+				svc := NewIncomingWebhookSvcStruct(mongoUseCase)
+				webhookID, err := svc.CreateIncomingWebhook(model.IncomingWebhook{RoomID: "room1"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateIncomingWebhook() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "webhook-id", webhookID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetIncomingWebhooks(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.IncomingWebhook{
+			{Name: "newer", CreatedAt: now},
+			{Name: "older", CreatedAt: now.Add(-time.Hour)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			decodeErr error
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, []string{"older", "newer"}, false},
+			{"init_error", true, nil, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, nil, true},
+			{"decode_error", false, nil, assert.AnError, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.IncomingWebhookCollectionName, tt.initErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{"roomid": "room1"}).Return(setupCursorMock(docs, tt.decodeErr), tt.findErr)
+
+				svc := NewIncomingWebhookSvcStruct(mongoUseCase)
+				webhooks, err := svc.GetIncomingWebhooks("room1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetIncomingWebhooks() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					assert.Empty(t, webhooks)
+					return
+				}
+				names := []string{}
+				for _, webhook := range webhooks {
+					names = append(names, webhook.Name)
+				}
+				assert.Equal(t, tt.expected, names)
+			})
+		}
+	})
+}
+
+func TestGetIncomingWebhook(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		webhookID := primitive.NewObjectID()
+
+		tests := []struct {
+			name       string
+			id         string
+			initErr    bool
+			findOneErr error
+			returnErr  bool
+		}{
+			{"success", webhookID.Hex(), false, nil, false},
+			{"init_error", webhookID.Hex(), true, nil, true},
+			{"invalid_id", "invalid_object_id", false, nil, true},
+			{"not_found", webhookID.Hex(), false, mongo.ErrNoDocuments, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.IncomingWebhookCollectionName, tt.initErr)
+				mongoCollectionMock.On("FindOne", mock.Anything, bson.M{"_id": webhookID}, mock.Anything).Run(func(args mock.Arguments) {
+					webhook := args.Get(2).(*model.IncomingWebhook)
+					webhook.Name = "CI"
+				}).Return(tt.findOneErr)
+
+				svc := NewIncomingWebhookSvcStruct(mongoUseCase)
+				webhook, err := svc.GetIncomingWebhook(tt.id, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetIncomingWebhook() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.findOneErr != nil {
+					assert.ErrorIs(t, err, tt.findOneErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "CI", webhook.Name)
+				}
+			})
+		}
+	})
+}
+
+func TestDeleteIncomingWebhook(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		webhookID := primitive.NewObjectID()
+
+		tests := []struct {
+			name      string
+			id        string
+			initErr   bool
+			result    *mongo.DeleteResult
+			deleteErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"deleted", webhookID.Hex(), false, &mongo.DeleteResult{DeletedCount: 1}, nil, true, false},
+			{"not_found", webhookID.Hex(), false, &mongo.DeleteResult{DeletedCount: 0}, nil, false, false},
+			{"init_error", webhookID.Hex(), true, nil, nil, false, true},
+			{"invalid_id", "invalid_object_id", false, nil, nil, false, true},
+			{"delete_error", webhookID.Hex(), false, &mongo.DeleteResult{}, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.IncomingWebhookCollectionName, tt.initErr)
+				filter := bson.M{"_id": webhookID, "roomid": "room1"}
+				mongoCollectionMock.On("DeleteOne", mock.Anything, filter).Return(tt.result, tt.deleteErr)
+
+				svc := NewIncomingWebhookSvcStruct(mongoUseCase)
+				deleted, err := svc.DeleteIncomingWebhook(tt.id, "room1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("DeleteIncomingWebhook() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, deleted)
+			})
+		}
+	})
+}
@@ -2,31 +2,52 @@ package mongo_svc
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type MessageSvcInterface interface {
 	SendMessage(message model.Message, ctx *atylabmongo.MongoCtxSvc) (string, error)
 	GetMessageList(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error)
+	StreamMessages(roomID string, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error
 	ReadMessages(messageIds []string, roomId string, userId string, ctx *atylabmongo.MongoCtxSvc) error
 	IsSender(messageID string, roomID string, userID string, ctx *atylabmongo.MongoCtxSvc) error
-	DeleteMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) error
+	DeleteMessage(messageID string, roomID string, deletedBy string, ctx *atylabmongo.MongoCtxSvc) error
+	GetDeletedMessages(roomID string, since time.Time, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error)
+	RestoreMessage(messageID string, roomID string, since time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	GetMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (model.Message, error)
+	GetMessagesAround(roomID string, at time.Time, window time.Duration, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error)
+	FindByClientMsgID(roomID string, sender string, clientMsgID string, ctx *atylabmongo.MongoCtxSvc) (model.Message, error)
+	SetAttachmentImage(messageID string, roomID string, attachmentID string, width int, height int, thumbnails []model.AttachmentThumbnail, ctx *atylabmongo.MongoCtxSvc) error
+	SetLinkPreviews(messageID string, roomID string, previews []model.LinkPreview, ctx *atylabmongo.MongoCtxSvc) error
+	PinMessage(messageID string, roomID string, pin model.MessagePin, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	UnpinMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	GetPinnedMessages(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error)
+	VotePoll(messageID string, roomID string, vote model.PollVote, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	ClosePoll(messageID string, roomID string, closedBy string, closedAt time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	GetExpiredMessages(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error)
+	DeleteExpiredMessage(message model.Message, ctx *atylabmongo.MongoCtxSvc) (bool, error)
 }
 
 type MessageSvcStruct struct {
-	mongo usecase.MongoUseCaseInterface
+	mongo  usecase.MongoUseCaseInterface
+	driver usecase.MongoDriverUseCaseInterface
 }
 
 func NewMessageSvcStruct(
 	mongo usecase.MongoUseCaseInterface,
+	driver usecase.MongoDriverUseCaseInterface,
 ) *MessageSvcStruct {
 	return &MessageSvcStruct{
-		mongo: mongo,
+		mongo:  mongo,
+		driver: driver,
 	}
 }
 
@@ -54,7 +75,14 @@ func (s *MessageSvcStruct) GetMessageList(roomID string, ctx *atylabmongo.MongoC
 	}
 
 	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
-	filter := bson.M{"roomid": roomID}
+	// 期限切れで削除待ちのメッセージは返さない
+	filter := bson.M{
+		"roomid": roomID,
+		"$or": []bson.M{
+			{"expiresAt": bson.M{"$exists": false}},
+			{"expiresAt": bson.M{"$gt": time.Now()}},
+		},
+	}
 
 	cursor, err := collection.Find(ctx.Ctx, filter)
 	if err != nil {
@@ -77,6 +105,56 @@ func (s *MessageSvcStruct) GetMessageList(roomID string, ctx *atylabmongo.MongoC
 	return messages, nil
 }
 
+// ルームのメッセージを投稿日時の順に1件ずつ fn に渡す（エクスポート用）
+// 件数の多いルームでも全件をメモリに載せないよう、カーソルから順に処理する
+// 取り込んだメッセージや予約投稿のように、挿入順と投稿日時が一致しないメッセージもあるため、
+// 並び順を指定できるドライバーで createdAt・_id の順に取得する
+func (s *MessageSvcStruct) StreamMessages(roomID string, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error {
+	db, err := s.driver.Database()
+	if err != nil {
+		fmt.Println("Failed to connect to MongoDB:", err)
+		return err
+	}
+
+	collection := db.Collection(model.MessageCollectionName)
+	// 期限切れで削除待ちのメッセージは含めない
+	filter := bson.M{
+		"roomid": roomID,
+		"$or": []bson.M{
+			{"expiresAt": bson.M{"$exists": false}},
+			{"expiresAt": bson.M{"$gt": time.Now()}},
+		},
+	}
+
+	cursor, err := collection.Find(
+		ctx.Ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		fmt.Println("Failed to find messages:", err)
+		return err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	for cursor.Next(ctx.Ctx) {
+		var message model.Message
+		if err := cursor.Decode(&message); err != nil {
+			fmt.Println("Failed to decode message:", err)
+			return err
+		}
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	// Nextがfalseを返した理由がタイムアウトやキャンセルの場合はエラーとして扱う
+	return ctx.Ctx.Err()
+}
+
 func (s *MessageSvcStruct) ReadMessages(messageIds []string, roomId string, userId string, ctx *atylabmongo.MongoCtxSvc) error {
 	mongo, err := s.mongo.MongoInit()
 	if err != nil {
@@ -95,13 +173,19 @@ func (s *MessageSvcStruct) ReadMessages(messageIds []string, roomId string, user
 		chatObjectIDs = append(chatObjectIDs, chatObjectID)
 	}
 
+	// 既読の日時は最初に読んだときのものを残すため、まだ読んでいないメッセージだけを更新する
 	filter := bson.M{
-		"_id":    bson.M{"$in": chatObjectIDs},
-		"roomid": roomId,
+		"_id":           bson.M{"$in": chatObjectIDs},
+		"roomid":        roomId,
+		"isReadUserIds": bson.M{"$ne": userId},
 	}
 
 	update := bson.M{
 		"$addToSet": bson.M{"isReadUserIds": userId},
+		"$push": bson.M{"readReceipts": model.ReadReceipt{
+			UserID: userId,
+			ReadAt: time.Now(),
+		}},
 	}
 
 	_, err = collection.UpdateMany(ctx.Ctx, filter, update)
@@ -141,7 +225,10 @@ func (s *MessageSvcStruct) IsSender(messageID string, roomID string, userID stri
 	return nil
 }
 
-func (s *MessageSvcStruct) DeleteMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) error {
+// 削除した日時とユーザーを記録する（ドキュメントは猶予期間が過ぎるまで残す）
+// スレッドや返信の文脈が失われないよう、一覧には本文を伏せた状態で残る
+// 削除済みのメッセージは記録を上書きしない。ピン留めは外す
+func (s *MessageSvcStruct) DeleteMessage(messageID string, roomID string, deletedBy string, ctx *atylabmongo.MongoCtxSvc) error {
 	mongo, err := s.mongo.MongoInit()
 	if err != nil {
 		fmt.Println("Failed to initialize MongoDB:", err)
@@ -154,10 +241,489 @@ func (s *MessageSvcStruct) DeleteMessage(messageID string, roomID string, ctx *a
 		return err
 	}
 
-	_, err = collection.DeleteOne(ctx.Ctx, bson.M{"_id": messageObjectID, "roomid": roomID})
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":       messageObjectID,
+			"roomid":    roomID,
+			"deletedAt": bson.M{"$exists": false},
+		},
+		bson.M{
+			"$set": bson.M{
+				"deletedAt": time.Now(),
+				"deletedBy": deletedBy,
+			},
+			"$unset": bson.M{
+				"pin": "",
+			},
+		},
+	)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// since 以降に削除されたメッセージを、新しく削除した順で返す
+func (s *MessageSvcStruct) GetDeletedMessages(roomID string, since time.Time, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Message{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	filter := bson.M{
+		"roomid":    roomID,
+		"deletedAt": bson.M{"$gte": since},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find deleted messages:", err)
+		return []model.Message{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	messages := []model.Message{}
+	if err = cursor.All(ctx.Ctx, &messages); err != nil {
+		fmt.Println("Failed to decode messages:", err)
+		return []model.Message{}, err
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].DeletedAt.After(*messages[j].DeletedAt)
+	})
+
+	return messages, nil
+}
+
+// since 以降に削除されたメッセージに限って削除を取り消す
+// 削除されていない、または猶予期間を過ぎていた場合は false を返す
+func (s *MessageSvcStruct) RestoreMessage(messageID string, roomID string, since time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":       messageObjectID,
+			"roomid":    roomID,
+			"deletedAt": bson.M{"$gte": since},
+		},
+		bson.M{"$unset": bson.M{
+			"deletedAt": "",
+			"deletedBy": "",
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+func (s *MessageSvcStruct) GetMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (model.Message, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Message{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return model.Message{}, err
+	}
+
+	var message model.Message
+	err = collection.FindOne(ctx.Ctx, bson.M{"_id": messageObjectID, "roomid": roomID}, &message)
+	if err != nil {
+		return model.Message{}, err
+	}
+
+	return message, nil
+}
+
+// 指定時刻の前後window以内に投稿されたメッセージを投稿順で返す
+func (s *MessageSvcStruct) GetMessagesAround(roomID string, at time.Time, window time.Duration, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Message{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	filter := bson.M{
+		"roomid": roomID,
+		"createdAt": bson.M{
+			"$gte": at.Add(-window),
+			"$lte": at.Add(window),
+		},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find messages:", err)
+		return []model.Message{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	var messages []model.Message
+	if err = cursor.All(ctx.Ctx, &messages); err != nil {
+		fmt.Println("Failed to decode messages:", err)
+		return []model.Message{}, err
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+
+	return messages, nil
+}
+
+// クライアントが採番したIDで送信済みのメッセージを探す（再送の判定用）
+func (s *MessageSvcStruct) FindByClientMsgID(roomID string, sender string, clientMsgID string, ctx *atylabmongo.MongoCtxSvc) (model.Message, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Message{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	filter := bson.M{
+		"roomid":      roomID,
+		"sender":      sender,
+		"clientMsgId": clientMsgID,
+	}
+
+	var message model.Message
+	err = collection.FindOne(ctx.Ctx, filter, &message)
+	if err != nil {
+		return model.Message{}, err
+	}
+
+	return message, nil
+}
+
+// 添付画像のサイズと生成したサムネイルを記録する
+func (s *MessageSvcStruct) SetAttachmentImage(messageID string, roomID string, attachmentID string, width int, height int, thumbnails []model.AttachmentThumbnail, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":            messageObjectID,
+			"roomid":         roomID,
+			"attachments.id": attachmentID,
+		},
+		bson.M{"$set": bson.M{
+			"attachments.$.width":      width,
+			"attachments.$.height":     height,
+			"attachments.$.thumbnails": thumbnails,
+		}},
+	)
+	return err
+}
+
+// メッセージ中のURLから取得したプレビューを記録する
+func (s *MessageSvcStruct) SetLinkPreviews(messageID string, roomID string, previews []model.LinkPreview, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return err
+	}
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":    messageObjectID,
+			"roomid": roomID,
+		},
+		bson.M{"$set": bson.M{
+			"linkPreviews": previews,
+		}},
+	)
+	return err
+}
+
+// ピン留めされていないメッセージに限ってピン留めする
+// 既にピン留めされている（他のモデレーターに先を越された）場合は false を返す
+func (s *MessageSvcStruct) PinMessage(messageID string, roomID string, pin model.MessagePin, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":    messageObjectID,
+			"roomid": roomID,
+			"pin":    bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{
+			"pin": pin,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// ピン留めを外す。ピン留めされていなかった場合は false を返す
+func (s *MessageSvcStruct) UnpinMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":    messageObjectID,
+			"roomid": roomID,
+			"pin":    bson.M{"$exists": true},
+		},
+		bson.M{"$unset": bson.M{
+			"pin": "",
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// 受付中の投票に投票する。すでに投票している場合は投票を置き換える
+// 同じユーザーが同時に投票しても1件になるよう、置き換えと追加をそれぞれ条件付きの1回の更新で行う
+// 投票が見つからないか、締め切られている場合は false を返す
+func (s *MessageSvcStruct) VotePoll(messageID string, roomID string, vote model.PollVote, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return false, err
+	}
+
+	open := func(voted any) bson.M {
+		return bson.M{
+			"_id":           messageObjectID,
+			"roomid":        roomID,
+			"deletedAt":     bson.M{"$exists": false},
+			"poll":          bson.M{"$exists": true},
+			"poll.closedAt": bson.M{"$exists": false},
+			"$or": bson.A{
+				bson.M{"poll.closesAt": bson.M{"$exists": false}},
+				bson.M{"poll.closesAt": bson.M{"$gt": vote.VotedAt}},
+			},
+			"poll.votes.userId": voted,
+		}
+	}
+
+	// 置き換えと追加の間に同じユーザーの投票が追加された場合は、もう一度置き換える
+	for range 2 {
+		result, err := collection.UpdateOne(
+			ctx.Ctx,
+			open(vote.UserID),
+			bson.M{"$set": bson.M{
+				"poll.votes.$": vote,
+			}},
+		)
+		if err != nil {
+			return false, err
+		}
+		if result.MatchedCount == 1 {
+			return true, nil
+		}
+
+		result, err = collection.UpdateOne(
+			ctx.Ctx,
+			open(bson.M{"$ne": vote.UserID}),
+			bson.M{"$push": bson.M{
+				"poll.votes": vote,
+			}},
+		)
+		if err != nil {
+			return false, err
+		}
+		if result.MatchedCount == 1 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// 受付中の投票を締め切る。投票が見つからないか、すでに締め切られている場合は false を返す
+func (s *MessageSvcStruct) ClosePoll(messageID string, roomID string, closedBy string, closedAt time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	messageObjectID, err := primitive.ObjectIDFromHex(messageID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":           messageObjectID,
+			"roomid":        roomID,
+			"poll":          bson.M{"$exists": true},
+			"poll.closedAt": bson.M{"$exists": false},
+		},
+		bson.M{"$set": bson.M{
+			"poll.closedAt": closedAt,
+			"poll.closedBy": closedBy,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+// ルームのピン留めされたメッセージを、新しくピン留めした順で返す
+func (s *MessageSvcStruct) GetPinnedMessages(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Message{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	filter := bson.M{
+		"roomid": roomID,
+		"pin":    bson.M{"$exists": true},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find pinned messages:", err)
+		return []model.Message{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	messages := []model.Message{}
+	if err = cursor.All(ctx.Ctx, &messages); err != nil {
+		fmt.Println("Failed to decode messages:", err)
+		return []model.Message{}, err
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Pin.PinnedAt.After(messages[j].Pin.PinnedAt)
+	})
+
+	return messages, nil
+}
+
+// 有効期限を過ぎたメッセージを、期限の古い順に limit 件まで返す
+func (s *MessageSvcStruct) GetExpiredMessages(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Message{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	filter := bson.M{
+		"expiresAt": bson.M{"$lte": now},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find expired messages:", err)
+		return []model.Message{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	messages := []model.Message{}
+	if err = cursor.All(ctx.Ctx, &messages); err != nil {
+		fmt.Println("Failed to decode messages:", err)
+		return []model.Message{}, err
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].ExpiresAt.Before(*messages[j].ExpiresAt)
+	})
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return messages, nil
+}
+
+// 期限切れのメッセージを削除する
+// 他のインスタンスや TTL インデックスが先に削除していた場合は false を返す
+func (s *MessageSvcStruct) DeleteExpiredMessage(message model.Message, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.MessageCollectionName)
+	result, err := collection.DeleteOne(ctx.Ctx, bson.M{
+		"_id":       message.ID,
+		"roomid":    message.RoomID,
+		"expiresAt": message.ExpiresAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
@@ -2,20 +2,24 @@ package mongo_svc
 
 import (
 	"testing"
+	"time"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 )
 
 func TestNewMessageSvcStruct(t *testing.T) {
 	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
-	svc := NewMessageSvcStruct(atylabMongo)
+	svc := NewMessageSvcStruct(atylabMongo, nil)
 	if svc == nil {
 		t.Error("expected non-nil MessageSvcStruct")
 		return
@@ -52,7 +56,7 @@ func TestSendMessage(t *testing.T) {
 
 				mongoConnectionStructMock := setupInitMock(tt.initErr, mongoConnectorStruct)
 				mongoUseCase := usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
-				messageSvc := NewMessageSvcStruct(mongoUseCase)
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
 
 				message := model.Message{
 					RoomID:  "room1",
@@ -104,7 +108,11 @@ func TestGetMessageList(t *testing.T) {
 				}
 				mongoCursorMock.On("Close", mock.Anything).Return(nil)
 
-				filter := bson.M{"roomid": "room1"}
+				// 期限切れのメッセージを除く条件は取得時刻で変わるので、条件の形だけを確認する
+				filter := mock.MatchedBy(func(filter bson.M) bool {
+					or, ok := filter["$or"].([]bson.M)
+					return filter["roomid"] == "room1" && ok && len(or) == 2
+				})
 				if tt.findOneErr {
 					mongoCollectionMock.On("Find", mock.Anything, filter).Return(mongoCursorMock, assert.AnError)
 				} else {
@@ -123,7 +131,7 @@ func TestGetMessageList(t *testing.T) {
 
 				mongoConnectionStructMock := setupInitMock(tt.initErr, mongoConnectorStruct)
 				mongoUseCase := usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
-				messageSvc := NewMessageSvcStruct(mongoUseCase)
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
 
 				messages, err := messageSvc.GetMessageList("room1", atylabmongo.NewMongoCtxSvc())
 				if (err != nil) != tt.returnErr {
@@ -145,6 +153,80 @@ func TestGetMessageList(t *testing.T) {
 	})
 }
 
+func TestStreamMessages(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "chatapp.messages"
+
+	messageDoc := func(text string) bson.D {
+		return bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "roomid", Value: "room1"}, {Key: "message", Value: text}}
+	}
+	stream := func(mt *mtest.T, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) ([]string, error) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(mt.DB, nil)
+
+		var got []string
+		err := NewMessageSvcStruct(nil, driver).StreamMessages("room1", ctx, func(message model.Message) error {
+			got = append(got, message.Message)
+			return fn(message)
+		})
+		return got, err
+	}
+	noop := func(model.Message) error { return nil }
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, messageDoc("first")),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch, messageDoc("second")),
+		)
+
+		got, err := stream(mt, atylabmongo.NewMongoCtxSvc(), noop)
+		assert.NoError(mt, err)
+		assert.Equal(mt, []string{"first", "second"}, got)
+
+		// 挿入順ではなく投稿日時の順に並べ、同時刻のものは _id で順序を決める
+		find := mt.GetStartedEvent()
+		assert.Equal(mt, "find", find.CommandName)
+		assert.Equal(mt, "room1", find.Command.Lookup("filter", "roomid").StringValue())
+		sort := find.Command.Lookup("sort").Document()
+		keys, err := sort.Elements()
+		assert.NoError(mt, err)
+		if assert.Len(mt, keys, 2) {
+			assert.Equal(mt, "createdAt", keys[0].Key())
+			assert.Equal(mt, "_id", keys[1].Key())
+		}
+	})
+
+	mt.Run("find error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}))
+		_, err := stream(mt, atylabmongo.NewMongoCtxSvc(), noop)
+		assert.Error(mt, err)
+	})
+
+	mt.Run("callback error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, messageDoc("first"), messageDoc("second")))
+		got, err := stream(mt, atylabmongo.NewMongoCtxSvc(), func(model.Message) error { return assert.AnError })
+		assert.ErrorIs(mt, err, assert.AnError)
+		assert.Equal(mt, []string{"first"}, got)
+	})
+
+	mt.Run("context canceled", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, messageDoc("first")))
+		ctx := atylabmongo.NewMongoCtxSvc()
+		_, err := stream(mt, ctx, func(model.Message) error {
+			ctx.Cancel()
+			return nil
+		})
+		assert.Error(mt, err)
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(nil, assert.AnError)
+		err := NewMessageSvcStruct(nil, driver).StreamMessages("room1", atylabmongo.NewMongoCtxSvc(), noop)
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
 func TestReadMessages(t *testing.T) {
 	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
 		tests := []struct {
@@ -170,7 +252,15 @@ func TestReadMessages(t *testing.T) {
 					if tt.updateManyErr {
 						mongoCollectionMock.On("UpdateMany", mock.Anything, mock.Anything, mock.Anything).Return(&mongo.UpdateResult{}, assert.AnError)
 					} else {
-						mongoCollectionMock.On("UpdateMany", mock.Anything, mock.Anything, mock.Anything).Return(&mongo.UpdateResult{}, nil)
+						// 未読のメッセージだけを対象にし、既読の日時を記録する
+						filter := mock.MatchedBy(func(filter bson.M) bool {
+							return assert.ObjectsAreEqual(bson.M{"$ne": "1"}, filter["isReadUserIds"])
+						})
+						update := mock.MatchedBy(func(update bson.M) bool {
+							receipt, ok := update["$push"].(bson.M)["readReceipts"].(model.ReadReceipt)
+							return ok && receipt.UserID == "1" && !receipt.ReadAt.IsZero()
+						})
+						mongoCollectionMock.On("UpdateMany", mock.Anything, filter, update).Return(&mongo.UpdateResult{}, nil)
 					}
 				}
 
@@ -182,7 +272,7 @@ func TestReadMessages(t *testing.T) {
 
 				mongoConnectionStructMock := setupInitMock(tt.initErr, mongoConnectorStruct)
 				mongoUseCase := usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
-				messageSvc := NewMessageSvcStruct(mongoUseCase)
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
 
 				messageIds := []string{"60c72b2f9b1d4c3d88f0e6b1", tt.id}
 				roomId := "room1"
@@ -238,7 +328,7 @@ func TestIsSender(t *testing.T) {
 
 				mongoConnectionStructMock := setupInitMock(tt.initErr, mongoConnectorStruct)
 				mongoUseCase := usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
-				messageSvc := NewMessageSvcStruct(mongoUseCase)
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
 
 				err := messageSvc.IsSender(tt.messageID, tt.roomID, tt.userID, atylabmongo.NewMongoCtxSvc())
 				if (err != nil) != tt.returnErr {
@@ -260,44 +350,648 @@ func TestDeleteMessage(t *testing.T) {
 			messageID    string
 			roomID       string
 			initErr      bool
-			deleteOneErr bool
+			updateOneErr bool
 			returnErr    bool
 		}{
 			{"success", "60c72b2f9b1d4c3d88f0e6b1", "room1", false, false, false},
 			{"ObjectIDFromHex_error", "invalid_id", "room1", false, false, true},
 			{"initErr", "60c72b2f9b1d4c3d88f0e6b1", "room1", true, false, true},
-			{"deleteone_error", "60c72b2f9b1d4c3d88f0e6b1", "room1", false, true, true},
+			{"updateone_error", "60c72b2f9b1d4c3d88f0e6b1", "room1", false, true, true},
 		}
 
 		for _, tt := range tests {
 			t.Run(tt.name, func(t *testing.T) {
-				mongoCollectionMock := new(atylabmongo.MongoCollectionStructMock)
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				// 物理削除はせず、削除済みでないメッセージに削除の記録を付ける
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+					return filter["roomid"] == tt.roomID && assert.ObjectsAreEqual(bson.M{"$exists": false}, filter["deletedAt"])
+				}), mock.MatchedBy(func(update bson.M) bool {
+					set, ok := update["$set"].(bson.M)
+					if !ok {
+						return false
+					}
+					_, hasDeletedAt := set["deletedAt"].(time.Time)
+					return hasDeletedAt && set["deletedBy"] == "user1" &&
+						assert.ObjectsAreEqual(bson.M{"pin": ""}, update["$unset"])
+				})).Return(&mongo.UpdateResult{MatchedCount: 1}, updateErr)
 
-				if tt.deleteOneErr {
-					mongoCollectionMock.On("DeleteOne", mock.Anything, mock.Anything).Return(&mongo.DeleteResult{}, assert.AnError)
-				} else {
-					mongoCollectionMock.On("DeleteOne", mock.Anything, mock.Anything).Return(&mongo.DeleteResult{}, nil)
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				err := messageSvc.DeleteMessage(tt.messageID, tt.roomID, "user1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("DeleteMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
 				}
+				mongoCollectionMock.AssertNotCalled(t, "DeleteOne", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
 
-				mongoDatabaseMock := new(atylabmongo.MongoDatabaseStructMock)
-				mongoDatabaseMock.On("Collection", "messages").Return(mongoCollectionMock)
+func TestGetDeletedMessages(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		since := time.Now().Add(-time.Hour)
+		older := since.Add(time.Minute)
+		newer := since.Add(time.Hour)
+		docs := []model.Message{
+			{Message: "older", DeletedAt: &older},
+			{Message: "newer", DeletedAt: &newer},
+		}
 
-				mongoConnectorStruct := &atylabmongo.MongoConnector{
-					Db: mongoDatabaseMock,
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			allErr    error
+			returnErr bool
+		}{
+			{"success", false, nil, nil, false},
+			{"init_error", true, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, true},
+			{"all_error", false, nil, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				filter := bson.M{
+					"roomid":    "room1",
+					"deletedAt": bson.M{"$gte": since},
 				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.allErr), tt.findErr)
 
-				mongoConnectionStructMock := setupInitMock(tt.initErr, mongoConnectorStruct)
-				mongoUseCase := usecase.NewMongoUseCaseStruct(mongoConnectionStructMock, usecase.NewMongo())
-				messageSvc := NewMessageSvcStruct(mongoUseCase)
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				messages, err := messageSvc.GetDeletedMessages("room1", since, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetDeletedMessages() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Len(t, messages, 2)
+				assert.Equal(t, "newer", messages[0].Message)
+				assert.Equal(t, "older", messages[1].Message)
+			})
+		}
+	})
+}
+
+func TestRestoreMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		since := time.Now().Add(-time.Hour)
+
+		tests := []struct {
+			name      string
+			messageID string
+			initErr   bool
+			matched   int64
+			updateErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, 1, nil, true, false},
+			{"not_deleted_or_expired", "60c72b2f9b1d4c3d88f0e6b1", false, 0, nil, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, 0, nil, false, true},
+			{"invalid_id", "invalid_id", false, 0, nil, false, true},
+			{"update_error", "60c72b2f9b1d4c3d88f0e6b1", false, 0, assert.AnError, false, true},
+		}
 
-				err := messageSvc.DeleteMessage(tt.messageID, tt.roomID, atylabmongo.NewMongoCtxSvc())
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+					return filter["roomid"] == "room1" && assert.ObjectsAreEqual(bson.M{"$gte": since}, filter["deletedAt"])
+				}), bson.M{"$unset": bson.M{
+					"deletedAt": "",
+					"deletedBy": "",
+				}}).Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				restored, err := messageSvc.RestoreMessage(tt.messageID, "room1", since, atylabmongo.NewMongoCtxSvc())
 				if (err != nil) != tt.returnErr {
-					t.Errorf("DeleteMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+					t.Errorf("RestoreMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
 				}
+				assert.Equal(t, tt.expected, restored)
+			})
+		}
+	})
+}
 
-				if m, ok := mongoConnectionStructMock.(interface{ AssertExpectations(*testing.T) }); ok {
-					m.AssertExpectations(t)
+func TestGetMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name       string
+			messageID  string
+			initErr    bool
+			findOneErr bool
+			returnErr  bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, false, true},
+			{"invalid_id", "invalid_id", false, false, true},
+			{"findone_error", "60c72b2f9b1d4c3d88f0e6b1", false, true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				var findErr error
+				if tt.findOneErr {
+					findErr = assert.AnError
+				}
+				mongoCollectionMock.On("FindOne", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					message := args.Get(2).(*model.Message)
+					message.Message = "hello"
+				}).Return(findErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				message, err := messageSvc.GetMessage(tt.messageID, "room1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "hello", message.Message)
+				}
+			})
+		}
+	})
+}
+
+func TestGetMessagesAround(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.Message{
+			{Message: "second", CreatedAt: now},
+			{Message: "first", CreatedAt: now.Add(-time.Minute)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   bool
+			allErr    bool
+			returnErr bool
+		}{
+			{"success", false, false, false, false},
+			{"init_error", true, false, false, true},
+			{"find_error", false, true, false, true},
+			{"all_error", false, false, true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				var allErr, findErr error
+				if tt.allErr {
+					allErr = assert.AnError
+				}
+				if tt.findErr {
+					findErr = assert.AnError
+				}
+				filter := bson.M{
+					"roomid": "room1",
+					"createdAt": bson.M{
+						"$gte": now.Add(-time.Hour),
+						"$lte": now.Add(time.Hour),
+					},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, allErr), findErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				messages, err := messageSvc.GetMessagesAround("room1", now, time.Hour, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetMessagesAround() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Len(t, messages, 2)
+				assert.Equal(t, "first", messages[0].Message)
+				assert.Equal(t, "second", messages[1].Message)
+			})
+		}
+	})
+}
+
+func TestFindByClientMsgID(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name       string
+			initErr    bool
+			findOneErr error
+			returnErr  bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"not_found", false, mongo.ErrNoDocuments, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				filter := bson.M{"roomid": "room1", "sender": "uuid", "clientMsgId": "client-1"}
+				mongoCollectionMock.On("FindOne", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+					message := args.Get(2).(*model.Message)
+					message.ClientMsgID = "client-1"
+				}).Return(tt.findOneErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				message, err := messageSvc.FindByClientMsgID("room1", "uuid", "client-1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("FindByClientMsgID() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.findOneErr != nil {
+					assert.ErrorIs(t, err, tt.findOneErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "client-1", message.ClientMsgID)
+				}
+			})
+		}
+	})
+}
+
+func TestSetAttachmentImage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		thumbnails := []model.AttachmentThumbnail{{Size: "small", Width: 160, Height: 120}}
+
+		tests := []struct {
+			name      string
+			messageID string
+			initErr   bool
+			updateErr error
+			returnErr bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, nil, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, nil, true},
+			{"invalid_id", "invalid_id", false, nil, true},
+			{"update_error", "60c72b2f9b1d4c3d88f0e6b1", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+					return filter["roomid"] == "room1" && filter["attachments.id"] == "attachment1"
+				}), bson.M{"$set": bson.M{
+					"attachments.$.width":      640,
+					"attachments.$.height":     480,
+					"attachments.$.thumbnails": thumbnails,
+				}}).Return(&mongo.UpdateResult{MatchedCount: 1}, tt.updateErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				err := messageSvc.SetAttachmentImage(tt.messageID, "room1", "attachment1", 640, 480, thumbnails, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("SetAttachmentImage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
+
+func TestSetLinkPreviews(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		previews := []model.LinkPreview{{URL: "https://example.com", Title: "Example"}}
+
+		tests := []struct {
+			name      string
+			messageID string
+			initErr   bool
+			updateErr error
+			returnErr bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, nil, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, nil, true},
+			{"invalid_id", "invalid_id", false, nil, true},
+			{"update_error", "60c72b2f9b1d4c3d88f0e6b1", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+					return filter["roomid"] == "room1"
+				}), bson.M{"$set": bson.M{
+					"linkPreviews": previews,
+				}}).Return(&mongo.UpdateResult{MatchedCount: 1}, tt.updateErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				err := messageSvc.SetLinkPreviews(tt.messageID, "room1", previews, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("SetLinkPreviews() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
+
+func TestPinMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		pin := model.MessagePin{PinnedBy: "moderator", PinnedAt: time.Now()}
+
+		tests := []struct {
+			name      string
+			messageID string
+			initErr   bool
+			matched   int64
+			updateErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, 1, nil, true, false},
+			{"already_pinned", "60c72b2f9b1d4c3d88f0e6b1", false, 0, nil, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, 0, nil, false, true},
+			{"invalid_id", "invalid_id", false, 0, nil, false, true},
+			{"update_error", "60c72b2f9b1d4c3d88f0e6b1", false, 0, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+					return filter["roomid"] == "room1" && assert.ObjectsAreEqual(bson.M{"$exists": false}, filter["pin"])
+				}), bson.M{"$set": bson.M{
+					"pin": pin,
+				}}).Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				pinned, err := messageSvc.PinMessage(tt.messageID, "room1", pin, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("PinMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, pinned)
+			})
+		}
+	})
+}
+
+func TestUnpinMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			messageID string
+			initErr   bool
+			matched   int64
+			updateErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, 1, nil, true, false},
+			{"not_pinned", "60c72b2f9b1d4c3d88f0e6b1", false, 0, nil, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, 0, nil, false, true},
+			{"invalid_id", "invalid_id", false, 0, nil, false, true},
+			{"update_error", "60c72b2f9b1d4c3d88f0e6b1", false, 0, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+					return filter["roomid"] == "room1" && assert.ObjectsAreEqual(bson.M{"$exists": true}, filter["pin"])
+				}), bson.M{"$unset": bson.M{
+					"pin": "",
+				}}).Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				unpinned, err := messageSvc.UnpinMessage(tt.messageID, "room1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("UnpinMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, unpinned)
+			})
+		}
+	})
+}
+
+func TestVotePoll(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		vote := model.PollVote{UserID: "user1", Options: []int{1}, VotedAt: time.Now()}
+
+		tests := []struct {
+			name      string
+			messageID string
+			initErr   bool
+			// 置き換え・追加の更新が順に一致した件数
+			replaced  []int64
+			pushed    []int64
+			updateErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"replace existing vote", "60c72b2f9b1d4c3d88f0e6b1", false, []int64{1}, nil, nil, true, false},
+			{"add new vote", "60c72b2f9b1d4c3d88f0e6b1", false, []int64{0}, []int64{1}, nil, true, false},
+			{"voted concurrently", "60c72b2f9b1d4c3d88f0e6b1", false, []int64{0, 1}, []int64{0}, nil, true, false},
+			{"closed or not found", "60c72b2f9b1d4c3d88f0e6b1", false, []int64{0, 0}, []int64{0, 0}, nil, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, nil, nil, nil, false, true},
+			{"invalid_id", "invalid_id", false, nil, nil, nil, false, true},
+			{"update_error", "60c72b2f9b1d4c3d88f0e6b1", false, []int64{0}, nil, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				isOpen := func(filter bson.M) bool {
+					return filter["roomid"] == "room1" &&
+						assert.ObjectsAreEqual(bson.M{"$exists": false}, filter["poll.closedAt"]) &&
+						filter["$or"] != nil
+				}
+				for _, matched := range tt.replaced {
+					mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+						return isOpen(filter) && filter["poll.votes.userId"] == "user1"
+					}), bson.M{"$set": bson.M{"poll.votes.$": vote}}).Return(&mongo.UpdateResult{MatchedCount: matched}, tt.updateErr).Once()
+				}
+				for _, matched := range tt.pushed {
+					mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+						return isOpen(filter) && assert.ObjectsAreEqual(bson.M{"$ne": "user1"}, filter["poll.votes.userId"])
+					}), bson.M{"$push": bson.M{"poll.votes": vote}}).Return(&mongo.UpdateResult{MatchedCount: matched}, nil).Once()
+				}
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				voted, err := messageSvc.VotePoll(tt.messageID, "room1", vote, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("VotePoll() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, voted)
+				mongoCollectionMock.AssertNumberOfCalls(t, "UpdateOne", len(tt.replaced)+len(tt.pushed))
+			})
+		}
+	})
+}
+
+func TestClosePoll(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		closedAt := time.Now()
+
+		tests := []struct {
+			name      string
+			messageID string
+			initErr   bool
+			matched   int64
+			updateErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, 1, nil, true, false},
+			{"already_closed", "60c72b2f9b1d4c3d88f0e6b1", false, 0, nil, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, 0, nil, false, true},
+			{"invalid_id", "invalid_id", false, 0, nil, false, true},
+			{"update_error", "60c72b2f9b1d4c3d88f0e6b1", false, 0, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.MatchedBy(func(filter bson.M) bool {
+					return filter["roomid"] == "room1" && assert.ObjectsAreEqual(bson.M{"$exists": false}, filter["poll.closedAt"])
+				}), bson.M{"$set": bson.M{
+					"poll.closedAt": closedAt,
+					"poll.closedBy": "user1",
+				}}).Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				closed, err := messageSvc.ClosePoll(tt.messageID, "room1", "user1", closedAt, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("ClosePoll() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, closed)
+			})
+		}
+	})
+}
+
+func TestGetPinnedMessages(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.Message{
+			{Message: "older", Pin: &model.MessagePin{PinnedBy: "moderator", PinnedAt: now.Add(-time.Hour)}},
+			{Message: "newer", Pin: &model.MessagePin{PinnedBy: "moderator", PinnedAt: now}},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			allErr    error
+			returnErr bool
+		}{
+			{"success", false, nil, nil, false},
+			{"init_error", true, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, true},
+			{"all_error", false, nil, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				filter := bson.M{
+					"roomid": "room1",
+					"pin":    bson.M{"$exists": true},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.allErr), tt.findErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				messages, err := messageSvc.GetPinnedMessages("room1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetPinnedMessages() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Len(t, messages, 2)
+				assert.Equal(t, "newer", messages[0].Message)
+				assert.Equal(t, "older", messages[1].Message)
+			})
+		}
+	})
+}
+
+func TestGetExpiredMessages(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		older := now.Add(-time.Hour)
+		newer := now.Add(-time.Minute)
+		docs := []model.Message{
+			{Message: "newer", ExpiresAt: &newer},
+			{Message: "older", ExpiresAt: &older},
+		}
+
+		tests := []struct {
+			name      string
+			limit     int
+			initErr   bool
+			findErr   error
+			allErr    error
+			expected  []string
+			returnErr bool
+		}{
+			{"success", 10, false, nil, nil, []string{"older", "newer"}, false},
+			{"limited", 1, false, nil, nil, []string{"older"}, false},
+			{"init_error", 10, true, nil, nil, nil, true},
+			{"find_error", 10, false, assert.AnError, nil, nil, true},
+			{"all_error", 10, false, nil, assert.AnError, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				filter := bson.M{"expiresAt": bson.M{"$lte": now}}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.allErr), tt.findErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				messages, err := messageSvc.GetExpiredMessages(now, tt.limit, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetExpiredMessages() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				actual := []string{}
+				for _, message := range messages {
+					actual = append(actual, message.Message)
+				}
+				assert.Equal(t, tt.expected, actual)
+			})
+		}
+	})
+}
+
+func TestDeleteExpiredMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		expiresAt := time.Now()
+		message := model.Message{ID: primitive.NewObjectID(), RoomID: "room1", ExpiresAt: &expiresAt}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			result    *mongo.DeleteResult
+			deleteErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"deleted", false, &mongo.DeleteResult{DeletedCount: 1}, nil, true, false},
+			{"already deleted", false, &mongo.DeleteResult{DeletedCount: 0}, nil, false, false},
+			{"init_error", true, nil, nil, false, true},
+			{"delete_error", false, &mongo.DeleteResult{}, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.MessageCollectionName, tt.initErr)
+				filter := bson.M{
+					"_id":       message.ID,
+					"roomid":    "room1",
+					"expiresAt": &expiresAt,
+				}
+				mongoCollectionMock.On("DeleteOne", mock.Anything, filter).Return(tt.result, tt.deleteErr)
+
+				messageSvc := NewMessageSvcStruct(mongoUseCase, nil)
+				deleted, err := messageSvc.DeleteExpiredMessage(message, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("DeleteExpiredMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
 				}
+				assert.Equal(t, tt.expected, deleted)
 			})
 		}
 	})
@@ -0,0 +1,295 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReminderSvcInterface interface {
+	CreateReminder(reminder model.Reminder, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetReminders(userID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Reminder, error)
+	GetReminder(reminderID string, userID string, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error)
+	SnoozeReminder(reminderID string, userID string, remindAt time.Time, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	CancelReminder(reminderID string, userID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	GetDueReminders(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.Reminder, error)
+	LeaseReminder(reminder model.Reminder, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	CompleteReminder(reminder model.Reminder, notificationID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error
+	RetryReminder(reminder model.Reminder, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error
+	FailReminder(reminder model.Reminder, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type ReminderSvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewReminderSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *ReminderSvcStruct {
+	return &ReminderSvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *ReminderSvcStruct) CreateReminder(reminder model.Reminder, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReminderCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, reminder)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// 通知前のリマインダーを通知日時の早い順で返す
+func (s *ReminderSvcStruct) GetReminders(userID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Reminder, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Reminder{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReminderCollectionName)
+	filter := bson.M{
+		"userid": userID,
+		"status": bson.M{"$in": []string{
+			consts.ReminderStatus.Pending,
+			consts.ReminderStatus.Sending,
+		}},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find reminders:", err)
+		return []model.Reminder{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	reminders := []model.Reminder{}
+	if err = cursor.All(ctx.Ctx, &reminders); err != nil {
+		fmt.Println("Failed to decode reminders:", err)
+		return []model.Reminder{}, err
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].RemindAt.Before(reminders[j].RemindAt)
+	})
+
+	return reminders, nil
+}
+
+func (s *ReminderSvcStruct) GetReminder(reminderID string, userID string, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Reminder{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReminderCollectionName)
+	reminderObjectID, err := primitive.ObjectIDFromHex(reminderID)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+
+	var reminder model.Reminder
+	err = collection.FindOne(ctx.Ctx, bson.M{"_id": reminderObjectID, "userid": userID}, &reminder)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+
+	return reminder, nil
+}
+
+// 通知前・通知済み・通知に失敗したリマインダーを、指定した日時に通知し直すよう戻す
+// 通知処理中、または取り消し済みの場合は false を返す
+func (s *ReminderSvcStruct) SnoozeReminder(reminderID string, userID string, remindAt time.Time, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	return s.updateReminder(reminderID, userID, []string{
+		consts.ReminderStatus.Pending,
+		consts.ReminderStatus.Sent,
+		consts.ReminderStatus.Failed,
+	}, bson.M{
+		"$set": bson.M{
+			"status":      consts.ReminderStatus.Pending,
+			"remindAt":    remindAt,
+			"availableAt": remindAt,
+			"attempts":    0,
+			"updatedAt":   now,
+		},
+		"$unset": bson.M{"lastError": ""},
+	}, ctx)
+}
+
+// 通知前のリマインダーに限って取り消す
+func (s *ReminderSvcStruct) CancelReminder(reminderID string, userID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	return s.updateReminder(reminderID, userID, []string{
+		consts.ReminderStatus.Pending,
+	}, bson.M{
+		"$set": bson.M{
+			"status":    consts.ReminderStatus.Canceled,
+			"updatedAt": now,
+		},
+	}, ctx)
+}
+
+func (s *ReminderSvcStruct) updateReminder(reminderID string, userID string, statuses []string, update bson.M, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReminderCollectionName)
+	reminderObjectID, err := primitive.ObjectIDFromHex(reminderID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":    reminderObjectID,
+			"userid": userID,
+			"status": bson.M{"$in": statuses},
+		},
+		update,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// 通知日時を過ぎたリマインダーと、リースが切れた（通知中にインスタンスが停止した）ものを古い順に返す
+func (s *ReminderSvcStruct) GetDueReminders(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.Reminder, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Reminder{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReminderCollectionName)
+	filter := bson.M{
+		"status": bson.M{"$in": []string{
+			consts.ReminderStatus.Pending,
+			consts.ReminderStatus.Sending,
+		}},
+		"availableAt": bson.M{"$lte": now},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find reminders:", err)
+		return []model.Reminder{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	var reminders []model.Reminder
+	if err = cursor.All(ctx.Ctx, &reminders); err != nil {
+		fmt.Println("Failed to decode reminders:", err)
+		return []model.Reminder{}, err
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].AvailableAt.Before(reminders[j].AvailableAt)
+	})
+	if len(reminders) > limit {
+		reminders = reminders[:limit]
+	}
+
+	return reminders, nil
+}
+
+// 取得時点から状態が変わっていない場合のみ通知処理用に確保する
+// 他のインスタンスが先に確保した、またはスヌーズ・取り消しされた場合は false を返す
+func (s *ReminderSvcStruct) LeaseReminder(reminder model.Reminder, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReminderCollectionName)
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":         reminder.ID,
+			"status":      reminder.Status,
+			"attempts":    reminder.Attempts,
+			"availableAt": reminder.AvailableAt,
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":      consts.ReminderStatus.Sending,
+				"availableAt": now.Add(lease),
+				"updatedAt":   now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (s *ReminderSvcStruct) CompleteReminder(reminder model.Reminder, notificationID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishReminder(reminder, bson.M{
+		"status":         consts.ReminderStatus.Sent,
+		"notificationid": notificationID,
+		"updatedAt":      now,
+	}, ctx)
+}
+
+func (s *ReminderSvcStruct) RetryReminder(reminder model.Reminder, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishReminder(reminder, bson.M{
+		"status":      consts.ReminderStatus.Pending,
+		"availableAt": retryAt,
+		"lastError":   lastError,
+		"updatedAt":   now,
+	}, ctx)
+}
+
+func (s *ReminderSvcStruct) FailReminder(reminder model.Reminder, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishReminder(reminder, bson.M{
+		"status":    consts.ReminderStatus.Failed,
+		"lastError": lastError,
+		"updatedAt": now,
+	}, ctx)
+}
+
+// 通知処理中のリマインダーの状態を更新する
+// リースが切れて他のインスタンスに再確保されている場合は、そちらの結果を優先して何もしない
+func (s *ReminderSvcStruct) finishReminder(reminder model.Reminder, set bson.M, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReminderCollectionName)
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":      reminder.ID,
+			"status":   consts.ReminderStatus.Sending,
+			"attempts": reminder.Attempts,
+		},
+		bson.M{"$set": set},
+	)
+	return err
+}
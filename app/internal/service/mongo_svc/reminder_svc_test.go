@@ -0,0 +1,405 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewReminderSvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewReminderSvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateReminder(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReminderCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("reminder-id", tt.insertErr)
+
+				svc := NewReminderSvcStruct(mongoUseCase)
+				reminderID, err := svc.CreateReminder(model.Reminder{UserID: "uuid"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateReminder() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "reminder-id", reminderID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetReminders(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.Reminder{
+			{Text: "later", RemindAt: now.Add(time.Hour)},
+			{Text: "sooner", RemindAt: now.Add(time.Minute)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			allErr    error
+			returnErr bool
+		}{
+			{"success", false, nil, nil, false},
+			{"init_error", true, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, true},
+			{"all_error", false, nil, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReminderCollectionName, tt.initErr)
+				filter := bson.M{
+					"userid": "uuid",
+					"status": bson.M{"$in": []string{
+						consts.ReminderStatus.Pending,
+						consts.ReminderStatus.Sending,
+					}},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.allErr), tt.findErr)
+
+				svc := NewReminderSvcStruct(mongoUseCase)
+				reminders, err := svc.GetReminders("uuid", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetReminders() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Len(t, reminders, 2)
+				assert.Equal(t, "sooner", reminders[0].Text)
+				assert.Equal(t, "later", reminders[1].Text)
+			})
+		}
+	})
+}
+
+func TestGetReminder(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		reminderID := primitive.NewObjectID()
+
+		tests := []struct {
+			name       string
+			reminderID string
+			initErr    bool
+			findOneErr error
+			returnErr  bool
+		}{
+			{"success", reminderID.Hex(), false, nil, false},
+			{"init_error", reminderID.Hex(), true, nil, true},
+			{"invalid_id", "invalid_id", false, nil, true},
+			{"not_found", reminderID.Hex(), false, mongo.ErrNoDocuments, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReminderCollectionName, tt.initErr)
+				filter := bson.M{"_id": reminderID, "userid": "uuid"}
+				mongoCollectionMock.On("FindOne", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+					reminder := args.Get(2).(*model.Reminder)
+					reminder.Text = "hello"
+				}).Return(tt.findOneErr)
+
+				svc := NewReminderSvcStruct(mongoUseCase)
+				reminder, err := svc.GetReminder(tt.reminderID, "uuid", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetReminder() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "hello", reminder.Text)
+				}
+			})
+		}
+	})
+}
+
+func TestUpdateReminder(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		remindAt := now.Add(time.Hour)
+		reminderID := primitive.NewObjectID()
+		snoozeFilter := bson.M{
+			"_id":    reminderID,
+			"userid": "uuid",
+			"status": bson.M{"$in": []string{
+				consts.ReminderStatus.Pending,
+				consts.ReminderStatus.Sent,
+				consts.ReminderStatus.Failed,
+			}},
+		}
+		snoozeUpdate := bson.M{
+			"$set": bson.M{
+				"status":      consts.ReminderStatus.Pending,
+				"remindAt":    remindAt,
+				"availableAt": remindAt,
+				"attempts":    0,
+				"updatedAt":   now,
+			},
+			"$unset": bson.M{"lastError": ""},
+		}
+		cancelFilter := bson.M{
+			"_id":    reminderID,
+			"userid": "uuid",
+			"status": bson.M{"$in": []string{consts.ReminderStatus.Pending}},
+		}
+		cancelUpdate := bson.M{
+			"$set": bson.M{
+				"status":    consts.ReminderStatus.Canceled,
+				"updatedAt": now,
+			},
+		}
+		snooze := func(svc *ReminderSvcStruct, id string) (bool, error) {
+			return svc.SnoozeReminder(id, "uuid", remindAt, now, atylabmongo.NewMongoCtxSvc())
+		}
+		cancel := func(svc *ReminderSvcStruct, id string) (bool, error) {
+			return svc.CancelReminder(id, "uuid", now, atylabmongo.NewMongoCtxSvc())
+		}
+
+		tests := []struct {
+			name       string
+			reminderID string
+			initErr    bool
+			matched    int64
+			updateErr  error
+			filter     bson.M
+			update     bson.M
+			call       func(svc *ReminderSvcStruct, id string) (bool, error)
+			expected   bool
+			returnErr  bool
+		}{
+			{name: "snooze", reminderID: reminderID.Hex(), matched: 1, filter: snoozeFilter, update: snoozeUpdate, call: snooze, expected: true},
+			{name: "snooze_canceled", reminderID: reminderID.Hex(), matched: 0, filter: snoozeFilter, update: snoozeUpdate, call: snooze, expected: false},
+			{name: "cancel", reminderID: reminderID.Hex(), matched: 1, filter: cancelFilter, update: cancelUpdate, call: cancel, expected: true},
+			{name: "cancel_sent", reminderID: reminderID.Hex(), matched: 0, filter: cancelFilter, update: cancelUpdate, call: cancel, expected: false},
+			{name: "init_error", reminderID: reminderID.Hex(), initErr: true, filter: cancelFilter, update: cancelUpdate, call: cancel, returnErr: true},
+			{name: "invalid_id", reminderID: "invalid_id", filter: cancelFilter, update: cancelUpdate, call: cancel, returnErr: true},
+			{name: "update_error", reminderID: reminderID.Hex(), updateErr: assert.AnError, filter: snoozeFilter, update: snoozeUpdate, call: snooze, returnErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReminderCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, tt.filter, tt.update).
+					Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				updated, err := tt.call(NewReminderSvcStruct(mongoUseCase), tt.reminderID)
+				if (err != nil) != tt.returnErr {
+					t.Errorf("[%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, updated)
+			})
+		}
+	})
+}
+
+func TestGetDueReminders(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.Reminder{
+			{Text: "newer", AvailableAt: now.Add(-time.Minute)},
+			{Text: "oldest", AvailableAt: now.Add(-time.Hour)},
+			{Text: "older", AvailableAt: now.Add(-10 * time.Minute)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			allErr    error
+			limit     int
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, 10, []string{"oldest", "older", "newer"}, false},
+			{"limited", false, nil, nil, 2, []string{"oldest", "older"}, false},
+			{"init_error", true, nil, nil, 10, nil, true},
+			{"find_error", false, assert.AnError, nil, 10, nil, true},
+			{"all_error", false, nil, assert.AnError, 10, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReminderCollectionName, tt.initErr)
+				filter := bson.M{
+					"status": bson.M{"$in": []string{
+						consts.ReminderStatus.Pending,
+						consts.ReminderStatus.Sending,
+					}},
+					"availableAt": bson.M{"$lte": now},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.allErr), tt.findErr)
+
+				svc := NewReminderSvcStruct(mongoUseCase)
+				reminders, err := svc.GetDueReminders(now, tt.limit, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetDueReminders() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				texts := []string{}
+				for _, r := range reminders {
+					texts = append(texts, r.Text)
+				}
+				assert.Equal(t, tt.expected, texts)
+			})
+		}
+	})
+}
+
+func TestLeaseReminder(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		reminder := model.Reminder{
+			ID:          primitive.NewObjectID(),
+			Status:      consts.ReminderStatus.Pending,
+			Attempts:    0,
+			AvailableAt: now.Add(-time.Minute),
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			matched   int64
+			expected  bool
+			returnErr bool
+		}{
+			{"leased", false, nil, 1, true, false},
+			{"taken_by_other_instance", false, nil, 0, false, false},
+			{"init_error", true, nil, 0, false, true},
+			{"update_error", false, assert.AnError, 0, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReminderCollectionName, tt.initErr)
+				filter := bson.M{
+					"_id":         reminder.ID,
+					"status":      consts.ReminderStatus.Pending,
+					"attempts":    0,
+					"availableAt": reminder.AvailableAt,
+				}
+				update := bson.M{
+					"$set": bson.M{
+						"status":      consts.ReminderStatus.Sending,
+						"availableAt": now.Add(time.Minute),
+						"updatedAt":   now,
+					},
+					"$inc": bson.M{"attempts": 1},
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, update).
+					Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				svc := NewReminderSvcStruct(mongoUseCase)
+				leased, err := svc.LeaseReminder(reminder, now, time.Minute, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("LeaseReminder() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, leased)
+			})
+		}
+	})
+}
+
+func TestFinishReminder(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		retryAt := now.Add(time.Minute)
+		reminder := model.Reminder{ID: primitive.NewObjectID(), Status: consts.ReminderStatus.Sending, Attempts: 2}
+		filter := bson.M{"_id": reminder.ID, "status": consts.ReminderStatus.Sending, "attempts": 2}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			set       bson.M
+			call      func(svc *ReminderSvcStruct) error
+			returnErr bool
+		}{
+			{
+				name: "complete",
+				set:  bson.M{"status": consts.ReminderStatus.Sent, "notificationid": "message1", "updatedAt": now},
+				call: func(svc *ReminderSvcStruct) error {
+					return svc.CompleteReminder(reminder, "message1", now, atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "retry",
+				set:  bson.M{"status": consts.ReminderStatus.Pending, "availableAt": retryAt, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ReminderSvcStruct) error {
+					return svc.RetryReminder(reminder, now, retryAt, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "fail",
+				set:  bson.M{"status": consts.ReminderStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ReminderSvcStruct) error {
+					return svc.FailReminder(reminder, now, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name:    "init_error",
+				initErr: true,
+				set:     bson.M{"status": consts.ReminderStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ReminderSvcStruct) error {
+					return svc.FailReminder(reminder, now, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+			{
+				name:      "update_error",
+				updateErr: assert.AnError,
+				set:       bson.M{"status": consts.ReminderStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ReminderSvcStruct) error {
+					return svc.FailReminder(reminder, now, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReminderCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, bson.M{"$set": tt.set}).
+					Return(&mongo.UpdateResult{MatchedCount: 1}, tt.updateErr)
+
+				err := tt.call(NewReminderSvcStruct(mongoUseCase))
+				if (err != nil) != tt.returnErr {
+					t.Errorf("[%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.initErr {
+					mongoCollectionMock.AssertNumberOfCalls(t, "UpdateOne", 1)
+				}
+			})
+		}
+	})
+}
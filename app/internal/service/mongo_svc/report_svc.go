@@ -0,0 +1,195 @@
+package mongo_svc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+type ReportSvcInterface interface {
+	CreateReport(report model.Report, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetReport(reportID string, ctx *atylabmongo.MongoCtxSvc) (model.Report, error)
+	GetReportList(status string, ctx *atylabmongo.MongoCtxSvc) ([]model.Report, error)
+	HasOpenReport(messageID string, reporterID string, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	ResolveReport(reportID string, resolution model.ReportResolution, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	ReopenReport(reportID string, resolution model.ReportResolution, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type ReportSvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewReportSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *ReportSvcStruct {
+	return &ReportSvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *ReportSvcStruct) CreateReport(report model.Report, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReportCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, report)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+func (s *ReportSvcStruct) GetReport(reportID string, ctx *atylabmongo.MongoCtxSvc) (model.Report, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Report{}, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return model.Report{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReportCollectionName)
+
+	var report model.Report
+	err = collection.FindOne(ctx.Ctx, bson.M{"_id": id}, &report)
+	if err != nil {
+		return model.Report{}, err
+	}
+
+	return report, nil
+}
+
+func (s *ReportSvcStruct) GetReportList(status string, ctx *atylabmongo.MongoCtxSvc) ([]model.Report, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Report{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReportCollectionName)
+
+	cursor, err := collection.Find(ctx.Ctx, bson.M{"status": status})
+	if err != nil {
+		fmt.Println("Failed to find reports:", err)
+		return []model.Report{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	var reports []model.Report
+	if err = cursor.All(ctx.Ctx, &reports); err != nil {
+		fmt.Println("Failed to decode reports:", err)
+		return []model.Report{}, err
+	}
+
+	// 古い通報から対応できるように作成日時の昇順で返す
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+	})
+
+	return reports, nil
+}
+
+func (s *ReportSvcStruct) HasOpenReport(messageID string, reporterID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReportCollectionName)
+
+	filter := bson.M{
+		"messageid": messageID,
+		"reporter":  reporterID,
+		"status":    consts.ReportStatus.Open,
+	}
+
+	var report model.Report
+	err = collection.FindOne(ctx.Ctx, filter, &report)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// 未対応の通報のみを対応済みにする
+// 既に他のモデレーターが対応済みの場合はfalseを返す
+func (s *ReportSvcStruct) ResolveReport(reportID string, resolution model.ReportResolution, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	id, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReportCollectionName)
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": id, "status": consts.ReportStatus.Open},
+		bson.M{"$set": bson.M{
+			"status":     consts.ReportStatus.Resolved,
+			"resolution": resolution,
+		}},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// ResolveReport で確保したあと対応に失敗した通報を未対応に戻す
+// 自分が確保した解決だけを取り消すよう、解決者と解決日時も条件にする
+func (s *ReportSvcStruct) ReopenReport(reportID string, resolution model.ReportResolution, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ReportCollectionName)
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":                   id,
+			"status":                consts.ReportStatus.Resolved,
+			"resolution.resolvedBy": resolution.ResolvedBy,
+			"resolution.resolvedAt": resolution.ResolvedAt,
+		},
+		bson.M{
+			"$set":   bson.M{"status": consts.ReportStatus.Open},
+			"$unset": bson.M{"resolution": ""},
+		},
+	)
+	return err
+}
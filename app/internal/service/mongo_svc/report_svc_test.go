@@ -0,0 +1,255 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewReportSvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewReportSvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateReport(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			initErr      bool
+			insertOneErr bool
+			returnErr    bool
+		}{
+			{"success", false, false, false},
+			{"init_error", true, false, true},
+			{"insert_error", false, true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReportCollectionName, tt.initErr)
+				var insertErr error
+				if tt.insertOneErr {
+					insertErr = assert.AnError
+				}
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("report-id", insertErr)
+
+				svc := NewReportSvcStruct(mongoUseCase)
+				reportID, err := svc.CreateReport(model.Report{RoomID: "room1"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateReport() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "report-id", reportID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetReport(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name       string
+			reportID   string
+			initErr    bool
+			findOneErr bool
+			returnErr  bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, false, true},
+			{"invalid_id", "invalid_id", false, false, true},
+			{"findone_error", "60c72b2f9b1d4c3d88f0e6b1", false, true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReportCollectionName, tt.initErr)
+				var findErr error
+				if tt.findOneErr {
+					findErr = assert.AnError
+				}
+				mongoCollectionMock.On("FindOne", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					report := args.Get(2).(*model.Report)
+					report.RoomID = "room1"
+				}).Return(findErr)
+
+				svc := NewReportSvcStruct(mongoUseCase)
+				report, err := svc.GetReport(tt.reportID, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetReport() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "room1", report.RoomID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetReportList(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.Report{
+			{MessageID: "newer", CreatedAt: now},
+			{MessageID: "older", CreatedAt: now.Add(-time.Hour)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   bool
+			allErr    bool
+			returnErr bool
+		}{
+			{"success", false, false, false, false},
+			{"init_error", true, false, false, true},
+			{"find_error", false, true, false, true},
+			{"all_error", false, false, true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReportCollectionName, tt.initErr)
+				var allErr, findErr error
+				if tt.allErr {
+					allErr = assert.AnError
+				}
+				if tt.findErr {
+					findErr = assert.AnError
+				}
+				mongoCursorMock := setupCursorMock(docs, allErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{"status": "open"}).Return(mongoCursorMock, findErr)
+
+				svc := NewReportSvcStruct(mongoUseCase)
+				reports, err := svc.GetReportList("open", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetReportList() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Len(t, reports, 2)
+				assert.Equal(t, "older", reports[0].MessageID)
+				assert.Equal(t, "newer", reports[1].MessageID)
+			})
+		}
+	})
+}
+
+func TestHasOpenReport(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			expected  bool
+			returnErr bool
+		}{
+			{"found", false, nil, true, false},
+			{"not_found", false, mongo.ErrNoDocuments, false, false},
+			{"init_error", true, nil, false, true},
+			{"findone_error", false, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReportCollectionName, tt.initErr)
+				mongoCollectionMock.On("FindOne", mock.Anything, bson.M{
+					"messageid": "message1",
+					"reporter":  "user1",
+					"status":    "open",
+				}, mock.Anything).Return(tt.findErr)
+
+				svc := NewReportSvcStruct(mongoUseCase)
+				result, err := svc.HasOpenReport("message1", "user1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("HasOpenReport() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, result)
+			})
+		}
+	})
+}
+
+func TestResolveReport(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			reportID     string
+			initErr      bool
+			updateOneErr bool
+			matched      int64
+			expected     bool
+			returnErr    bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, false, 1, true, false},
+			{"already_resolved", "60c72b2f9b1d4c3d88f0e6b1", false, false, 0, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, false, 0, false, true},
+			{"invalid_id", "invalid_id", false, false, 0, false, true},
+			{"updateone_error", "60c72b2f9b1d4c3d88f0e6b1", false, true, 0, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReportCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, mock.Anything).
+					Return(&mongo.UpdateResult{MatchedCount: tt.matched}, updateErr)
+
+				svc := NewReportSvcStruct(mongoUseCase)
+				result, err := svc.ResolveReport(tt.reportID, model.ReportResolution{Action: "dismissed"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("ResolveReport() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, result)
+			})
+		}
+	})
+}
+
+func TestReopenReport(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			reportID     string
+			initErr      bool
+			updateOneErr bool
+			returnErr    bool
+		}{
+			{"success", "60c72b2f9b1d4c3d88f0e6b1", false, false, false},
+			{"init_error", "60c72b2f9b1d4c3d88f0e6b1", true, false, true},
+			{"invalid_id", "invalid_id", false, false, true},
+			{"updateone_error", "60c72b2f9b1d4c3d88f0e6b1", false, true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ReportCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, mock.Anything).
+					Return(&mongo.UpdateResult{MatchedCount: 1}, updateErr)
+
+				svc := NewReportSvcStruct(mongoUseCase)
+				err := svc.ReopenReport(tt.reportID, model.ReportResolution{Action: "deleted", ResolvedBy: "moderator"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("ReopenReport() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
@@ -1,13 +1,16 @@
 package mongo_svc
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
 )
 
 type RoomSvcInterface interface {
@@ -17,6 +20,13 @@ type RoomSvcInterface interface {
 	JoinRoom(roomID string, uuid string, ctx *atylabmongo.MongoCtxSvc) error
 	LeaveRoom(roomID string, uuid string, ctx *atylabmongo.MongoCtxSvc) error
 	DeleteRoom(roomID string, ctx *atylabmongo.MongoCtxSvc) error
+	BanMember(roomID string, uuid string, ctx *atylabmongo.MongoCtxSvc) error
+	SetMessageTTL(roomID string, ttl int, ctx *atylabmongo.MongoCtxSvc) error
+	SetRetentionDays(roomID string, days int, ctx *atylabmongo.MongoCtxSvc) error
+	SetHideReadReceipts(roomID string, hide bool, ctx *atylabmongo.MongoCtxSvc) error
+	SetTopic(roomID string, topic string, ctx *atylabmongo.MongoCtxSvc) error
+	SetMuted(roomID string, uuid string, muted bool, ctx *atylabmongo.MongoCtxSvc) error
+	GetOrCreateRoomByExternalID(room model.Room, ctx *atylabmongo.MongoCtxSvc) (model.Room, []string, error)
 }
 
 type RoomSvcStruct struct {
@@ -193,3 +203,233 @@ func (s *RoomSvcStruct) DeleteRoom(roomID string, ctx *atylabmongo.MongoCtxSvc)
 
 	return nil
 }
+
+// メンバーから外した上で、再参加できないようにBANリストへ追加する
+func (s *RoomSvcStruct) BanMember(roomID string, uuid string, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": id},
+		bson.M{
+			"$pull":     bson.M{"members": uuid},
+			"$addToSet": bson.M{"banned_members": uuid},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// メッセージの既定の有効期間（秒）を設定する。0 の場合は期限なしに戻す
+func (s *RoomSvcStruct) SetMessageTTL(roomID string, ttl int, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"message_ttl": ttl}},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// メッセージの保存日数を設定する。0 の場合は無期限に保存する
+// 期間を過ぎたメッセージは retention-purge コマンドで削除する
+func (s *RoomSvcStruct) SetRetentionDays(roomID string, days int, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"retention_days": days}},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// 既読の情報を送信者以外に見せないかどうかを設定する
+func (s *RoomSvcStruct) SetHideReadReceipts(roomID string, hide bool, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"hide_read_receipts": hide}},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ルームのトピックを設定する
+func (s *RoomSvcStruct) SetTopic(roomID string, topic string, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"topic": topic}},
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ルームの通知を止めたメンバーに追加する。muted が false の場合は外す
+func (s *RoomSvcStruct) SetMuted(roomID string, uuid string, muted bool, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	id, err := primitive.ObjectIDFromHex(roomID)
+	if err != nil {
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	update := bson.M{"$pull": bson.M{"muted_members": uuid}}
+	if muted {
+		update = bson.M{"$addToSet": bson.M{"muted_members": uuid}}
+	}
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": id},
+		update,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// external_id でルームを探し、なければ作成する（通知用ルームなど、サーバーが用意するルームに使う）
+// 既にある場合は room.Members のうち退室したメンバーを戻す。同時に作成された場合は先に作成された方を返す
+// 作成・復帰によってルームに加わったメンバーも返す
+func (s *RoomSvcStruct) GetOrCreateRoomByExternalID(room model.Room, ctx *atylabmongo.MongoCtxSvc) (model.Room, []string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Room{}, nil, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.RoomCollectionName)
+
+	var existing model.Room
+	err = collection.FindOne(ctx.Ctx, bson.M{"external_id": room.ExternalID}, &existing)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		insertedID, err := collection.InsertOne(ctx.Ctx, room)
+		if mongodriver.IsDuplicateKeyError(err) {
+			err = collection.FindOne(ctx.Ctx, bson.M{"external_id": room.ExternalID}, &existing)
+			if err != nil {
+				return model.Room{}, nil, err
+			}
+			return existing, []string{}, nil
+		}
+		if err != nil {
+			return model.Room{}, nil, err
+		}
+		room.ID, err = primitive.ObjectIDFromHex(insertedID)
+		if err != nil {
+			return model.Room{}, nil, err
+		}
+		return room, room.Members, nil
+	}
+	if err != nil {
+		return model.Room{}, nil, err
+	}
+
+	missing := []string{}
+	for _, member := range room.Members {
+		if !slices.Contains(existing.Members, member) {
+			missing = append(missing, member)
+		}
+	}
+	if len(missing) == 0 {
+		return existing, missing, nil
+	}
+
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{"_id": existing.ID},
+		bson.M{"$addToSet": bson.M{
+			"members": bson.M{"$each": missing},
+		}},
+	)
+	if err != nil {
+		return model.Room{}, nil, err
+	}
+	existing.Members = append(existing.Members, missing...)
+	return existing, missing, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -357,3 +358,310 @@ func TestDeleteRoom(t *testing.T) {
 		}
 	})
 }
+
+func TestBanMember(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			initErr      bool
+			request      string
+			updateOneErr bool
+			returnErr    bool
+		}{
+			{"success", false, "64a7b2f4e13e4c3f9c8b4567", false, false},
+			{"error", true, "64a7b2f4e13e4c3f9c8b4567", false, true},
+			{"invalid_id", false, "invalid_object_id", false, true},
+			{"updateone_error", false, "64a7b2f4e13e4c3f9c8b4567", true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, bson.M{
+					"$pull":     bson.M{"members": "123"},
+					"$addToSet": bson.M{"banned_members": "123"},
+				}).Return(&mongo.UpdateResult{}, updateErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				err := roomSvc.BanMember(tt.request, "123", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("BanMember() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
+
+func TestSetMessageTTL(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			initErr      bool
+			request      string
+			updateOneErr bool
+			returnErr    bool
+		}{
+			{"success", false, "64a7b2f4e13e4c3f9c8b4567", false, false},
+			{"error", true, "64a7b2f4e13e4c3f9c8b4567", false, true},
+			{"invalid_id", false, "invalid_object_id", false, true},
+			{"updateone_error", false, "64a7b2f4e13e4c3f9c8b4567", true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, bson.M{
+					"$set": bson.M{"message_ttl": 3600},
+				}).Return(&mongo.UpdateResult{}, updateErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				err := roomSvc.SetMessageTTL(tt.request, 3600, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("SetMessageTTL() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
+
+func TestSetRetentionDays(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			initErr      bool
+			request      string
+			updateOneErr bool
+			returnErr    bool
+		}{
+			{"success", false, "64a7b2f4e13e4c3f9c8b4567", false, false},
+			{"error", true, "64a7b2f4e13e4c3f9c8b4567", false, true},
+			{"invalid_id", false, "invalid_object_id", false, true},
+			{"updateone_error", false, "64a7b2f4e13e4c3f9c8b4567", true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, bson.M{
+					"$set": bson.M{"retention_days": 30},
+				}).Return(&mongo.UpdateResult{}, updateErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				err := roomSvc.SetRetentionDays(tt.request, 30, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("SetRetentionDays() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
+
+func TestSetHideReadReceipts(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			initErr      bool
+			request      string
+			updateOneErr bool
+			returnErr    bool
+		}{
+			{"success", false, "64a7b2f4e13e4c3f9c8b4567", false, false},
+			{"error", true, "64a7b2f4e13e4c3f9c8b4567", false, true},
+			{"invalid_id", false, "invalid_object_id", false, true},
+			{"updateone_error", false, "64a7b2f4e13e4c3f9c8b4567", true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, bson.M{
+					"$set": bson.M{"hide_read_receipts": true},
+				}).Return(&mongo.UpdateResult{}, updateErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				err := roomSvc.SetHideReadReceipts(tt.request, true, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("SetHideReadReceipts() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
+
+func TestSetTopic(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			initErr      bool
+			request      string
+			updateOneErr bool
+			returnErr    bool
+		}{
+			{"success", false, "64a7b2f4e13e4c3f9c8b4567", false, false},
+			{"error", true, "64a7b2f4e13e4c3f9c8b4567", false, true},
+			{"invalid_id", false, "invalid_object_id", false, true},
+			{"updateone_error", false, "64a7b2f4e13e4c3f9c8b4567", true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, bson.M{
+					"$set": bson.M{"topic": "release planning"},
+				}).Return(&mongo.UpdateResult{}, updateErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				err := roomSvc.SetTopic(tt.request, "release planning", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("SetTopic() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+			})
+		}
+	})
+}
+
+func TestSetMuted(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name         string
+			initErr      bool
+			request      string
+			muted        bool
+			update       bson.M
+			updateOneErr bool
+			returnErr    bool
+		}{
+			{"mute", false, "64a7b2f4e13e4c3f9c8b4567", true, bson.M{"$addToSet": bson.M{"muted_members": "123"}}, false, false},
+			{"unmute", false, "64a7b2f4e13e4c3f9c8b4567", false, bson.M{"$pull": bson.M{"muted_members": "123"}}, false, false},
+			{"error", true, "64a7b2f4e13e4c3f9c8b4567", true, nil, false, true},
+			{"invalid_id", false, "invalid_object_id", true, nil, false, true},
+			{"updateone_error", false, "64a7b2f4e13e4c3f9c8b4567", true, bson.M{"$addToSet": bson.M{"muted_members": "123"}}, true, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				var updateErr error
+				if tt.updateOneErr {
+					updateErr = assert.AnError
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, mock.Anything, tt.update).Return(&mongo.UpdateResult{}, updateErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				err := roomSvc.SetMuted(tt.request, "123", tt.muted, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("SetMuted() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					mongoCollectionMock.AssertNumberOfCalls(t, "UpdateOne", 1)
+				}
+			})
+		}
+	})
+}
+
+func TestGetOrCreateRoomByExternalID(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		existingID := primitive.NewObjectID()
+		insertedID := primitive.NewObjectID()
+		room := model.Room{Name: "Notifications", ExternalID: "notifications:123", Members: []string{"123"}}
+		filter := bson.M{"external_id": "notifications:123"}
+		duplicateErr := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}
+
+		tests := []struct {
+			name           string
+			initErr        bool
+			findErr        error
+			existing       []string
+			insertErr      error
+			refindErr      error
+			updateErr      error
+			expectedID     primitive.ObjectID
+			expectMembers  []string
+			expectAdded    []string
+			expectInsert   bool
+			expectFindOnce bool
+			expectUpdate   bool
+			returnErr      bool
+		}{
+			{name: "create", findErr: mongo.ErrNoDocuments, expectedID: insertedID, expectMembers: []string{"123"}, expectAdded: []string{"123"}, expectInsert: true, expectFindOnce: true},
+			{name: "existing", existing: []string{"123"}, expectedID: existingID, expectMembers: []string{"123"}, expectAdded: []string{}, expectFindOnce: true},
+			{name: "existing_left", existing: []string{}, expectedID: existingID, expectMembers: []string{"123"}, expectAdded: []string{"123"}, expectFindOnce: true, expectUpdate: true},
+			{name: "created_concurrently", findErr: mongo.ErrNoDocuments, insertErr: duplicateErr, existing: []string{"123"}, expectedID: existingID, expectMembers: []string{"123"}, expectAdded: []string{}, expectInsert: true},
+			{name: "init_error", initErr: true, returnErr: true},
+			{name: "find_error", findErr: assert.AnError, expectFindOnce: true, returnErr: true},
+			{name: "insert_error", findErr: mongo.ErrNoDocuments, insertErr: assert.AnError, expectInsert: true, expectFindOnce: true, returnErr: true},
+			{name: "refind_error", findErr: mongo.ErrNoDocuments, insertErr: duplicateErr, refindErr: assert.AnError, expectInsert: true, returnErr: true},
+			{name: "update_error", existing: []string{}, updateErr: assert.AnError, expectFindOnce: true, expectUpdate: true, returnErr: true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.RoomCollectionName, tt.initErr)
+				mongoCollectionMock.On("FindOne", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+					found := args.Get(2).(*model.Room)
+					found.ID = existingID
+					found.Members = tt.existing
+				}).Return(tt.findErr).Once()
+				mongoCollectionMock.On("FindOne", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+					found := args.Get(2).(*model.Room)
+					found.ID = existingID
+					found.Members = tt.existing
+				}).Return(tt.refindErr).Once()
+				mongoCollectionMock.On("InsertOne", mock.Anything, room).Return(insertedID.Hex(), tt.insertErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, bson.M{"_id": existingID}, bson.M{"$addToSet": bson.M{
+					"members": bson.M{"$each": []string{"123"}},
+				}}).Return(&mongo.UpdateResult{MatchedCount: 1}, tt.updateErr)
+
+				roomSvc := NewRoomSvcStruct(mongoUseCase)
+				result, added, err := roomSvc.GetOrCreateRoomByExternalID(room, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetOrCreateRoomByExternalID() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, tt.expectedID, result.ID)
+					assert.Equal(t, tt.expectMembers, result.Members)
+					assert.Equal(t, tt.expectAdded, added)
+				}
+				if tt.initErr {
+					return
+				}
+				if tt.expectInsert {
+					mongoCollectionMock.AssertNumberOfCalls(t, "InsertOne", 1)
+				} else {
+					mongoCollectionMock.AssertNotCalled(t, "InsertOne", mock.Anything, mock.Anything)
+				}
+				if tt.expectFindOnce {
+					mongoCollectionMock.AssertNumberOfCalls(t, "FindOne", 1)
+				} else {
+					mongoCollectionMock.AssertNumberOfCalls(t, "FindOne", 2)
+				}
+				if tt.expectUpdate {
+					mongoCollectionMock.AssertNumberOfCalls(t, "UpdateOne", 1)
+				} else {
+					mongoCollectionMock.AssertNotCalled(t, "UpdateOne", mock.Anything, mock.Anything, mock.Anything)
+				}
+			})
+		}
+	})
+}
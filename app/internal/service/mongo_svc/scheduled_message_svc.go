@@ -0,0 +1,284 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ScheduledMessageSvcInterface interface {
+	CreateScheduledMessage(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetScheduledMessages(roomID string, sender string, ctx *atylabmongo.MongoCtxSvc) ([]model.ScheduledMessage, error)
+	GetScheduledMessage(scheduledID string, roomID string, sender string, ctx *atylabmongo.MongoCtxSvc) (model.ScheduledMessage, error)
+	UpdateScheduledMessage(scheduledID string, roomID string, sender string, message string, sendAt time.Time, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	CancelScheduledMessage(scheduledID string, roomID string, sender string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	GetDueMessages(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.ScheduledMessage, error)
+	LeaseMessage(scheduled model.ScheduledMessage, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	CompleteMessage(scheduled model.ScheduledMessage, messageID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error
+	RetryMessage(scheduled model.ScheduledMessage, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error
+	FailMessage(scheduled model.ScheduledMessage, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type ScheduledMessageSvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewScheduledMessageSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *ScheduledMessageSvcStruct {
+	return &ScheduledMessageSvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *ScheduledMessageSvcStruct) CreateScheduledMessage(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ScheduledMessageCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, scheduled)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// 送信待ちの予約メッセージを送信日時の早い順で返す
+func (s *ScheduledMessageSvcStruct) GetScheduledMessages(roomID string, sender string, ctx *atylabmongo.MongoCtxSvc) ([]model.ScheduledMessage, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.ScheduledMessage{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ScheduledMessageCollectionName)
+	filter := bson.M{
+		"roomid": roomID,
+		"sender": sender,
+		"status": bson.M{"$in": []string{
+			consts.ScheduledMessageStatus.Pending,
+			consts.ScheduledMessageStatus.Sending,
+		}},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find scheduled messages:", err)
+		return []model.ScheduledMessage{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	scheduled := []model.ScheduledMessage{}
+	if err = cursor.All(ctx.Ctx, &scheduled); err != nil {
+		fmt.Println("Failed to decode scheduled messages:", err)
+		return []model.ScheduledMessage{}, err
+	}
+
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scheduled[i].SendAt.Before(scheduled[j].SendAt)
+	})
+
+	return scheduled, nil
+}
+
+func (s *ScheduledMessageSvcStruct) GetScheduledMessage(scheduledID string, roomID string, sender string, ctx *atylabmongo.MongoCtxSvc) (model.ScheduledMessage, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.ScheduledMessage{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ScheduledMessageCollectionName)
+	scheduledObjectID, err := primitive.ObjectIDFromHex(scheduledID)
+	if err != nil {
+		return model.ScheduledMessage{}, err
+	}
+
+	var scheduled model.ScheduledMessage
+	err = collection.FindOne(ctx.Ctx, bson.M{"_id": scheduledObjectID, "roomid": roomID, "sender": sender}, &scheduled)
+	if err != nil {
+		return model.ScheduledMessage{}, err
+	}
+
+	return scheduled, nil
+}
+
+// 送信待ちの予約メッセージに限って内容と送信日時を変更する
+// 送信処理が始まっている、または送信・取り消し済みの場合は false を返す
+func (s *ScheduledMessageSvcStruct) UpdateScheduledMessage(scheduledID string, roomID string, sender string, message string, sendAt time.Time, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	return s.updatePending(scheduledID, roomID, sender, bson.M{
+		"message":     message,
+		"sendAt":      sendAt,
+		"availableAt": sendAt,
+		"updatedAt":   now,
+	}, ctx)
+}
+
+func (s *ScheduledMessageSvcStruct) CancelScheduledMessage(scheduledID string, roomID string, sender string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	return s.updatePending(scheduledID, roomID, sender, bson.M{
+		"status":    consts.ScheduledMessageStatus.Canceled,
+		"updatedAt": now,
+	}, ctx)
+}
+
+func (s *ScheduledMessageSvcStruct) updatePending(scheduledID string, roomID string, sender string, set bson.M, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ScheduledMessageCollectionName)
+	scheduledObjectID, err := primitive.ObjectIDFromHex(scheduledID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":    scheduledObjectID,
+			"roomid": roomID,
+			"sender": sender,
+			"status": consts.ScheduledMessageStatus.Pending,
+		},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+// 送信日時を過ぎた予約メッセージと、リースが切れた（送信中にインスタンスが停止した）ものを古い順に返す
+func (s *ScheduledMessageSvcStruct) GetDueMessages(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.ScheduledMessage, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.ScheduledMessage{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ScheduledMessageCollectionName)
+	filter := bson.M{
+		"status": bson.M{"$in": []string{
+			consts.ScheduledMessageStatus.Pending,
+			consts.ScheduledMessageStatus.Sending,
+		}},
+		"availableAt": bson.M{"$lte": now},
+	}
+
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find scheduled messages:", err)
+		return []model.ScheduledMessage{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	var scheduled []model.ScheduledMessage
+	if err = cursor.All(ctx.Ctx, &scheduled); err != nil {
+		fmt.Println("Failed to decode scheduled messages:", err)
+		return []model.ScheduledMessage{}, err
+	}
+
+	sort.SliceStable(scheduled, func(i, j int) bool {
+		return scheduled[i].AvailableAt.Before(scheduled[j].AvailableAt)
+	})
+	if len(scheduled) > limit {
+		scheduled = scheduled[:limit]
+	}
+
+	return scheduled, nil
+}
+
+// 取得時点から状態が変わっていない場合のみ送信処理用に確保する
+// 他のインスタンスが先に確保した、または編集・取り消しされた場合は false を返す
+func (s *ScheduledMessageSvcStruct) LeaseMessage(scheduled model.ScheduledMessage, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ScheduledMessageCollectionName)
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":         scheduled.ID,
+			"status":      scheduled.Status,
+			"attempts":    scheduled.Attempts,
+			"availableAt": scheduled.AvailableAt,
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":      consts.ScheduledMessageStatus.Sending,
+				"availableAt": now.Add(lease),
+				"updatedAt":   now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (s *ScheduledMessageSvcStruct) CompleteMessage(scheduled model.ScheduledMessage, messageID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishMessage(scheduled, bson.M{
+		"status":    consts.ScheduledMessageStatus.Sent,
+		"messageid": messageID,
+		"updatedAt": now,
+	}, ctx)
+}
+
+func (s *ScheduledMessageSvcStruct) RetryMessage(scheduled model.ScheduledMessage, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishMessage(scheduled, bson.M{
+		"status":      consts.ScheduledMessageStatus.Pending,
+		"availableAt": retryAt,
+		"lastError":   lastError,
+		"updatedAt":   now,
+	}, ctx)
+}
+
+func (s *ScheduledMessageSvcStruct) FailMessage(scheduled model.ScheduledMessage, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishMessage(scheduled, bson.M{
+		"status":    consts.ScheduledMessageStatus.Failed,
+		"lastError": lastError,
+		"updatedAt": now,
+	}, ctx)
+}
+
+// 送信処理中の予約メッセージの状態を更新する
+// リースが切れて他のインスタンスに再確保されている場合は、そちらの結果を優先して何もしない
+func (s *ScheduledMessageSvcStruct) finishMessage(scheduled model.ScheduledMessage, set bson.M, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ScheduledMessageCollectionName)
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":      scheduled.ID,
+			"status":   consts.ScheduledMessageStatus.Sending,
+			"attempts": scheduled.Attempts,
+		},
+		bson.M{"$set": set},
+	)
+	return err
+}
@@ -0,0 +1,427 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewScheduledMessageSvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewScheduledMessageSvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateScheduledMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ScheduledMessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("scheduled-id", tt.insertErr)
+
+				svc := NewScheduledMessageSvcStruct(mongoUseCase)
+				scheduledID, err := svc.CreateScheduledMessage(model.ScheduledMessage{RoomID: "room1"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateScheduledMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "scheduled-id", scheduledID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetScheduledMessages(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.ScheduledMessage{
+			{Message: "later", SendAt: now.Add(time.Hour)},
+			{Message: "sooner", SendAt: now.Add(time.Minute)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			allErr    error
+			returnErr bool
+		}{
+			{"success", false, nil, nil, false},
+			{"init_error", true, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, true},
+			{"all_error", false, nil, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ScheduledMessageCollectionName, tt.initErr)
+				filter := bson.M{
+					"roomid": "room1",
+					"sender": "uuid",
+					"status": bson.M{"$in": []string{
+						consts.ScheduledMessageStatus.Pending,
+						consts.ScheduledMessageStatus.Sending,
+					}},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.allErr), tt.findErr)
+
+				svc := NewScheduledMessageSvcStruct(mongoUseCase)
+				scheduled, err := svc.GetScheduledMessages("room1", "uuid", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetScheduledMessages() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				assert.Len(t, scheduled, 2)
+				assert.Equal(t, "sooner", scheduled[0].Message)
+				assert.Equal(t, "later", scheduled[1].Message)
+			})
+		}
+	})
+}
+
+func TestGetScheduledMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		scheduledID := primitive.NewObjectID()
+
+		tests := []struct {
+			name        string
+			scheduledID string
+			initErr     bool
+			findOneErr  error
+			returnErr   bool
+		}{
+			{"success", scheduledID.Hex(), false, nil, false},
+			{"init_error", scheduledID.Hex(), true, nil, true},
+			{"invalid_id", "invalid_id", false, nil, true},
+			{"not_found", scheduledID.Hex(), false, mongo.ErrNoDocuments, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ScheduledMessageCollectionName, tt.initErr)
+				filter := bson.M{"_id": scheduledID, "roomid": "room1", "sender": "uuid"}
+				mongoCollectionMock.On("FindOne", mock.Anything, filter, mock.Anything).Run(func(args mock.Arguments) {
+					scheduled := args.Get(2).(*model.ScheduledMessage)
+					scheduled.Message = "hello"
+				}).Return(tt.findOneErr)
+
+				svc := NewScheduledMessageSvcStruct(mongoUseCase)
+				scheduled, err := svc.GetScheduledMessage(tt.scheduledID, "room1", "uuid", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetScheduledMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "hello", scheduled.Message)
+				}
+			})
+		}
+	})
+}
+
+func TestUpdatePendingScheduledMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		sendAt := now.Add(time.Hour)
+		scheduledID := primitive.NewObjectID()
+		filter := bson.M{
+			"_id":    scheduledID,
+			"roomid": "room1",
+			"sender": "uuid",
+			"status": consts.ScheduledMessageStatus.Pending,
+		}
+
+		tests := []struct {
+			name        string
+			scheduledID string
+			initErr     bool
+			matched     int64
+			updateErr   error
+			set         bson.M
+			call        func(svc *ScheduledMessageSvcStruct, id string) (bool, error)
+			expected    bool
+			returnErr   bool
+		}{
+			{
+				name:        "update",
+				scheduledID: scheduledID.Hex(),
+				matched:     1,
+				set:         bson.M{"message": "edited", "sendAt": sendAt, "availableAt": sendAt, "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct, id string) (bool, error) {
+					return svc.UpdateScheduledMessage(id, "room1", "uuid", "edited", sendAt, now, atylabmongo.NewMongoCtxSvc())
+				},
+				expected: true,
+			},
+			{
+				name:        "cancel",
+				scheduledID: scheduledID.Hex(),
+				matched:     1,
+				set:         bson.M{"status": consts.ScheduledMessageStatus.Canceled, "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct, id string) (bool, error) {
+					return svc.CancelScheduledMessage(id, "room1", "uuid", now, atylabmongo.NewMongoCtxSvc())
+				},
+				expected: true,
+			},
+			{
+				name:        "already_sent",
+				scheduledID: scheduledID.Hex(),
+				matched:     0,
+				set:         bson.M{"status": consts.ScheduledMessageStatus.Canceled, "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct, id string) (bool, error) {
+					return svc.CancelScheduledMessage(id, "room1", "uuid", now, atylabmongo.NewMongoCtxSvc())
+				},
+				expected: false,
+			},
+			{
+				name:        "init_error",
+				scheduledID: scheduledID.Hex(),
+				initErr:     true,
+				set:         bson.M{"status": consts.ScheduledMessageStatus.Canceled, "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct, id string) (bool, error) {
+					return svc.CancelScheduledMessage(id, "room1", "uuid", now, atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+			{
+				name:        "invalid_id",
+				scheduledID: "invalid_id",
+				set:         bson.M{"status": consts.ScheduledMessageStatus.Canceled, "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct, id string) (bool, error) {
+					return svc.CancelScheduledMessage(id, "room1", "uuid", now, atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+			{
+				name:        "update_error",
+				scheduledID: scheduledID.Hex(),
+				updateErr:   assert.AnError,
+				set:         bson.M{"message": "edited", "sendAt": sendAt, "availableAt": sendAt, "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct, id string) (bool, error) {
+					return svc.UpdateScheduledMessage(id, "room1", "uuid", "edited", sendAt, now, atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ScheduledMessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, bson.M{"$set": tt.set}).
+					Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				updated, err := tt.call(NewScheduledMessageSvcStruct(mongoUseCase), tt.scheduledID)
+				if (err != nil) != tt.returnErr {
+					t.Errorf("[%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, updated)
+			})
+		}
+	})
+}
+
+func TestGetDueScheduledMessages(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.ScheduledMessage{
+			{Message: "newer", AvailableAt: now.Add(-time.Minute)},
+			{Message: "oldest", AvailableAt: now.Add(-time.Hour)},
+			{Message: "older", AvailableAt: now.Add(-10 * time.Minute)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			allErr    error
+			limit     int
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, 10, []string{"oldest", "older", "newer"}, false},
+			{"limited", false, nil, nil, 2, []string{"oldest", "older"}, false},
+			{"init_error", true, nil, nil, 10, nil, true},
+			{"find_error", false, assert.AnError, nil, 10, nil, true},
+			{"all_error", false, nil, assert.AnError, 10, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ScheduledMessageCollectionName, tt.initErr)
+				filter := bson.M{
+					"status": bson.M{"$in": []string{
+						consts.ScheduledMessageStatus.Pending,
+						consts.ScheduledMessageStatus.Sending,
+					}},
+					"availableAt": bson.M{"$lte": now},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.allErr), tt.findErr)
+
+				svc := NewScheduledMessageSvcStruct(mongoUseCase)
+				scheduled, err := svc.GetDueMessages(now, tt.limit, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetDueMessages() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				messages := []string{}
+				for _, s := range scheduled {
+					messages = append(messages, s.Message)
+				}
+				assert.Equal(t, tt.expected, messages)
+			})
+		}
+	})
+}
+
+func TestLeaseScheduledMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		scheduled := model.ScheduledMessage{
+			ID:          primitive.NewObjectID(),
+			Status:      consts.ScheduledMessageStatus.Pending,
+			Attempts:    0,
+			AvailableAt: now.Add(-time.Minute),
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			matched   int64
+			expected  bool
+			returnErr bool
+		}{
+			{"leased", false, nil, 1, true, false},
+			{"taken_by_other_instance", false, nil, 0, false, false},
+			{"init_error", true, nil, 0, false, true},
+			{"update_error", false, assert.AnError, 0, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ScheduledMessageCollectionName, tt.initErr)
+				filter := bson.M{
+					"_id":         scheduled.ID,
+					"status":      consts.ScheduledMessageStatus.Pending,
+					"attempts":    0,
+					"availableAt": scheduled.AvailableAt,
+				}
+				update := bson.M{
+					"$set": bson.M{
+						"status":      consts.ScheduledMessageStatus.Sending,
+						"availableAt": now.Add(time.Minute),
+						"updatedAt":   now,
+					},
+					"$inc": bson.M{"attempts": 1},
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, update).
+					Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				svc := NewScheduledMessageSvcStruct(mongoUseCase)
+				leased, err := svc.LeaseMessage(scheduled, now, time.Minute, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("LeaseMessage() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, leased)
+			})
+		}
+	})
+}
+
+func TestFinishScheduledMessage(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		retryAt := now.Add(time.Minute)
+		scheduled := model.ScheduledMessage{ID: primitive.NewObjectID(), Status: consts.ScheduledMessageStatus.Sending, Attempts: 2}
+		filter := bson.M{"_id": scheduled.ID, "status": consts.ScheduledMessageStatus.Sending, "attempts": 2}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			set       bson.M
+			call      func(svc *ScheduledMessageSvcStruct) error
+			returnErr bool
+		}{
+			{
+				name: "complete",
+				set:  bson.M{"status": consts.ScheduledMessageStatus.Sent, "messageid": "message1", "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct) error {
+					return svc.CompleteMessage(scheduled, "message1", now, atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "retry",
+				set:  bson.M{"status": consts.ScheduledMessageStatus.Pending, "availableAt": retryAt, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct) error {
+					return svc.RetryMessage(scheduled, now, retryAt, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "fail",
+				set:  bson.M{"status": consts.ScheduledMessageStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct) error {
+					return svc.FailMessage(scheduled, now, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name:    "init_error",
+				initErr: true,
+				set:     bson.M{"status": consts.ScheduledMessageStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct) error {
+					return svc.FailMessage(scheduled, now, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+			{
+				name:      "update_error",
+				updateErr: assert.AnError,
+				set:       bson.M{"status": consts.ScheduledMessageStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ScheduledMessageSvcStruct) error {
+					return svc.FailMessage(scheduled, now, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ScheduledMessageCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, bson.M{"$set": tt.set}).
+					Return(&mongo.UpdateResult{MatchedCount: 1}, tt.updateErr)
+
+				err := tt.call(NewScheduledMessageSvcStruct(mongoUseCase))
+				if (err != nil) != tt.returnErr {
+					t.Errorf("[%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.initErr {
+					mongoCollectionMock.AssertNumberOfCalls(t, "UpdateOne", 1)
+				}
+			})
+		}
+	})
+}
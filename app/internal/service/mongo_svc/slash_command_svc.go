@@ -0,0 +1,165 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SlashCommandSvcInterface interface {
+	CreateSlashCommand(command model.SlashCommand, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetSlashCommands(botID string, ctx *atylabmongo.MongoCtxSvc) ([]model.SlashCommand, error)
+	GetAllSlashCommands(ctx *atylabmongo.MongoCtxSvc) ([]model.SlashCommand, error)
+	GetSlashCommandByName(name string, ctx *atylabmongo.MongoCtxSvc) (model.SlashCommand, error)
+	DeleteSlashCommand(commandID string, botID string, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	DeleteSlashCommands(botID string, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type SlashCommandSvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewSlashCommandSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *SlashCommandSvcStruct {
+	return &SlashCommandSvcStruct{
+		mongo: mongo,
+	}
+}
+
+// 同じ名前のコマンドが登録済みの場合は重複キーのエラーを返す
+func (s *SlashCommandSvcStruct) CreateSlashCommand(command model.SlashCommand, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.SlashCommandCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, command)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// ボットが登録したコマンドを登録した順で返す
+func (s *SlashCommandSvcStruct) GetSlashCommands(botID string, ctx *atylabmongo.MongoCtxSvc) ([]model.SlashCommand, error) {
+	commands, err := s.find(bson.M{"botid": botID}, ctx)
+	if err != nil {
+		return []model.SlashCommand{}, err
+	}
+
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].CreatedAt.Before(commands[j].CreatedAt)
+	})
+
+	return commands, nil
+}
+
+// 登録されているすべてのコマンドを名前順で返す
+func (s *SlashCommandSvcStruct) GetAllSlashCommands(ctx *atylabmongo.MongoCtxSvc) ([]model.SlashCommand, error) {
+	commands, err := s.find(bson.M{}, ctx)
+	if err != nil {
+		return []model.SlashCommand{}, err
+	}
+
+	sort.SliceStable(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+
+	return commands, nil
+}
+
+func (s *SlashCommandSvcStruct) GetSlashCommandByName(name string, ctx *atylabmongo.MongoCtxSvc) (model.SlashCommand, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.SlashCommand{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.SlashCommandCollectionName)
+
+	var command model.SlashCommand
+	err = collection.FindOne(ctx.Ctx, bson.M{"name": name}, &command)
+	if err != nil {
+		return model.SlashCommand{}, err
+	}
+
+	return command, nil
+}
+
+// ボットのコマンドを削除する。該当するコマンドがない場合は false を返す
+func (s *SlashCommandSvcStruct) DeleteSlashCommand(commandID string, botID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.SlashCommandCollectionName)
+	commandObjectID, err := primitive.ObjectIDFromHex(commandID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.DeleteOne(ctx.Ctx, bson.M{"_id": commandObjectID, "botid": botID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
+
+// ボットが登録したコマンドをすべて削除し、名前を他のボットが使えるようにする
+func (s *SlashCommandSvcStruct) DeleteSlashCommands(botID string, ctx *atylabmongo.MongoCtxSvc) error {
+	commands, err := s.find(bson.M{"botid": botID}, ctx)
+	if err != nil {
+		return err
+	}
+
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.SlashCommandCollectionName)
+	for _, command := range commands {
+		if _, err := collection.DeleteOne(ctx.Ctx, bson.M{"_id": command.ID}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *SlashCommandSvcStruct) find(filter bson.M, ctx *atylabmongo.MongoCtxSvc) ([]model.SlashCommand, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return nil, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.SlashCommandCollectionName)
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find slash commands:", err)
+		return nil, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	commands := []model.SlashCommand{}
+	if err = cursor.All(ctx.Ctx, &commands); err != nil {
+		fmt.Println("Failed to decode slash commands:", err)
+		return nil, err
+	}
+
+	return commands, nil
+}
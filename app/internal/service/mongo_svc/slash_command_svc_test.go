@@ -0,0 +1,256 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewSlashCommandSvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewSlashCommandSvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateSlashCommand(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.SlashCommandCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("command-id", tt.insertErr)
+
+				svc := NewSlashCommandSvcStruct(mongoUseCase)
+				commandID, err := svc.CreateSlashCommand(model.SlashCommand{BotID: "bot1", Name: "deploy"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateSlashCommand() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "command-id", commandID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetSlashCommands(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.SlashCommand{
+			{Name: "newer", CreatedAt: now},
+			{Name: "older", CreatedAt: now.Add(-time.Hour)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			decodeErr error
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, []string{"older", "newer"}, false},
+			{"init_error", true, nil, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, nil, true},
+			{"decode_error", false, nil, assert.AnError, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.SlashCommandCollectionName, tt.initErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{"botid": "bot1"}).Return(setupCursorMock(docs, tt.decodeErr), tt.findErr)
+
+				svc := NewSlashCommandSvcStruct(mongoUseCase)
+				commands, err := svc.GetSlashCommands("bot1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetSlashCommands() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					assert.Empty(t, commands)
+					return
+				}
+				names := []string{}
+				for _, command := range commands {
+					names = append(names, command.Name)
+				}
+				assert.Equal(t, tt.expected, names)
+			})
+		}
+	})
+}
+
+func TestGetAllSlashCommands(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		docs := []model.SlashCommand{
+			{Name: "weather"},
+			{Name: "deploy"},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			decodeErr error
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, []string{"deploy", "weather"}, false},
+			{"init_error", true, nil, nil, nil, true},
+			{"find_error", false, assert.AnError, nil, nil, true},
+			{"decode_error", false, nil, assert.AnError, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.SlashCommandCollectionName, tt.initErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{}).Return(setupCursorMock(docs, tt.decodeErr), tt.findErr)
+
+				svc := NewSlashCommandSvcStruct(mongoUseCase)
+				commands, err := svc.GetAllSlashCommands(atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetAllSlashCommands() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					assert.Empty(t, commands)
+					return
+				}
+				names := []string{}
+				for _, command := range commands {
+					names = append(names, command.Name)
+				}
+				assert.Equal(t, tt.expected, names)
+			})
+		}
+	})
+}
+
+func TestGetSlashCommandByName(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name       string
+			initErr    bool
+			findOneErr error
+			returnErr  bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"not_found", false, mongo.ErrNoDocuments, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.SlashCommandCollectionName, tt.initErr)
+				mongoCollectionMock.On("FindOne", mock.Anything, bson.M{"name": "deploy"}, mock.Anything).Run(func(args mock.Arguments) {
+					command := args.Get(2).(*model.SlashCommand)
+					command.BotID = "bot1"
+				}).Return(tt.findOneErr)
+
+				svc := NewSlashCommandSvcStruct(mongoUseCase)
+				command, err := svc.GetSlashCommandByName("deploy", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetSlashCommandByName() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.findOneErr != nil {
+					assert.ErrorIs(t, err, tt.findOneErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "bot1", command.BotID)
+				}
+			})
+		}
+	})
+}
+
+func TestDeleteSlashCommand(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		commandID := primitive.NewObjectID()
+
+		tests := []struct {
+			name      string
+			id        string
+			initErr   bool
+			result    *mongo.DeleteResult
+			deleteErr error
+			expected  bool
+			returnErr bool
+		}{
+			{"deleted", commandID.Hex(), false, &mongo.DeleteResult{DeletedCount: 1}, nil, true, false},
+			{"not_found", commandID.Hex(), false, &mongo.DeleteResult{DeletedCount: 0}, nil, false, false},
+			{"init_error", commandID.Hex(), true, nil, nil, false, true},
+			{"invalid_id", "invalid_object_id", false, nil, nil, false, true},
+			{"delete_error", commandID.Hex(), false, &mongo.DeleteResult{}, assert.AnError, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.SlashCommandCollectionName, tt.initErr)
+				filter := bson.M{"_id": commandID, "botid": "bot1"}
+				mongoCollectionMock.On("DeleteOne", mock.Anything, filter).Return(tt.result, tt.deleteErr)
+
+				svc := NewSlashCommandSvcStruct(mongoUseCase)
+				deleted, err := svc.DeleteSlashCommand(tt.id, "bot1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("DeleteSlashCommand() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, deleted)
+			})
+		}
+	})
+}
+
+func TestDeleteSlashCommands(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		docs := []model.SlashCommand{
+			{ID: primitive.NewObjectID(), Name: "deploy"},
+			{ID: primitive.NewObjectID(), Name: "weather"},
+		}
+
+		tests := []struct {
+			name          string
+			initErr       bool
+			findErr       error
+			deleteErr     error
+			expectDeletes int
+			returnErr     bool
+		}{
+			{"success", false, nil, nil, 2, false},
+			{"init_error", true, nil, nil, 0, true},
+			{"find_error", false, assert.AnError, nil, 0, true},
+			{"delete_error", false, nil, assert.AnError, 1, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.SlashCommandCollectionName, tt.initErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{"botid": "bot1"}).Return(setupCursorMock(docs, nil), tt.findErr)
+				mongoCollectionMock.On("DeleteOne", mock.Anything, mock.Anything).Return(&mongo.DeleteResult{DeletedCount: 1}, tt.deleteErr)
+
+				svc := NewSlashCommandSvcStruct(mongoUseCase)
+				err := svc.DeleteSlashCommands("bot1", atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("DeleteSlashCommands() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				mongoCollectionMock.AssertNumberOfCalls(t, "DeleteOne", tt.expectDeletes)
+			})
+		}
+	})
+}
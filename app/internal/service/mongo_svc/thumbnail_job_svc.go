@@ -0,0 +1,171 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ThumbnailJobSvcInterface interface {
+	CreateJob(job model.ThumbnailJob, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetRunnableJobs(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.ThumbnailJob, error)
+	LeaseJob(job model.ThumbnailJob, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	CompleteJob(job model.ThumbnailJob, now time.Time, ctx *atylabmongo.MongoCtxSvc) error
+	RetryJob(job model.ThumbnailJob, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error
+	FailJob(job model.ThumbnailJob, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type ThumbnailJobSvcStruct struct {
+	mongo  usecase.MongoUseCaseInterface
+	driver usecase.MongoDriverUseCaseInterface
+}
+
+func NewThumbnailJobSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+	driver usecase.MongoDriverUseCaseInterface,
+) *ThumbnailJobSvcStruct {
+	return &ThumbnailJobSvcStruct{
+		mongo:  mongo,
+		driver: driver,
+	}
+}
+
+func (s *ThumbnailJobSvcStruct) CreateJob(job model.ThumbnailJob, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ThumbnailJobCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, job)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// 実行待ちのジョブと、リースが切れた（処理中にワーカーが停止した）ジョブを古い順に返す
+// 溜まったジョブを全件読まないよう、並び順と件数を指定できるドライバーで status・availableAt のインデックスを使って取得する
+func (s *ThumbnailJobSvcStruct) GetRunnableJobs(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.ThumbnailJob, error) {
+	db, err := s.driver.Database()
+	if err != nil {
+		fmt.Println("Failed to connect to MongoDB:", err)
+		return []model.ThumbnailJob{}, err
+	}
+
+	collection := db.Collection(model.ThumbnailJobCollectionName)
+	filter := bson.M{
+		"status": bson.M{"$in": []string{
+			consts.ThumbnailJobStatus.Pending,
+			consts.ThumbnailJobStatus.Processing,
+		}},
+		"availableAt": bson.M{"$lte": now},
+	}
+
+	cursor, err := collection.Find(
+		ctx.Ctx,
+		filter,
+		options.Find().SetSort(bson.D{{Key: "availableAt", Value: 1}}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		fmt.Println("Failed to find thumbnail jobs:", err)
+		return []model.ThumbnailJob{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	jobs := []model.ThumbnailJob{}
+	if err = cursor.All(ctx.Ctx, &jobs); err != nil {
+		fmt.Println("Failed to decode thumbnail jobs:", err)
+		return []model.ThumbnailJob{}, err
+	}
+
+	return jobs, nil
+}
+
+// 取得時点から状態が変わっていない場合のみジョブを確保する
+// 他のワーカーが先に確保した場合は false を返す
+func (s *ThumbnailJobSvcStruct) LeaseJob(job model.ThumbnailJob, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ThumbnailJobCollectionName)
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":         job.ID,
+			"status":      job.Status,
+			"attempts":    job.Attempts,
+			"availableAt": job.AvailableAt,
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":      consts.ThumbnailJobStatus.Processing,
+				"availableAt": now.Add(lease),
+				"updatedAt":   now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (s *ThumbnailJobSvcStruct) CompleteJob(job model.ThumbnailJob, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishJob(job, bson.M{
+		"status":    consts.ThumbnailJobStatus.Done,
+		"updatedAt": now,
+	}, ctx)
+}
+
+func (s *ThumbnailJobSvcStruct) RetryJob(job model.ThumbnailJob, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishJob(job, bson.M{
+		"status":      consts.ThumbnailJobStatus.Pending,
+		"availableAt": retryAt,
+		"lastError":   lastError,
+		"updatedAt":   now,
+	}, ctx)
+}
+
+func (s *ThumbnailJobSvcStruct) FailJob(job model.ThumbnailJob, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishJob(job, bson.M{
+		"status":    consts.ThumbnailJobStatus.Failed,
+		"lastError": lastError,
+		"updatedAt": now,
+	}, ctx)
+}
+
+// 処理中のジョブの状態を更新する
+// リースが切れて他のワーカーに再確保されている場合は、そちらの結果を優先して何もしない
+func (s *ThumbnailJobSvcStruct) finishJob(job model.ThumbnailJob, set bson.M, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.ThumbnailJobCollectionName)
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":      job.ID,
+			"status":   consts.ThumbnailJobStatus.Processing,
+			"attempts": job.Attempts,
+		},
+		bson.M{"$set": set},
+	)
+	return err
+}
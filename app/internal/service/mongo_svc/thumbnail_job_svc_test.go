@@ -0,0 +1,262 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestNewThumbnailJobSvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	driver := usecase.NewMongoDriverUseCaseStruct()
+	svc := NewThumbnailJobSvcStruct(atylabMongo, driver)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+	assert.Equal(t, driver, svc.driver, "expected driver field to be set correctly")
+}
+
+func TestCreateThumbnailJob(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ThumbnailJobCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("job-id", tt.insertErr)
+
+				svc := NewThumbnailJobSvcStruct(mongoUseCase, nil)
+				jobID, err := svc.CreateJob(model.ThumbnailJob{MessageID: "message1"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateJob() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "job-id", jobID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetRunnableThumbnailJobs(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ns := "chatapp." + model.ThumbnailJobCollectionName
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	jobDoc := func(messageID string) bson.D {
+		return bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "messageid", Value: messageID}}
+	}
+	getJobs := func(mt *mtest.T) ([]model.ThumbnailJob, error) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(mt.DB, nil)
+		return NewThumbnailJobSvcStruct(nil, driver).GetRunnableJobs(now, 2, atylabmongo.NewMongoCtxSvc())
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, jobDoc("oldest"), jobDoc("older")))
+
+		jobs, err := getJobs(mt)
+		assert.NoError(mt, err)
+		if assert.Len(mt, jobs, 2) {
+			assert.Equal(mt, "oldest", jobs[0].MessageID)
+			assert.Equal(mt, "older", jobs[1].MessageID)
+		}
+
+		// 並び替えと件数の制限はデータベースで行う
+		find := mt.GetStartedEvent()
+		assert.Equal(mt, "find", find.CommandName)
+		statuses, err := find.Command.Lookup("filter", "status", "$in").Array().Values()
+		assert.NoError(mt, err)
+		if assert.Len(mt, statuses, 2) {
+			assert.Equal(mt, consts.ThumbnailJobStatus.Pending, statuses[0].StringValue())
+			assert.Equal(mt, consts.ThumbnailJobStatus.Processing, statuses[1].StringValue())
+		}
+		assert.Equal(mt, now.UnixMilli(), find.Command.Lookup("filter", "availableAt", "$lte").Time().UnixMilli())
+		keys, err := find.Command.Lookup("sort").Document().Elements()
+		assert.NoError(mt, err)
+		if assert.Len(mt, keys, 1) {
+			assert.Equal(mt, "availableAt", keys[0].Key())
+			assert.Equal(mt, int32(1), keys[0].Value().Int32())
+		}
+		assert.Equal(mt, int64(2), find.Command.Lookup("limit").AsInt64())
+	})
+
+	mt.Run("empty", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+
+		jobs, err := getJobs(mt)
+		assert.NoError(mt, err)
+		assert.Empty(mt, jobs)
+	})
+
+	mt.Run("find error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "failed"}))
+
+		jobs, err := getJobs(mt)
+		assert.Error(mt, err)
+		assert.Empty(mt, jobs)
+	})
+
+	mt.Run("decode error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "_id", Value: "not-object-id"}}))
+
+		jobs, err := getJobs(mt)
+		assert.Error(mt, err)
+		assert.Empty(mt, jobs)
+	})
+
+	t.Run("connection error", func(t *testing.T) {
+		driver := new(usecase_mock.MongoDriverUseCaseMock)
+		driver.On("Database").Return(nil, assert.AnError)
+		_, err := NewThumbnailJobSvcStruct(nil, driver).GetRunnableJobs(now, 2, atylabmongo.NewMongoCtxSvc())
+		assert.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestLeaseThumbnailJob(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		job := model.ThumbnailJob{
+			ID:          primitive.NewObjectID(),
+			Status:      consts.ThumbnailJobStatus.Pending,
+			Attempts:    1,
+			AvailableAt: now.Add(-time.Minute),
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			matched   int64
+			expected  bool
+			returnErr bool
+		}{
+			{"leased", false, nil, 1, true, false},
+			{"taken_by_other_worker", false, nil, 0, false, false},
+			{"init_error", true, nil, 0, false, true},
+			{"update_error", false, assert.AnError, 0, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ThumbnailJobCollectionName, tt.initErr)
+				filter := bson.M{
+					"_id":         job.ID,
+					"status":      consts.ThumbnailJobStatus.Pending,
+					"attempts":    1,
+					"availableAt": job.AvailableAt,
+				}
+				update := bson.M{
+					"$set": bson.M{
+						"status":      consts.ThumbnailJobStatus.Processing,
+						"availableAt": now.Add(time.Minute),
+						"updatedAt":   now,
+					},
+					"$inc": bson.M{"attempts": 1},
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, update).
+					Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				svc := NewThumbnailJobSvcStruct(mongoUseCase, nil)
+				leased, err := svc.LeaseJob(job, now, time.Minute, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("LeaseJob() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, leased)
+			})
+		}
+	})
+}
+
+func TestFinishThumbnailJob(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		retryAt := now.Add(time.Minute)
+		job := model.ThumbnailJob{ID: primitive.NewObjectID(), Status: consts.ThumbnailJobStatus.Processing, Attempts: 2}
+		filter := bson.M{"_id": job.ID, "status": consts.ThumbnailJobStatus.Processing, "attempts": 2}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			set       bson.M
+			call      func(svc *ThumbnailJobSvcStruct) error
+			returnErr bool
+		}{
+			{
+				name: "complete",
+				set:  bson.M{"status": consts.ThumbnailJobStatus.Done, "updatedAt": now},
+				call: func(svc *ThumbnailJobSvcStruct) error {
+					return svc.CompleteJob(job, now, atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "retry",
+				set:  bson.M{"status": consts.ThumbnailJobStatus.Pending, "availableAt": retryAt, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ThumbnailJobSvcStruct) error {
+					return svc.RetryJob(job, now, retryAt, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "fail",
+				set:  bson.M{"status": consts.ThumbnailJobStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *ThumbnailJobSvcStruct) error {
+					return svc.FailJob(job, now, "boom", atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name:    "init_error",
+				initErr: true,
+				set:     bson.M{"status": consts.ThumbnailJobStatus.Done, "updatedAt": now},
+				call: func(svc *ThumbnailJobSvcStruct) error {
+					return svc.CompleteJob(job, now, atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+			{
+				name:      "update_error",
+				updateErr: assert.AnError,
+				set:       bson.M{"status": consts.ThumbnailJobStatus.Done, "updatedAt": now},
+				call: func(svc *ThumbnailJobSvcStruct) error {
+					return svc.CompleteJob(job, now, atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.ThumbnailJobCollectionName, tt.initErr)
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, bson.M{"$set": tt.set}).
+					Return(&mongo.UpdateResult{MatchedCount: 1}, tt.updateErr)
+
+				err := tt.call(NewThumbnailJobSvcStruct(mongoUseCase, nil))
+				if (err != nil) != tt.returnErr {
+					t.Errorf("[%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.initErr {
+					mongoCollectionMock.AssertNumberOfCalls(t, "UpdateOne", 1)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,198 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type WebhookDeliverySvcInterface interface {
+	CreateDelivery(delivery model.WebhookDelivery, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetDeliveries(webhookID string, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.WebhookDelivery, error)
+	GetRunnableDeliveries(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.WebhookDelivery, error)
+	LeaseDelivery(delivery model.WebhookDelivery, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+	CompleteDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, ctx *atylabmongo.MongoCtxSvc) error
+	RetryDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, retryAt time.Time, ctx *atylabmongo.MongoCtxSvc) error
+	FailDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, ctx *atylabmongo.MongoCtxSvc) error
+}
+
+type WebhookDeliverySvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewWebhookDeliverySvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *WebhookDeliverySvcStruct {
+	return &WebhookDeliverySvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *WebhookDeliverySvcStruct) CreateDelivery(delivery model.WebhookDelivery, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookDeliveryCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, delivery)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// Webhook の配信を新しい順に返す
+func (s *WebhookDeliverySvcStruct) GetDeliveries(webhookID string, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.WebhookDelivery, error) {
+	deliveries, err := s.find(bson.M{"webhookid": webhookID}, ctx)
+	if err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// 送信時刻を過ぎた配信と、リースが切れた（送信中にワーカーが停止した）配信を古い順に返す
+func (s *WebhookDeliverySvcStruct) GetRunnableDeliveries(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.WebhookDelivery, error) {
+	deliveries, err := s.find(bson.M{
+		"status": bson.M{"$in": []string{
+			consts.WebhookDeliveryStatus.Pending,
+			consts.WebhookDeliveryStatus.Processing,
+		}},
+		"availableAt": bson.M{"$lte": now},
+	}, ctx)
+	if err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].AvailableAt.Before(deliveries[j].AvailableAt)
+	})
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (s *WebhookDeliverySvcStruct) find(filter bson.M, ctx *atylabmongo.MongoCtxSvc) ([]model.WebhookDelivery, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.WebhookDelivery{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookDeliveryCollectionName)
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find webhook deliveries:", err)
+		return []model.WebhookDelivery{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	deliveries := []model.WebhookDelivery{}
+	if err = cursor.All(ctx.Ctx, &deliveries); err != nil {
+		fmt.Println("Failed to decode webhook deliveries:", err)
+		return []model.WebhookDelivery{}, err
+	}
+
+	return deliveries, nil
+}
+
+// 取得時点から状態が変わっていない場合のみ配信を確保する
+// 他のワーカーが先に確保した場合は false を返す
+func (s *WebhookDeliverySvcStruct) LeaseDelivery(delivery model.WebhookDelivery, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookDeliveryCollectionName)
+	result, err := collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":         delivery.ID,
+			"status":      delivery.Status,
+			"attempts":    delivery.Attempts,
+			"availableAt": delivery.AvailableAt,
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":      consts.WebhookDeliveryStatus.Processing,
+				"availableAt": now.Add(lease),
+				"updatedAt":   now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (s *WebhookDeliverySvcStruct) CompleteDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishDelivery(delivery, attempt, bson.M{
+		"status":    consts.WebhookDeliveryStatus.Done,
+		"updatedAt": attempt.AttemptedAt,
+	}, ctx)
+}
+
+func (s *WebhookDeliverySvcStruct) RetryDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, retryAt time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishDelivery(delivery, attempt, bson.M{
+		"status":      consts.WebhookDeliveryStatus.Pending,
+		"availableAt": retryAt,
+		"lastError":   attempt.Error,
+		"updatedAt":   attempt.AttemptedAt,
+	}, ctx)
+}
+
+func (s *WebhookDeliverySvcStruct) FailDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, ctx *atylabmongo.MongoCtxSvc) error {
+	return s.finishDelivery(delivery, attempt, bson.M{
+		"status":    consts.WebhookDeliveryStatus.Failed,
+		"lastError": attempt.Error,
+		"updatedAt": attempt.AttemptedAt,
+	}, ctx)
+}
+
+// 送信中の配信の状態を更新し、試行の結果を履歴に残す
+// リースが切れて他のワーカーに再確保されている場合は、そちらの結果を優先して何もしない
+func (s *WebhookDeliverySvcStruct) finishDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, set bson.M, ctx *atylabmongo.MongoCtxSvc) error {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookDeliveryCollectionName)
+	_, err = collection.UpdateOne(
+		ctx.Ctx,
+		bson.M{
+			"_id":      delivery.ID,
+			"status":   consts.WebhookDeliveryStatus.Processing,
+			"attempts": delivery.Attempts,
+		},
+		bson.M{
+			"$set":  set,
+			"$push": bson.M{"history": attempt},
+		},
+	)
+	return err
+}
@@ -0,0 +1,288 @@
+package mongo_svc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestNewWebhookDeliverySvcStruct(t *testing.T) {
+	atylabMongo := usecase.NewMongoUseCaseStruct(atylabmongo.NewMongoConnectionStruct(), usecase.NewMongo())
+	svc := NewWebhookDeliverySvcStruct(atylabMongo)
+	assert.Equal(t, atylabMongo, svc.mongo, "expected mongo field to be set correctly")
+}
+
+func TestCreateWebhookDelivery(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		tests := []struct {
+			name      string
+			initErr   bool
+			insertErr error
+			returnErr bool
+		}{
+			{"success", false, nil, false},
+			{"init_error", true, nil, true},
+			{"insert_error", false, assert.AnError, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.WebhookDeliveryCollectionName, tt.initErr)
+				mongoCollectionMock.On("InsertOne", mock.Anything, mock.Anything).Return("delivery-id", tt.insertErr)
+
+				svc := NewWebhookDeliverySvcStruct(mongoUseCase)
+				deliveryID, err := svc.CreateDelivery(model.WebhookDelivery{WebhookID: "webhook1"}, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("CreateDelivery() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.returnErr {
+					assert.Equal(t, "delivery-id", deliveryID)
+				}
+			})
+		}
+	})
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.WebhookDelivery{
+			{Event: "older", CreatedAt: now.Add(-time.Hour)},
+			{Event: "newest", CreatedAt: now},
+			{Event: "middle", CreatedAt: now.Add(-time.Minute)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			decodeErr error
+			limit     int
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, 10, []string{"newest", "middle", "older"}, false},
+			{"limited", false, nil, nil, 2, []string{"newest", "middle"}, false},
+			{"init_error", true, nil, nil, 10, nil, true},
+			{"find_error", false, assert.AnError, nil, 10, nil, true},
+			{"decode_error", false, nil, assert.AnError, 10, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.WebhookDeliveryCollectionName, tt.initErr)
+				mongoCollectionMock.On("Find", mock.Anything, bson.M{"webhookid": "webhook1"}).Return(setupCursorMock(docs, tt.decodeErr), tt.findErr)
+
+				svc := NewWebhookDeliverySvcStruct(mongoUseCase)
+				deliveries, err := svc.GetDeliveries("webhook1", tt.limit, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetDeliveries() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				events := []string{}
+				for _, delivery := range deliveries {
+					events = append(events, delivery.Event)
+				}
+				assert.Equal(t, tt.expected, events)
+			})
+		}
+	})
+}
+
+func TestGetRunnableWebhookDeliveries(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		docs := []model.WebhookDelivery{
+			{Event: "newer", AvailableAt: now.Add(-time.Minute)},
+			{Event: "oldest", AvailableAt: now.Add(-time.Hour)},
+			{Event: "older", AvailableAt: now.Add(-10 * time.Minute)},
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			findErr   error
+			decodeErr error
+			limit     int
+			expected  []string
+			returnErr bool
+		}{
+			{"success", false, nil, nil, 10, []string{"oldest", "older", "newer"}, false},
+			{"limited", false, nil, nil, 2, []string{"oldest", "older"}, false},
+			{"init_error", true, nil, nil, 10, nil, true},
+			{"find_error", false, assert.AnError, nil, 10, nil, true},
+			{"decode_error", false, nil, assert.AnError, 10, nil, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.WebhookDeliveryCollectionName, tt.initErr)
+				filter := bson.M{
+					"status": bson.M{"$in": []string{
+						consts.WebhookDeliveryStatus.Pending,
+						consts.WebhookDeliveryStatus.Processing,
+					}},
+					"availableAt": bson.M{"$lte": now},
+				}
+				mongoCollectionMock.On("Find", mock.Anything, filter).Return(setupCursorMock(docs, tt.decodeErr), tt.findErr)
+
+				svc := NewWebhookDeliverySvcStruct(mongoUseCase)
+				deliveries, err := svc.GetRunnableDeliveries(now, tt.limit, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("GetRunnableDeliveries() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if tt.returnErr {
+					return
+				}
+				events := []string{}
+				for _, delivery := range deliveries {
+					events = append(events, delivery.Event)
+				}
+				assert.Equal(t, tt.expected, events)
+			})
+		}
+	})
+}
+
+func TestLeaseWebhookDelivery(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		delivery := model.WebhookDelivery{
+			ID:          primitive.NewObjectID(),
+			Status:      consts.WebhookDeliveryStatus.Pending,
+			Attempts:    1,
+			AvailableAt: now.Add(-time.Minute),
+		}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			matched   int64
+			expected  bool
+			returnErr bool
+		}{
+			{"leased", false, nil, 1, true, false},
+			{"taken_by_other_worker", false, nil, 0, false, false},
+			{"init_error", true, nil, 0, false, true},
+			{"update_error", false, assert.AnError, 0, false, true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.WebhookDeliveryCollectionName, tt.initErr)
+				filter := bson.M{
+					"_id":         delivery.ID,
+					"status":      consts.WebhookDeliveryStatus.Pending,
+					"attempts":    1,
+					"availableAt": delivery.AvailableAt,
+				}
+				update := bson.M{
+					"$set": bson.M{
+						"status":      consts.WebhookDeliveryStatus.Processing,
+						"availableAt": now.Add(time.Minute),
+						"updatedAt":   now,
+					},
+					"$inc": bson.M{"attempts": 1},
+				}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, update).
+					Return(&mongo.UpdateResult{MatchedCount: tt.matched}, tt.updateErr)
+
+				svc := NewWebhookDeliverySvcStruct(mongoUseCase)
+				leased, err := svc.LeaseDelivery(delivery, now, time.Minute, atylabmongo.NewMongoCtxSvc())
+				if (err != nil) != tt.returnErr {
+					t.Errorf("LeaseDelivery() [%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				assert.Equal(t, tt.expected, leased)
+			})
+		}
+	})
+}
+
+func TestFinishWebhookDelivery(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		now := time.Now()
+		retryAt := now.Add(time.Minute)
+		delivery := model.WebhookDelivery{ID: primitive.NewObjectID(), Status: consts.WebhookDeliveryStatus.Processing, Attempts: 2}
+		attempt := model.WebhookAttempt{AttemptedAt: now, StatusCode: 500, Error: "boom", DurationMs: 12}
+		filter := bson.M{"_id": delivery.ID, "status": consts.WebhookDeliveryStatus.Processing, "attempts": 2}
+
+		tests := []struct {
+			name      string
+			initErr   bool
+			updateErr error
+			set       bson.M
+			call      func(svc *WebhookDeliverySvcStruct) error
+			returnErr bool
+		}{
+			{
+				name: "complete",
+				set:  bson.M{"status": consts.WebhookDeliveryStatus.Done, "updatedAt": now},
+				call: func(svc *WebhookDeliverySvcStruct) error {
+					return svc.CompleteDelivery(delivery, attempt, atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "retry",
+				set:  bson.M{"status": consts.WebhookDeliveryStatus.Pending, "availableAt": retryAt, "lastError": "boom", "updatedAt": now},
+				call: func(svc *WebhookDeliverySvcStruct) error {
+					return svc.RetryDelivery(delivery, attempt, retryAt, atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name: "fail",
+				set:  bson.M{"status": consts.WebhookDeliveryStatus.Failed, "lastError": "boom", "updatedAt": now},
+				call: func(svc *WebhookDeliverySvcStruct) error {
+					return svc.FailDelivery(delivery, attempt, atylabmongo.NewMongoCtxSvc())
+				},
+			},
+			{
+				name:    "init_error",
+				initErr: true,
+				set:     bson.M{"status": consts.WebhookDeliveryStatus.Done, "updatedAt": now},
+				call: func(svc *WebhookDeliverySvcStruct) error {
+					return svc.CompleteDelivery(delivery, attempt, atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+			{
+				name:      "update_error",
+				updateErr: assert.AnError,
+				set:       bson.M{"status": consts.WebhookDeliveryStatus.Done, "updatedAt": now},
+				call: func(svc *WebhookDeliverySvcStruct) error {
+					return svc.CompleteDelivery(delivery, attempt, atylabmongo.NewMongoCtxSvc())
+				},
+				returnErr: true,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mongoCollectionMock, mongoUseCase := setupCollectionMock(model.WebhookDeliveryCollectionName, tt.initErr)
+				update := bson.M{"$set": tt.set, "$push": bson.M{"history": attempt}}
+				mongoCollectionMock.On("UpdateOne", mock.Anything, filter, update).
+					Return(&mongo.UpdateResult{MatchedCount: 1}, tt.updateErr)
+
+				err := tt.call(NewWebhookDeliverySvcStruct(mongoUseCase))
+				if (err != nil) != tt.returnErr {
+					t.Errorf("[%s] error = %v, wantErr %v", tt.name, err, tt.returnErr)
+				}
+				if !tt.initErr {
+					mongoCollectionMock.AssertNumberOfCalls(t, "UpdateOne", 1)
+				}
+			})
+		}
+	})
+}
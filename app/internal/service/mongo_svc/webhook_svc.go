@@ -0,0 +1,131 @@
+package mongo_svc
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WebhookSvcInterface interface {
+	CreateWebhook(webhook model.Webhook, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	GetWebhooks(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Webhook, error)
+	GetSubscribedWebhooks(roomID string, event string, ctx *atylabmongo.MongoCtxSvc) ([]model.Webhook, error)
+	GetWebhook(webhookID string, ctx *atylabmongo.MongoCtxSvc) (model.Webhook, error)
+	DeleteWebhook(webhookID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error)
+}
+
+type WebhookSvcStruct struct {
+	mongo usecase.MongoUseCaseInterface
+}
+
+func NewWebhookSvcStruct(
+	mongo usecase.MongoUseCaseInterface,
+) *WebhookSvcStruct {
+	return &WebhookSvcStruct{
+		mongo: mongo,
+	}
+}
+
+func (s *WebhookSvcStruct) CreateWebhook(webhook model.Webhook, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return "", err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookCollectionName)
+	InsertedID, err := collection.InsertOne(ctx.Ctx, webhook)
+	if err != nil {
+		return "", err
+	}
+
+	return InsertedID, nil
+}
+
+// ルームの Webhook を登録した順で返す
+func (s *WebhookSvcStruct) GetWebhooks(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Webhook, error) {
+	return s.find(bson.M{"roomid": roomID}, ctx)
+}
+
+// イベントを購読しているルームの Webhook を返す
+func (s *WebhookSvcStruct) GetSubscribedWebhooks(roomID string, event string, ctx *atylabmongo.MongoCtxSvc) ([]model.Webhook, error) {
+	return s.find(bson.M{"roomid": roomID, "events": event}, ctx)
+}
+
+func (s *WebhookSvcStruct) find(filter bson.M, ctx *atylabmongo.MongoCtxSvc) ([]model.Webhook, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return []model.Webhook{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookCollectionName)
+	cursor, err := collection.Find(ctx.Ctx, filter)
+	if err != nil {
+		fmt.Println("Failed to find webhooks:", err)
+		return []model.Webhook{}, err
+	}
+	defer cursor.Close(ctx.Ctx)
+
+	webhooks := []model.Webhook{}
+	if err = cursor.All(ctx.Ctx, &webhooks); err != nil {
+		fmt.Println("Failed to decode webhooks:", err)
+		return []model.Webhook{}, err
+	}
+
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
+
+func (s *WebhookSvcStruct) GetWebhook(webhookID string, ctx *atylabmongo.MongoCtxSvc) (model.Webhook, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return model.Webhook{}, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookCollectionName)
+	webhookObjectID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	var webhook model.Webhook
+	err = collection.FindOne(ctx.Ctx, bson.M{"_id": webhookObjectID}, &webhook)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// ルームの Webhook を削除する。該当する Webhook がない場合は false を返す
+// 配信待ちの配信は、送信時に Webhook が見つからないものとして失敗にする
+func (s *WebhookSvcStruct) DeleteWebhook(webhookID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	mongo, err := s.mongo.MongoInit()
+	if err != nil {
+		fmt.Println("Failed to initialize MongoDB:", err)
+		return false, err
+	}
+
+	collection := mongo.MongoConnector.Db.Collection(model.WebhookCollectionName)
+	webhookObjectID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := collection.DeleteOne(ctx.Ctx, bson.M{"_id": webhookObjectID, "roomid": roomID})
+	if err != nil {
+		return false, err
+	}
+
+	return result.DeletedCount == 1, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPin(t *testing.T) {
+	now := time.Date(2025, 1, 2, 9, 0, 0, 500, time.FixedZone("JST", 9*60*60))
+	message := model.Message{ID: primitive.NewObjectID(), RoomID: "room1"}
+	pin := model.MessagePin{PinnedBy: "moderator", PinnedAt: now}
+
+	tests := []struct {
+		name        string
+		message     model.Message
+		pinnedCount int
+		getErr      error
+		pinned      bool
+		pinErr      error
+		publishErr  error
+		expectedErr error
+		expectPin   bool
+		expectEvent bool
+	}{
+		{"success", message, 1, nil, true, nil, nil, nil, true, true},
+		{"publish error is ignored", message, 0, nil, true, nil, assert.AnError, nil, true, true},
+		{"already pinned", model.Message{ID: message.ID, RoomID: "room1", Pin: &pin}, 0, nil, false, nil, nil, ErrAlreadyPinned, false, false},
+		{"limit reached", message, consts.PinMaxPerRoom, nil, false, nil, nil, ErrPinLimitReached, false, false},
+		{"get error", message, 0, assert.AnError, false, nil, nil, assert.AnError, false, false},
+		{"pinned by another moderator", message, 0, nil, false, nil, nil, ErrAlreadyPinned, true, false},
+		{"pin error", message, 0, nil, false, assert.AnError, nil, assert.AnError, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("GetPinnedMessages", "room1", mock.Anything).Return(make([]model.Message, tt.pinnedCount), tt.getErr)
+			messageSvcMock.On("PinMessage", message.ID.Hex(), "room1", pin, mock.Anything).Return(tt.pinned, tt.pinErr)
+			eventSvc := &eventSvcStub{err: tt.publishErr}
+
+			svc := NewPinSvc(messageSvcMock, eventSvc, atylabclock.NewClockMock(now))
+			result, err := svc.Pin(tt.message, "moderator", nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, pin, result)
+			}
+
+			if tt.expectPin {
+				messageSvcMock.AssertCalled(t, "PinMessage", message.ID.Hex(), "room1", pin, mock.Anything)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "PinMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			if !tt.expectEvent {
+				assert.Empty(t, eventSvc.events)
+				return
+			}
+			assert.Len(t, eventSvc.events, 1)
+			assert.Equal(t, consts.EventTypes.MessagePinned, eventSvc.events[0].Type)
+			assert.Equal(t, "room1", eventSvc.events[0].RoomID)
+			assert.Equal(t, messagePinnedEvent{
+				MessageID: message.ID.Hex(),
+				PinnedBy:  "moderator",
+				PinnedAt:  "2025-01-02T00:00:00Z",
+			}, eventSvc.events[0].Data)
+		})
+	}
+}
+
+func TestUnpin(t *testing.T) {
+	pin := &model.MessagePin{PinnedBy: "moderator", PinnedAt: time.Now()}
+	message := model.Message{ID: primitive.NewObjectID(), RoomID: "room1", Pin: pin}
+
+	tests := []struct {
+		name        string
+		message     model.Message
+		unpinned    bool
+		unpinErr    error
+		expectedErr error
+		expectEvent bool
+	}{
+		{"success", message, true, nil, nil, true},
+		{"not pinned", model.Message{ID: message.ID, RoomID: "room1"}, false, nil, ErrNotPinned, false},
+		{"unpinned by another moderator", message, false, nil, ErrNotPinned, false},
+		{"unpin error", message, false, assert.AnError, assert.AnError, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("UnpinMessage", message.ID.Hex(), "room1", mock.Anything).Return(tt.unpinned, tt.unpinErr)
+			eventSvc := &eventSvcStub{}
+
+			err := NewPinSvc(messageSvcMock, eventSvc, atylabclock.NewClock()).Unpin(tt.message, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if !tt.expectEvent {
+				assert.Empty(t, eventSvc.events)
+				return
+			}
+			assert.Len(t, eventSvc.events, 1)
+			assert.Equal(t, consts.EventTypes.MessageUnpinned, eventSvc.events[0].Type)
+			assert.Equal(t, messageUnpinnedEvent{MessageID: message.ID.Hex()}, eventSvc.events[0].Data)
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+var (
+	ErrPollInvalid         = errors.New("invalid poll")
+	ErrPollInvalidVote     = errors.New("invalid vote")
+	ErrNotPoll             = errors.New("message is not a poll")
+	ErrPollClosed          = errors.New("poll is closed")
+	ErrPollCloseNotAllowed = errors.New("only the creator or admin can close the poll")
+)
+
+// 作成する投票の内容
+type PollInput struct {
+	Question       string
+	Options        []string
+	MultipleChoice bool
+	Anonymous      bool
+	// 締め切り（締め切らない場合は nil）
+	ClosesAt *time.Time
+}
+
+type PollSvcInterface interface {
+	Create(input PollInput, room model.Room, uuid string, bot *model.MessageBot, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	Vote(message model.Message, uuid string, options []int, ctx *atylabmongo.MongoCtxSvc) (model.MessagePoll, error)
+	Close(message model.Message, uuid string, isAdmin bool, ctx *atylabmongo.MongoCtxSvc) (model.MessagePoll, error)
+}
+
+type PollSvc struct {
+	mongoMessageSvc mongo_svc.MessageSvcInterface
+	sendSvc         MessageSvcInterface
+	eventSvc        EventSvcInterface
+	messageDto      dto.MessageDtoInterface
+	clock           atylabclock.ClockInterface
+}
+
+func NewPollSvc(
+	mongoMessageSvc mongo_svc.MessageSvcInterface,
+	sendSvc MessageSvcInterface,
+	eventSvc EventSvcInterface,
+	messageDto dto.MessageDtoInterface,
+	clock atylabclock.ClockInterface,
+) PollSvcInterface {
+	return &PollSvc{
+		mongoMessageSvc: mongoMessageSvc,
+		sendSvc:         sendSvc,
+		eventSvc:        eventSvc,
+		messageDto:      messageDto,
+		clock:           clock,
+	}
+}
+
+// 集計はメンバー全員に同じものを送るので、自分の投票（MyVotes）は含めない
+type pollUpdatedEvent struct {
+	MessageID string           `json:"message_id"`
+	Poll      dto.PollResponse `json:"poll"`
+}
+
+// 投票をメッセージとしてルームに送信する。本文は質問にする
+func (s *PollSvc) Create(input PollInput, room model.Room, uuid string, bot *model.MessageBot, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	now := s.clock.Now()
+	poll, err := newPoll(input, now)
+	if err != nil {
+		return "", err
+	}
+
+	return s.sendSvc.Send(model.Message{
+		RoomID:        room.ID.Hex(),
+		Sender:        uuid,
+		Message:       poll.Question,
+		CreatedAt:     now,
+		IsReadUserIds: []string{uuid},
+		ExpiresAt:     MessageExpiresAt(0, room, now),
+		Bot:           bot,
+		Type:          consts.MessageTypes.Poll,
+		Poll:          &poll,
+	}, ctx)
+}
+
+// 投票する。投票し直した場合は前の投票を置き換える
+// 同時に投票したメンバーの分も含めるため、保存したあとの投票を読み直して集計を知らせる
+func (s *PollSvc) Vote(message model.Message, uuid string, options []int, ctx *atylabmongo.MongoCtxSvc) (model.MessagePoll, error) {
+	if message.Poll == nil {
+		return model.MessagePoll{}, ErrNotPoll
+	}
+	now := s.clock.Now()
+	if pollClosed(*message.Poll, now) {
+		return model.MessagePoll{}, ErrPollClosed
+	}
+
+	options, err := validatePollVote(*message.Poll, options)
+	if err != nil {
+		return model.MessagePoll{}, err
+	}
+
+	voted, err := s.mongoMessageSvc.VotePoll(message.ID.Hex(), message.RoomID, model.PollVote{
+		UserID:  uuid,
+		Options: options,
+		VotedAt: now,
+	}, ctx)
+	if err != nil {
+		return model.MessagePoll{}, err
+	}
+	if !voted {
+		return model.MessagePoll{}, ErrPollClosed
+	}
+
+	updated, err := s.mongoMessageSvc.GetMessage(message.ID.Hex(), message.RoomID, ctx)
+	if err != nil {
+		return model.MessagePoll{}, err
+	}
+	if updated.Poll == nil {
+		return model.MessagePoll{}, ErrNotPoll
+	}
+
+	s.publish(updated.ID.Hex(), updated.RoomID, *updated.Poll)
+	return *updated.Poll, nil
+}
+
+// 締め切りの前に投票を締め切る。作成したユーザーとルームの管理者だけができる
+func (s *PollSvc) Close(message model.Message, uuid string, isAdmin bool, ctx *atylabmongo.MongoCtxSvc) (model.MessagePoll, error) {
+	if message.Poll == nil {
+		return model.MessagePoll{}, ErrNotPoll
+	}
+	if message.Sender != uuid && !isAdmin {
+		return model.MessagePoll{}, ErrPollCloseNotAllowed
+	}
+	if message.Poll.ClosedAt != nil {
+		return model.MessagePoll{}, ErrPollClosed
+	}
+
+	now := s.clock.Now()
+	closed, err := s.mongoMessageSvc.ClosePoll(message.ID.Hex(), message.RoomID, uuid, now, ctx)
+	if err != nil {
+		return model.MessagePoll{}, err
+	}
+	if !closed {
+		return model.MessagePoll{}, ErrPollClosed
+	}
+
+	poll := *message.Poll
+	poll.ClosedAt = &now
+	poll.ClosedBy = uuid
+	s.publish(message.ID.Hex(), message.RoomID, poll)
+	return poll, nil
+}
+
+// 投票は保存できているので、通知できなくてもログに残すだけにする
+func (s *PollSvc) publish(messageID string, roomID string, poll model.MessagePoll) {
+	_, err := s.eventSvc.Publish(RoomEvent{
+		Type:   consts.EventTypes.PollUpdated,
+		RoomID: roomID,
+		Data: pollUpdatedEvent{
+			MessageID: messageID,
+			Poll:      s.messageDto.GetPollInfo(poll, ""),
+		},
+	})
+	if err != nil {
+		fmt.Println("Failed to publish poll event:", err)
+	}
+}
+
+// 質問と選択肢の前後の空白を取り除き、数・長さ・締め切りを確認する
+func newPoll(input PollInput, now time.Time) (model.MessagePoll, error) {
+	question := strings.TrimSpace(input.Question)
+	if question == "" {
+		return model.MessagePoll{}, fmt.Errorf("%w: question is required", ErrPollInvalid)
+	}
+	if utf8.RuneCountInString(question) > consts.PollQuestionMaxLength {
+		return model.MessagePoll{}, fmt.Errorf("%w: question must be at most %d characters", ErrPollInvalid, consts.PollQuestionMaxLength)
+	}
+
+	options := []string{}
+	for _, option := range input.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return model.MessagePoll{}, fmt.Errorf("%w: options must not be empty", ErrPollInvalid)
+		}
+		if utf8.RuneCountInString(option) > consts.PollOptionMaxLength {
+			return model.MessagePoll{}, fmt.Errorf("%w: each option must be at most %d characters", ErrPollInvalid, consts.PollOptionMaxLength)
+		}
+		if slices.Contains(options, option) {
+			return model.MessagePoll{}, fmt.Errorf("%w: options must be unique", ErrPollInvalid)
+		}
+		options = append(options, option)
+	}
+	if len(options) < consts.PollMinOptions || len(options) > consts.PollMaxOptions {
+		return model.MessagePoll{}, fmt.Errorf("%w: a poll needs %d to %d options", ErrPollInvalid, consts.PollMinOptions, consts.PollMaxOptions)
+	}
+
+	if input.ClosesAt != nil {
+		if !input.ClosesAt.After(now) {
+			return model.MessagePoll{}, fmt.Errorf("%w: closes_at must be in the future", ErrPollInvalid)
+		}
+		if input.ClosesAt.After(now.Add(consts.PollMaxDuration)) {
+			return model.MessagePoll{}, fmt.Errorf("%w: closes_at must be within %s", ErrPollInvalid, consts.PollMaxDuration)
+		}
+	}
+
+	return model.MessagePoll{
+		Question:       question,
+		Options:        options,
+		MultipleChoice: input.MultipleChoice,
+		Anonymous:      input.Anonymous,
+		ClosesAt:       input.ClosesAt,
+		Votes:          []model.PollVote{},
+	}, nil
+}
+
+// 選択肢の重複を取り除いて並べ替える。単一選択の投票では1つだけ選べる
+func validatePollVote(poll model.MessagePoll, options []int) ([]int, error) {
+	unique := []int{}
+	for _, option := range options {
+		if option < 0 || option >= len(poll.Options) {
+			return nil, fmt.Errorf("%w: option %d does not exist", ErrPollInvalidVote, option)
+		}
+		if !slices.Contains(unique, option) {
+			unique = append(unique, option)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("%w: choose at least one option", ErrPollInvalidVote)
+	}
+	if !poll.MultipleChoice && len(unique) > 1 {
+		return nil, fmt.Errorf("%w: this poll allows only one option", ErrPollInvalidVote)
+	}
+	slices.Sort(unique)
+	return unique, nil
+}
+
+func pollClosed(poll model.MessagePoll, now time.Time) bool {
+	return poll.ClosedAt != nil || (poll.ClosesAt != nil && !poll.ClosesAt.After(now))
+}
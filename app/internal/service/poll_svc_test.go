@@ -0,0 +1,227 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPollCreate(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	room := model.Room{ID: primitive.NewObjectID(), MessageTTL: 60}
+	closesAt := now.Add(time.Hour)
+	past := now.Add(-time.Second)
+	tooLate := now.Add(consts.PollMaxDuration + time.Second)
+
+	tests := []struct {
+		name        string
+		input       PollInput
+		sendErr     error
+		expectedErr error
+		expectPoll  *model.MessagePoll
+	}{
+		{
+			name:  "success",
+			input: PollInput{Question: " Lunch? ", Options: []string{" Sushi", "Ramen "}, MultipleChoice: true, Anonymous: true, ClosesAt: &closesAt},
+			expectPoll: &model.MessagePoll{
+				Question:       "Lunch?",
+				Options:        []string{"Sushi", "Ramen"},
+				MultipleChoice: true,
+				Anonymous:      true,
+				ClosesAt:       &closesAt,
+				Votes:          []model.PollVote{},
+			},
+		},
+		{name: "no question", input: PollInput{Question: " ", Options: []string{"a", "b"}}, expectedErr: ErrPollInvalid},
+		{name: "question too long", input: PollInput{Question: strings.Repeat("あ", consts.PollQuestionMaxLength+1), Options: []string{"a", "b"}}, expectedErr: ErrPollInvalid},
+		{name: "too few options", input: PollInput{Question: "q", Options: []string{"a"}}, expectedErr: ErrPollInvalid},
+		{name: "too many options", input: PollInput{Question: "q", Options: strings.Split("abcdefghijk", "")}, expectedErr: ErrPollInvalid},
+		{name: "empty option", input: PollInput{Question: "q", Options: []string{"a", " "}}, expectedErr: ErrPollInvalid},
+		{name: "option too long", input: PollInput{Question: "q", Options: []string{"a", strings.Repeat("b", consts.PollOptionMaxLength+1)}}, expectedErr: ErrPollInvalid},
+		{name: "duplicated options", input: PollInput{Question: "q", Options: []string{"a", "a "}}, expectedErr: ErrPollInvalid},
+		{name: "closes in the past", input: PollInput{Question: "q", Options: []string{"a", "b"}, ClosesAt: &past}, expectedErr: ErrPollInvalid},
+		{name: "closes too late", input: PollInput{Question: "q", Options: []string{"a", "b"}, ClosesAt: &tooLate}, expectedErr: ErrPollInvalid},
+		{
+			name:        "rejected as spam",
+			input:       PollInput{Question: "buy now", Options: []string{"a", "b"}},
+			sendErr:     ErrSpamRejected,
+			expectedErr: ErrSpamRejected,
+			expectPoll:  &model.MessagePoll{Question: "buy now", Options: []string{"a", "b"}, Votes: []model.PollVote{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sendSvc := &sendSvcStub{id: "message-id", err: tt.sendErr}
+
+			svc := NewPollSvc(nil, sendSvc, nil, dto.NewMessageDtoStruct(), atylabclock.NewClockMock(now))
+			messageID, err := svc.Create(tt.input, room, "user1", nil, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "message-id", messageID)
+			}
+
+			if tt.expectPoll == nil {
+				assert.Empty(t, sendSvc.messages)
+				return
+			}
+			assert.Equal(t, []model.Message{{
+				RoomID:        room.ID.Hex(),
+				Sender:        "user1",
+				Message:       tt.expectPoll.Question,
+				CreatedAt:     now,
+				IsReadUserIds: []string{"user1"},
+				ExpiresAt:     MessageExpiresAt(0, room, now),
+				Type:          consts.MessageTypes.Poll,
+				Poll:          tt.expectPoll,
+			}}, sendSvc.messages)
+		})
+	}
+}
+
+func TestPollVote(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Second)
+	messageID := primitive.NewObjectID()
+	poll := model.MessagePoll{Question: "Lunch?", Options: []string{"Sushi", "Ramen", "Curry"}}
+	multiple := poll
+	multiple.MultipleChoice = true
+	expired := poll
+	expired.ClosesAt = &past
+	closed := poll
+	closed.ClosedAt = &past
+	updated := multiple
+	updated.Votes = []model.PollVote{{UserID: "user1", Options: []int{0, 2}}, {UserID: "user2", Options: []int{2}}}
+
+	tests := []struct {
+		name          string
+		poll          *model.MessagePoll
+		options       []int
+		voted         bool
+		voteErr       error
+		getErr        error
+		publishErr    error
+		expectedErr   error
+		expectOptions []int
+		expectEvent   bool
+	}{
+		{name: "single choice", poll: &poll, options: []int{1}, voted: true, expectOptions: []int{1}, expectEvent: true},
+		{name: "multiple choice", poll: &multiple, options: []int{2, 0, 2}, voted: true, expectOptions: []int{0, 2}, expectEvent: true},
+		{name: "publish error is ignored", poll: &poll, options: []int{1}, voted: true, publishErr: assert.AnError, expectOptions: []int{1}, expectEvent: true},
+		{name: "not a poll", options: []int{0}, expectedErr: ErrNotPoll},
+		{name: "closed", poll: &closed, options: []int{0}, expectedErr: ErrPollClosed},
+		{name: "past close time", poll: &expired, options: []int{0}, expectedErr: ErrPollClosed},
+		{name: "multiple options on single choice", poll: &poll, options: []int{0, 1}, expectedErr: ErrPollInvalidVote},
+		{name: "unknown option", poll: &poll, options: []int{3}, expectedErr: ErrPollInvalidVote},
+		{name: "negative option", poll: &poll, options: []int{-1}, expectedErr: ErrPollInvalidVote},
+		{name: "no option", poll: &poll, options: []int{}, expectedErr: ErrPollInvalidVote},
+		{name: "closed concurrently", poll: &poll, options: []int{0}, voted: false, expectedErr: ErrPollClosed, expectOptions: []int{0}},
+		{name: "vote error", poll: &poll, options: []int{0}, voteErr: assert.AnError, expectedErr: assert.AnError, expectOptions: []int{0}},
+		{name: "get error", poll: &poll, options: []int{0}, voted: true, getErr: assert.AnError, expectedErr: assert.AnError, expectOptions: []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := model.Message{ID: messageID, RoomID: "room1", Sender: "user1", Poll: tt.poll}
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("VotePoll", messageID.Hex(), "room1", mock.Anything, mock.Anything).Return(tt.voted, tt.voteErr)
+			messageSvcMock.On("GetMessage", messageID.Hex(), "room1", mock.Anything).Return(model.Message{ID: messageID, RoomID: "room1", Poll: &updated}, tt.getErr)
+			eventSvc := &eventSvcStub{err: tt.publishErr}
+
+			svc := NewPollSvc(messageSvcMock, nil, eventSvc, dto.NewMessageDtoStruct(), atylabclock.NewClockMock(now))
+			result, err := svc.Vote(message, "user2", tt.options, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, updated, result)
+			}
+
+			if tt.expectOptions == nil {
+				messageSvcMock.AssertNotCalled(t, "VotePoll", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				messageSvcMock.AssertCalled(t, "VotePoll", messageID.Hex(), "room1", model.PollVote{UserID: "user2", Options: tt.expectOptions, VotedAt: now}, mock.Anything)
+			}
+
+			if !tt.expectEvent {
+				assert.Empty(t, eventSvc.events)
+				return
+			}
+			// 読み直した投票を、投票者を問わない集計として知らせる
+			assert.Equal(t, []RoomEvent{{
+				Type:   consts.EventTypes.PollUpdated,
+				RoomID: "room1",
+				Data: pollUpdatedEvent{
+					MessageID: messageID.Hex(),
+					Poll:      dto.NewMessageDtoStruct().GetPollInfo(updated, ""),
+				},
+			}}, eventSvc.events)
+		})
+	}
+}
+
+func TestPollClose(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	messageID := primitive.NewObjectID()
+	poll := model.MessagePoll{Question: "Lunch?", Options: []string{"Sushi", "Ramen"}}
+	closed := poll
+	closed.ClosedAt = &now
+
+	tests := []struct {
+		name        string
+		poll        *model.MessagePoll
+		uuid        string
+		isAdmin     bool
+		closed      bool
+		closeErr    error
+		expectedErr error
+		expectClose bool
+	}{
+		{name: "creator", poll: &poll, uuid: "creator", closed: true, expectClose: true},
+		{name: "admin", poll: &poll, uuid: "admin", isAdmin: true, closed: true, expectClose: true},
+		{name: "other member", poll: &poll, uuid: "member", expectedErr: ErrPollCloseNotAllowed},
+		{name: "not a poll", uuid: "creator", expectedErr: ErrNotPoll},
+		{name: "already closed", poll: &closed, uuid: "creator", expectedErr: ErrPollClosed},
+		{name: "closed concurrently", poll: &poll, uuid: "creator", closed: false, expectedErr: ErrPollClosed, expectClose: true},
+		{name: "close error", poll: &poll, uuid: "creator", closeErr: assert.AnError, expectedErr: assert.AnError, expectClose: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := model.Message{ID: messageID, RoomID: "room1", Sender: "creator", Poll: tt.poll}
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("ClosePoll", messageID.Hex(), "room1", tt.uuid, now, mock.Anything).Return(tt.closed, tt.closeErr)
+			eventSvc := &eventSvcStub{}
+
+			svc := NewPollSvc(messageSvcMock, nil, eventSvc, dto.NewMessageDtoStruct(), atylabclock.NewClockMock(now))
+			result, err := svc.Close(message, tt.uuid, tt.isAdmin, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, eventSvc.events)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, &now, result.ClosedAt)
+				assert.Equal(t, tt.uuid, result.ClosedBy)
+				assert.Len(t, eventSvc.events, 1)
+				assert.True(t, eventSvc.events[0].Data.(pollUpdatedEvent).Poll.Closed)
+			}
+
+			if tt.expectClose {
+				messageSvcMock.AssertNumberOfCalls(t, "ClosePoll", 1)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "ClosePoll", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
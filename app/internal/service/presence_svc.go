@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+// オンライン状態を更新し、heartbeat が途絶えたときにオフラインにできるよう期限を記録する
+// KEYS[1]: オンライン状態のキー KEYS[2]: 離席中のキー KEYS[3]: ステータスのテキストのキー KEYS[4]: オンラインのユーザーの一覧
+// ARGV[1]: オンラインとみなす時間(ms) ARGV[2]: UUID ARGV[3]: オンラインの期限(ms)
+// ARGV[4]: 離席中にする場合は 1、解除する場合は 0、変えない場合は空 ARGV[5]: テキストを変える場合は 1 ARGV[6]: テキスト
+// 戻り値: {状態, 変わった場合は 1, テキスト}
+const presenceUpdateScript = `
+if ARGV[4] == '1' then
+	redis.call('SET', KEYS[2], '1')
+elseif ARGV[4] == '0' then
+	redis.call('DEL', KEYS[2])
+end
+if ARGV[5] == '1' then
+	if ARGV[6] == '' then
+		redis.call('DEL', KEYS[3])
+	else
+		redis.call('SET', KEYS[3], ARGV[6])
+	end
+end
+local status = 'online'
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	status = 'away'
+end
+local prev = redis.call('GET', KEYS[1])
+redis.call('SET', KEYS[1], status, 'PX', ARGV[1])
+redis.call('ZADD', KEYS[4], ARGV[3], ARGV[2])
+local changed = 1
+if prev == status then
+	changed = 0
+end
+return {status, changed, redis.call('GET', KEYS[3]) or ''}
+`
+
+// オンライン状態とステータスのテキストをまとめて読み出す
+// KEYS: ユーザーごとに {オンライン状態のキー, テキストのキー}
+// 戻り値: {状態, テキスト, 状態, テキスト, ...}（ないものは空）
+const presenceGetScript = `
+local result = {}
+for i = 1, #KEYS, 2 do
+	table.insert(result, redis.call('GET', KEYS[i]) or '')
+	table.insert(result, redis.call('GET', KEYS[i + 1]) or '')
+end
+return result
+`
+
+// オンラインの期限を過ぎたユーザーを一覧から外して返す
+// 一覧の期限はアプリケーションの時計で、オンライン状態のキーの期限は Redis の時計で数えるので、
+// オンライン状態のキーが残っている場合は外さず、キーの残りの期限で一覧に戻す
+// 一覧から外したユーザーだけを返すので、複数のインスタンスで同時に実行しても1回だけ通知する
+// KEYS[1]: オンラインのユーザーの一覧 / ARGV[1]: 現在時刻(ms) ARGV[2]: 件数 ARGV[3]: オンライン状態のキーの接頭辞
+const presenceSweepScript = `
+local now = tonumber(ARGV[1])
+local expired = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now, 'LIMIT', 0, ARGV[2])
+local offline = {}
+for _, uuid in ipairs(expired) do
+	local ttl = redis.call('PTTL', ARGV[3] .. uuid)
+	if ttl > 0 then
+		redis.call('ZADD', KEYS[1], now + ttl, uuid)
+	else
+		redis.call('ZREM', KEYS[1], uuid)
+		table.insert(offline, uuid)
+	end
+end
+return offline
+`
+
+const presenceOnlineKey = "presence:online"
+
+const presenceKeyPrefix = "presence:"
+
+func presenceKey(uuid string) string {
+	return presenceKeyPrefix + uuid
+}
+
+func presenceAwayKey(uuid string) string {
+	return "presence:away:" + uuid
+}
+
+func presenceTextKey(uuid string) string {
+	return "presence:text:" + uuid
+}
+
+type PresenceSvcInterface interface {
+	Heartbeat(uuid string) (model.Presence, error)
+	SetStatus(uuid string, away bool, statusText string) (model.Presence, error)
+	GetPresences(uuids []string) (map[string]model.Presence, error)
+	RunNext() (bool, error)
+}
+
+type PresenceSvc struct {
+	redis        usecase.RedisUseCaseInterface
+	mongoRoomSvc mongo_svc.RoomSvcInterface
+	eventSvc     EventSvcInterface
+	clock        atylabclock.ClockInterface
+}
+
+func NewPresenceSvc(
+	redis usecase.RedisUseCaseInterface,
+	mongoRoomSvc mongo_svc.RoomSvcInterface,
+	eventSvc EventSvcInterface,
+	clock atylabclock.ClockInterface,
+) PresenceSvcInterface {
+	return &PresenceSvc{
+		redis:        redis,
+		mongoRoomSvc: mongoRoomSvc,
+		eventSvc:     eventSvc,
+		clock:        clock,
+	}
+}
+
+type presenceChangedEvent struct {
+	Uuid       string `json:"uuid"`
+	Status     string `json:"status"`
+	StatusText string `json:"status_text"`
+}
+
+// 接続中であることを記録する。オフラインからオンラインになった場合は参加しているルームに通知する
+func (s *PresenceSvc) Heartbeat(uuid string) (model.Presence, error) {
+	presence, changed, err := s.update(uuid, "", "", "")
+	if err != nil {
+		return model.Presence{}, err
+	}
+	if changed {
+		s.broadcast(uuid, presence)
+	}
+	return presence, nil
+}
+
+// 離席中かどうかとステータスのテキストを設定し、参加しているルームに通知する
+// 設定した操作も接続中であることを表すので、heartbeat としても扱う
+func (s *PresenceSvc) SetStatus(uuid string, away bool, statusText string) (model.Presence, error) {
+	awayFlag := "0"
+	if away {
+		awayFlag = "1"
+	}
+	presence, _, err := s.update(uuid, awayFlag, "1", statusText)
+	if err != nil {
+		return model.Presence{}, err
+	}
+	s.broadcast(uuid, presence)
+	return presence, nil
+}
+
+func (s *PresenceSvc) update(uuid string, awayFlag string, updateText string, statusText string) (model.Presence, bool, error) {
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return model.Presence{}, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	raw, err := redis.Cmd.Eval(
+		ctx,
+		presenceUpdateScript,
+		[]string{presenceKey(uuid), presenceAwayKey(uuid), presenceTextKey(uuid), presenceOnlineKey},
+		consts.PresenceTTL.Milliseconds(),
+		uuid,
+		s.clock.Now().Add(consts.PresenceTTL).UnixMilli(),
+		awayFlag,
+		updateText,
+		statusText,
+	)
+	if err != nil {
+		return model.Presence{}, false, err
+	}
+
+	result, ok := raw.([]any)
+	if !ok || len(result) != 3 {
+		return model.Presence{}, false, fmt.Errorf("unexpected presence result: %v", raw)
+	}
+	status, statusOk := result[0].(string)
+	changed, changedOk := result[1].(int64)
+	text, textOk := result[2].(string)
+	if !statusOk || !changedOk || !textOk {
+		return model.Presence{}, false, fmt.Errorf("unexpected presence result: %v", raw)
+	}
+	return model.Presence{Status: status, StatusText: text}, changed == 1, nil
+}
+
+// ユーザーごとのオンライン状態を返す。heartbeat が途絶えたユーザーはオフラインになる
+func (s *PresenceSvc) GetPresences(uuids []string) (map[string]model.Presence, error) {
+	presences := make(map[string]model.Presence, len(uuids))
+	if len(uuids) == 0 {
+		return presences, nil
+	}
+
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(uuids)*2)
+	for _, uuid := range uuids {
+		keys = append(keys, presenceKey(uuid), presenceTextKey(uuid))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	raw, err := redis.Cmd.Eval(ctx, presenceGetScript, keys)
+	if err != nil {
+		return nil, err
+	}
+	values, err := redisStrings(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(keys) {
+		return nil, fmt.Errorf("unexpected presence result: %v", raw)
+	}
+
+	for i, uuid := range uuids {
+		status := values[i*2]
+		if status == "" {
+			status = consts.PresenceStatuses.Offline
+		}
+		presences[uuid] = model.Presence{
+			Status:     status,
+			StatusText: values[i*2+1],
+		}
+	}
+	return presences, nil
+}
+
+// heartbeat が途絶えたユーザーをオフラインとして、参加しているルームに通知する
+func (s *PresenceSvc) RunNext() (bool, error) {
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	raw, err := redis.Cmd.Eval(
+		ctx,
+		presenceSweepScript,
+		[]string{presenceOnlineKey},
+		s.clock.Now().UnixMilli(),
+		consts.PresenceSweepBatchSize,
+		presenceKeyPrefix,
+	)
+	if err != nil {
+		return false, err
+	}
+	uuids, err := redisStrings(raw)
+	if err != nil {
+		return false, err
+	}
+	if len(uuids) == 0 {
+		return false, nil
+	}
+
+	presences, err := s.GetPresences(uuids)
+	if err != nil {
+		return false, err
+	}
+	for _, uuid := range uuids {
+		presence := presences[uuid]
+		// 一覧から外す前に heartbeat が届いていた場合はオンラインのまま
+		if presence.Status != consts.PresenceStatuses.Offline {
+			continue
+		}
+		s.broadcast(uuid, presence)
+	}
+	return true, nil
+}
+
+// 参加しているルームのメンバーにオンライン状態の変化を通知する
+// 通知できなくても、クライアントはメンバー一覧を取得し直せば最新の状態を得られる
+func (s *PresenceSvc) broadcast(uuid string, presence model.Presence) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	rooms, err := s.mongoRoomSvc.GetRoomList(uuid, "joined", ctx)
+	if err != nil {
+		fmt.Println("Failed to get rooms for presence:", err)
+		return
+	}
+	for _, room := range rooms {
+		_, err := s.eventSvc.Publish(RoomEvent{
+			Type:   consts.EventTypes.PresenceChanged,
+			RoomID: room.ID.Hex(),
+			Data: presenceChangedEvent{
+				Uuid:       uuid,
+				Status:     presence.Status,
+				StatusText: presence.StatusText,
+			},
+		})
+		if err != nil {
+			fmt.Println("Failed to publish presence event:", err)
+		}
+	}
+}
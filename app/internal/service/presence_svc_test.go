@@ -0,0 +1,215 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func setupPresenceRooms(uuid string, rooms ...model.Room) *mongo_svc_mock.RoomSvcMock {
+	roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+	roomSvcMock.On("GetRoomList", uuid, "joined", mock.Anything).Return(rooms, nil)
+	return roomSvcMock
+}
+
+func TestPresenceHeartbeat(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	room1 := model.Room{ID: primitive.NewObjectID()}
+	room2 := model.Room{ID: primitive.NewObjectID()}
+	events := &eventSvcStub{}
+	svc := NewPresenceSvc(setupMiniRedis(t), setupPresenceRooms("uuid1", room1, room2), events, atylabclock.NewClockMock(now))
+
+	presence, err := svc.Heartbeat("uuid1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Presence{Status: consts.PresenceStatuses.Online}, presence)
+
+	// オフラインからオンラインになったことを参加しているルームに通知する
+	assert.Len(t, events.events, 2)
+	assert.Equal(t, consts.EventTypes.PresenceChanged, events.events[0].Type)
+	assert.Equal(t, room1.ID.Hex(), events.events[0].RoomID)
+	assert.Equal(t, room2.ID.Hex(), events.events[1].RoomID)
+	assert.Equal(t, presenceChangedEvent{Uuid: "uuid1", Status: "online"}, events.events[0].Data)
+
+	// 状態が変わらなければ通知しない
+	_, err = svc.Heartbeat("uuid1")
+	assert.NoError(t, err)
+	assert.Len(t, events.events, 2)
+}
+
+func TestPresenceSetStatus(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	room := model.Room{ID: primitive.NewObjectID()}
+	events := &eventSvcStub{}
+	svc := NewPresenceSvc(setupMiniRedis(t), setupPresenceRooms("uuid1", room), events, atylabclock.NewClockMock(now))
+
+	presence, err := svc.SetStatus("uuid1", true, "In a meeting")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Presence{Status: "away", StatusText: "In a meeting"}, presence)
+	assert.Len(t, events.events, 1)
+	assert.Equal(t, presenceChangedEvent{Uuid: "uuid1", Status: "away", StatusText: "In a meeting"}, events.events[0].Data)
+
+	// 離席中の設定は heartbeat では解除されない
+	presence, err = svc.Heartbeat("uuid1")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Presence{Status: "away", StatusText: "In a meeting"}, presence)
+
+	// テキストを空にすると消える
+	presence, err = svc.SetStatus("uuid1", false, "")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Presence{Status: "online"}, presence)
+	assert.Len(t, events.events, 2)
+}
+
+func TestPresenceGetPresences(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	redisMock, mr := setupMiniRedisServer(t)
+	svc := NewPresenceSvc(redisMock, setupPresenceRooms("uuid1"), &eventSvcStub{}, atylabclock.NewClockMock(now))
+
+	_, err := svc.SetStatus("uuid1", false, "Working remotely")
+	assert.NoError(t, err)
+
+	presences, err := svc.GetPresences([]string{"uuid1", "uuid2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]model.Presence{
+		"uuid1": {Status: "online", StatusText: "Working remotely"},
+		"uuid2": {Status: "offline"},
+	}, presences)
+
+	// heartbeat が途絶えるとオフラインになるが、テキストは残る
+	mr.FastForward(consts.PresenceTTL)
+	presences, err = svc.GetPresences([]string{"uuid1"})
+	assert.NoError(t, err)
+	assert.Equal(t, model.Presence{Status: "offline", StatusText: "Working remotely"}, presences["uuid1"])
+
+	presences, err = svc.GetPresences([]string{})
+	assert.NoError(t, err)
+	assert.Empty(t, presences)
+}
+
+func TestPresenceRunNext(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	room := model.Room{ID: primitive.NewObjectID()}
+	redisMock, mr := setupMiniRedisServer(t)
+	roomSvcMock := setupPresenceRooms("uuid1", room)
+	roomSvcMock.On("GetRoomList", "uuid2", "joined", mock.Anything).Return([]model.Room{room}, nil)
+
+	_, err := NewPresenceSvc(redisMock, roomSvcMock, &eventSvcStub{}, atylabclock.NewClockMock(now)).Heartbeat("uuid1")
+	assert.NoError(t, err)
+	_, err = NewPresenceSvc(redisMock, roomSvcMock, &eventSvcStub{}, atylabclock.NewClockMock(now.Add(30*time.Second))).Heartbeat("uuid2")
+	assert.NoError(t, err)
+
+	// 期限前はオフラインにしない
+	events := &eventSvcStub{}
+	processed, err := NewPresenceSvc(redisMock, roomSvcMock, events, atylabclock.NewClockMock(now.Add(time.Second))).RunNext()
+	assert.NoError(t, err)
+	assert.False(t, processed)
+
+	// uuid1 の期限だけが過ぎている
+	mr.FastForward(consts.PresenceTTL)
+	sweeper := NewPresenceSvc(redisMock, roomSvcMock, events, atylabclock.NewClockMock(now.Add(consts.PresenceTTL)))
+	processed, err = sweeper.RunNext()
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	assert.Len(t, events.events, 1)
+	assert.Equal(t, presenceChangedEvent{Uuid: "uuid1", Status: "offline"}, events.events[0].Data)
+
+	// 通知したユーザーは一覧から外れているので、重ねて通知しない
+	processed, err = sweeper.RunNext()
+	assert.NoError(t, err)
+	assert.False(t, processed)
+	assert.Len(t, events.events, 1)
+}
+
+func TestPresenceRunNextClockSkew(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	room := model.Room{ID: primitive.NewObjectID()}
+	redisMock, mr := setupMiniRedisServer(t)
+	roomSvcMock := setupPresenceRooms("uuid1", room)
+
+	_, err := NewPresenceSvc(redisMock, roomSvcMock, &eventSvcStub{}, atylabclock.NewClockMock(now)).Heartbeat("uuid1")
+	assert.NoError(t, err)
+
+	// アプリケーションの時計では期限を過ぎていても、オンライン状態のキーが残っていればオフラインにしない
+	events := &eventSvcStub{}
+	skewed := now.Add(consts.PresenceTTL + time.Second)
+	processed, err := NewPresenceSvc(redisMock, roomSvcMock, events, atylabclock.NewClockMock(skewed)).RunNext()
+	assert.NoError(t, err)
+	assert.False(t, processed)
+	assert.Empty(t, events.events)
+	score, err := mr.ZScore(presenceOnlineKey, "uuid1")
+	assert.NoError(t, err)
+	assert.Equal(t, float64(skewed.Add(consts.PresenceTTL).UnixMilli()), score)
+
+	// キーの期限が切れたら、一覧に戻した期限でオフラインにする
+	mr.FastForward(consts.PresenceTTL)
+	processed, err = NewPresenceSvc(redisMock, roomSvcMock, events, atylabclock.NewClockMock(skewed.Add(consts.PresenceTTL))).RunNext()
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	assert.Len(t, events.events, 1)
+	assert.Equal(t, presenceChangedEvent{Uuid: "uuid1", Status: "offline"}, events.events[0].Data)
+}
+
+func TestPresenceError(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("init error", func(t *testing.T) {
+		redisMock := new(usecase_mock.RedisUseCaseMock)
+		redisMock.On("RedisInit").Return(&usecase.Redis{}, assert.AnError)
+		svc := NewPresenceSvc(redisMock, nil, nil, atylabclock.NewClockMock(now))
+
+		_, err := svc.Heartbeat("uuid1")
+		assert.Error(t, err)
+		_, err = svc.SetStatus("uuid1", true, "")
+		assert.Error(t, err)
+		_, err = svc.GetPresences([]string{"uuid1"})
+		assert.Error(t, err)
+		_, err = svc.RunNext()
+		assert.Error(t, err)
+	})
+
+	tests := map[string]struct {
+		result any
+		err    error
+	}{
+		"eval error":        {result: nil, err: assert.AnError},
+		"unexpected result": {result: int64(1), err: nil},
+		"unexpected items":  {result: []any{int64(1), "a", int64(2)}, err: nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmdMock := new(usecase_mock.RedisCmdMock)
+			cmdMock.On("Eval", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.result, tt.err)
+			redisMock := new(usecase_mock.RedisUseCaseMock)
+			redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, nil)
+			svc := NewPresenceSvc(redisMock, nil, nil, atylabclock.NewClockMock(now))
+
+			_, err := svc.Heartbeat("uuid1")
+			assert.Error(t, err)
+			_, err = svc.GetPresences([]string{"uuid1"})
+			assert.Error(t, err)
+			_, err = svc.RunNext()
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("broadcast error", func(t *testing.T) {
+		roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+		roomSvcMock.On("GetRoomList", "uuid1", "joined", mock.Anything).Return([]model.Room{}, assert.AnError)
+		events := &eventSvcStub{}
+
+		// 通知できなくても状態は更新する
+		presence, err := NewPresenceSvc(setupMiniRedis(t), roomSvcMock, events, atylabclock.NewClockMock(now)).Heartbeat("uuid1")
+		assert.NoError(t, err)
+		assert.Equal(t, "online", presence.Status)
+		assert.Empty(t, events.events)
+	})
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+)
+
+// スライディングウィンドウ方式で全てのキーを判定し、全て上限内であれば記録する
+// KEYS: 判定するキー / ARGV[1]: 現在時刻(ms) ARGV[2]: ウィンドウ幅(ms) ARGV[3]: 記録する値 ARGV[4..]: 各キーの上限
+// 戻り値: {許可(1/0), キー1の件数, キー1の最古の時刻(ms), キー2の件数, ...}
+const rateLimitScript = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local allowed = 1
+for i, key in ipairs(KEYS) do
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+	if redis.call('ZCARD', key) >= tonumber(ARGV[3 + i]) then
+		allowed = 0
+	end
+end
+local result = {allowed}
+for i, key in ipairs(KEYS) do
+	if allowed == 1 then
+		redis.call('ZADD', key, now, ARGV[3])
+		redis.call('PEXPIRE', key, window)
+	end
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	local oldestAt = now
+	if oldest[2] then
+		oldestAt = tonumber(oldest[2])
+	end
+	table.insert(result, redis.call('ZCARD', key))
+	table.insert(result, oldestAt)
+end
+return result
+`
+
+type RateLimitKey struct {
+	Key   string
+	Limit int
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration
+}
+
+type RateLimitSvcInterface interface {
+	Allow(keys []RateLimitKey, window time.Duration) (RateLimitResult, error)
+}
+
+type RateLimitSvc struct {
+	redis usecase.RedisUseCaseInterface
+	clock atylabclock.ClockInterface
+}
+
+func NewRateLimitSvc(
+	redis usecase.RedisUseCaseInterface,
+	clock atylabclock.ClockInterface,
+) RateLimitSvcInterface {
+	return &RateLimitSvc{
+		redis: redis,
+		clock: clock,
+	}
+}
+
+func (s *RateLimitSvc) Allow(keys []RateLimitKey, window time.Duration) (RateLimitResult, error) {
+	if len(keys) == 0 {
+		return RateLimitResult{Allowed: true}, nil
+	}
+
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return RateLimitResult{}, err
+	}
+
+	now := s.clock.Now()
+	nowMs := now.UnixMilli()
+	windowMs := window.Milliseconds()
+
+	redisKeys := make([]string, 0, len(keys))
+	args := []interface{}{
+		nowMs,
+		windowMs,
+		// 同一ミリ秒内のリクエストも別々に数えるため乱数を付与する
+		strconv.FormatInt(now.UnixNano(), 10) + "-" + strconv.FormatUint(rand.Uint64(), 36),
+	}
+	for _, key := range keys {
+		redisKeys = append(redisKeys, key.Key)
+		args = append(args, key.Limit)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	raw, err := redis.Cmd.Eval(ctx, rateLimitScript, redisKeys, args...)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	values, ok := raw.([]interface{})
+	if !ok || len(values) != 1+len(keys)*2 {
+		return RateLimitResult{}, fmt.Errorf("unexpected rate limit result: %v", raw)
+	}
+
+	result := RateLimitResult{
+		Allowed:   toInt64(values[0]) == 1,
+		Remaining: -1,
+	}
+	for i, key := range keys {
+		count := toInt64(values[1+i*2])
+		oldestAt := toInt64(values[2+i*2])
+		reset := time.Duration(oldestAt+windowMs-nowMs) * time.Millisecond
+
+		// ヘッダーには最も残りが少ないキーの情報を返す
+		remaining := max(key.Limit-int(count), 0)
+		if result.Remaining < 0 || remaining < result.Remaining {
+			result.Limit = key.Limit
+			result.Remaining = remaining
+			result.ResetAfter = reset
+		}
+
+		if !result.Allowed && int(count) >= key.Limit && reset > result.RetryAfter {
+			result.RetryAfter = reset
+		}
+	}
+
+	return result, nil
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int:
+		return int64(n)
+	case string:
+		i, _ := strconv.ParseInt(n, 10, 64)
+		return i
+	default:
+		return 0
+	}
+}
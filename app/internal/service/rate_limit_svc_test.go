@@ -0,0 +1,118 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRateLimitAllow(t *testing.T) {
+	redisMock := setupMiniRedis(t)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := []RateLimitKey{
+		{Key: "ratelimit:test:user:uuid", Limit: 5},
+		{Key: "ratelimit:test:room:room1:user:uuid", Limit: 2},
+	}
+
+	// 1件目: ルーム単位の残りが少ないのでそちらがヘッダーの対象になる
+	svc := NewRateLimitSvc(redisMock, atylabclock.NewClockMock(start))
+	result, err := svc.Allow(keys, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, time.Minute, result.ResetAfter)
+
+	// 2件目
+	svc = NewRateLimitSvc(redisMock, atylabclock.NewClockMock(start.Add(10*time.Second)))
+	result, err = svc.Allow(keys, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 50*time.Second, result.ResetAfter)
+
+	// 3件目: ルーム単位の上限に達しているので拒否され、最古の記録が消えるまで待つ必要がある
+	svc = NewRateLimitSvc(redisMock, atylabclock.NewClockMock(start.Add(20*time.Second)))
+	result, err = svc.Allow(keys, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 40*time.Second, result.RetryAfter)
+
+	// 拒否されたリクエストは記録されないので、別ルームではユーザー単位の残りが減っていない
+	svc = NewRateLimitSvc(redisMock, atylabclock.NewClockMock(start.Add(20*time.Second)))
+	result, err = svc.Allow([]RateLimitKey{
+		{Key: "ratelimit:test:user:uuid", Limit: 5},
+		{Key: "ratelimit:test:room:room2:user:uuid", Limit: 2},
+	}, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	// ウィンドウを過ぎると古い記録は除外される
+	svc = NewRateLimitSvc(redisMock, atylabclock.NewClockMock(start.Add(61*time.Second)))
+	result, err = svc.Allow(keys, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestRateLimitAllowNoKeys(t *testing.T) {
+	redisMock := new(usecase_mock.RedisUseCaseMock)
+	svc := NewRateLimitSvc(redisMock, atylabclock.NewClock())
+
+	result, err := svc.Allow([]RateLimitKey{}, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	redisMock.AssertNotCalled(t, "RedisInit")
+}
+
+func TestRateLimitAllowError(t *testing.T) {
+	keys := []RateLimitKey{{Key: "ratelimit:test:user:uuid", Limit: 5}}
+
+	expected := map[string]struct {
+		initErr    error
+		evalResult interface{}
+		evalErr    error
+	}{
+		"redis_init_error": {
+			initErr: assert.AnError,
+		},
+		"eval_error": {
+			evalErr: assert.AnError,
+		},
+		"unexpected_result": {
+			evalResult: "unexpected",
+		},
+		"unexpected_length": {
+			evalResult: []interface{}{int64(1)},
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			cmdMock := new(usecase_mock.RedisCmdMock)
+			cmdMock.On("Eval", mock.Anything, rateLimitScript, []string{"ratelimit:test:user:uuid"}, mock.Anything).
+				Return(tt.evalResult, tt.evalErr)
+
+			redisMock := new(usecase_mock.RedisUseCaseMock)
+			redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, tt.initErr)
+
+			svc := NewRateLimitSvc(redisMock, atylabclock.NewClock())
+			_, err := svc.Allow(keys, time.Minute)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestToInt64(t *testing.T) {
+	assert.Equal(t, int64(3), toInt64(int64(3)))
+	assert.Equal(t, int64(3), toInt64(3))
+	assert.Equal(t, int64(3), toInt64("3"))
+	assert.Equal(t, int64(0), toInt64(nil))
+}
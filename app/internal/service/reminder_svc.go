@@ -0,0 +1,324 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInvalidRemindAt      = fmt.Errorf("remind time must be in the future and within %d days", int(consts.ReminderMaxAhead.Hours()/24))
+	ErrReminderInvalid      = errors.New("invalid reminder")
+	ErrReminderLimitReached = fmt.Errorf("you can have at most %d reminders", consts.ReminderMaxPending)
+	ErrReminderNotPending   = errors.New("reminder has already been delivered or canceled")
+	ErrReminderNotSnoozable = errors.New("reminder is being delivered or has been canceled")
+)
+
+// 1回の確認で取得するリマインダーの数（他のインスタンスに先を越された場合の候補）
+const reminderBatchSize = 10
+
+// 通知する日時。At か In（"2h" や "3d" など現在からの期間）のどちらか一方を指定する
+type ReminderTime struct {
+	At *time.Time
+	In string
+}
+
+type ReminderSvcInterface interface {
+	Create(reminder model.Reminder, when ReminderTime, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error)
+	Snooze(reminder model.Reminder, when ReminderTime, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error)
+	Cancel(reminder model.Reminder, ctx *atylabmongo.MongoCtxSvc) error
+	RunNext() (bool, error)
+}
+
+type ReminderSvc struct {
+	reminderSvc     mongo_svc.ReminderSvcInterface
+	mongoRoomSvc    mongo_svc.RoomSvcInterface
+	mongoMessageSvc mongo_svc.MessageSvcInterface
+	eventSvc        EventSvcInterface
+	messageDto      dto.MessageDtoInterface
+	clock           atylabclock.ClockInterface
+}
+
+func NewReminderSvc(
+	reminderSvc mongo_svc.ReminderSvcInterface,
+	mongoRoomSvc mongo_svc.RoomSvcInterface,
+	mongoMessageSvc mongo_svc.MessageSvcInterface,
+	eventSvc EventSvcInterface,
+	messageDto dto.MessageDtoInterface,
+	clock atylabclock.ClockInterface,
+) ReminderSvcInterface {
+	return &ReminderSvc{
+		reminderSvc:     reminderSvc,
+		mongoRoomSvc:    mongoRoomSvc,
+		mongoMessageSvc: mongoMessageSvc,
+		eventSvc:        eventSvc,
+		messageDto:      messageDto,
+		clock:           clock,
+	}
+}
+
+// 内容と通知日時、件数を確認してリマインダーを設定する
+// メッセージに設定する場合は、呼び出し側で RoomID・MessageID・Snapshot を埋めておく（内容は省略できる）
+func (s *ReminderSvc) Create(reminder model.Reminder, when ReminderTime, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error) {
+	now := s.clock.Now()
+	remindAt, err := reminderTime(when, now)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+
+	reminder.Text = strings.TrimSpace(reminder.Text)
+	if reminder.MessageID == "" && reminder.Text == "" {
+		return model.Reminder{}, fmt.Errorf("%w: text is required", ErrReminderInvalid)
+	}
+	if utf8.RuneCountInString(reminder.Text) > consts.ReminderTextMaxLength {
+		return model.Reminder{}, fmt.Errorf("%w: text must be at most %d characters", ErrReminderInvalid, consts.ReminderTextMaxLength)
+	}
+
+	pending, err := s.reminderSvc.GetReminders(reminder.UserID, ctx)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+	if len(pending) >= consts.ReminderMaxPending {
+		return model.Reminder{}, ErrReminderLimitReached
+	}
+
+	reminder.RemindAt = remindAt
+	reminder.Status = consts.ReminderStatus.Pending
+	reminder.Attempts = 0
+	reminder.AvailableAt = remindAt
+	reminder.CreatedAt = now
+	reminder.UpdatedAt = now
+	reminderID, err := s.reminderSvc.CreateReminder(reminder, ctx)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+	reminder.ID, err = primitive.ObjectIDFromHex(reminderID)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+	return reminder, nil
+}
+
+// 通知日時を変更する。通知済みのリマインダーは、指定した日時にもう一度通知する
+func (s *ReminderSvc) Snooze(reminder model.Reminder, when ReminderTime, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error) {
+	now := s.clock.Now()
+	remindAt, err := reminderTime(when, now)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+
+	snoozed, err := s.reminderSvc.SnoozeReminder(reminder.ID.Hex(), reminder.UserID, remindAt, now, ctx)
+	if err != nil {
+		return model.Reminder{}, err
+	}
+	if !snoozed {
+		return model.Reminder{}, ErrReminderNotSnoozable
+	}
+
+	reminder.RemindAt = remindAt
+	reminder.Status = consts.ReminderStatus.Pending
+	reminder.Attempts = 0
+	reminder.AvailableAt = remindAt
+	reminder.LastError = ""
+	reminder.UpdatedAt = now
+	return reminder, nil
+}
+
+func (s *ReminderSvc) Cancel(reminder model.Reminder, ctx *atylabmongo.MongoCtxSvc) error {
+	canceled, err := s.reminderSvc.CancelReminder(reminder.ID.Hex(), reminder.UserID, s.clock.Now(), ctx)
+	if err != nil {
+		return err
+	}
+	if !canceled {
+		return ErrReminderNotPending
+	}
+	return nil
+}
+
+// 通知日時を過ぎたリマインダーを1件確保して通知用ルームに届ける
+// 届けたリマインダーがなければ false を返す
+func (s *ReminderSvc) RunNext() (bool, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	due, err := s.reminderSvc.GetDueReminders(now, reminderBatchSize, ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, reminder := range due {
+		leased, err := s.reminderSvc.LeaseReminder(reminder, now, consts.ReminderLease, ctx)
+		if err != nil {
+			return false, err
+		}
+		if !leased {
+			continue
+		}
+
+		reminder.Status = consts.ReminderStatus.Sending
+		reminder.Attempts++
+		messageID, err := s.deliver(reminder)
+		return true, s.finish(reminder, messageID, err)
+	}
+
+	return false, nil
+}
+
+// 本人だけが参加する通知用ルームに、システムのメッセージとして届ける
+// 他のメンバーに見せるものではないので、スパム判定や Webhook の配信は通さない
+func (s *ReminderSvc) deliver(reminder model.Reminder) (string, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	room, added, err := s.mongoRoomSvc.GetOrCreateRoomByExternalID(model.Room{
+		Name: consts.NotificationRoomName,
+		// 本人が他のユーザーを招待したりしないよう、管理者（作成者）はシステムにする
+		OwnerID:    consts.ReminderSender,
+		CreatedAt:  now,
+		Members:    []string{reminder.UserID},
+		IsPrivate:  true,
+		ExternalID: consts.NotificationRoomIDPrefix + reminder.UserID,
+	}, ctx)
+	if err != nil {
+		return "", err
+	}
+	// 通知用ルームを作成した（退室から戻した）場合は、本人の接続がルームの一覧を読み直す前に通知が届くようにする
+	for _, member := range added {
+		s.publish(RoomEvent{
+			Type:   consts.EventTypes.MemberJoined,
+			RoomID: room.ID.Hex(),
+			Data:   WebhookMemberData{UserID: member},
+			Member: member,
+		})
+	}
+
+	message := model.Message{
+		RoomID:        room.ID.Hex(),
+		Sender:        consts.ReminderSender,
+		Message:       reminderMessage(reminder),
+		CreatedAt:     now,
+		IsReadUserIds: []string{},
+		// 送信後に記録できずに再送しても二重に届けないようにする（スヌーズした場合は通知日時ごとに届ける）
+		ClientMsgID: fmt.Sprintf("reminder:%s:%d", reminder.ID.Hex(), reminder.RemindAt.Unix()),
+		ExpiresAt:   MessageExpiresAt(0, room, now),
+		Type:        consts.MessageTypes.Reminder,
+		Reminder: &model.MessageReminder{
+			ReminderID: reminder.ID.Hex(),
+			RoomID:     reminder.RoomID,
+			MessageID:  reminder.MessageID,
+		},
+	}
+	messageID, err := s.mongoMessageSvc.SendMessage(message, ctx)
+	if mongo.IsDuplicateKeyError(err) {
+		sent, err := s.mongoMessageSvc.FindByClientMsgID(message.RoomID, message.Sender, message.ClientMsgID, ctx)
+		if err != nil {
+			return "", err
+		}
+		return sent.ID.Hex(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if id, err := primitive.ObjectIDFromHex(messageID); err == nil {
+		message.ID = id
+		s.publish(RoomEvent{
+			Type:   consts.EventTypes.MessageCreated,
+			RoomID: message.RoomID,
+			Data:   s.messageDto.GetMessageInfo(message, ""),
+		})
+	}
+	return messageID, nil
+}
+
+// イベントを記録できなくても、リマインダーは届けたものとして扱う
+func (s *ReminderSvc) publish(event RoomEvent) {
+	if _, err := s.eventSvc.Publish(event); err != nil {
+		fmt.Println("Failed to publish reminder event:", err)
+	}
+}
+
+func (s *ReminderSvc) finish(reminder model.Reminder, messageID string, deliverErr error) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	switch {
+	case deliverErr == nil:
+		return s.reminderSvc.CompleteReminder(reminder, messageID, now, ctx)
+	case reminder.Attempts >= consts.ReminderMaxAttempts:
+		fmt.Println("Reminder failed:", reminder.ID.Hex(), deliverErr)
+		return s.reminderSvc.FailReminder(reminder, now, deliverErr.Error(), ctx)
+	default:
+		retryAt := now.Add(consts.ReminderRetryBase << (reminder.Attempts - 1))
+		return s.reminderSvc.RetryReminder(reminder, now, retryAt, deliverErr.Error(), ctx)
+	}
+}
+
+// 内容を省略したメッセージのリマインダーは、設定した時点のメッセージの本文を届ける
+func reminderMessage(reminder model.Reminder) string {
+	if reminder.Text != "" {
+		return reminder.Text
+	}
+	if reminder.Snapshot != "" {
+		return reminder.Snapshot
+	}
+	return "Reminder about a message"
+}
+
+func reminderTime(when ReminderTime, now time.Time) (time.Time, error) {
+	var remindAt time.Time
+	switch {
+	case when.At != nil && when.In != "":
+		return time.Time{}, fmt.Errorf("%w: specify either a time or a duration", ErrInvalidRemindAt)
+	case when.At != nil:
+		remindAt = *when.At
+	case when.In != "":
+		d, err := ParseReminderDuration(when.In)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidRemindAt, err)
+		}
+		remindAt = now.Add(d)
+	default:
+		return time.Time{}, fmt.Errorf("%w: a time or a duration is required", ErrInvalidRemindAt)
+	}
+
+	if !remindAt.After(now) || remindAt.After(now.Add(consts.ReminderMaxAhead)) {
+		return time.Time{}, ErrInvalidRemindAt
+	}
+	return remindAt, nil
+}
+
+// "90m" "2h" "1h30m" などの Go の形式に加え、日（"3d"）と週（"1w"）の単位を受け付ける
+func ParseReminderDuration(text string) (time.Duration, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(text, suffix); ok {
+			count, err := strconv.Atoi(n)
+			if err != nil || count <= 0 || time.Duration(count) > consts.ReminderMaxAhead/unit {
+				return 0, fmt.Errorf("invalid duration %q", text)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(text)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", text)
+	}
+	return d, nil
+}
@@ -0,0 +1,484 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/dto"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestParseReminderDuration(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"2h", 2 * time.Hour, false},
+		{"1h30m", 90 * time.Minute, false},
+		{" 90M ", 90 * time.Minute, false},
+		{"3d", 3 * 24 * time.Hour, false},
+		{"1w", 7 * 24 * time.Hour, false},
+		{"0m", 0, true},
+		{"-1h", 0, true},
+		{"0d", 0, true},
+		{"1.5d", 0, true},
+		{"99999999999w", 0, true},
+		{"tomorrow", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			d, err := ParseReminderDuration(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, d)
+		})
+	}
+}
+
+func TestCreateReminder(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reminderID := primitive.NewObjectID()
+	at := now.Add(time.Hour)
+	past := now.Add(-time.Minute)
+	tooLate := now.Add(consts.ReminderMaxAhead + time.Second)
+
+	tests := []struct {
+		name           string
+		reminder       model.Reminder
+		when           ReminderTime
+		pendingCount   int
+		getErr         error
+		createErr      error
+		expectedErr    error
+		expectRemindAt time.Time
+		expectCreate   bool
+	}{
+		{name: "in", reminder: model.Reminder{Text: " call Bob "}, when: ReminderTime{In: "2h"}, expectRemindAt: now.Add(2 * time.Hour), expectCreate: true},
+		{name: "at", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{At: &at}, pendingCount: 1, expectRemindAt: at, expectCreate: true},
+		{name: "message without text", reminder: model.Reminder{RoomID: "room1", MessageID: "message1", Snapshot: "review this"}, when: ReminderTime{In: "1d"}, expectRemindAt: now.Add(24 * time.Hour), expectCreate: true},
+		{name: "no text", reminder: model.Reminder{Text: " "}, when: ReminderTime{In: "2h"}, expectedErr: ErrReminderInvalid},
+		{name: "text too long", reminder: model.Reminder{Text: strings.Repeat("a", consts.ReminderTextMaxLength+1)}, when: ReminderTime{In: "2h"}, expectedErr: ErrReminderInvalid},
+		{name: "no time", reminder: model.Reminder{Text: "call Bob"}, expectedErr: ErrInvalidRemindAt},
+		{name: "both time and duration", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{At: &at, In: "2h"}, expectedErr: ErrInvalidRemindAt},
+		{name: "invalid duration", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{In: "soon"}, expectedErr: ErrInvalidRemindAt},
+		{name: "in the past", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{At: &past}, expectedErr: ErrInvalidRemindAt},
+		{name: "too far ahead", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{At: &tooLate}, expectedErr: ErrInvalidRemindAt},
+		{name: "limit reached", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{In: "2h"}, pendingCount: consts.ReminderMaxPending, expectedErr: ErrReminderLimitReached},
+		{name: "get error", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{In: "2h"}, getErr: assert.AnError, expectedErr: assert.AnError},
+		{name: "create error", reminder: model.Reminder{Text: "call Bob"}, when: ReminderTime{In: "2h"}, createErr: assert.AnError, expectedErr: assert.AnError, expectRemindAt: now.Add(2 * time.Hour), expectCreate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.reminder
+			input.UserID = "uuid"
+			expected := input
+			expected.Text = strings.TrimSpace(input.Text)
+			expected.RemindAt = tt.expectRemindAt
+			expected.Status = consts.ReminderStatus.Pending
+			expected.AvailableAt = tt.expectRemindAt
+			expected.CreatedAt = now
+			expected.UpdatedAt = now
+
+			reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("GetReminders", "uuid", mock.Anything).Return(make([]model.Reminder, tt.pendingCount), tt.getErr)
+			reminderSvcMock.On("CreateReminder", expected, mock.Anything).Return(reminderID.Hex(), tt.createErr)
+
+			svc := NewReminderSvc(reminderSvcMock, nil, nil, nil, nil, atylabclock.NewClockMock(now))
+			reminder, err := svc.Create(input, tt.when, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				expected.ID = reminderID
+				assert.Equal(t, expected, reminder)
+			}
+			if tt.expectCreate {
+				reminderSvcMock.AssertNumberOfCalls(t, "CreateReminder", 1)
+			} else {
+				reminderSvcMock.AssertNotCalled(t, "CreateReminder", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSnoozeReminder(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reminder := model.Reminder{ID: primitive.NewObjectID(), UserID: "uuid", Status: consts.ReminderStatus.Sent, Attempts: 1, LastError: "boom"}
+
+	tests := []struct {
+		name         string
+		when         ReminderTime
+		snoozed      bool
+		snoozeErr    error
+		expectedErr  error
+		expectSnooze bool
+	}{
+		{"success", ReminderTime{In: "10m"}, true, nil, nil, true},
+		{"invalid time", ReminderTime{In: "-10m"}, false, nil, ErrInvalidRemindAt, false},
+		{"canceled", ReminderTime{In: "10m"}, false, nil, ErrReminderNotSnoozable, true},
+		{"snooze error", ReminderTime{In: "10m"}, false, assert.AnError, assert.AnError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remindAt := now.Add(10 * time.Minute)
+			reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("SnoozeReminder", reminder.ID.Hex(), "uuid", remindAt, now, mock.Anything).Return(tt.snoozed, tt.snoozeErr)
+
+			svc := NewReminderSvc(reminderSvcMock, nil, nil, nil, nil, atylabclock.NewClockMock(now))
+			snoozed, err := svc.Snooze(reminder, tt.when, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, model.Reminder{
+					ID:          reminder.ID,
+					UserID:      "uuid",
+					RemindAt:    remindAt,
+					Status:      consts.ReminderStatus.Pending,
+					AvailableAt: remindAt,
+					UpdatedAt:   now,
+				}, snoozed)
+			}
+			if tt.expectSnooze {
+				reminderSvcMock.AssertNumberOfCalls(t, "SnoozeReminder", 1)
+			} else {
+				reminderSvcMock.AssertNotCalled(t, "SnoozeReminder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestCancelReminder(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reminder := model.Reminder{ID: primitive.NewObjectID(), UserID: "uuid"}
+
+	tests := []struct {
+		name        string
+		canceled    bool
+		cancelErr   error
+		expectedErr error
+	}{
+		{"success", true, nil, nil},
+		{"already delivered", false, nil, ErrReminderNotPending},
+		{"cancel error", false, assert.AnError, assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("CancelReminder", reminder.ID.Hex(), "uuid", now, mock.Anything).Return(tt.canceled, tt.cancelErr)
+
+			svc := NewReminderSvc(reminderSvcMock, nil, nil, nil, nil, atylabclock.NewClockMock(now))
+			err := svc.Cancel(reminder, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReminderRunNext(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	remindAt := now.Add(-time.Minute)
+	notificationRoom := model.Room{ID: primitive.NewObjectID(), Members: []string{"uuid"}}
+	duplicateErr := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000}}}
+	sentID := primitive.NewObjectID()
+	messageID := primitive.NewObjectID().Hex()
+
+	type expectation struct {
+		complete       bool
+		notificationID string
+		fail           bool
+		retryAt        time.Time
+	}
+
+	tests := []struct {
+		name        string
+		reminder    model.Reminder
+		attempts    int
+		added       []string
+		roomErr     error
+		sendErr     error
+		findErr     error
+		expectSend  bool
+		expectBody  string
+		expectFound bool
+		expect      expectation
+	}{
+		{
+			name:       "free text",
+			reminder:   model.Reminder{Text: "call Bob"},
+			expectSend: true,
+			expectBody: "call Bob",
+			expect:     expectation{complete: true, notificationID: messageID},
+		},
+		{
+			name:       "notification room created",
+			reminder:   model.Reminder{Text: "call Bob"},
+			added:      []string{"uuid"},
+			expectSend: true,
+			expectBody: "call Bob",
+			expect:     expectation{complete: true, notificationID: messageID},
+		},
+		{
+			name:       "message with note",
+			reminder:   model.Reminder{RoomID: "room1", MessageID: "message1", Snapshot: "review this", Text: "before lunch"},
+			expectSend: true,
+			expectBody: "before lunch",
+			expect:     expectation{complete: true, notificationID: messageID},
+		},
+		{
+			name:       "message without note",
+			reminder:   model.Reminder{RoomID: "room1", MessageID: "message1", Snapshot: "review this"},
+			expectSend: true,
+			expectBody: "review this",
+			expect:     expectation{complete: true, notificationID: messageID},
+		},
+		{
+			name:       "message without text",
+			reminder:   model.Reminder{RoomID: "room1", MessageID: "message1"},
+			expectSend: true,
+			expectBody: "Reminder about a message",
+			expect:     expectation{complete: true, notificationID: messageID},
+		},
+		{
+			name:        "already delivered",
+			reminder:    model.Reminder{Text: "call Bob"},
+			sendErr:     duplicateErr,
+			expectSend:  true,
+			expectBody:  "call Bob",
+			expectFound: true,
+			expect:      expectation{complete: true, notificationID: sentID.Hex()},
+		},
+		{
+			name:        "lookup error after duplicate",
+			reminder:    model.Reminder{Text: "call Bob"},
+			sendErr:     duplicateErr,
+			findErr:     assert.AnError,
+			expectSend:  true,
+			expectBody:  "call Bob",
+			expectFound: true,
+			expect:      expectation{retryAt: now.Add(consts.ReminderRetryBase)},
+		},
+		{
+			name:     "room error",
+			reminder: model.Reminder{Text: "call Bob"},
+			roomErr:  assert.AnError,
+			expect:   expectation{retryAt: now.Add(consts.ReminderRetryBase)},
+		},
+		{
+			name:       "send error",
+			reminder:   model.Reminder{Text: "call Bob"},
+			sendErr:    assert.AnError,
+			expectSend: true,
+			expectBody: "call Bob",
+			expect:     expectation{retryAt: now.Add(consts.ReminderRetryBase)},
+		},
+		{
+			name:       "send error backs off",
+			reminder:   model.Reminder{Text: "call Bob"},
+			attempts:   2,
+			sendErr:    assert.AnError,
+			expectSend: true,
+			expectBody: "call Bob",
+			expect:     expectation{retryAt: now.Add(consts.ReminderRetryBase * 4)},
+		},
+		{
+			name:       "send error after max attempts",
+			reminder:   model.Reminder{Text: "call Bob"},
+			attempts:   consts.ReminderMaxAttempts - 1,
+			sendErr:    assert.AnError,
+			expectSend: true,
+			expectBody: "call Bob",
+			expect:     expectation{fail: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due := tt.reminder
+			due.ID = primitive.NewObjectID()
+			due.UserID = "uuid"
+			due.RemindAt = remindAt
+			due.Status = consts.ReminderStatus.Pending
+			due.Attempts = tt.attempts
+			due.AvailableAt = remindAt
+			leased := due
+			leased.Status = consts.ReminderStatus.Sending
+			leased.Attempts = tt.attempts + 1
+			clientMsgID := "reminder:" + due.ID.Hex() + ":" + strconv.FormatInt(remindAt.Unix(), 10)
+
+			reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("GetDueReminders", now, reminderBatchSize, mock.Anything).Return([]model.Reminder{due}, nil)
+			reminderSvcMock.On("LeaseReminder", due, now, consts.ReminderLease, mock.Anything).Return(true, nil)
+			reminderSvcMock.On("CompleteReminder", leased, mock.Anything, now, mock.Anything).Return(nil)
+			reminderSvcMock.On("FailReminder", leased, now, mock.Anything, mock.Anything).Return(nil)
+			reminderSvcMock.On("RetryReminder", leased, now, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			roomSvcMock.On("GetOrCreateRoomByExternalID", model.Room{
+				Name:       consts.NotificationRoomName,
+				OwnerID:    consts.ReminderSender,
+				CreatedAt:  now,
+				Members:    []string{"uuid"},
+				IsPrivate:  true,
+				ExternalID: "notifications:uuid",
+			}, mock.Anything).Return(notificationRoom, append([]string{}, tt.added...), tt.roomErr)
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("SendMessage", mock.Anything, mock.Anything).Return(messageID, tt.sendErr)
+			messageSvcMock.On("FindByClientMsgID", notificationRoom.ID.Hex(), consts.ReminderSender, clientMsgID, mock.Anything).Return(model.Message{ID: sentID}, tt.findErr)
+
+			eventSvc := &eventSvcStub{}
+			svc := NewReminderSvc(reminderSvcMock, roomSvcMock, messageSvcMock, eventSvc, dto.NewMessageDtoStruct(), atylabclock.NewClockMock(now))
+			processed, err := svc.RunNext()
+			assert.NoError(t, err)
+			assert.True(t, processed)
+
+			// ルームに加わったことを先に知らせてから、新しく送信した通知を届ける
+			events := []string{}
+			for _, event := range eventSvc.events {
+				assert.Equal(t, notificationRoom.ID.Hex(), event.RoomID)
+				events = append(events, event.Type)
+				switch event.Type {
+				case consts.EventTypes.MemberJoined:
+					assert.Equal(t, "uuid", event.Member)
+				case consts.EventTypes.MessageCreated:
+					data := event.Data.(dto.MessageResponse)
+					assert.Equal(t, messageID, data.ID)
+					assert.Equal(t, tt.expectBody, data.Message)
+				}
+			}
+			expectEvents := []string{}
+			if len(tt.added) > 0 {
+				expectEvents = append(expectEvents, consts.EventTypes.MemberJoined)
+			}
+			if tt.expectSend && tt.sendErr == nil {
+				expectEvents = append(expectEvents, consts.EventTypes.MessageCreated)
+			}
+			assert.Equal(t, expectEvents, events)
+
+			if tt.expectSend {
+				messageSvcMock.AssertCalled(t, "SendMessage", model.Message{
+					RoomID:        notificationRoom.ID.Hex(),
+					Sender:        consts.ReminderSender,
+					Message:       tt.expectBody,
+					CreatedAt:     now,
+					IsReadUserIds: []string{},
+					ClientMsgID:   clientMsgID,
+					Type:          consts.MessageTypes.Reminder,
+					Reminder: &model.MessageReminder{
+						ReminderID: due.ID.Hex(),
+						RoomID:     tt.reminder.RoomID,
+						MessageID:  tt.reminder.MessageID,
+					},
+				}, mock.Anything)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+			}
+			if tt.expectFound {
+				messageSvcMock.AssertNumberOfCalls(t, "FindByClientMsgID", 1)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "FindByClientMsgID", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			switch {
+			case tt.expect.complete:
+				reminderSvcMock.AssertCalled(t, "CompleteReminder", leased, tt.expect.notificationID, now, mock.Anything)
+				reminderSvcMock.AssertNotCalled(t, "FailReminder", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				reminderSvcMock.AssertNotCalled(t, "RetryReminder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			case tt.expect.fail:
+				reminderSvcMock.AssertCalled(t, "FailReminder", leased, now, mock.Anything, mock.Anything)
+				reminderSvcMock.AssertNotCalled(t, "RetryReminder", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			default:
+				reminderSvcMock.AssertCalled(t, "RetryReminder", leased, now, tt.expect.retryAt, mock.Anything, mock.Anything)
+				reminderSvcMock.AssertNotCalled(t, "FailReminder", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestReminderRunNextLease(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := model.Reminder{ID: primitive.NewObjectID(), UserID: "uuid", Text: "first"}
+	second := model.Reminder{ID: primitive.NewObjectID(), UserID: "uuid", Text: "second"}
+
+	t.Run("nothing due", func(t *testing.T) {
+		reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+		reminderSvcMock.On("GetDueReminders", now, reminderBatchSize, mock.Anything).Return([]model.Reminder{}, nil)
+
+		processed, err := NewReminderSvc(reminderSvcMock, nil, nil, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+		reminderSvcMock.On("GetDueReminders", now, reminderBatchSize, mock.Anything).Return([]model.Reminder{}, assert.AnError)
+
+		processed, err := NewReminderSvc(reminderSvcMock, nil, nil, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("lease error", func(t *testing.T) {
+		reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+		reminderSvcMock.On("GetDueReminders", now, reminderBatchSize, mock.Anything).Return([]model.Reminder{first}, nil)
+		reminderSvcMock.On("LeaseReminder", first, now, consts.ReminderLease, mock.Anything).Return(false, assert.AnError)
+
+		processed, err := NewReminderSvc(reminderSvcMock, nil, nil, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("taken by other instances", func(t *testing.T) {
+		reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+		reminderSvcMock.On("GetDueReminders", now, reminderBatchSize, mock.Anything).Return([]model.Reminder{first, second}, nil)
+		reminderSvcMock.On("LeaseReminder", mock.Anything, now, consts.ReminderLease, mock.Anything).Return(false, nil)
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+
+		processed, err := NewReminderSvc(reminderSvcMock, nil, messageSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+		messageSvcMock.AssertNotCalled(t, "SendMessage", mock.Anything, mock.Anything)
+		reminderSvcMock.AssertNumberOfCalls(t, "LeaseReminder", 2)
+	})
+
+	t.Run("next reminder is leased when the first is taken", func(t *testing.T) {
+		reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+		reminderSvcMock.On("GetDueReminders", now, reminderBatchSize, mock.Anything).Return([]model.Reminder{first, second}, nil)
+		reminderSvcMock.On("LeaseReminder", first, now, consts.ReminderLease, mock.Anything).Return(false, nil)
+		reminderSvcMock.On("LeaseReminder", second, now, consts.ReminderLease, mock.Anything).Return(true, nil)
+		reminderSvcMock.On("CompleteReminder", mock.MatchedBy(func(r model.Reminder) bool {
+			return r.ID == second.ID
+		}), "message-id", now, mock.Anything).Return(nil)
+		roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+		roomSvcMock.On("GetOrCreateRoomByExternalID", mock.Anything, mock.Anything).Return(model.Room{ID: primitive.NewObjectID()}, []string{}, nil)
+		messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+		messageSvcMock.On("SendMessage", mock.MatchedBy(func(m model.Message) bool {
+			return m.Message == "second"
+		}), mock.Anything).Return("message-id", nil)
+
+		processed, err := NewReminderSvc(reminderSvcMock, roomSvcMock, messageSvcMock, &eventSvcStub{}, dto.NewMessageDtoStruct(), atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.True(t, processed)
+		messageSvcMock.AssertNumberOfCalls(t, "SendMessage", 1)
+	})
+}
@@ -17,6 +17,7 @@ type RoomSvcInterface interface {
 	GetRoom(roomId string, ctx *atylabmongo.MongoCtxSvc) (model.Room, error)
 	IsMember(room model.Room, uuid string) bool
 	IsOwner(room model.Room, uuid string) bool
+	IsBanned(room model.Room, uuid string) bool
 	GetMemberInfos(room model.Room, ctx *atylabapi.ApiCtxSvc) ([]model.RoomMember, error)
 }
 
@@ -50,6 +51,10 @@ func (s *RoomSvc) IsOwner(room model.Room, uuid string) bool {
 	return room.OwnerID == uuid
 }
 
+func (s *RoomSvc) IsBanned(room model.Room, uuid string) bool {
+	return slices.Contains(room.BannedMembers, uuid)
+}
+
 func (s *RoomSvc) GetMemberInfos(room model.Room, ctx *atylabapi.ApiCtxSvc) ([]model.RoomMember, error) {
 	rawJSON, err := s.getMemberInfos(room, ctx)
 	if err != nil {
@@ -59,6 +59,21 @@ func TestIsOwner(t *testing.T) {
 	assert.False(t, roomSvc.IsOwner(room, "otherUuid"))
 }
 
+func TestIsBanned(t *testing.T) {
+	mongo_svc_mock := new(mongo_svc_mock.RoomSvcMock)
+	api_mock := new(atylabapi.ApiPostStructMock)
+	redis := &usecase.RedisUseCaseStruct{}
+
+	roomSvc := NewRoomSvc(redis, mongo_svc_mock, api_mock)
+
+	room := model.Room{
+		BannedMembers: []string{"bannedUuid"},
+	}
+
+	assert.True(t, roomSvc.IsBanned(room, "bannedUuid"))
+	assert.False(t, roomSvc.IsBanned(room, "otherUuid"))
+}
+
 func createResultData(returnDataType string) []byte {
 	switch returnDataType {
 	case "all_hit":
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrInvalidSendAt              = fmt.Errorf("send_at must be in the future and within %d days", int(consts.ScheduledMessageMaxAhead.Hours()/24))
+	ErrScheduledLimitReached      = fmt.Errorf("you can have at most %d scheduled messages in a room", consts.ScheduledMessageMaxPending)
+	ErrScheduledMessageNotPending = errors.New("scheduled message has already been sent or canceled")
+)
+
+// 再送しても送信できる見込みがない失敗（ルームの削除・退室・スパム判定など）
+var errScheduledMessagePermanent = errors.New("scheduled message cannot be sent")
+
+// 1回の確認で取得する予約メッセージの数（他のインスタンスに先を越された場合の候補）
+const scheduledMessageBatchSize = 10
+
+type ScheduledMessageSvcInterface interface {
+	Schedule(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) (string, error)
+	Reschedule(scheduled model.ScheduledMessage, message string, sendAt time.Time, ctx *atylabmongo.MongoCtxSvc) error
+	Cancel(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) error
+	RunNext() (bool, error)
+}
+
+type ScheduledMessageSvc struct {
+	scheduledSvc mongo_svc.ScheduledMessageSvcInterface
+	mongoRoomSvc mongo_svc.RoomSvcInterface
+	sendSvc      MessageSvcInterface
+	clock        atylabclock.ClockInterface
+}
+
+func NewScheduledMessageSvc(
+	scheduledSvc mongo_svc.ScheduledMessageSvcInterface,
+	mongoRoomSvc mongo_svc.RoomSvcInterface,
+	sendSvc MessageSvcInterface,
+	clock atylabclock.ClockInterface,
+) ScheduledMessageSvcInterface {
+	return &ScheduledMessageSvc{
+		scheduledSvc: scheduledSvc,
+		mongoRoomSvc: mongoRoomSvc,
+		sendSvc:      sendSvc,
+		clock:        clock,
+	}
+}
+
+// 送信日時と予約件数を確認してメッセージを予約する
+func (s *ScheduledMessageSvc) Schedule(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	now := s.clock.Now()
+	if !s.validSendAt(scheduled.SendAt, now) {
+		return "", ErrInvalidSendAt
+	}
+
+	pending, err := s.scheduledSvc.GetScheduledMessages(scheduled.RoomID, scheduled.Sender, ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(pending) >= consts.ScheduledMessageMaxPending {
+		return "", ErrScheduledLimitReached
+	}
+
+	scheduled.Status = consts.ScheduledMessageStatus.Pending
+	scheduled.Attempts = 0
+	scheduled.AvailableAt = scheduled.SendAt
+	scheduled.CreatedAt = now
+	scheduled.UpdatedAt = now
+	return s.scheduledSvc.CreateScheduledMessage(scheduled, ctx)
+}
+
+// 送信前の予約メッセージの内容と送信日時を変更する
+func (s *ScheduledMessageSvc) Reschedule(scheduled model.ScheduledMessage, message string, sendAt time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	now := s.clock.Now()
+	if !s.validSendAt(sendAt, now) {
+		return ErrInvalidSendAt
+	}
+
+	updated, err := s.scheduledSvc.UpdateScheduledMessage(scheduled.ID.Hex(), scheduled.RoomID, scheduled.Sender, message, sendAt, now, ctx)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrScheduledMessageNotPending
+	}
+	return nil
+}
+
+func (s *ScheduledMessageSvc) Cancel(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) error {
+	canceled, err := s.scheduledSvc.CancelScheduledMessage(scheduled.ID.Hex(), scheduled.RoomID, scheduled.Sender, s.clock.Now(), ctx)
+	if err != nil {
+		return err
+	}
+	if !canceled {
+		return ErrScheduledMessageNotPending
+	}
+	return nil
+}
+
+func (s *ScheduledMessageSvc) validSendAt(sendAt time.Time, now time.Time) bool {
+	return sendAt.After(now) && !sendAt.After(now.Add(consts.ScheduledMessageMaxAhead))
+}
+
+// 送信日時を過ぎた予約メッセージを1件確保して送信する
+// 送信したメッセージがなければ false を返す
+func (s *ScheduledMessageSvc) RunNext() (bool, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	due, err := s.scheduledSvc.GetDueMessages(now, scheduledMessageBatchSize, ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, scheduled := range due {
+		leased, err := s.scheduledSvc.LeaseMessage(scheduled, now, consts.ScheduledMessageLease, ctx)
+		if err != nil {
+			return false, err
+		}
+		if !leased {
+			continue
+		}
+
+		scheduled.Status = consts.ScheduledMessageStatus.Sending
+		scheduled.Attempts++
+		messageID, err := s.send(scheduled)
+		return true, s.finish(scheduled, messageID, err)
+	}
+
+	return false, nil
+}
+
+// 通常の送信と同じ処理（スパム判定・プレビュー取得など）を通して送信する
+func (s *ScheduledMessageSvc) send(scheduled model.ScheduledMessage) (string, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	// 予約後に退室・BANされた場合は送信しない
+	room, err := s.mongoRoomSvc.GetRoomByID(scheduled.RoomID, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", fmt.Errorf("%w: room not found", errScheduledMessagePermanent)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(room.Members, scheduled.Sender) || slices.Contains(room.BannedMembers, scheduled.Sender) {
+		return "", fmt.Errorf("%w: sender is not a member of the room", errScheduledMessagePermanent)
+	}
+
+	now := s.clock.Now()
+	messageID, err := s.sendSvc.Send(model.Message{
+		RoomID:        scheduled.RoomID,
+		Sender:        scheduled.Sender,
+		Message:       scheduled.Message,
+		CreatedAt:     now,
+		IsReadUserIds: []string{scheduled.Sender},
+		// 送信後に記録できずに再送しても、同じメッセージが二重に保存されないようにする
+		ClientMsgID: "scheduled:" + scheduled.ID.Hex(),
+		// 有効期間は送信した時点のルームの設定に従う
+		ExpiresAt: MessageExpiresAt(0, room, now),
+	}, ctx)
+	if errors.Is(err, ErrSpamRejected) {
+		return "", fmt.Errorf("%w: %v", errScheduledMessagePermanent, err)
+	}
+	return messageID, err
+}
+
+func (s *ScheduledMessageSvc) finish(scheduled model.ScheduledMessage, messageID string, sendErr error) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	switch {
+	case sendErr == nil:
+		return s.scheduledSvc.CompleteMessage(scheduled, messageID, now, ctx)
+	case errors.Is(sendErr, errScheduledMessagePermanent) || scheduled.Attempts >= consts.ScheduledMessageMaxAttempts:
+		fmt.Println("Scheduled message failed:", scheduled.ID.Hex(), sendErr)
+		return s.scheduledSvc.FailMessage(scheduled, now, sendErr.Error(), ctx)
+	default:
+		retryAt := now.Add(consts.ScheduledMessageRetryBase << (scheduled.Attempts - 1))
+		return s.scheduledSvc.RetryMessage(scheduled, now, retryAt, sendErr.Error(), ctx)
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type sendSvcStub struct {
+	messages []model.Message
+	id       string
+	err      error
+}
+
+func (s *sendSvcStub) Send(message model.Message, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	s.messages = append(s.messages, message)
+	return s.id, s.err
+}
+
+func TestScheduleMessage(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		sendAt       time.Time
+		pendingCount int
+		getErr       error
+		createErr    error
+		expectedErr  error
+		expectCreate bool
+	}{
+		{"success", now.Add(time.Hour), 1, nil, nil, nil, true},
+		{"latest allowed", now.Add(consts.ScheduledMessageMaxAhead), 0, nil, nil, nil, true},
+		{"in the past", now.Add(-time.Minute), 0, nil, nil, ErrInvalidSendAt, false},
+		{"now", now, 0, nil, nil, ErrInvalidSendAt, false},
+		{"too far ahead", now.Add(consts.ScheduledMessageMaxAhead + time.Second), 0, nil, nil, ErrInvalidSendAt, false},
+		{"limit reached", now.Add(time.Hour), consts.ScheduledMessageMaxPending, nil, nil, ErrScheduledLimitReached, false},
+		{"get error", now.Add(time.Hour), 0, assert.AnError, nil, assert.AnError, false},
+		{"create error", now.Add(time.Hour), 0, nil, assert.AnError, assert.AnError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+			scheduledSvcMock.On("GetScheduledMessages", "room1", "uuid", mock.Anything).Return(make([]model.ScheduledMessage, tt.pendingCount), tt.getErr)
+			scheduledSvcMock.On("CreateScheduledMessage", model.ScheduledMessage{
+				RoomID:      "room1",
+				Sender:      "uuid",
+				Message:     "good morning",
+				SendAt:      tt.sendAt,
+				Status:      consts.ScheduledMessageStatus.Pending,
+				AvailableAt: tt.sendAt,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}, mock.Anything).Return("scheduled-id", tt.createErr)
+
+			svc := NewScheduledMessageSvc(scheduledSvcMock, nil, nil, atylabclock.NewClockMock(now))
+			scheduledID, err := svc.Schedule(model.ScheduledMessage{
+				RoomID:  "room1",
+				Sender:  "uuid",
+				Message: "good morning",
+				SendAt:  tt.sendAt,
+			}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "scheduled-id", scheduledID)
+			}
+			if tt.expectCreate {
+				scheduledSvcMock.AssertNumberOfCalls(t, "CreateScheduledMessage", 1)
+			} else {
+				scheduledSvcMock.AssertNotCalled(t, "CreateScheduledMessage", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestRescheduleMessage(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduled := model.ScheduledMessage{ID: primitive.NewObjectID(), RoomID: "room1", Sender: "uuid"}
+
+	tests := []struct {
+		name        string
+		sendAt      time.Time
+		updated     bool
+		updateErr   error
+		expectedErr error
+	}{
+		{"success", now.Add(time.Hour), true, nil, nil},
+		{"invalid send_at", now.Add(-time.Hour), false, nil, ErrInvalidSendAt},
+		{"already sent", now.Add(time.Hour), false, nil, ErrScheduledMessageNotPending},
+		{"update error", now.Add(time.Hour), false, assert.AnError, assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+			scheduledSvcMock.On("UpdateScheduledMessage", scheduled.ID.Hex(), "room1", "uuid", "edited", tt.sendAt, now, mock.Anything).Return(tt.updated, tt.updateErr)
+
+			svc := NewScheduledMessageSvc(scheduledSvcMock, nil, nil, atylabclock.NewClockMock(now))
+			err := svc.Reschedule(scheduled, "edited", tt.sendAt, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCancelScheduledMessage(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduled := model.ScheduledMessage{ID: primitive.NewObjectID(), RoomID: "room1", Sender: "uuid"}
+
+	tests := []struct {
+		name        string
+		canceled    bool
+		cancelErr   error
+		expectedErr error
+	}{
+		{"success", true, nil, nil},
+		{"already sent", false, nil, ErrScheduledMessageNotPending},
+		{"cancel error", false, assert.AnError, assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+			scheduledSvcMock.On("CancelScheduledMessage", scheduled.ID.Hex(), "room1", "uuid", now, mock.Anything).Return(tt.canceled, tt.cancelErr)
+
+			svc := NewScheduledMessageSvc(scheduledSvcMock, nil, nil, atylabclock.NewClockMock(now))
+			err := svc.Cancel(scheduled, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestScheduledMessageRunNext(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	scheduled := model.ScheduledMessage{
+		ID:          primitive.NewObjectID(),
+		RoomID:      "room1",
+		Sender:      "uuid",
+		Message:     "good morning",
+		Status:      consts.ScheduledMessageStatus.Pending,
+		Attempts:    0,
+		AvailableAt: now.Add(-time.Minute),
+	}
+	member := model.Room{Members: []string{"uuid"}}
+
+	type expectation struct {
+		complete bool
+		fail     bool
+		retryAt  time.Time
+	}
+
+	tests := []struct {
+		name       string
+		attempts   int
+		room       model.Room
+		roomErr    error
+		sendErr    error
+		expectSend bool
+		expect     expectation
+	}{
+		{"sent", 0, member, nil, nil, true, expectation{complete: true}},
+		{"sent with the room's message lifetime", 0, model.Room{Members: []string{"uuid"}, MessageTTL: 60}, nil, nil, true, expectation{complete: true}},
+		{"room deleted", 0, model.Room{}, mongo.ErrNoDocuments, nil, false, expectation{fail: true}},
+		{"room lookup error", 0, model.Room{}, assert.AnError, nil, false, expectation{retryAt: now.Add(consts.ScheduledMessageRetryBase)}},
+		{"sender left the room", 0, model.Room{Members: []string{"other"}}, nil, nil, false, expectation{fail: true}},
+		{"sender banned", 0, model.Room{Members: []string{"uuid"}, BannedMembers: []string{"uuid"}}, nil, nil, false, expectation{fail: true}},
+		{"rejected as spam", 0, member, nil, ErrSpamRejected, true, expectation{fail: true}},
+		{"send error", 0, member, nil, assert.AnError, true, expectation{retryAt: now.Add(consts.ScheduledMessageRetryBase)}},
+		{"send error backs off", 2, member, nil, assert.AnError, true, expectation{retryAt: now.Add(consts.ScheduledMessageRetryBase * 4)}},
+		{"send error after max attempts", consts.ScheduledMessageMaxAttempts - 1, member, nil, assert.AnError, true, expectation{fail: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due := scheduled
+			due.Attempts = tt.attempts
+			leased := due
+			leased.Status = consts.ScheduledMessageStatus.Sending
+			leased.Attempts = tt.attempts + 1
+
+			scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+			scheduledSvcMock.On("GetDueMessages", now, scheduledMessageBatchSize, mock.Anything).Return([]model.ScheduledMessage{due}, nil)
+			scheduledSvcMock.On("LeaseMessage", due, now, consts.ScheduledMessageLease, mock.Anything).Return(true, nil)
+			scheduledSvcMock.On("CompleteMessage", leased, "message-id", now, mock.Anything).Return(nil)
+			scheduledSvcMock.On("FailMessage", leased, now, mock.Anything, mock.Anything).Return(nil)
+			scheduledSvcMock.On("RetryMessage", leased, now, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			roomSvcMock.On("GetRoomByID", "room1", mock.Anything).Return(tt.room, tt.roomErr)
+			sendSvc := &sendSvcStub{id: "message-id", err: tt.sendErr}
+
+			svc := NewScheduledMessageSvc(scheduledSvcMock, roomSvcMock, sendSvc, atylabclock.NewClockMock(now))
+			processed, err := svc.RunNext()
+			assert.NoError(t, err)
+			assert.True(t, processed)
+
+			if tt.expectSend {
+				assert.Equal(t, []model.Message{{
+					RoomID:        "room1",
+					Sender:        "uuid",
+					Message:       "good morning",
+					CreatedAt:     now,
+					IsReadUserIds: []string{"uuid"},
+					ClientMsgID:   "scheduled:" + scheduled.ID.Hex(),
+					ExpiresAt:     MessageExpiresAt(0, tt.room, now),
+				}}, sendSvc.messages)
+			} else {
+				assert.Empty(t, sendSvc.messages)
+			}
+
+			switch {
+			case tt.expect.complete:
+				scheduledSvcMock.AssertCalled(t, "CompleteMessage", leased, "message-id", now, mock.Anything)
+				scheduledSvcMock.AssertNotCalled(t, "FailMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				scheduledSvcMock.AssertNotCalled(t, "RetryMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			case tt.expect.fail:
+				scheduledSvcMock.AssertCalled(t, "FailMessage", leased, now, mock.Anything, mock.Anything)
+				scheduledSvcMock.AssertNotCalled(t, "RetryMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			default:
+				scheduledSvcMock.AssertCalled(t, "RetryMessage", leased, now, tt.expect.retryAt, mock.Anything, mock.Anything)
+				scheduledSvcMock.AssertNotCalled(t, "FailMessage", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestScheduledMessageRunNextLease(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := model.ScheduledMessage{ID: primitive.NewObjectID(), RoomID: "room1", Sender: "uuid"}
+	second := model.ScheduledMessage{ID: primitive.NewObjectID(), RoomID: "room1", Sender: "uuid"}
+
+	t.Run("nothing due", func(t *testing.T) {
+		scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+		scheduledSvcMock.On("GetDueMessages", now, scheduledMessageBatchSize, mock.Anything).Return([]model.ScheduledMessage{}, nil)
+
+		processed, err := NewScheduledMessageSvc(scheduledSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+		scheduledSvcMock.On("GetDueMessages", now, scheduledMessageBatchSize, mock.Anything).Return([]model.ScheduledMessage{}, assert.AnError)
+
+		processed, err := NewScheduledMessageSvc(scheduledSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("lease error", func(t *testing.T) {
+		scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+		scheduledSvcMock.On("GetDueMessages", now, scheduledMessageBatchSize, mock.Anything).Return([]model.ScheduledMessage{first}, nil)
+		scheduledSvcMock.On("LeaseMessage", first, now, consts.ScheduledMessageLease, mock.Anything).Return(false, assert.AnError)
+
+		processed, err := NewScheduledMessageSvc(scheduledSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("taken by other instances", func(t *testing.T) {
+		scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+		scheduledSvcMock.On("GetDueMessages", now, scheduledMessageBatchSize, mock.Anything).Return([]model.ScheduledMessage{first, second}, nil)
+		scheduledSvcMock.On("LeaseMessage", mock.Anything, now, consts.ScheduledMessageLease, mock.Anything).Return(false, nil)
+		sendSvc := &sendSvcStub{}
+
+		processed, err := NewScheduledMessageSvc(scheduledSvcMock, nil, sendSvc, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+		assert.Empty(t, sendSvc.messages)
+		scheduledSvcMock.AssertNumberOfCalls(t, "LeaseMessage", 2)
+	})
+
+	t.Run("next message is leased when the first is taken", func(t *testing.T) {
+		scheduledSvcMock := new(mongo_svc_mock.ScheduledMessageSvcMock)
+		scheduledSvcMock.On("GetDueMessages", now, scheduledMessageBatchSize, mock.Anything).Return([]model.ScheduledMessage{first, second}, nil)
+		scheduledSvcMock.On("LeaseMessage", first, now, consts.ScheduledMessageLease, mock.Anything).Return(false, nil)
+		scheduledSvcMock.On("LeaseMessage", second, now, consts.ScheduledMessageLease, mock.Anything).Return(true, nil)
+		scheduledSvcMock.On("CompleteMessage", mock.MatchedBy(func(s model.ScheduledMessage) bool {
+			return s.ID == second.ID
+		}), "message-id", now, mock.Anything).Return(nil)
+		roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+		roomSvcMock.On("GetRoomByID", "room1", mock.Anything).Return(model.Room{Members: []string{"uuid"}}, nil)
+		sendSvc := &sendSvcStub{id: "message-id"}
+
+		processed, err := NewScheduledMessageSvc(scheduledSvcMock, roomSvcMock, sendSvc, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.True(t, processed)
+		assert.Len(t, sendSvc.messages, 1)
+		assert.Equal(t, "scheduled:"+second.ID.Hex(), sendSvc.messages[0].ClientMsgID)
+	})
+}
@@ -0,0 +1,494 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrSlashCommandUnknown      = errors.New("unknown command")
+	ErrSlashCommandUsage        = errors.New("invalid command usage")
+	ErrSlashCommandForbidden    = errors.New("not allowed to run this command")
+	ErrSlashCommandFailed       = errors.New("command failed")
+	ErrSlashCommandNameInvalid  = errors.New("command name must be 1-32 lowercase letters, digits, '-' or '_'")
+	ErrSlashCommandNameTaken    = errors.New("command name is already taken")
+	ErrSlashCommandLimitReached = fmt.Errorf("a bot can register at most %d commands", consts.SlashCommandMaxPerBot)
+)
+
+var slashCommandNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// 実行するコマンドと、実行したユーザー・ルーム
+type SlashCommandRequest struct {
+	// 先頭の / を除いたコマンド名
+	Name    string
+	Text    string
+	Room    model.Room
+	Uuid    string
+	IsAdmin bool
+	// 実行したのがボットの場合の送信元（ユーザーは nil）
+	Bot *model.MessageBot
+}
+
+type SlashCommandResult struct {
+	// ルームに送信したメッセージの ID（送信しなかった場合は空）
+	MessageID string
+	// 実行したユーザーにだけ返す応答
+	Ephemeral string
+}
+
+// 外部のコマンドに POST する内容
+type SlashCommandPayload struct {
+	Command string `json:"command"`
+	Text    string `json:"text"`
+	RoomID  string `json:"room_id"`
+	UserID  string `json:"user_id"`
+}
+
+// 外部のコマンドの応答（Slack のスラッシュコマンドと同じ形式）
+type SlashCommandResponse struct {
+	ResponseType string `json:"response_type"`
+	SlackPayload
+}
+
+type SlashCommandSvcInterface interface {
+	Register(command model.SlashCommand, ctx *atylabmongo.MongoCtxSvc) (model.SlashCommand, error)
+	Execute(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error)
+}
+
+type slashCommandFunc func(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error)
+
+type SlashCommandSvc struct {
+	slashCommandSvc mongo_svc.SlashCommandSvcInterface
+	botSvc          mongo_svc.BotSvcInterface
+	roomSvc         mongo_svc.RoomSvcInterface
+	sendSvc         MessageSvcInterface
+	webhookSvc      WebhookSvcInterface
+	eventSvc        EventSvcInterface
+	pollSvc         PollSvcInterface
+	reminderSvc     ReminderSvcInterface
+	caller          usecase.WebhookCallerInterface
+	clock           atylabclock.ClockInterface
+	builtins        map[string]slashCommandFunc
+}
+
+func NewSlashCommandSvc(
+	slashCommandSvc mongo_svc.SlashCommandSvcInterface,
+	botSvc mongo_svc.BotSvcInterface,
+	roomSvc mongo_svc.RoomSvcInterface,
+	sendSvc MessageSvcInterface,
+	webhookSvc WebhookSvcInterface,
+	eventSvc EventSvcInterface,
+	pollSvc PollSvcInterface,
+	reminderSvc ReminderSvcInterface,
+	caller usecase.WebhookCallerInterface,
+	clock atylabclock.ClockInterface,
+) SlashCommandSvcInterface {
+	s := &SlashCommandSvc{
+		slashCommandSvc: slashCommandSvc,
+		botSvc:          botSvc,
+		roomSvc:         roomSvc,
+		sendSvc:         sendSvc,
+		webhookSvc:      webhookSvc,
+		eventSvc:        eventSvc,
+		pollSvc:         pollSvc,
+		reminderSvc:     reminderSvc,
+		caller:          caller,
+		clock:           clock,
+	}
+	// consts.SlashCommandBuiltins に載せたコマンドの実装
+	s.builtins = map[string]slashCommandFunc{
+		"me":     s.me,
+		"topic":  s.topic,
+		"invite": s.invite,
+		"mute":   s.mute,
+		"poll":   s.poll,
+		"remind": s.remind,
+	}
+	return s
+}
+
+// "/name 引数" の形のメッセージを、コマンド名と引数に分ける
+// 名前に使えない文字を含む場合（"/usr/bin" など）はコマンドとして扱わない
+func ParseSlashCommand(text string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(text, "/")
+	if !ok {
+		return "", "", false
+	}
+
+	name, args := rest, ""
+	if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+		name, args = rest[:i], rest[i:]
+	}
+	name = strings.ToLower(name)
+	if !slashCommandNamePattern.MatchString(name) {
+		return "", "", false
+	}
+	return name, strings.TrimSpace(args), true
+}
+
+// "//" で始まるメッセージは、先頭の / を1つ取り除いてテキストとして送信する
+func UnescapeSlashCommand(text string) string {
+	if strings.HasPrefix(text, "//") {
+		return text[1:]
+	}
+	return text
+}
+
+// 組み込みのコマンドか、組み込みにする予定の名前かどうか
+func IsReservedSlashCommand(name string) bool {
+	for _, builtin := range consts.SlashCommandBuiltins {
+		if builtin.Name == name {
+			return true
+		}
+	}
+	return slices.Contains(consts.SlashCommandReservedNames, name)
+}
+
+// 名前・URL・登録数を確認して、ボットのコマンドを登録する
+// 署名の鍵はここで生成し、登録したコマンドと一緒に返す
+func (s *SlashCommandSvc) Register(command model.SlashCommand, ctx *atylabmongo.MongoCtxSvc) (model.SlashCommand, error) {
+	if !slashCommandNamePattern.MatchString(command.Name) {
+		return model.SlashCommand{}, ErrSlashCommandNameInvalid
+	}
+	if IsReservedSlashCommand(command.Name) {
+		return model.SlashCommand{}, ErrSlashCommandNameTaken
+	}
+	if err := usecase.ValidateWebhookURL(command.URL); err != nil {
+		return model.SlashCommand{}, err
+	}
+
+	commands, err := s.slashCommandSvc.GetSlashCommands(command.BotID, ctx)
+	if err != nil {
+		return model.SlashCommand{}, err
+	}
+	if len(commands) >= consts.SlashCommandMaxPerBot {
+		return model.SlashCommand{}, ErrSlashCommandLimitReached
+	}
+
+	command.Secret, err = newSecretToken()
+	if err != nil {
+		return model.SlashCommand{}, err
+	}
+	command.CreatedAt = s.clock.Now()
+
+	commandID, err := s.slashCommandSvc.CreateSlashCommand(command, ctx)
+	if mongo.IsDuplicateKeyError(err) {
+		return model.SlashCommand{}, ErrSlashCommandNameTaken
+	}
+	if err != nil {
+		return model.SlashCommand{}, err
+	}
+	command.ID, err = primitive.ObjectIDFromHex(commandID)
+	if err != nil {
+		return model.SlashCommand{}, err
+	}
+	return command, nil
+}
+
+// 組み込みのコマンドを優先し、なければボットが登録したコマンドを呼び出す
+func (s *SlashCommandSvc) Execute(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	if builtin, ok := s.builtins[req.Name]; ok {
+		return builtin(req, ctx)
+	}
+	return s.callExternal(req, ctx)
+}
+
+// /me <text>: 自分の動作を表すメッセージを送信する
+func (s *SlashCommandSvc) me(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	if req.Text == "" {
+		return SlashCommandResult{}, slashCommandUsageError(req.Name)
+	}
+
+	messageID, err := s.send(req, consts.MessageTypes.Me, req.Text, ctx)
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+	return SlashCommandResult{MessageID: messageID}, nil
+}
+
+// /topic [text]: トピックを表示する。引数がある場合は管理者に限りトピックを変更し、ルームに知らせる
+func (s *SlashCommandSvc) topic(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	if req.Text == "" {
+		if req.Room.Topic == "" {
+			return SlashCommandResult{Ephemeral: "No topic is set for this room."}, nil
+		}
+		return SlashCommandResult{Ephemeral: "Topic: " + req.Room.Topic}, nil
+	}
+	if !req.IsAdmin {
+		return SlashCommandResult{}, fmt.Errorf("%w: only admin can change the topic", ErrSlashCommandForbidden)
+	}
+
+	// トピックは1行で表示するので、改行や連続した空白は1つの空白にまとめる
+	topic := strings.Join(strings.Fields(req.Text), " ")
+	if utf8.RuneCountInString(topic) > consts.RoomTopicMaxLength {
+		return SlashCommandResult{}, fmt.Errorf("%w: topic must be at most %d characters", ErrSlashCommandUsage, consts.RoomTopicMaxLength)
+	}
+
+	if err := s.roomSvc.SetTopic(req.Room.ID.Hex(), topic, ctx); err != nil {
+		return SlashCommandResult{}, err
+	}
+	messageID, err := s.send(req, consts.MessageTypes.Topic, topic, ctx)
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+	return SlashCommandResult{MessageID: messageID}, nil
+}
+
+// /invite @user [@user...]: 管理者がユーザーをルームに追加する（ボットは @bot:<ID> で指定する）
+// BAN されたユーザーとすでにメンバーのユーザーは追加せず、結果を実行したユーザーに返す
+func (s *SlashCommandSvc) invite(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	if !req.IsAdmin {
+		return SlashCommandResult{}, fmt.Errorf("%w: only admin can invite members", ErrSlashCommandForbidden)
+	}
+
+	targets := []string{}
+	for _, field := range strings.Fields(req.Text) {
+		uuid, ok := strings.CutPrefix(field, "@")
+		if !ok || uuid == "" {
+			return SlashCommandResult{}, slashCommandUsageError(req.Name)
+		}
+		if !slices.Contains(targets, uuid) {
+			targets = append(targets, uuid)
+		}
+	}
+	if len(targets) == 0 {
+		return SlashCommandResult{}, slashCommandUsageError(req.Name)
+	}
+	if len(targets) > consts.SlashCommandInviteMaxUsers {
+		return SlashCommandResult{}, fmt.Errorf("%w: at most %d users can be invited at once", ErrSlashCommandUsage, consts.SlashCommandInviteMaxUsers)
+	}
+
+	roomID := req.Room.ID.Hex()
+	lines := []string{}
+	for _, uuid := range targets {
+		switch {
+		case slices.Contains(req.Room.BannedMembers, uuid):
+			lines = append(lines, "@"+uuid+" is banned from this room.")
+		case slices.Contains(req.Room.Members, uuid):
+			lines = append(lines, "@"+uuid+" is already a member.")
+		default:
+			if err := s.roomSvc.JoinRoom(roomID, uuid, ctx); err != nil {
+				return SlashCommandResult{}, err
+			}
+			member := WebhookMemberData{
+				UserID:  uuid,
+				ActorID: req.Uuid,
+			}
+			s.webhookSvc.Dispatch(roomID, consts.WebhookEvents.MemberJoined, member)
+			// 招待したユーザーの接続が、ルームの一覧を読み直さなくてもルームのイベントを受け取れるようにする
+			if _, err := s.eventSvc.Publish(RoomEvent{
+				Type:   consts.EventTypes.MemberJoined,
+				RoomID: roomID,
+				Data:   member,
+				Member: uuid,
+			}); err != nil {
+				fmt.Println("Failed to publish member event:", err)
+			}
+			lines = append(lines, "Invited @"+uuid+".")
+		}
+	}
+	return SlashCommandResult{Ephemeral: strings.Join(lines, "\n")}, nil
+}
+
+// /mute: ルームの通知を止める。通知を止めている場合は再開する
+func (s *SlashCommandSvc) mute(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	if req.Text != "" {
+		return SlashCommandResult{}, slashCommandUsageError(req.Name)
+	}
+
+	muted := !slices.Contains(req.Room.MutedMembers, req.Uuid)
+	if err := s.roomSvc.SetMuted(req.Room.ID.Hex(), req.Uuid, muted, ctx); err != nil {
+		return SlashCommandResult{}, err
+	}
+	if muted {
+		return SlashCommandResult{Ephemeral: "Muted notifications from this room. Run /mute again to unmute."}, nil
+	}
+	return SlashCommandResult{Ephemeral: "Unmuted notifications from this room."}, nil
+}
+
+// /poll <question> | <option> | <option>...: 単一選択の投票を作成する
+// 複数選択や匿名、締め切りの指定は投票の作成 API から行う
+func (s *SlashCommandSvc) poll(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	parts := strings.Split(req.Text, "|")
+	if len(parts) < 1+consts.PollMinOptions {
+		return SlashCommandResult{}, slashCommandUsageError(req.Name)
+	}
+
+	messageID, err := s.pollSvc.Create(PollInput{
+		Question: parts[0],
+		Options:  parts[1:],
+	}, req.Room, req.Uuid, req.Bot, ctx)
+	if errors.Is(err, ErrPollInvalid) {
+		return SlashCommandResult{}, fmt.Errorf("%w: %v", ErrSlashCommandUsage, err)
+	}
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+	return SlashCommandResult{MessageID: messageID}, nil
+}
+
+// /remind [me] [in] <duration> <text>: 指定した時間が経ったら、通知用ルームに内容を届ける
+// メッセージへのリマインダーはリマインダーの作成 API から設定する
+func (s *SlashCommandSvc) remind(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	if req.Bot != nil {
+		return SlashCommandResult{}, fmt.Errorf("%w: bots cannot set reminders", ErrSlashCommandForbidden)
+	}
+
+	fields := strings.Fields(req.Text)
+	if len(fields) > 0 && strings.EqualFold(fields[0], "me") {
+		fields = fields[1:]
+	}
+	if len(fields) > 0 && strings.EqualFold(fields[0], "in") {
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return SlashCommandResult{}, slashCommandUsageError(req.Name)
+	}
+
+	reminder, err := s.reminderSvc.Create(model.Reminder{
+		UserID: req.Uuid,
+		Text:   strings.Join(fields[1:], " "),
+	}, ReminderTime{In: fields[0]}, ctx)
+	if errors.Is(err, ErrInvalidRemindAt) || errors.Is(err, ErrReminderInvalid) {
+		return SlashCommandResult{}, fmt.Errorf("%w: %v", ErrSlashCommandUsage, err)
+	}
+	if errors.Is(err, ErrReminderLimitReached) {
+		return SlashCommandResult{}, fmt.Errorf("%w: %v", ErrSlashCommandForbidden, err)
+	}
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+	return SlashCommandResult{Ephemeral: "I will remind you at " + reminder.RemindAt.Format(time.RFC3339) + "."}, nil
+}
+
+// ボットが登録したコマンドの URL に内容を POST し、応答を実行したユーザーかルームに返す
+// コマンドを登録したボットがルームのメンバーでない場合は、存在しないコマンドとして扱う
+// 本文には Webhook と同じ方式で署名するので、受信側は同じ方法で検証できる
+func (s *SlashCommandSvc) callExternal(req SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (SlashCommandResult, error) {
+	command, err := s.slashCommandSvc.GetSlashCommandByName(req.Name, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return SlashCommandResult{}, fmt.Errorf("%w: /%s", ErrSlashCommandUnknown, req.Name)
+	}
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+	bot, err := s.botSvc.GetBot(command.BotID, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return SlashCommandResult{}, fmt.Errorf("%w: /%s", ErrSlashCommandUnknown, req.Name)
+	}
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+	// ルームに追加されていないボットには、ルームの内容を送らず、ルームへの送信もさせない
+	botSender := consts.BotSenderPrefix + bot.ID.Hex()
+	if !slices.Contains(req.Room.Members, botSender) || slices.Contains(req.Room.BannedMembers, botSender) {
+		return SlashCommandResult{}, fmt.Errorf("%w: /%s", ErrSlashCommandUnknown, req.Name)
+	}
+
+	payload, err := json.Marshal(SlashCommandPayload{
+		Command: "/" + command.Name,
+		Text:    req.Text,
+		RoomID:  req.Room.ID.Hex(),
+		UserID:  req.Uuid,
+	})
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+
+	timestamp := s.clock.Now().Unix()
+	headers := map[string]string{
+		consts.WebhookHeaders.Event:     consts.SlashCommandWebhookEvent,
+		consts.WebhookHeaders.Timestamp: strconv.FormatInt(timestamp, 10),
+		consts.WebhookHeaders.Signature: consts.WebhookSignaturePrefix + SignWebhookPayload(command.Secret, timestamp, payload),
+	}
+
+	callCtx, cancel := context.WithTimeout(context.Background(), consts.SlashCommandTimeout)
+	defer cancel()
+
+	statusCode, body, err := s.caller.Call(callCtx, command.URL, headers, payload, consts.SlashCommandResponseMaxBytes)
+	if err != nil {
+		fmt.Println("Failed to call slash command:", command.Name, err)
+		return SlashCommandResult{}, fmt.Errorf("%w: /%s did not respond", ErrSlashCommandFailed, command.Name)
+	}
+	if statusCode < 200 || statusCode >= 300 {
+		return SlashCommandResult{}, fmt.Errorf("%w: /%s responded with status %d", ErrSlashCommandFailed, command.Name, statusCode)
+	}
+
+	response := parseSlashCommandResponse(body)
+	text := response.PlainText()
+	if text == "" {
+		return SlashCommandResult{}, nil
+	}
+	if utf8.RuneCountInString(text) > consts.SlashCommandMaxTextLength {
+		return SlashCommandResult{}, fmt.Errorf("%w: the response of /%s is too long", ErrSlashCommandFailed, command.Name)
+	}
+	if response.ResponseType != consts.SlashCommandResponseTypes.InChannel {
+		return SlashCommandResult{Ephemeral: text}, nil
+	}
+
+	now := s.clock.Now()
+	messageID, err := s.sendSvc.Send(model.Message{
+		RoomID:        req.Room.ID.Hex(),
+		Sender:        botSender,
+		Message:       text,
+		CreatedAt:     now,
+		IsReadUserIds: []string{},
+		ExpiresAt:     MessageExpiresAt(0, req.Room, now),
+		Bot:           BotMessageSender(bot),
+	}, ctx)
+	if err != nil {
+		return SlashCommandResult{}, err
+	}
+	return SlashCommandResult{MessageID: messageID}, nil
+}
+
+// コマンドを実行したユーザーからのメッセージとして送信する
+func (s *SlashCommandSvc) send(req SlashCommandRequest, messageType string, text string, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	now := s.clock.Now()
+	return s.sendSvc.Send(model.Message{
+		RoomID:        req.Room.ID.Hex(),
+		Sender:        req.Uuid,
+		Message:       text,
+		CreatedAt:     now,
+		IsReadUserIds: []string{req.Uuid},
+		ExpiresAt:     MessageExpiresAt(0, req.Room, now),
+		Bot:           req.Bot,
+		Type:          messageType,
+	}, ctx)
+}
+
+// JSON でない応答は、本文をそのまま実行したユーザーへの応答にする
+func parseSlashCommandResponse(body []byte) SlashCommandResponse {
+	var response SlashCommandResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return SlashCommandResponse{SlackPayload: SlackPayload{Text: string(body)}}
+	}
+	return response
+}
+
+func slashCommandUsageError(name string) error {
+	for _, builtin := range consts.SlashCommandBuiltins {
+		if builtin.Name == name {
+			return fmt.Errorf("%w: %s", ErrSlashCommandUsage, builtin.Usage)
+		}
+	}
+	return ErrSlashCommandUsage
+}
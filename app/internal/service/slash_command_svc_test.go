@@ -0,0 +1,541 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type slashCommandCallerStub struct {
+	urls       []string
+	headers    []map[string]string
+	bodies     [][]byte
+	statusCode int
+	response   string
+	err        error
+}
+
+func (s *slashCommandCallerStub) Call(ctx context.Context, rawURL string, headers map[string]string, body []byte, maxResponseBytes int64) (int, []byte, error) {
+	s.urls = append(s.urls, rawURL)
+	s.headers = append(s.headers, headers)
+	s.bodies = append(s.bodies, body)
+	return s.statusCode, []byte(s.response), s.err
+}
+
+func TestParseSlashCommand(t *testing.T) {
+	tests := map[string]struct {
+		name string
+		args string
+		ok   bool
+	}{
+		"/me waves":                   {name: "me", args: "waves", ok: true},
+		"/topic":                      {name: "topic", ok: true},
+		"/Topic  new topic  ":         {name: "topic", args: "new topic", ok: true},
+		"/deploy\nprod":               {name: "deploy", args: "prod", ok: true},
+		"/usr/bin is a path":          {},
+		"/":                           {},
+		"// not a command":            {},
+		"hello /me":                   {},
+		"/" + strings.Repeat("a", 33): {},
+	}
+
+	for text, tt := range tests {
+		name, args, ok := ParseSlashCommand(text)
+		assert.Equal(t, tt.ok, ok, text)
+		assert.Equal(t, tt.name, name, text)
+		assert.Equal(t, tt.args, args, text)
+	}
+}
+
+func TestUnescapeSlashCommand(t *testing.T) {
+	assert.Equal(t, "/me is a command", UnescapeSlashCommand("//me is a command"))
+	assert.Equal(t, "/usr/bin", UnescapeSlashCommand("/usr/bin"))
+	assert.Equal(t, "hello", UnescapeSlashCommand("hello"))
+}
+
+func TestSlashCommandBuiltinsAreImplemented(t *testing.T) {
+	svc := NewSlashCommandSvc(nil, nil, nil, nil, nil, nil, nil, nil, nil, atylabclock.NewClock()).(*SlashCommandSvc)
+	assert.Len(t, svc.builtins, len(consts.SlashCommandBuiltins))
+	for _, builtin := range consts.SlashCommandBuiltins {
+		assert.Contains(t, svc.builtins, builtin.Name)
+		assert.True(t, IsReservedSlashCommand(builtin.Name))
+	}
+	for _, name := range consts.SlashCommandReservedNames {
+		assert.True(t, IsReservedSlashCommand(name))
+	}
+	assert.False(t, IsReservedSlashCommand("deploy"))
+}
+
+func TestSlashCommandRegister(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	commandID := primitive.NewObjectID()
+
+	tests := []struct {
+		name         string
+		command      string
+		url          string
+		count        int
+		getErr       error
+		createErr    error
+		expectedErr  error
+		expectCreate bool
+	}{
+		{"success", "deploy", "https://example.com/deploy", 0, nil, nil, nil, true},
+		{"invalid name", "Deploy!", "https://example.com/deploy", 0, nil, nil, ErrSlashCommandNameInvalid, false},
+		{"builtin name", "me", "https://example.com/deploy", 0, nil, nil, ErrSlashCommandNameTaken, false},
+		{"reserved name", "poll", "https://example.com/deploy", 0, nil, nil, ErrSlashCommandNameTaken, false},
+		{"invalid url", "deploy", "ftp://example.com/deploy", 0, nil, nil, nil, false},
+		{"limit reached", "deploy", "https://example.com/deploy", consts.SlashCommandMaxPerBot, nil, nil, ErrSlashCommandLimitReached, false},
+		{"get error", "deploy", "https://example.com/deploy", 0, assert.AnError, nil, assert.AnError, false},
+		{"name taken by another bot", "deploy", "https://example.com/deploy", 0, nil, mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}, ErrSlashCommandNameTaken, true},
+		{"create error", "deploy", "https://example.com/deploy", 0, nil, assert.AnError, assert.AnError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stored model.SlashCommand
+			slashCommandSvcMock := new(mongo_svc_mock.SlashCommandSvcMock)
+			slashCommandSvcMock.On("GetSlashCommands", "bot1", mock.Anything).Return(make([]model.SlashCommand, tt.count), tt.getErr)
+			slashCommandSvcMock.On("CreateSlashCommand", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				stored = args.Get(0).(model.SlashCommand)
+			}).Return(commandID.Hex(), tt.createErr)
+
+			svc := NewSlashCommandSvc(slashCommandSvcMock, nil, nil, nil, nil, nil, nil, nil, nil, atylabclock.NewClockMock(now))
+			command, err := svc.Register(model.SlashCommand{BotID: "bot1", Name: tt.command, URL: tt.url}, nil)
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case !tt.expectCreate:
+				assert.Error(t, err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, commandID, command.ID)
+				assert.Len(t, command.Secret, 64)
+				assert.Equal(t, command.Secret, stored.Secret)
+				assert.Equal(t, now, stored.CreatedAt)
+			}
+			if tt.expectCreate {
+				slashCommandSvcMock.AssertNumberOfCalls(t, "CreateSlashCommand", 1)
+			} else {
+				slashCommandSvcMock.AssertNotCalled(t, "CreateSlashCommand", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSlashCommandMe(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	room := model.Room{ID: primitive.NewObjectID(), MessageTTL: 60}
+
+	sendSvc := &sendSvcStub{id: "message-id"}
+	svc := NewSlashCommandSvc(nil, nil, nil, sendSvc, nil, nil, nil, nil, nil, atylabclock.NewClockMock(now))
+
+	result, err := svc.Execute(SlashCommandRequest{Name: "me", Text: "waves", Room: room, Uuid: "uuid1"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, SlashCommandResult{MessageID: "message-id"}, result)
+	assert.Equal(t, []model.Message{{
+		RoomID:        room.ID.Hex(),
+		Sender:        "uuid1",
+		Message:       "waves",
+		CreatedAt:     now,
+		IsReadUserIds: []string{"uuid1"},
+		ExpiresAt:     MessageExpiresAt(0, room, now),
+		Type:          consts.MessageTypes.Me,
+	}}, sendSvc.messages)
+
+	_, err = svc.Execute(SlashCommandRequest{Name: "me", Room: room, Uuid: "uuid1"}, nil)
+	assert.ErrorIs(t, err, ErrSlashCommandUsage)
+	assert.Contains(t, err.Error(), "/me <text>")
+	assert.Len(t, sendSvc.messages, 1)
+}
+
+func TestSlashCommandTopic(t *testing.T) {
+	room := model.Room{ID: primitive.NewObjectID()}
+
+	tests := []struct {
+		name          string
+		text          string
+		currentTopic  string
+		isAdmin       bool
+		setErr        error
+		expectedErr   error
+		expectTopic   string
+		expectMessage string
+	}{
+		{name: "show topic", currentTopic: "release", expectMessage: "Topic: release"},
+		{name: "show empty topic", expectMessage: "No topic is set for this room."},
+		{name: "change topic", text: "next  release\nplanning", isAdmin: true, expectTopic: "next release planning"},
+		{name: "not admin", text: "new topic", expectedErr: ErrSlashCommandForbidden},
+		{name: "too long", text: strings.Repeat("あ", consts.RoomTopicMaxLength+1), isAdmin: true, expectedErr: ErrSlashCommandUsage},
+		{name: "set error", text: "new topic", isAdmin: true, setErr: assert.AnError, expectedErr: assert.AnError, expectTopic: "new topic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := room
+			current.Topic = tt.currentTopic
+			roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			roomSvcMock.On("SetTopic", room.ID.Hex(), mock.Anything, mock.Anything).Return(tt.setErr)
+			sendSvc := &sendSvcStub{id: "message-id"}
+
+			svc := NewSlashCommandSvc(nil, nil, roomSvcMock, sendSvc, nil, nil, nil, nil, nil, atylabclock.NewClock())
+			result, err := svc.Execute(SlashCommandRequest{Name: "topic", Text: tt.text, Room: current, Uuid: "uuid1", IsAdmin: tt.isAdmin}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectMessage, result.Ephemeral)
+			}
+
+			if tt.expectTopic == "" {
+				roomSvcMock.AssertNotCalled(t, "SetTopic", mock.Anything, mock.Anything, mock.Anything)
+				assert.Empty(t, sendSvc.messages)
+				return
+			}
+			roomSvcMock.AssertCalled(t, "SetTopic", room.ID.Hex(), tt.expectTopic, mock.Anything)
+			if tt.setErr != nil {
+				assert.Empty(t, sendSvc.messages)
+				return
+			}
+			assert.Equal(t, "message-id", result.MessageID)
+			assert.Len(t, sendSvc.messages, 1)
+			assert.Equal(t, tt.expectTopic, sendSvc.messages[0].Message)
+			assert.Equal(t, consts.MessageTypes.Topic, sendSvc.messages[0].Type)
+		})
+	}
+}
+
+func TestSlashCommandInvite(t *testing.T) {
+	room := model.Room{ID: primitive.NewObjectID(), Members: []string{"admin", "member"}, BannedMembers: []string{"banned"}}
+	tooMany := []string{}
+	for i := 0; i <= consts.SlashCommandInviteMaxUsers; i++ {
+		tooMany = append(tooMany, "@user"+string(rune('a'+i)))
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		isAdmin       bool
+		joinErr       error
+		expectedErr   error
+		expectJoined  []string
+		expectMessage string
+	}{
+		{
+			name:          "success",
+			text:          "@new1 @member @banned @new2 @new1",
+			isAdmin:       true,
+			expectJoined:  []string{"new1", "new2"},
+			expectMessage: "Invited @new1.\n@member is already a member.\n@banned is banned from this room.\nInvited @new2.",
+		},
+		{name: "not admin", text: "@new1", expectedErr: ErrSlashCommandForbidden},
+		{name: "no users", isAdmin: true, expectedErr: ErrSlashCommandUsage},
+		{name: "not a mention", text: "@new1 new2", isAdmin: true, expectedErr: ErrSlashCommandUsage},
+		{name: "too many users", text: strings.Join(tooMany, " "), isAdmin: true, expectedErr: ErrSlashCommandUsage},
+		{name: "join error", text: "@new1", isAdmin: true, joinErr: assert.AnError, expectedErr: assert.AnError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			roomSvcMock.On("JoinRoom", room.ID.Hex(), mock.Anything, mock.Anything).Return(tt.joinErr)
+			webhookSvc := &webhookSvcStub{}
+			eventSvc := &eventSvcStub{}
+
+			svc := NewSlashCommandSvc(nil, nil, roomSvcMock, nil, webhookSvc, eventSvc, nil, nil, nil, atylabclock.NewClock())
+			result, err := svc.Execute(SlashCommandRequest{Name: "invite", Text: tt.text, Room: room, Uuid: "admin", IsAdmin: tt.isAdmin}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, webhookSvc.joined)
+				assert.Empty(t, eventSvc.events)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectMessage, result.Ephemeral)
+			roomSvcMock.AssertNumberOfCalls(t, "JoinRoom", len(tt.expectJoined))
+			assert.Len(t, eventSvc.events, len(tt.expectJoined))
+			for i, uuid := range tt.expectJoined {
+				roomSvcMock.AssertCalled(t, "JoinRoom", room.ID.Hex(), uuid, mock.Anything)
+				assert.Equal(t, WebhookMemberData{UserID: uuid, ActorID: "admin"}, webhookSvc.joined[i])
+				assert.Equal(t, RoomEvent{
+					Type:   consts.EventTypes.MemberJoined,
+					RoomID: room.ID.Hex(),
+					Data:   WebhookMemberData{UserID: uuid, ActorID: "admin"},
+					Member: uuid,
+				}, eventSvc.events[i])
+			}
+		})
+	}
+}
+
+func TestSlashCommandMute(t *testing.T) {
+	room := model.Room{ID: primitive.NewObjectID()}
+
+	tests := []struct {
+		name          string
+		text          string
+		mutedMembers  []string
+		setErr        error
+		expectedErr   error
+		expectMuted   bool
+		expectSet     bool
+		expectMessage string
+	}{
+		{name: "mute", expectMuted: true, expectSet: true, expectMessage: "Muted notifications from this room. Run /mute again to unmute."},
+		{name: "unmute", mutedMembers: []string{"uuid1"}, expectSet: true, expectMessage: "Unmuted notifications from this room."},
+		{name: "with args", text: "1h", expectedErr: ErrSlashCommandUsage},
+		{name: "set error", setErr: assert.AnError, expectedErr: assert.AnError, expectMuted: true, expectSet: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := room
+			current.MutedMembers = tt.mutedMembers
+			roomSvcMock := new(mongo_svc_mock.RoomSvcMock)
+			roomSvcMock.On("SetMuted", room.ID.Hex(), "uuid1", mock.Anything, mock.Anything).Return(tt.setErr)
+
+			svc := NewSlashCommandSvc(nil, nil, roomSvcMock, nil, nil, nil, nil, nil, nil, atylabclock.NewClock())
+			result, err := svc.Execute(SlashCommandRequest{Name: "mute", Text: tt.text, Room: current, Uuid: "uuid1"}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectMessage, result.Ephemeral)
+			}
+			if tt.expectSet {
+				roomSvcMock.AssertCalled(t, "SetMuted", room.ID.Hex(), "uuid1", tt.expectMuted, mock.Anything)
+			} else {
+				roomSvcMock.AssertNotCalled(t, "SetMuted", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestSlashCommandPoll(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	room := model.Room{ID: primitive.NewObjectID()}
+
+	tests := []struct {
+		name         string
+		text         string
+		expectedErr  error
+		expectPoll   *model.MessagePoll
+		expectResult SlashCommandResult
+	}{
+		{
+			name:         "success",
+			text:         "Lunch? | Sushi | Ramen | Curry",
+			expectPoll:   &model.MessagePoll{Question: "Lunch?", Options: []string{"Sushi", "Ramen", "Curry"}, Votes: []model.PollVote{}},
+			expectResult: SlashCommandResult{MessageID: "message-id"},
+		},
+		{name: "no args", expectedErr: ErrSlashCommandUsage},
+		{name: "one option", text: "Lunch? | Sushi", expectedErr: ErrSlashCommandUsage},
+		{name: "empty option", text: "Lunch? | Sushi | ", expectedErr: ErrSlashCommandUsage},
+		{name: "empty question", text: " | Sushi | Ramen", expectedErr: ErrSlashCommandUsage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sendSvc := &sendSvcStub{id: "message-id"}
+			pollSvc := NewPollSvc(nil, sendSvc, nil, nil, atylabclock.NewClockMock(now))
+
+			svc := NewSlashCommandSvc(nil, nil, nil, nil, nil, nil, pollSvc, nil, nil, atylabclock.NewClockMock(now))
+			result, err := svc.Execute(SlashCommandRequest{Name: "poll", Text: tt.text, Room: room, Uuid: "uuid1"}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Empty(t, sendSvc.messages)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectResult, result)
+			assert.Len(t, sendSvc.messages, 1)
+			assert.Equal(t, consts.MessageTypes.Poll, sendSvc.messages[0].Type)
+			assert.Equal(t, tt.expectPoll, sendSvc.messages[0].Poll)
+		})
+	}
+}
+
+func TestSlashCommandRemind(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	reminderID := primitive.NewObjectID()
+
+	tests := []struct {
+		name         string
+		text         string
+		bot          *model.MessageBot
+		pendingCount int
+		expectedErr  error
+		expectText   string
+		expectAt     time.Time
+	}{
+		{name: "duration first", text: "2h call Bob", expectText: "call Bob", expectAt: now.Add(2 * time.Hour)},
+		{name: "remind me in", text: "me in 3d renew  the domain", expectText: "renew the domain", expectAt: now.Add(3 * 24 * time.Hour)},
+		{name: "no text", text: "me in 2h", expectedErr: ErrSlashCommandUsage},
+		{name: "no args", expectedErr: ErrSlashCommandUsage},
+		{name: "invalid duration", text: "soon call Bob", expectedErr: ErrSlashCommandUsage},
+		{name: "limit reached", text: "2h call Bob", pendingCount: consts.ReminderMaxPending, expectedErr: ErrSlashCommandForbidden},
+		{name: "bot", text: "2h call Bob", bot: &model.MessageBot{Name: "Deployer"}, expectedErr: ErrSlashCommandForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reminderSvcMock := new(mongo_svc_mock.ReminderSvcMock)
+			reminderSvcMock.On("GetReminders", "uuid1", mock.Anything).Return(make([]model.Reminder, tt.pendingCount), nil)
+			reminderSvcMock.On("CreateReminder", mock.Anything, mock.Anything).Return(reminderID.Hex(), nil)
+			reminderSvc := NewReminderSvc(reminderSvcMock, nil, nil, nil, nil, atylabclock.NewClockMock(now))
+
+			svc := NewSlashCommandSvc(nil, nil, nil, nil, nil, nil, nil, reminderSvc, nil, atylabclock.NewClockMock(now))
+			result, err := svc.Execute(SlashCommandRequest{Name: "remind", Text: tt.text, Room: model.Room{ID: primitive.NewObjectID()}, Uuid: "uuid1", Bot: tt.bot}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				reminderSvcMock.AssertNotCalled(t, "CreateReminder", mock.Anything, mock.Anything)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "I will remind you at "+tt.expectAt.Format(time.RFC3339)+".", result.Ephemeral)
+			reminderSvcMock.AssertCalled(t, "CreateReminder", mock.MatchedBy(func(r model.Reminder) bool {
+				return r.UserID == "uuid1" && r.Text == tt.expectText && r.RemindAt.Equal(tt.expectAt) && r.RoomID == ""
+			}), mock.Anything)
+		})
+	}
+}
+
+func TestSlashCommandExternal(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	bot := model.Bot{ID: primitive.NewObjectID(), Name: "Deployer", IconURL: "https://example.com/bot.png"}
+	botSender := consts.BotSenderPrefix + bot.ID.Hex()
+	roomID := primitive.NewObjectID()
+	command := model.SlashCommand{ID: primitive.NewObjectID(), BotID: bot.ID.Hex(), Name: "deploy", URL: "https://example.com/deploy", Secret: "secret"}
+
+	tests := []struct {
+		name          string
+		commandErr    error
+		botErr        error
+		members       []string
+		banned        []string
+		statusCode    int
+		response      string
+		callErr       error
+		sendErr       error
+		expectedErr   error
+		expectCall    bool
+		expectResult  SlashCommandResult
+		expectMessage string
+	}{
+		{
+			name:         "ephemeral response",
+			statusCode:   200,
+			response:     `{"text": "Deploying <https://ci.example.com/1|#1>"}`,
+			expectCall:   true,
+			expectResult: SlashCommandResult{Ephemeral: "Deploying https://ci.example.com/1"},
+		},
+		{
+			name:          "in channel response",
+			statusCode:    200,
+			response:      `{"response_type": "in_channel", "text": "Deploy started"}`,
+			expectCall:    true,
+			expectResult:  SlashCommandResult{MessageID: "message-id"},
+			expectMessage: "Deploy started",
+		},
+		{
+			name:         "plain text response",
+			statusCode:   200,
+			response:     "ok",
+			expectCall:   true,
+			expectResult: SlashCommandResult{Ephemeral: "ok"},
+		},
+		{
+			name:       "empty response",
+			statusCode: 200,
+			expectCall: true,
+		},
+		{name: "unknown command", commandErr: mongo.ErrNoDocuments, expectedErr: ErrSlashCommandUnknown},
+		{name: "bot deleted", botErr: mongo.ErrNoDocuments, expectedErr: ErrSlashCommandUnknown},
+		// 他のルームのボットが登録したコマンドは、このルームでは存在しないものとして扱う
+		{name: "bot not in room", members: []string{"uuid1"}, expectedErr: ErrSlashCommandUnknown},
+		{name: "bot banned from room", banned: []string{botSender}, expectedErr: ErrSlashCommandUnknown},
+		{name: "get command error", commandErr: assert.AnError, expectedErr: assert.AnError},
+		{name: "call error", callErr: assert.AnError, expectedErr: ErrSlashCommandFailed, expectCall: true},
+		{name: "error status", statusCode: 500, expectedErr: ErrSlashCommandFailed, expectCall: true},
+		{
+			name:        "too long response",
+			statusCode:  200,
+			response:    strings.Repeat("a", consts.SlashCommandMaxTextLength+1),
+			expectedErr: ErrSlashCommandFailed,
+			expectCall:  true,
+		},
+		{
+			name:          "send error",
+			statusCode:    200,
+			response:      `{"response_type": "in_channel", "text": "Deploy started"}`,
+			sendErr:       ErrSpamRejected,
+			expectedErr:   ErrSpamRejected,
+			expectCall:    true,
+			expectMessage: "Deploy started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room := model.Room{ID: roomID, Members: []string{"uuid1", botSender}, BannedMembers: tt.banned}
+			if tt.members != nil {
+				room.Members = tt.members
+			}
+			slashCommandSvcMock := new(mongo_svc_mock.SlashCommandSvcMock)
+			slashCommandSvcMock.On("GetSlashCommandByName", "deploy", mock.Anything).Return(command, tt.commandErr)
+			botSvcMock := new(mongo_svc_mock.BotSvcMock)
+			botSvcMock.On("GetBot", bot.ID.Hex(), mock.Anything).Return(bot, tt.botErr)
+			caller := &slashCommandCallerStub{statusCode: tt.statusCode, response: tt.response, err: tt.callErr}
+			sendSvc := &sendSvcStub{id: "message-id", err: tt.sendErr}
+
+			svc := NewSlashCommandSvc(slashCommandSvcMock, botSvcMock, nil, sendSvc, nil, nil, nil, nil, caller, atylabclock.NewClockMock(now))
+			result, err := svc.Execute(SlashCommandRequest{Name: "deploy", Text: "prod", Room: room, Uuid: "uuid1"}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectResult, result)
+			}
+
+			if !tt.expectCall {
+				assert.Empty(t, caller.urls)
+				return
+			}
+			assert.Equal(t, []string{command.URL}, caller.urls)
+			var payload SlashCommandPayload
+			assert.NoError(t, json.Unmarshal(caller.bodies[0], &payload))
+			assert.Equal(t, SlashCommandPayload{Command: "/deploy", Text: "prod", RoomID: room.ID.Hex(), UserID: "uuid1"}, payload)
+			assert.Equal(t, map[string]string{
+				consts.WebhookHeaders.Event:     consts.SlashCommandWebhookEvent,
+				consts.WebhookHeaders.Timestamp: "1735689600",
+				consts.WebhookHeaders.Signature: consts.WebhookSignaturePrefix + SignWebhookPayload("secret", now.Unix(), caller.bodies[0]),
+			}, caller.headers[0])
+
+			if tt.expectMessage == "" {
+				assert.Empty(t, sendSvc.messages)
+				return
+			}
+			assert.Equal(t, []model.Message{{
+				RoomID:        room.ID.Hex(),
+				Sender:        botSender,
+				Message:       tt.expectMessage,
+				CreatedAt:     now,
+				IsReadUserIds: []string{},
+				ExpiresAt:     MessageExpiresAt(0, room, now),
+				Bot:           BotMessageSender(bot),
+			}}, sendSvc.messages)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+)
+
+// ユーザー×本文ごとに、投稿したルームと回数を記録する
+// KEYS[1]: 本文のハッシュキー / ARGV[1]: ルームID ARGV[2]: 記録期間(ms)
+// 戻り値: {投稿したルーム数, 同じルームへの投稿回数}
+const spamDuplicateScript = `
+local count = redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return {redis.call('HLEN', KEYS[1]), count}
+`
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)[^\s]+`)
+
+type SpamCheckResult struct {
+	Flagged bool
+	Reason  string
+	Detail  string
+}
+
+type SpamSvcInterface interface {
+	Check(message model.Message) (SpamCheckResult, error)
+}
+
+type SpamSvc struct {
+	redis usecase.RedisUseCaseInterface
+}
+
+func NewSpamSvc(
+	redis usecase.RedisUseCaseInterface,
+) SpamSvcInterface {
+	return &SpamSvc{
+		redis: redis,
+	}
+}
+
+func (s *SpamSvc) Check(message model.Message) (SpamCheckResult, error) {
+	if result := s.checkLinkDensity(message.Message); result.Flagged {
+		return result, nil
+	}
+
+	return s.checkDuplicate(message)
+}
+
+func (s *SpamSvc) checkLinkDensity(body string) SpamCheckResult {
+	links := linkPattern.FindAllString(body, -1)
+	if len(links) == 0 {
+		return SpamCheckResult{}
+	}
+
+	linkLength := 0
+	for _, link := range links {
+		linkLength += utf8.RuneCountInString(link)
+	}
+	density := float64(linkLength) / float64(utf8.RuneCountInString(strings.TrimSpace(body)))
+
+	if len(links) >= consts.SpamMaxLinks || (len(links) >= 2 && density >= consts.SpamMaxLinkDensity) {
+		return SpamCheckResult{
+			Flagged: true,
+			Reason:  consts.SpamReasons.LinkDensity,
+			Detail:  fmt.Sprintf("%d links (%.0f%% of the message)", len(links), density*100),
+		}
+	}
+
+	return SpamCheckResult{}
+}
+
+func (s *SpamSvc) checkDuplicate(message model.Message) (SpamCheckResult, error) {
+	normalized := normalizeMessage(message.Message)
+	if utf8.RuneCountInString(normalized) < consts.SpamMinDuplicateLength {
+		return SpamCheckResult{}, nil
+	}
+
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return SpamCheckResult{}, err
+	}
+
+	hash := sha256.Sum256([]byte(normalized))
+	key := "spam:dup:" + message.Sender + ":" + hex.EncodeToString(hash[:])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	raw, err := redis.Cmd.Eval(ctx, spamDuplicateScript, []string{key}, message.RoomID, consts.SpamDuplicateWindow.Milliseconds())
+	if err != nil {
+		return SpamCheckResult{}, err
+	}
+
+	values, ok := raw.([]interface{})
+	if !ok || len(values) != 2 {
+		return SpamCheckResult{}, fmt.Errorf("unexpected spam check result: %v", raw)
+	}
+	rooms := toInt64(values[0])
+	count := toInt64(values[1])
+
+	switch {
+	case rooms >= consts.SpamCrossRoomThreshold:
+		return SpamCheckResult{
+			Flagged: true,
+			Reason:  consts.SpamReasons.DuplicateAcrossRooms,
+			Detail:  fmt.Sprintf("same message posted to %d rooms", rooms),
+		}, nil
+	case count >= consts.SpamSameRoomThreshold:
+		return SpamCheckResult{
+			Flagged: true,
+			Reason:  consts.SpamReasons.DuplicateInRoom,
+			Detail:  fmt.Sprintf("same message posted %d times", count),
+		}, nil
+	}
+
+	return SpamCheckResult{}, nil
+}
+
+// 大文字小文字・空白・記号・数字の違いや、URLのクエリ文字列だけを変えた投稿を同じ内容として扱う
+func normalizeMessage(body string) string {
+	body = linkPattern.ReplaceAllStringFunc(body, func(link string) string {
+		if !strings.Contains(link, "://") {
+			link = "http://" + link
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			return link
+		}
+		return u.Host + u.Path
+	})
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(body) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
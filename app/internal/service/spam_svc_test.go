@@ -0,0 +1,161 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	expected := map[string]struct {
+		a string
+		b string
+	}{
+		"case and whitespace": {
+			a: "Buy cheap   watches NOW",
+			b: "buy cheap watches now",
+		},
+		"punctuation and digits": {
+			a: "Buy cheap watches now!!! 1",
+			b: "buy cheap watches now... 2",
+		},
+		"url query": {
+			a: "check this https://spam.example.com/offer?ref=room1",
+			b: "check this https://spam.example.com/offer?ref=room2#top",
+		},
+		"url without scheme": {
+			a: "check this www.spam.example.com/offer?ref=1",
+			b: "check this www.spam.example.com/offer?ref=2",
+		},
+		"japanese": {
+			a: "今だけ！激安セール開催中",
+			b: "今だけ激安セール開催中!!",
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, normalizeMessage(tt.a), normalizeMessage(tt.b))
+		})
+	}
+
+	assert.NotEqual(t, normalizeMessage("hello world"), normalizeMessage("goodbye world"))
+}
+
+func TestSpamCheckLinkDensity(t *testing.T) {
+	expected := map[string]struct {
+		body    string
+		flagged bool
+	}{
+		"no link": {
+			body: "hello everyone",
+		},
+		"single link": {
+			body: "https://example.com",
+		},
+		"links with text": {
+			body: "here is the document https://example.com/a and the spec https://example.com/b for tomorrow's meeting, please read them",
+		},
+		"mostly links": {
+			body:    "https://spam.example.com/a https://spam.example.com/b look",
+			flagged: true,
+		},
+		"too many links": {
+			body:    "a http://a.example b http://b.example c http://c.example d http://d.example e http://e.example and some long text to keep the ratio low enough",
+			flagged: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			svc := &SpamSvc{}
+			result := svc.checkLinkDensity(tt.body)
+			assert.Equal(t, tt.flagged, result.Flagged)
+			if tt.flagged {
+				assert.Equal(t, consts.SpamReasons.LinkDensity, result.Reason)
+			}
+		})
+	}
+}
+
+func TestSpamCheckDuplicate(t *testing.T) {
+	redisMock := setupMiniRedis(t)
+	svc := NewSpamSvc(redisMock)
+
+	post := func(sender, roomID, body string) SpamCheckResult {
+		result, err := svc.Check(model.Message{Sender: sender, RoomID: roomID, Message: body})
+		assert.NoError(t, err)
+		return result
+	}
+
+	// 別のルームへの同じ内容の投稿
+	assert.False(t, post("spammer", "room1", "Buy cheap watches now!").Flagged)
+	assert.False(t, post("spammer", "room2", "buy cheap watches now").Flagged)
+	result := post("spammer", "room3", "BUY CHEAP WATCHES NOW!!!")
+	assert.True(t, result.Flagged)
+	assert.Equal(t, consts.SpamReasons.DuplicateAcrossRooms, result.Reason)
+
+	// 他のユーザーの投稿は別に数える
+	assert.False(t, post("another", "room3", "Buy cheap watches now!").Flagged)
+
+	// 同じルームへの繰り返し投稿
+	for i := 1; i < consts.SpamSameRoomThreshold; i++ {
+		assert.False(t, post("repeater", "room1", "this is the same message").Flagged)
+	}
+	result = post("repeater", "room1", "this is the same message")
+	assert.True(t, result.Flagged)
+	assert.Equal(t, consts.SpamReasons.DuplicateInRoom, result.Reason)
+
+	// 短い投稿は重複判定しない
+	for i := 0; i < consts.SpamSameRoomThreshold+1; i++ {
+		assert.False(t, post("short", "room1", "ok!").Flagged)
+	}
+}
+
+func TestSpamCheckLinkDensityBeforeRedis(t *testing.T) {
+	redisMock := new(usecase_mock.RedisUseCaseMock)
+	svc := NewSpamSvc(redisMock)
+
+	result, err := svc.Check(model.Message{Message: "https://spam.example.com/a https://spam.example.com/b"})
+	assert.NoError(t, err)
+	assert.True(t, result.Flagged)
+	redisMock.AssertNotCalled(t, "RedisInit")
+}
+
+func TestSpamCheckDuplicateError(t *testing.T) {
+	expected := map[string]struct {
+		initErr    error
+		evalResult interface{}
+		evalErr    error
+	}{
+		"redis_init_error": {
+			initErr: assert.AnError,
+		},
+		"eval_error": {
+			evalErr: assert.AnError,
+		},
+		"unexpected_result": {
+			evalResult: []interface{}{int64(1)},
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			cmdMock := new(usecase_mock.RedisCmdMock)
+			cmdMock.On("Eval", mock.Anything, spamDuplicateScript, mock.Anything, mock.Anything).
+				Return(tt.evalResult, tt.evalErr)
+
+			redisMock := new(usecase_mock.RedisUseCaseMock)
+			redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, tt.initErr)
+
+			svc := NewSpamSvc(redisMock)
+			_, err := svc.Check(model.Message{Sender: "uuid", RoomID: "room1", Message: "long enough message"})
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+)
+
+// 再実行しても結果が変わらない失敗（元画像が壊れている・削除済みなど）
+var errThumbnailPermanent = errors.New("thumbnail cannot be generated")
+
+// 1回の確認で取得するジョブの数（他のワーカーに先を越された場合の候補）
+const thumbnailJobBatchSize = 10
+
+type ThumbnailSvcInterface interface {
+	Enqueue(message model.Message, ctx *atylabmongo.MongoCtxSvc) error
+	RunNext() (bool, error)
+}
+
+type ThumbnailSvc struct {
+	jobSvc     mongo_svc.ThumbnailJobSvcInterface
+	messageSvc mongo_svc.MessageSvcInterface
+	storage    usecase.StorageUseCaseInterface
+	clock      atylabclock.ClockInterface
+}
+
+func NewThumbnailSvc(
+	jobSvc mongo_svc.ThumbnailJobSvcInterface,
+	messageSvc mongo_svc.MessageSvcInterface,
+	storage usecase.StorageUseCaseInterface,
+	clock atylabclock.ClockInterface,
+) ThumbnailSvcInterface {
+	return &ThumbnailSvc{
+		jobSvc:     jobSvc,
+		messageSvc: messageSvc,
+		storage:    storage,
+		clock:      clock,
+	}
+}
+
+// 画像の添付ごとにサムネイル生成ジョブを登録する
+// 生成はワーカーが行うので、送信のレスポンスは待たせない
+func (s *ThumbnailSvc) Enqueue(message model.Message, ctx *atylabmongo.MongoCtxSvc) error {
+	now := s.clock.Now()
+	for _, attachment := range message.Attachments {
+		if !consts.ThumbnailSourceTypes[attachment.ContentType] {
+			continue
+		}
+
+		_, err := s.jobSvc.CreateJob(model.ThumbnailJob{
+			RoomID:       message.RoomID,
+			MessageID:    message.ID.Hex(),
+			AttachmentID: attachment.ID,
+			StorageKey:   attachment.StorageKey,
+			Status:       consts.ThumbnailJobStatus.Pending,
+			AvailableAt:  now,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}, ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 実行可能なジョブを1件確保して処理する
+// 処理したジョブがなければ false を返す
+func (s *ThumbnailSvc) RunNext() (bool, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	jobs, err := s.jobSvc.GetRunnableJobs(now, thumbnailJobBatchSize, ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, job := range jobs {
+		leased, err := s.jobSvc.LeaseJob(job, now, consts.ThumbnailJobLease, ctx)
+		if err != nil {
+			return false, err
+		}
+		if !leased {
+			continue
+		}
+
+		job.Status = consts.ThumbnailJobStatus.Processing
+		job.Attempts++
+		return true, s.finish(job, s.process(job))
+	}
+
+	return false, nil
+}
+
+func (s *ThumbnailSvc) finish(job model.ThumbnailJob, processErr error) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	switch {
+	case processErr == nil:
+		return s.jobSvc.CompleteJob(job, now, ctx)
+	case errors.Is(processErr, errThumbnailPermanent) || job.Attempts >= consts.ThumbnailJobMaxAttempts:
+		fmt.Println("Thumbnail job failed:", job.ID.Hex(), processErr)
+		return s.jobSvc.FailJob(job, now, processErr.Error(), ctx)
+	default:
+		retryAt := now.Add(consts.ThumbnailJobRetryBase << (job.Attempts - 1))
+		return s.jobSvc.RetryJob(job, now, retryAt, processErr.Error(), ctx)
+	}
+}
+
+func (s *ThumbnailSvc) process(job model.ThumbnailJob) error {
+	storage, err := s.storage.StorageInit()
+	if err != nil {
+		fmt.Println("Failed to initialize storage:", err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), consts.ThumbnailJobLease)
+	defer cancel()
+
+	data, err := s.read(ctx, storage.Backend, job.StorageKey)
+	if err != nil {
+		return err
+	}
+
+	decoded, err := decodeImage(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errThumbnailPermanent, err)
+	}
+
+	thumbnails := []model.AttachmentThumbnail{}
+	for _, size := range consts.ThumbnailSizes {
+		img := decoded.thumbnail(size.MaxEdge)
+		encoded, contentType, err := decoded.encode(img)
+		if err != nil {
+			return err
+		}
+
+		// 元画像と同じ場所に、サイズ名を付けて保存する
+		key := job.StorageKey + "_" + size.Name
+		if err := storage.Backend.Put(ctx, key, bytes.NewReader(encoded), int64(len(encoded)), contentType); err != nil {
+			return err
+		}
+		thumbnails = append(thumbnails, model.AttachmentThumbnail{
+			Size:        size.Name,
+			Width:       img.Bounds().Dx(),
+			Height:      img.Bounds().Dy(),
+			ContentType: contentType,
+			ByteSize:    int64(len(encoded)),
+			StorageKey:  key,
+		})
+	}
+
+	mongoCtx := atylabmongo.NewMongoCtxSvc()
+	defer mongoCtx.Cancel()
+
+	width, height := decoded.size()
+	return s.messageSvc.SetAttachmentImage(job.MessageID, job.RoomID, job.AttachmentID, width, height, thumbnails, mongoCtx)
+}
+
+func (s *ThumbnailSvc) read(ctx context.Context, backend usecase.StorageBackendInterface, key string) ([]byte, error) {
+	reader, err := backend.Get(ctx, key)
+	if errors.Is(err, usecase.ErrStorageObjectNotFound) {
+		return nil, fmt.Errorf("%w: %v", errThumbnailPermanent, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// 添付の上限サイズを超えるものは読み込まない
+	data, err := io.ReadAll(io.LimitReader(reader, consts.AttachmentMaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > consts.AttachmentMaxSize {
+		return nil, fmt.Errorf("%w: %v", errThumbnailPermanent, ErrAttachmentTooLarge)
+	}
+	return data, nil
+}
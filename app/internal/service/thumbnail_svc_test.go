@@ -0,0 +1,251 @@
+package service
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestThumbnailEnqueue(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	messageID := primitive.NewObjectID()
+	message := model.Message{
+		ID:     messageID,
+		RoomID: "room1",
+		Attachments: []model.Attachment{
+			{ID: "att1", ContentType: "image/jpeg", StorageKey: "attachments/room1/att1"},
+			{ID: "att2", ContentType: "application/pdf", StorageKey: "attachments/room1/att2"},
+			{ID: "att3", ContentType: "image/png", StorageKey: "attachments/room1/att3"},
+		},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+		for _, attachmentID := range []string{"att1", "att3"} {
+			jobSvcMock.On("CreateJob", model.ThumbnailJob{
+				RoomID:       "room1",
+				MessageID:    messageID.Hex(),
+				AttachmentID: attachmentID,
+				StorageKey:   "attachments/room1/" + attachmentID,
+				Status:       consts.ThumbnailJobStatus.Pending,
+				AvailableAt:  now,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}, mock.Anything).Return("job-"+attachmentID, nil)
+		}
+
+		svc := NewThumbnailSvc(jobSvcMock, nil, nil, atylabclock.NewClockMock(now))
+		assert.NoError(t, svc.Enqueue(message, nil))
+		// 画像以外の添付にはジョブを作らない
+		jobSvcMock.AssertNumberOfCalls(t, "CreateJob", 2)
+	})
+
+	t.Run("create error", func(t *testing.T) {
+		jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+		jobSvcMock.On("CreateJob", mock.Anything, mock.Anything).Return("", assert.AnError)
+
+		svc := NewThumbnailSvc(jobSvcMock, nil, nil, atylabclock.NewClockMock(now))
+		assert.Error(t, svc.Enqueue(message, nil))
+	})
+}
+
+func TestThumbnailRunNext(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var source bytes.Buffer
+	assert.NoError(t, png.Encode(&source, newTestImage(1280, 640)))
+
+	type result struct {
+		complete bool
+		fail     bool
+		retryAt  time.Time
+	}
+
+	expected := map[string]struct {
+		attempts  int
+		getErr    error
+		data      []byte
+		putErr    error
+		setErr    error
+		expectSet bool
+		expect    result
+	}{
+		"success": {
+			data:      source.Bytes(),
+			expectSet: true,
+			expect:    result{complete: true},
+		},
+		"object not found is not retried": {
+			getErr: usecase.ErrStorageObjectNotFound,
+			expect: result{fail: true},
+		},
+		"broken image is not retried": {
+			data:   []byte("not an image"),
+			expect: result{fail: true},
+		},
+		"storage error is retried": {
+			getErr: assert.AnError,
+			expect: result{retryAt: now.Add(consts.ThumbnailJobRetryBase)},
+		},
+		"retry interval grows with attempts": {
+			attempts: 2,
+			data:     source.Bytes(),
+			putErr:   assert.AnError,
+			expect:   result{retryAt: now.Add(consts.ThumbnailJobRetryBase * 4)},
+		},
+		"message update error is retried": {
+			data:      source.Bytes(),
+			setErr:    assert.AnError,
+			expectSet: true,
+			expect:    result{retryAt: now.Add(consts.ThumbnailJobRetryBase)},
+		},
+		"gives up after max attempts": {
+			attempts: consts.ThumbnailJobMaxAttempts - 1,
+			getErr:   assert.AnError,
+			expect:   result{fail: true},
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			job := model.ThumbnailJob{
+				ID:           primitive.NewObjectID(),
+				RoomID:       "room1",
+				MessageID:    "msg1",
+				AttachmentID: "att1",
+				StorageKey:   "attachments/room1/att1",
+				Status:       consts.ThumbnailJobStatus.Pending,
+				Attempts:     tt.attempts,
+			}
+			leased := job
+			leased.Status = consts.ThumbnailJobStatus.Processing
+			leased.Attempts = tt.attempts + 1
+
+			jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+			jobSvcMock.On("GetRunnableJobs", now, thumbnailJobBatchSize, mock.Anything).Return([]model.ThumbnailJob{job}, nil)
+			jobSvcMock.On("LeaseJob", job, now, consts.ThumbnailJobLease, mock.Anything).Return(true, nil)
+			jobSvcMock.On("CompleteJob", leased, now, mock.Anything).Return(nil)
+			jobSvcMock.On("FailJob", leased, now, mock.Anything, mock.Anything).Return(nil)
+			jobSvcMock.On("RetryJob", leased, now, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			backendMock := new(usecase_mock.StorageBackendMock)
+			if tt.getErr != nil {
+				backendMock.On("Get", mock.Anything, job.StorageKey).Return(nil, tt.getErr)
+			} else {
+				backendMock.On("Get", mock.Anything, job.StorageKey).Return(io.NopCloser(bytes.NewReader(tt.data)), nil)
+			}
+			backendMock.On("Put", mock.Anything, job.StorageKey+"_small", mock.Anything, mock.Anything, "image/png").Return(tt.putErr)
+			backendMock.On("Put", mock.Anything, job.StorageKey+"_medium", mock.Anything, mock.Anything, "image/png").Return(tt.putErr)
+			storageMock := new(usecase_mock.StorageUseCaseMock)
+			storageMock.On("StorageInit").Return(&usecase.Storage{Backend: backendMock}, nil)
+
+			messageSvcMock := new(mongo_svc_mock.MessageSvcMock)
+			messageSvcMock.On("SetAttachmentImage", "msg1", "room1", "att1", 1280, 640, mock.MatchedBy(func(thumbnails []model.AttachmentThumbnail) bool {
+				return len(thumbnails) == 2 &&
+					thumbnails[0] == model.AttachmentThumbnail{
+						Size: "small", Width: 160, Height: 80, ContentType: "image/png",
+						ByteSize: thumbnails[0].ByteSize, StorageKey: "attachments/room1/att1_small",
+					} &&
+					thumbnails[1].Size == "medium" && thumbnails[1].Width == 640 && thumbnails[1].Height == 320
+			}), mock.Anything).Return(tt.setErr)
+
+			svc := NewThumbnailSvc(jobSvcMock, messageSvcMock, storageMock, atylabclock.NewClockMock(now))
+			processed, err := svc.RunNext()
+			assert.NoError(t, err)
+			assert.True(t, processed)
+
+			if tt.expectSet {
+				messageSvcMock.AssertNumberOfCalls(t, "SetAttachmentImage", 1)
+			} else {
+				messageSvcMock.AssertNotCalled(t, "SetAttachmentImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			switch {
+			case tt.expect.complete:
+				jobSvcMock.AssertCalled(t, "CompleteJob", leased, now, mock.Anything)
+			case tt.expect.fail:
+				jobSvcMock.AssertCalled(t, "FailJob", leased, now, mock.Anything, mock.Anything)
+				jobSvcMock.AssertNotCalled(t, "RetryJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			default:
+				jobSvcMock.AssertCalled(t, "RetryJob", leased, now, tt.expect.retryAt, mock.Anything, mock.Anything)
+				jobSvcMock.AssertNotCalled(t, "FailJob", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestThumbnailRunNextLease(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := model.ThumbnailJob{ID: primitive.NewObjectID(), StorageKey: "attachments/room1/att1"}
+	second := model.ThumbnailJob{ID: primitive.NewObjectID(), StorageKey: "attachments/room1/att2"}
+
+	t.Run("no jobs", func(t *testing.T) {
+		jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+		jobSvcMock.On("GetRunnableJobs", now, thumbnailJobBatchSize, mock.Anything).Return([]model.ThumbnailJob{}, nil)
+
+		processed, err := NewThumbnailSvc(jobSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+		jobSvcMock.On("GetRunnableJobs", now, thumbnailJobBatchSize, mock.Anything).Return([]model.ThumbnailJob{}, assert.AnError)
+
+		processed, err := NewThumbnailSvc(jobSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("lease error", func(t *testing.T) {
+		jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+		jobSvcMock.On("GetRunnableJobs", now, thumbnailJobBatchSize, mock.Anything).Return([]model.ThumbnailJob{first}, nil)
+		jobSvcMock.On("LeaseJob", first, now, consts.ThumbnailJobLease, mock.Anything).Return(false, assert.AnError)
+
+		processed, err := NewThumbnailSvc(jobSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("all jobs taken by other workers", func(t *testing.T) {
+		jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+		jobSvcMock.On("GetRunnableJobs", now, thumbnailJobBatchSize, mock.Anything).Return([]model.ThumbnailJob{first, second}, nil)
+		jobSvcMock.On("LeaseJob", mock.Anything, now, consts.ThumbnailJobLease, mock.Anything).Return(false, nil)
+
+		processed, err := NewThumbnailSvc(jobSvcMock, nil, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+		jobSvcMock.AssertNumberOfCalls(t, "LeaseJob", 2)
+	})
+
+	t.Run("next job is leased when the first is taken", func(t *testing.T) {
+		jobSvcMock := new(mongo_svc_mock.ThumbnailJobSvcMock)
+		jobSvcMock.On("GetRunnableJobs", now, thumbnailJobBatchSize, mock.Anything).Return([]model.ThumbnailJob{first, second}, nil)
+		jobSvcMock.On("LeaseJob", first, now, consts.ThumbnailJobLease, mock.Anything).Return(false, nil)
+		jobSvcMock.On("LeaseJob", second, now, consts.ThumbnailJobLease, mock.Anything).Return(true, nil)
+		jobSvcMock.On("FailJob", mock.MatchedBy(func(job model.ThumbnailJob) bool {
+			return job.ID == second.ID
+		}), now, mock.Anything, mock.Anything).Return(nil)
+
+		backendMock := new(usecase_mock.StorageBackendMock)
+		backendMock.On("Get", mock.Anything, second.StorageKey).Return(nil, usecase.ErrStorageObjectNotFound)
+		storageMock := new(usecase_mock.StorageUseCaseMock)
+		storageMock.On("StorageInit").Return(&usecase.Storage{Backend: backendMock}, nil)
+
+		processed, err := NewThumbnailSvc(jobSvcMock, nil, storageMock, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.True(t, processed)
+		backendMock.AssertNotCalled(t, "Get", mock.Anything, first.StorageKey)
+	})
+}
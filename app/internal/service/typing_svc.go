@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+)
+
+// 入力中のユーザーを記録・解除し、ルームに通知するかどうかを返す
+// 入力中の記録は期限を過ぎたものから取り除き、間隔内に届いた入力中の通知は期限だけを延ばす
+// 入力をやめた通知は、入力中を通知して期限が切れていない場合に1回だけ行う
+// 開始と終了を繰り返した場合は、間隔内の2回目以降の開始を通知しないので、終了も通知しない
+// KEYS[1]: ルームの入力中のユーザーの一覧 KEYS[2]: ユーザーの通知間隔のキー KEYS[3]: 入力中を通知したことを表すキー
+// ARGV[1]: 現在時刻(ms) ARGV[2]: 入力中とみなす時間(ms) ARGV[3]: 通知間隔(ms) ARGV[4]: UUID ARGV[5]: 入力中の場合は 1
+// 戻り値: 通知する場合は 1
+const typingUpdateScript = `
+local now = tonumber(ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+if ARGV[5] == '1' then
+	redis.call('ZADD', KEYS[1], now + tonumber(ARGV[2]), ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	if redis.call('SET', KEYS[2], '1', 'PX', ARGV[3], 'NX') then
+		redis.call('SET', KEYS[3], '1', 'PX', ARGV[2])
+		return 1
+	end
+	return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[4])
+return redis.call('DEL', KEYS[3])
+`
+
+// 入力中のユーザーの一覧を返す
+// KEYS[1]: ルームの入力中のユーザーの一覧 / ARGV[1]: 現在時刻(ms)
+const typingListScript = `
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])
+return redis.call('ZRANGE', KEYS[1], 0, -1)
+`
+
+func typingKey(roomID string) string {
+	return "typing:room:" + roomID
+}
+
+func typingThrottleKey(roomID string, uuid string) string {
+	return "typing:throttle:" + roomID + ":" + uuid
+}
+
+func typingAnnouncedKey(roomID string, uuid string) string {
+	return "typing:announced:" + roomID + ":" + uuid
+}
+
+type TypingSvcInterface interface {
+	SetTyping(roomID string, uuid string, typing bool) error
+	GetTyping(roomID string) ([]string, error)
+}
+
+type TypingSvc struct {
+	redis    usecase.RedisUseCaseInterface
+	eventSvc EventSvcInterface
+	clock    atylabclock.ClockInterface
+}
+
+func NewTypingSvc(
+	redis usecase.RedisUseCaseInterface,
+	eventSvc EventSvcInterface,
+	clock atylabclock.ClockInterface,
+) TypingSvcInterface {
+	return &TypingSvc{
+		redis:    redis,
+		eventSvc: eventSvc,
+		clock:    clock,
+	}
+}
+
+type typingEvent struct {
+	Uuid   string `json:"uuid"`
+	Typing bool   `json:"typing"`
+	// 再接続時に読み直した古い通知を表示しないよう、入力中とみなす期限を付ける
+	ExpiresAt string `json:"expires_at,omitempty"`
+}
+
+// 入力中かどうかを記録し、本人以外のメンバーに通知する
+// Redis にだけ記録し、Mongo には保存しない
+func (s *TypingSvc) SetTyping(roomID string, uuid string, typing bool) error {
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	now := s.clock.Now()
+	typingFlag := "0"
+	if typing {
+		typingFlag = "1"
+	}
+	raw, err := redis.Cmd.Eval(
+		ctx,
+		typingUpdateScript,
+		[]string{typingKey(roomID), typingThrottleKey(roomID, uuid), typingAnnouncedKey(roomID, uuid)},
+		now.UnixMilli(),
+		consts.TypingTTL.Milliseconds(),
+		consts.TypingThrottle.Milliseconds(),
+		uuid,
+		typingFlag,
+	)
+	if err != nil {
+		return err
+	}
+	notify, ok := raw.(int64)
+	if !ok {
+		return fmt.Errorf("unexpected typing result: %v", raw)
+	}
+	if notify != 1 {
+		return nil
+	}
+
+	data := typingEvent{Uuid: uuid, Typing: typing}
+	if typing {
+		data.ExpiresAt = now.Add(consts.TypingTTL).UTC().Format(time.RFC3339Nano)
+	}
+	_, err = s.eventSvc.Publish(RoomEvent{
+		Type:       consts.EventTypes.Typing,
+		RoomID:     roomID,
+		Data:       data,
+		ExceptUuid: uuid,
+	})
+	return err
+}
+
+// ルームで入力中のユーザーを返す
+func (s *TypingSvc) GetTyping(roomID string) ([]string, error) {
+	redis, err := s.redis.RedisInit()
+	if err != nil {
+		fmt.Println("Failed to initialize Redis:", err)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	raw, err := redis.Cmd.Eval(ctx, typingListScript, []string{typingKey(roomID)}, s.clock.Now().UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	return redisStrings(raw)
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/usecase_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTypingSetTyping(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	redisMock, mr := setupMiniRedisServer(t)
+	events := &eventSvcStub{}
+	svcAt := func(at time.Time) TypingSvcInterface {
+		return NewTypingSvc(redisMock, events, atylabclock.NewClockMock(at))
+	}
+
+	// 本人以外のメンバーに通知する
+	assert.NoError(t, svcAt(now).SetTyping("room1", "uuid1", true))
+	assert.Len(t, events.events, 1)
+	assert.Equal(t, RoomEvent{
+		Type:   consts.EventTypes.Typing,
+		RoomID: "room1",
+		Data: typingEvent{
+			Uuid:      "uuid1",
+			Typing:    true,
+			ExpiresAt: "2025-01-01T00:00:05Z",
+		},
+		ExceptUuid: "uuid1",
+	}, events.events[0])
+
+	// 通知間隔内は期限だけを延ばし、通知しない
+	assert.NoError(t, svcAt(now.Add(time.Second)).SetTyping("room1", "uuid1", true))
+	assert.Len(t, events.events, 1)
+	typing, err := svcAt(now.Add(5 * time.Second)).GetTyping("room1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"uuid1"}, typing)
+
+	// 別のユーザーは別に数える
+	assert.NoError(t, svcAt(now.Add(time.Second)).SetTyping("room1", "uuid2", true))
+	assert.Len(t, events.events, 2)
+
+	// 通知間隔を過ぎたら再び通知する
+	mr.FastForward(consts.TypingThrottle)
+	assert.NoError(t, svcAt(now.Add(consts.TypingThrottle)).SetTyping("room1", "uuid1", true))
+	assert.Len(t, events.events, 3)
+
+	// 通知間隔内でも入力をやめたら通知し、一覧から外す
+	assert.NoError(t, svcAt(now.Add(4*time.Second)).SetTyping("room1", "uuid1", false))
+	assert.Len(t, events.events, 4)
+	assert.Equal(t, typingEvent{Uuid: "uuid1", Typing: false}, events.events[3].Data)
+	typing, err = svcAt(now.Add(4 * time.Second)).GetTyping("room1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"uuid2"}, typing)
+
+	// 入力中でなかった場合は通知しない
+	assert.NoError(t, svcAt(now.Add(4*time.Second)).SetTyping("room1", "uuid1", false))
+	assert.Len(t, events.events, 4)
+
+	// 期限を過ぎたものは一覧に含めない
+	typing, err = svcAt(now.Add(time.Minute)).GetTyping("room1")
+	assert.NoError(t, err)
+	assert.Empty(t, typing)
+}
+
+func TestTypingToggle(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	redisMock, mr := setupMiniRedisServer(t)
+	events := &eventSvcStub{}
+	svcAt := func(at time.Time) TypingSvcInterface {
+		return NewTypingSvc(redisMock, events, atylabclock.NewClockMock(at))
+	}
+
+	// 開始と終了を続けて繰り返しても、通知間隔内に通知するのは最初の開始とその終了だけ
+	for i, typing := range []bool{true, false, true, false, true} {
+		assert.NoError(t, svcAt(now.Add(time.Duration(i)*100*time.Millisecond)).SetTyping("room1", "uuid1", typing))
+	}
+	assert.Len(t, events.events, 2)
+	assert.Equal(t, typingEvent{Uuid: "uuid1", Typing: true, ExpiresAt: "2025-01-01T00:00:05Z"}, events.events[0].Data)
+	assert.Equal(t, typingEvent{Uuid: "uuid1", Typing: false}, events.events[1].Data)
+
+	// 通知しなくても入力中の一覧には最後の状態を反映する
+	typing, err := svcAt(now.Add(time.Second)).GetTyping("room1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"uuid1"}, typing)
+
+	// 通知していない開始に対する終了は通知しない
+	assert.NoError(t, svcAt(now.Add(time.Second)).SetTyping("room1", "uuid1", false))
+	assert.Len(t, events.events, 2)
+
+	// 通知間隔を過ぎたら、再び開始と終了を1回ずつ通知する
+	mr.FastForward(consts.TypingThrottle)
+	later := now.Add(consts.TypingThrottle)
+	for i, typing := range []bool{true, false, true, false} {
+		assert.NoError(t, svcAt(later.Add(time.Duration(i)*100*time.Millisecond)).SetTyping("room1", "uuid1", typing))
+	}
+	assert.Len(t, events.events, 4)
+	assert.Equal(t, typingEvent{Uuid: "uuid1", Typing: true, ExpiresAt: "2025-01-01T00:00:08Z"}, events.events[2].Data)
+	assert.Equal(t, typingEvent{Uuid: "uuid1", Typing: false}, events.events[3].Data)
+	typing, err = svcAt(later.Add(time.Second)).GetTyping("room1")
+	assert.NoError(t, err)
+	assert.Empty(t, typing)
+}
+
+func TestTypingError(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("init error", func(t *testing.T) {
+		redisMock := new(usecase_mock.RedisUseCaseMock)
+		redisMock.On("RedisInit").Return(&usecase.Redis{}, assert.AnError)
+		svc := NewTypingSvc(redisMock, &eventSvcStub{}, atylabclock.NewClockMock(now))
+
+		assert.Error(t, svc.SetTyping("room1", "uuid1", true))
+		_, err := svc.GetTyping("room1")
+		assert.Error(t, err)
+	})
+
+	tests := map[string]struct {
+		result any
+		err    error
+	}{
+		"eval error":        {result: nil, err: assert.AnError},
+		"unexpected result": {result: "1", err: nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmdMock := new(usecase_mock.RedisCmdMock)
+			cmdMock.On("Eval", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.result, tt.err)
+			redisMock := new(usecase_mock.RedisUseCaseMock)
+			redisMock.On("RedisInit").Return(&usecase.Redis{Cmd: cmdMock}, nil)
+			svc := NewTypingSvc(redisMock, &eventSvcStub{}, atylabclock.NewClockMock(now))
+
+			assert.Error(t, svc.SetTyping("room1", "uuid1", true))
+			_, err := svc.GetTyping("room1")
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("publish error", func(t *testing.T) {
+		svc := NewTypingSvc(setupMiniRedis(t), &eventSvcStub{err: assert.AnError}, atylabclock.NewClockMock(now))
+		assert.Error(t, svc.SetTyping("room1", "uuid1", true))
+	})
+}
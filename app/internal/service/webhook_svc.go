@@ -0,0 +1,269 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service/mongo_svc"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrWebhookLimitReached = fmt.Errorf("a room can have at most %d webhooks", consts.WebhookMaxPerRoom)
+
+// 再送しても届く見込みがない失敗（Webhook の削除・接続先の拒否など）
+var errWebhookDeliveryPermanent = errors.New("webhook delivery cannot be sent")
+
+// 1回の確認で取得する配信の数（他のインスタンスに先を越された場合の候補）
+const webhookDeliveryBatchSize = 10
+
+type WebhookSvcInterface interface {
+	Register(webhook model.Webhook, ctx *atylabmongo.MongoCtxSvc) (model.Webhook, error)
+	Dispatch(roomID string, event string, data any)
+	RunNext() (bool, error)
+}
+
+// message.created で送るデータ
+type WebhookMessageData struct {
+	MessageID string    `json:"message_id"`
+	Sender    string    `json:"sender"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// member.joined / member.left で送るデータ
+type WebhookMemberData struct {
+	UserID string `json:"user_id"`
+	// 本人の操作でなく管理者が追加・削除した場合の操作者
+	ActorID string `json:"actor_id,omitempty"`
+}
+
+// 配信する本文
+type webhookPayload struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	RoomID    string    `json:"room_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type WebhookSvc struct {
+	webhookSvc  mongo_svc.WebhookSvcInterface
+	deliverySvc mongo_svc.WebhookDeliverySvcInterface
+	poster      usecase.WebhookPosterInterface
+	clock       atylabclock.ClockInterface
+}
+
+func NewWebhookSvc(
+	webhookSvc mongo_svc.WebhookSvcInterface,
+	deliverySvc mongo_svc.WebhookDeliverySvcInterface,
+	poster usecase.WebhookPosterInterface,
+	clock atylabclock.ClockInterface,
+) WebhookSvcInterface {
+	return &WebhookSvc{
+		webhookSvc:  webhookSvc,
+		deliverySvc: deliverySvc,
+		poster:      poster,
+		clock:       clock,
+	}
+}
+
+// URL と登録数を確認して Webhook を登録する
+// 署名の鍵はここで生成し、登録した Webhook と一緒に返す
+func (s *WebhookSvc) Register(webhook model.Webhook, ctx *atylabmongo.MongoCtxSvc) (model.Webhook, error) {
+	if err := usecase.ValidateWebhookURL(webhook.URL); err != nil {
+		return model.Webhook{}, err
+	}
+
+	webhooks, err := s.webhookSvc.GetWebhooks(webhook.RoomID, ctx)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	if len(webhooks) >= consts.WebhookMaxPerRoom {
+		return model.Webhook{}, ErrWebhookLimitReached
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return model.Webhook{}, err
+	}
+	webhook.Secret = hex.EncodeToString(secret)
+	webhook.CreatedAt = s.clock.Now()
+
+	webhookID, err := s.webhookSvc.CreateWebhook(webhook, ctx)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	webhook.ID, err = primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// イベントを購読している Webhook ごとに配信を登録する
+// 送信はワーカーが行うので、呼び出し元のリクエストは待たせない
+// 失敗してもイベント自体の処理は止めず、ログに残すだけにする
+func (s *WebhookSvc) Dispatch(roomID string, event string, data any) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	webhooks, err := s.webhookSvc.GetSubscribedWebhooks(roomID, event, ctx)
+	if err != nil {
+		fmt.Println("Failed to get webhooks:", err)
+		return
+	}
+
+	now := s.clock.Now()
+	for _, webhook := range webhooks {
+		// 受信側で重複を判定できるよう、配信IDを本文にも含める
+		deliveryID := primitive.NewObjectID()
+		payload, err := json.Marshal(webhookPayload{
+			ID:        deliveryID.Hex(),
+			Event:     event,
+			RoomID:    roomID,
+			CreatedAt: now,
+			Data:      data,
+		})
+		if err != nil {
+			fmt.Println("Failed to encode webhook payload:", err)
+			return
+		}
+
+		_, err = s.deliverySvc.CreateDelivery(model.WebhookDelivery{
+			ID:          deliveryID,
+			WebhookID:   webhook.ID.Hex(),
+			RoomID:      roomID,
+			Event:       event,
+			Payload:     string(payload),
+			Status:      consts.WebhookDeliveryStatus.Pending,
+			AvailableAt: now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}, ctx)
+		if err != nil {
+			fmt.Println("Failed to create webhook delivery:", webhook.ID.Hex(), err)
+		}
+	}
+}
+
+// 送信待ちの配信を1件確保して送信する
+// 送信した配信がなければ false を返す
+func (s *WebhookSvc) RunNext() (bool, error) {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	now := s.clock.Now()
+	deliveries, err := s.deliverySvc.GetRunnableDeliveries(now, webhookDeliveryBatchSize, ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, delivery := range deliveries {
+		leased, err := s.deliverySvc.LeaseDelivery(delivery, now, consts.WebhookDeliveryLease, ctx)
+		if err != nil {
+			return false, err
+		}
+		if !leased {
+			continue
+		}
+
+		delivery.Status = consts.WebhookDeliveryStatus.Processing
+		delivery.Attempts++
+		attempt, err := s.send(delivery)
+		return true, s.finish(delivery, attempt, err)
+	}
+
+	return false, nil
+}
+
+func (s *WebhookSvc) send(delivery model.WebhookDelivery) (model.WebhookAttempt, error) {
+	attempt := model.WebhookAttempt{AttemptedAt: s.clock.Now()}
+
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	// 登録後に削除された Webhook には送らない
+	webhook, err := s.webhookSvc.GetWebhook(delivery.WebhookID, ctx)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return attempt, fmt.Errorf("%w: webhook was deleted", errWebhookDeliveryPermanent)
+	}
+	if err != nil {
+		return attempt, err
+	}
+
+	timestamp := attempt.AttemptedAt.Unix()
+	headers := map[string]string{
+		consts.WebhookHeaders.Event:     delivery.Event,
+		consts.WebhookHeaders.Delivery:  delivery.ID.Hex(),
+		consts.WebhookHeaders.Timestamp: strconv.FormatInt(timestamp, 10),
+		consts.WebhookHeaders.Signature: consts.WebhookSignaturePrefix + SignWebhookPayload(webhook.Secret, timestamp, []byte(delivery.Payload)),
+	}
+
+	postCtx, cancel := context.WithTimeout(context.Background(), consts.WebhookTimeout)
+	defer cancel()
+
+	started := time.Now()
+	statusCode, err := s.poster.Post(postCtx, webhook.URL, headers, []byte(delivery.Payload))
+	attempt.DurationMs = time.Since(started).Milliseconds()
+	attempt.StatusCode = statusCode
+	if errors.Is(err, usecase.ErrWebhookAddressNotAllowed) {
+		return attempt, fmt.Errorf("%w: %v", errWebhookDeliveryPermanent, err)
+	}
+	if err != nil {
+		return attempt, err
+	}
+	if statusCode < 200 || statusCode >= 300 {
+		return attempt, fmt.Errorf("unexpected status code: %d", statusCode)
+	}
+	return attempt, nil
+}
+
+func (s *WebhookSvc) finish(delivery model.WebhookDelivery, attempt model.WebhookAttempt, sendErr error) error {
+	ctx := atylabmongo.NewMongoCtxSvc()
+	defer ctx.Cancel()
+
+	if sendErr != nil {
+		attempt.Error = truncateWebhookError(sendErr.Error())
+	}
+
+	switch {
+	case sendErr == nil:
+		return s.deliverySvc.CompleteDelivery(delivery, attempt, ctx)
+	case errors.Is(sendErr, errWebhookDeliveryPermanent) || delivery.Attempts >= consts.WebhookDeliveryMaxAttempts:
+		fmt.Println("Webhook delivery failed:", delivery.ID.Hex(), sendErr)
+		return s.deliverySvc.FailDelivery(delivery, attempt, ctx)
+	default:
+		retryAt := s.clock.Now().Add(consts.WebhookDeliveryRetryBase << (delivery.Attempts - 1))
+		return s.deliverySvc.RetryDelivery(delivery, attempt, retryAt, ctx)
+	}
+}
+
+func truncateWebhookError(message string) string {
+	runes := []rune(message)
+	if len(runes) <= consts.WebhookDeliveryErrorMaxLength {
+		return message
+	}
+	return string(runes[:consts.WebhookDeliveryErrorMaxLength])
+}
+
+// 「タイムスタンプ:本文」の HMAC-SHA256 を16進数で返す
+// タイムスタンプも署名に含めることで、受信側で古い配信の再送を拒否できるようにする
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d:%s", timestamp, payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
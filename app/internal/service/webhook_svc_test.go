@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/consts"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/mocks/svc_mock/mongo_svc_mock"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabclock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type webhookPosterStub struct {
+	urls       []string
+	statusCode int
+	err        error
+}
+
+func (p *webhookPosterStub) Post(ctx context.Context, rawURL string, headers map[string]string, body []byte) (int, error) {
+	p.urls = append(p.urls, rawURL)
+	return p.statusCode, p.err
+}
+
+func TestWebhookRegister(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhookID := primitive.NewObjectID()
+
+	tests := []struct {
+		name         string
+		url          string
+		count        int
+		getErr       error
+		createErr    error
+		expectedErr  error
+		expectCreate bool
+	}{
+		{"success", "https://example.com/hook", 0, nil, nil, nil, true},
+		{"invalid url", "ftp://example.com/hook", 0, nil, nil, usecase.ErrWebhookAddressNotAllowed, false},
+		{"limit reached", "https://example.com/hook", consts.WebhookMaxPerRoom, nil, nil, ErrWebhookLimitReached, false},
+		{"get error", "https://example.com/hook", 0, assert.AnError, nil, assert.AnError, false},
+		{"create error", "https://example.com/hook", 0, nil, assert.AnError, assert.AnError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("GetWebhooks", "room1", mock.Anything).Return(make([]model.Webhook, tt.count), tt.getErr)
+			webhookSvcMock.On("CreateWebhook", mock.MatchedBy(func(w model.Webhook) bool {
+				return w.RoomID == "room1" && w.URL == tt.url && len(w.Secret) == 64 && w.CreatedAt.Equal(now)
+			}), mock.Anything).Return(webhookID.Hex(), tt.createErr)
+
+			svc := NewWebhookSvc(webhookSvcMock, nil, nil, atylabclock.NewClockMock(now))
+			webhook, err := svc.Register(model.Webhook{
+				RoomID:    "room1",
+				URL:       tt.url,
+				Events:    []string{consts.WebhookEvents.MessageCreated},
+				CreatedBy: "uuid",
+			}, nil)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, webhookID, webhook.ID)
+				assert.Len(t, webhook.Secret, 64)
+				assert.Equal(t, "uuid", webhook.CreatedBy)
+			}
+			if tt.expectCreate {
+				webhookSvcMock.AssertNumberOfCalls(t, "CreateWebhook", 1)
+			} else {
+				webhookSvcMock.AssertNotCalled(t, "CreateWebhook", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatch(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := model.Webhook{ID: primitive.NewObjectID(), RoomID: "room1"}
+	second := model.Webhook{ID: primitive.NewObjectID(), RoomID: "room1"}
+
+	t.Run("creates a delivery for each subscribed webhook", func(t *testing.T) {
+		webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+		webhookSvcMock.On("GetSubscribedWebhooks", "room1", consts.WebhookEvents.MemberJoined, mock.Anything).Return([]model.Webhook{first, second}, nil)
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+		var deliveries []model.WebhookDelivery
+		deliverySvcMock.On("CreateDelivery", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			deliveries = append(deliveries, args.Get(0).(model.WebhookDelivery))
+		}).Return("delivery-id", nil)
+
+		svc := NewWebhookSvc(webhookSvcMock, deliverySvcMock, nil, atylabclock.NewClockMock(now))
+		svc.Dispatch("room1", consts.WebhookEvents.MemberJoined, WebhookMemberData{UserID: "uuid"})
+
+		assert.Len(t, deliveries, 2)
+		for i, webhook := range []model.Webhook{first, second} {
+			delivery := deliveries[i]
+			assert.Equal(t, webhook.ID.Hex(), delivery.WebhookID)
+			assert.Equal(t, "room1", delivery.RoomID)
+			assert.Equal(t, consts.WebhookEvents.MemberJoined, delivery.Event)
+			assert.Equal(t, consts.WebhookDeliveryStatus.Pending, delivery.Status)
+			assert.Equal(t, now, delivery.AvailableAt)
+
+			var payload map[string]any
+			assert.NoError(t, json.Unmarshal([]byte(delivery.Payload), &payload))
+			assert.Equal(t, map[string]any{
+				"id":         delivery.ID.Hex(),
+				"event":      consts.WebhookEvents.MemberJoined,
+				"room_id":    "room1",
+				"created_at": now.Format(time.RFC3339),
+				"data":       map[string]any{"user_id": "uuid"},
+			}, payload)
+		}
+	})
+
+	t.Run("no subscribed webhooks", func(t *testing.T) {
+		webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+		webhookSvcMock.On("GetSubscribedWebhooks", "room1", consts.WebhookEvents.RoomDeleted, mock.Anything).Return([]model.Webhook{}, nil)
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+
+		NewWebhookSvc(webhookSvcMock, deliverySvcMock, nil, atylabclock.NewClockMock(now)).Dispatch("room1", consts.WebhookEvents.RoomDeleted, nil)
+		deliverySvcMock.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+		webhookSvcMock.On("GetSubscribedWebhooks", "room1", consts.WebhookEvents.RoomDeleted, mock.Anything).Return([]model.Webhook{}, assert.AnError)
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+
+		NewWebhookSvc(webhookSvcMock, deliverySvcMock, nil, atylabclock.NewClockMock(now)).Dispatch("room1", consts.WebhookEvents.RoomDeleted, nil)
+		deliverySvcMock.AssertNotCalled(t, "CreateDelivery", mock.Anything, mock.Anything)
+	})
+
+	t.Run("create error continues with the next webhook", func(t *testing.T) {
+		webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+		webhookSvcMock.On("GetSubscribedWebhooks", "room1", consts.WebhookEvents.MemberLeft, mock.Anything).Return([]model.Webhook{first, second}, nil)
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+		deliverySvcMock.On("CreateDelivery", mock.Anything, mock.Anything).Return("", assert.AnError)
+
+		NewWebhookSvc(webhookSvcMock, deliverySvcMock, nil, atylabclock.NewClockMock(now)).Dispatch("room1", consts.WebhookEvents.MemberLeft, nil)
+		deliverySvcMock.AssertNumberOfCalls(t, "CreateDelivery", 2)
+	})
+}
+
+func TestWebhookRunNextSignsPayload(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	payload := `{"event":"message.created"}`
+
+	received := make(chan *http.Request, 1)
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := model.Webhook{ID: primitive.NewObjectID(), RoomID: "room1", URL: server.URL + "/hook", Secret: "secret"}
+	delivery := model.WebhookDelivery{
+		ID:        primitive.NewObjectID(),
+		WebhookID: webhook.ID.Hex(),
+		RoomID:    "room1",
+		Event:     consts.WebhookEvents.MessageCreated,
+		Payload:   payload,
+		Status:    consts.WebhookDeliveryStatus.Pending,
+	}
+
+	webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+	webhookSvcMock.On("GetWebhook", webhook.ID.Hex(), mock.Anything).Return(webhook, nil)
+	deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+	deliverySvcMock.On("GetRunnableDeliveries", now, webhookDeliveryBatchSize, mock.Anything).Return([]model.WebhookDelivery{delivery}, nil)
+	deliverySvcMock.On("LeaseDelivery", delivery, now, consts.WebhookDeliveryLease, mock.Anything).Return(true, nil)
+	deliverySvcMock.On("CompleteDelivery", mock.Anything, mock.MatchedBy(func(a model.WebhookAttempt) bool {
+		return a.StatusCode == http.StatusNoContent && a.Error == "" && a.AttemptedAt.Equal(now)
+	}), mock.Anything).Return(nil)
+
+	poster := usecase.NewWebhookPosterStruct(usecase.WebhookPosterConfig{
+		Timeout:   time.Second,
+		AllowAddr: func(netip.Addr) bool { return true },
+	})
+	processed, err := NewWebhookSvc(webhookSvcMock, deliverySvcMock, poster, atylabclock.NewClockMock(now)).RunNext()
+	assert.NoError(t, err)
+	assert.True(t, processed)
+	deliverySvcMock.AssertNumberOfCalls(t, "CompleteDelivery", 1)
+
+	r := <-received
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	assert.Equal(t, payload, string(body))
+	assert.Equal(t, consts.WebhookEvents.MessageCreated, r.Header.Get(consts.WebhookHeaders.Event))
+	assert.Equal(t, delivery.ID.Hex(), r.Header.Get(consts.WebhookHeaders.Delivery))
+	assert.Equal(t, timestamp, r.Header.Get(consts.WebhookHeaders.Timestamp))
+	assert.Equal(t, "sha256="+SignWebhookPayload("secret", now.Unix(), []byte(payload)), r.Header.Get(consts.WebhookHeaders.Signature))
+}
+
+func TestWebhookRunNext(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhook := model.Webhook{ID: primitive.NewObjectID(), RoomID: "room1", URL: "https://example.com/hook", Secret: "secret"}
+	delivery := model.WebhookDelivery{
+		ID:        primitive.NewObjectID(),
+		WebhookID: webhook.ID.Hex(),
+		RoomID:    "room1",
+		Event:     consts.WebhookEvents.MessageCreated,
+		Payload:   `{}`,
+		Status:    consts.WebhookDeliveryStatus.Pending,
+	}
+
+	type expectation struct {
+		complete bool
+		fail     bool
+		retryAt  time.Time
+	}
+
+	tests := []struct {
+		name       string
+		attempts   int
+		webhookErr error
+		statusCode int
+		postErr    error
+		expectPost bool
+		expect     expectation
+	}{
+		{"delivered", 0, nil, 200, nil, true, expectation{complete: true}},
+		{"webhook deleted", 0, mongo.ErrNoDocuments, 0, nil, false, expectation{fail: true}},
+		{"webhook lookup error", 0, assert.AnError, 0, nil, false, expectation{retryAt: now.Add(consts.WebhookDeliveryRetryBase)}},
+		{"address not allowed", 0, nil, 0, usecase.ErrWebhookAddressNotAllowed, true, expectation{fail: true}},
+		{"connection error", 0, nil, 0, assert.AnError, true, expectation{retryAt: now.Add(consts.WebhookDeliveryRetryBase)}},
+		{"error status", 0, nil, 500, nil, true, expectation{retryAt: now.Add(consts.WebhookDeliveryRetryBase)}},
+		{"redirect is not followed", 0, nil, 302, nil, true, expectation{retryAt: now.Add(consts.WebhookDeliveryRetryBase)}},
+		{"error status backs off", 3, nil, 500, nil, true, expectation{retryAt: now.Add(consts.WebhookDeliveryRetryBase * 8)}},
+		{"error status after max attempts", consts.WebhookDeliveryMaxAttempts - 1, nil, 500, nil, true, expectation{fail: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runnable := delivery
+			runnable.Attempts = tt.attempts
+			leased := runnable
+			leased.Status = consts.WebhookDeliveryStatus.Processing
+			leased.Attempts = tt.attempts + 1
+
+			webhookSvcMock := new(mongo_svc_mock.WebhookSvcMock)
+			webhookSvcMock.On("GetWebhook", webhook.ID.Hex(), mock.Anything).Return(webhook, tt.webhookErr)
+			deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+			deliverySvcMock.On("GetRunnableDeliveries", now, webhookDeliveryBatchSize, mock.Anything).Return([]model.WebhookDelivery{runnable}, nil)
+			deliverySvcMock.On("LeaseDelivery", runnable, now, consts.WebhookDeliveryLease, mock.Anything).Return(true, nil)
+			deliverySvcMock.On("CompleteDelivery", leased, mock.Anything, mock.Anything).Return(nil)
+			deliverySvcMock.On("FailDelivery", leased, mock.Anything, mock.Anything).Return(nil)
+			deliverySvcMock.On("RetryDelivery", leased, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			poster := &webhookPosterStub{statusCode: tt.statusCode, err: tt.postErr}
+
+			processed, err := NewWebhookSvc(webhookSvcMock, deliverySvcMock, poster, atylabclock.NewClockMock(now)).RunNext()
+			assert.NoError(t, err)
+			assert.True(t, processed)
+
+			if tt.expectPost {
+				assert.Equal(t, []string{webhook.URL}, poster.urls)
+			} else {
+				assert.Empty(t, poster.urls)
+			}
+
+			recorded := mock.MatchedBy(func(a model.WebhookAttempt) bool {
+				return a.AttemptedAt.Equal(now) && a.StatusCode == tt.statusCode && (a.Error == "") == tt.expect.complete
+			})
+			switch {
+			case tt.expect.complete:
+				deliverySvcMock.AssertCalled(t, "CompleteDelivery", leased, recorded, mock.Anything)
+				deliverySvcMock.AssertNotCalled(t, "FailDelivery", mock.Anything, mock.Anything, mock.Anything)
+				deliverySvcMock.AssertNotCalled(t, "RetryDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			case tt.expect.fail:
+				deliverySvcMock.AssertCalled(t, "FailDelivery", leased, recorded, mock.Anything)
+				deliverySvcMock.AssertNotCalled(t, "RetryDelivery", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			default:
+				deliverySvcMock.AssertCalled(t, "RetryDelivery", leased, recorded, tt.expect.retryAt, mock.Anything)
+				deliverySvcMock.AssertNotCalled(t, "FailDelivery", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWebhookRunNextLease(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := model.WebhookDelivery{ID: primitive.NewObjectID()}
+	second := model.WebhookDelivery{ID: primitive.NewObjectID()}
+
+	t.Run("nothing runnable", func(t *testing.T) {
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+		deliverySvcMock.On("GetRunnableDeliveries", now, webhookDeliveryBatchSize, mock.Anything).Return([]model.WebhookDelivery{}, nil)
+
+		processed, err := NewWebhookSvc(nil, deliverySvcMock, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+		deliverySvcMock.On("GetRunnableDeliveries", now, webhookDeliveryBatchSize, mock.Anything).Return([]model.WebhookDelivery{}, assert.AnError)
+
+		processed, err := NewWebhookSvc(nil, deliverySvcMock, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("lease error", func(t *testing.T) {
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+		deliverySvcMock.On("GetRunnableDeliveries", now, webhookDeliveryBatchSize, mock.Anything).Return([]model.WebhookDelivery{first}, nil)
+		deliverySvcMock.On("LeaseDelivery", first, now, consts.WebhookDeliveryLease, mock.Anything).Return(false, assert.AnError)
+
+		processed, err := NewWebhookSvc(nil, deliverySvcMock, nil, atylabclock.NewClockMock(now)).RunNext()
+		assert.Error(t, err)
+		assert.False(t, processed)
+	})
+
+	t.Run("taken by other instances", func(t *testing.T) {
+		deliverySvcMock := new(mongo_svc_mock.WebhookDeliverySvcMock)
+		deliverySvcMock.On("GetRunnableDeliveries", now, webhookDeliveryBatchSize, mock.Anything).Return([]model.WebhookDelivery{first, second}, nil)
+		deliverySvcMock.On("LeaseDelivery", mock.Anything, now, consts.WebhookDeliveryLease, mock.Anything).Return(false, nil)
+		poster := &webhookPosterStub{}
+
+		processed, err := NewWebhookSvc(nil, deliverySvcMock, poster, atylabclock.NewClockMock(now)).RunNext()
+		assert.NoError(t, err)
+		assert.False(t, processed)
+		assert.Empty(t, poster.urls)
+		deliverySvcMock.AssertNumberOfCalls(t, "LeaseDelivery", 2)
+	})
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	signature := SignWebhookPayload("secret", 1700000000, []byte(`{"a":1}`))
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, SignWebhookPayload("secret", 1700000000, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("other", 1700000000, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("secret", 1700000001, []byte(`{"a":1}`)))
+	assert.NotEqual(t, signature, SignWebhookPayload("secret", 1700000000, []byte(`{"a":2}`)))
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrLinkAddressNotAllowed = errors.New("link address is not allowed")
+	ErrLinkNotHTML           = errors.New("link is not an html page")
+)
+
+// リンクプレビュー用にページを取得する
+// テストでは httptest のサーバーに向けられるよう、接続先の判定を差し替えられるようにしている
+type LinkFetcherInterface interface {
+	Fetch(ctx context.Context, rawURL string) (LinkPage, error)
+}
+
+type LinkPage struct {
+	// リダイレクト後のURL（相対パスの画像URLを解決するのに使う）
+	URL         string
+	ContentType string
+	Body        []byte
+}
+
+type LinkFetcherConfig struct {
+	Timeout      time.Duration
+	MaxBodySize  int64
+	MaxRedirects int
+	// 接続してよいアドレスかどうか。nil の場合は IsPublicAddr を使う
+	AllowAddr func(netip.Addr) bool
+}
+
+type LinkFetcherStruct struct {
+	client      *http.Client
+	maxBodySize int64
+}
+
+func NewLinkFetcherStruct(config LinkFetcherConfig) *LinkFetcherStruct {
+	allowAddr := config.AllowAddr
+	if allowAddr == nil {
+		allowAddr = IsPublicAddr
+	}
+
+	transport := newPublicTransport(config.Timeout, allowAddr, ErrLinkAddressNotAllowed)
+
+	return &LinkFetcherStruct{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > config.MaxRedirects {
+					return fmt.Errorf("stopped after %d redirects", config.MaxRedirects)
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return fmt.Errorf("%w: %s", ErrLinkAddressNotAllowed, req.URL.Scheme)
+				}
+				return nil
+			},
+		},
+		maxBodySize: config.MaxBodySize,
+	}
+}
+
+func (f *LinkFetcherStruct) Fetch(ctx context.Context, rawURL string) (LinkPage, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return LinkPage{}, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return LinkPage{}, fmt.Errorf("%w: %s", ErrLinkAddressNotAllowed, rawURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return LinkPage{}, err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "ChatLinkPreview/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return LinkPage{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return LinkPage{}, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "text/html" && mediaType != "application/xhtml+xml") {
+		return LinkPage{}, fmt.Errorf("%w: %s", ErrLinkNotHTML, contentType)
+	}
+
+	// OGP は head に書かれるので、上限を超えた分は読まずに切り捨てる
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBodySize))
+	if err != nil {
+		return LinkPage{}, err
+	}
+
+	return LinkPage{
+		URL:         resp.Request.URL.String(),
+		ContentType: contentType,
+		Body:        body,
+	}, nil
+}
+
+// allowAddr で許可したアドレスにだけ接続する Transport を作る
+// 許可しないアドレスへの接続は notAllowed をラップしたエラーになる
+func newPublicTransport(timeout time.Duration, allowAddr func(netip.Addr) bool, notAllowed error) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// 名前解決後の実際の接続先で判定するので、DNS の応答を差し替えられても内部には接続しない
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !allowAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", notAllowed, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	return &http.Transport{
+		// 環境変数のプロキシを経由すると接続先の判定が効かなくなるため使わない
+		Proxy:                 nil,
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+}
+
+// インターネット上のアドレスに限って接続を許可する
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+}
+
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() ||
+		addr.IsLinkLocalUnicast() || addr.IsUnspecified() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
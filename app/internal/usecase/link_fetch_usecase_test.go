@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLinkFetcher(allowAddr func(netip.Addr) bool) *LinkFetcherStruct {
+	return NewLinkFetcherStruct(LinkFetcherConfig{
+		Timeout:      time.Second,
+		MaxBodySize:  64,
+		MaxRedirects: 2,
+		AllowAddr:    allowAddr,
+	})
+}
+
+func allowAll(netip.Addr) bool {
+	return true
+}
+
+func TestLinkFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><head><title>hello</title></head><body>" + strings.Repeat("x", 200) + "</body></html>"))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("\x89PNG"))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(3 * time.Second):
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	t.Run("success", func(t *testing.T) {
+		page, err := newTestLinkFetcher(allowAll).Fetch(t.Context(), server.URL+"/page")
+		assert.NoError(t, err)
+		assert.Equal(t, server.URL+"/page", page.URL)
+		assert.Equal(t, "text/html; charset=utf-8", page.ContentType)
+		// 上限を超えた分は切り捨てる
+		assert.Len(t, page.Body, 64)
+		assert.True(t, strings.HasPrefix(string(page.Body), "<html><head><title>hello</title>"))
+	})
+
+	t.Run("follows redirects", func(t *testing.T) {
+		page, err := newTestLinkFetcher(allowAll).Fetch(t.Context(), server.URL+"/redirect")
+		assert.NoError(t, err)
+		assert.Equal(t, server.URL+"/page", page.URL)
+	})
+
+	t.Run("too many redirects", func(t *testing.T) {
+		_, err := newTestLinkFetcher(allowAll).Fetch(t.Context(), server.URL+"/loop")
+		assert.ErrorContains(t, err, "redirects")
+	})
+
+	t.Run("not html", func(t *testing.T) {
+		_, err := newTestLinkFetcher(allowAll).Fetch(t.Context(), server.URL+"/image")
+		assert.ErrorIs(t, err, ErrLinkNotHTML)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		_, err := newTestLinkFetcher(allowAll).Fetch(t.Context(), server.URL+"/missing")
+		assert.ErrorContains(t, err, "404")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		fetcher := NewLinkFetcherStruct(LinkFetcherConfig{Timeout: 100 * time.Millisecond, MaxBodySize: 64, AllowAddr: allowAll})
+		start := time.Now()
+		_, err := fetcher.Fetch(t.Context(), server.URL+"/slow")
+		assert.Error(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("private address is blocked", func(t *testing.T) {
+		// 既定の判定ではループバックアドレスに接続しない
+		_, err := newTestLinkFetcher(nil).Fetch(t.Context(), server.URL+"/page")
+		assert.ErrorIs(t, err, ErrLinkAddressNotAllowed)
+	})
+
+	t.Run("redirect to private address is blocked", func(t *testing.T) {
+		public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, server.URL+"/page", http.StatusFound)
+		}))
+		defer public.Close()
+		publicAddr := netip.MustParseAddrPort(strings.TrimPrefix(public.URL, "http://"))
+
+		// リダイレクト元のサーバーだけを許可し、リダイレクト先は既定の判定にかける
+		allowed := 0
+		fetcher := newTestLinkFetcher(func(addr netip.Addr) bool {
+			allowed++
+			return allowed == 1 && addr == publicAddr.Addr()
+		})
+		_, err := fetcher.Fetch(t.Context(), public.URL)
+		assert.ErrorIs(t, err, ErrLinkAddressNotAllowed)
+	})
+
+	t.Run("unsupported scheme", func(t *testing.T) {
+		for _, rawURL := range []string{"file:///etc/passwd", "gopher://example.com", "http://", "://bad"} {
+			_, err := newTestLinkFetcher(allowAll).Fetch(t.Context(), rawURL)
+			assert.Error(t, err, rawURL)
+		}
+	})
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	expected := map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::1":   true,
+		"127.0.0.1":            false,
+		"10.0.0.1":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"255.255.255.255":      false,
+		"224.0.0.1":            false,
+		"::1":                  false,
+		"fd00::1":              false,
+		"fe80::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:93.184.216.34": true,
+		"64:ff9b::a00:1":       false,
+		"2002:a00:1::1":        false,
+		"240.0.0.1":            false,
+		"198.18.0.1":           false,
+	}
+
+	for addr, public := range expected {
+		assert.Equal(t, public, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// atylabmongo では一括の挿入・削除や並び順の指定ができないため、
+// インデックスの作成と同じくドライバーに直接接続したデータベースを返す
+type MongoDriverUseCaseInterface interface {
+	Database() (*mongo.Database, error)
+}
+
+type MongoDriverUseCaseStruct struct {
+	mu     sync.Mutex
+	client *mongo.Client
+}
+
+func NewMongoDriverUseCaseStruct() *MongoDriverUseCaseStruct {
+	return &MongoDriverUseCaseStruct{}
+}
+
+// 初回の呼び出しで接続し、以降は同じ接続を使い回す
+func (s *MongoDriverUseCaseStruct) Database() (*mongo.Database, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		uri, err := makeUri()
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+		if err != nil {
+			return nil, err
+		}
+		s.client = client
+	}
+
+	return s.client.Database("chatapp"), nil
+}
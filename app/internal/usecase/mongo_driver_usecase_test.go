@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMongoDriverDatabase(t *testing.T) {
+	funcs.WithEnvMap(mongoSvcEnvs, t, func() {
+		driver := NewMongoDriverUseCaseStruct()
+
+		// 接続はサーバーへの最初の操作まで確立されないため、サーバーがなくても取得できる
+		db, err := driver.Database()
+		assert.NoError(t, err)
+		assert.Equal(t, "chatapp", db.Name())
+
+		again, err := driver.Database()
+		assert.NoError(t, err)
+		assert.Same(t, db.Client(), again.Client())
+	})
+}
+
+func TestMongoDriverDatabaseWithoutConnectionInfo(t *testing.T) {
+	funcs.WithEnvUnset(unsetEnvs, t, func() {
+		_, err := NewMongoDriverUseCaseStruct().Database()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// atylabmongo ではインデックスを作成できないため、ドライバーに直接接続して作成する
+type MongoIndexUseCaseInterface interface {
+	EnsureIndexes() error
+}
+
+type MongoIndexUseCaseStruct struct {
+	indexes map[string][]mongo.IndexModel
+}
+
+func NewMongoIndexUseCaseStruct(
+	indexes map[string][]mongo.IndexModel,
+) *MongoIndexUseCaseStruct {
+	return &MongoIndexUseCaseStruct{
+		indexes: indexes,
+	}
+}
+
+func (s *MongoIndexUseCaseStruct) EnsureIndexes() error {
+	uri, err := makeUri()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(ctx)
+
+	return createIndexes(ctx, client.Database("chatapp"), s.indexes)
+}
+
+// 同じ定義のインデックスが既にあれば何もしないので、起動のたびに呼んでよい
+func createIndexes(ctx context.Context, db *mongo.Database, indexes map[string][]mongo.IndexModel) error {
+	for collection, models := range indexes {
+		if len(models) == 0 {
+			continue
+		}
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return fmt.Errorf("failed to create indexes on %s: %w", collection, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCreateIndexes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		// コレクションごとに createIndexes が実行される
+		for range model.MongoIndexes {
+			mt.AddMockResponses(mtest.CreateSuccessResponse())
+		}
+		err := createIndexes(context.Background(), mt.DB, model.MongoIndexes)
+		assert.NoError(mt, err)
+	})
+
+	mt.Run("empty", func(mt *mtest.T) {
+		err := createIndexes(context.Background(), mt.DB, map[string][]mongo.IndexModel{"messages": {}})
+		assert.NoError(mt, err)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    85,
+			Message: "Index already exists with a different name",
+		}))
+		err := createIndexes(context.Background(), mt.DB, map[string][]mongo.IndexModel{
+			"messages": {{Keys: bson.D{{Key: "roomid", Value: 1}}}},
+		})
+		assert.ErrorContains(mt, err, "messages")
+	})
+}
+
+func TestEnsureIndexesWithoutConnectionInfo(t *testing.T) {
+	funcs.WithEnvUnset(unsetEnvs, t, func() {
+		err := NewMongoIndexUseCaseStruct(model.MongoIndexes).EnsureIndexes()
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// atylabredis のクライアントは Get/Set しか提供していないため、
+// アトミックな操作が必要な処理はこちらを利用する
+type RedisCmdInterface interface {
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error)
+}
+
+type RedisCmdStruct struct {
+	rdb *redis.Client
+}
+
+func NewRedisCmdStruct(rdb *redis.Client) *RedisCmdStruct {
+	return &RedisCmdStruct{
+		rdb: rdb,
+	}
+}
+
+func (r *RedisCmdStruct) Eval(
+	ctx context.Context,
+	script string,
+	keys []string,
+	args ...interface{},
+) (interface{}, error) {
+	return r.rdb.Eval(ctx, script, keys, args...).Result()
+}
@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRedisCmd(t *testing.T) (*RedisCmdStruct, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewRedisCmdStruct(rdb), mr
+}
+
+func TestRedisCmdEval(t *testing.T) {
+	cmd, mr := setupRedisCmd(t)
+
+	result, err := cmd.Eval(
+		context.Background(),
+		"redis.call('SET', KEYS[1], ARGV[1]) return redis.call('INCR', KEYS[1])",
+		[]string{"counter"},
+		10,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), result)
+
+	value, err := mr.Get("counter")
+	assert.NoError(t, err)
+	assert.Equal(t, "11", value)
+}
+
+func TestRedisCmdEvalError(t *testing.T) {
+	cmd, _ := setupRedisCmd(t)
+
+	_, err := cmd.Eval(context.Background(), "return redis.call('UNKNOWN')", []string{})
+	assert.Error(t, err)
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabredis"
+	"github.com/redis/go-redis/v9"
 )
 
 type RedisUseCaseInterface interface {
@@ -14,6 +15,7 @@ type RedisUseCaseInterface interface {
 
 type Redis struct {
 	RedisConnector *atylabredis.RedisConnector
+	Cmd            RedisCmdInterface
 	IsConnected    bool
 }
 
@@ -60,10 +62,21 @@ func (s *RedisUseCaseStruct) RedisInit() (*Redis, error) {
 		return nil, err
 	}
 
-	s.redis = &Redis{
+	connected := Redis{
 		RedisConnector: redisConnector,
-		IsConnected:    true,
+		Cmd: NewRedisCmdStruct(redis.NewClient(&redis.Options{
+			Addr:     redisAddr,
+			Password: redisPass,
+			DB:       redisDB,
+		})),
+		IsConnected: true,
 	}
 
+	// 共有しているインスタンスに接続情報を反映し、リクエスト毎の再接続を防ぐ
+	if s.redis == nil {
+		s.redis = &Redis{}
+	}
+	*s.redis = connected
+
 	return s.redis, nil
 }
@@ -60,6 +60,10 @@ func TestRedisInit(t *testing.T) {
 		if !r.IsConnected {
 			t.Errorf("RedisInit() expected IsConnected to be true, got false")
 		}
+		assert.NotNil(t, r.Cmd)
+		// 共有インスタンスが接続済みになっていること
+		assert.Same(t, redis, r)
+		assert.True(t, redis.IsConnected)
 	})
 }
 
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewLocalStorageStruct(dir)
+	ctx := context.Background()
+
+	err := storage.Put(ctx, "rooms/room1/file1", strings.NewReader("hello"), 5, "text/plain")
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "rooms", "room1", "file1"))
+
+	reader, err := storage.Get(ctx, "rooms/room1/file1")
+	assert.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	reader.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+
+	assert.NoError(t, storage.Delete(ctx, "rooms/room1/file1"))
+	// 削除済みでもエラーにしない
+	assert.NoError(t, storage.Delete(ctx, "rooms/room1/file1"))
+
+	_, err = storage.Get(ctx, "rooms/room1/file1")
+	assert.ErrorIs(t, err, ErrStorageObjectNotFound)
+}
+
+func TestLocalStoragePathTraversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "storage")
+	storage := NewLocalStorageStruct(dir)
+
+	err := storage.Put(context.Background(), "../../outside", strings.NewReader("x"), 1, "text/plain")
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "outside"))
+	assert.NoFileExists(t, filepath.Join(root, "outside"))
+}
+
+func TestLocalStoragePutError(t *testing.T) {
+	dir := t.TempDir()
+	// ディレクトリを作るべき場所にファイルがあると保存できない
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "rooms"), []byte("x"), 0o644))
+	storage := NewLocalStorageStruct(dir)
+
+	err := storage.Put(context.Background(), "rooms/room1/file1", strings.NewReader("hello"), 5, "text/plain")
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// PUT/GET/HEAD/DELETE だけを受け付ける S3 互換のスタブサーバー
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]bool
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newFakeS3(t *testing.T) *httptest.Server {
+	f := &fakeS3{buckets: map[string]bool{}, objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	switch {
+	case key == "" && r.Method == http.MethodHead:
+		if !f.buckets[bucket] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+	case key == "" && r.Method == http.MethodPut:
+		f.buckets[bucket] = true
+	case r.Method == http.MethodPut:
+		body, err := readS3Body(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = body
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				io.WriteString(w, `<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", f.types[r.URL.Path])
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+// 署名付きチャンク形式（aws-chunked）で送られた本文を復元する
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var body bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return body.Bytes(), nil
+		}
+		if _, err := io.CopyN(&body, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func newTestS3Storage(t *testing.T, endpoint string) *S3StorageStruct {
+	storage, err := NewS3StorageStruct(S3Config{
+		Endpoint:  endpoint,
+		AccessKey: "access",
+		SecretKey: "secretsecret",
+		Bucket:    "attachments",
+		Region:    "us-east-1",
+	})
+	assert.NoError(t, err)
+	return storage
+}
+
+func testS3Storage(t *testing.T, storage *S3StorageStruct) {
+	ctx := context.Background()
+
+	assert.NoError(t, storage.EnsureBucket(ctx))
+	// 2回目は作成済みのバケットをそのまま使う
+	assert.NoError(t, storage.EnsureBucket(ctx))
+
+	body := []byte("hello attachment")
+	err := storage.Put(ctx, "rooms/room1/file1", bytes.NewReader(body), int64(len(body)), "text/plain")
+	assert.NoError(t, err)
+
+	reader, err := storage.Get(ctx, "rooms/room1/file1")
+	assert.NoError(t, err)
+	got, err := io.ReadAll(reader)
+	reader.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, body, got)
+
+	assert.NoError(t, storage.Delete(ctx, "rooms/room1/file1"))
+
+	_, err = storage.Get(ctx, "rooms/room1/file1")
+	assert.ErrorIs(t, err, ErrStorageObjectNotFound)
+}
+
+func TestS3Storage(t *testing.T) {
+	server := newFakeS3(t)
+	testS3Storage(t, newTestS3Storage(t, strings.TrimPrefix(server.URL, "http://")))
+}
+
+// S3_TEST_ENDPOINT を指定した場合は実際の MinIO に対しても確認する
+func TestS3StorageWithMinio(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+
+	storage, err := NewS3StorageStruct(S3Config{
+		Endpoint:  endpoint,
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		Bucket:    "storage-test",
+		Region:    os.Getenv("S3_REGION"),
+	})
+	assert.NoError(t, err)
+	testS3Storage(t, storage)
+}
+
+func TestS3StorageConnectionError(t *testing.T) {
+	server := newFakeS3(t)
+	storage := newTestS3Storage(t, strings.TrimPrefix(server.URL, "http://"))
+	server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	assert.Error(t, storage.EnsureBucket(ctx))
+	_, err := storage.Get(ctx, "missing")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrStorageObjectNotFound)
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+var ErrStorageObjectNotFound = errors.New("storage object not found")
+
+// 添付ファイルの保存先
+// STORAGE_DRIVER で local / s3 を切り替える
+type StorageBackendInterface interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type StorageUseCaseInterface interface {
+	StorageInit() (*Storage, error)
+}
+
+type Storage struct {
+	Backend     StorageBackendInterface
+	IsConnected bool
+}
+
+func NewStorage() *Storage {
+	return &Storage{
+		IsConnected: false,
+	}
+}
+
+type StorageUseCaseStruct struct {
+	storage *Storage
+}
+
+func NewStorageUseCaseStruct(
+	storage *Storage,
+) *StorageUseCaseStruct {
+	return &StorageUseCaseStruct{
+		storage: storage,
+	}
+}
+
+func (s *StorageUseCaseStruct) StorageInit() (*Storage, error) {
+	if s.storage != nil && s.storage.IsConnected {
+		return s.storage, nil
+	}
+
+	backend, err := newStorageBackend()
+	if err != nil {
+		return &Storage{}, err
+	}
+
+	// 共有しているインスタンスを更新し、次回以降のリクエストで再接続しないようにする
+	if s.storage == nil {
+		s.storage = NewStorage()
+	}
+	s.storage.Backend = backend
+	s.storage.IsConnected = true
+	return s.storage, nil
+}
+
+func newStorageBackend() (StorageBackendInterface, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./storage"
+		}
+		return NewLocalStorageStruct(dir), nil
+	case "s3":
+		storage, err := NewS3StorageStruct(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			Region:    os.Getenv("S3_REGION"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := storage.EnsureBucket(ctx); err != nil {
+			return nil, err
+		}
+		return storage, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", driver)
+	}
+}
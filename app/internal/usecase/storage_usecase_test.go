@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/test_helper/funcs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageInit(t *testing.T) {
+	expected := map[string]struct {
+		envs      funcs.Envs
+		expectErr bool
+		expect    interface{}
+	}{
+		"default local": {
+			envs:   funcs.Envs{"STORAGE_DRIVER": "", "STORAGE_LOCAL_DIR": ""},
+			expect: &LocalStorageStruct{},
+		},
+		"local": {
+			envs:   funcs.Envs{"STORAGE_DRIVER": "local", "STORAGE_LOCAL_DIR": "/tmp/attachments"},
+			expect: &LocalStorageStruct{},
+		},
+		"s3 connection error": {
+			envs:      funcs.Envs{"STORAGE_DRIVER": "s3", "S3_ENDPOINT": "127.0.0.1:1", "S3_BUCKET": "attachments", "S3_REGION": "us-east-1"},
+			expectErr: true,
+		},
+		"s3 invalid endpoint": {
+			envs:      funcs.Envs{"STORAGE_DRIVER": "s3", "S3_ENDPOINT": "http://invalid/path"},
+			expectErr: true,
+		},
+		"unknown driver": {
+			envs:      funcs.Envs{"STORAGE_DRIVER": "ftp"},
+			expectErr: true,
+		},
+	}
+
+	for name, tt := range expected {
+		t.Run(name, func(t *testing.T) {
+			funcs.WithEnvMap(tt.envs, t, func() {
+				shared := NewStorage()
+				storage, err := NewStorageUseCaseStruct(shared).StorageInit()
+				if tt.expectErr {
+					assert.Error(t, err)
+					assert.False(t, shared.IsConnected)
+					return
+				}
+				assert.NoError(t, err)
+				assert.IsType(t, tt.expect, storage.Backend)
+				assert.Same(t, shared, storage)
+
+				// 初期化済みであれば同じインスタンスを返す
+				again, err := NewStorageUseCaseStruct(shared).StorageInit()
+				assert.NoError(t, err)
+				assert.Same(t, storage, again)
+			})
+		})
+	}
+}
+
+func TestStorageInitS3(t *testing.T) {
+	server := newFakeS3(t)
+	funcs.WithEnvMap(funcs.Envs{
+		"STORAGE_DRIVER": "s3",
+		"S3_ENDPOINT":    server.URL[len("http://"):],
+		"S3_BUCKET":      "attachments",
+		"S3_REGION":      "us-east-1",
+		"S3_ACCESS_KEY":  "access",
+		"S3_SECRET_KEY":  "secretsecret",
+	}, t, func() {
+		storage, err := NewStorageUseCaseStruct(nil).StorageInit()
+		assert.NoError(t, err)
+		assert.IsType(t, &S3StorageStruct{}, storage.Backend)
+	})
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrWebhookAddressNotAllowed = errors.New("webhook address is not allowed")
+	ErrWebhookResponseTooLarge  = errors.New("webhook response is too large")
+)
+
+// Webhook の本文を登録先の URL に POST する
+// リンクプレビューと同じく、内部のネットワークには接続しない
+type WebhookPosterInterface interface {
+	Post(ctx context.Context, rawURL string, headers map[string]string, body []byte) (int, error)
+}
+
+// 外部のコマンドのように、レスポンスの本文も使う場合の送信
+type WebhookCallerInterface interface {
+	Call(ctx context.Context, rawURL string, headers map[string]string, body []byte, maxResponseBytes int64) (int, []byte, error)
+}
+
+type WebhookPosterConfig struct {
+	Timeout time.Duration
+	// 接続してよいアドレスかどうか。nil の場合は IsPublicAddr を使う
+	AllowAddr func(netip.Addr) bool
+}
+
+type WebhookPosterStruct struct {
+	client *http.Client
+}
+
+func NewWebhookPosterStruct(config WebhookPosterConfig) *WebhookPosterStruct {
+	allowAddr := config.AllowAddr
+	if allowAddr == nil {
+		allowAddr = IsPublicAddr
+	}
+
+	return &WebhookPosterStruct{
+		client: &http.Client{
+			Transport: newPublicTransport(config.Timeout, allowAddr, ErrWebhookAddressNotAllowed),
+			Timeout:   config.Timeout,
+			// 署名した本文を別の宛先に送らないよう、リダイレクトには従わずにそのままのステータスを返す
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// 送信してレスポンスのステータスコードを返す
+// 2xx 以外のステータスはエラーにしないので、成否は呼び出し側で判断する
+func (p *WebhookPosterStruct) Post(ctx context.Context, rawURL string, headers map[string]string, body []byte) (int, error) {
+	resp, err := p.send(ctx, rawURL, headers, body)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 接続を再利用できるよう、レスポンスは読み捨てる（大きすぎる場合は途中でやめる）
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// 送信してレスポンスのステータスコードと本文を返す
+// 本文が maxResponseBytes を超える場合は ErrWebhookResponseTooLarge を返す
+func (p *WebhookPosterStruct) Call(ctx context.Context, rawURL string, headers map[string]string, body []byte, maxResponseBytes int64) (int, []byte, error) {
+	resp, err := p.send(ctx, rawURL, headers, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if int64(len(respBody)) > maxResponseBytes {
+		return resp.StatusCode, nil, ErrWebhookResponseTooLarge
+	}
+
+	return resp.StatusCode, respBody, nil
+}
+
+func (p *WebhookPosterStruct) send(ctx context.Context, rawURL string, headers map[string]string, body []byte) (*http.Response, error) {
+	if err := ValidateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ChatWebhook/1.0")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return p.client.Do(req)
+}
+
+// Webhook として登録できる URL（http / https の絶対 URL）かどうか
+func ValidateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, rawURL)
+	}
+	return nil
+}
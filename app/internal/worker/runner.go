@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 1回分の処理。処理したものがあれば true を返す
+type Task func() (bool, error)
+
+// Task を繰り返し実行する
+// 処理するものがなかった・失敗した場合は interval だけ待ってから次を確認する
+type Runner struct {
+	name     string
+	task     Task
+	interval time.Duration
+}
+
+func NewRunner(
+	name string,
+	task Task,
+	interval time.Duration,
+) *Runner {
+	return &Runner{
+		name:     name,
+		task:     task,
+		interval: interval,
+	}
+}
+
+func (r *Runner) Name() string {
+	return r.name
+}
+
+// ctx がキャンセルされるまで実行する（実行中の処理は最後まで行う）
+func (r *Runner) Run(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		processed, err := r.task()
+		if err != nil {
+			fmt.Println("Worker task failed:", r.name, err)
+		}
+		// 続けて処理できるものがあるかもしれないので、待たずに次を確認する
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
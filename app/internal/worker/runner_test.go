@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunnerRun(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// 3回目までは処理あり（待たずに次へ）、4回目は失敗、5回目で停止する
+	runner := NewRunner("test", func() (bool, error) {
+		switch calls.Add(1) {
+		case 1, 2, 3:
+			return true, nil
+		case 4:
+			return true, errors.New("failed")
+		default:
+			cancel()
+			return false, nil
+		}
+	}, time.Millisecond)
+	assert.Equal(t, "test", runner.Name())
+
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runner did not stop")
+	}
+	assert.Equal(t, int32(5), calls.Load())
+}
+
+func TestRunnerRunWaitsWhenIdle(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runner := NewRunner("idle", func() (bool, error) {
+		calls.Add(1)
+		return false, nil
+	}, time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(done)
+	}()
+
+	// 処理するものがなければ interval の間は待つ
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// 待機中でもキャンセルすればすぐに止まる
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runner did not stop")
+	}
+}
+
+func TestRunnerRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	called := false
+	NewRunner("canceled", func() (bool, error) {
+		called = true
+		return true, nil
+	}, time.Millisecond).Run(ctx)
+	assert.False(t, called)
+}
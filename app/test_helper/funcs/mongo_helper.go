@@ -65,6 +65,50 @@ func (m *TestMongoStruct) MongoCleanUp() error {
 	if err != nil {
 		return err
 	}
+	err = m.DB.Collection(model.ReportCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.ThumbnailJobCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.ScheduledMessageCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.ReminderCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.ArchivedMessageCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.WebhookCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.WebhookDeliveryCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.IncomingWebhookCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.BotCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.BotAPIKeyCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
+	err = m.DB.Collection(model.SlashCommandCollectionName).Drop(m.Ctx)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println("MongoDB cleaned up for tests.")
 	return nil
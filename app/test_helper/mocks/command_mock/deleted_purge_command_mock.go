@@ -0,0 +1,19 @@
+package command_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/command"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/stretchr/testify/mock"
+)
+
+type DeletedPurgeCommandMock struct {
+	mock.Mock
+}
+
+func (m *DeletedPurgeCommandMock) Run(args []string) {
+	m.Called(args)
+}
+
+func (m *DeletedPurgeCommandMock) SetUp(mongo usecase.MongoUseCaseInterface, storage usecase.StorageUseCaseInterface, options command.DeletedPurgeOptions) {
+	m.Called(mongo, storage, options)
+}
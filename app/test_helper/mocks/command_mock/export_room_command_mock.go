@@ -0,0 +1,19 @@
+package command_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/command"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/stretchr/testify/mock"
+)
+
+type ExportRoomCommandMock struct {
+	mock.Mock
+}
+
+func (m *ExportRoomCommandMock) Run(args []string) {
+	m.Called(args)
+}
+
+func (m *ExportRoomCommandMock) SetUp(mongo usecase.MongoUseCaseInterface, redis usecase.RedisUseCaseInterface, options command.ExportRoomOptions) {
+	m.Called(mongo, redis, options)
+}
@@ -13,6 +13,6 @@ func (m *ForbiddenWordsCommandMock) Run(args []string) {
 	m.Called(args)
 }
 
-func (m *ForbiddenWordsCommandMock) SetUp(mongo usecase.MongoUseCaseInterface, timeOut int) {
-	m.Called(mongo, timeOut)
+func (m *ForbiddenWordsCommandMock) SetUp(mongo usecase.MongoUseCaseInterface, timeOut int, concurrency int) {
+	m.Called(mongo, timeOut, concurrency)
 }
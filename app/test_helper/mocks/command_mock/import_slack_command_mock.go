@@ -0,0 +1,19 @@
+package command_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/command"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/stretchr/testify/mock"
+)
+
+type ImportSlackCommandMock struct {
+	mock.Mock
+}
+
+func (m *ImportSlackCommandMock) Run(args []string) {
+	m.Called(args)
+}
+
+func (m *ImportSlackCommandMock) SetUp(mongo usecase.MongoUseCaseInterface, options command.ImportSlackOptions) {
+	m.Called(mongo, options)
+}
@@ -0,0 +1,19 @@
+package command_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/command"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/stretchr/testify/mock"
+)
+
+type RetentionPurgeCommandMock struct {
+	mock.Mock
+}
+
+func (m *RetentionPurgeCommandMock) Run(args []string) {
+	m.Called(args)
+}
+
+func (m *RetentionPurgeCommandMock) SetUp(mongo usecase.MongoUseCaseInterface, storage usecase.StorageUseCaseInterface, options command.RetentionPurgeOptions) {
+	m.Called(mongo, storage, options)
+}
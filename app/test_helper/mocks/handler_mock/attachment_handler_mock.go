@@ -0,0 +1,21 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockAttachmentHandler struct{}
+
+func (h *MockAttachmentHandler) Upload(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "uploaded"})
+}
+
+func (h *MockAttachmentHandler) Download(c echo.Context) error {
+	return c.String(http.StatusOK, "file")
+}
+
+func (h *MockAttachmentHandler) Thumbnail(c echo.Context) error {
+	return c.String(http.StatusOK, "thumbnail")
+}
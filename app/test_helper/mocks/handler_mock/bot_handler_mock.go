@@ -0,0 +1,33 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockBotHandler struct{}
+
+func (h *MockBotHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"bots": "list"})
+}
+
+func (h *MockBotHandler) Create(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "created"})
+}
+
+func (h *MockBotHandler) Delete(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "deleted"})
+}
+
+func (h *MockBotHandler) APIKeys(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"api_keys": "list"})
+}
+
+func (h *MockBotHandler) IssueAPIKey(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "issued"})
+}
+
+func (h *MockBotHandler) RevokeAPIKey(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "revoked"})
+}
@@ -0,0 +1,13 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockEventHandler struct{}
+
+func (h *MockEventHandler) Stream(c echo.Context) error {
+	return c.String(http.StatusOK, "retry: 3000\n\n")
+}
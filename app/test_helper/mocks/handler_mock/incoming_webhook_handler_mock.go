@@ -0,0 +1,25 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockIncomingWebhookHandler struct{}
+
+func (h *MockIncomingWebhookHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"incoming_webhooks": "list"})
+}
+
+func (h *MockIncomingWebhookHandler) Create(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "created"})
+}
+
+func (h *MockIncomingWebhookHandler) Delete(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "deleted"})
+}
+
+func (h *MockIncomingWebhookHandler) Post(c echo.Context) error {
+	return c.String(http.StatusOK, "ok")
+}
@@ -23,3 +23,27 @@ func (h *MockMessageHandler) Read(c echo.Context) error {
 func (h *MockMessageHandler) Delete(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{"message": "deleted"})
 }
+
+func (h *MockMessageHandler) Report(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "reported"})
+}
+
+func (h *MockMessageHandler) Pin(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "pinned"})
+}
+
+func (h *MockMessageHandler) Unpin(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "unpinned"})
+}
+
+func (h *MockMessageHandler) Pins(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"pins": "list"})
+}
+
+func (h *MockMessageHandler) Deleted(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"messages": "deleted"})
+}
+
+func (h *MockMessageHandler) Restore(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "restored"})
+}
@@ -0,0 +1,21 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockModerationHandler struct{}
+
+func (h *MockModerationHandler) Reports(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"reports": "list"})
+}
+
+func (h *MockModerationHandler) ReportDetail(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"report": "detail"})
+}
+
+func (h *MockModerationHandler) Resolve(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"report": "resolved"})
+}
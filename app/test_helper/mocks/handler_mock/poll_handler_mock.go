@@ -0,0 +1,21 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockPollHandler struct{}
+
+func (h *MockPollHandler) Create(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message_id": "mock-message-id"})
+}
+
+func (h *MockPollHandler) Vote(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"poll": echo.Map{}})
+}
+
+func (h *MockPollHandler) Close(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"poll": echo.Map{}})
+}
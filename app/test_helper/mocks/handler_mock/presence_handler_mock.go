@@ -0,0 +1,17 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockPresenceHandler struct{}
+
+func (h *MockPresenceHandler) Heartbeat(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"presence": "heartbeat"})
+}
+
+func (h *MockPresenceHandler) SetStatus(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"presence": "status"})
+}
@@ -0,0 +1,13 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockReceiptHandler struct{}
+
+func (h *MockReceiptHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"receipts": []string{}})
+}
@@ -0,0 +1,29 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockReminderHandler struct{}
+
+func (h *MockReminderHandler) Create(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "created"})
+}
+
+func (h *MockReminderHandler) CreateForMessage(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "created for message"})
+}
+
+func (h *MockReminderHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"reminders": "list"})
+}
+
+func (h *MockReminderHandler) Snooze(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "snoozed"})
+}
+
+func (h *MockReminderHandler) Cancel(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "canceled"})
+}
@@ -43,3 +43,19 @@ func (h *MockRoomHandler) AddMember(c echo.Context) error {
 func (h *MockRoomHandler) RemoveMember(c echo.Context) error {
 	return c.JSON(http.StatusOK, echo.Map{"member": "removed"})
 }
+
+func (h *MockRoomHandler) SetMessageTTL(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message_ttl": 0})
+}
+
+func (h *MockRoomHandler) SetRetention(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"retention_days": 0})
+}
+
+func (h *MockRoomHandler) SetReadReceipts(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"hide_read_receipts": false})
+}
+
+func (h *MockRoomHandler) Export(c echo.Context) error {
+	return c.String(http.StatusOK, "")
+}
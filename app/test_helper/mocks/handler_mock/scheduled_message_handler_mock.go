@@ -0,0 +1,25 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockScheduledMessageHandler struct{}
+
+func (h *MockScheduledMessageHandler) Create(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "scheduled"})
+}
+
+func (h *MockScheduledMessageHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"scheduled_messages": "list"})
+}
+
+func (h *MockScheduledMessageHandler) Update(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "updated"})
+}
+
+func (h *MockScheduledMessageHandler) Cancel(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "canceled"})
+}
@@ -0,0 +1,25 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockSlashCommandHandler struct{}
+
+func (h *MockSlashCommandHandler) Catalog(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"commands": "catalog"})
+}
+
+func (h *MockSlashCommandHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"commands": "list"})
+}
+
+func (h *MockSlashCommandHandler) Create(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "created"})
+}
+
+func (h *MockSlashCommandHandler) Delete(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "deleted"})
+}
@@ -0,0 +1,17 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockTypingHandler struct{}
+
+func (h *MockTypingHandler) Update(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"typing": true})
+}
+
+func (h *MockTypingHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"typing": []string{}})
+}
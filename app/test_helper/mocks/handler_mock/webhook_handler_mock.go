@@ -0,0 +1,25 @@
+package handler_mock
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+type MockWebhookHandler struct{}
+
+func (h *MockWebhookHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"webhooks": "list"})
+}
+
+func (h *MockWebhookHandler) Create(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "created"})
+}
+
+func (h *MockWebhookHandler) Delete(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"message": "deleted"})
+}
+
+func (h *MockWebhookHandler) Deliveries(c echo.Context) error {
+	return c.JSON(http.StatusOK, echo.Map{"deliveries": "list"})
+}
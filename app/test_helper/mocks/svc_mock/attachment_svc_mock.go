@@ -0,0 +1,31 @@
+package svc_mock
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type AttachmentSvcMock struct {
+	mock.Mock
+}
+
+func (m *AttachmentSvcMock) Upload(ctx context.Context, roomID string, file *multipart.FileHeader) (model.Attachment, error) {
+	args := m.Called(ctx, roomID, file)
+	return args.Get(0).(model.Attachment), args.Error(1)
+}
+
+func (m *AttachmentSvcMock) Open(ctx context.Context, attachment model.Attachment) (io.ReadCloser, error) {
+	args := m.Called(ctx, attachment)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *AttachmentSvcMock) Remove(ctx context.Context, attachments []model.Attachment) {
+	m.Called(ctx, attachments)
+}
@@ -0,0 +1,31 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type BotSvcMock struct {
+	mock.Mock
+}
+
+func (m *BotSvcMock) Create(bot model.Bot, ctx *atylabmongo.MongoCtxSvc) (model.Bot, error) {
+	args := m.Called(bot, ctx)
+	return args.Get(0).(model.Bot), args.Error(1)
+}
+
+func (m *BotSvcMock) Delete(botID string, ownerID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(botID, ownerID, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BotSvcMock) IssueAPIKey(key model.BotAPIKey, ctx *atylabmongo.MongoCtxSvc) (model.BotAPIKey, string, error) {
+	args := m.Called(key, ctx)
+	return args.Get(0).(model.BotAPIKey), args.String(1), args.Error(2)
+}
+
+func (m *BotSvcMock) Authenticate(apiKey string, ctx *atylabmongo.MongoCtxSvc) (model.Bot, model.BotAPIKey, error) {
+	args := m.Called(apiKey, ctx)
+	return args.Get(0).(model.Bot), args.Get(1).(model.BotAPIKey), args.Error(2)
+}
@@ -1,6 +1,8 @@
 package cmd_svc_mock
 
 import (
+	"time"
+
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/stretchr/testify/mock"
@@ -10,12 +12,66 @@ type MessageSvcMock struct {
 	mock.Mock
 }
 
-func (m *MessageSvcMock) GetMessageList(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
-	args := m.Called(roomID, ctx)
-	return args.Get(0).([]model.Message), args.Error(1)
+// Return に渡したメッセージを順にコールバックへ流し、最後に指定したエラーを返す
+func (m *MessageSvcMock) StreamMessageList(roomID string, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error {
+	args := m.Called(roomID, ctx, fn)
+	if messages, ok := args.Get(0).([]model.Message); ok {
+		for _, message := range messages {
+			if err := fn(message); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+// StreamMessageList と同様に、Return に渡したメッセージを順にコールバックへ流す
+func (m *MessageSvcMock) StreamMessagesBefore(roomID string, before time.Time, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error {
+	args := m.Called(roomID, before, ctx, fn)
+	if messages, ok := args.Get(0).([]model.Message); ok {
+		for _, message := range messages {
+			if err := fn(message); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MessageSvcMock) ArchiveMessages(messages []model.Message, archivedAt time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(messages, archivedAt, ctx)
+	return args.Error(0)
+}
+
+func (m *MessageSvcMock) DeleteMessages(messages []model.Message, ctx *atylabmongo.MongoCtxSvc) (int64, error) {
+	args := m.Called(messages, ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// StreamMessageList と同様に、Return に渡したメッセージを順にコールバックへ流す
+func (m *MessageSvcMock) StreamDeletedMessagesBefore(before time.Time, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error {
+	args := m.Called(before, ctx, fn)
+	if messages, ok := args.Get(0).([]model.Message); ok {
+		for _, message := range messages {
+			if err := fn(message); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MessageSvcMock) PurgeDeletedMessages(messages []model.Message, before time.Time, ctx *atylabmongo.MongoCtxSvc) (int64, []model.Message, error) {
+	args := m.Called(messages, before, ctx)
+	return args.Get(0).(int64), args.Get(1).([]model.Message), args.Error(2)
 }
 
 func (m *MessageSvcMock) ContainsForbiddenWords(message string) bool {
 	args := m.Called(message)
 	return args.Bool(0)
 }
+
+func (m *MessageSvcMock) ImportMessage(message model.Message, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(message, ctx)
+	return args.Bool(0), args.Error(1)
+}
@@ -14,3 +14,8 @@ func (m *RoomSvcMock) ListRooms(ctx *atylabmongo.MongoCtxSvc) ([]model.Room, err
 	args := m.Called(ctx)
 	return args.Get(0).([]model.Room), args.Error(1)
 }
+
+func (m *RoomSvcMock) ImportRoom(room model.Room, ctx *atylabmongo.MongoCtxSvc) (model.Room, bool, error) {
+	args := m.Called(room, ctx)
+	return args.Get(0).(model.Room), args.Bool(1), args.Error(2)
+}
@@ -0,0 +1,27 @@
+package svc_mock
+
+import (
+	"context"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/stretchr/testify/mock"
+)
+
+type EventSvcMock struct {
+	mock.Mock
+}
+
+func (m *EventSvcMock) Publish(event service.RoomEvent) (string, error) {
+	args := m.Called(event)
+	return args.String(0), args.Error(1)
+}
+
+func (m *EventSvcMock) Resume(lastEventID string, ctx context.Context) (string, bool, error) {
+	args := m.Called(lastEventID, ctx)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (m *EventSvcMock) Read(cursor string, ctx context.Context) ([]service.StreamEvent, error) {
+	args := m.Called(cursor, ctx)
+	return args.Get(0).([]service.StreamEvent), args.Error(1)
+}
@@ -0,0 +1,24 @@
+package svc_mock
+
+import (
+	"io"
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ExportSvcMock struct {
+	mock.Mock
+}
+
+func (m *ExportSvcMock) ContentType(format string) (string, error) {
+	args := m.Called(format)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ExportSvcMock) Export(w io.Writer, room model.Room, format string, loc *time.Location, ctx *atylabmongo.MongoCtxSvc) (int, error) {
+	args := m.Called(w, room, format, loc, ctx)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,27 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type IncomingWebhookSvcMock struct {
+	mock.Mock
+}
+
+func (m *IncomingWebhookSvcMock) Create(webhook model.IncomingWebhook, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, string, error) {
+	args := m.Called(webhook, ctx)
+	return args.Get(0).(model.IncomingWebhook), args.String(1), args.Error(2)
+}
+
+func (m *IncomingWebhookSvcMock) Authenticate(webhookID string, token string, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, error) {
+	args := m.Called(webhookID, token, ctx)
+	return args.Get(0).(model.IncomingWebhook), args.Error(1)
+}
+
+func (m *IncomingWebhookSvcMock) Post(webhook model.IncomingWebhook, room model.Room, payload service.SlackPayload, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(webhook, room, payload, ctx)
+	return args.String(0), args.Error(1)
+}
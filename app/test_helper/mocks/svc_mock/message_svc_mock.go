@@ -0,0 +1,16 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type MessageSvcMock struct {
+	mock.Mock
+}
+
+func (m *MessageSvcMock) Send(message model.Message, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(message, ctx)
+	return args.String(0), args.Error(1)
+}
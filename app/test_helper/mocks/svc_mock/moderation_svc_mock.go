@@ -0,0 +1,21 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ModerationSvcMock struct {
+	mock.Mock
+}
+
+func (m *ModerationSvcMock) GetReportContext(report model.Report, ctx *atylabmongo.MongoCtxSvc) (model.Message, []model.Message, error) {
+	args := m.Called(report, ctx)
+	return args.Get(0).(model.Message), args.Get(1).([]model.Message), args.Error(2)
+}
+
+func (m *ModerationSvcMock) Resolve(report model.Report, action string, moderatorID string, note string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(report, action, moderatorID, note, ctx)
+	return args.Error(0)
+}
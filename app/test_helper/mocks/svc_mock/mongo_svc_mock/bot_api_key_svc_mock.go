@@ -0,0 +1,38 @@
+package mongo_svc_mock
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type BotAPIKeySvcMock struct {
+	mock.Mock
+}
+
+func (m *BotAPIKeySvcMock) CreateAPIKey(key model.BotAPIKey, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(key, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *BotAPIKeySvcMock) GetAPIKeys(botID string, ctx *atylabmongo.MongoCtxSvc) ([]model.BotAPIKey, error) {
+	args := m.Called(botID, ctx)
+	return args.Get(0).([]model.BotAPIKey), args.Error(1)
+}
+
+func (m *BotAPIKeySvcMock) GetActiveAPIKeyByHash(keyHash string, ctx *atylabmongo.MongoCtxSvc) (model.BotAPIKey, error) {
+	args := m.Called(keyHash, ctx)
+	return args.Get(0).(model.BotAPIKey), args.Error(1)
+}
+
+func (m *BotAPIKeySvcMock) RevokeAPIKey(keyID string, botID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(keyID, botID, now, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *BotAPIKeySvcMock) RevokeAPIKeys(botID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(botID, now, ctx)
+	return args.Error(0)
+}
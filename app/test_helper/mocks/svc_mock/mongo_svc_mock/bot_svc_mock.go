@@ -0,0 +1,31 @@
+package mongo_svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type BotSvcMock struct {
+	mock.Mock
+}
+
+func (m *BotSvcMock) CreateBot(bot model.Bot, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(bot, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *BotSvcMock) GetBots(ownerID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Bot, error) {
+	args := m.Called(ownerID, ctx)
+	return args.Get(0).([]model.Bot), args.Error(1)
+}
+
+func (m *BotSvcMock) GetBot(botID string, ctx *atylabmongo.MongoCtxSvc) (model.Bot, error) {
+	args := m.Called(botID, ctx)
+	return args.Get(0).(model.Bot), args.Error(1)
+}
+
+func (m *BotSvcMock) DeleteBot(botID string, ownerID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(botID, ownerID, ctx)
+	return args.Bool(0), args.Error(1)
+}
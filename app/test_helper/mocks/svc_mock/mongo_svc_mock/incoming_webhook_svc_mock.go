@@ -0,0 +1,31 @@
+package mongo_svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type IncomingWebhookSvcMock struct {
+	mock.Mock
+}
+
+func (m *IncomingWebhookSvcMock) CreateIncomingWebhook(webhook model.IncomingWebhook, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(webhook, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *IncomingWebhookSvcMock) GetIncomingWebhooks(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.IncomingWebhook, error) {
+	args := m.Called(roomID, ctx)
+	return args.Get(0).([]model.IncomingWebhook), args.Error(1)
+}
+
+func (m *IncomingWebhookSvcMock) GetIncomingWebhook(webhookID string, ctx *atylabmongo.MongoCtxSvc) (model.IncomingWebhook, error) {
+	args := m.Called(webhookID, ctx)
+	return args.Get(0).(model.IncomingWebhook), args.Error(1)
+}
+
+func (m *IncomingWebhookSvcMock) DeleteIncomingWebhook(webhookID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(webhookID, roomID, ctx)
+	return args.Bool(0), args.Error(1)
+}
@@ -1,6 +1,8 @@
 package mongo_svc_mock
 
 import (
+	"time"
+
 	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
 	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
 	"github.com/stretchr/testify/mock"
@@ -19,6 +21,19 @@ func (m *MessageSvcMock) GetMessageList(roomID string, ctx *atylabmongo.MongoCtx
 	return args.Get(0).([]model.Message), args.Error(1)
 }
 
+// Return に渡したメッセージを順にコールバックへ流す
+func (m *MessageSvcMock) StreamMessages(roomID string, ctx *atylabmongo.MongoCtxSvc, fn func(message model.Message) error) error {
+	args := m.Called(roomID, ctx, fn)
+	if messages, ok := args.Get(0).([]model.Message); ok {
+		for _, message := range messages {
+			if err := fn(message); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MessageSvcMock) ReadMessages(messageIds []string, roomId string, userId string, ctx *atylabmongo.MongoCtxSvc) error {
 	args := m.Called(messageIds, roomId, userId, ctx)
 	return args.Error(0)
@@ -29,7 +44,77 @@ func (m *MessageSvcMock) IsSender(messageID string, roomID string, userID string
 	return args.Error(0)
 }
 
-func (m *MessageSvcMock) DeleteMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) error {
+func (m *MessageSvcMock) DeleteMessage(messageID string, roomID string, deletedBy string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(messageID, roomID, deletedBy, ctx)
+	return args.Error(0)
+}
+
+func (m *MessageSvcMock) GetDeletedMessages(roomID string, since time.Time, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	args := m.Called(roomID, since, ctx)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MessageSvcMock) RestoreMessage(messageID string, roomID string, since time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(messageID, roomID, since, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MessageSvcMock) GetMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (model.Message, error) {
 	args := m.Called(messageID, roomID, ctx)
+	return args.Get(0).(model.Message), args.Error(1)
+}
+
+func (m *MessageSvcMock) GetMessagesAround(roomID string, at time.Time, window time.Duration, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	args := m.Called(roomID, at, window, ctx)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MessageSvcMock) FindByClientMsgID(roomID string, sender string, clientMsgID string, ctx *atylabmongo.MongoCtxSvc) (model.Message, error) {
+	args := m.Called(roomID, sender, clientMsgID, ctx)
+	return args.Get(0).(model.Message), args.Error(1)
+}
+
+func (m *MessageSvcMock) SetAttachmentImage(messageID string, roomID string, attachmentID string, width int, height int, thumbnails []model.AttachmentThumbnail, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(messageID, roomID, attachmentID, width, height, thumbnails, ctx)
+	return args.Error(0)
+}
+
+func (m *MessageSvcMock) SetLinkPreviews(messageID string, roomID string, previews []model.LinkPreview, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(messageID, roomID, previews, ctx)
 	return args.Error(0)
 }
+
+func (m *MessageSvcMock) PinMessage(messageID string, roomID string, pin model.MessagePin, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(messageID, roomID, pin, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MessageSvcMock) UnpinMessage(messageID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(messageID, roomID, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MessageSvcMock) VotePoll(messageID string, roomID string, vote model.PollVote, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(messageID, roomID, vote, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MessageSvcMock) ClosePoll(messageID string, roomID string, closedBy string, closedAt time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(messageID, roomID, closedBy, closedAt, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MessageSvcMock) GetPinnedMessages(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	args := m.Called(roomID, ctx)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MessageSvcMock) GetExpiredMessages(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.Message, error) {
+	args := m.Called(now, limit, ctx)
+	return args.Get(0).([]model.Message), args.Error(1)
+}
+
+func (m *MessageSvcMock) DeleteExpiredMessage(message model.Message, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(message, ctx)
+	return args.Bool(0), args.Error(1)
+}
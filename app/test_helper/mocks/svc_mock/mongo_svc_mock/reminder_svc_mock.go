@@ -0,0 +1,63 @@
+package mongo_svc_mock
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReminderSvcMock struct {
+	mock.Mock
+}
+
+func (m *ReminderSvcMock) CreateReminder(reminder model.Reminder, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(reminder, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ReminderSvcMock) GetReminders(userID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Reminder, error) {
+	args := m.Called(userID, ctx)
+	return args.Get(0).([]model.Reminder), args.Error(1)
+}
+
+func (m *ReminderSvcMock) GetReminder(reminderID string, userID string, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error) {
+	args := m.Called(reminderID, userID, ctx)
+	return args.Get(0).(model.Reminder), args.Error(1)
+}
+
+func (m *ReminderSvcMock) SnoozeReminder(reminderID string, userID string, remindAt time.Time, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(reminderID, userID, remindAt, now, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReminderSvcMock) CancelReminder(reminderID string, userID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(reminderID, userID, now, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReminderSvcMock) GetDueReminders(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.Reminder, error) {
+	args := m.Called(now, limit, ctx)
+	return args.Get(0).([]model.Reminder), args.Error(1)
+}
+
+func (m *ReminderSvcMock) LeaseReminder(reminder model.Reminder, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(reminder, now, lease, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReminderSvcMock) CompleteReminder(reminder model.Reminder, notificationID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(reminder, notificationID, now, ctx)
+	return args.Error(0)
+}
+
+func (m *ReminderSvcMock) RetryReminder(reminder model.Reminder, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(reminder, now, retryAt, lastError, ctx)
+	return args.Error(0)
+}
+
+func (m *ReminderSvcMock) FailReminder(reminder model.Reminder, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(reminder, now, lastError, ctx)
+	return args.Error(0)
+}
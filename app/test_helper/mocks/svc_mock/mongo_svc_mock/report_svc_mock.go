@@ -0,0 +1,41 @@
+package mongo_svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReportSvcMock struct {
+	mock.Mock
+}
+
+func (m *ReportSvcMock) CreateReport(report model.Report, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(report, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ReportSvcMock) GetReport(reportID string, ctx *atylabmongo.MongoCtxSvc) (model.Report, error) {
+	args := m.Called(reportID, ctx)
+	return args.Get(0).(model.Report), args.Error(1)
+}
+
+func (m *ReportSvcMock) GetReportList(status string, ctx *atylabmongo.MongoCtxSvc) ([]model.Report, error) {
+	args := m.Called(status, ctx)
+	return args.Get(0).([]model.Report), args.Error(1)
+}
+
+func (m *ReportSvcMock) HasOpenReport(messageID string, reporterID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(messageID, reporterID, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReportSvcMock) ResolveReport(reportID string, resolution model.ReportResolution, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(reportID, resolution, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ReportSvcMock) ReopenReport(reportID string, resolution model.ReportResolution, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(reportID, resolution, ctx)
+	return args.Error(0)
+}
@@ -49,3 +49,38 @@ func (m *RoomSvcMock) DeleteRoom(roomID string, ctx *atylabmongo.MongoCtxSvc) er
 	args := m.Called(roomID, ctx)
 	return args.Error(0)
 }
+
+func (m *RoomSvcMock) BanMember(roomID string, uuid string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(roomID, uuid, ctx)
+	return args.Error(0)
+}
+
+func (m *RoomSvcMock) SetMessageTTL(roomID string, ttl int, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(roomID, ttl, ctx)
+	return args.Error(0)
+}
+
+func (m *RoomSvcMock) SetRetentionDays(roomID string, days int, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(roomID, days, ctx)
+	return args.Error(0)
+}
+
+func (m *RoomSvcMock) SetHideReadReceipts(roomID string, hide bool, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(roomID, hide, ctx)
+	return args.Error(0)
+}
+
+func (m *RoomSvcMock) SetTopic(roomID string, topic string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(roomID, topic, ctx)
+	return args.Error(0)
+}
+
+func (m *RoomSvcMock) SetMuted(roomID string, uuid string, muted bool, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(roomID, uuid, muted, ctx)
+	return args.Error(0)
+}
+
+func (m *RoomSvcMock) GetOrCreateRoomByExternalID(room model.Room, ctx *atylabmongo.MongoCtxSvc) (model.Room, []string, error) {
+	args := m.Called(room, ctx)
+	return args.Get(0).(model.Room), args.Get(1).([]string), args.Error(2)
+}
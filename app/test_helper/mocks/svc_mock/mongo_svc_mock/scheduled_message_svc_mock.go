@@ -0,0 +1,63 @@
+package mongo_svc_mock
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ScheduledMessageSvcMock struct {
+	mock.Mock
+}
+
+func (m *ScheduledMessageSvcMock) CreateScheduledMessage(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(scheduled, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) GetScheduledMessages(roomID string, sender string, ctx *atylabmongo.MongoCtxSvc) ([]model.ScheduledMessage, error) {
+	args := m.Called(roomID, sender, ctx)
+	return args.Get(0).([]model.ScheduledMessage), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) GetScheduledMessage(scheduledID string, roomID string, sender string, ctx *atylabmongo.MongoCtxSvc) (model.ScheduledMessage, error) {
+	args := m.Called(scheduledID, roomID, sender, ctx)
+	return args.Get(0).(model.ScheduledMessage), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) UpdateScheduledMessage(scheduledID string, roomID string, sender string, message string, sendAt time.Time, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(scheduledID, roomID, sender, message, sendAt, now, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) CancelScheduledMessage(scheduledID string, roomID string, sender string, now time.Time, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(scheduledID, roomID, sender, now, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) GetDueMessages(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.ScheduledMessage, error) {
+	args := m.Called(now, limit, ctx)
+	return args.Get(0).([]model.ScheduledMessage), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) LeaseMessage(scheduled model.ScheduledMessage, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(scheduled, now, lease, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) CompleteMessage(scheduled model.ScheduledMessage, messageID string, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(scheduled, messageID, now, ctx)
+	return args.Error(0)
+}
+
+func (m *ScheduledMessageSvcMock) RetryMessage(scheduled model.ScheduledMessage, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(scheduled, now, retryAt, lastError, ctx)
+	return args.Error(0)
+}
+
+func (m *ScheduledMessageSvcMock) FailMessage(scheduled model.ScheduledMessage, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(scheduled, now, lastError, ctx)
+	return args.Error(0)
+}
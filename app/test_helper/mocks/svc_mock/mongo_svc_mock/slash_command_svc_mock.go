@@ -0,0 +1,41 @@
+package mongo_svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type SlashCommandSvcMock struct {
+	mock.Mock
+}
+
+func (m *SlashCommandSvcMock) CreateSlashCommand(command model.SlashCommand, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(command, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *SlashCommandSvcMock) GetSlashCommands(botID string, ctx *atylabmongo.MongoCtxSvc) ([]model.SlashCommand, error) {
+	args := m.Called(botID, ctx)
+	return args.Get(0).([]model.SlashCommand), args.Error(1)
+}
+
+func (m *SlashCommandSvcMock) GetAllSlashCommands(ctx *atylabmongo.MongoCtxSvc) ([]model.SlashCommand, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.SlashCommand), args.Error(1)
+}
+
+func (m *SlashCommandSvcMock) GetSlashCommandByName(name string, ctx *atylabmongo.MongoCtxSvc) (model.SlashCommand, error) {
+	args := m.Called(name, ctx)
+	return args.Get(0).(model.SlashCommand), args.Error(1)
+}
+
+func (m *SlashCommandSvcMock) DeleteSlashCommand(commandID string, botID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(commandID, botID, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *SlashCommandSvcMock) DeleteSlashCommands(botID string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(botID, ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,43 @@
+package mongo_svc_mock
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ThumbnailJobSvcMock struct {
+	mock.Mock
+}
+
+func (m *ThumbnailJobSvcMock) CreateJob(job model.ThumbnailJob, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(job, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ThumbnailJobSvcMock) GetRunnableJobs(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.ThumbnailJob, error) {
+	args := m.Called(now, limit, ctx)
+	return args.Get(0).([]model.ThumbnailJob), args.Error(1)
+}
+
+func (m *ThumbnailJobSvcMock) LeaseJob(job model.ThumbnailJob, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(job, now, lease, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *ThumbnailJobSvcMock) CompleteJob(job model.ThumbnailJob, now time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(job, now, ctx)
+	return args.Error(0)
+}
+
+func (m *ThumbnailJobSvcMock) RetryJob(job model.ThumbnailJob, now time.Time, retryAt time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(job, now, retryAt, lastError, ctx)
+	return args.Error(0)
+}
+
+func (m *ThumbnailJobSvcMock) FailJob(job model.ThumbnailJob, now time.Time, lastError string, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(job, now, lastError, ctx)
+	return args.Error(0)
+}
@@ -0,0 +1,48 @@
+package mongo_svc_mock
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookDeliverySvcMock struct {
+	mock.Mock
+}
+
+func (m *WebhookDeliverySvcMock) CreateDelivery(delivery model.WebhookDelivery, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(delivery, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *WebhookDeliverySvcMock) GetDeliveries(webhookID string, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.WebhookDelivery, error) {
+	args := m.Called(webhookID, limit, ctx)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *WebhookDeliverySvcMock) GetRunnableDeliveries(now time.Time, limit int, ctx *atylabmongo.MongoCtxSvc) ([]model.WebhookDelivery, error) {
+	args := m.Called(now, limit, ctx)
+	return args.Get(0).([]model.WebhookDelivery), args.Error(1)
+}
+
+func (m *WebhookDeliverySvcMock) LeaseDelivery(delivery model.WebhookDelivery, now time.Time, lease time.Duration, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(delivery, now, lease, ctx)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *WebhookDeliverySvcMock) CompleteDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(delivery, attempt, ctx)
+	return args.Error(0)
+}
+
+func (m *WebhookDeliverySvcMock) RetryDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, retryAt time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(delivery, attempt, retryAt, ctx)
+	return args.Error(0)
+}
+
+func (m *WebhookDeliverySvcMock) FailDelivery(delivery model.WebhookDelivery, attempt model.WebhookAttempt, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(delivery, attempt, ctx)
+	return args.Error(0)
+}
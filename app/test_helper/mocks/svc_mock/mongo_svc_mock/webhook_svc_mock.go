@@ -0,0 +1,36 @@
+package mongo_svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookSvcMock struct {
+	mock.Mock
+}
+
+func (m *WebhookSvcMock) CreateWebhook(webhook model.Webhook, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(webhook, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *WebhookSvcMock) GetWebhooks(roomID string, ctx *atylabmongo.MongoCtxSvc) ([]model.Webhook, error) {
+	args := m.Called(roomID, ctx)
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (m *WebhookSvcMock) GetSubscribedWebhooks(roomID string, event string, ctx *atylabmongo.MongoCtxSvc) ([]model.Webhook, error) {
+	args := m.Called(roomID, event, ctx)
+	return args.Get(0).([]model.Webhook), args.Error(1)
+}
+
+func (m *WebhookSvcMock) GetWebhook(webhookID string, ctx *atylabmongo.MongoCtxSvc) (model.Webhook, error) {
+	args := m.Called(webhookID, ctx)
+	return args.Get(0).(model.Webhook), args.Error(1)
+}
+
+func (m *WebhookSvcMock) DeleteWebhook(webhookID string, roomID string, ctx *atylabmongo.MongoCtxSvc) (bool, error) {
+	args := m.Called(webhookID, roomID, ctx)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,21 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type PinSvcMock struct {
+	mock.Mock
+}
+
+func (m *PinSvcMock) Pin(message model.Message, moderatorID string, ctx *atylabmongo.MongoCtxSvc) (model.MessagePin, error) {
+	args := m.Called(message, moderatorID, ctx)
+	return args.Get(0).(model.MessagePin), args.Error(1)
+}
+
+func (m *PinSvcMock) Unpin(message model.Message, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(message, ctx)
+	return args.Error(0)
+}
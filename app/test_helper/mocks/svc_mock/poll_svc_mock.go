@@ -0,0 +1,27 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type PollSvcMock struct {
+	mock.Mock
+}
+
+func (m *PollSvcMock) Create(input service.PollInput, room model.Room, uuid string, bot *model.MessageBot, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(input, room, uuid, bot, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *PollSvcMock) Vote(message model.Message, uuid string, options []int, ctx *atylabmongo.MongoCtxSvc) (model.MessagePoll, error) {
+	args := m.Called(message, uuid, options, ctx)
+	return args.Get(0).(model.MessagePoll), args.Error(1)
+}
+
+func (m *PollSvcMock) Close(message model.Message, uuid string, isAdmin bool, ctx *atylabmongo.MongoCtxSvc) (model.MessagePoll, error) {
+	args := m.Called(message, uuid, isAdmin, ctx)
+	return args.Get(0).(model.MessagePoll), args.Error(1)
+}
@@ -0,0 +1,30 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type PresenceSvcMock struct {
+	mock.Mock
+}
+
+func (m *PresenceSvcMock) Heartbeat(uuid string) (model.Presence, error) {
+	args := m.Called(uuid)
+	return args.Get(0).(model.Presence), args.Error(1)
+}
+
+func (m *PresenceSvcMock) SetStatus(uuid string, away bool, statusText string) (model.Presence, error) {
+	args := m.Called(uuid, away, statusText)
+	return args.Get(0).(model.Presence), args.Error(1)
+}
+
+func (m *PresenceSvcMock) GetPresences(uuids []string) (map[string]model.Presence, error) {
+	args := m.Called(uuids)
+	return args.Get(0).(map[string]model.Presence), args.Error(1)
+}
+
+func (m *PresenceSvcMock) RunNext() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
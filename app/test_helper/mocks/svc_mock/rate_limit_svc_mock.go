@@ -0,0 +1,17 @@
+package svc_mock
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/stretchr/testify/mock"
+)
+
+type RateLimitSvcMock struct {
+	mock.Mock
+}
+
+func (m *RateLimitSvcMock) Allow(keys []service.RateLimitKey, window time.Duration) (service.RateLimitResult, error) {
+	args := m.Called(keys, window)
+	return args.Get(0).(service.RateLimitResult), args.Error(1)
+}
@@ -0,0 +1,32 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ReminderSvcMock struct {
+	mock.Mock
+}
+
+func (m *ReminderSvcMock) Create(reminder model.Reminder, when service.ReminderTime, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error) {
+	args := m.Called(reminder, when, ctx)
+	return args.Get(0).(model.Reminder), args.Error(1)
+}
+
+func (m *ReminderSvcMock) Snooze(reminder model.Reminder, when service.ReminderTime, ctx *atylabmongo.MongoCtxSvc) (model.Reminder, error) {
+	args := m.Called(reminder, when, ctx)
+	return args.Get(0).(model.Reminder), args.Error(1)
+}
+
+func (m *ReminderSvcMock) Cancel(reminder model.Reminder, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(reminder, ctx)
+	return args.Error(0)
+}
+
+func (m *ReminderSvcMock) RunNext() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
@@ -30,3 +30,8 @@ func (m *RoomSvcMock) GetMemberInfos(room model.Room, ctx *atylabapi.ApiCtxSvc)
 	args := m.Called(room, ctx)
 	return args.Get(0).([]model.RoomMember), args.Error(1)
 }
+
+func (m *RoomSvcMock) IsBanned(room model.Room, uuid string) bool {
+	args := m.Called(room, uuid)
+	return args.Bool(0)
+}
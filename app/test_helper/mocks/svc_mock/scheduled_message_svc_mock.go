@@ -0,0 +1,33 @@
+package svc_mock
+
+import (
+	"time"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ScheduledMessageSvcMock struct {
+	mock.Mock
+}
+
+func (m *ScheduledMessageSvcMock) Schedule(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) (string, error) {
+	args := m.Called(scheduled, ctx)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ScheduledMessageSvcMock) Reschedule(scheduled model.ScheduledMessage, message string, sendAt time.Time, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(scheduled, message, sendAt, ctx)
+	return args.Error(0)
+}
+
+func (m *ScheduledMessageSvcMock) Cancel(scheduled model.ScheduledMessage, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(scheduled, ctx)
+	return args.Error(0)
+}
+
+func (m *ScheduledMessageSvcMock) RunNext() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,22 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type SlashCommandSvcMock struct {
+	mock.Mock
+}
+
+func (m *SlashCommandSvcMock) Register(command model.SlashCommand, ctx *atylabmongo.MongoCtxSvc) (model.SlashCommand, error) {
+	args := m.Called(command, ctx)
+	return args.Get(0).(model.SlashCommand), args.Error(1)
+}
+
+func (m *SlashCommandSvcMock) Execute(req service.SlashCommandRequest, ctx *atylabmongo.MongoCtxSvc) (service.SlashCommandResult, error) {
+	args := m.Called(req, ctx)
+	return args.Get(0).(service.SlashCommandResult), args.Error(1)
+}
@@ -0,0 +1,16 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/service"
+	"github.com/stretchr/testify/mock"
+)
+
+type SpamSvcMock struct {
+	mock.Mock
+}
+
+func (m *SpamSvcMock) Check(message model.Message) (service.SpamCheckResult, error) {
+	args := m.Called(message)
+	return args.Get(0).(service.SpamCheckResult), args.Error(1)
+}
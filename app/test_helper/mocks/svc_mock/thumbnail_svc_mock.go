@@ -0,0 +1,21 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type ThumbnailSvcMock struct {
+	mock.Mock
+}
+
+func (m *ThumbnailSvcMock) Enqueue(message model.Message, ctx *atylabmongo.MongoCtxSvc) error {
+	args := m.Called(message, ctx)
+	return args.Error(0)
+}
+
+func (m *ThumbnailSvcMock) RunNext() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,17 @@
+package svc_mock
+
+import "github.com/stretchr/testify/mock"
+
+type TypingSvcMock struct {
+	mock.Mock
+}
+
+func (m *TypingSvcMock) SetTyping(roomID string, uuid string, typing bool) error {
+	args := m.Called(roomID, uuid, typing)
+	return args.Error(0)
+}
+
+func (m *TypingSvcMock) GetTyping(roomID string) ([]string, error) {
+	args := m.Called(roomID)
+	return args.Get(0).([]string), args.Error(1)
+}
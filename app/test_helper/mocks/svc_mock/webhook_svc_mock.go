@@ -0,0 +1,25 @@
+package svc_mock
+
+import (
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/model"
+	"github.com/AtsuyaOotsuka/portfolio-go-lib/atylabmongo"
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookSvcMock struct {
+	mock.Mock
+}
+
+func (m *WebhookSvcMock) Register(webhook model.Webhook, ctx *atylabmongo.MongoCtxSvc) (model.Webhook, error) {
+	args := m.Called(webhook, ctx)
+	return args.Get(0).(model.Webhook), args.Error(1)
+}
+
+func (m *WebhookSvcMock) Dispatch(roomID string, event string, data any) {
+	m.Called(roomID, event, data)
+}
+
+func (m *WebhookSvcMock) RunNext() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,17 @@
+package usecase_mock
+
+import (
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mtest のモックデータベースを返すときに使う
+type MongoDriverUseCaseMock struct {
+	mock.Mock
+}
+
+func (m *MongoDriverUseCaseMock) Database() (*mongo.Database, error) {
+	args := m.Called()
+	db, _ := args.Get(0).(*mongo.Database)
+	return db, args.Error(1)
+}
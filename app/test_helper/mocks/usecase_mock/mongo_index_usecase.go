@@ -0,0 +1,14 @@
+package usecase_mock
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+type MongoIndexUseCaseMock struct {
+	mock.Mock
+}
+
+func (m *MongoIndexUseCaseMock) EnsureIndexes() error {
+	args := m.Called()
+	return args.Error(0)
+}
@@ -0,0 +1,16 @@
+package usecase_mock
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RedisCmdMock struct {
+	mock.Mock
+}
+
+func (m *RedisCmdMock) Eval(ctx context.Context, script string, keys []string, args ...interface{}) (interface{}, error) {
+	callArgs := m.Called(ctx, script, keys, args)
+	return callArgs.Get(0), callArgs.Error(1)
+}
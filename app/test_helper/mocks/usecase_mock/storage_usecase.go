@@ -0,0 +1,45 @@
+package usecase_mock
+
+import (
+	"context"
+	"io"
+
+	"github.com/AtsuyaOotsuka/portfolio-go-chat/internal/usecase"
+	"github.com/stretchr/testify/mock"
+)
+
+type StorageUseCaseMock struct {
+	mock.Mock
+}
+
+func (m *StorageUseCaseMock) StorageInit() (*usecase.Storage, error) {
+	args := m.Called()
+	return args.Get(0).(*usecase.Storage), args.Error(1)
+}
+
+type StorageBackendMock struct {
+	mock.Mock
+}
+
+func (m *StorageBackendMock) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	// 呼び出し側で内容を検証できるように読み切ってから渡す
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	args := m.Called(ctx, key, data, size, contentType)
+	return args.Error(0)
+}
+
+func (m *StorageBackendMock) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *StorageBackendMock) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}